@@ -1,6 +1,7 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"os"
 
@@ -9,7 +10,14 @@ import (
 
 func main() {
 	if err := cli.Execute(); err != nil {
-		fmt.Fprintln(os.Stderr, err)
-		os.Exit(1)
+		switch {
+		case errors.Is(err, cli.ErrInterrupted):
+			fmt.Fprintln(os.Stderr, "Interrupted")
+		case errors.Is(err, cli.ErrNoAPIKey):
+			fmt.Fprintln(os.Stderr, cli.NoAPIKeyHelp)
+		default:
+			fmt.Fprintln(os.Stderr, err)
+		}
+		os.Exit(cli.ExitCode(err))
 	}
 }
@@ -0,0 +1,68 @@
+//go:build e2e
+
+package e2e
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestAliases tests the built-in shortcuts and user-defined aliases.
+func TestAliases(t *testing.T) {
+	configDir := t.TempDir()
+
+	stdout, _, code := runVSBWithConfig(t, configDir, "new", "--output", "json")
+	require.Equal(t, 0, code)
+	var created struct {
+		Email string `json:"email"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(stdout), &created))
+	t.Cleanup(func() {
+		runVSBWithConfig(t, configDir, "inbox", "delete", created.Email)
+	})
+
+	t.Run("shortcut passes flags on", func(t *testing.T) {
+		stdout, stderr, code := runVSBWithConfig(t, configDir, "ls", "--output", "json")
+		require.Equal(t, 0, code, "stderr: %s", stderr)
+		var emails []interface{}
+		require.NoError(t, json.Unmarshal([]byte(stdout), &emails))
+	})
+
+	t.Run("user alias", func(t *testing.T) {
+		_, stderr, code := runVSBWithConfig(t, configDir, "config", "set", "alias.mine", "inbox list --output json")
+		require.Equal(t, 0, code, "stderr: %s", stderr)
+
+		stdout, stderr, code := runVSBWithConfig(t, configDir, "mine")
+		require.Equal(t, 0, code, "stderr: %s", stderr)
+		assert.Contains(t, stdout, created.Email)
+	})
+
+	t.Run("alias list", func(t *testing.T) {
+		stdout, stderr, code := runVSBWithConfig(t, configDir, "alias", "list", "--output", "json")
+		require.Equal(t, 0, code, "stderr: %s", stderr)
+
+		var entries []struct {
+			Name string `json:"name"`
+			Runs string `json:"runs"`
+			Type string `json:"type"`
+		}
+		require.NoError(t, json.Unmarshal([]byte(stdout), &entries))
+		runs := make(map[string]string)
+		for _, e := range entries {
+			runs[e.Name] = e.Runs
+		}
+		assert.Equal(t, "vsb email list", runs["vsb ls"])
+		assert.Equal(t, "vsb inbox create", runs["vsb new"])
+		assert.Equal(t, "vsb inbox list", runs["vsb inbox ls"])
+		assert.Equal(t, "vsb inbox list --output json", runs["vsb mine"])
+	})
+
+	t.Run("help shows shortcuts under the command", func(t *testing.T) {
+		stdout, _, code := runVSBWithConfig(t, configDir, "email", "list", "--help")
+		require.Equal(t, 0, code)
+		assert.Contains(t, stdout, "Shortcuts:\n  vsb ls")
+	})
+}
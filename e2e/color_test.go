@@ -0,0 +1,42 @@
+//go:build e2e
+
+package e2e
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestColorFlag tests --color with table output, which is piped here.
+func TestColorFlag(t *testing.T) {
+	configDir := t.TempDir()
+
+	t.Run("never is the same as NO_COLOR", func(t *testing.T) {
+		noColor, stderr, code := runVSBWithConfigAndEnv(t, configDir, map[string]string{"NO_COLOR": "1"}, "alias", "list")
+		require.Equal(t, 0, code, "stderr: %s", stderr)
+
+		never, stderr, code := runVSBWithConfigAndEnv(t, configDir, map[string]string{"NO_COLOR": ""}, "alias", "list", "--color", "never")
+		require.Equal(t, 0, code, "stderr: %s", stderr)
+		assert.Equal(t, noColor, never)
+	})
+
+	t.Run("always colors piped output", func(t *testing.T) {
+		stdout, stderr, code := runVSBWithConfigAndEnv(t, configDir, map[string]string{"NO_COLOR": "1"}, "alias", "list", "--color", "always")
+		require.Equal(t, 0, code, "stderr: %s", stderr)
+		assert.Contains(t, stdout, "\x1b[")
+	})
+
+	t.Run("auto does not color piped output", func(t *testing.T) {
+		stdout, stderr, code := runVSBWithConfigAndEnv(t, configDir, map[string]string{"NO_COLOR": ""}, "alias", "list")
+		require.Equal(t, 0, code, "stderr: %s", stderr)
+		assert.NotContains(t, stdout, "\x1b[")
+	})
+
+	t.Run("invalid value", func(t *testing.T) {
+		_, stderr, code := runVSBWithConfig(t, configDir, "alias", "list", "--color", "sometimes")
+		assert.NotEqual(t, 0, code)
+		assert.Contains(t, stderr, "must be auto, always or never")
+	})
+}
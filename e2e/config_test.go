@@ -4,9 +4,16 @@ package e2e
 
 import (
 	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httputil"
+	"net/url"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -36,6 +43,18 @@ func TestConfigShow(t *testing.T) {
 		assert.NotContains(t, stdout, "vsb_test1234567890abcdef")
 	})
 
+	t.Run("reveal prints full API key with warning", func(t *testing.T) {
+		configDir := t.TempDir()
+
+		_, _, code := runVSBWithConfig(t, configDir, "config", "set", "api-key", "vsb_test1234567890abcdef")
+		require.Equal(t, 0, code)
+
+		stdout, stderr, code := runVSBWithConfig(t, configDir, "config", "show", "--reveal")
+		require.Equal(t, 0, code, "config show --reveal failed: stderr=%s", stderr)
+		assert.Contains(t, stdout, "vsb_test1234567890abcdef")
+		assert.Contains(t, stderr, "unmasked API key")
+	})
+
 	t.Run("show config JSON output", func(t *testing.T) {
 		configDir := t.TempDir()
 
@@ -164,6 +183,39 @@ func TestConfigSet(t *testing.T) {
 	})
 }
 
+// TestConfigGet tests printing a single resolved value.
+func TestConfigGet(t *testing.T) {
+	t.Run("env overrides config file", func(t *testing.T) {
+		configDir := t.TempDir()
+
+		_, _, code := runVSBWithConfig(t, configDir, "config", "set", "base-url", "https://file.example.com")
+		require.Equal(t, 0, code)
+
+		// runVSBWithConfig exports VSB_BASE_URL, which takes precedence
+		stdout, stderr, code := runVSBWithConfig(t, configDir, "config", "get", "base-url")
+		require.Equal(t, 0, code, "config get failed: stderr=%s", stderr)
+		assert.Equal(t, baseURL, strings.TrimSpace(stdout))
+	})
+
+	t.Run("api key masked unless revealed", func(t *testing.T) {
+		configDir := t.TempDir()
+
+		stdout, _, code := runVSBWithConfig(t, configDir, "config", "get", "api-key")
+		require.Equal(t, 0, code)
+		assert.NotContains(t, stdout, apiKey)
+
+		stdout, _, code = runVSBWithConfig(t, configDir, "config", "get", "api-key", "--reveal")
+		require.Equal(t, 0, code)
+		assert.Equal(t, apiKey, strings.TrimSpace(stdout))
+	})
+
+	t.Run("unknown key", func(t *testing.T) {
+		_, stderr, code := runVSBWithConfig(t, t.TempDir(), "config", "get", "invalid-key")
+		assert.NotEqual(t, 0, code)
+		assert.Contains(t, stderr, "unknown config key")
+	})
+}
+
 // TestConfigPersistence tests that config values persist across invocations.
 func TestConfigPersistence(t *testing.T) {
 	configDir := t.TempDir()
@@ -377,6 +429,22 @@ func TestConfigStrategy(t *testing.T) {
 		assert.Contains(t, stderr, "invalid strategy")
 	})
 
+	t.Run("set browser command", func(t *testing.T) {
+		configDir := t.TempDir()
+
+		_, _, code := runVSBWithConfig(t, configDir, "config", "set", "browser", "firefox --new-tab")
+		require.Equal(t, 0, code)
+
+		stdout, _, code := runVSBWithConfig(t, configDir, "config", "show", "--output", "json")
+		require.Equal(t, 0, code)
+
+		var result struct {
+			Browser string `json:"browser"`
+		}
+		require.NoError(t, json.Unmarshal([]byte(stdout), &result))
+		assert.Equal(t, "firefox --new-tab", result.Browser)
+	})
+
 	t.Run("default strategy is sse", func(t *testing.T) {
 		configDir := t.TempDir()
 
@@ -404,3 +472,298 @@ func TestConfigStrategy(t *testing.T) {
 		assert.Contains(t, stdout, "polling")
 	})
 }
+
+// TestInit tests the first-time setup wizard.
+func TestInit(t *testing.T) {
+	t.Run("interactive", func(t *testing.T) {
+		configDir := t.TempDir()
+
+		// Server URL (default from VSB_BASE_URL), an invalid then a valid
+		// key, an invalid then a valid TTL, polling, create an inbox
+		input := strings.Join([]string{"", "vsb_invalid_key", apiKey, "soon", "2h", "2", "y"}, "\n") + "\n"
+		stdout, stderr, code := runVSBWithInput(t, configDir, input, "init")
+		require.Equal(t, 0, code, "init failed: stdout=%s stderr=%s", stdout, stderr)
+
+		assert.Contains(t, stdout, "API key was rejected")
+		assert.Contains(t, stdout, "invalid default-ttl")
+		assert.Contains(t, stdout, "You're ready! Your inbox is: ")
+
+		inboxEmail := extractEmail(stdout[strings.Index(stdout, "You're ready!"):])
+		require.NotEmpty(t, inboxEmail)
+		t.Cleanup(func() {
+			runVSBWithConfig(t, configDir, "inbox", "delete", inboxEmail)
+		})
+
+		data, err := os.ReadFile(filepath.Join(configDir, "config.yaml"))
+		require.NoError(t, err)
+		assert.Contains(t, string(data), "strategy: polling")
+		assert.Contains(t, string(data), "default_ttl: 2h")
+
+		listResult := runVSBJSONWithConfig[[]struct {
+			Email    string `json:"email"`
+			IsActive bool   `json:"isActive"`
+		}](t, configDir, "inbox", "list")
+		require.Len(t, listResult, 1)
+		assert.Equal(t, inboxEmail, listResult[0].Email)
+		assert.True(t, listResult[0].IsActive)
+
+		// Declining to overwrite keeps the configuration
+		stdout, _, code = runVSBWithInput(t, configDir, "n\n", "init")
+		require.Equal(t, 0, code)
+		assert.Contains(t, stdout, "Keeping the existing configuration")
+		data, err = os.ReadFile(filepath.Join(configDir, "config.yaml"))
+		require.NoError(t, err)
+		assert.Contains(t, string(data), "strategy: polling")
+	})
+
+	t.Run("non-interactive", func(t *testing.T) {
+		configDir := t.TempDir()
+
+		stdout, stderr, code := runVSBWithConfig(t, configDir, "init", "--non-interactive",
+			"--api-key", apiKey, "--base-url", baseURL, "--ttl", "1h", "--create-inbox", "--output", "json")
+		require.Equal(t, 0, code, "init failed: stderr=%s", stderr)
+
+		var result struct {
+			Strategy   string `json:"strategy"`
+			DefaultTTL string `json:"defaultTtl"`
+			Inbox      string `json:"inbox"`
+		}
+		require.NoError(t, json.Unmarshal([]byte(stdout), &result))
+		require.NotEmpty(t, result.Inbox)
+		t.Cleanup(func() {
+			runVSBWithConfig(t, configDir, "inbox", "delete", result.Inbox)
+		})
+		assert.Equal(t, "sse", result.Strategy)
+		assert.Equal(t, "1h", result.DefaultTTL)
+
+		// An existing configuration is only replaced with --force
+		_, stderr, code = runVSBWithConfig(t, configDir, "init", "--non-interactive", "--api-key", apiKey)
+		assert.NotEqual(t, 0, code)
+		assert.Contains(t, stderr, "--force")
+
+		_, stderr, code = runVSBWithConfig(t, configDir, "init", "--non-interactive", "--api-key", apiKey,
+			"--base-url", baseURL, "--strategy", "polling", "--force")
+		require.Equal(t, 0, code, "init --force failed: stderr=%s", stderr)
+		data, err := os.ReadFile(filepath.Join(configDir, "config.yaml"))
+		require.NoError(t, err)
+		assert.Contains(t, string(data), "strategy: polling")
+	})
+
+	t.Run("non-interactive rejects bad key", func(t *testing.T) {
+		_, stderr, code := runVSB(t, "init", "--non-interactive", "--api-key", "vsb_invalid_key", "--base-url", baseURL)
+		assert.NotEqual(t, 0, code)
+		assert.Contains(t, stderr, "API key was rejected")
+	})
+}
+
+// TestProxy routes vsb through an intercepting proxy with VSB_PROXY.
+func TestProxy(t *testing.T) {
+	var mu sync.Mutex
+	var seen []string
+	record := func(s string) {
+		mu.Lock()
+		defer mu.Unlock()
+		seen = append(seen, s)
+	}
+
+	forward := &httputil.ReverseProxy{Director: func(*http.Request) {}}
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		record(r.URL.Hostname())
+		if r.Method != http.MethodConnect {
+			forward.ServeHTTP(w, r)
+			return
+		}
+		// HTTPS: tunnel the connection to the requested host
+		upstream, err := net.Dial("tcp", r.Host)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		conn, _, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			upstream.Close()
+			return
+		}
+		conn.Write([]byte("HTTP/1.1 200 Connection Established\r\n\r\n"))
+		go func() {
+			io.Copy(upstream, conn)
+			upstream.Close()
+		}()
+		io.Copy(conn, upstream)
+		conn.Close()
+	}))
+	defer proxy.Close()
+
+	apiURL, err := url.Parse(baseURL)
+	require.NoError(t, err)
+
+	env := func(proxyURL string) map[string]string {
+		return map[string]string{
+			"VSB_API_KEY":  apiKey,
+			"VSB_BASE_URL": baseURL,
+			"VSB_PROXY":    proxyURL,
+			"VSB_NO_PROXY": "",
+			"NO_PROXY":     "",
+			"no_proxy":     "",
+		}
+	}
+
+	t.Run("requests go through the proxy", func(t *testing.T) {
+		configDir := t.TempDir()
+
+		stdout, stderr, code := runVSBWithConfigAndEnv(t, configDir, env(proxy.URL), "inbox", "create", "--output", "json")
+		require.Equal(t, 0, code, "inbox create failed: stderr=%s", stderr)
+
+		var result struct {
+			Email string `json:"email"`
+		}
+		require.NoError(t, json.Unmarshal([]byte(stdout), &result))
+		t.Cleanup(func() {
+			runVSBWithConfig(t, configDir, "inbox", "delete", result.Email)
+		})
+
+		mu.Lock()
+		defer mu.Unlock()
+		assert.Contains(t, seen, apiURL.Hostname())
+	})
+
+	t.Run("unreachable proxy fails the request", func(t *testing.T) {
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		deadProxy := "http://" + ln.Addr().String()
+		ln.Close()
+
+		_, _, code := runVSBWithConfigAndEnv(t, t.TempDir(), env(deadProxy), "inbox", "create")
+		assert.NotEqual(t, 0, code)
+	})
+}
+
+// TestUserAgent tests the User-Agent header sent to the API. The gateway has
+// no endpoint that echoes headers, so requests go to a local server posing
+// as the API.
+func TestUserAgent(t *testing.T) {
+	var mu sync.Mutex
+	var agents []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		agents = append(agents, r.Header.Get("User-Agent"))
+		mu.Unlock()
+		http.Error(w, `{"error":"invalid API key"}`, http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	run := func(t *testing.T, userAgent string, args ...string) []string {
+		mu.Lock()
+		agents = nil
+		mu.Unlock()
+
+		env := map[string]string{
+			"VSB_API_KEY":    "vsb_test_key",
+			"VSB_BASE_URL":   server.URL,
+			"VSB_USER_AGENT": userAgent,
+			"VSB_PROXY":      "",
+			"HTTP_PROXY":     "",
+			"http_proxy":     "",
+		}
+		_, _, code := runVSBWithConfigAndEnv(t, t.TempDir(), env, args...)
+		assert.NotEqual(t, 0, code, "the local server rejects the key")
+
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), agents...)
+	}
+
+	t.Run("default includes the vsb version", func(t *testing.T) {
+		seen := run(t, "", "inbox", "create")
+		require.NotEmpty(t, seen)
+		for _, ua := range seen {
+			assert.True(t, strings.HasPrefix(ua, "vsb-cli/"), "got User-Agent %q", ua)
+		}
+	})
+
+	t.Run("VSB_USER_AGENT replaces it", func(t *testing.T) {
+		seen := run(t, "MyCI/2.0 vsb-cli", "inbox", "create")
+		require.NotEmpty(t, seen)
+		for _, ua := range seen {
+			assert.Equal(t, "MyCI/2.0 vsb-cli", ua)
+		}
+	})
+
+	t.Run("config set rejects non-printable values", func(t *testing.T) {
+		configDir := t.TempDir()
+		_, stderr, code := runVSBWithConfig(t, configDir, "config", "set", "user-agent", "MyCI\t2.0")
+		assert.NotEqual(t, 0, code)
+		assert.Contains(t, stderr, "only printable ASCII")
+
+		_, stderr, code = runVSBWithConfig(t, configDir, "config", "set", "user-agent", "MyCI/2.0")
+		require.Equal(t, 0, code, "config set failed: stderr=%s", stderr)
+		stdout, _, code := runVSBWithConfig(t, configDir, "config", "get", "user-agent")
+		require.Equal(t, 0, code)
+		assert.Equal(t, "MyCI/2.0", strings.TrimSpace(stdout))
+	})
+}
+
+// TestEnvFile tests --env-file. Requests go to a local server posing as the
+// API, which records the API key each one carries.
+func TestEnvFile(t *testing.T) {
+	var mu sync.Mutex
+	var keys []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		keys = append(keys, r.Header.Get("X-API-Key"))
+		mu.Unlock()
+		http.Error(w, `{"error":"invalid API key"}`, http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	// An address nothing listens on, for requests that must not reach server
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	deadURL := "http://" + ln.Addr().String()
+	ln.Close()
+
+	run := func(t *testing.T, envFile string, env map[string]string) (stderr string, seen []string) {
+		mu.Lock()
+		keys = nil
+		mu.Unlock()
+
+		configDir := t.TempDir()
+		path := filepath.Join(configDir, ".vsb.env")
+		require.NoError(t, os.WriteFile(path, []byte(envFile), 0600))
+		env["VSB_PROXY"], env["HTTP_PROXY"], env["http_proxy"] = "", "", ""
+
+		_, stderr, code := runVSBWithConfigAndEnv(t, configDir, env, "--env-file", path, "inbox", "create")
+		assert.NotEqual(t, 0, code, "the local server rejects the key")
+
+		mu.Lock()
+		defer mu.Unlock()
+		return stderr, append([]string(nil), keys...)
+	}
+
+	t.Run("variables from the file are used", func(t *testing.T) {
+		envFile := "# test credentials\nVSB_API_KEY=\"vsb_from_file\"\nexport VSB_BASE_URL='" + server.URL + "'\n"
+		_, seen := run(t, envFile, map[string]string{"VSB_API_KEY": "", "VSB_BASE_URL": ""})
+		require.NotEmpty(t, seen)
+		assert.Equal(t, "vsb_from_file", seen[0])
+	})
+
+	t.Run("environment variables win", func(t *testing.T) {
+		envFile := "VSB_API_KEY=vsb_from_file\nVSB_BASE_URL=" + deadURL + "\n"
+		_, seen := run(t, envFile, map[string]string{"VSB_API_KEY": "vsb_from_env", "VSB_BASE_URL": server.URL})
+		require.NotEmpty(t, seen)
+		assert.Equal(t, "vsb_from_env", seen[0])
+	})
+
+	t.Run("override marker replaces environment variables", func(t *testing.T) {
+		envFile := "override VSB_API_KEY=vsb_from_file\noverride VSB_BASE_URL=" + server.URL + "\n"
+		_, seen := run(t, envFile, map[string]string{"VSB_API_KEY": "vsb_from_env", "VSB_BASE_URL": deadURL})
+		require.NotEmpty(t, seen)
+		assert.Equal(t, "vsb_from_file", seen[0])
+	})
+
+	t.Run("invalid file", func(t *testing.T) {
+		stderr, seen := run(t, "VSB_API_KEY\n", map[string]string{"VSB_BASE_URL": server.URL})
+		assert.Contains(t, stderr, "line 1: expected KEY=VALUE")
+		assert.Empty(t, seen)
+	})
+}
@@ -29,6 +29,7 @@ import (
 
 	"github.com/joho/godotenv"
 	"github.com/stretchr/testify/require"
+	mailfile "github.com/vaultsandbox/vsb-cli/internal/email"
 )
 
 var (
@@ -83,8 +84,16 @@ func runVSB(t *testing.T, args ...string) (stdout, stderr string, exitCode int)
 // runVSBWithConfig executes the vsb CLI with a specific config directory.
 func runVSBWithConfig(t *testing.T, configDir string, args ...string) (stdout, stderr string, exitCode int) {
 	t.Helper()
+	return runVSBWithInput(t, configDir, "", args...)
+}
+
+// runVSBWithInput executes the vsb CLI with a specific config directory,
+// feeding stdin to answer prompts.
+func runVSBWithInput(t *testing.T, configDir, stdin string, args ...string) (stdout, stderr string, exitCode int) {
+	t.Helper()
 
 	cmd := exec.Command(vsbBinPath, args...)
+	cmd.Stdin = strings.NewReader(stdin)
 	cmd.Dir = configDir // Run from the config directory for relative paths
 
 	// Build environment, converting GOCOVERDIR to absolute path relative to project root
@@ -175,90 +184,54 @@ func skipIfNoSMTP(t *testing.T) {
 // sendTestEmail sends a plain text test email via SMTP.
 func sendTestEmail(t *testing.T, to, subject, body string) {
 	t.Helper()
-	skipIfNoSMTP(t)
-
-	smtpHost, smtpPort := getSMTPConfig()
-	from := "test@example.com"
-	msg := fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: %s\r\nContent-Type: text/plain; charset=utf-8\r\n\r\n%s",
-		from, to, subject, body)
+	sendTestEmailFrom(t, "test@example.com", to, subject, body)
+}
 
-	addr := fmt.Sprintf("%s:%s", smtpHost, smtpPort)
-	if err := smtp.SendMail(addr, nil, from, []string{to}, []byte(msg)); err != nil {
-		t.Fatalf("sendTestEmail() error = %v", err)
-	}
-	t.Logf("Sent email to %s with subject: %s", to, subject)
+// sendTestEmailFrom sends a plain text test email from the given sender
+// via SMTP.
+func sendTestEmailFrom(t *testing.T, from, to, subject, body string) {
+	t.Helper()
+	sendTestMessage(t, mailfile.Message{From: from, To: to, Subject: subject, Text: body})
 }
 
 // sendTestHTMLEmail sends a test email with HTML content via SMTP.
 func sendTestHTMLEmail(t *testing.T, to, subject, textBody, htmlBody string) {
 	t.Helper()
-	skipIfNoSMTP(t)
-
-	smtpHost, smtpPort := getSMTPConfig()
-	from := "test@example.com"
-	boundary := "boundary-example-12345"
-
-	msg := fmt.Sprintf(`From: %s
-To: %s
-Subject: %s
-MIME-Version: 1.0
-Content-Type: multipart/alternative; boundary="%s"
-
---%s
-Content-Type: text/plain; charset=utf-8
-
-%s
-
---%s
-Content-Type: text/html; charset=utf-8
-
-%s
-
---%s--
-`, from, to, subject, boundary, boundary, textBody, boundary, htmlBody, boundary)
+	sendTestMessage(t, mailfile.Message{To: to, Subject: subject, Text: textBody, HTML: htmlBody})
+}
 
-	addr := fmt.Sprintf("%s:%s", smtpHost, smtpPort)
-	if err := smtp.SendMail(addr, nil, from, []string{to}, []byte(msg)); err != nil {
-		t.Fatalf("sendTestHTMLEmail() error = %v", err)
-	}
-	t.Logf("Sent HTML email to %s with subject: %s", to, subject)
+// sendTestEmailWithAttachment sends a test email with one attachment via
+// SMTP.
+func sendTestEmailWithAttachment(t *testing.T, to, subject, body, attachmentName string, attachmentData []byte) {
+	t.Helper()
+	sendTestMessage(t, mailfile.Message{
+		To:          to,
+		Subject:     subject,
+		Text:        body,
+		Attachments: []mailfile.Attachment{{Filename: attachmentName, Data: attachmentData}},
+	})
 }
 
-// sendTestEmailWithAttachment sends a test email with an attachment via SMTP.
-func sendTestEmailWithAttachment(t *testing.T, to, subject, body, attachmentName, attachmentContent string) {
+// sendTestMessage composes m with the CLI's MIME writer and sends it via
+// SMTP, from test@example.com unless m sets a sender.
+func sendTestMessage(t *testing.T, m mailfile.Message) {
 	t.Helper()
 	skipIfNoSMTP(t)
 
-	smtpHost, smtpPort := getSMTPConfig()
-	from := "test@example.com"
-	boundary := "boundary-attachment-67890"
-
-	msg := fmt.Sprintf(`From: %s
-To: %s
-Subject: %s
-MIME-Version: 1.0
-Content-Type: multipart/mixed; boundary="%s"
-
---%s
-Content-Type: text/plain; charset=utf-8
-
-%s
-
---%s
-Content-Type: application/octet-stream; name="%s"
-Content-Disposition: attachment; filename="%s"
-Content-Transfer-Encoding: base64
-
-%s
-
---%s--
-`, from, to, subject, boundary, boundary, body, boundary, attachmentName, attachmentName, attachmentContent, boundary)
+	if m.From == "" {
+		m.From = "test@example.com"
+	}
+	msg, err := mailfile.Compose(m)
+	if err != nil {
+		t.Fatalf("Compose() error = %v", err)
+	}
 
+	smtpHost, smtpPort := getSMTPConfig()
 	addr := fmt.Sprintf("%s:%s", smtpHost, smtpPort)
-	if err := smtp.SendMail(addr, nil, from, []string{to}, []byte(msg)); err != nil {
-		t.Fatalf("sendTestEmailWithAttachment() error = %v", err)
+	if err := smtp.SendMail(addr, nil, m.From, []string{m.To}, msg); err != nil {
+		t.Fatalf("sendTestMessage() error = %v", err)
 	}
-	t.Logf("Sent email with attachment to %s", to)
+	t.Logf("Sent email to %s with subject: %s", m.To, m.Subject)
 }
 
 // ============================================================================
@@ -4,15 +4,19 @@ package e2e
 
 import (
 	"encoding/base64"
+	"encoding/csv"
 	"encoding/json"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	mailfile "github.com/vaultsandbox/vsb-cli/internal/email"
 )
 
 // TestEmailList tests listing emails in an inbox.
@@ -81,9 +85,146 @@ func TestEmailList(t *testing.T) {
 		assert.True(t, subjects["Test Subject 1"] || subjects["Test Subject 2"],
 			"at least one of our test emails should be found")
 	})
+
+	t.Run("time window", func(t *testing.T) {
+		stdout, stderr, code := runVSBWithConfig(t, configDir, "email", "list", "--since", "1h", "--output", "json")
+		require.Equal(t, 0, code, "list failed: stdout=%s, stderr=%s", stdout, stderr)
+		var recent []interface{}
+		require.NoError(t, json.Unmarshal([]byte(stdout), &recent))
+		assert.GreaterOrEqual(t, len(recent), 2)
+
+		stdout, stderr, code = runVSBWithConfig(t, configDir, "email", "list", "--until", "1h", "--output", "json")
+		require.Equal(t, 0, code, "list failed: stdout=%s, stderr=%s", stdout, stderr)
+		var old []interface{}
+		_ = json.Unmarshal([]byte(stdout), &old)
+		assert.Empty(t, old)
+	})
+
+	t.Run("csv output", func(t *testing.T) {
+		stdout, stderr, code := runVSBWithConfig(t, configDir, "email", "list", "--output", "csv")
+		require.Equal(t, 0, code, "list failed: stdout=%s, stderr=%s", stdout, stderr)
+
+		records, err := csv.NewReader(strings.NewReader(stdout)).ReadAll()
+		require.NoError(t, err)
+		require.GreaterOrEqual(t, len(records), 3)
+		assert.Equal(t, []string{"id", "receivedAt", "from", "to", "subject", "links", "attachments", "sizeBytes"}, records[0])
+	})
+
+	t.Run("count only", func(t *testing.T) {
+		stdout, stderr, code := runVSBWithConfig(t, configDir, "email", "list", "--count-only")
+		require.Equal(t, 0, code, "list failed: stdout=%s, stderr=%s", stdout, stderr)
+		count, err := strconv.Atoi(strings.TrimSpace(stdout))
+		require.NoError(t, err)
+		assert.GreaterOrEqual(t, count, 2)
+
+		stdout, stderr, code = runVSBWithConfig(t, configDir, "email", "list", "--count-only", "--since", "1h", "--output", "json")
+		require.Equal(t, 0, code, "list failed: stdout=%s, stderr=%s", stdout, stderr)
+		var result struct {
+			Count int `json:"count"`
+		}
+		require.NoError(t, json.Unmarshal([]byte(stdout), &result))
+		assert.Equal(t, count, result.Count)
+
+		stdout, _, code = runVSBWithConfig(t, configDir, "email", "list", "--count-only", "--until", "1h")
+		require.Equal(t, 0, code)
+		assert.Equal(t, "0", strings.TrimSpace(stdout))
+	})
 }
 
 // TestEmailView tests viewing email content.
+// TestEmailListContentFilters tests --has-links and --has-attachment.
+func TestEmailListContentFilters(t *testing.T) {
+	skipIfNoSMTP(t)
+	configDir := t.TempDir()
+
+	stdout, _, code := runVSBWithConfig(t, configDir, "inbox", "create", "--output", "json")
+	require.Equal(t, 0, code)
+	var createResult struct {
+		Email string `json:"email"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(stdout), &createResult))
+	inboxEmail := createResult.Email
+	t.Cleanup(func() {
+		runVSBWithConfig(t, configDir, "inbox", "delete", inboxEmail)
+	})
+
+	sendTestEmail(t, inboxEmail, "Plain", "No links in here")
+	sendTestEmail(t, inboxEmail, "With link", "Verify at https://example.com/verify?token=abc")
+	sendTestEmailWithAttachment(t, inboxEmail, "With attachment", "See attached", "report.txt", []byte("report"))
+	time.Sleep(2 * time.Second)
+
+	subjects := func(args ...string) []string {
+		t.Helper()
+		args = append([]string{"email", "list", "--output", "json"}, args...)
+		stdout, stderr, code := runVSBWithConfig(t, configDir, args...)
+		require.Equal(t, 0, code, "list failed: stdout=%s, stderr=%s", stdout, stderr)
+		var result []struct {
+			Subject string `json:"subject"`
+		}
+		require.NoError(t, json.Unmarshal([]byte(stdout), &result))
+		var out []string
+		for _, e := range result {
+			out = append(out, e.Subject)
+		}
+		return out
+	}
+
+	assert.Len(t, subjects(), 3)
+	assert.Equal(t, []string{"With link"}, subjects("--has-links"))
+	assert.Equal(t, []string{"With attachment"}, subjects("--has-attachment"))
+	assert.Equal(t, []string{"With link"}, subjects("--has-links", "--since", "1h"))
+	assert.Empty(t, subjects("--has-links", "--until", "1h"))
+
+	stdout, stderr, code := runVSBWithConfig(t, configDir, "email", "list", "--has-links", "--count-only")
+	require.Equal(t, 0, code, "list failed: stderr=%s", stderr)
+	assert.Equal(t, "1", strings.TrimSpace(stdout))
+}
+
+func TestEmailListSenderDomain(t *testing.T) {
+	skipIfNoSMTP(t)
+	configDir := t.TempDir()
+
+	stdout, _, code := runVSBWithConfig(t, configDir, "inbox", "create", "--output", "json")
+	require.Equal(t, 0, code)
+	var createResult struct {
+		Email string `json:"email"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(stdout), &createResult))
+	inboxEmail := createResult.Email
+	t.Cleanup(func() {
+		runVSBWithConfig(t, configDir, "inbox", "delete", inboxEmail)
+	})
+
+	sendTestEmailFrom(t, "noreply@github.com", inboxEmail, "From GitHub", "Hello")
+	sendTestEmailFrom(t, "Alerts <alerts@mail.example.org>", inboxEmail, "From Example", "Hello")
+	time.Sleep(2 * time.Second)
+
+	subjects := func(args ...string) []string {
+		t.Helper()
+		args = append([]string{"email", "list", "--output", "json"}, args...)
+		stdout, stderr, code := runVSBWithConfig(t, configDir, args...)
+		require.Equal(t, 0, code, "list failed: stdout=%s, stderr=%s", stdout, stderr)
+		var result []struct {
+			Subject string `json:"subject"`
+		}
+		require.NoError(t, json.Unmarshal([]byte(stdout), &result))
+		var out []string
+		for _, e := range result {
+			out = append(out, e.Subject)
+		}
+		return out
+	}
+
+	assert.Equal(t, []string{"From GitHub"}, subjects("--sender-domain", "GitHub.com"))
+	assert.ElementsMatch(t, []string{"From GitHub", "From Example"}, subjects("--sender-domain", "github.com,mail.example.org"))
+	assert.Empty(t, subjects("--sender-domain", "example.org"))
+	assert.Equal(t, []string{"From Example"}, subjects("--sender-domain-regex", `(^|\.)example\.org$`))
+
+	stdout, stderr, code := runVSBWithConfig(t, configDir, "email", "list", "--sender-domain", "github.com", "--count-only")
+	require.Equal(t, 0, code, "list failed: stderr=%s", stderr)
+	assert.Equal(t, "1", strings.TrimSpace(stdout))
+}
+
 func TestEmailView(t *testing.T) {
 	skipIfNoSMTP(t)
 	configDir := t.TempDir()
@@ -184,6 +325,126 @@ func TestEmailView(t *testing.T) {
 			t.Log("Warning: --raw flag returned empty output")
 		}
 	})
+
+	t.Run("view raw headers only", func(t *testing.T) {
+		stdout, stderr, code := runVSBWithConfig(t, configDir, "email", "view", "--raw", "--headers-only")
+		require.Equal(t, 0, code, "view --raw --headers-only failed: stdout=%s, stderr=%s", stdout, stderr)
+
+		assert.Contains(t, stdout, "Subject: "+testSubject)
+		assert.NotContains(t, stdout, testBody)
+	})
+
+	t.Run("headers-only requires raw", func(t *testing.T) {
+		_, stderr, code := runVSBWithConfig(t, configDir, "email", "view", "--headers-only")
+		assert.NotEqual(t, 0, code)
+		assert.Contains(t, stderr, "--headers-only requires --raw")
+	})
+}
+
+// TestEmailViewParts tests listing and dumping MIME parts.
+func TestEmailViewParts(t *testing.T) {
+	skipIfNoSMTP(t)
+	configDir := t.TempDir()
+
+	stdout, _, code := runVSBWithConfig(t, configDir, "inbox", "create", "--output", "json")
+	require.Equal(t, 0, code)
+	var createResult struct {
+		Email string `json:"email"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(stdout), &createResult))
+	inboxEmail := createResult.Email
+	t.Cleanup(func() {
+		runVSBWithConfig(t, configDir, "inbox", "delete", inboxEmail)
+	})
+
+	sendTestEmailWithAttachment(t, inboxEmail, "Parts Test", "Body text", "notes.txt", []byte("attached notes"))
+	time.Sleep(2 * time.Second)
+
+	stdout, stderr, code := runVSBWithConfig(t, configDir, "email", "view", "--list-parts")
+	require.Equal(t, 0, code, "view --list-parts failed: stderr=%s", stderr)
+	assert.Contains(t, stdout, "multipart/mixed")
+	assert.Contains(t, stdout, "text/plain")
+	assert.Contains(t, stdout, "notes.txt")
+
+	stdout, stderr, code = runVSBWithConfig(t, configDir, "email", "view", "--part", "2")
+	require.Equal(t, 0, code, "view --part failed: stderr=%s", stderr)
+	assert.Equal(t, "attached notes", strings.TrimSpace(stdout))
+
+	_, stderr, code = runVSBWithConfig(t, configDir, "email", "view", "--part", "9")
+	assert.NotEqual(t, 0, code)
+	assert.Contains(t, stderr, "part 9 not found")
+}
+
+// TestEmailViewDecodeBase64 tests decoding a base64-encoded body.
+func TestEmailViewDecodeBase64(t *testing.T) {
+	skipIfNoSMTP(t)
+	configDir := t.TempDir()
+
+	stdout, _, code := runVSBWithConfig(t, configDir, "inbox", "create", "--output", "json")
+	require.Equal(t, 0, code)
+
+	var createResult struct {
+		Email string `json:"email"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(stdout), &createResult))
+	inboxEmail := createResult.Email
+
+	t.Cleanup(func() {
+		runVSBWithConfig(t, configDir, "inbox", "delete", inboxEmail)
+	})
+
+	plainBody := "This body was sent base64-encoded."
+	sendTestEmail(t, inboxEmail, "Base64 Body Test", base64.StdEncoding.EncodeToString([]byte(plainBody)))
+
+	time.Sleep(2 * time.Second)
+
+	t.Run("without flag shows encoded body", func(t *testing.T) {
+		stdout, stderr, code := runVSBWithConfig(t, configDir, "email", "view", "--text", "--no-wrap")
+		require.Equal(t, 0, code, "view failed: stdout=%s, stderr=%s", stdout, stderr)
+		assert.NotContains(t, stdout, plainBody)
+	})
+
+	t.Run("with flag shows decoded body", func(t *testing.T) {
+		stdout, stderr, code := runVSBWithConfig(t, configDir, "email", "view", "--text", "--decode-base64")
+		require.Equal(t, 0, code, "view failed: stdout=%s, stderr=%s", stdout, stderr)
+		assert.Contains(t, stdout, plainBody)
+	})
+}
+
+// TestEmailViewPreview tests rendering HTML in the terminal with w3m/lynx.
+func TestEmailViewPreview(t *testing.T) {
+	skipIfNoSMTP(t)
+	if _, err := exec.LookPath("w3m"); err != nil {
+		if _, err := exec.LookPath("lynx"); err != nil {
+			t.Skip("w3m or lynx required")
+		}
+	}
+	configDir := t.TempDir()
+
+	stdout, _, code := runVSBWithConfig(t, configDir, "inbox", "create", "--output", "json")
+	require.Equal(t, 0, code)
+
+	var createResult struct {
+		Email string `json:"email"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(stdout), &createResult))
+	inboxEmail := createResult.Email
+
+	t.Cleanup(func() {
+		runVSBWithConfig(t, configDir, "inbox", "delete", inboxEmail)
+	})
+
+	sendTestHTMLEmail(t, inboxEmail, "Preview Test",
+		"Plain text fallback",
+		"<html><body><h1>Rendered Heading</h1><p>Rendered <b>paragraph</b></p></body></html>")
+
+	time.Sleep(2 * time.Second)
+
+	stdout, stderr, code := runVSBWithConfig(t, configDir, "email", "view", "--preview")
+	require.Equal(t, 0, code, "view failed: stdout=%s, stderr=%s", stdout, stderr)
+	assert.Contains(t, stdout, "Rendered Heading")
+	assert.Contains(t, stdout, "Rendered paragraph")
+	assert.NotContains(t, stdout, "<h1>")
 }
 
 // TestEmailAudit tests email security auditing.
@@ -244,6 +505,362 @@ func TestEmailAudit(t *testing.T) {
 	})
 }
 
+// TestEmailSpamCheck tests heuristic spam checks.
+func TestEmailSpamCheck(t *testing.T) {
+	skipIfNoSMTP(t)
+	configDir := t.TempDir()
+
+	// Create inbox
+	stdout, _, code := runVSBWithConfig(t, configDir, "inbox", "create", "--output", "json")
+	require.Equal(t, 0, code)
+
+	var createResult struct {
+		Email string `json:"email"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(stdout), &createResult))
+	inboxEmail := createResult.Email
+
+	t.Cleanup(func() {
+		runVSBWithConfig(t, configDir, "inbox", "delete", inboxEmail)
+	})
+
+	sendTestEmail(t, inboxEmail, "FREE PRIZE WAITING!!", "Claim it now!!!")
+	time.Sleep(2 * time.Second)
+
+	stdout, stderr, code := runVSBWithConfig(t, configDir, "email", "spam-check", "--output", "json")
+	require.Equal(t, 0, code, "spam-check failed: stdout=%s, stderr=%s", stdout, stderr)
+
+	var result struct {
+		RiskLevel      string   `json:"riskLevel"`
+		TriggeredRules []string `json:"triggeredRules"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(stdout), &result))
+	assert.Contains(t, result.TriggeredRules, "allCaps")
+	assert.Contains(t, result.TriggeredRules, "excessiveExclamation")
+	assert.Contains(t, []string{"medium", "high"}, result.RiskLevel)
+}
+
+// TestEmailParseHeaders tests the grouped header display.
+func TestEmailParseHeaders(t *testing.T) {
+	skipIfNoSMTP(t)
+	configDir := t.TempDir()
+
+	// Create inbox
+	stdout, _, code := runVSBWithConfig(t, configDir, "inbox", "create", "--output", "json")
+	require.Equal(t, 0, code)
+
+	var createResult struct {
+		Email string `json:"email"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(stdout), &createResult))
+	inboxEmail := createResult.Email
+
+	t.Cleanup(func() {
+		runVSBWithConfig(t, configDir, "inbox", "delete", inboxEmail)
+	})
+
+	sendTestEmail(t, inboxEmail, "Header Test", "Body")
+	time.Sleep(2 * time.Second)
+
+	t.Run("json output", func(t *testing.T) {
+		stdout, stderr, code := runVSBWithConfig(t, configDir, "email", "parse-headers", "--output", "json")
+		require.Equal(t, 0, code, "parse-headers failed: stdout=%s, stderr=%s", stdout, stderr)
+
+		var headers map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(stdout), &headers))
+		assert.Equal(t, "Header Test", headers["Subject"])
+		assert.Contains(t, headers, "Received")
+	})
+
+	t.Run("single header", func(t *testing.T) {
+		stdout, stderr, code := runVSBWithConfig(t, configDir, "email", "parse-headers", "--header", "subject")
+		require.Equal(t, 0, code, "stderr=%s", stderr)
+		assert.Equal(t, "Header Test", strings.TrimSpace(stdout))
+	})
+
+	t.Run("missing header", func(t *testing.T) {
+		_, stderr, code := runVSBWithConfig(t, configDir, "email", "parse-headers", "--header", "X-Does-Not-Exist")
+		assert.NotEqual(t, 0, code)
+		assert.Contains(t, stderr, "header not found")
+	})
+}
+
+// TestEmailThread tests grouping a reply chain into a thread.
+func TestEmailThread(t *testing.T) {
+	skipIfNoSMTP(t)
+	configDir := t.TempDir()
+
+	stdout, _, code := runVSBWithConfig(t, configDir, "inbox", "create", "--output", "json")
+	require.Equal(t, 0, code)
+
+	var createResult struct {
+		Email string `json:"email"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(stdout), &createResult))
+	inboxEmail := createResult.Email
+
+	t.Cleanup(func() {
+		runVSBWithConfig(t, configDir, "inbox", "delete", inboxEmail)
+	})
+
+	sendTestMessage(t, mailfile.Message{
+		To: inboxEmail, Subject: "Order placed", Text: "Body",
+		MessageID: "<order-1@e2e.test>",
+	})
+	time.Sleep(time.Second)
+	sendTestMessage(t, mailfile.Message{
+		To: inboxEmail, Subject: "Re: Order placed", Text: "Body",
+		MessageID:  "<order-2@e2e.test>",
+		InReplyTo:  "<order-1@e2e.test>",
+		References: "<order-1@e2e.test>",
+	})
+	time.Sleep(time.Second)
+	sendTestMessage(t, mailfile.Message{
+		To: inboxEmail, Subject: "Re: Re: Order placed", Text: "Body",
+		MessageID:  "<order-3@e2e.test>",
+		InReplyTo:  "<order-2@e2e.test>",
+		References: "<order-1@e2e.test> <order-2@e2e.test>",
+	})
+	sendTestEmail(t, inboxEmail, "Unrelated", "Body")
+	time.Sleep(2 * time.Second)
+
+	t.Run("json tree", func(t *testing.T) {
+		stdout, stderr, code := runVSBWithConfig(t, configDir, "email", "thread", "--output", "json")
+		require.Equal(t, 0, code, "thread failed: stdout=%s, stderr=%s", stdout, stderr)
+
+		type node struct {
+			Subject  string `json:"subject"`
+			Children []node `json:"children"`
+		}
+		var roots []node
+		require.NoError(t, json.Unmarshal([]byte(stdout), &roots))
+		require.Len(t, roots, 2)
+
+		var chain *node
+		for i := range roots {
+			if roots[i].Subject == "Order placed" {
+				chain = &roots[i]
+			}
+		}
+		require.NotNil(t, chain, "reply chain root not found: %s", stdout)
+		require.Len(t, chain.Children, 1)
+		assert.Equal(t, "Re: Order placed", chain.Children[0].Subject)
+		require.Len(t, chain.Children[0].Children, 1)
+		assert.Equal(t, "Re: Re: Order placed", chain.Children[0].Children[0].Subject)
+	})
+
+	t.Run("flatten", func(t *testing.T) {
+		stdout, stderr, code := runVSBWithConfig(t, configDir, "email", "thread", "--flatten", "--output", "json")
+		require.Equal(t, 0, code, "stderr=%s", stderr)
+
+		var entries []struct {
+			Subject string `json:"subject"`
+			Depth   int    `json:"depth"`
+		}
+		require.NoError(t, json.Unmarshal([]byte(stdout), &entries))
+		require.Len(t, entries, 4)
+
+		depths := make(map[string]int)
+		for _, e := range entries {
+			depths[e.Subject] = e.Depth
+		}
+		assert.Equal(t, 0, depths["Order placed"])
+		assert.Equal(t, 1, depths["Re: Order placed"])
+		assert.Equal(t, 2, depths["Re: Re: Order placed"])
+		assert.Equal(t, 0, depths["Unrelated"])
+	})
+
+	t.Run("tree output", func(t *testing.T) {
+		stdout, stderr, code := runVSBWithConfig(t, configDir, "email", "thread")
+		require.Equal(t, 0, code, "stderr=%s", stderr)
+		assert.Contains(t, stdout, "└─")
+		assert.Contains(t, stdout, "4 email(s) in 2 thread(s)")
+	})
+}
+
+// TestEmailDownload tests saving emails as EML and mbox files.
+func TestEmailDownload(t *testing.T) {
+	skipIfNoSMTP(t)
+	configDir := t.TempDir()
+
+	stdout, _, code := runVSBWithConfig(t, configDir, "inbox", "create", "--output", "json")
+	require.Equal(t, 0, code)
+
+	var createResult struct {
+		Email string `json:"email"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(stdout), &createResult))
+	inboxEmail := createResult.Email
+
+	t.Cleanup(func() {
+		runVSBWithConfig(t, configDir, "inbox", "delete", inboxEmail)
+	})
+
+	sendTestEmail(t, inboxEmail, "Download Test", "Body to save")
+	time.Sleep(2 * time.Second)
+
+	t.Run("eml with default filename", func(t *testing.T) {
+		dir := t.TempDir()
+		stdout, stderr, code := runVSBWithConfig(t, configDir, "email", "download", "--dir", dir)
+		require.Equal(t, 0, code, "download failed: stdout=%s, stderr=%s", stdout, stderr)
+
+		path := filepath.Join(dir, "Download_Test.eml")
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Contains(t, string(data), "Subject: Download Test\r\n")
+		assert.Contains(t, string(data), "Body to save")
+
+		info, err := os.Stat(path)
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	})
+
+	t.Run("all as mbox", func(t *testing.T) {
+		out := filepath.Join(t.TempDir(), "inbox.mbox")
+		stdout, stderr, code := runVSBWithConfig(t, configDir, "email", "download", "--all", "--format", "mbox", "--out", out, "--output", "json")
+		require.Equal(t, 0, code, "stderr=%s", stderr)
+
+		var result []map[string]string
+		require.NoError(t, json.Unmarshal([]byte(stdout), &result))
+		require.Len(t, result, 1)
+		assert.Equal(t, out, result[0]["path"])
+
+		data, err := os.ReadFile(out)
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(string(data), "From "))
+		assert.Contains(t, string(data), "Subject: Download Test\n")
+	})
+}
+
+// TestEmailArchive tests exporting an inbox to mbox and JSON archives.
+func TestEmailArchive(t *testing.T) {
+	skipIfNoSMTP(t)
+	configDir := t.TempDir()
+
+	stdout, _, code := runVSBWithConfig(t, configDir, "inbox", "create", "--output", "json")
+	require.Equal(t, 0, code)
+
+	var createResult struct {
+		Email string `json:"email"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(stdout), &createResult))
+	inboxEmail := createResult.Email
+
+	t.Cleanup(func() {
+		runVSBWithConfig(t, configDir, "inbox", "delete", inboxEmail)
+	})
+
+	sendTestEmail(t, inboxEmail, "Archive Test", "From the first line of the body")
+	time.Sleep(2 * time.Second)
+
+	t.Run("mbox", func(t *testing.T) {
+		out := filepath.Join(t.TempDir(), "archive.mbox")
+		stdout, stderr, code := runVSBWithConfig(t, configDir, "email", "archive", "--out", out, "--output", "json")
+		require.Equal(t, 0, code, "stderr=%s", stderr)
+
+		var summary map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(stdout), &summary))
+		assert.Equal(t, float64(1), summary["emails"])
+		assert.Equal(t, out, summary["path"])
+
+		data, err := os.ReadFile(out)
+		require.NoError(t, err)
+		assert.Equal(t, float64(len(data)), summary["bytes"])
+		assert.True(t, strings.HasPrefix(string(data), "From "))
+		assert.Contains(t, string(data), "Subject: Archive Test\n")
+		assert.Contains(t, string(data), "\n>From the first line")
+
+		info, err := os.Stat(out)
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+		_, stderr, code = runVSBWithConfig(t, configDir, "email", "archive", "--out", out)
+		assert.NotEqual(t, 0, code)
+		assert.Contains(t, stderr, "file already exists")
+	})
+
+	t.Run("json", func(t *testing.T) {
+		out := filepath.Join(t.TempDir(), "archive.json")
+		_, stderr, code := runVSBWithConfig(t, configDir, "email", "archive", "--format", "json", "--out", out)
+		require.Equal(t, 0, code, "stderr=%s", stderr)
+
+		data, err := os.ReadFile(out)
+		require.NoError(t, err)
+		var emails []map[string]interface{}
+		require.NoError(t, json.Unmarshal(data, &emails))
+		require.Len(t, emails, 1)
+		assert.Equal(t, "Archive Test", emails[0]["subject"])
+	})
+
+	t.Run("since excludes older emails", func(t *testing.T) {
+		out := filepath.Join(t.TempDir(), "archive.json")
+		stdout, stderr, code := runVSBWithConfig(t, configDir, "email", "archive", "--format", "json",
+			"--since", time.Now().Add(time.Hour).Format(time.RFC3339), "--out", out, "--output", "json")
+		require.Equal(t, 0, code, "stderr=%s", stderr)
+
+		var summary map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(stdout), &summary))
+		assert.Equal(t, float64(0), summary["emails"])
+	})
+}
+
+// TestEmailReadState tests local read/unread tracking.
+func TestEmailReadState(t *testing.T) {
+	skipIfNoSMTP(t)
+	configDir := t.TempDir()
+
+	stdout, _, code := runVSBWithConfig(t, configDir, "inbox", "create", "--output", "json")
+	require.Equal(t, 0, code)
+
+	var createResult struct {
+		Email string `json:"email"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(stdout), &createResult))
+	inboxEmail := createResult.Email
+
+	t.Cleanup(func() {
+		runVSBWithConfig(t, configDir, "inbox", "delete", inboxEmail)
+	})
+
+	sendTestEmail(t, inboxEmail, "Read State Test", "Body")
+	time.Sleep(2 * time.Second)
+
+	type listItem struct {
+		ID   string `json:"id"`
+		Read bool   `json:"read"`
+	}
+
+	unread := runVSBJSONWithConfig[[]listItem](t, configDir, "email", "list", "--unread")
+	require.Len(t, unread, 1)
+	assert.False(t, unread[0].Read)
+	emailID := unread[0].ID
+
+	// Viewing marks the email read
+	_, stderr, code := runVSBWithConfig(t, configDir, "email", "view", "-t", emailID)
+	require.Equal(t, 0, code, "stderr=%s", stderr)
+
+	all := runVSBJSONWithConfig[[]listItem](t, configDir, "email", "list")
+	require.Len(t, all, 1)
+	assert.True(t, all[0].Read)
+
+	stdout, _, code = runVSBWithConfig(t, configDir, "email", "list", "--unread", "--count-only")
+	require.Equal(t, 0, code)
+	assert.Equal(t, "0", strings.TrimSpace(stdout))
+
+	info := runVSBJSONWithConfig[map[string]interface{}](t, configDir, "inbox", "info")
+	assert.Equal(t, float64(0), info["unreadCount"])
+
+	_, stderr, code = runVSBWithConfig(t, configDir, "email", "mark-unread", emailID)
+	require.Equal(t, 0, code, "stderr=%s", stderr)
+	unread = runVSBJSONWithConfig[[]listItem](t, configDir, "email", "list", "--unread")
+	assert.Len(t, unread, 1)
+
+	_, stderr, code = runVSBWithConfig(t, configDir, "email", "mark-read", "--all")
+	require.Equal(t, 0, code, "stderr=%s", stderr)
+	unread = runVSBJSONWithConfig[[]listItem](t, configDir, "email", "list", "--unread")
+	assert.Empty(t, unread)
+}
+
 // TestEmailURL tests URL extraction from emails.
 func TestEmailURL(t *testing.T) {
 	skipIfNoSMTP(t)
@@ -293,6 +910,32 @@ func TestEmailURL(t *testing.T) {
 		assert.True(t, foundVerify, "should find verify URL")
 	})
 
+	t.Run("group URLs by domain", func(t *testing.T) {
+		htmlBody := `<html><body>
+			<p><a href="https://example.com/verify?token=abc123">Verify</a></p>
+			<p><a href="https://example.com/help">Help</a></p>
+			<p><a href="https://example.org/unsubscribe">Unsubscribe</a></p>
+		</body></html>`
+
+		sendTestHTMLEmail(t, inboxEmail, "Email with Domains", "See the links in the HTML part.", htmlBody)
+		time.Sleep(2 * time.Second)
+
+		stdout, stderr, code := runVSBWithConfig(t, configDir, "email", "url", "--group-by-domain", "--output", "json")
+		require.Equal(t, 0, code, "url failed: stdout=%s, stderr=%s", stdout, stderr)
+
+		var groups map[string][]string
+		require.NoError(t, json.Unmarshal([]byte(stdout), &groups))
+		assert.Len(t, groups["example.com"], 2)
+		assert.Len(t, groups["example.org"], 1)
+
+		stdout, stderr, code = runVSBWithConfig(t, configDir, "email", "url", "--domain-filter", "example.org", "--output", "json")
+		require.Equal(t, 0, code, "url failed: stdout=%s, stderr=%s", stdout, stderr)
+
+		var links []string
+		require.NoError(t, json.Unmarshal([]byte(stdout), &links))
+		assert.Equal(t, []string{"https://example.org/unsubscribe"}, links)
+	})
+
 	t.Run("no URLs in email", func(t *testing.T) {
 		// Send plain email without links
 		sendTestEmail(t, inboxEmail, "No Links Email", "This email has no links at all.")
@@ -327,8 +970,7 @@ func TestEmailAttachment(t *testing.T) {
 
 	t.Run("list attachments", func(t *testing.T) {
 		// Send email with attachment
-		attachmentContent := base64.StdEncoding.EncodeToString([]byte("Hello, this is a test file content!"))
-		sendTestEmailWithAttachment(t, inboxEmail, "Email with Attachment", "See attached file.", "test.txt", attachmentContent)
+		sendTestEmailWithAttachment(t, inboxEmail, "Email with Attachment", "See attached file.", "test.txt", []byte("Hello, this is a test file content!"))
 		time.Sleep(2 * time.Second)
 
 		stdout, stderr, code := runVSBWithConfig(t, configDir, "email", "attachment", "--output", "json")
@@ -381,6 +1023,41 @@ func TestEmailAttachment(t *testing.T) {
 		assert.NotEmpty(t, files)
 	})
 
+	t.Run("extract attachment to stdout", func(t *testing.T) {
+		expected := "Hello, this is a test file content!"
+
+		stdout, stderr, code := runVSBWithConfig(t, configDir, "email", "attachment", "--extract-to-stdout", "--by-name", "test.txt")
+		require.Equal(t, 0, code, "attachment --extract-to-stdout failed: stderr=%s", stderr)
+		assert.Equal(t, expected, stdout)
+
+		// Pipe through wc -c
+		cmd := exec.Command("sh", "-c", `"$VSB" email attachment --extract-to-stdout --index 1 | wc -c`)
+		cmd.Env = append(os.Environ(),
+			"VSB="+vsbBinPath,
+			"VSB_API_KEY="+apiKey,
+			"VSB_BASE_URL="+baseURL,
+			"VSB_CONFIG_DIR="+configDir,
+		)
+		out, err := cmd.Output()
+		require.NoError(t, err)
+		assert.Equal(t, strconv.Itoa(len(expected)), strings.TrimSpace(string(out)))
+	})
+
+	t.Run("stream attachment by index", func(t *testing.T) {
+		stdout, stderr, code := runVSBWithConfig(t, configDir, "email", "attachment", "--stdout", "1")
+		require.Equal(t, 0, code, "attachment --stdout failed: stderr=%s", stderr)
+		assert.Equal(t, "Hello, this is a test file content!", stdout)
+
+		_, stderr, code = runVSBWithConfig(t, configDir, "email", "attachment", "--stdout", "9")
+		assert.NotEqual(t, 0, code)
+		assert.Contains(t, stderr, "out of range")
+	})
+
+	t.Run("extract attachment out of range", func(t *testing.T) {
+		_, _, code := runVSBWithConfig(t, configDir, "email", "attachment", "--extract-to-stdout", "--index", "9")
+		assert.NotEqual(t, 0, code)
+	})
+
 	t.Run("no attachments", func(t *testing.T) {
 		// Send email without attachments
 		sendTestEmail(t, inboxEmail, "No Attachments", "This email has no attachments.")
@@ -395,6 +1072,65 @@ func TestEmailAttachment(t *testing.T) {
 }
 
 // TestEmailDelete tests deleting emails.
+func TestEmailRecheck(t *testing.T) {
+	skipIfNoSMTP(t)
+	configDir := t.TempDir()
+
+	stdout, _, code := runVSBWithConfig(t, configDir, "inbox", "create", "--output", "json")
+	require.Equal(t, 0, code)
+	var createResult struct {
+		Email string `json:"email"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(stdout), &createResult))
+	inboxEmail := createResult.Email
+	t.Cleanup(func() {
+		runVSBWithConfig(t, configDir, "inbox", "delete", inboxEmail)
+	})
+
+	sendTestEmail(t, inboxEmail, "Recheck Test", "Fetch me again.")
+	time.Sleep(2 * time.Second)
+
+	stdout, _, code = runVSBWithConfig(t, configDir, "email", "list", "--output", "json")
+	require.Equal(t, 0, code)
+	var emails []struct {
+		ID string `json:"id"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(stdout), &emails))
+	require.Len(t, emails, 1)
+
+	type recheckResult struct {
+		ID               string `json:"id"`
+		Subject          string `json:"subject"`
+		ProcessingStatus string `json:"processingStatus"`
+	}
+
+	t.Run("prints the email as JSON", func(t *testing.T) {
+		stdout, stderr, code := runVSBWithConfig(t, configDir, "email", "recheck", emails[0].ID)
+		require.Equal(t, 0, code, "recheck failed: stdout=%s, stderr=%s", stdout, stderr)
+
+		var result recheckResult
+		require.NoError(t, json.Unmarshal([]byte(stdout), &result))
+		assert.Equal(t, emails[0].ID, result.ID)
+		assert.Equal(t, "Recheck Test", result.Subject)
+		assert.Contains(t, []string{"pending", "complete"}, result.ProcessingStatus)
+	})
+
+	t.Run("wait for complete", func(t *testing.T) {
+		stdout, stderr, code := runVSBWithConfig(t, configDir, "email", "recheck", emails[0].ID, "--wait-for-complete", "--timeout", "30s")
+		require.Equal(t, 0, code, "recheck failed: stdout=%s, stderr=%s", stdout, stderr)
+
+		var result recheckResult
+		require.NoError(t, json.Unmarshal([]byte(stdout), &result))
+		assert.Equal(t, "complete", result.ProcessingStatus)
+	})
+
+	t.Run("unknown email", func(t *testing.T) {
+		_, stderr, code := runVSBWithConfig(t, configDir, "email", "recheck", "does-not-exist")
+		assert.NotEqual(t, 0, code)
+		assert.Contains(t, stderr, "failed to get email does-not-exist")
+	})
+}
+
 func TestEmailDelete(t *testing.T) {
 	skipIfNoSMTP(t)
 	configDir := t.TempDir()
@@ -661,6 +1397,90 @@ func TestEmailWorkflow(t *testing.T) {
 	assert.False(t, found, "deleted email should not be in list")
 }
 
+// TestDevSend tests sending test emails with 'vsb dev send'.
+func TestDevSend(t *testing.T) {
+	skipIfNoSMTP(t)
+	configDir := t.TempDir()
+
+	stdout, _, code := runVSBWithConfig(t, configDir, "inbox", "create", "--output", "json")
+	require.Equal(t, 0, code)
+
+	var createResult struct {
+		Email string `json:"email"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(stdout), &createResult))
+	inboxEmail := createResult.Email
+
+	t.Cleanup(func() {
+		runVSBWithConfig(t, configDir, "inbox", "delete", inboxEmail)
+	})
+
+	t.Run("hidden from help", func(t *testing.T) {
+		stdout, _, code := runVSBWithConfig(t, configDir, "--help")
+		require.Equal(t, 0, code)
+		assert.NotContains(t, stdout, "dev ")
+	})
+
+	t.Run("body from stdin with HTML and attachments to active inbox", func(t *testing.T) {
+		dir := t.TempDir()
+		htmlPath := filepath.Join(dir, "body.html")
+		require.NoError(t, os.WriteFile(htmlPath, []byte("<p>Dev HTML body</p>"), 0600))
+		attachA := filepath.Join(dir, "notes.txt")
+		require.NoError(t, os.WriteFile(attachA, []byte("first attachment"), 0600))
+		attachB := filepath.Join(dir, "data.bin")
+		require.NoError(t, os.WriteFile(attachB, []byte{0, 1, 2, 0xff}, 0600))
+
+		stdout, stderr, code := runVSBWithInput(t, configDir, "Dev text body\n",
+			"dev", "send", "--subject", "Dev Send", "--text", "-",
+			"--html", htmlPath, "--attach", attachA, "--attach", attachB)
+		require.Equal(t, 0, code, "dev send failed: stdout=%s, stderr=%s", stdout, stderr)
+		assert.Contains(t, stdout, inboxEmail)
+
+		time.Sleep(2 * time.Second)
+
+		stdout, stderr, code = runVSBWithConfig(t, configDir, "email", "view", "--output", "json")
+		require.Equal(t, 0, code, "view failed: stdout=%s, stderr=%s", stdout, stderr)
+
+		var email struct {
+			Subject     string `json:"subject"`
+			Text        string `json:"text"`
+			HTML        string `json:"html"`
+			Attachments []struct {
+				Filename string `json:"filename"`
+			} `json:"attachments"`
+		}
+		require.NoError(t, json.Unmarshal([]byte(stdout), &email))
+		assert.Equal(t, "Dev Send", email.Subject)
+		assert.Contains(t, email.Text, "Dev text body")
+		assert.Contains(t, email.HTML, "Dev HTML body")
+		require.Len(t, email.Attachments, 2)
+		assert.Equal(t, "notes.txt", email.Attachments[0].Filename)
+		assert.Equal(t, "data.bin", email.Attachments[1].Filename)
+	})
+
+	t.Run("count", func(t *testing.T) {
+		stdout, stderr, code := runVSBWithConfig(t, configDir,
+			"dev", "send", "--to", inboxEmail, "--subject", "Burst", "--count", "3", "--interval", "100ms", "--output", "json")
+		require.Equal(t, 0, code, "dev send failed: stdout=%s, stderr=%s", stdout, stderr)
+
+		var result struct {
+			Sent       int      `json:"sent"`
+			MessageIDs []string `json:"messageIds"`
+		}
+		require.NoError(t, json.Unmarshal([]byte(stdout), &result))
+		assert.Equal(t, 3, result.Sent)
+		assert.Len(t, result.MessageIDs, 3)
+
+		time.Sleep(2 * time.Second)
+
+		stdout, _, code = runVSBWithConfig(t, configDir, "email", "list", "--output", "json")
+		require.Equal(t, 0, code)
+		for i := 1; i <= 3; i++ {
+			assert.Contains(t, stdout, "Burst ("+strconv.Itoa(i)+"/3)")
+		}
+	})
+}
+
 // downloadDirHasFile checks if a directory contains a file with the given name.
 func downloadDirHasFile(dir, filename string) bool {
 	files, err := os.ReadDir(dir)
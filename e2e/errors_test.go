@@ -586,16 +586,19 @@ func TestGlobalErrors(t *testing.T) {
 		})
 
 		// Try invalid output format
-		_, stderr, code := runVSBWithConfig(t, configDir, "inbox", "list", "--output", "invalid")
-
-		// May succeed with default or fail
-		if code != 0 {
-			assert.True(t,
-				strings.Contains(stderr, "output") ||
-					strings.Contains(stderr, "format") ||
-					strings.Contains(stderr, "invalid"),
-				"error should indicate invalid output format, got: %s", stderr)
-		}
+		stdout, stderr, code := runVSBWithConfig(t, configDir, "inbox", "list", "--output", "invalid")
+		assert.NotEqual(t, 0, code)
+		assert.Empty(t, stdout, "nothing should run before validation")
+		assert.Contains(t, stderr, "invalid output format 'invalid', valid: pretty, json")
+
+		// Formats other commands support are rejected the same way
+		_, stderr, code = runVSBWithConfig(t, configDir, "inbox", "list", "--output", "csv")
+		assert.NotEqual(t, 0, code)
+		assert.Contains(t, stderr, "invalid output format 'csv', valid: pretty, json")
+
+		_, stderr, code = runVSBWithConfig(t, configDir, "email", "list", "--output", "yamml")
+		assert.NotEqual(t, 0, code)
+		assert.Contains(t, stderr, "invalid output format 'yamml', valid: pretty, json, csv")
 	})
 
 	t.Run("conflicting flags", func(t *testing.T) {
@@ -627,6 +630,146 @@ func TestGlobalErrors(t *testing.T) {
 	})
 }
 
+// jsonError is the object written to stdout when a command run with
+// --output json fails.
+type jsonError struct {
+	Error struct {
+		Code    string                 `json:"code"`
+		Message string                 `json:"message"`
+		Details map[string]interface{} `json:"details"`
+	} `json:"error"`
+}
+
+// createJSONErrorInbox creates an inbox in configDir, deleted when the test
+// ends.
+func createJSONErrorInbox(t *testing.T, configDir string) string {
+	t.Helper()
+	stdout, _, code := runVSBWithConfig(t, configDir, "inbox", "create", "--output", "json")
+	require.Equal(t, 0, code)
+
+	var result struct {
+		Email string `json:"email"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(stdout), &result))
+	t.Cleanup(func() {
+		runVSBWithConfig(t, configDir, "inbox", "delete", result.Email)
+	})
+	return result.Email
+}
+
+// TestJSONErrors tests that failures with --output json also write a JSON
+// error object to stdout, keeping the text on stderr and the exit code.
+func TestJSONErrors(t *testing.T) {
+	t.Run("inbox not found", func(t *testing.T) {
+		configDir := t.TempDir()
+
+		stdout, stderr, code := runVSBWithConfig(t, configDir, "email", "list",
+			"--inbox", "nonexistent@example.com", "--output", "json")
+		assert.Equal(t, 1, code)
+		assert.Contains(t, stderr, "inbox not found: nonexistent@example.com")
+
+		var result jsonError
+		require.NoError(t, json.Unmarshal([]byte(stdout), &result), "stdout should be JSON, got: %s", stdout)
+		assert.Equal(t, "inbox_not_found", result.Error.Code)
+		assert.Equal(t, "inbox not found: nonexistent@example.com", result.Error.Message)
+		assert.Equal(t, "nonexistent@example.com", result.Error.Details["query"])
+	})
+
+	t.Run("pretty output keeps stdout empty", func(t *testing.T) {
+		configDir := t.TempDir()
+
+		stdout, _, code := runVSBWithConfig(t, configDir, "email", "list", "--inbox", "nonexistent@example.com")
+		assert.Equal(t, 1, code)
+		assert.Empty(t, stdout)
+	})
+
+	t.Run("wait timeout", func(t *testing.T) {
+		skipIfNoSMTP(t)
+		configDir := t.TempDir()
+		inboxEmail := createJSONErrorInbox(t, configDir)
+
+		stdout, stderr, code := runVSBWithConfig(t, configDir, "email", "wait",
+			"--inbox", inboxEmail, "--timeout", "2s", "--output", "json")
+		assert.Equal(t, 1, code)
+		assert.Contains(t, stderr, "timeout waiting for email")
+
+		var result jsonError
+		require.NoError(t, json.Unmarshal([]byte(stdout), &result), "stdout should be JSON, got: %s", stdout)
+		assert.Equal(t, "timeout", result.Error.Code)
+		assert.Equal(t, "2s", result.Error.Details["timeout"])
+	})
+
+	t.Run("wait count timeout ends with the error", func(t *testing.T) {
+		skipIfNoSMTP(t)
+		configDir := t.TempDir()
+		inboxEmail := createJSONErrorInbox(t, configDir)
+		sendTestEmail(t, inboxEmail, "JSON Error Test", "Test body")
+
+		stdout, _, code := runVSBWithConfig(t, configDir, "email", "wait",
+			"--inbox", inboxEmail, "--count", "2", "--timeout", "10s",
+			"--output", "json", "--json-compact")
+		assert.Equal(t, 1, code)
+
+		lines := strings.Split(strings.TrimSpace(stdout), "\n")
+		require.NotEmpty(t, lines)
+		var result jsonError
+		require.NoError(t, json.Unmarshal([]byte(lines[len(lines)-1]), &result), "last line should be JSON, got: %s", stdout)
+		assert.Equal(t, "timeout", result.Error.Code)
+	})
+}
+
+// TestNoAPIKey tests the message shown when no API key is configured and
+// that commands which don't call the API still work.
+func TestNoAPIKey(t *testing.T) {
+	noKey := map[string]string{"VSB_API_KEY": ""}
+
+	t.Run("api command shows setup help", func(t *testing.T) {
+		configDir := t.TempDir()
+
+		_, stderr, code := runVSBWithConfigAndEnv(t, configDir, noKey, "inbox", "create")
+		assert.Equal(t, 3, code)
+		assert.Contains(t, stderr, "No API key configured")
+		assert.Contains(t, stderr, "vsb config set api-key <key>")
+		assert.Contains(t, stderr, "export VSB_API_KEY=<key>")
+		assert.NotContains(t, stderr, "Usage:")
+	})
+
+	t.Run("local commands do not need a key", func(t *testing.T) {
+		configDir := t.TempDir()
+
+		for _, args := range [][]string{
+			{"config", "show"},
+			{"completion", "bash"},
+			{"--version"},
+			{"inbox", "list"},
+		} {
+			_, stderr, code := runVSBWithConfigAndEnv(t, configDir, noKey, args...)
+			assert.Equal(t, 0, code, "vsb %s failed: stderr=%s", strings.Join(args, " "), stderr)
+		}
+	})
+
+	t.Run("import --local does not need a key", func(t *testing.T) {
+		sourceDir := t.TempDir()
+		stdout, _, code := runVSBWithConfig(t, sourceDir, "inbox", "create", "--output", "json")
+		require.Equal(t, 0, code)
+
+		var result struct {
+			Email string `json:"email"`
+		}
+		require.NoError(t, json.Unmarshal([]byte(stdout), &result))
+		t.Cleanup(func() {
+			runVSBWithConfig(t, sourceDir, "inbox", "delete", result.Email)
+		})
+
+		exportPath := filepath.Join(sourceDir, "export.json")
+		_, stderr, code := runVSBWithConfig(t, sourceDir, "export", result.Email, "--out", exportPath)
+		require.Equal(t, 0, code, "export failed: stderr=%s", stderr)
+
+		_, stderr, code = runVSBWithConfigAndEnv(t, t.TempDir(), noKey, "import", exportPath, "--local")
+		assert.Equal(t, 0, code, "import --local failed: stderr=%s", stderr)
+	})
+}
+
 // TestNetworkErrors tests behavior with network issues.
 func TestNetworkErrors(t *testing.T) {
 	t.Run("invalid base URL", func(t *testing.T) {
@@ -5,6 +5,7 @@ package e2e
 import (
 	"encoding/json"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"strings"
 	"testing"
@@ -74,6 +75,23 @@ func TestExport(t *testing.T) {
 		assert.True(t, exported.ExpiresAt.After(time.Now()))
 	})
 
+	t.Run("export JSON output", func(t *testing.T) {
+		exportPath := filepath.Join(t.TempDir(), "json-export.json")
+
+		stdout, stderr, code := runVSBWithConfig(t, configDir, "export", "--out", exportPath, "--output", "json")
+		require.Equal(t, 0, code, "export failed: stderr=%s", stderr)
+
+		var result struct {
+			Path      string `json:"path"`
+			Email     string `json:"email"`
+			ExpiresAt string `json:"expiresAt"`
+		}
+		require.NoError(t, json.Unmarshal([]byte(stdout), &result), "stdout=%s", stdout)
+		assert.Equal(t, exportPath, result.Path)
+		assert.Equal(t, inboxEmail, result.Email)
+		assert.NotEmpty(t, result.ExpiresAt)
+	})
+
 	t.Run("export specific inbox", func(t *testing.T) {
 		exportDir := t.TempDir()
 		exportPath := filepath.Join(exportDir, "specific-export.json")
@@ -141,6 +159,29 @@ func TestExport(t *testing.T) {
 		mode := info.Mode().Perm()
 		assert.Equal(t, os.FileMode(0600), mode, "export file should have 0600 permissions")
 	})
+
+	t.Run("tampered export is rejected", func(t *testing.T) {
+		exportPath := filepath.Join(t.TempDir(), "tampered-export.json")
+
+		_, stderr, code := runVSBWithConfig(t, configDir, "export", "--out", exportPath)
+		require.Equal(t, 0, code, "export failed: stderr=%s", stderr)
+
+		data, err := os.ReadFile(exportPath)
+		require.NoError(t, err)
+		assert.Contains(t, string(data), `"checksum": "sha256:`)
+
+		// Flip the emailAuth flag
+		tampered := strings.Replace(string(data), `"emailAuth": false`, `"emailAuth": true`, 1)
+		if tampered == string(data) {
+			tampered = strings.Replace(string(data), `"emailAuth": true`, `"emailAuth": false`, 1)
+		}
+		require.NotEqual(t, string(data), tampered)
+		require.NoError(t, os.WriteFile(exportPath, []byte(tampered), 0600))
+
+		_, stderr, code = runVSBWithConfig(t, t.TempDir(), "import", "--local", exportPath)
+		assert.NotEqual(t, 0, code)
+		assert.Contains(t, stderr, "export file corrupted or modified")
+	})
 }
 
 // TestImport tests importing inboxes.
@@ -307,8 +348,58 @@ func TestImport(t *testing.T) {
 		require.Equal(t, 0, code)
 
 		// Import with --force while inbox exists (should succeed)
-		_, stderr, code := runVSBWithConfig(t, configDir, "import", "--force", exportPath)
+		stdout, stderr, code := runVSBWithConfig(t, configDir, "import", "--force", exportPath, "--output", "json")
 		require.Equal(t, 0, code, "import --force failed: stderr=%s", stderr)
+
+		var result struct {
+			Email  string `json:"email"`
+			Forced bool   `json:"forced"`
+		}
+		require.NoError(t, json.Unmarshal([]byte(stdout), &result), "stdout=%s", stdout)
+		assert.Equal(t, originalEmail, result.Email)
+		assert.True(t, result.Forced)
+	})
+
+	t.Run("update keeps local state", func(t *testing.T) {
+		configDir := t.TempDir()
+
+		stdout, _, code := runVSBWithConfig(t, configDir, "inbox", "create", "--label-prefix", "upd-", "--output", "json")
+		require.Equal(t, 0, code)
+
+		var createResult struct {
+			Email string `json:"email"`
+		}
+		require.NoError(t, json.Unmarshal([]byte(stdout), &createResult))
+		originalEmail := createResult.Email
+
+		t.Cleanup(func() {
+			runVSBWithConfig(t, configDir, "inbox", "delete", originalEmail)
+		})
+
+		exportPath := filepath.Join(t.TempDir(), "update-test.json")
+		_, _, code = runVSBWithConfig(t, configDir, "export", "--out", exportPath)
+		require.Equal(t, 0, code)
+
+		// The export is newer than the inbox, so the first update applies
+		stdout, stderr, code := runVSBWithConfig(t, configDir, "import", "--update", exportPath, "--output", "json")
+		require.Equal(t, 0, code, "import --update failed: stderr=%s", stderr)
+		var result struct {
+			Email   string `json:"email"`
+			Updated bool   `json:"updated"`
+		}
+		require.NoError(t, json.Unmarshal([]byte(stdout), &result), "stdout=%s", stdout)
+		assert.Equal(t, originalEmail, result.Email)
+		assert.True(t, result.Updated)
+
+		// Re-importing the same export is a no-op
+		stdout, stderr, code = runVSBWithConfig(t, configDir, "import", "--update", exportPath, "--output", "json")
+		require.Equal(t, 0, code, "second import --update failed: stderr=%s", stderr)
+		require.NoError(t, json.Unmarshal([]byte(stdout), &result), "stdout=%s", stdout)
+		assert.False(t, result.Updated)
+
+		stdout, _, code = runVSBWithConfig(t, configDir, "inbox", "list", "--output", "json")
+		require.Equal(t, 0, code)
+		assert.Contains(t, stdout, "upd-", "label is kept")
 	})
 
 	t.Run("reject expired inbox", func(t *testing.T) {
@@ -528,3 +619,142 @@ func TestImportPartialMatch(t *testing.T) {
 	require.NoError(t, json.Unmarshal(data, &exported))
 	assert.Equal(t, originalEmail, exported.EmailAddress)
 }
+
+// TestImportFromEnv tests adding an inbox from VSB_INBOX_* variables.
+func TestImportFromEnv(t *testing.T) {
+	sourceDir := t.TempDir()
+	stdout, _, code := runVSBWithConfig(t, sourceDir, "inbox", "create", "--output", "json")
+	require.Equal(t, 0, code)
+
+	var createResult struct {
+		Email string `json:"email"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(stdout), &createResult))
+	t.Cleanup(func() {
+		runVSBWithConfig(t, sourceDir, "inbox", "delete", createResult.Email)
+	})
+
+	exportPath := filepath.Join(t.TempDir(), "env-source.json")
+	_, _, code = runVSBWithConfig(t, sourceDir, "export", "--out", exportPath)
+	require.Equal(t, 0, code)
+
+	data, err := os.ReadFile(exportPath)
+	require.NoError(t, err)
+	var exported struct {
+		EmailAddress string `json:"emailAddress"`
+		InboxHash    string `json:"inboxHash"`
+		ExpiresAt    string `json:"expiresAt"`
+		SecretKey    string `json:"secretKey"`
+		ServerSigPk  string `json:"serverSigPk"`
+	}
+	require.NoError(t, json.Unmarshal(data, &exported))
+
+	t.Run("missing variables", func(t *testing.T) {
+		t.Setenv("VSB_INBOX_EMAIL", exported.EmailAddress)
+		t.Setenv("VSB_INBOX_HASH", "")
+		t.Setenv("VSB_INBOX_KEM_PRIVATE", "")
+		t.Setenv("VSB_INBOX_SERVER_SIG_PK", "")
+		t.Setenv("VSB_INBOX_EXPIRES_AT", "")
+
+		_, stderr, code := runVSBWithConfig(t, t.TempDir(), "inbox", "import-from-env")
+		assert.NotEqual(t, 0, code)
+		assert.Contains(t, stderr, "VSB_INBOX_HASH, VSB_INBOX_KEM_PRIVATE, VSB_INBOX_SERVER_SIG_PK, VSB_INBOX_EXPIRES_AT")
+	})
+
+	t.Run("imports into empty keystore", func(t *testing.T) {
+		t.Setenv("VSB_INBOX_EMAIL", exported.EmailAddress)
+		t.Setenv("VSB_INBOX_HASH", exported.InboxHash)
+		t.Setenv("VSB_INBOX_KEM_PRIVATE", exported.SecretKey)
+		t.Setenv("VSB_INBOX_SERVER_SIG_PK", exported.ServerSigPk)
+		t.Setenv("VSB_INBOX_EXPIRES_AT", exported.ExpiresAt)
+
+		configDir := t.TempDir()
+		_, stderr, code := runVSBWithConfig(t, configDir, "inbox", "import-from-env")
+		require.Equal(t, 0, code, "import-from-env failed: stderr=%s", stderr)
+
+		listResult := runVSBJSONWithConfig[[]struct {
+			Email    string `json:"email"`
+			IsActive bool   `json:"isActive"`
+		}](t, configDir, "inbox", "list")
+		require.Len(t, listResult, 1)
+		assert.Equal(t, exported.EmailAddress, listResult[0].Email)
+		assert.True(t, listResult[0].IsActive)
+
+		// The imported keys must be usable against the server
+		_, stderr, code = runVSBWithConfig(t, configDir, "email", "list")
+		assert.Equal(t, 0, code, "email list failed: stderr=%s", stderr)
+	})
+}
+
+// TestExportToEnv tests that export-to-env output can be evaluated by each
+// target shell and fed back into import-from-env.
+func TestExportToEnv(t *testing.T) {
+	sourceDir := t.TempDir()
+	stdout, _, code := runVSBWithConfig(t, sourceDir, "inbox", "create", "--output", "json")
+	require.Equal(t, 0, code)
+
+	var createResult struct {
+		Email string `json:"email"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(stdout), &createResult))
+	t.Cleanup(func() {
+		runVSBWithConfig(t, sourceDir, "inbox", "delete", createResult.Email)
+	})
+
+	t.Run("masks private key by default", func(t *testing.T) {
+		stdout, stderr, code := runVSBWithConfig(t, sourceDir, "inbox", "export-to-env")
+		require.Equal(t, 0, code, "stderr=%s", stderr)
+		assert.Contains(t, stdout, "export VSB_INBOX_EMAIL='"+createResult.Email+"'")
+		assert.Contains(t, stdout, "export VSB_INBOX_KEM_PRIVATE='********'")
+		assert.Contains(t, stderr, "--show-secrets")
+	})
+
+	// Each shell evaluates the statements from stdin and prints two variables
+	shells := []struct {
+		shell string
+		bin   string
+		args  []string
+	}{
+		{"bash", "bash", []string{"-c", `eval "$(cat)"; printf '%s\n%s\n' "$VSB_INBOX_EMAIL" "$VSB_INBOX_KEM_PRIVATE"`}},
+		{"fish", "fish", []string{"-c", `source; printf '%s\n%s\n' $VSB_INBOX_EMAIL $VSB_INBOX_KEM_PRIVATE`}},
+		{"powershell", "pwsh", []string{"-NoProfile", "-Command", `$input | Out-String | Invoke-Expression; $env:VSB_INBOX_EMAIL; $env:VSB_INBOX_KEM_PRIVATE`}},
+	}
+
+	for _, sh := range shells {
+		t.Run(sh.shell, func(t *testing.T) {
+			if _, err := exec.LookPath(sh.bin); err != nil {
+				t.Skipf("%s not installed", sh.bin)
+			}
+			script, stderr, code := runVSBWithConfig(t, sourceDir, "inbox", "export-to-env", "--shell", sh.shell, "--show-secrets")
+			require.Equal(t, 0, code, "stderr=%s", stderr)
+
+			cmd := exec.Command(sh.bin, sh.args...)
+			cmd.Stdin = strings.NewReader(script)
+			out, err := cmd.Output()
+			require.NoError(t, err, "%s could not evaluate:\n%s", sh.bin, script)
+
+			lines := strings.Split(strings.TrimSpace(string(out)), "\n")
+			require.Len(t, lines, 2)
+			assert.Equal(t, createResult.Email, strings.TrimSpace(lines[0]))
+			assert.NotEqual(t, "********", strings.TrimSpace(lines[1]))
+		})
+	}
+
+	t.Run("dotenv round trip", func(t *testing.T) {
+		stdout, stderr, code := runVSBWithConfig(t, sourceDir, "inbox", "export-to-env", "--no-export", "--show-secrets")
+		require.Equal(t, 0, code, "stderr=%s", stderr)
+
+		for _, line := range strings.Split(strings.TrimSpace(stdout), "\n") {
+			name, value, ok := strings.Cut(line, "=")
+			require.True(t, ok, line)
+			t.Setenv(name, value)
+		}
+
+		configDir := t.TempDir()
+		_, stderr, code = runVSBWithConfig(t, configDir, "inbox", "import-from-env")
+		require.Equal(t, 0, code, "import-from-env failed: stderr=%s", stderr)
+
+		_, stderr, code = runVSBWithConfig(t, configDir, "email", "list")
+		assert.Equal(t, 0, code, "email list failed: stderr=%s", stderr)
+	})
+}
@@ -4,6 +4,10 @@ package e2e
 
 import (
 	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
@@ -91,6 +95,82 @@ func TestInboxCreate(t *testing.T) {
 			runVSBWithConfig(t, configDir, "inbox", "delete", result.Email)
 		})
 	})
+
+	t.Run("wait ready", func(t *testing.T) {
+		configDir := t.TempDir()
+
+		stdout, stderr, code := runVSBWithConfig(t, configDir, "inbox", "create", "--wait-ready=30s", "--output", "json")
+		require.Equal(t, 0, code, "create failed: stdout=%s, stderr=%s", stdout, stderr)
+
+		var result struct {
+			Email        string `json:"email"`
+			ReadyAfterMs *int64 `json:"readyAfterMs"`
+		}
+		require.NoError(t, json.Unmarshal([]byte(stdout), &result))
+		require.NotNil(t, result.ReadyAfterMs, "readyAfterMs should be reported")
+		assert.GreaterOrEqual(t, *result.ReadyAfterMs, int64(0))
+
+		t.Cleanup(func() {
+			runVSBWithConfig(t, configDir, "inbox", "delete", result.Email)
+		})
+	})
+
+	t.Run("dry run", func(t *testing.T) {
+		configDir := t.TempDir()
+
+		stdout, stderr, code := runVSBWithConfig(t, configDir, "inbox", "create", "--dry-run", "--ttl", "2h", "--prefix", "lint", "--output", "json")
+		require.Equal(t, 0, code, "dry run failed: stdout=%s, stderr=%s", stdout, stderr)
+
+		var result struct {
+			DryRun  bool   `json:"dryRun"`
+			Address string `json:"address"`
+			TTL     string `json:"ttl"`
+		}
+		require.NoError(t, json.Unmarshal([]byte(stdout), &result))
+		assert.True(t, result.DryRun)
+		assert.True(t, strings.HasPrefix(result.Address, "lint-"), "got %s", result.Address)
+		assert.Equal(t, "2h0m0s", result.TTL)
+
+		_, err := os.Stat(filepath.Join(configDir, "keystore.json"))
+		assert.True(t, os.IsNotExist(err), "dry run must not write the keystore")
+
+		_, stderr, code = runVSBWithConfig(t, configDir, "inbox", "create", "--dry-run", "--ttl", "soon")
+		assert.NotEqual(t, 0, code)
+		assert.Contains(t, stderr, "invalid TTL format")
+	})
+}
+
+// TestInboxDomains tests listing domains and creating inboxes on a domain.
+func TestInboxDomains(t *testing.T) {
+	configDir := t.TempDir()
+
+	stdout, stderr, code := runVSBWithConfig(t, configDir, "inbox", "list-domains", "--output", "json")
+	require.Equal(t, 0, code, "list-domains failed: stderr=%s", stderr)
+
+	var domains []string
+	require.NoError(t, json.Unmarshal([]byte(stdout), &domains))
+	require.NotEmpty(t, domains)
+
+	t.Run("create on listed domain", func(t *testing.T) {
+		stdout, stderr, code := runVSBWithConfig(t, configDir, "inbox", "create", "--domain", domains[0], "--output", "json")
+		require.Equal(t, 0, code, "create failed: stderr=%s", stderr)
+
+		var result struct {
+			Email string `json:"email"`
+		}
+		require.NoError(t, json.Unmarshal([]byte(stdout), &result))
+		t.Cleanup(func() {
+			runVSBWithConfig(t, configDir, "inbox", "delete", result.Email)
+		})
+		assert.True(t, strings.HasSuffix(result.Email, "@"+domains[0]), "got %s", result.Email)
+	})
+
+	t.Run("unavailable domain lists available", func(t *testing.T) {
+		_, stderr, code := runVSBWithConfig(t, configDir, "inbox", "create", "--domain", "not-a-real-domain.invalid")
+		assert.NotEqual(t, 0, code)
+		assert.Contains(t, stderr, "not available")
+		assert.Contains(t, stderr, domains[0])
+	})
 }
 
 // TestInboxList tests listing inboxes.
@@ -165,6 +245,50 @@ func TestInboxList(t *testing.T) {
 			}
 		}
 		assert.Equal(t, 1, activeCount, "exactly one inbox should be active")
+
+		// email-only output is one address per line, piped to wc -l
+		pipe := exec.Command("sh", "-c", `"$VSB" inbox list --format email-only | wc -l`)
+		pipe.Env = append(os.Environ(),
+			"VSB="+vsbBinPath,
+			"VSB_CONFIG_DIR="+configDir,
+			"VSB_API_KEY="+apiKey,
+			"VSB_BASE_URL="+baseURL,
+		)
+		out, err := pipe.Output()
+		require.NoError(t, err)
+		assert.Equal(t, strconv.Itoa(len(result)), strings.TrimSpace(string(out)))
+
+		stdout, _, code = runVSBWithConfig(t, configDir, "inbox", "list", "--format", "email-only")
+		require.Equal(t, 0, code)
+		assert.True(t, strings.HasSuffix(stdout, "\n") && !strings.HasSuffix(stdout, "\n\n"))
+		assert.NotContains(t, stdout, " ")
+
+		// The last created inbox is active
+		stdout, _, code = runVSBWithConfig(t, configDir, "inbox", "list", "--active-only")
+		require.Equal(t, 0, code)
+		assert.Equal(t, emails[len(emails)-1]+"\n", stdout)
+
+		// Details report the (empty) email count of each inbox
+		stdout, stderr, code = runVSBWithConfig(t, configDir, "inbox", "list", "--details", "--sort", "last-activity", "--output", "json")
+		require.Equal(t, 0, code, "list --details failed: stdout=%s, stderr=%s", stdout, stderr)
+
+		var detailed []struct {
+			Email       string  `json:"email"`
+			EmailCount  *int    `json:"emailCount"`
+			LastEmailAt *string `json:"lastEmailAt"`
+		}
+		require.NoError(t, json.Unmarshal([]byte(stdout), &detailed))
+		require.Len(t, detailed, len(result))
+		for _, inbox := range detailed {
+			require.NotNil(t, inbox.EmailCount, "emailCount for %s", inbox.Email)
+			assert.Equal(t, 0, *inbox.EmailCount)
+			assert.Nil(t, inbox.LastEmailAt)
+		}
+
+		stdout, _, code = runVSBWithConfig(t, configDir, "inbox", "list", "--details")
+		require.Equal(t, 0, code)
+		assert.Contains(t, stdout, "COUNT")
+		assert.Contains(t, stdout, "LAST EMAIL")
 	})
 }
 
@@ -234,6 +358,91 @@ func TestInboxInfo(t *testing.T) {
 	})
 }
 
+// TestInboxInfoServer tests reconciling the keystore with the server.
+func TestInboxInfoServer(t *testing.T) {
+	configDir := t.TempDir()
+
+	stdout, _, code := runVSBWithConfig(t, configDir, "inbox", "create", "--output", "json")
+	require.Equal(t, 0, code)
+	var createResult struct {
+		Email string `json:"email"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(stdout), &createResult))
+	email := createResult.Email
+
+	t.Cleanup(func() {
+		runVSBWithConfig(t, configDir, "inbox", "delete", email)
+	})
+
+	type reconciliation struct {
+		Local struct {
+			Exists bool `json:"exists"`
+		} `json:"local"`
+		Server struct {
+			Exists     bool `json:"exists"`
+			EmailCount *int `json:"emailCount"`
+		} `json:"server"`
+		Drift struct {
+			InSync     bool     `json:"inSync"`
+			Mismatches []string `json:"mismatches"`
+			Hints      []string `json:"hints"`
+		} `json:"drift"`
+	}
+
+	t.Run("in sync", func(t *testing.T) {
+		stdout, stderr, code := runVSBWithConfig(t, configDir, "inbox", "info", "--server", "--output", "json")
+		require.Equal(t, 0, code, "stderr=%s", stderr)
+
+		var result reconciliation
+		require.NoError(t, json.Unmarshal([]byte(stdout), &result))
+		assert.True(t, result.Local.Exists)
+		assert.True(t, result.Server.Exists)
+		require.NotNil(t, result.Server.EmailCount)
+		assert.True(t, result.Drift.InSync)
+	})
+
+	t.Run("deleted on the server", func(t *testing.T) {
+		// Keep a local copy, delete the inbox, then restore only the copy
+		exportPath := filepath.Join(t.TempDir(), "gone.json")
+		_, _, code := runVSBWithConfig(t, configDir, "export", "--out", exportPath)
+		require.Equal(t, 0, code)
+		_, _, code = runVSBWithConfig(t, configDir, "inbox", "delete", email)
+		require.Equal(t, 0, code)
+		_, stderr, code := runVSBWithConfig(t, configDir, "import", "--local", exportPath)
+		require.Equal(t, 0, code, "stderr=%s", stderr)
+
+		stdout, stderr, code := runVSBWithConfig(t, configDir, "inbox", "info", "--server", "--output", "json")
+		require.Equal(t, 0, code, "stderr=%s", stderr)
+
+		var result reconciliation
+		require.NoError(t, json.Unmarshal([]byte(stdout), &result))
+		assert.False(t, result.Server.Exists)
+		assert.False(t, result.Drift.InSync)
+		assert.Equal(t, []string{"exists"}, result.Drift.Mismatches)
+		require.NotEmpty(t, result.Drift.Hints)
+		assert.Contains(t, result.Drift.Hints[0], "inbox delete --local")
+	})
+
+	t.Run("rejected API key", func(t *testing.T) {
+		cmd := exec.Command(vsbBinPath, "inbox", "info", "--server", "--output", "json")
+		cmd.Env = append(os.Environ(),
+			"VSB_API_KEY=vsb_invalid_key",
+			"VSB_BASE_URL="+baseURL,
+			"VSB_CONFIG_DIR="+configDir,
+			"NO_COLOR=1",
+		)
+		var stderr strings.Builder
+		cmd.Stderr = &stderr
+		out, err := cmd.Output()
+		require.Error(t, err)
+		assert.Contains(t, stderr.String(), "server rejected the API key")
+
+		var result jsonError
+		require.NoError(t, json.Unmarshal(out, &result), "stdout should be JSON, got: %s", out)
+		assert.Equal(t, "unauthorized", result.Error.Code)
+	})
+}
+
 // TestInboxUse tests switching the active inbox.
 func TestInboxUse(t *testing.T) {
 	configDir := t.TempDir()
@@ -301,6 +510,106 @@ func TestInboxUse(t *testing.T) {
 	})
 }
 
+// TestInboxTags tests tagging inboxes, filtering the list by tag and
+// carrying tags through export and import. It works on a local keystore,
+// so no server is needed.
+func TestInboxTags(t *testing.T) {
+	configDir := t.TempDir()
+	expires := time.Now().Add(24 * time.Hour).UTC().Format(time.RFC3339)
+	keystore := `{
+  "version": 2,
+  "inboxes": [
+    {"email": "signup@example.com", "id": "h1", "label": "signup", "expiresAt": "` + expires + `", "keys": {"kem_private": "priv1", "kem_public": "pub1", "server_sig_pk": "sig"}},
+    {"email": "invoices@example.com", "id": "h2", "label": "invoices", "expiresAt": "` + expires + `", "keys": {"kem_private": "priv2", "kem_public": "pub2", "server_sig_pk": "sig"}}
+  ],
+  "active_inbox": "signup@example.com"
+}`
+	require.NoError(t, os.WriteFile(filepath.Join(configDir, "keystore.json"), []byte(keystore), 0600))
+
+	type listEntry struct {
+		Email string   `json:"email"`
+		Tags  []string `json:"tags"`
+	}
+	list := func(t *testing.T, dir string, args ...string) []listEntry {
+		t.Helper()
+		stdout, stderr, code := runVSBWithConfig(t, dir, append([]string{"inbox", "list", "-o", "json"}, args...)...)
+		require.Equal(t, 0, code, "stderr: %s", stderr)
+		var entries []listEntry
+		require.NoError(t, json.Unmarshal([]byte(stdout), &entries))
+		return entries
+	}
+
+	t.Run("tag", func(t *testing.T) {
+		stdout, stderr, code := runVSBWithConfig(t, configDir, "inbox", "tag", "signup", "auth", "billing")
+		require.Equal(t, 0, code, "stderr: %s", stderr)
+		assert.Contains(t, stdout, "auth, billing")
+
+		_, _, code = runVSBWithConfig(t, configDir, "inbox", "tag", "invoices", "billing")
+		require.Equal(t, 0, code)
+	})
+
+	t.Run("invalid tag", func(t *testing.T) {
+		_, stderr, code := runVSBWithConfig(t, configDir, "inbox", "tag", "signup", "has space")
+		assert.NotEqual(t, 0, code)
+		assert.Contains(t, stderr, "invalid tag")
+	})
+
+	t.Run("list filters by tag", func(t *testing.T) {
+		entries := list(t, configDir, "--tag", "auth")
+		require.Len(t, entries, 1)
+		assert.Equal(t, "signup@example.com", entries[0].Email)
+		assert.Equal(t, []string{"auth", "billing"}, entries[0].Tags)
+
+		assert.Len(t, list(t, configDir, "--tag", "billing"), 2)
+		assert.Len(t, list(t, configDir, "--tag", "billing", "--tag", "auth"), 1)
+		assert.Empty(t, list(t, configDir, "--tag", "none"))
+	})
+
+	t.Run("info shows tags", func(t *testing.T) {
+		stdout, _, code := runVSBWithConfig(t, configDir, "inbox", "info", "signup", "-o", "json")
+		require.Equal(t, 0, code)
+		var info listEntry
+		require.NoError(t, json.Unmarshal([]byte(stdout), &info))
+		assert.Equal(t, []string{"auth", "billing"}, info.Tags)
+	})
+
+	t.Run("untag", func(t *testing.T) {
+		stdout, stderr, code := runVSBWithConfig(t, configDir, "inbox", "untag", "invoices", "billing", "-o", "json")
+		require.Equal(t, 0, code, "stderr: %s", stderr)
+		var result listEntry
+		require.NoError(t, json.Unmarshal([]byte(stdout), &result))
+		assert.Empty(t, result.Tags)
+
+		entries := list(t, configDir, "--tag", "billing")
+		require.Len(t, entries, 1)
+		assert.Equal(t, "signup@example.com", entries[0].Email)
+	})
+
+	t.Run("tags survive export and import", func(t *testing.T) {
+		exportPath := filepath.Join(t.TempDir(), "tagged.json")
+		_, stderr, code := runVSBWithConfig(t, configDir, "export", "signup", "--out", exportPath)
+		require.Equal(t, 0, code, "stderr: %s", stderr)
+
+		data, err := os.ReadFile(exportPath)
+		require.NoError(t, err)
+		var exported struct {
+			Version int      `json:"version"`
+			Tags    []string `json:"tags"`
+		}
+		require.NoError(t, json.Unmarshal(data, &exported))
+		assert.Equal(t, 2, exported.Version)
+		assert.Equal(t, []string{"auth", "billing"}, exported.Tags)
+
+		newConfigDir := t.TempDir()
+		_, stderr, code = runVSBWithConfig(t, newConfigDir, "import", exportPath, "--local")
+		require.Equal(t, 0, code, "stderr: %s", stderr)
+
+		entries := list(t, newConfigDir, "--tag", "auth")
+		require.Len(t, entries, 1)
+		assert.Equal(t, []string{"auth", "billing"}, entries[0].Tags)
+	})
+}
+
 // TestInboxDelete tests deleting inboxes.
 func TestInboxDelete(t *testing.T) {
 	t.Run("delete from server and local", func(t *testing.T) {
@@ -381,6 +690,25 @@ func TestInboxDelete(t *testing.T) {
 		// up local state when the server inbox was already deleted.
 	})
 
+	t.Run("delete all with summary", func(t *testing.T) {
+		configDir := t.TempDir()
+
+		for i := 0; i < 3; i++ {
+			_, _, code := runVSBWithConfig(t, configDir, "inbox", "create", "--output", "json")
+			require.Equal(t, 0, code)
+		}
+
+		stdout, stderr, code := runVSBWithConfig(t, configDir, "inbox", "delete", "--all", "--yes")
+		require.Equal(t, 0, code, "delete --all failed: stdout=%s, stderr=%s", stdout, stderr)
+		assert.Contains(t, stdout, "Deleted 3 of 3 inboxes, 0 failed")
+
+		stdout, _, code = runVSBWithConfig(t, configDir, "inbox", "list", "--output", "json")
+		require.Equal(t, 0, code)
+		var listResult []interface{}
+		_ = json.Unmarshal([]byte(stdout), &listResult)
+		assert.Empty(t, listResult)
+	})
+
 	t.Run("delete with partial match", func(t *testing.T) {
 		configDir := t.TempDir()
 
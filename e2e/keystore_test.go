@@ -0,0 +1,327 @@
+//go:build e2e
+
+package e2e
+
+import (
+	"crypto/rand"
+	"crypto/sha3"
+	"encoding/base64"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestKeystoreMigrate tests upgrading a version 1 keystore file.
+func TestKeystoreMigrate(t *testing.T) {
+	configDir := t.TempDir()
+	path := filepath.Join(configDir, "keystore.json")
+	expires := time.Now().Add(24 * time.Hour).UTC().Format(time.RFC3339)
+	v1 := `{
+  "version": 1,
+  "inboxes": [
+    {"email": "first@example.com", "id": "h1", "label": "ci", "expiresAt": "` + expires + `", "keys": {"kem_private": "priv1", "kem_public": "pub1", "server_sig_pk": "sig"}, "encrypted": true, "emailAuth": true},
+    {"email": "second@example.com", "id": "h2", "label": "CI", "expiresAt": "` + expires + `", "keys": {"kem_private": "priv2", "kem_public": "pub2", "server_sig_pk": "sig"}, "encrypted": true, "emailAuth": false}
+  ],
+  "active_inbox": "deleted@example.com"
+}`
+	require.NoError(t, os.WriteFile(path, []byte(v1), 0600))
+
+	t.Run("dry run", func(t *testing.T) {
+		stdout, stderr, code := runVSBWithConfig(t, configDir, "keystore", "migrate", "--dry-run")
+		require.Equal(t, 0, code, "stderr: %s", stderr)
+		assert.Contains(t, stdout, "schema version 1 -> 2")
+		assert.Contains(t, stdout, `second@example.com: renamed label "CI" to "CI-2"`)
+
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, v1, string(data))
+	})
+
+	t.Run("migrate", func(t *testing.T) {
+		stdout, stderr, code := runVSBWithConfig(t, configDir, "keystore", "migrate", "-o", "json")
+		require.Equal(t, 0, code, "stderr: %s", stderr)
+
+		var result struct {
+			From    int      `json:"from"`
+			To      int      `json:"to"`
+			Changes []string `json:"changes"`
+			Written bool     `json:"written"`
+			Backup  string   `json:"backup"`
+		}
+		require.NoError(t, json.Unmarshal([]byte(stdout), &result))
+		assert.Equal(t, 1, result.From)
+		assert.Equal(t, 2, result.To)
+		assert.True(t, result.Written)
+
+		backup, err := os.ReadFile(result.Backup)
+		require.NoError(t, err)
+		assert.Equal(t, v1, string(backup))
+
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		var ks struct {
+			Version int `json:"version"`
+			Inboxes []struct {
+				Email     string `json:"email"`
+				Label     string `json:"label"`
+				EmailAuth bool   `json:"emailAuth"`
+				Keys      struct {
+					KEMPrivate string `json:"kem_private"`
+				} `json:"keys"`
+			} `json:"inboxes"`
+			ActiveInbox string `json:"active_inbox"`
+		}
+		require.NoError(t, json.Unmarshal(data, &ks))
+		assert.Equal(t, 2, ks.Version)
+		assert.Equal(t, "first@example.com", ks.ActiveInbox)
+		require.Len(t, ks.Inboxes, 2)
+		assert.Equal(t, "ci", ks.Inboxes[0].Label)
+		assert.Equal(t, "CI-2", ks.Inboxes[1].Label)
+		assert.Equal(t, "priv2", ks.Inboxes[1].Keys.KEMPrivate)
+		assert.False(t, ks.Inboxes[1].EmailAuth)
+	})
+
+	t.Run("migrated keystore is usable", func(t *testing.T) {
+		stdout, stderr, code := runVSBWithConfig(t, configDir, "inbox", "list", "-o", "json")
+		require.Equal(t, 0, code, "stderr: %s", stderr)
+		assert.Contains(t, stdout, "first@example.com")
+		assert.Contains(t, stdout, "second@example.com")
+
+		stdout, _, code = runVSBWithConfig(t, configDir, "keystore", "migrate")
+		assert.Equal(t, 0, code)
+		assert.Contains(t, stdout, "up to date (schema version 2)")
+	})
+}
+
+// TestKeystoreBackupRestore tests backing up a keystore and restoring it
+// into another config directory.
+func TestKeystoreBackupRestore(t *testing.T) {
+	configDir := t.TempDir()
+	expires := time.Now().Add(24 * time.Hour).UTC().Format(time.RFC3339)
+	keystore := `{
+  "version": 2,
+  "inboxes": [
+    {"email": "backup@example.com", "id": "h1", "label": "ci", "expiresAt": "` + expires + `", "keys": {"kem_private": "priv1", "kem_public": "pub1", "server_sig_pk": "sig"}, "encrypted": true}
+  ],
+  "active_inbox": "backup@example.com"
+}`
+	require.NoError(t, os.WriteFile(filepath.Join(configDir, "keystore.json"), []byte(keystore), 0600))
+
+	type backupResult struct {
+		Path       string `json:"path"`
+		Inboxes    int    `json:"inboxes"`
+		Encrypted  bool   `json:"encrypted"`
+		VSBVersion string `json:"vsbVersion"`
+	}
+
+	for _, encrypted := range []bool{false, true} {
+		name := "plain"
+		args := []string{"keystore", "backup", "-o", "json", "--out", filepath.Join(t.TempDir(), "backup.json")}
+		if encrypted {
+			name = "encrypted"
+			args = append(args, "--encrypt-with-password")
+			t.Setenv("VSB_BACKUP_PASSWORD", "e2e-password")
+		}
+
+		t.Run(name, func(t *testing.T) {
+			stdout, stderr, code := runVSBWithConfig(t, configDir, args...)
+			require.Equal(t, 0, code, "stderr: %s", stderr)
+
+			var backup backupResult
+			require.NoError(t, json.Unmarshal([]byte(stdout), &backup))
+			assert.Equal(t, 1, backup.Inboxes)
+			assert.Equal(t, encrypted, backup.Encrypted)
+			assert.NotEmpty(t, backup.VSBVersion)
+
+			data, err := os.ReadFile(backup.Path)
+			require.NoError(t, err)
+			assert.Contains(t, string(data), `"createdAt"`)
+			assert.Contains(t, string(data), `"checksum": "sha256:`)
+			assert.Equal(t, !encrypted, strings.Contains(string(data), "priv1"))
+
+			// Restore into a fresh config directory
+			restoreDir := t.TempDir()
+			_, stderr, code = runVSBWithConfig(t, restoreDir, "keystore", "restore", backup.Path)
+			require.Equal(t, 0, code, "stderr: %s", stderr)
+
+			stdout, stderr, code = runVSBWithConfig(t, restoreDir, "inbox", "list", "-o", "json")
+			require.Equal(t, 0, code, "stderr: %s", stderr)
+			assert.Contains(t, stdout, "backup@example.com")
+
+			// A second restore needs --force
+			_, stderr, code = runVSBWithConfig(t, restoreDir, "keystore", "restore", backup.Path)
+			assert.NotEqual(t, 0, code)
+			assert.Contains(t, stderr, "keystore already exists")
+
+			_, stderr, code = runVSBWithConfig(t, restoreDir, "keystore", "restore", backup.Path, "--force")
+			assert.Equal(t, 0, code, "stderr: %s", stderr)
+		})
+	}
+
+	t.Run("modified backup is rejected", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "backup.json")
+		_, stderr, code := runVSBWithConfig(t, configDir, "keystore", "backup", "--out", path)
+		require.Equal(t, 0, code, "stderr: %s", stderr)
+
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		modified := strings.Replace(string(data), "backup@example.com", "other@example.com", 1)
+		require.NoError(t, os.WriteFile(path, []byte(modified), 0600))
+
+		_, stderr, code = runVSBWithConfig(t, t.TempDir(), "keystore", "restore", path)
+		assert.NotEqual(t, 0, code)
+		assert.Contains(t, stderr, "keystore backup corrupted or modified")
+	})
+}
+
+// TestKeystoreVerify tests verifying a keystore with one valid and one
+// corrupted inbox, and repairing it.
+func TestKeystoreVerify(t *testing.T) {
+	configDir := t.TempDir()
+
+	// A well-formed ML-KEM-768 private key: random apart from the SHA3-256
+	// hash of the public key embedded in it
+	priv := make([]byte, 2400)
+	_, err := rand.Read(priv)
+	require.NoError(t, err)
+	sum := sha3.Sum256(priv[1152:2336])
+	copy(priv[2336:], sum[:])
+	corrupted := append([]byte(nil), priv...)
+	corrupted[1500] ^= 1
+	sig := base64.RawURLEncoding.EncodeToString(make([]byte, 1952))
+
+	expires := time.Now().Add(24 * time.Hour).UTC().Format(time.RFC3339)
+	keystore := `{
+  "version": 2,
+  "inboxes": [
+    {"email": "valid@example.com", "id": "h1", "expiresAt": "` + expires + `", "encrypted": true, "keys": {"kem_private": "` + base64.RawURLEncoding.EncodeToString(priv) + `", "server_sig_pk": "` + sig + `"}},
+    {"email": "corrupted@example.com", "id": "h2", "expiresAt": "` + expires + `", "encrypted": true, "keys": {"kem_private": "` + base64.RawURLEncoding.EncodeToString(corrupted) + `", "server_sig_pk": "` + sig + `"}}
+  ],
+  "active_inbox": "corrupted@example.com"
+}`
+	require.NoError(t, os.WriteFile(filepath.Join(configDir, "keystore.json"), []byte(keystore), 0600))
+
+	t.Run("reports the corrupted inbox", func(t *testing.T) {
+		stdout, stderr, code := runVSBWithConfig(t, configDir, "keystore", "verify", "--verbose")
+		assert.Equal(t, 1, code)
+		assert.Contains(t, stderr, "1 of 2 failed verification")
+		assert.Contains(t, stdout, "valid@example.com")
+		assert.Contains(t, stdout, "hash mismatch")
+		assert.Contains(t, stdout, "SHA256:")
+	})
+
+	t.Run("json", func(t *testing.T) {
+		stdout, _, code := runVSBWithConfig(t, configDir, "keystore", "verify", "-o", "json", "--json-compact")
+		assert.Equal(t, 1, code)
+
+		// The result is followed by the JSON error object
+		lines := strings.Split(strings.TrimSpace(stdout), "\n")
+		require.Len(t, lines, 2)
+		var result struct {
+			Inboxes []struct {
+				Email  string `json:"email"`
+				Status string `json:"status"`
+			} `json:"inboxes"`
+		}
+		require.NoError(t, json.Unmarshal([]byte(lines[0]), &result))
+		require.Len(t, result.Inboxes, 2)
+		assert.Equal(t, "ok", result.Inboxes[0].Status)
+		assert.Equal(t, "invalid", result.Inboxes[1].Status)
+
+		var errResult jsonError
+		require.NoError(t, json.Unmarshal([]byte(lines[1]), &errResult))
+		assert.Equal(t, "keystore_invalid", errResult.Error.Code)
+	})
+
+	t.Run("repair", func(t *testing.T) {
+		stdout, stderr, code := runVSBWithConfig(t, configDir, "keystore", "verify", "--repair")
+		require.Equal(t, 0, code, "stderr: %s", stderr)
+		assert.Contains(t, stdout, "removed")
+
+		stdout, stderr, code = runVSBWithConfig(t, configDir, "inbox", "list", "--format", "email-only")
+		require.Equal(t, 0, code, "stderr: %s", stderr)
+		assert.Equal(t, "valid@example.com", strings.TrimSpace(stdout))
+
+		_, _, code = runVSBWithConfig(t, configDir, "keystore", "verify")
+		assert.Equal(t, 0, code)
+	})
+}
+
+// TestKeystorePrune tests removing an inbox whose expiry has passed in the
+// keystore, although the server still has it.
+func TestKeystorePrune(t *testing.T) {
+	configDir := t.TempDir()
+	create := func() string {
+		stdout, stderr, code := runVSBWithConfig(t, configDir, "inbox", "create", "--ttl", "1h", "--output", "json")
+		require.Equal(t, 0, code, "inbox create failed: stderr=%s", stderr)
+		var result struct {
+			Email string `json:"email"`
+		}
+		require.NoError(t, json.Unmarshal([]byte(stdout), &result))
+		return result.Email
+	}
+	kept := create()
+	t.Cleanup(func() { runVSBWithConfig(t, configDir, "inbox", "delete", kept) })
+	expired := create() // active
+
+	// Expire the active inbox two hours ago, keeping every other field
+	path := filepath.Join(configDir, "keystore.json")
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	var ks map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &ks))
+	require.Equal(t, expired, ks["active_inbox"])
+	for _, inbox := range ks["inboxes"].([]interface{}) {
+		if inbox := inbox.(map[string]interface{}); inbox["email"] == expired {
+			inbox["expiresAt"] = time.Now().Add(-2 * time.Hour).UTC().Format(time.RFC3339)
+		}
+	}
+	data, err = json.Marshal(ks)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, data, 0600))
+
+	t.Run("dry run changes nothing", func(t *testing.T) {
+		type pruneOutput struct {
+			DryRun bool `json:"dryRun"`
+			Pruned []struct {
+				Email string `json:"email"`
+			} `json:"pruned"`
+			Remaining int `json:"remaining"`
+		}
+		result := runVSBJSONWithConfig[pruneOutput](t, configDir, "keystore", "prune", "--dry-run")
+		assert.True(t, result.DryRun)
+		require.Len(t, result.Pruned, 1)
+		assert.Equal(t, expired, result.Pruned[0].Email)
+		assert.Equal(t, 1, result.Remaining)
+
+		after, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, string(data), string(after))
+	})
+
+	t.Run("older-than keeps recently expired inboxes", func(t *testing.T) {
+		stdout, stderr, code := runVSBWithConfig(t, configDir, "keystore", "prune", "--older-than", "1d")
+		require.Equal(t, 0, code, "stderr=%s", stderr)
+		assert.Contains(t, stdout, "Pruned 0 expired inboxes. 1 active inbox remains.")
+		assert.Contains(t, stdout, "Kept 1 inbox that expired less than 1d ago.")
+	})
+
+	t.Run("removes the expired inbox", func(t *testing.T) {
+		stdout, stderr, code := runVSBWithConfig(t, configDir, "keystore", "prune")
+		require.Equal(t, 0, code, "stderr=%s", stderr)
+		assert.Contains(t, stdout, expired)
+		assert.Contains(t, stdout, "Pruned 1 expired inbox. 1 active inbox remains.")
+		assert.Contains(t, stdout, "Active inbox is now "+kept)
+
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.NotContains(t, string(data), expired)
+		assert.Contains(t, string(data), `"active_inbox": "`+kept+`"`)
+	})
+}
@@ -5,8 +5,13 @@ package e2e
 import (
 	"encoding/json"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httputil"
 	"net/smtp"
+	"net/url"
 	"os"
+	"path/filepath"
 	"strings"
 	"sync"
 	"testing"
@@ -280,6 +285,117 @@ func TestWaitFrom(t *testing.T) {
 	})
 }
 
+// TestWaitExclusion tests that --not-* filters skip emails and keep waiting.
+func TestWaitExclusion(t *testing.T) {
+	skipIfNoSMTP(t)
+	configDir := t.TempDir()
+
+	stdout, _, code := runVSBWithConfig(t, configDir, "inbox", "create", "--output", "json")
+	require.Equal(t, 0, code)
+
+	var createResult struct {
+		Email string `json:"email"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(stdout), &createResult))
+	inboxEmail := createResult.Email
+
+	t.Cleanup(func() {
+		runVSBWithConfig(t, configDir, "inbox", "delete", inboxEmail)
+	})
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		time.Sleep(500 * time.Millisecond)
+		<-sendTestEmailAsync(inboxEmail, "Weekly Newsletter", "Excluded")
+		time.Sleep(time.Second)
+		<-sendTestEmailAsync(inboxEmail, "Account Activated", "Expected")
+	}()
+
+	stdout, stderr, code := runVSBWithConfig(t, configDir, "email", "wait",
+		"--from", "test@example.com", "--not-subject-regex", "(?i)newsletter",
+		"--timeout", "30s", "--output", "json")
+	require.Equal(t, 0, code, "wait --not-subject-regex failed: stdout=%s, stderr=%s", stdout, stderr)
+
+	var result struct {
+		Subject string `json:"subject"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(stdout), &result))
+	assert.Equal(t, "Account Activated", result.Subject)
+
+	wg.Wait()
+}
+
+// TestWaitOnlyNew tests that --only-new and --min-received skip emails that
+// were already in the inbox.
+func TestWaitOnlyNew(t *testing.T) {
+	skipIfNoSMTP(t)
+	configDir := t.TempDir()
+
+	stdout, _, code := runVSBWithConfig(t, configDir, "inbox", "create", "--output", "json")
+	require.Equal(t, 0, code)
+
+	var createResult struct {
+		Email string `json:"email"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(stdout), &createResult))
+	inboxEmail := createResult.Email
+
+	t.Cleanup(func() {
+		runVSBWithConfig(t, configDir, "inbox", "delete", inboxEmail)
+	})
+
+	sendTestEmail(t, inboxEmail, "Welcome", "Left over from an earlier run")
+	time.Sleep(2 * time.Second)
+
+	t.Run("existing email matches by default", func(t *testing.T) {
+		stdout, stderr, code := runVSBWithConfig(t, configDir, "email", "wait",
+			"--subject", "Welcome", "--timeout", "10s", "--output", "json")
+		require.Equal(t, 0, code, "wait failed: stdout=%s, stderr=%s", stdout, stderr)
+	})
+
+	t.Run("only new ignores existing email", func(t *testing.T) {
+		stdout, stderr, code := runVSBWithConfig(t, configDir, "email", "wait",
+			"--subject", "Welcome", "--only-new", "--timeout", "3s")
+		assert.NotEqual(t, 0, code, "wait --only-new should time out: stdout=%s, stderr=%s", stdout, stderr)
+	})
+
+	t.Run("only new matches email sent during wait", func(t *testing.T) {
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			time.Sleep(time.Second)
+			<-sendTestEmailAsync(inboxEmail, "Welcome", "Fresh")
+		}()
+
+		stdout, stderr, code := runVSBWithConfig(t, configDir, "email", "wait",
+			"--subject", "Welcome", "--only-new", "--timeout", "30s", "--output", "json")
+		require.Equal(t, 0, code, "wait --only-new failed: stdout=%s, stderr=%s", stdout, stderr)
+
+		var result struct {
+			Text string `json:"text"`
+		}
+		require.NoError(t, json.Unmarshal([]byte(stdout), &result))
+		assert.Contains(t, result.Text, "Fresh")
+		wg.Wait()
+	})
+
+	t.Run("min received in the future ignores existing emails", func(t *testing.T) {
+		cutoff := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+		_, _, code := runVSBWithConfig(t, configDir, "email", "wait",
+			"--subject", "Welcome", "--min-received", cutoff, "--timeout", "3s")
+		assert.NotEqual(t, 0, code)
+	})
+
+	t.Run("relative min received includes recent emails", func(t *testing.T) {
+		stdout, stderr, code := runVSBWithConfig(t, configDir, "email", "wait",
+			"--subject", "Welcome", "--min-received", "1h", "--timeout", "10s")
+		require.Equal(t, 0, code, "wait --min-received failed: stdout=%s, stderr=%s", stdout, stderr)
+	})
+}
+
 // TestWaitTimeout tests timeout behavior.
 func TestWaitTimeout(t *testing.T) {
 	skipIfNoSMTP(t)
@@ -407,6 +523,53 @@ func TestWaitExtractLink(t *testing.T) {
 	})
 }
 
+// TestWaitExtractRegex tests extracting a named capture group from the body.
+func TestWaitExtractRegex(t *testing.T) {
+	skipIfNoSMTP(t)
+	configDir := t.TempDir()
+
+	stdout, _, code := runVSBWithConfig(t, configDir, "inbox", "create", "--output", "json")
+	require.Equal(t, 0, code)
+
+	var createResult struct {
+		Email string `json:"email"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(stdout), &createResult))
+	inboxEmail := createResult.Email
+
+	t.Cleanup(func() {
+		runVSBWithConfig(t, configDir, "inbox", "delete", inboxEmail)
+	})
+
+	t.Run("extracts OTP", func(t *testing.T) {
+		otp := time.Now().Format("150405")
+
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			time.Sleep(500 * time.Millisecond)
+			<-sendTestEmailAsync(inboxEmail, "Your code", "Hello,\nYour OTP is: "+otp+"\nThanks")
+		}()
+
+		stdout, stderr, code := runVSBWithConfig(t, configDir, "email", "wait",
+			"--body-regex", `Your OTP is: (?P<otp>\d{6})`, "--extract-regex", "otp", "--timeout", "30s")
+		require.Equal(t, 0, code, "wait --extract-regex failed: stdout=%s, stderr=%s", stdout, stderr)
+		assert.Equal(t, otp+"\n", stdout)
+
+		wg.Wait()
+	})
+
+	t.Run("unknown group fails before waiting", func(t *testing.T) {
+		start := time.Now()
+		_, stderr, code := runVSBWithConfig(t, configDir, "email", "wait",
+			"--body-regex", `Your OTP is: (\d{6})`, "--extract-regex", "otp", "--timeout", "30s")
+		assert.Equal(t, 1, code)
+		assert.Contains(t, stderr, `no capture group named "otp"`)
+		assert.Less(t, time.Since(start), 10*time.Second)
+	})
+}
+
 // TestWaitQuiet tests quiet mode output.
 func TestWaitQuiet(t *testing.T) {
 	skipIfNoSMTP(t)
@@ -519,6 +682,65 @@ func TestWaitWithInbox(t *testing.T) {
 
 		wg.Wait()
 	})
+
+	t.Run("wait on several inboxes", func(t *testing.T) {
+		timestamp := time.Now().Format("150405.000")
+		subject := "Multi Inbox Test " + timestamp
+
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			time.Sleep(500 * time.Millisecond)
+			<-sendTestEmailAsync(inboxEmails[1], subject, "Testing wait across inboxes")
+		}()
+
+		stdout, stderr, code := runVSBWithConfig(t, configDir, "email", "wait",
+			"--inbox", inboxEmails[0], "--inbox", inboxEmails[1],
+			"--subject", subject, "--timeout", "30s", "--output", "json")
+		require.Equal(t, 0, code, "wait failed: stdout=%s, stderr=%s", stdout, stderr)
+
+		var result struct {
+			Subject string `json:"subject"`
+			Inbox   string `json:"inbox"`
+		}
+		require.NoError(t, json.Unmarshal([]byte(stdout), &result))
+		assert.Equal(t, subject, result.Subject)
+		assert.Equal(t, inboxEmails[1], result.Inbox)
+
+		wg.Wait()
+	})
+
+	t.Run("wait on all inboxes", func(t *testing.T) {
+		timestamp := time.Now().Format("150405.000")
+		subject := "All Inboxes Test " + timestamp
+
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			time.Sleep(500 * time.Millisecond)
+			<-sendTestEmailAsync(inboxEmails[0], subject, "Testing wait on every inbox")
+		}()
+
+		stdout, stderr, code := runVSBWithConfig(t, configDir, "email", "wait",
+			"--inbox", "all", "--subject", subject, "--timeout", "30s", "--output", "json")
+		require.Equal(t, 0, code, "wait failed: stdout=%s, stderr=%s", stdout, stderr)
+
+		var result struct {
+			Inbox string `json:"inbox"`
+		}
+		require.NoError(t, json.Unmarshal([]byte(stdout), &result))
+		assert.Equal(t, inboxEmails[0], result.Inbox)
+
+		wg.Wait()
+	})
+
+	t.Run("wait on all inboxes without inboxes fails", func(t *testing.T) {
+		_, stderr, code := runVSBWithConfig(t, t.TempDir(), "email", "wait", "--inbox", "all", "--timeout", "5s")
+		assert.NotEqual(t, 0, code)
+		assert.Contains(t, stderr, "no inboxes found")
+	})
 }
 
 // Verify async helpers actually check SMTP config
@@ -526,3 +748,223 @@ func init() {
 	// Ensure environment is loaded for getSMTPConfig in async helpers
 	os.Getenv("SMTP_HOST")
 }
+
+// TestWaitTrigger tests running a trigger after the watch is established.
+func TestWaitTrigger(t *testing.T) {
+	skipIfNoSMTP(t)
+	configDir := t.TempDir()
+
+	// Create inbox
+	stdout, _, code := runVSBWithConfig(t, configDir, "inbox", "create", "--output", "json")
+	require.Equal(t, 0, code)
+
+	var createResult struct {
+		Email string `json:"email"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(stdout), &createResult))
+	inboxEmail := createResult.Email
+
+	t.Cleanup(func() {
+		runVSBWithConfig(t, configDir, "inbox", "delete", inboxEmail)
+	})
+
+	t.Run("trigger url sends email", func(t *testing.T) {
+		uniqueSubject := "Trigger Test " + time.Now().Format("150405.000")
+
+		// The "application" sends the email when the trigger URL is hit
+		app := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			<-sendTestEmailAsync(inboxEmail, uniqueSubject, "Sent by trigger")
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		defer app.Close()
+
+		stdout, stderr, code := runVSBWithConfig(t, configDir, "email", "wait",
+			"--subject", uniqueSubject,
+			"--trigger-url", app.URL+"/reset",
+			"--timeout", "30s",
+			"--output", "json")
+		require.Equal(t, 0, code, "stderr: %s", stderr)
+
+		var result struct {
+			Subject string `json:"subject"`
+		}
+		require.NoError(t, json.Unmarshal([]byte(stdout), &result))
+		assert.Equal(t, uniqueSubject, result.Subject)
+	})
+
+	t.Run("failing trigger aborts wait", func(t *testing.T) {
+		start := time.Now()
+		_, stderr, code := runVSBWithConfig(t, configDir, "email", "wait",
+			"--trigger", "exit 1",
+			"--timeout", "30s")
+		elapsed := time.Since(start)
+
+		assert.NotEqual(t, 0, code)
+		assert.Contains(t, stderr, "trigger command failed")
+		assert.Less(t, elapsed, 10*time.Second, "should not wait for the timeout")
+	})
+}
+
+// TestWaitExec tests running a command with the matched email.
+func TestWaitExec(t *testing.T) {
+	skipIfNoSMTP(t)
+	configDir := t.TempDir()
+
+	stdout, _, code := runVSBWithConfig(t, configDir, "inbox", "create", "--output", "json")
+	require.Equal(t, 0, code)
+
+	var createResult struct {
+		Email string `json:"email"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(stdout), &createResult))
+	inboxEmail := createResult.Email
+
+	t.Cleanup(func() {
+		runVSBWithConfig(t, configDir, "inbox", "delete", inboxEmail)
+	})
+
+	uniqueSubject := "Exec Test's " + time.Now().Format("150405.000")
+	sendTestEmail(t, inboxEmail, uniqueSubject, "Body")
+
+	t.Run("command output replaces the wait output", func(t *testing.T) {
+		stdout, stderr, code := runVSBWithConfig(t, configDir, "email", "wait",
+			"--subject", uniqueSubject,
+			"--exec", "echo subject={subject} inbox=$VSB_EMAIL_INBOX",
+			"--timeout", "30s")
+		require.Equal(t, 0, code, "stderr: %s", stderr)
+		assert.Equal(t, "subject="+uniqueSubject+" inbox="+inboxEmail+"\n", stdout)
+	})
+
+	t.Run("exit status is passed on", func(t *testing.T) {
+		_, stderr, code := runVSBWithConfig(t, configDir, "email", "wait",
+			"--subject", uniqueSubject,
+			"--exec", "exit 4",
+			"--timeout", "30s")
+		assert.Equal(t, 4, code)
+		assert.Contains(t, stderr, "exec command exited with status 4")
+	})
+}
+
+// TestWaitSaveToDir tests saving the matched emails to a directory.
+func TestWaitSaveToDir(t *testing.T) {
+	skipIfNoSMTP(t)
+	configDir := t.TempDir()
+
+	stdout, _, code := runVSBWithConfig(t, configDir, "inbox", "create", "--output", "json")
+	require.Equal(t, 0, code)
+
+	var createResult struct {
+		Email string `json:"email"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(stdout), &createResult))
+	inboxEmail := createResult.Email
+
+	t.Cleanup(func() {
+		runVSBWithConfig(t, configDir, "inbox", "delete", inboxEmail)
+	})
+
+	subject := "Save To Dir Test " + time.Now().Format("150405.000")
+	sendTestEmail(t, inboxEmail, subject, "First email")
+	sendTestEmail(t, inboxEmail, subject, "Second email")
+
+	t.Run("eml", func(t *testing.T) {
+		dir := filepath.Join(t.TempDir(), "emails")
+		stdout, stderr, code := runVSBWithConfig(t, configDir, "email", "wait",
+			"--count", "2", "--subject", subject, "--timeout", "30s",
+			"--save-to-dir", dir, "--output", "json")
+		require.Equal(t, 0, code, "stderr: %s", stderr)
+		assert.Contains(t, stdout, subject, "stdout keeps the JSON output")
+
+		files, err := filepath.Glob(filepath.Join(dir, "*.eml"))
+		require.NoError(t, err)
+		require.Len(t, files, 2)
+		for _, f := range files {
+			data, err := os.ReadFile(f)
+			require.NoError(t, err)
+			assert.Contains(t, string(data), "Subject: "+subject)
+			assert.Contains(t, stderr, "Saved "+f)
+		}
+	})
+
+	t.Run("json", func(t *testing.T) {
+		dir := t.TempDir()
+		_, stderr, code := runVSBWithConfig(t, configDir, "email", "wait",
+			"--count", "2", "--subject", subject, "--timeout", "30s",
+			"--save-to-dir", dir, "--save-format", "json", "--quiet")
+		require.Equal(t, 0, code, "stderr: %s", stderr)
+
+		files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+		require.NoError(t, err)
+		assert.Len(t, files, 2)
+	})
+}
+
+// TestWaitPollInterval checks that --poll-interval polls the server at about
+// the requested interval, by counting sync requests through a reverse proxy
+// in front of the API.
+func TestWaitPollInterval(t *testing.T) {
+	configDir := t.TempDir()
+
+	stdout, _, code := runVSBWithConfig(t, configDir, "inbox", "create", "--output", "json")
+	require.Equal(t, 0, code)
+	var createResult struct {
+		Email string `json:"email"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(stdout), &createResult))
+	t.Cleanup(func() {
+		runVSBWithConfig(t, configDir, "inbox", "delete", createResult.Email)
+	})
+
+	target, err := url.Parse(baseURL)
+	require.NoError(t, err)
+	var mu sync.Mutex
+	var syncs []time.Time
+	forward := &httputil.ReverseProxy{Rewrite: func(r *httputil.ProxyRequest) { r.SetURL(target) }}
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/sync") {
+			mu.Lock()
+			syncs = append(syncs, time.Now())
+			mu.Unlock()
+		}
+		forward.ServeHTTP(w, r)
+	}))
+	defer api.Close()
+
+	env := map[string]string{
+		"VSB_API_KEY":  apiKey,
+		"VSB_BASE_URL": api.URL,
+		"VSB_STRATEGY": "sse", // the flag switches to polling
+	}
+
+	t.Run("polls at the requested interval", func(t *testing.T) {
+		_, stderr, code := runVSBWithConfigAndEnv(t, configDir, env,
+			"email", "wait", "--subject", "never sent", "--poll-interval", "1s", "--timeout", "6s")
+		assert.NotEqual(t, 0, code)
+		assert.Contains(t, stderr, "timeout")
+
+		mu.Lock()
+		defer mu.Unlock()
+		require.GreaterOrEqual(t, len(syncs), 4, "expected a sync request about every second")
+		assert.LessOrEqual(t, len(syncs), 9)
+		// Importing the inbox and the first poll check the sync status back
+		// to back; the polls after that are an interval apart
+		for i := 2; i < len(syncs); i++ {
+			gap := syncs[i].Sub(syncs[i-1])
+			assert.InDelta(t, 1.0, gap.Seconds(), 0.4, "gap %d", i)
+		}
+	})
+
+	t.Run("rejects intervals below the minimum", func(t *testing.T) {
+		_, stderr, code := runVSBWithConfigAndEnv(t, configDir, env,
+			"email", "wait", "--poll-interval", "100ms", "--timeout", "5s")
+		assert.NotEqual(t, 0, code)
+		assert.Contains(t, stderr, "below the minimum of 500ms")
+	})
+
+	t.Run("rejects intervals not shorter than the timeout", func(t *testing.T) {
+		_, stderr, code := runVSBWithConfigAndEnv(t, configDir, env,
+			"email", "wait", "--poll-interval", "5s", "--timeout", "5s")
+		assert.NotEqual(t, 0, code)
+		assert.Contains(t, stderr, "must be shorter than --timeout")
+	})
+}
@@ -0,0 +1,263 @@
+package analysis
+
+import (
+	"fmt"
+	"net/mail"
+	"strings"
+
+	vaultsandbox "github.com/vaultsandbox/client-go"
+	"github.com/vaultsandbox/client-go/authresults"
+)
+
+// Problem kinds, as reported in AuthExplanation.Kind.
+const (
+	KindMissingRecord    = "missingRecord"    // no SPF or DMARC record published
+	KindMissingSignature = "missingSignature" // the message was not DKIM-signed
+	KindUnauthorized     = "unauthorized"     // SPF does not list the sending IP
+	KindSignature        = "signature"        // a DKIM signature did not verify
+	KindAlignment        = "alignment"        // nothing passed for the From domain
+	KindDNSError         = "dnsError"         // temporary DNS failure
+	KindInvalidRecord    = "invalidRecord"    // the published record is broken
+)
+
+// Specifications linked from explanations.
+const (
+	SPFDocURL   = "https://www.rfc-editor.org/rfc/rfc7208"
+	DKIMDocURL  = "https://www.rfc-editor.org/rfc/rfc6376"
+	DMARCDocURL = "https://www.rfc-editor.org/rfc/rfc7489"
+)
+
+// AuthExplanation describes why an SPF, DKIM or DMARC check did not pass
+// and what would fix it.
+type AuthExplanation struct {
+	Mechanism string `json:"mechanism"` // spf, dkim or dmarc
+	Result    string `json:"result"`
+	Kind      string `json:"kind"`
+	Domain    string `json:"domain,omitempty"`
+	Problem   string `json:"problem"`
+	Fix       string `json:"fix"`
+	Record    string `json:"record,omitempty"` // DNS name the fix applies to
+	DocURL    string `json:"docUrl"`
+}
+
+// ExplainAuth explains every SPF, DKIM and DMARC result of the email that
+// did not pass. A mechanism the server did not report is explained as if
+// its result were "none". Passed and skipped checks are left out, and so is
+// an email without authentication results.
+func ExplainAuth(email *vaultsandbox.Email) []AuthExplanation {
+	auth := email.AuthResults
+	if auth == nil {
+		return nil
+	}
+	fromDomain := addressDomain(email.From)
+
+	var explanations []AuthExplanation
+	if e, ok := explainSPF(auth.SPF, fromDomain); ok {
+		explanations = append(explanations, e)
+	}
+	explanations = append(explanations, explainDKIM(auth.DKIM, fromDomain)...)
+	if e, ok := explainDMARC(auth, fromDomain); ok {
+		explanations = append(explanations, e)
+	}
+	return explanations
+}
+
+// passed reports whether result counts as passing, as in AuthResults.Validate
+func passed(result string) bool {
+	result = strings.ToLower(result)
+	return result == "pass" || result == "skipped"
+}
+
+func explainSPF(spf *authresults.SPFResult, fromDomain string) (AuthExplanation, bool) {
+	if spf == nil {
+		spf = &authresults.SPFResult{Result: "none"}
+	}
+	if passed(spf.Result) {
+		return AuthExplanation{}, false
+	}
+	domain := spf.Domain
+	if domain == "" {
+		domain = fromDomain
+	}
+	e := AuthExplanation{
+		Mechanism: "spf",
+		Result:    strings.ToLower(spf.Result),
+		Domain:    domain,
+		Record:    domain,
+		DocURL:    SPFDocURL,
+	}
+	sender := "the sending server"
+	if spf.IP != "" {
+		sender = spf.IP
+	}
+	mechanism := "ip4:" + spf.IP
+	if spf.IP == "" {
+		mechanism = "include:<your mail provider>"
+	} else if strings.Contains(spf.IP, ":") {
+		mechanism = "ip6:" + spf.IP
+	}
+
+	switch e.Result {
+	case "none":
+		e.Kind = KindMissingRecord
+		e.Problem = fmt.Sprintf("No SPF record was found for the envelope sender domain %s.", orUnknown(domain))
+		e.Fix = fmt.Sprintf("Publish a TXT record such as \"v=spf1 %s ~all\".", mechanism)
+	case "temperror":
+		e.Kind = KindDNSError
+		e.Problem = fmt.Sprintf("The SPF record of %s could not be looked up (temporary DNS error).", orUnknown(domain))
+		e.Fix = "Check that the domain's name servers answer TXT queries, then send again."
+	case "permerror":
+		e.Kind = KindInvalidRecord
+		e.Problem = fmt.Sprintf("The SPF record of %s is invalid.", orUnknown(domain))
+		e.Fix = "Fix the record's syntax: publish exactly one v=spf1 TXT record and keep it under 10 DNS lookups (include, a, mx, redirect)."
+	default: // fail, softfail, neutral
+		e.Kind = KindUnauthorized
+		e.Problem = fmt.Sprintf("The SPF record of %s does not authorize %s.", orUnknown(domain), sender)
+		e.Fix = fmt.Sprintf("Add \"%s\" to the record, before its all mechanism.", mechanism)
+	}
+	if spf.Details != "" {
+		e.Problem += " (" + spf.Details + ")"
+	}
+	return e, true
+}
+
+func explainDKIM(dkim []authresults.DKIMResult, fromDomain string) []AuthExplanation {
+	if len(dkim) == 0 {
+		dkim = []authresults.DKIMResult{{Result: "none"}}
+	}
+	// One valid signature is enough
+	for _, d := range dkim {
+		if strings.EqualFold(d.Result, "pass") {
+			return nil
+		}
+	}
+
+	var explanations []AuthExplanation
+	for _, d := range dkim {
+		if passed(d.Result) {
+			continue
+		}
+		domain := d.Domain
+		if domain == "" {
+			domain = fromDomain
+		}
+		selector := d.Selector
+		if selector == "" {
+			selector = "<selector>"
+		}
+		e := AuthExplanation{
+			Mechanism: "dkim",
+			Result:    strings.ToLower(d.Result),
+			Domain:    domain,
+			Record:    selector + "._domainkey." + orUnknown(domain),
+			DocURL:    DKIMDocURL,
+		}
+
+		switch e.Result {
+		case "none":
+			e.Kind = KindMissingSignature
+			e.Problem = "The message has no DKIM signature."
+			e.Fix = fmt.Sprintf("Enable DKIM signing for %s and publish the public key as a TXT record \"v=DKIM1; k=rsa; p=<public key>\".", orUnknown(domain))
+		case "temperror":
+			e.Kind = KindDNSError
+			e.Problem = "The DKIM public key could not be looked up (temporary DNS error)."
+			e.Fix = "Check that the domain's name servers answer TXT queries for the key, then send again."
+		case "permerror":
+			e.Kind = KindInvalidRecord
+			e.Problem = "The DKIM public key record is missing or malformed."
+			e.Fix = "Publish the key as a TXT record \"v=DKIM1; k=rsa; p=<public key>\" under the signature's selector."
+		default: // fail, neutral, policy
+			e.Kind = KindSignature
+			e.Problem = "The DKIM signature did not verify: the message was changed after signing, or the published key does not match the signing key."
+			e.Fix = "Compare the published key with the one the mail server signs with, and make sure nothing rewrites the message (footers, link tracking) after signing."
+		}
+		if d.Info != "" {
+			e.Problem += " (" + d.Info + ")"
+		}
+		explanations = append(explanations, e)
+	}
+	return explanations
+}
+
+func explainDMARC(auth *authresults.AuthResults, fromDomain string) (AuthExplanation, bool) {
+	dmarc := auth.DMARC
+	if dmarc == nil {
+		dmarc = &authresults.DMARCResult{Result: "none"}
+	}
+	if passed(dmarc.Result) {
+		return AuthExplanation{}, false
+	}
+	domain := dmarc.Domain
+	if domain == "" {
+		domain = fromDomain
+	}
+	e := AuthExplanation{
+		Mechanism: "dmarc",
+		Result:    strings.ToLower(dmarc.Result),
+		Domain:    domain,
+		Record:    "_dmarc." + orUnknown(domain),
+		DocURL:    DMARCDocURL,
+	}
+
+	switch e.Result {
+	case "none":
+		e.Kind = KindMissingRecord
+		e.Problem = fmt.Sprintf("No DMARC record was found for the From domain %s.", orUnknown(domain))
+		e.Fix = fmt.Sprintf("Publish a TXT record such as \"v=DMARC1; p=none; rua=mailto:dmarc-reports@%s\".", orUnknown(domain))
+	case "temperror":
+		e.Kind = KindDNSError
+		e.Problem = fmt.Sprintf("The DMARC record of %s could not be looked up (temporary DNS error).", orUnknown(domain))
+		e.Fix = "Check that the domain's name servers answer TXT queries, then send again."
+	case "permerror":
+		e.Kind = KindInvalidRecord
+		e.Problem = fmt.Sprintf("The DMARC record of %s is invalid.", orUnknown(domain))
+		e.Fix = "Fix the record's syntax: it must start with \"v=DMARC1;\" and have a p= tag of none, quarantine or reject."
+	default: // fail
+		e.Kind = KindAlignment
+		e.Problem = fmt.Sprintf("Neither SPF nor DKIM passed for a domain aligned with the From domain %s%s.",
+			orUnknown(domain), alignmentDetails(auth))
+		e.Fix = fmt.Sprintf("Send with an envelope sender on %[1]s, or sign with d=%[1]s, and make that check pass.", orUnknown(domain))
+	}
+	if dmarc.Policy != "" && e.Result == "fail" {
+		e.Problem += fmt.Sprintf(" The domain's policy is p=%s.", dmarc.Policy)
+	}
+	return e, true
+}
+
+// alignmentDetails lists the domains SPF and DKIM checked, for a failed
+// DMARC explanation
+func alignmentDetails(auth *authresults.AuthResults) string {
+	var checked []string
+	if auth.SPF != nil && auth.SPF.Domain != "" {
+		checked = append(checked, fmt.Sprintf("SPF %s for %s", strings.ToLower(auth.SPF.Result), auth.SPF.Domain))
+	}
+	for _, d := range auth.DKIM {
+		if d.Domain != "" {
+			checked = append(checked, fmt.Sprintf("DKIM %s for %s", strings.ToLower(d.Result), d.Domain))
+		}
+	}
+	if len(checked) == 0 {
+		return ""
+	}
+	return " (" + strings.Join(checked, ", ") + ")"
+}
+
+// addressDomain returns the lowercased domain of an address, or ""
+func addressDomain(from string) string {
+	addr, err := mail.ParseAddress(from)
+	if err != nil {
+		return ""
+	}
+	at := strings.LastIndex(addr.Address, "@")
+	if at < 0 {
+		return ""
+	}
+	return strings.ToLower(addr.Address[at+1:])
+}
+
+func orUnknown(domain string) string {
+	if domain == "" {
+		return "<domain>"
+	}
+	return domain
+}
@@ -0,0 +1,230 @@
+package analysis
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	vaultsandbox "github.com/vaultsandbox/client-go"
+	"github.com/vaultsandbox/client-go/authresults"
+)
+
+func TestExplainAuthPassing(t *testing.T) {
+	tests := []struct {
+		name string
+		auth *authresults.AuthResults
+	}{
+		{"no results", nil},
+		{"all pass", &authresults.AuthResults{
+			SPF:   &authresults.SPFResult{Result: "pass"},
+			DKIM:  []authresults.DKIMResult{{Result: "pass"}},
+			DMARC: &authresults.DMARCResult{Result: "pass"},
+		}},
+		{"skipped counts as passed", &authresults.AuthResults{
+			SPF:   &authresults.SPFResult{Result: "skipped"},
+			DKIM:  []authresults.DKIMResult{{Result: "skipped"}},
+			DMARC: &authresults.DMARCResult{Result: "SKIPPED"},
+		}},
+		{"one passing DKIM signature is enough", &authresults.AuthResults{
+			SPF:   &authresults.SPFResult{Result: "pass"},
+			DKIM:  []authresults.DKIMResult{{Result: "fail", Domain: "esp.example"}, {Result: "pass"}},
+			DMARC: &authresults.DMARCResult{Result: "pass"},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			email := &vaultsandbox.Email{From: "a@example.com", AuthResults: tt.auth}
+			assert.Empty(t, ExplainAuth(email))
+		})
+	}
+}
+
+func TestExplainAuth(t *testing.T) {
+	pass := func(auth authresults.AuthResults) *authresults.AuthResults {
+		if auth.SPF == nil {
+			auth.SPF = &authresults.SPFResult{Result: "pass", Domain: "example.com"}
+		}
+		if auth.DKIM == nil {
+			auth.DKIM = []authresults.DKIMResult{{Result: "pass", Domain: "example.com"}}
+		}
+		if auth.DMARC == nil {
+			auth.DMARC = &authresults.DMARCResult{Result: "pass", Domain: "example.com"}
+		}
+		return &auth
+	}
+
+	tests := []struct {
+		name      string
+		auth      *authresults.AuthResults
+		mechanism string
+		result    string
+		kind      string
+		domain    string
+		record    string
+		problem   string
+		fix       string
+		docURL    string
+	}{
+		{
+			name:      "spf fail",
+			auth:      pass(authresults.AuthResults{SPF: &authresults.SPFResult{Result: "fail", Domain: "bounce.example.com", IP: "203.0.113.7"}}),
+			mechanism: "spf", result: "fail", kind: KindUnauthorized,
+			domain: "bounce.example.com", record: "bounce.example.com",
+			problem: "The SPF record of bounce.example.com does not authorize 203.0.113.7.",
+			fix:     `Add "ip4:203.0.113.7" to the record`,
+			docURL:  SPFDocURL,
+		},
+		{
+			name:      "spf softfail with an IPv6 sender",
+			auth:      pass(authresults.AuthResults{SPF: &authresults.SPFResult{Result: "softfail", Domain: "example.com", IP: "2001:db8::1"}}),
+			mechanism: "spf", result: "softfail", kind: KindUnauthorized,
+			domain: "example.com", record: "example.com",
+			problem: "does not authorize 2001:db8::1",
+			fix:     `Add "ip6:2001:db8::1" to the record`,
+			docURL:  SPFDocURL,
+		},
+		{
+			name:      "spf none",
+			auth:      pass(authresults.AuthResults{SPF: &authresults.SPFResult{Result: "none", Domain: "example.com", IP: "203.0.113.7"}}),
+			mechanism: "spf", result: "none", kind: KindMissingRecord,
+			domain: "example.com", record: "example.com",
+			problem: "No SPF record was found for the envelope sender domain example.com.",
+			fix:     `"v=spf1 ip4:203.0.113.7 ~all"`,
+			docURL:  SPFDocURL,
+		},
+		{
+			name:      "spf not reported uses the From domain",
+			auth:      &authresults.AuthResults{DKIM: []authresults.DKIMResult{{Result: "pass"}}, DMARC: &authresults.DMARCResult{Result: "pass"}},
+			mechanism: "spf", result: "none", kind: KindMissingRecord,
+			domain: "sender.example", record: "sender.example",
+			problem: "No SPF record was found for the envelope sender domain sender.example.",
+			fix:     "include:<your mail provider>",
+			docURL:  SPFDocURL,
+		},
+		{
+			name:      "spf temperror",
+			auth:      pass(authresults.AuthResults{SPF: &authresults.SPFResult{Result: "temperror", Domain: "example.com"}}),
+			mechanism: "spf", result: "temperror", kind: KindDNSError,
+			domain: "example.com", record: "example.com",
+			problem: "could not be looked up (temporary DNS error)",
+			fix:     "name servers answer TXT queries",
+			docURL:  SPFDocURL,
+		},
+		{
+			name:      "spf permerror",
+			auth:      pass(authresults.AuthResults{SPF: &authresults.SPFResult{Result: "permerror", Domain: "example.com", Details: "too many DNS lookups"}}),
+			mechanism: "spf", result: "permerror", kind: KindInvalidRecord,
+			domain: "example.com", record: "example.com",
+			problem: "The SPF record of example.com is invalid. (too many DNS lookups)",
+			fix:     "10 DNS lookups",
+			docURL:  SPFDocURL,
+		},
+		{
+			name:      "dkim fail",
+			auth:      pass(authresults.AuthResults{DKIM: []authresults.DKIMResult{{Result: "fail", Domain: "example.com", Selector: "s1", Info: "body hash did not verify"}}}),
+			mechanism: "dkim", result: "fail", kind: KindSignature,
+			domain: "example.com", record: "s1._domainkey.example.com",
+			problem: "The DKIM signature did not verify",
+			fix:     "Compare the published key",
+			docURL:  DKIMDocURL,
+		},
+		{
+			name:      "dkim none",
+			auth:      pass(authresults.AuthResults{DKIM: []authresults.DKIMResult{{Result: "none"}}}),
+			mechanism: "dkim", result: "none", kind: KindMissingSignature,
+			domain: "sender.example", record: "<selector>._domainkey.sender.example",
+			problem: "The message has no DKIM signature.",
+			fix:     "Enable DKIM signing for sender.example",
+			docURL:  DKIMDocURL,
+		},
+		{
+			name:      "dkim not reported",
+			auth:      &authresults.AuthResults{SPF: &authresults.SPFResult{Result: "pass"}, DKIM: []authresults.DKIMResult{}, DMARC: &authresults.DMARCResult{Result: "pass"}},
+			mechanism: "dkim", result: "none", kind: KindMissingSignature,
+			domain: "sender.example", record: "<selector>._domainkey.sender.example",
+			problem: "no DKIM signature",
+			fix:     `"v=DKIM1; k=rsa; p=<public key>"`,
+			docURL:  DKIMDocURL,
+		},
+		{
+			name:      "dkim temperror",
+			auth:      pass(authresults.AuthResults{DKIM: []authresults.DKIMResult{{Result: "temperror", Domain: "example.com", Selector: "s1"}}}),
+			mechanism: "dkim", result: "temperror", kind: KindDNSError,
+			domain: "example.com", record: "s1._domainkey.example.com",
+			problem: "temporary DNS error",
+			fix:     "send again",
+			docURL:  DKIMDocURL,
+		},
+		{
+			name:      "dmarc fail lists the checked domains",
+			auth:      pass(authresults.AuthResults{SPF: &authresults.SPFResult{Result: "pass", Domain: "esp.example"}, DKIM: []authresults.DKIMResult{{Result: "pass", Domain: "esp.example"}}, DMARC: &authresults.DMARCResult{Result: "fail", Domain: "example.com", Policy: "reject"}}),
+			mechanism: "dmarc", result: "fail", kind: KindAlignment,
+			domain: "example.com", record: "_dmarc.example.com",
+			problem: "Neither SPF nor DKIM passed for a domain aligned with the From domain example.com (SPF pass for esp.example, DKIM pass for esp.example). The domain's policy is p=reject.",
+			fix:     "envelope sender on example.com, or sign with d=example.com",
+			docURL:  DMARCDocURL,
+		},
+		{
+			name:      "dmarc none",
+			auth:      pass(authresults.AuthResults{DMARC: &authresults.DMARCResult{Result: "none"}}),
+			mechanism: "dmarc", result: "none", kind: KindMissingRecord,
+			domain: "sender.example", record: "_dmarc.sender.example",
+			problem: "No DMARC record was found for the From domain sender.example.",
+			fix:     `"v=DMARC1; p=none; rua=mailto:dmarc-reports@sender.example"`,
+			docURL:  DMARCDocURL,
+		},
+		{
+			name:      "dmarc temperror",
+			auth:      pass(authresults.AuthResults{DMARC: &authresults.DMARCResult{Result: "temperror", Domain: "example.com"}}),
+			mechanism: "dmarc", result: "temperror", kind: KindDNSError,
+			domain: "example.com", record: "_dmarc.example.com",
+			problem: "The DMARC record of example.com could not be looked up",
+			fix:     "send again",
+			docURL:  DMARCDocURL,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			email := &vaultsandbox.Email{From: "Sender <me@Sender.example>", AuthResults: tt.auth}
+			explanations := ExplainAuth(email)
+			require.Len(t, explanations, 1)
+
+			e := explanations[0]
+			assert.Equal(t, tt.mechanism, e.Mechanism)
+			assert.Equal(t, tt.result, e.Result)
+			assert.Equal(t, tt.kind, e.Kind)
+			assert.Equal(t, tt.domain, e.Domain)
+			assert.Equal(t, tt.record, e.Record)
+			assert.Contains(t, e.Problem, tt.problem)
+			assert.Contains(t, e.Fix, tt.fix)
+			assert.Equal(t, tt.docURL, e.DocURL)
+		})
+	}
+}
+
+func TestExplainAuthAllFailing(t *testing.T) {
+	email := &vaultsandbox.Email{
+		From: "a@example.com",
+		AuthResults: &authresults.AuthResults{
+			SPF: &authresults.SPFResult{Result: "fail"},
+			DKIM: []authresults.DKIMResult{
+				{Result: "fail", Domain: "example.com"},
+				{Result: "none", Domain: "esp.example"},
+			},
+			DMARC: &authresults.DMARCResult{Result: "fail"},
+		},
+	}
+
+	var mechanisms []string
+	for _, e := range ExplainAuth(email) {
+		mechanisms = append(mechanisms, e.Mechanism+":"+e.Kind)
+	}
+	assert.Equal(t, []string{
+		"spf:" + KindUnauthorized,
+		"dkim:" + KindSignature,
+		"dkim:" + KindMissingSignature,
+		"dmarc:" + KindAlignment,
+	}, mechanisms)
+}
@@ -0,0 +1,208 @@
+// Package analysis provides heuristic checks on received emails.
+package analysis
+
+import (
+	"net"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"strings"
+	"unicode"
+
+	vaultsandbox "github.com/vaultsandbox/client-go"
+)
+
+// Spam rule identifiers, as reported in SpamReport.TriggeredRules.
+const (
+	RuleAllCaps              = "allCaps"
+	RuleExcessiveExclamation = "excessiveExclamation"
+	RuleMismatchedSender     = "mismatchedSender"
+	RuleSuspiciousRedirect   = "suspiciousRedirect"
+	RuleMissingUnsubscribe   = "missingUnsubscribe"
+)
+
+// Risk levels returned by CheckSpam.
+const (
+	RiskLow    = "low"
+	RiskMedium = "medium"
+	RiskHigh   = "high"
+)
+
+// SpamRule is a single heuristic. Weight is added to the risk score when
+// the rule is triggered.
+type SpamRule struct {
+	ID          string
+	Description string
+	Weight      int
+	Check       func(email *vaultsandbox.Email) bool
+}
+
+// SpamRules are evaluated in order by CheckSpam.
+var SpamRules = []SpamRule{
+	{RuleAllCaps, "Subject is written in ALL CAPS", 1, SubjectAllCaps},
+	{RuleExcessiveExclamation, "Excessive exclamation marks", 1, ExcessiveExclamation},
+	{RuleMismatchedSender, "Sender display name names a different domain", 2, MismatchedSender},
+	{RuleSuspiciousRedirect, "Links redirect through shorteners, IP addresses or other domains", 2, SuspiciousRedirect},
+	{RuleMissingUnsubscribe, "No unsubscribe link or List-Unsubscribe header", 1, MissingUnsubscribe},
+}
+
+// SpamReport is the result of CheckSpam.
+type SpamReport struct {
+	RiskLevel      string   `json:"riskLevel"`
+	Score          int      `json:"score"`
+	TriggeredRules []string `json:"triggeredRules"`
+}
+
+// CheckSpam runs every rule against the email. A score below 2 is low risk,
+// below 4 medium, and anything higher is high.
+func CheckSpam(email *vaultsandbox.Email) SpamReport {
+	report := SpamReport{TriggeredRules: []string{}}
+	for _, rule := range SpamRules {
+		if rule.Check(email) {
+			report.Score += rule.Weight
+			report.TriggeredRules = append(report.TriggeredRules, rule.ID)
+		}
+	}
+
+	switch {
+	case report.Score >= 4:
+		report.RiskLevel = RiskHigh
+	case report.Score >= 2:
+		report.RiskLevel = RiskMedium
+	default:
+		report.RiskLevel = RiskLow
+	}
+	return report
+}
+
+// SubjectAllCaps reports whether the subject is mostly uppercase, or shouts
+// with at least two all-caps words. Short acronyms alone do not count.
+func SubjectAllCaps(email *vaultsandbox.Email) bool {
+	var letters, upper int
+	for _, r := range email.Subject {
+		if unicode.IsLetter(r) {
+			letters++
+			if unicode.IsUpper(r) {
+				upper++
+			}
+		}
+	}
+	if letters >= 5 && upper*10 >= letters*7 {
+		return true
+	}
+
+	shouted := 0
+	for _, word := range strings.FieldsFunc(email.Subject, func(r rune) bool { return !unicode.IsLetter(r) }) {
+		if len([]rune(word)) >= 4 && strings.ToUpper(word) == word {
+			shouted++
+		}
+	}
+	return shouted >= 2
+}
+
+// ExcessiveExclamation reports two or more exclamation marks in the subject,
+// or a run of three in the body.
+func ExcessiveExclamation(email *vaultsandbox.Email) bool {
+	if strings.Count(email.Subject, "!") >= 2 {
+		return true
+	}
+	return strings.Contains(email.Text, "!!!") || strings.Contains(email.HTML, "!!!")
+}
+
+// domainPattern finds domain-like tokens such as "paypal.com"
+var domainPattern = regexp.MustCompile(`(?i)\b[a-z0-9](?:[a-z0-9-]*[a-z0-9])?(?:\.[a-z0-9](?:[a-z0-9-]*[a-z0-9])?)*\.[a-z]{2,}\b`)
+
+// MismatchedSender reports whether the From display name mentions a domain
+// (or address) other than the one the email was actually sent from, as in
+// "PayPal.com <alerts@evil.example>".
+func MismatchedSender(email *vaultsandbox.Email) bool {
+	addr, err := mail.ParseAddress(email.From)
+	if err != nil || addr.Name == "" {
+		return false
+	}
+	at := strings.LastIndex(addr.Address, "@")
+	if at < 0 {
+		return false
+	}
+	senderDomain := strings.ToLower(addr.Address[at+1:])
+
+	for _, token := range domainPattern.FindAllString(addr.Name, -1) {
+		token = strings.ToLower(token)
+		if token != senderDomain && !strings.HasSuffix(senderDomain, "."+token) {
+			return true
+		}
+	}
+	return false
+}
+
+// urlShorteners hide the destination of a link
+var urlShorteners = map[string]bool{
+	"bit.ly":      true,
+	"tinyurl.com": true,
+	"t.co":        true,
+	"goo.gl":      true,
+	"ow.ly":       true,
+	"is.gd":       true,
+	"buff.ly":     true,
+	"rebrand.ly":  true,
+	"cutt.ly":     true,
+}
+
+// SuspiciousRedirect reports links that use a URL shortener, point at a bare
+// IP address, or carry another site's URL in their query string (open
+// redirects such as "?url=https://elsewhere.example").
+func SuspiciousRedirect(email *vaultsandbox.Email) bool {
+	return len(SuspiciousLinks(email)) > 0
+}
+
+// SuspiciousLinks returns the links that SuspiciousRedirect objects to, in
+// the order they appear in the email.
+func SuspiciousLinks(email *vaultsandbox.Email) []string {
+	var suspicious []string
+	for _, link := range email.Links {
+		if suspiciousLink(link) {
+			suspicious = append(suspicious, link)
+		}
+	}
+	return suspicious
+}
+
+func suspiciousLink(link string) bool {
+	u, err := url.Parse(link)
+	if err != nil {
+		return false
+	}
+	host := strings.ToLower(u.Hostname())
+	if urlShorteners[strings.TrimPrefix(host, "www.")] || net.ParseIP(host) != nil {
+		return true
+	}
+	for _, values := range u.Query() {
+		for _, v := range values {
+			target, err := url.Parse(v)
+			if err != nil || (target.Scheme != "http" && target.Scheme != "https") {
+				continue
+			}
+			if th := strings.ToLower(target.Hostname()); th != "" && th != host {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// MissingUnsubscribe reports whether the email offers no way to unsubscribe:
+// no List-Unsubscribe header and no link or text mentioning unsubscribe.
+func MissingUnsubscribe(email *vaultsandbox.Email) bool {
+	for name := range email.Headers {
+		if strings.EqualFold(name, "List-Unsubscribe") {
+			return false
+		}
+	}
+	for _, link := range email.Links {
+		if strings.Contains(strings.ToLower(link), "unsubscribe") {
+			return false
+		}
+	}
+	body := strings.ToLower(email.Text + email.HTML)
+	return !strings.Contains(body, "unsubscribe")
+}
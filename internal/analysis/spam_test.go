@@ -0,0 +1,152 @@
+package analysis
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	vaultsandbox "github.com/vaultsandbox/client-go"
+)
+
+func TestSubjectAllCaps(t *testing.T) {
+	tests := []struct {
+		subject string
+		want    bool
+	}{
+		{"URGENT ACTION REQUIRED", true},
+		{"Act now: FREE MONEY inside", true},
+		{"Your order has shipped", false},
+		{"Reset your HTTP API key", false},
+		{"OK", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.subject, func(t *testing.T) {
+			assert.Equal(t, tt.want, SubjectAllCaps(&vaultsandbox.Email{Subject: tt.subject}))
+		})
+	}
+}
+
+func TestExcessiveExclamation(t *testing.T) {
+	tests := []struct {
+		name  string
+		email vaultsandbox.Email
+		want  bool
+	}{
+		{"subject with two", vaultsandbox.Email{Subject: "Win now!!"}, true},
+		{"body run of three", vaultsandbox.Email{Subject: "Hi", Text: "Amazing deal!!!"}, true},
+		{"html run of three", vaultsandbox.Email{HTML: "<b>Wow!!!</b>"}, true},
+		{"single in subject", vaultsandbox.Email{Subject: "Welcome aboard!"}, false},
+		{"none", vaultsandbox.Email{Subject: "Invoice", Text: "Attached."}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ExcessiveExclamation(&tt.email))
+		})
+	}
+}
+
+func TestMismatchedSender(t *testing.T) {
+	tests := []struct {
+		from string
+		want bool
+	}{
+		{`"PayPal.com" <alerts@evil.example>`, true},
+		{`"support@bank.com" <x@phish.example>`, true},
+		{`"Example.com" <noreply@example.com>`, false},
+		{`"example.com" <noreply@mail.example.com>`, false},
+		{`"Example Support" <support@example.com>`, false},
+		{`noreply@example.com`, false},
+		{`not an address`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.from, func(t *testing.T) {
+			assert.Equal(t, tt.want, MismatchedSender(&vaultsandbox.Email{From: tt.from}))
+		})
+	}
+}
+
+func TestSuspiciousRedirect(t *testing.T) {
+	tests := []struct {
+		name  string
+		links []string
+		want  bool
+	}{
+		{"url shortener", []string{"https://bit.ly/abc"}, true},
+		{"ip address", []string{"http://192.0.2.10/login"}, true},
+		{"open redirect", []string{"https://track.example.com/c?url=https://evil.example/login"}, true},
+		{"redirect to same host", []string{"https://example.com/go?next=https://example.com/home"}, false},
+		{"plain links", []string{"https://example.com/verify?token=abc"}, false},
+		{"no links", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, SuspiciousRedirect(&vaultsandbox.Email{Links: tt.links}))
+		})
+	}
+}
+
+func TestMissingUnsubscribe(t *testing.T) {
+	tests := []struct {
+		name  string
+		email vaultsandbox.Email
+		want  bool
+	}{
+		{"list-unsubscribe header", vaultsandbox.Email{Headers: map[string]string{"list-unsubscribe": "<mailto:u@example.com>"}}, false},
+		{"unsubscribe link", vaultsandbox.Email{Links: []string{"https://example.com/unsubscribe?id=1"}}, false},
+		{"unsubscribe text", vaultsandbox.Email{Text: "To Unsubscribe reply STOP"}, false},
+		{"nothing", vaultsandbox.Email{Text: "Buy now", Links: []string{"https://example.com/buy"}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, MissingUnsubscribe(&tt.email))
+		})
+	}
+}
+
+func TestCheckSpam(t *testing.T) {
+	t.Run("clean email is low risk", func(t *testing.T) {
+		report := CheckSpam(&vaultsandbox.Email{
+			Subject: "Your receipt",
+			From:    `"Example" <billing@example.com>`,
+			Text:    "Thanks. Unsubscribe at any time.",
+		})
+		assert.Equal(t, RiskLow, report.RiskLevel)
+		assert.Empty(t, report.TriggeredRules)
+	})
+
+	t.Run("caps and mismatched sender is medium risk", func(t *testing.T) {
+		report := CheckSpam(&vaultsandbox.Email{
+			Subject: "ACCOUNT SUSPENDED",
+			From:    `"PayPal.com" <alerts@evil.example>`,
+			Text:    "Unsubscribe here.",
+		})
+		assert.Equal(t, RiskMedium, report.RiskLevel)
+		assert.Equal(t, []string{RuleAllCaps, RuleMismatchedSender}, report.TriggeredRules)
+	})
+
+	t.Run("many rules is high risk", func(t *testing.T) {
+		report := CheckSpam(&vaultsandbox.Email{
+			Subject: "CLAIM YOUR PRIZE!!",
+			From:    `"PayPal.com" <alerts@evil.example>`,
+			Links:   []string{"https://bit.ly/prize"},
+		})
+		assert.Equal(t, RiskHigh, report.RiskLevel)
+		assert.Len(t, report.TriggeredRules, 5)
+	})
+}
+
+func TestSuspiciousLinks(t *testing.T) {
+	email := &vaultsandbox.Email{Links: []string{
+		"https://example.com/welcome",
+		"https://bit.ly/abc",
+		"http://192.0.2.10/login",
+		"https://example.com/go?next=https://example.com/home",
+	}}
+	assert.Equal(t, []string{"https://bit.ly/abc", "http://192.0.2.10/login"}, SuspiciousLinks(email))
+	assert.Empty(t, SuspiciousLinks(&vaultsandbox.Email{}))
+}
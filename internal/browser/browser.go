@@ -10,6 +10,8 @@ import (
 	"runtime"
 	"strings"
 	"time"
+
+	"github.com/vaultsandbox/vsb-cli/internal/config"
 )
 
 // Allowed URL schemes for security
@@ -35,6 +37,14 @@ var createTempFile = os.CreateTemp
 // readDir is a variable for os.ReadDir that can be overridden in tests
 var readDir = os.ReadDir
 
+// getenv is a variable for os.Getenv that can be overridden in tests
+var getenv = os.Getenv
+
+// browserCommand returns the user-configured browser command (--browser,
+// VSB_BROWSER or the browser config key). It is a variable so tests can
+// override it.
+var browserCommand = config.GetBrowser
+
 // TempFile interface for testing file operations
 type TempFile interface {
 	Close() error
@@ -60,14 +70,14 @@ var createTempFileWrapper = func(dir, pattern string) (TempFile, error) {
 // previewFilePrefix is used to identify temp files created by this package
 const previewFilePrefix = "vsb-preview-"
 
-// OpenURL opens a URL in the default browser.
-// Only http, https, mailto, and file schemes are allowed.
+// OpenURL opens a URL in the configured browser, falling back to the
+// platform default. Only http, https, mailto, and file schemes are allowed.
 func OpenURL(rawURL string) error {
 	return openURLFunc(rawURL)
 }
 
-// openURLInternal is the actual implementation of OpenURL.
-func openURLInternal(rawURL string) error {
+// ValidateURL checks that a URL parses and uses an allowed scheme.
+func ValidateURL(rawURL string) error {
 	parsed, err := url.Parse(rawURL)
 	if err != nil {
 		return fmt.Errorf("invalid URL: %w", err)
@@ -77,6 +87,34 @@ func openURLInternal(rawURL string) error {
 	if !allowedSchemes[scheme] {
 		return fmt.Errorf("URL scheme %q not allowed", scheme)
 	}
+	return nil
+}
+
+// IsHeadless reports whether a graphical browser is unlikely to be available,
+// e.g. on Linux without a display server or in an SSH session.
+func IsHeadless() bool {
+	if goos == "linux" {
+		return getenv("DISPLAY") == "" && getenv("WAYLAND_DISPLAY") == ""
+	}
+	return getenv("SSH_CONNECTION") != "" || getenv("SSH_TTY") != ""
+}
+
+// openURLInternal is the actual implementation of OpenURL.
+func openURLInternal(rawURL string) error {
+	if err := ValidateURL(rawURL); err != nil {
+		return err
+	}
+
+	if custom := browserCommand(); custom != "" {
+		args, err := splitCommand(custom)
+		if err != nil {
+			return fmt.Errorf("invalid browser command: %w", err)
+		}
+		if len(args) > 0 {
+			args = browserArgs(args, rawURL)
+			return execCommand(args[0], args[1:]...).Start()
+		}
+	}
 
 	var cmd *exec.Cmd
 
@@ -94,6 +132,69 @@ func openURLInternal(rawURL string) error {
 	return cmd.Start()
 }
 
+// urlPlaceholder in a browser command is replaced with the URL
+const urlPlaceholder = "%u"
+
+// browserArgs puts the URL into a split browser command: every %u is
+// replaced with it, or, if there is none, it is appended as the last argument.
+// Substitution happens after splitting so a URL is always a single argument.
+func browserArgs(args []string, rawURL string) []string {
+	out := make([]string, len(args))
+	substituted := false
+	for i, arg := range args {
+		if strings.Contains(arg, urlPlaceholder) {
+			arg = strings.ReplaceAll(arg, urlPlaceholder, rawURL)
+			substituted = true
+		}
+		out[i] = arg
+	}
+	if !substituted {
+		out = append(out, rawURL)
+	}
+	return out
+}
+
+// splitCommand splits a command line into arguments on whitespace.
+// Single and double quotes group words containing spaces; no other shell
+// syntax is interpreted.
+func splitCommand(command string) ([]string, error) {
+	var args []string
+	var current strings.Builder
+	var quote rune
+	inArg := false
+
+	for _, r := range command {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				current.WriteRune(r)
+			}
+		case r == '"' || r == '\'':
+			quote = r
+			inArg = true
+		case r == ' ' || r == '\t':
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		default:
+			current.WriteRune(r)
+			inArg = true
+		}
+	}
+
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated quote in %q", command)
+	}
+	if inArg {
+		args = append(args, current.String())
+	}
+	return args, nil
+}
+
 // ViewHTML writes HTML to a temp file and opens it in the browser.
 // Uses secure temp file creation with restricted permissions.
 func ViewHTML(html string) error {
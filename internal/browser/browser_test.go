@@ -7,6 +7,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"runtime"
 	"testing"
 	"time"
 
@@ -473,6 +474,114 @@ func TestOpenURL_CommandConstruction(t *testing.T) {
 	})
 }
 
+func TestOpenURL_ConfiguredBrowser(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("mock browser command requires sh")
+	}
+
+	originalBrowserCommand := browserCommand
+	defer func() { browserCommand = originalBrowserCommand }()
+
+	// The mock browser writes its arguments to a file, one per line
+	outFile := filepath.Join(t.TempDir(), "url.txt")
+	browserCommand = func() string {
+		return `sh -c 'printf "%s\n" "$@" > "$0"' ` + outFile + ` --new-tab`
+	}
+
+	t.Run("URL passed as last argument", func(t *testing.T) {
+		testURL := "https://example.com/verify?token=abc&id=1"
+		require.NoError(t, openURLInternal(testURL))
+
+		assert.Eventually(t, func() bool {
+			data, err := os.ReadFile(outFile)
+			return err == nil && string(data) == "--new-tab\n"+testURL+"\n"
+		}, 5*time.Second, 20*time.Millisecond)
+	})
+
+	t.Run("URL substituted for placeholder", func(t *testing.T) {
+		browserCommand = func() string {
+			return `sh -c 'printf "%s\n" "$@" > "$0"' ` + outFile + ` --url=%u --new-tab`
+		}
+		testURL := "https://example.com/verify?token=abc&id=1"
+		require.NoError(t, openURLInternal(testURL))
+
+		assert.Eventually(t, func() bool {
+			data, err := os.ReadFile(outFile)
+			return err == nil && string(data) == "--url="+testURL+"\n--new-tab\n"
+		}, 5*time.Second, 20*time.Millisecond)
+	})
+
+	t.Run("blocked scheme never reaches command", func(t *testing.T) {
+		originalExecCommand := execCommand
+		defer func() { execCommand = originalExecCommand }()
+
+		called := false
+		execCommand = func(name string, args ...string) *exec.Cmd {
+			called = true
+			return exec.Command("true")
+		}
+
+		err := openURLInternal("javascript:alert(1)")
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "not allowed")
+		assert.False(t, called)
+	})
+
+	t.Run("invalid command", func(t *testing.T) {
+		browserCommand = func() string { return `firefox "--new-tab` }
+
+		err := openURLInternal("https://example.com")
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid browser command")
+	})
+}
+
+func TestBrowserArgs(t *testing.T) {
+	const u = "https://example.com/a b"
+	tests := []struct {
+		name string
+		args []string
+		want []string
+	}{
+		{"appended without placeholder", []string{"firefox", "--new-tab"}, []string{"firefox", "--new-tab", u}},
+		{"placeholder replaced", []string{"remote-open", "%u", "--wait"}, []string{"remote-open", u, "--wait"}},
+		{"placeholder inside argument", []string{"ssh", "laptop", "open '%u'"}, []string{"ssh", "laptop", "open '" + u + "'"}},
+		{"every placeholder replaced", []string{"cmd", "%u", "%u"}, []string{"cmd", u, u}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, browserArgs(tt.args, u))
+		})
+	}
+}
+
+func TestSplitCommand(t *testing.T) {
+	tests := []struct {
+		name    string
+		command string
+		want    []string
+	}{
+		{"single word", "firefox", []string{"firefox"}},
+		{"with flag", "firefox --new-tab", []string{"firefox", "--new-tab"}},
+		{"extra whitespace", "  firefox \t --new-tab  ", []string{"firefox", "--new-tab"}},
+		{"double quoted path", `"/Applications/My Browser.app/bin" -x`, []string{"/Applications/My Browser.app/bin", "-x"}},
+		{"single quoted argument", `sh -c 'echo "$1"'`, []string{"sh", "-c", `echo "$1"`}},
+		{"empty quotes", `cmd ""`, []string{"cmd", ""}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := splitCommand(tt.command)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	_, err := splitCommand(`firefox 'unterminated`)
+	assert.Error(t, err)
+}
+
 func TestOpenURL_Wrapper(t *testing.T) {
 	// Save original and restore after test
 	originalOpenURLFunc := openURLFunc
@@ -494,6 +603,37 @@ func TestOpenURL_Wrapper(t *testing.T) {
 	assert.Equal(t, testURL, capturedURL)
 }
 
+func TestIsHeadless(t *testing.T) {
+	originalGoos := goos
+	originalGetenv := getenv
+	defer func() {
+		goos = originalGoos
+		getenv = originalGetenv
+	}()
+
+	tests := []struct {
+		name string
+		goos string
+		env  map[string]string
+		want bool
+	}{
+		{"linux without display", "linux", nil, true},
+		{"linux with X11", "linux", map[string]string{"DISPLAY": ":0"}, false},
+		{"linux with wayland", "linux", map[string]string{"WAYLAND_DISPLAY": "wayland-0"}, false},
+		{"linux ssh with X forwarding", "linux", map[string]string{"DISPLAY": "localhost:10.0", "SSH_TTY": "/dev/pts/0"}, false},
+		{"darwin local", "darwin", nil, false},
+		{"darwin over ssh", "darwin", map[string]string{"SSH_CONNECTION": "10.0.0.1 22 10.0.0.2 22"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			goos = tt.goos
+			getenv = func(key string) string { return tt.env[key] }
+			assert.Equal(t, tt.want, IsHeadless())
+		})
+	}
+}
+
 // ============================================================================
 // ViewHTML Tests (with mocked OpenURL)
 // ============================================================================
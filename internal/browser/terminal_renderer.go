@@ -0,0 +1,66 @@
+package browser
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// ErrNoTerminalRenderer is returned when neither w3m nor lynx is installed.
+var ErrNoTerminalRenderer = errors.New("no terminal HTML renderer found (install w3m or lynx)")
+
+// terminalRenderer is a text-mode browser that can dump HTML read from stdin.
+type terminalRenderer struct {
+	name string
+	args []string
+}
+
+// terminalRenderers are tried in order of preference.
+var terminalRenderers = []terminalRenderer{
+	{"w3m", []string{"-dump", "-T", "text/html", "-I", "UTF-8", "-O", "UTF-8"}},
+	{"lynx", []string{"-dump", "-stdin", "-force_html", "-nolist", "-assume_charset=utf-8", "-display_charset=utf-8"}},
+}
+
+// lookPath is a variable for exec.LookPath that can be overridden in tests
+var lookPath = exec.LookPath
+
+// rendererOutput is where rendered text is written; overridden in tests
+var rendererOutput io.Writer = os.Stdout
+
+// findTerminalRenderer returns the path and arguments of the first
+// available renderer.
+func findTerminalRenderer() (string, []string, error) {
+	for _, r := range terminalRenderers {
+		if path, err := lookPath(r.name); err == nil {
+			return path, r.args, nil
+		}
+	}
+	return "", nil, ErrNoTerminalRenderer
+}
+
+// HasTerminalRenderer reports whether w3m or lynx is available.
+func HasTerminalRenderer() bool {
+	_, _, err := findTerminalRenderer()
+	return err == nil
+}
+
+// RenderHTMLInTerminal renders HTML as text to stdout using w3m, or lynx if
+// w3m is not installed. Returns ErrNoTerminalRenderer if neither is found.
+func RenderHTMLInTerminal(html string) error {
+	path, args, err := findTerminalRenderer()
+	if err != nil {
+		return err
+	}
+
+	cmd := execCommand(path, args...)
+	cmd.Stdin = strings.NewReader(html)
+	cmd.Stdout = rendererOutput
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to render HTML: %w", err)
+	}
+	return nil
+}
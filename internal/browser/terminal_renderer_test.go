@@ -0,0 +1,102 @@
+package browser
+
+import (
+	"bytes"
+	"errors"
+	"os/exec"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockRenderers makes lookPath find only the named renderers.
+func mockRenderers(t *testing.T, available ...string) {
+	originalLookPath := lookPath
+	t.Cleanup(func() { lookPath = originalLookPath })
+
+	lookPath = func(name string) (string, error) {
+		for _, a := range available {
+			if a == name {
+				return "/usr/bin/" + name, nil
+			}
+		}
+		return "", exec.ErrNotFound
+	}
+}
+
+func TestFindTerminalRenderer(t *testing.T) {
+	t.Run("prefers w3m", func(t *testing.T) {
+		mockRenderers(t, "lynx", "w3m")
+		path, args, err := findTerminalRenderer()
+		require.NoError(t, err)
+		assert.Equal(t, "/usr/bin/w3m", path)
+		assert.Contains(t, args, "-dump")
+	})
+
+	t.Run("falls back to lynx", func(t *testing.T) {
+		mockRenderers(t, "lynx")
+		path, args, err := findTerminalRenderer()
+		require.NoError(t, err)
+		assert.Equal(t, "/usr/bin/lynx", path)
+		assert.Contains(t, args, "-stdin")
+	})
+
+	t.Run("none available", func(t *testing.T) {
+		mockRenderers(t)
+		_, _, err := findTerminalRenderer()
+		assert.ErrorIs(t, err, ErrNoTerminalRenderer)
+		assert.False(t, HasTerminalRenderer())
+	})
+}
+
+func TestRenderHTMLInTerminal(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("mock renderer requires sh")
+	}
+
+	originalExecCommand := execCommand
+	originalOutput := rendererOutput
+	defer func() {
+		execCommand = originalExecCommand
+		rendererOutput = originalOutput
+	}()
+
+	var out bytes.Buffer
+	rendererOutput = &out
+
+	t.Run("pipes HTML to renderer", func(t *testing.T) {
+		mockRenderers(t, "w3m")
+		out.Reset()
+
+		var capturedName string
+		execCommand = func(name string, args ...string) *exec.Cmd {
+			capturedName = name
+			// Mock renderer strips tags from stdin
+			return exec.Command("sh", "-c", `sed -e 's/<[^>]*>//g'`)
+		}
+
+		err := RenderHTMLInTerminal("<html><body><p>Hello <b>World</b></p></body></html>")
+		require.NoError(t, err)
+		assert.Equal(t, "/usr/bin/w3m", capturedName)
+		assert.Equal(t, "Hello World", out.String())
+	})
+
+	t.Run("renderer failure", func(t *testing.T) {
+		mockRenderers(t, "lynx")
+		execCommand = func(name string, args ...string) *exec.Cmd {
+			return exec.Command("sh", "-c", "exit 2")
+		}
+
+		err := RenderHTMLInTerminal("<p>x</p>")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to render HTML")
+	})
+
+	t.Run("no renderer", func(t *testing.T) {
+		mockRenderers(t)
+		err := RenderHTMLInTerminal("<p>x</p>")
+		assert.True(t, errors.Is(err, ErrNoTerminalRenderer))
+	})
+}
@@ -0,0 +1,104 @@
+// Package ci reports command results to CI systems as step outputs and
+// workflow annotations.
+package ci
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/vaultsandbox/vsb-cli/internal/config"
+)
+
+// Reporter receives results and failures from a command. Implementations
+// exist per CI system; NopReporter is used when no integration is active.
+type Reporter interface {
+	// SetOutput records a named step output (e.g. email_id, link).
+	SetOutput(key, value string) error
+	// Error annotates the run with a failure.
+	Error(msg string)
+	// Warning annotates the run with a non-fatal problem.
+	Warning(msg string)
+}
+
+// getenv is a variable for os.Getenv that can be overridden in tests
+var getenv = os.Getenv
+
+// NewReporter returns the reporter for the current environment. GitHub
+// Actions reporting is used when requested by flag or enabled through the
+// ci-integration config key, and only if GITHUB_OUTPUT is set.
+func NewReporter(githubOutput bool) Reporter {
+	if !githubOutput && !config.GetCIIntegration() {
+		return NopReporter{}
+	}
+	path := getenv("GITHUB_OUTPUT")
+	if path == "" {
+		return NopReporter{}
+	}
+	return &GitHubReporter{OutputPath: path, Annotations: os.Stderr}
+}
+
+// NopReporter discards everything.
+type NopReporter struct{}
+
+func (NopReporter) SetOutput(key, value string) error { return nil }
+func (NopReporter) Error(msg string)                  {}
+func (NopReporter) Warning(msg string)                {}
+
+// GitHubReporter appends outputs to the $GITHUB_OUTPUT file and writes
+// ::error:: / ::warning:: workflow commands.
+type GitHubReporter struct {
+	OutputPath  string
+	Annotations io.Writer
+}
+
+// newDelimiter is a variable so tests can use a fixed heredoc delimiter
+var newDelimiter = func() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return "ghadelimiter_" + hex.EncodeToString(b)
+}
+
+// SetOutput appends key=value to the output file. Multi-line values use
+// the heredoc form GitHub requires.
+func (g *GitHubReporter) SetOutput(key, value string) error {
+	f, err := os.OpenFile(g.OutputPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open GITHUB_OUTPUT: %w", err)
+	}
+	defer f.Close()
+
+	var line string
+	if strings.ContainsAny(value, "\r\n") {
+		delim := newDelimiter()
+		line = fmt.Sprintf("%s<<%s\n%s\n%s\n", key, delim, value, delim)
+	} else {
+		line = fmt.Sprintf("%s=%s\n", key, value)
+	}
+
+	if _, err := f.WriteString(line); err != nil {
+		return fmt.Errorf("failed to write GITHUB_OUTPUT: %w", err)
+	}
+	return nil
+}
+
+// Error writes an ::error:: annotation.
+func (g *GitHubReporter) Error(msg string) {
+	fmt.Fprintf(g.Annotations, "::error::%s\n", escapeData(msg))
+}
+
+// Warning writes a ::warning:: annotation.
+func (g *GitHubReporter) Warning(msg string) {
+	fmt.Fprintf(g.Annotations, "::warning::%s\n", escapeData(msg))
+}
+
+// escapeData escapes a workflow command message so it stays on one line.
+func escapeData(s string) string {
+	s = strings.ReplaceAll(s, "%", "%25")
+	s = strings.ReplaceAll(s, "\r", "%0D")
+	s = strings.ReplaceAll(s, "\n", "%0A")
+	return s
+}
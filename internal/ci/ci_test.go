@@ -0,0 +1,78 @@
+package ci
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGitHubReporterSetOutput(t *testing.T) {
+	originalDelimiter := newDelimiter
+	defer func() { newDelimiter = originalDelimiter }()
+	newDelimiter = func() string { return "EOF_TEST" }
+
+	path := filepath.Join(t.TempDir(), "github_output")
+	require.NoError(t, os.WriteFile(path, []byte("existing=1\n"), 0600))
+
+	r := &GitHubReporter{OutputPath: path, Annotations: &bytes.Buffer{}}
+	require.NoError(t, r.SetOutput("email_id", "abc123"))
+	require.NoError(t, r.SetOutput("link", "https://example.com/verify?token=a=b"))
+	require.NoError(t, r.SetOutput("subject", "line one\nline two"))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "existing=1\n"+
+		"email_id=abc123\n"+
+		"link=https://example.com/verify?token=a=b\n"+
+		"subject<<EOF_TEST\nline one\nline two\nEOF_TEST\n", string(data))
+}
+
+func TestGitHubReporterAnnotations(t *testing.T) {
+	var buf bytes.Buffer
+	r := &GitHubReporter{OutputPath: filepath.Join(t.TempDir(), "out"), Annotations: &buf}
+
+	r.Error("timeout waiting for email")
+	r.Warning("100% done\nnext line")
+
+	assert.Equal(t, "::error::timeout waiting for email\n::warning::100%25 done%0Anext line\n", buf.String())
+}
+
+func TestNewReporter(t *testing.T) {
+	originalGetenv := getenv
+	defer func() { getenv = originalGetenv }()
+	t.Setenv("VSB_CI_INTEGRATION", "")
+
+	path := filepath.Join(t.TempDir(), "github_output")
+	env := map[string]string{}
+	getenv = func(key string) string { return env[key] }
+
+	t.Run("disabled without flag or config", func(t *testing.T) {
+		env["GITHUB_OUTPUT"] = path
+		assert.IsType(t, NopReporter{}, NewReporter(false))
+	})
+
+	t.Run("flag without GITHUB_OUTPUT writes nothing", func(t *testing.T) {
+		delete(env, "GITHUB_OUTPUT")
+		r := NewReporter(true)
+		assert.IsType(t, NopReporter{}, r)
+		require.NoError(t, r.SetOutput("email_id", "abc"))
+		assert.NoFileExists(t, path)
+	})
+
+	t.Run("flag with GITHUB_OUTPUT", func(t *testing.T) {
+		env["GITHUB_OUTPUT"] = path
+		r := NewReporter(true)
+		require.IsType(t, &GitHubReporter{}, r)
+		assert.Equal(t, path, r.(*GitHubReporter).OutputPath)
+	})
+
+	t.Run("enabled by config", func(t *testing.T) {
+		t.Setenv("VSB_CI_INTEGRATION", "true")
+		env["GITHUB_OUTPUT"] = path
+		assert.IsType(t, &GitHubReporter{}, NewReporter(false))
+	})
+}
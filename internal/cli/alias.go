@@ -0,0 +1,322 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/vaultsandbox/vsb-cli/internal/cliutil"
+	"github.com/vaultsandbox/vsb-cli/internal/config"
+	"github.com/vaultsandbox/vsb-cli/internal/styles"
+)
+
+var aliasCmd = &cobra.Command{
+	Use:   "alias",
+	Short: "List command shortcuts and aliases",
+	Long: `Show the shorter names commands can be run by.
+
+vsb has top-level shortcuts for the most used commands (vsb ls runs
+vsb email list), and many subcommands have aliases of their own (vsb inbox
+ls). Define your own in the config file with:
+
+  vsb config set alias.links "email url"
+
+after which 'vsb links' runs 'vsb email url'. Any arguments and flags after
+an alias are passed on to the command it runs. An alias may use another
+alias, but not itself, and may not reuse the name of a command or shortcut.`,
+	RunE: runAlias,
+}
+
+var aliasListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "List shortcuts, command aliases and user-defined aliases",
+	Long: `List every name a command can be run by: the built-in top-level
+shortcuts, the aliases of subcommands and the aliases defined in the config
+file. User-defined aliases that clash with a command are marked, as running
+them fails.
+
+Examples:
+  vsb alias list
+  vsb alias list -o json`,
+	Args: cobra.NoArgs,
+	RunE: runAliasList,
+}
+
+func init() {
+	aliasCmd.AddCommand(aliasListCmd)
+	cliutil.AddOutputFormats(aliasListCmd, cliutil.FormatJSON)
+}
+
+func runAlias(cmd *cobra.Command, args []string) error {
+	if len(args) > 0 {
+		return fmt.Errorf("unknown command %q for %q", args[0], cmd.CommandPath())
+	}
+	return cmd.Help()
+}
+
+// shortcut is a built-in top-level name for a longer command line
+type shortcut struct {
+	Name      string
+	Expansion string
+}
+
+// shortcuts are expanded before the command line is parsed, like
+// user-defined aliases
+var shortcuts = []shortcut{
+	{"ls", "email list"},
+	{"new", "inbox create"},
+	{"rm", "email delete"},
+	{"open", "email url --open 1"},
+}
+
+func lookupShortcut(name string) (string, bool) {
+	for _, s := range shortcuts {
+		if s.Name == name {
+			return s.Expansion, true
+		}
+	}
+	return "", false
+}
+
+// reservedNames are commands cobra adds to the root command when it runs
+var reservedNames = []string{"help", "completion", cobra.ShellCompRequestCmd, cobra.ShellCompNoDescRequestCmd}
+
+// topLevelCommand returns the name of the top-level command run by name,
+// or "" if there is none.
+func topLevelCommand(root *cobra.Command, name string) string {
+	for _, c := range root.Commands() {
+		if c.Name() == name || c.HasAlias(name) {
+			return c.Name()
+		}
+	}
+	for _, reserved := range reservedNames {
+		if name == reserved {
+			return name
+		}
+	}
+	return ""
+}
+
+// shadowedCommand describes the top-level command or shortcut that name
+// would hide, or returns "" if none.
+func shadowedCommand(root *cobra.Command, name string) string {
+	if command := topLevelCommand(root, name); command != "" {
+		return "the command 'vsb " + command + "'"
+	}
+	if _, ok := lookupShortcut(name); ok {
+		return "the shortcut 'vsb " + name + "'"
+	}
+	return ""
+}
+
+// expandAliases rewrites the command name in args when it is a user alias
+// or a built-in shortcut, repeating while the result is another alias. The
+// arguments after the name are kept. The command lines of shell completion
+// requests and 'vsb help' are expanded too, so both work with an alias.
+func expandAliases(root *cobra.Command, args []string, aliases map[string]string) ([]string, error) {
+	i := commandIndex(root, args)
+	if i < 0 {
+		return args, nil
+	}
+	if args[i] == cobra.ShellCompRequestCmd || args[i] == cobra.ShellCompNoDescRequestCmd || args[i] == "help" {
+		rest, err := expandAliases(root, args[i+1:], aliases)
+		if err != nil {
+			return nil, err
+		}
+		if args[i] == "help" {
+			// help only takes the command path, not the flags of a shortcut
+			for j, word := range rest {
+				if strings.HasPrefix(word, "-") {
+					rest = rest[:j]
+					break
+				}
+			}
+		}
+		return append(append([]string(nil), args[:i+1]...), rest...), nil
+	}
+
+	var chain []string
+	for {
+		name := args[i]
+		// Commands an alias expands to run as they are, even if a broken
+		// alias reuses their name
+		if len(chain) > 0 && topLevelCommand(root, name) != "" {
+			return args, nil
+		}
+		expansion, ok := aliases[name]
+		if ok {
+			if shadowed := shadowedCommand(root, name); shadowed != "" {
+				return nil, fmt.Errorf("alias %q shadows %s; rename or remove it with 'vsb config set alias.%s \"\"'", name, shadowed, name)
+			}
+		} else if expansion, ok = lookupShortcut(name); !ok {
+			return args, nil
+		}
+
+		for _, seen := range chain {
+			if seen == name {
+				return nil, fmt.Errorf("alias %q expands to itself: %s -> %s", chain[0], strings.Join(chain, " -> "), name)
+			}
+		}
+		chain = append(chain, name)
+
+		words := strings.Fields(expansion)
+		if len(words) == 0 {
+			return nil, fmt.Errorf("alias %q expands to nothing", name)
+		}
+		expanded := append(append([]string(nil), args[:i]...), words...)
+		args = append(expanded, args[i+1:]...)
+	}
+}
+
+// commandIndex returns the index of the command name in args, skipping the
+// root command's flags and their values, or -1 if there is none.
+func commandIndex(root *cobra.Command, args []string) int {
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			return -1
+		}
+		if !strings.HasPrefix(arg, "-") || arg == "-" {
+			return i
+		}
+		if strings.Contains(arg, "=") {
+			continue
+		}
+		name := strings.TrimLeft(arg, "-")
+		flag := root.PersistentFlags().Lookup(name)
+		if flag == nil {
+			flag = root.Flags().Lookup(name)
+		}
+		if flag == nil && len(name) == 1 && !strings.HasPrefix(arg, "--") {
+			flag = root.PersistentFlags().ShorthandLookup(name)
+		}
+		if flag != nil && flag.NoOptDefVal == "" {
+			i++ // skip the flag's value
+		}
+	}
+	return -1
+}
+
+// flagValue returns the value of the long flag name in args, before any
+// "--". It is for the few flags needed before the command line is parsed.
+func flagValue(args []string, name string) string {
+	for i, arg := range args {
+		switch {
+		case arg == "--":
+			return ""
+		case strings.HasPrefix(arg, "--"+name+"="):
+			return strings.TrimPrefix(arg, "--"+name+"=")
+		case arg == "--"+name && i+1 < len(args):
+			return args[i+1]
+		}
+	}
+	return ""
+}
+
+// commandShortcuts lists the shortcuts and user aliases that run cmd, for
+// its help.
+func commandShortcuts(cmd *cobra.Command) string {
+	root := cmd.Root()
+	if cmd == root {
+		return ""
+	}
+	runs := func(expansion string) bool {
+		found, _, err := root.Find(strings.Fields(expansion))
+		return err == nil && found == cmd
+	}
+
+	var names []string
+	for _, s := range shortcuts {
+		if runs(s.Expansion) {
+			names = append(names, "vsb "+s.Name)
+		}
+	}
+	aliases := config.GetAliases()
+	for _, name := range sortedKeys(aliases) {
+		if runs(aliases[name]) && shadowedCommand(root, name) == "" {
+			names = append(names, "vsb "+name)
+		}
+	}
+	return strings.Join(names, ", ")
+}
+
+// withShortcuts adds a Shortcuts section after the Aliases section of a
+// cobra usage template
+func withShortcuts(template string) string {
+	const aliases = "{{.NameAndAliases}}{{end}}"
+	return strings.Replace(template, aliases, aliases+`{{with shortcuts .}}
+
+Shortcuts:
+  {{.}}{{end}}`, 1)
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// aliasEntry is one row of 'alias list'
+type aliasEntry struct {
+	Name    string `json:"name"`
+	Runs    string `json:"runs"`
+	Type    string `json:"type"` // shortcut, command or user
+	Problem string `json:"problem,omitempty"`
+}
+
+// listAliases collects the shortcuts, the aliases of every command under
+// root and the user-defined aliases
+func listAliases(root *cobra.Command, user map[string]string) []aliasEntry {
+	var entries []aliasEntry
+	for _, s := range shortcuts {
+		entries = append(entries, aliasEntry{Name: "vsb " + s.Name, Runs: "vsb " + s.Expansion, Type: "shortcut"})
+	}
+
+	var walk func(c *cobra.Command)
+	walk = func(c *cobra.Command) {
+		for _, sub := range c.Commands() {
+			if sub.Hidden {
+				continue
+			}
+			parent := strings.TrimSuffix(sub.CommandPath(), sub.Name())
+			for _, alias := range sub.Aliases {
+				entries = append(entries, aliasEntry{Name: parent + alias, Runs: sub.CommandPath(), Type: "command"})
+			}
+			walk(sub)
+		}
+	}
+	walk(root)
+
+	for _, name := range sortedKeys(user) {
+		e := aliasEntry{Name: "vsb " + name, Runs: "vsb " + strings.Join(strings.Fields(user[name]), " "), Type: "user"}
+		if shadowed := shadowedCommand(root, name); shadowed != "" {
+			e.Problem = "shadows " + shadowed
+		}
+		entries = append(entries, e)
+	}
+	return entries
+}
+
+func runAliasList(cmd *cobra.Command, args []string) error {
+	entries := listAliases(cmd.Root(), config.GetAliases())
+
+	if cliutil.GetOutput(cmd) == cliutil.FormatJSON {
+		return cliutil.OutputJSON(entries)
+	}
+
+	t := cliutil.NewTableWriter(os.Stdout, "ALIAS", "RUNS", "TYPE")
+	for _, e := range entries {
+		if e.Problem != "" {
+			t.AddStyledRow(styles.FailStyle, e.Name, e.Runs, e.Type+": "+e.Problem)
+			continue
+		}
+		t.AddRow(e.Name, e.Runs, e.Type)
+	}
+	return t.Render()
+}
@@ -0,0 +1,101 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpandAliases(t *testing.T) {
+	aliases := map[string]string{
+		"li":    "inbox list",
+		"l":     "li --all",
+		"co":    "email audit",
+		"loop1": "loop2",
+		"loop2": "loop1",
+		"self":  "self --x",
+		"inbox": "email list",
+	}
+
+	tests := []struct {
+		name string
+		args []string
+		want []string
+	}{
+		{"no command", nil, nil},
+		{"real command", []string{"email", "list"}, []string{"email", "list"}},
+		{"shortcut", []string{"ls", "--since", "2h"}, []string{"email", "list", "--since", "2h"}},
+		{"shortcut with flags", []string{"open", "abc123"}, []string{"email", "url", "--open", "1", "abc123"}},
+		{"user alias", []string{"co", "-o", "json"}, []string{"email", "audit", "-o", "json"}},
+		{"alias of an alias", []string{"l"}, []string{"inbox", "list", "--all"}},
+		{"after global flags", []string{"-o", "json", "--verbose", "ls"}, []string{"-o", "json", "--verbose", "email", "list"}},
+		{"after a flag with =", []string{"--output=json", "ls"}, []string{"--output=json", "email", "list"}},
+		{"after --", []string{"--", "ls"}, []string{"--", "ls"}},
+		{"only the command name", []string{"email", "ls"}, []string{"email", "ls"}},
+		{"completion", []string{"__complete", "ls", ""}, []string{"__complete", "email", "list", ""}},
+		{"help", []string{"help", "open"}, []string{"help", "email", "url"}},
+		{"expansion reaching a shadowed command", []string{"li"}, []string{"inbox", "list"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := expandAliases(rootCmd, tt.args, aliases)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	t.Run("recursion", func(t *testing.T) {
+		_, err := expandAliases(rootCmd, []string{"loop1"}, aliases)
+		assert.EqualError(t, err, `alias "loop1" expands to itself: loop1 -> loop2 -> loop1`)
+
+		_, err = expandAliases(rootCmd, []string{"self"}, aliases)
+		assert.EqualError(t, err, `alias "self" expands to itself: self -> self`)
+	})
+
+	t.Run("alias shadowing a command", func(t *testing.T) {
+		_, err := expandAliases(rootCmd, []string{"inbox", "list"}, aliases)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `alias "inbox" shadows the command 'vsb inbox'`)
+	})
+}
+
+func TestShadowedCommand(t *testing.T) {
+	assert.Equal(t, "the command 'vsb email'", shadowedCommand(rootCmd, "email"))
+	assert.Equal(t, "the command 'vsb help'", shadowedCommand(rootCmd, "help"))
+	assert.Equal(t, "the shortcut 'vsb ls'", shadowedCommand(rootCmd, "ls"))
+	assert.Empty(t, shadowedCommand(rootCmd, "co"))
+
+	// Shortcuts must not hide real commands
+	for _, s := range shortcuts {
+		assert.Empty(t, topLevelCommand(rootCmd, s.Name), s.Name)
+	}
+}
+
+func TestCommandShortcuts(t *testing.T) {
+	find := func(args ...string) *cobra.Command {
+		cmd, _, err := rootCmd.Find(args)
+		require.NoError(t, err)
+		return cmd
+	}
+
+	assert.Equal(t, "vsb ls", commandShortcuts(find("email", "list")))
+	assert.Equal(t, "vsb open", commandShortcuts(find("email", "url")))
+	assert.Empty(t, commandShortcuts(find("email")))
+	assert.Empty(t, commandShortcuts(rootCmd))
+}
+
+func TestListAliases(t *testing.T) {
+	entries := listAliases(rootCmd, map[string]string{"co": "email  audit", "inbox": "email list"})
+
+	byName := make(map[string]aliasEntry)
+	for _, e := range entries {
+		byName[e.Name] = e
+	}
+	assert.Equal(t, aliasEntry{Name: "vsb ls", Runs: "vsb email list", Type: "shortcut"}, byName["vsb ls"])
+	assert.Equal(t, aliasEntry{Name: "vsb inbox ls", Runs: "vsb inbox list", Type: "command"}, byName["vsb inbox ls"])
+	assert.Equal(t, aliasEntry{Name: "vsb email v", Runs: "vsb email view", Type: "command"}, byName["vsb email v"])
+	assert.Equal(t, aliasEntry{Name: "vsb co", Runs: "vsb email audit", Type: "user"}, byName["vsb co"])
+	assert.Equal(t, "shadows the command 'vsb inbox'", byName["vsb inbox"].Problem)
+}
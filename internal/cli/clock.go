@@ -0,0 +1,33 @@
+package cli
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/vaultsandbox/vsb-cli/internal/cliutil"
+	"github.com/vaultsandbox/vsb-cli/internal/config"
+)
+
+// describeClockSkew describes a skew measured by config.ClockSkew, e.g.
+// "the server clock is 10m0s behind this machine"
+func describeClockSkew(skew time.Duration) string {
+	switch {
+	case skew > 0:
+		return fmt.Sprintf("the server clock is %s ahead of this machine", skew.Round(time.Second))
+	case skew < 0:
+		return fmt.Sprintf("the server clock is %s behind this machine", (-skew).Round(time.Second))
+	}
+	return "the server clock agrees with this machine"
+}
+
+// warnClockSkew reports with --verbose that the local clock is off by more
+// than config.ClockSkewWarnThreshold
+func warnClockSkew() {
+	if !verbose {
+		return
+	}
+	skew, known := config.ClockSkew()
+	if known && (skew >= config.ClockSkewWarnThreshold || skew <= -config.ClockSkewWarnThreshold) {
+		cliutil.Verbosef("warning: %s; expiry times are corrected for it", describeClockSkew(skew))
+	}
+}
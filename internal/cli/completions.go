@@ -0,0 +1,267 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/vaultsandbox/vsb-cli/internal/cliutil"
+	"github.com/vaultsandbox/vsb-cli/internal/styles"
+)
+
+var completionsCmd = &cobra.Command{
+	Use:   "completions",
+	Short: "Install shell completion",
+	Long: `Install and check shell completion for vsb.
+
+To print a completion script instead, use 'vsb completion <shell>'.`,
+}
+
+var completionsInstallCmd = &cobra.Command{
+	Use:   "install",
+	Short: "Write the completion script for your shell",
+	Long: `Detect the current shell from $SHELL, generate its completion script and
+write it to the usual per-user location:
+
+  bash        ~/.bash_completion.d/vsb
+  zsh         ~/.zfunc/_vsb
+  fish        ~/.config/fish/completions/vsb.fish
+  powershell  ~/.config/powershell/vsb.ps1
+
+An existing script is overwritten, so re-run this after upgrading vsb. If
+the shell doesn't load the location on its own, the line to add to your
+shell profile is printed.
+
+Examples:
+  vsb completions install
+  vsb completions install --shell zsh
+  vsb completions install --dry-run`,
+	Args: cobra.NoArgs,
+	RunE: runCompletionsInstall,
+}
+
+var completionsCheckCmd = &cobra.Command{
+	Use:   "check",
+	Short: "Check that shell completion is installed and current",
+	Long: `Check that the completion script for the current shell (or --shell) is
+installed and matches this version of vsb. Exits non-zero if it is missing
+or outdated.
+
+Examples:
+  vsb completions check
+  vsb completions check --shell fish -o json`,
+	Args: cobra.NoArgs,
+	RunE: runCompletionsCheck,
+}
+
+var (
+	completionsShell  string
+	completionsDryRun bool
+)
+
+// Shells supported by completions install.
+const (
+	shellBash       = "bash"
+	shellZsh        = "zsh"
+	shellFish       = "fish"
+	shellPowerShell = "powershell"
+)
+
+func init() {
+	rootCmd.AddCommand(completionsCmd)
+	completionsCmd.AddCommand(completionsInstallCmd)
+	completionsCmd.AddCommand(completionsCheckCmd)
+	cliutil.AddOutputFormats(completionsInstallCmd, cliutil.FormatJSON)
+	cliutil.AddOutputFormats(completionsCheckCmd, cliutil.FormatJSON)
+
+	for _, c := range []*cobra.Command{completionsInstallCmd, completionsCheckCmd} {
+		c.Flags().StringVar(&completionsShell, "shell", "",
+			"Shell to use: bash, zsh, fish, powershell (default: detected from $SHELL)")
+	}
+	completionsInstallCmd.Flags().BoolVar(&completionsDryRun, "dry-run", false,
+		"Print where the script would be written without writing it")
+}
+
+func runCompletionsInstall(cmd *cobra.Command, args []string) error {
+	shell, path, err := completionsTarget()
+	if err != nil {
+		return err
+	}
+
+	if completionsDryRun {
+		fmt.Printf("Would write %s completion to %s\n", shell, path)
+		if hint := completionsHint(shell, path); hint != "" {
+			fmt.Println()
+			fmt.Println(hint)
+		}
+		return nil
+	}
+
+	script, err := completionScript(cmd.Root(), shell)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+	}
+	if err := os.WriteFile(path, script, 0644); err != nil {
+		return fmt.Errorf("failed to write completion script: %w", err)
+	}
+
+	if cliutil.GetOutput(cmd) == cliutil.FormatJSON {
+		return cliutil.OutputJSON(map[string]interface{}{
+			"shell": shell,
+			"path":  path,
+		})
+	}
+
+	fmt.Println(styles.PassStyle.Render(fmt.Sprintf("✓ Installed %s completion to %s", shell, path)))
+	if hint := completionsHint(shell, path); hint != "" {
+		fmt.Println()
+		fmt.Println(hint)
+	} else {
+		fmt.Println(styles.MutedStyle.Render("  Open a new shell to use it."))
+	}
+	return nil
+}
+
+func runCompletionsCheck(cmd *cobra.Command, args []string) error {
+	shell, path, err := completionsTarget()
+	if err != nil {
+		return err
+	}
+	script, err := completionScript(cmd.Root(), shell)
+	if err != nil {
+		return err
+	}
+
+	installed, current := false, false
+	if existing, err := os.ReadFile(path); err == nil {
+		installed = true
+		current = bytes.Equal(existing, script)
+	}
+
+	if cliutil.GetOutput(cmd) == cliutil.FormatJSON {
+		if err := cliutil.OutputJSON(map[string]interface{}{
+			"shell":     shell,
+			"path":      path,
+			"installed": installed,
+			"current":   current,
+		}); err != nil {
+			return err
+		}
+	} else if current {
+		fmt.Println(styles.PassStyle.Render(fmt.Sprintf("✓ %s completion is installed at %s", shell, path)))
+	}
+
+	switch {
+	case !installed:
+		return fmt.Errorf("%s completion is not installed at %s (run 'vsb completions install')", shell, path)
+	case !current:
+		return fmt.Errorf("%s completion at %s is outdated (run 'vsb completions install')", shell, path)
+	}
+	return nil
+}
+
+// completionsTarget resolves the shell from --shell or the environment and
+// the file its completion script belongs in.
+func completionsTarget() (shell, path string, err error) {
+	shell = strings.ToLower(completionsShell)
+	if shell == "" {
+		shell, err = detectShell(os.Getenv("SHELL"), runtime.GOOS)
+		if err != nil {
+			return "", "", err
+		}
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", "", fmt.Errorf("failed to find home directory: %w", err)
+	}
+	path, err = completionPath(shell, home, os.Getenv("XDG_CONFIG_HOME"))
+	if err != nil {
+		return "", "", err
+	}
+	return shell, path, nil
+}
+
+// detectShell maps a $SHELL value such as /usr/bin/zsh to a supported shell.
+// Windows rarely sets $SHELL, so PowerShell is assumed there.
+func detectShell(shellEnv, goos string) (string, error) {
+	if shellEnv == "" {
+		if goos == "windows" {
+			return shellPowerShell, nil
+		}
+		return "", fmt.Errorf("could not detect shell: $SHELL is not set (use --shell)")
+	}
+
+	// $SHELL may be a Windows path even when built for another OS (e.g. WSL)
+	name := shellEnv[strings.LastIndexAny(shellEnv, `/\`)+1:]
+	name = strings.TrimSuffix(strings.ToLower(name), ".exe")
+	switch name {
+	case "bash", "zsh", "fish":
+		return name, nil
+	case "pwsh", "powershell":
+		return shellPowerShell, nil
+	}
+	return "", fmt.Errorf("unsupported shell %q (use --shell bash, zsh, fish or powershell)", name)
+}
+
+// completionPath returns the per-user completion file for a shell. An empty
+// xdgConfigHome means ~/.config.
+func completionPath(shell, home, xdgConfigHome string) (string, error) {
+	if xdgConfigHome == "" {
+		xdgConfigHome = filepath.Join(home, ".config")
+	}
+	switch shell {
+	case shellBash:
+		return filepath.Join(home, ".bash_completion.d", "vsb"), nil
+	case shellZsh:
+		return filepath.Join(home, ".zfunc", "_vsb"), nil
+	case shellFish:
+		return filepath.Join(xdgConfigHome, "fish", "completions", "vsb.fish"), nil
+	case shellPowerShell:
+		return filepath.Join(xdgConfigHome, "powershell", "vsb.ps1"), nil
+	}
+	return "", fmt.Errorf("unsupported shell %q (use bash, zsh, fish or powershell)", shell)
+}
+
+// completionScript generates the completion script for a shell.
+func completionScript(root *cobra.Command, shell string) ([]byte, error) {
+	var buf bytes.Buffer
+	var err error
+	switch shell {
+	case shellBash:
+		err = root.GenBashCompletionV2(&buf, true)
+	case shellZsh:
+		err = root.GenZshCompletion(&buf)
+	case shellFish:
+		err = root.GenFishCompletion(&buf, true)
+	case shellPowerShell:
+		err = root.GenPowerShellCompletionWithDesc(&buf)
+	default:
+		return nil, fmt.Errorf("unsupported shell %q (use bash, zsh, fish or powershell)", shell)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate %s completion: %w", shell, err)
+	}
+	return buf.Bytes(), nil
+}
+
+// completionsHint explains how to make the shell load the script, or returns
+// "" when the shell loads it on its own.
+func completionsHint(shell, path string) string {
+	switch shell {
+	case shellBash:
+		return fmt.Sprintf("Add this to ~/.bashrc, then restart your shell:\n  for f in %s/*; do source \"$f\"; done", filepath.Dir(path))
+	case shellZsh:
+		return fmt.Sprintf("Add this to ~/.zshrc before compinit runs, then restart your shell:\n  fpath=(%s $fpath)\n  autoload -U compinit && compinit", filepath.Dir(path))
+	case shellPowerShell:
+		return fmt.Sprintf("Add this to your PowerShell profile ($PROFILE), then restart your shell:\n  . %s", path)
+	}
+	return ""
+}
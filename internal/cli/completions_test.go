@@ -0,0 +1,102 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectShell(t *testing.T) {
+	tests := []struct {
+		shellEnv string
+		goos     string
+		want     string
+		wantErr  string
+	}{
+		{"/bin/bash", "linux", "bash", ""},
+		{"/usr/local/bin/zsh", "darwin", "zsh", ""},
+		{"/opt/homebrew/bin/fish", "darwin", "fish", ""},
+		{"/usr/bin/pwsh", "linux", "powershell", ""},
+		{`C:\Program Files\PowerShell\7\pwsh.exe`, "windows", "powershell", ""},
+		{"", "windows", "powershell", ""},
+		{"", "linux", "", "$SHELL is not set"},
+		{"/bin/tcsh", "linux", "", `unsupported shell "tcsh"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.shellEnv+"/"+tt.goos, func(t *testing.T) {
+			got, err := detectShell(tt.shellEnv, tt.goos)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestCompletionPath(t *testing.T) {
+	home := filepath.Join("/home", "dev")
+	tests := []struct {
+		shell string
+		xdg   string
+		want  string
+	}{
+		{"bash", "", filepath.Join(home, ".bash_completion.d", "vsb")},
+		{"zsh", "", filepath.Join(home, ".zfunc", "_vsb")},
+		{"fish", "", filepath.Join(home, ".config", "fish", "completions", "vsb.fish")},
+		{"fish", "/xdg", filepath.Join("/xdg", "fish", "completions", "vsb.fish")},
+		{"powershell", "", filepath.Join(home, ".config", "powershell", "vsb.ps1")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.shell+tt.xdg, func(t *testing.T) {
+			got, err := completionPath(tt.shell, home, tt.xdg)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	_, err := completionPath("tcsh", home, "")
+	assert.Error(t, err)
+}
+
+func TestCompletionScript(t *testing.T) {
+	for _, shell := range []string{shellBash, shellZsh, shellFish, shellPowerShell} {
+		t.Run(shell, func(t *testing.T) {
+			script, err := completionScript(rootCmd, shell)
+			require.NoError(t, err)
+			assert.Contains(t, string(script), "vsb")
+		})
+	}
+}
+
+func TestCompletionsInstallAndCheck(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", "")
+
+	originalShell := completionsShell
+	t.Cleanup(func() { completionsShell = originalShell })
+	completionsShell = "zsh"
+
+	path := filepath.Join(home, ".zfunc", "_vsb")
+
+	err := runCompletionsCheck(completionsCheckCmd, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not installed")
+
+	require.NoError(t, runCompletionsInstall(completionsInstallCmd, nil))
+	assert.FileExists(t, path)
+	require.NoError(t, runCompletionsCheck(completionsCheckCmd, nil))
+
+	require.NoError(t, os.WriteFile(path, []byte("# old"), 0644))
+	err = runCompletionsCheck(completionsCheckCmd, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "outdated")
+}
@@ -2,14 +2,18 @@ package cli
 
 import (
 	"bufio"
-	"encoding/json"
 	"fmt"
+	"net/url"
 	"os"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/vaultsandbox/vsb-cli/internal/cliutil"
 	"github.com/vaultsandbox/vsb-cli/internal/config"
+	"github.com/vaultsandbox/vsb-cli/internal/styles"
+	"github.com/vaultsandbox/vsb-cli/internal/timeparse"
 )
 
 var configCmd = &cobra.Command{
@@ -19,18 +23,51 @@ var configCmd = &cobra.Command{
 
 Running 'vsb config' without subcommands starts interactive configuration.
 
+Flag defaults per command can be set in the defaults section of the config
+file, e.g. defaults.email.wait.timeout: 60s; flags given on the command
+line still win, and 'vsb <command> --help' marks them "(from config)".
+
 Examples:
   vsb config                    # Interactive configuration
   vsb config show               # Show current configuration
+  vsb config get base-url       # Print a single resolved value
   vsb config set api-key <key>  # Set API key
-  vsb config set base-url <url> # Set base URL`,
+  vsb config set base-url <url> # Set base URL
+  vsb config migrate            # Upgrade config and keystore files`,
 	RunE: runConfigInteractive,
 }
 
 var configShowCmd = &cobra.Command{
 	Use:   "show",
 	Short: "Show current configuration",
-	RunE:  runConfigShow,
+	Long: `Show the configuration stored in the config file.
+
+The API key is masked. Use --reveal to print it in full, for example to copy
+it into another tool on a trusted machine.
+
+Examples:
+  vsb config show
+  vsb config show --reveal
+  vsb config show -o json`,
+	RunE: runConfigShow,
+}
+
+var configGetCmd = &cobra.Command{
+	Use:   "get <key>",
+	Short: "Print a single resolved configuration value",
+	Long: `Print the effective value of a configuration key.
+
+The value is resolved the same way commands resolve it: environment variable
+(VSB_<KEY>), then config file, then the built-in default. Only the value is
+printed, so the output can be used directly in scripts. The API key is masked
+unless --reveal is given.
+
+Examples:
+  vsb config get base-url
+  vsb config get strategy
+  vsb config get api-key --reveal`,
+	Args: cobra.ExactArgs(1),
+	RunE: runConfigGet,
 }
 
 var configSetCmd = &cobra.Command{
@@ -39,15 +76,65 @@ var configSetCmd = &cobra.Command{
 	Long: `Set a configuration value.
 
 Available keys:
-  api-key   - Your VaultSandbox API key
-  base-url  - API server URL (default: https://api.vaultsandbox.com)
-  strategy  - Delivery strategy: sse or polling (default: sse)
+  api-key         - Your VaultSandbox API key (--keychain stores it in
+                    the system keychain instead of config.yaml)
+  api-key-command - Command whose output is the API key, e.g. a secret
+                    manager CLI; used instead of api-key (default: none)
+  base-url        - API server URL (default: https://api.vaultsandbox.com)
+  strategy        - Delivery strategy: sse or polling (default: sse)
+  poll-interval   - Fixed polling interval with the polling strategy,
+                    at least 500ms (default: 2s, backing off to 30s)
+  browser         - Command used to open URLs; %u is replaced with the
+                    URL, otherwise it is appended as the last argument
+                    (default: platform default)
+  ci-integration  - Write GitHub Actions outputs whenever GITHUB_OUTPUT
+                    is set: true or false (default: false)
+  html-renderer   - How 'email view' shows HTML: browser or terminal
+                    (w3m/lynx) (default: browser)
+  default-ttl     - Lifetime of inboxes created without --ttl, e.g. 1h
+                    or 7d (default: 24h)
+  default-label-prefix
+                  - Label new inboxes created without --label-prefix as
+                    <prefix><local part> (default: no label)
+  proxy           - Route all HTTP requests through an http://, https://
+                    or socks5:// proxy (default: HTTPS_PROXY/HTTP_PROXY)
+  no-proxy        - Comma-separated hosts, domains, IPs or CIDR ranges
+                    that bypass the proxy (default: NO_PROXY)
+  user-agent      - User-Agent header sent with every HTTP request,
+                    printable ASCII only (default: vsb-cli/<version>)
+  attachment-max-size
+                  - Largest attachment 'email attachment' saves or
+                    extracts without --force, e.g. 10MB (default: no limit)
+  alias.<name>    - Make 'vsb <name>' run another command line, e.g.
+                    "email url"; an empty value removes the alias
+                    (see 'vsb alias list')
 
 Examples:
   vsb config set api-key vsb_abc123
+  vsb config set api-key vsb_abc123 --keychain
+  vsb config set api-key --keychain     # Move existing key to keychain
+  vsb config set api-key --no-keychain  # Move key back to config.yaml
+  vsb config set api-key-command "op read op://ci/vaultsandbox/api-key"
+  vsb config set api-key-command ""     # Use api-key again
   vsb config set base-url https://api.vaultsandbox.com
   vsb config set strategy sse
-  vsb config set strategy        # Interactive selection`,
+  vsb config set strategy        # Interactive selection
+  vsb config set poll-interval 1s
+  vsb config set browser "firefox --new-tab"
+  vsb config set browser "remote-open --url %u"
+  vsb config set browser ""      # Restore platform default
+  vsb config set ci-integration true
+  vsb config set html-renderer terminal
+  vsb config set default-ttl 2h
+  vsb config set default-label-prefix ci-
+  vsb config set proxy http://proxy.corp.example.com:8080
+  vsb config set proxy socks5://127.0.0.1:1080
+  vsb config set no-proxy "localhost,192.168.0.0/16"
+  vsb config set user-agent "MyCI/2.0 vsb-cli"
+  vsb config set user-agent ""   # Restore the default
+  vsb config set attachment-max-size 10MB
+  vsb config set alias.links "email url"
+  vsb config set alias.links ""  # Remove the alias`,
 	Args: cobra.RangeArgs(1, 2),
 	RunE: runConfigSet,
 }
@@ -55,9 +142,30 @@ Examples:
 func init() {
 	rootCmd.AddCommand(configCmd)
 	configCmd.AddCommand(configShowCmd)
+	configCmd.AddCommand(configGetCmd)
 	configCmd.AddCommand(configSetCmd)
+	cliutil.AddOutputFormats(configShowCmd, cliutil.FormatJSON)
+	cliutil.AddOutputFormats(configGetCmd, cliutil.FormatJSON)
+
+	configShowCmd.Flags().BoolVar(&configShowReveal, "reveal", false,
+		"Print the API key unmasked")
+	configGetCmd.Flags().BoolVar(&configGetReveal, "reveal", false,
+		"Print the API key unmasked")
+
+	configSetCmd.Flags().BoolVar(&configSetKeychain, "keychain", false,
+		"Store the API key in the system keychain")
+	configSetCmd.Flags().BoolVar(&configSetNoKeychain, "no-keychain", false,
+		"Store the API key in config.yaml and remove it from the system keychain")
+	configSetCmd.MarkFlagsMutuallyExclusive("keychain", "no-keychain")
 }
 
+var (
+	configShowReveal    bool
+	configGetReveal     bool
+	configSetKeychain   bool
+	configSetNoKeychain bool
+)
+
 // maskAPIKey masks an API key for display, showing first 7 and last 4 characters.
 func maskAPIKey(key string) string {
 	if len(key) >= 11 {
@@ -89,7 +197,10 @@ func runConfigInteractive(cmd *cobra.Command, args []string) error {
 
 	// Prompt for API key
 	prompt := "API Key: "
-	if existing.APIKey != "" {
+	inKeychain := config.IsKeychainRef(existing.APIKey)
+	if inKeychain {
+		prompt = "API Key [(system keychain)]: "
+	} else if existing.APIKey != "" {
 		prompt = fmt.Sprintf("API Key [%s]: ", maskAPIKey(existing.APIKey))
 	}
 	fmt.Print(prompt)
@@ -129,13 +240,19 @@ func runConfigInteractive(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("invalid strategy selection: %s", strategyInput)
 	}
 
-	// Save config
-	cfg := &config.Config{
-		APIKey:   apiKey,
-		BaseURL:  baseURL,
-		Strategy: strategy,
+	// A new key replaces the keychain entry and config.yaml keeps the reference
+	if inKeychain && apiKey != existing.APIKey {
+		if err := config.StoreKeychainAPIKey(apiKey); err != nil {
+			return err
+		}
+		apiKey = config.APIKeyKeychainRef
 	}
-	if err := config.Save(cfg); err != nil {
+
+	// Save config, keeping settings not covered by the prompts
+	existing.APIKey = apiKey
+	existing.BaseURL = baseURL
+	existing.Strategy = strategy
+	if err := config.Save(existing); err != nil {
 		return fmt.Errorf("failed to save config: %w", err)
 	}
 
@@ -157,7 +274,14 @@ func runConfigShow(cmd *cobra.Command, args []string) error {
 
 	// Mask API key for display
 	maskedKey := ""
-	if cfg.APIKey != "" {
+	if configShowReveal {
+		maskedKey, err = revealAPIKey(cfg.APIKey)
+		if err != nil {
+			return err
+		}
+	} else if config.IsKeychainRef(cfg.APIKey) {
+		maskedKey = "(stored in system keychain)"
+	} else if cfg.APIKey != "" {
 		maskedKey = maskAPIKey(cfg.APIKey)
 	}
 
@@ -171,17 +295,53 @@ func runConfigShow(cmd *cobra.Command, args []string) error {
 		strategy = config.DefaultStrategy
 	}
 
+	htmlRenderer := cfg.HTMLRenderer
+	if htmlRenderer == "" {
+		htmlRenderer = config.DefaultHTMLRenderer
+	}
+
+	defaultTTL := cfg.DefaultTTL
+	if defaultTTL == "" {
+		defaultTTL = config.DefaultTTL
+	}
+
+	userAgent := cfg.UserAgent
+	if userAgent == "" {
+		userAgent = config.DefaultUserAgent()
+	}
+
+	aliases := cfg.Aliases
+	if aliases == nil {
+		aliases = map[string]string{}
+	}
+
 	// JSON output
-	if cliutil.GetOutput(cmd) == "json" {
+	if cliutil.GetOutput(cmd) == cliutil.FormatJSON {
 		data := map[string]interface{}{
-			"configFile": configPath,
-			"apiKey":     maskedKey,
-			"baseUrl":    baseURL,
-			"strategy":   strategy,
+			"configFile":         configPath,
+			"apiKey":             maskedKey,
+			"apiKeyStorage":      apiKeyStorage(cfg.APIKey),
+			"apiKeyCommand":      cfg.APIKeyCommand,
+			"baseUrl":            baseURL,
+			"strategy":           strategy,
+			"pollInterval":       cfg.PollInterval,
+			"browser":            cfg.Browser,
+			"ciIntegration":      cfg.CIIntegration,
+			"htmlRenderer":       htmlRenderer,
+			"defaultTtl":         defaultTTL,
+			"defaultLabelPrefix": cfg.DefaultLabelPrefix,
+			"exportStrict":       cfg.ExportStrict,
+			"proxy":              redactProxy(cfg.Proxy),
+			"noProxy":            cfg.NoProxy,
+			"userAgent":          userAgent,
+			"attachmentMaxSize":  cfg.AttachmentMaxSize,
+			"aliases":            aliases,
+			"clockSkewSeconds":   nil,
 		}
-		out, _ := json.MarshalIndent(data, "", "  ")
-		fmt.Println(string(out))
-		return nil
+		if skew, known := config.ClockSkew(); known {
+			data["clockSkewSeconds"] = int(skew.Round(time.Second) / time.Second)
+		}
+		return cliutil.OutputJSON(data)
 	}
 
 	// Pretty output
@@ -191,8 +351,58 @@ func runConfigShow(cmd *cobra.Command, args []string) error {
 
 	fmt.Printf("Config file: %s\n\n", configPath)
 	fmt.Printf("api-key:  %s\n", maskedKey)
+	if cfg.APIKeyCommand != "" {
+		fmt.Printf("api-key-command: %s\n", cfg.APIKeyCommand)
+	}
 	fmt.Printf("base-url: %s\n", baseURL)
 	fmt.Printf("strategy: %s\n", strategy)
+	if cfg.PollInterval != "" {
+		fmt.Printf("poll-interval: %s\n", cfg.PollInterval)
+	}
+
+	browserCmd := cfg.Browser
+	if browserCmd == "" {
+		browserCmd = "(platform default)"
+	}
+	fmt.Printf("browser:  %s\n", browserCmd)
+	fmt.Printf("ci-integration: %t\n", cfg.CIIntegration)
+	fmt.Printf("html-renderer: %s\n", htmlRenderer)
+	fmt.Printf("default-ttl: %s\n", defaultTTL)
+
+	labelPrefix := cfg.DefaultLabelPrefix
+	if labelPrefix == "" {
+		labelPrefix = "(none)"
+	}
+	fmt.Printf("default-label-prefix: %s\n", labelPrefix)
+	fmt.Printf("export-strict: %t\n", cfg.ExportStrict)
+
+	proxy := redactProxy(cfg.Proxy)
+	if proxy == "" {
+		proxy = "(from HTTPS_PROXY/HTTP_PROXY)"
+	}
+	fmt.Printf("proxy: %s\n", proxy)
+	if cfg.NoProxy != "" {
+		fmt.Printf("no-proxy: %s\n", cfg.NoProxy)
+	}
+	fmt.Printf("user-agent: %s\n", userAgent)
+	if cfg.AttachmentMaxSize != "" {
+		fmt.Printf("attachment-max-size: %s\n", cfg.AttachmentMaxSize)
+	}
+	for _, name := range sortedKeys(aliases) {
+		fmt.Printf("alias.%s: %s\n", name, aliases[name])
+	}
+
+	// Not a setting, but what the expiry times shown by other commands
+	// are corrected by
+	if skew, known := config.ClockSkew(); known {
+		line := describeClockSkew(skew)
+		if skew >= config.ClockSkewWarnThreshold || skew <= -config.ClockSkewWarnThreshold {
+			line = styles.WarnStyle.Render(line + " (check this machine's time)")
+		}
+		fmt.Printf("\nClock: %s\n", line)
+	} else {
+		fmt.Printf("\nClock: not compared with the server in the last %s\n", cliutil.FormatDuration(config.ClockSkewMaxAge))
+	}
 
 	return nil
 }
@@ -205,11 +415,15 @@ func runConfigSet(cmd *cobra.Command, args []string) error {
 		return runStrategyInteractive()
 	}
 
-	if len(args) < 2 {
-		return fmt.Errorf("value required for key: %s", key)
+	if (configSetKeychain || configSetNoKeychain) && key != "api-key" {
+		return fmt.Errorf("--keychain and --no-keychain only apply to api-key")
 	}
 
-	value := args[1]
+	// Without a value, the keychain flags migrate the existing API key
+	migrate := key == "api-key" && len(args) == 1 && (configSetKeychain || configSetNoKeychain)
+	if len(args) < 2 && !migrate {
+		return fmt.Errorf("value required for key: %s", key)
+	}
 
 	// Load existing config
 	cfg, err := config.Load()
@@ -217,10 +431,46 @@ func runConfigSet(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to load config: %w", err)
 	}
 
+	if key == "api-key" {
+		var value string
+		if !migrate {
+			value = args[1]
+		}
+		wasInKeychain := config.IsKeychainRef(cfg.APIKey)
+		msg, err := setAPIKey(cfg, value, configSetKeychain, configSetNoKeychain)
+		if err != nil {
+			return err
+		}
+		if err := config.Save(cfg); err != nil {
+			return fmt.Errorf("failed to save config: %w", err)
+		}
+		// Only drop the keychain entry once config.yaml holds the key
+		if wasInKeychain && !config.IsKeychainRef(cfg.APIKey) {
+			if err := config.DeleteKeychainAPIKey(); err != nil {
+				return err
+			}
+		}
+		fmt.Println(msg)
+		return nil
+	}
+
+	value := args[1]
+
+	if name, ok := strings.CutPrefix(key, "alias."); ok {
+		if err := setAlias(cfg, name, value); err != nil {
+			return err
+		}
+		if err := config.Save(cfg); err != nil {
+			return fmt.Errorf("failed to save config: %w", err)
+		}
+		fmt.Printf("Set %s successfully\n", key)
+		return nil
+	}
+
 	// Update the appropriate key
 	switch key {
-	case "api-key":
-		cfg.APIKey = value
+	case "api-key-command":
+		cfg.APIKeyCommand = strings.TrimSpace(value)
 	case "base-url":
 		cfg.BaseURL = value
 	case "strategy":
@@ -228,8 +478,71 @@ func runConfigSet(cmd *cobra.Command, args []string) error {
 			return fmt.Errorf("invalid strategy: %s (valid: sse, polling)", value)
 		}
 		cfg.Strategy = value
+	case "poll-interval":
+		value = strings.TrimSpace(value)
+		if value != "" {
+			if _, err := config.ParsePollInterval(value); err != nil {
+				return fmt.Errorf("invalid poll-interval: %w", err)
+			}
+		}
+		cfg.PollInterval = value
+	case "browser":
+		cfg.Browser = strings.TrimSpace(value)
+	case "ci-integration":
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid ci-integration value: %s (valid: true, false)", value)
+		}
+		cfg.CIIntegration = enabled
+	case "html-renderer":
+		if value != "browser" && value != "terminal" {
+			return fmt.Errorf("invalid html-renderer: %s (valid: browser, terminal)", value)
+		}
+		cfg.HTMLRenderer = value
+	case "default-ttl":
+		if err := validateDefaultTTL(value); err != nil {
+			return err
+		}
+		cfg.DefaultTTL = value
+	case "default-label-prefix":
+		cfg.DefaultLabelPrefix = strings.TrimSpace(value)
+	case "export-strict":
+		strict, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid export-strict value: %s (valid: true, false)", value)
+		}
+		cfg.ExportStrict = strict
+	case "proxy":
+		value = strings.TrimSpace(value)
+		if value != "" {
+			if _, err := config.ParseProxyURL(value); err != nil {
+				return err
+			}
+		}
+		cfg.Proxy = value
+	case "no-proxy":
+		if err := config.ValidateNoProxy(value); err != nil {
+			return err
+		}
+		cfg.NoProxy = strings.TrimSpace(value)
+	case "user-agent":
+		value = strings.TrimSpace(value)
+		if value != "" {
+			if err := config.ValidateUserAgent(value); err != nil {
+				return err
+			}
+		}
+		cfg.UserAgent = value
+	case "attachment-max-size":
+		value = strings.TrimSpace(value)
+		if value != "" {
+			if _, err := config.ParseByteSize(value); err != nil {
+				return fmt.Errorf("invalid attachment-max-size: %w", err)
+			}
+		}
+		cfg.AttachmentMaxSize = value
 	default:
-		return fmt.Errorf("unknown config key: %s (valid keys: api-key, base-url, strategy)", key)
+		return unknownConfigKeyError(key)
 	}
 
 	// Save config
@@ -241,6 +554,199 @@ func runConfigSet(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// setAlias sets or, with an empty expansion, removes a user-defined alias.
+// Names of commands and shortcuts are refused, as the alias would hide them.
+func setAlias(cfg *config.Config, name, expansion string) error {
+	if strings.TrimSpace(expansion) == "" {
+		delete(cfg.Aliases, name)
+		return nil
+	}
+	if err := config.ValidateAlias(name, expansion); err != nil {
+		return err
+	}
+	if shadowed := shadowedCommand(rootCmd, name); shadowed != "" {
+		return fmt.Errorf("alias %q would shadow %s; choose another name", name, shadowed)
+	}
+	if cfg.Aliases == nil {
+		cfg.Aliases = make(map[string]string)
+	}
+	cfg.Aliases[name] = strings.Join(strings.Fields(expansion), " ")
+	return nil
+}
+
+// validateDefaultTTL checks a default-ttl value the same way 'inbox create'
+// parses --ttl, so a bad value is caught when it is set.
+func validateDefaultTTL(value string) error {
+	ttl, err := timeparse.Duration(value)
+	if err != nil {
+		return fmt.Errorf("invalid default-ttl: %w", err)
+	}
+	if ttl <= 0 {
+		return fmt.Errorf("invalid default-ttl: %s (must be positive)", value)
+	}
+	return nil
+}
+
+// redactProxy hides the password of a proxy URL with credentials
+func redactProxy(value string) string {
+	u, err := url.Parse(value)
+	if err != nil || u.User == nil {
+		return value
+	}
+	return u.Redacted()
+}
+
+// revealAPIKey returns the stored API key unmasked, reading it from the
+// system keychain when config.yaml holds a reference, and warns on stderr.
+func revealAPIKey(stored string) (string, error) {
+	key := stored
+	if config.IsKeychainRef(stored) {
+		var err error
+		key, err = config.ReadKeychainAPIKey()
+		if err != nil {
+			return "", fmt.Errorf("failed to read API key from system keychain: %w", err)
+		}
+	}
+	if key != "" {
+		warnAPIKeyRevealed()
+	}
+	return key, nil
+}
+
+// warnAPIKeyRevealed reminds the user that the full API key is on screen.
+func warnAPIKeyRevealed() {
+	fmt.Fprintln(os.Stderr, "Warning: printing the unmasked API key; don't share this output")
+}
+
+// unknownConfigKeyError is returned by 'config get' and 'config set' for keys
+// they don't know.
+func unknownConfigKeyError(key string) error {
+	return fmt.Errorf("unknown config key: %s (valid keys: api-key, api-key-command, base-url, strategy, poll-interval, browser, ci-integration, html-renderer, default-ttl, default-label-prefix, export-strict, proxy, no-proxy, user-agent, attachment-max-size, alias.<name>)", key)
+}
+
+func runConfigGet(cmd *cobra.Command, args []string) error {
+	key := args[0]
+	value, err := resolveConfigValue(key, configGetReveal)
+	if err != nil {
+		return err
+	}
+
+	if cliutil.GetOutput(cmd) == cliutil.FormatJSON {
+		return cliutil.OutputJSON(map[string]string{
+			"key":   key,
+			"value": value,
+		})
+	}
+
+	fmt.Println(value)
+	return nil
+}
+
+// resolveConfigValue returns the effective value of a config key after
+// applying env > config file > default precedence. The API key is masked
+// unless reveal is set.
+func resolveConfigValue(key string, reveal bool) (string, error) {
+	switch key {
+	case "api-key":
+		apiKey, err := config.ResolveAPIKey()
+		if err != nil {
+			return "", err
+		}
+		if apiKey == "" {
+			return "", nil
+		}
+		if reveal {
+			warnAPIKeyRevealed()
+			return apiKey, nil
+		}
+		return maskAPIKey(apiKey), nil
+	case "api-key-command":
+		return config.GetAPIKeyCommand(), nil
+	case "base-url":
+		return config.GetBaseURL(), nil
+	case "strategy":
+		return config.GetStrategy(), nil
+	case "poll-interval":
+		return config.GetPollInterval(), nil
+	case "browser":
+		return config.GetBrowser(), nil
+	case "ci-integration":
+		return strconv.FormatBool(config.GetCIIntegration()), nil
+	case "html-renderer":
+		return config.GetHTMLRenderer(), nil
+	case "default-ttl":
+		return config.GetDefaultTTL(), nil
+	case "default-label-prefix":
+		return config.GetDefaultLabelPrefix(), nil
+	case "export-strict":
+		return strconv.FormatBool(config.GetExportStrict()), nil
+	case "proxy":
+		return config.GetProxy(), nil
+	case "no-proxy":
+		return config.GetNoProxy(), nil
+	case "user-agent":
+		return config.GetUserAgent(), nil
+	case "attachment-max-size":
+		return config.GetAttachmentMaxSize(), nil
+	default:
+		if name, ok := strings.CutPrefix(key, "alias."); ok {
+			return config.GetAliases()[name], nil
+		}
+		return "", unknownConfigKeyError(key)
+	}
+}
+
+// apiKeyStorage describes where the API key is kept
+func apiKeyStorage(value string) string {
+	switch {
+	case config.IsKeychainRef(value):
+		return "keychain"
+	case value != "":
+		return "file"
+	default:
+		return ""
+	}
+}
+
+// setAPIKey updates cfg for 'config set api-key'. An empty value migrates the
+// existing key between config.yaml and the system keychain. Without either
+// flag the key stays where it is currently stored. The caller removes the
+// keychain entry after saving when the key moves back to config.yaml.
+func setAPIKey(cfg *config.Config, value string, toKeychain, toFile bool) (string, error) {
+	inKeychain := config.IsKeychainRef(cfg.APIKey)
+
+	if value == "" {
+		switch {
+		case toKeychain && inKeychain:
+			return "API key is already stored in the system keychain", nil
+		case toKeychain:
+			if cfg.APIKey == "" {
+				return "", fmt.Errorf("no API key to move: pass a value or run 'vsb config'")
+			}
+			value = cfg.APIKey
+		case toFile && !inKeychain:
+			return "API key is already stored in config file", nil
+		case toFile:
+			key, err := config.ReadKeychainAPIKey()
+			if err != nil {
+				return "", fmt.Errorf("failed to read API key from system keychain: %w", err)
+			}
+			value = key
+		}
+	}
+
+	if toKeychain || (inKeychain && !toFile) {
+		if err := config.StoreKeychainAPIKey(value); err != nil {
+			return "", err
+		}
+		cfg.APIKey = config.APIKeyKeychainRef
+		return "Set api-key successfully (stored in system keychain)", nil
+	}
+
+	cfg.APIKey = value
+	return "Set api-key successfully", nil
+}
+
 func runStrategyInteractive() error {
 	cfg, err := config.Load()
 	if err != nil {
@@ -289,4 +795,3 @@ func runStrategyInteractive() error {
 	fmt.Printf("\nStrategy set to: %s\n", selected)
 	return nil
 }
-
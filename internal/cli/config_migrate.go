@@ -0,0 +1,101 @@
+package cli
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"github.com/vaultsandbox/vsb-cli/internal/cliutil"
+	"github.com/vaultsandbox/vsb-cli/internal/config"
+	"github.com/vaultsandbox/vsb-cli/internal/styles"
+)
+
+var configMigrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Upgrade config.yaml and keystore.json to the current schema",
+	Long: `Upgrade config.yaml and keystore.json written by older versions of vsb.
+
+Missing fields are filled in with their defaults and the stored schema
+version is bumped. Each file is rewritten atomically, and only if something
+changed, so it is safe to run on files that are already current. Files that
+do not exist are skipped. Use --dry-run to list the changes without writing.
+The old keystore is kept as a backup; see 'vsb keystore migrate'.
+
+Other commands already read older files and upgrade the keystore the next
+time they save it; migrate does it up front for both files.
+
+Examples:
+  vsb config migrate --dry-run
+  vsb config migrate
+  vsb config migrate -o json`,
+	Args: cobra.NoArgs,
+	RunE: runConfigMigrate,
+}
+
+var configMigrateDryRun bool
+
+func init() {
+	configCmd.AddCommand(configMigrateCmd)
+	cliutil.MarkSavesState(configMigrateCmd)
+	cliutil.AddOutputFormats(configMigrateCmd, cliutil.FormatJSON)
+
+	configMigrateCmd.Flags().BoolVar(&configMigrateDryRun, "dry-run", false,
+		"Show the changes without writing any file")
+}
+
+func runConfigMigrate(cmd *cobra.Command, args []string) error {
+	var migrations []*config.Migration
+	for _, migrate := range []func(bool) (*config.Migration, error){config.MigrateConfig, config.MigrateKeystore} {
+		m, err := migrate(configMigrateDryRun)
+		if err != nil {
+			return fmt.Errorf("migration failed: %w", err)
+		}
+		migrations = append(migrations, m)
+	}
+
+	if cliutil.GetOutput(cmd) == cliutil.FormatJSON {
+		files := make([]map[string]interface{}, len(migrations))
+		for i, m := range migrations {
+			changes := m.Changes
+			if changes == nil {
+				changes = []string{}
+			}
+			files[i] = map[string]interface{}{
+				"path":    m.Path,
+				"exists":  m.Exists,
+				"from":    m.From,
+				"to":      m.To,
+				"changes": changes,
+				"written": m.Written,
+				"backup":  m.Backup,
+			}
+		}
+		return cliutil.OutputJSON(map[string]interface{}{
+			"dryRun": configMigrateDryRun,
+			"files":  files,
+		})
+	}
+
+	if configMigrateDryRun {
+		fmt.Println(styles.WarningTitleStyle.Render("Dry run: no files were changed"))
+		fmt.Println()
+	}
+	for _, m := range migrations {
+		name := filepath.Base(m.Path)
+		switch {
+		case !m.Exists:
+			fmt.Printf("%s: not found, skipped\n", name)
+		case len(m.Changes) == 0:
+			fmt.Printf("%s: up to date (schema version %d)\n", name, m.To)
+		default:
+			fmt.Printf("%s: schema version %d -> %d\n", name, m.From, m.To)
+			for _, change := range m.Changes {
+				fmt.Printf("  - %s\n", change)
+			}
+			if m.Backup != "" {
+				fmt.Printf("  backed up to %s\n", m.Backup)
+			}
+		}
+	}
+	return nil
+}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/spf13/cobra"
 	"github.com/vaultsandbox/vsb-cli/internal/cliutil"
+	"github.com/vaultsandbox/vsb-cli/internal/config"
 	"github.com/vaultsandbox/vsb-cli/internal/styles"
 )
 
@@ -26,21 +27,51 @@ Use cases:
 - Share inbox with CI/CD systems
 - Transfer inbox to another machine/team member
 
+Before writing, vsb warns if the file would land inside a git work tree
+without being covered by .gitignore, and after writing it warns if the file
+is readable by other users (e.g. on filesystems that ignore Unix
+permissions). With --strict, or the export-strict config key, these are
+errors instead and nothing is left on disk; --strict=false turns the config
+key off for one export.
+
+Every export includes a sha256 checksum that 'vsb import' verifies to catch
+truncated or modified files. Use --sign with an Ed25519 private key to also
+sign the file, so importers can check where it came from with --verify-key.
+
+The inbox's tags and filter presets are exported with it. Exports of tagged
+inboxes use format version 2, which vsb releases older than tags cannot
+import; untagged inboxes are still written as version 1.
+
 Examples:
   vsb export                     # Export active inbox
   vsb export abc@vsb.com         # Export specific inbox
-  vsb export --out ~/backup.json # Specify output file`,
+  vsb export --out ~/backup.json # Specify output file
+  vsb export --sign signing-key.pem
+  vsb export --strict            # Fail instead of warning about exposure
+  vsb export -o json             # Print path, address and expiry as JSON
+
+Create a signing key pair with openssl:
+  openssl genpkey -algorithm ed25519 -out signing-key.pem
+  openssl pkey -in signing-key.pem -pubout -out signing-key.pub.pem`,
 	Args: cobra.MaximumNArgs(1),
 	RunE: runExport,
 }
 
 var (
-	exportOut string
+	exportOut    string
+	exportSign   string
+	exportStrict bool
 )
 
 func init() {
+	cliutil.AddOutputFormats(ExportCmd, cliutil.FormatJSON)
+
 	ExportCmd.Flags().StringVar(&exportOut, "out", "",
 		"Output file path (default: <email>.json)")
+	ExportCmd.Flags().StringVar(&exportSign, "sign", "",
+		"Sign the export with this Ed25519 private key (PEM file)")
+	ExportCmd.Flags().BoolVar(&exportStrict, "strict", false,
+		"Fail if the file could be committed to git or read by other users (default from export-strict config)")
 }
 
 func runExport(cmd *cobra.Command, args []string) error {
@@ -56,8 +87,10 @@ func runExport(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	jsonMode := cliutil.GetOutput(cmd) == cliutil.FormatJSON
+
 	// Check if expired
-	if stored.ExpiresAt.Before(time.Now()) {
+	if stored.ExpiresAt.Before(config.ServerNow()) && !jsonMode {
 		warningBox := styles.WarningBoxStyle.Render(styles.WarningTitleStyle.Render("Warning: This inbox has expired"))
 		fmt.Println(warningBox)
 	}
@@ -76,8 +109,25 @@ func runExport(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("file already exists: %s (use --out to specify different path)", absPath)
 	}
 
+	strict := exportStrictMode(cmd)
+	if err := checkExportRisk(gitExposure(absPath), strict); err != nil {
+		return err
+	}
+
 	// Create export data
 	exportData := stored.ToExportFile()
+	if exportSign != "" {
+		key, err := loadSigningKey(exportSign)
+		if err != nil {
+			return err
+		}
+		if err := exportData.Sign(key); err != nil {
+			return err
+		}
+	}
+	if err := exportData.Seal(); err != nil {
+		return err
+	}
 
 	// Marshal to JSON
 	data, err := json.MarshalIndent(exportData, "", "  ")
@@ -89,6 +139,14 @@ func runExport(cmd *cobra.Command, args []string) error {
 	if err := os.WriteFile(absPath, data, 0600); err != nil {
 		return err
 	}
+	if err := checkExportRisk(permissionExposure(absPath), strict); err != nil {
+		os.Remove(absPath)
+		return err
+	}
+
+	if jsonMode {
+		return cliutil.OutputJSON(exportResultJSON(absPath, stored.Email, stored.ExpiresAt, exportData.Signature != ""))
+	}
 
 	// Security warning
 	printExportWarning(absPath, stored.Email)
@@ -96,7 +154,38 @@ func runExport(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// exportStrictMode returns --strict if it was given, so --strict=false
+// overrides the export-strict config, and the config otherwise
+func exportStrictMode(cmd *cobra.Command) bool {
+	if cmd.Flags().Changed("strict") {
+		return exportStrict
+	}
+	return config.GetExportStrict()
+}
+
+// checkExportRisk warns about risk on stderr, or returns it as an error in
+// strict mode. A nil risk is ignored.
+func checkExportRisk(risk *exportRisk, strict bool) error {
+	if risk == nil {
+		return nil
+	}
+	if strict {
+		return fmt.Errorf("refusing to export: %s (%s)", risk.Message, risk.Suggestion)
+	}
+	fmt.Fprintf(os.Stderr, "%s %s\n  %s\n",
+		styles.WarningTitleStyle.Render("Warning:"), risk.Message, risk.Suggestion)
+	return nil
+}
 
+// exportResultJSON describes a completed export for --output json.
+func exportResultJSON(path, email string, expiresAt time.Time, signed bool) map[string]interface{} {
+	return map[string]interface{}{
+		"path":      path,
+		"email":     email,
+		"expiresAt": expiresAt.Format(time.RFC3339),
+		"signed":    signed,
+	}
+}
 
 // getExportPath returns the output path for an export.
 // If outFlag is provided, it is used directly. Otherwise, generates <email>.json.
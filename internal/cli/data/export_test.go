@@ -2,8 +2,11 @@ package data
 
 import (
 	"testing"
+	"time"
 
+	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestGetExportPath(t *testing.T) {
@@ -33,3 +36,42 @@ func TestGetExportPath(t *testing.T) {
 		assert.Equal(t, "custom.json", result)
 	})
 }
+
+func TestExportResultJSON(t *testing.T) {
+	expires := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	result := exportResultJSON("/tmp/backup.json", "test@example.com", expires, true)
+
+	assert.Equal(t, "/tmp/backup.json", result["path"])
+	assert.Equal(t, "test@example.com", result["email"])
+	assert.Equal(t, "2026-03-01T12:00:00Z", result["expiresAt"])
+	assert.Equal(t, true, result["signed"])
+}
+
+func TestExportStrictMode(t *testing.T) {
+	parse := func(t *testing.T, args ...string) *cobra.Command {
+		t.Helper()
+		t.Cleanup(func() { exportStrict = false })
+		cmd := &cobra.Command{Use: "export"}
+		cmd.Flags().BoolVar(&exportStrict, "strict", false, "")
+		require.NoError(t, cmd.ParseFlags(args))
+		return cmd
+	}
+
+	tests := []struct {
+		name   string
+		config string
+		args   []string
+		want   bool
+	}{
+		{"default", "", nil, false},
+		{"from config", "true", nil, true},
+		{"flag", "", []string{"--strict"}, true},
+		{"flag false overrides config", "true", []string{"--strict=false"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("VSB_EXPORT_STRICT", tt.config)
+			assert.Equal(t, tt.want, exportStrictMode(parse(t, tt.args...)))
+		})
+	}
+}
@@ -0,0 +1,224 @@
+package data
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+)
+
+// goos is a variable for runtime.GOOS that can be overridden in tests
+var goos = runtime.GOOS
+
+// exportRisk describes a way an export file could leak its private key.
+type exportRisk struct {
+	Message    string
+	Suggestion string
+}
+
+// gitExposure reports a risk if path lies inside a git work tree and isn't
+// covered by a .gitignore (or .git/info/exclude) rule, so it could be
+// committed by accident. It returns nil when path is safe.
+func gitExposure(path string) *exportRisk {
+	root, ok := findGitRoot(filepath.Dir(path))
+	if !ok {
+		return nil
+	}
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		return nil
+	}
+	rel = filepath.ToSlash(rel)
+	if isGitIgnored(root, rel) {
+		return nil
+	}
+	return &exportRisk{
+		Message: fmt.Sprintf("%s is inside the git repository %s and is not ignored", path, root),
+		Suggestion: fmt.Sprintf("add it to .gitignore, e.g. echo '/%s' >> %s",
+			rel, filepath.Join(root, ".gitignore")),
+	}
+}
+
+// permissionExposure reports a risk if the written file is readable by
+// group or others, e.g. on filesystems that ignore the requested 0600 mode.
+func permissionExposure(path string) *exportRisk {
+	if goos == "windows" {
+		// Unix permission bits don't reflect Windows ACLs
+		return nil
+	}
+	info, err := os.Stat(path)
+	if err != nil || info.Mode().Perm()&0077 == 0 {
+		return nil
+	}
+	return &exportRisk{
+		Message:    fmt.Sprintf("%s is accessible by other users (mode %04o)", path, info.Mode().Perm()),
+		Suggestion: fmt.Sprintf("run chmod 600 %s, or export to a filesystem that supports Unix permissions", path),
+	}
+}
+
+// findGitRoot walks up from dir looking for a .git directory (or the .git
+// file used by worktrees and submodules) and returns the work tree root.
+func findGitRoot(dir string) (string, bool) {
+	for {
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			return dir, true
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false
+		}
+		dir = parent
+	}
+}
+
+// ignoreRule is one pattern from a .gitignore file.
+type ignoreRule struct {
+	base    string // directory of the .gitignore, relative to the root ("" for the root)
+	re      *regexp.Regexp
+	negate  bool
+	dirOnly bool
+}
+
+func (r ignoreRule) matches(rel string, isDir bool) bool {
+	if r.dirOnly && !isDir {
+		return false
+	}
+	if r.base != "" {
+		if !strings.HasPrefix(rel, r.base+"/") {
+			return false
+		}
+		rel = rel[len(r.base)+1:]
+	}
+	return r.re.MatchString(rel)
+}
+
+// isGitIgnored reports whether rel (slash-separated, relative to root) is
+// excluded by .git/info/exclude or a .gitignore between root and the file.
+// This covers the common gitignore syntax (globs, **, negation, anchoring
+// and directory-only patterns) rather than every corner git handles.
+func isGitIgnored(root, rel string) bool {
+	rules := parseIgnoreFile(filepath.Join(root, ".git", "info", "exclude"), "")
+
+	parts := strings.Split(rel, "/")
+	for i := 0; i < len(parts); i++ {
+		base := strings.Join(parts[:i], "/")
+		rules = append(rules, parseIgnoreFile(filepath.Join(root, filepath.FromSlash(base), ".gitignore"), base)...)
+	}
+
+	// A file can't be re-included once a parent directory is excluded, so
+	// each ancestor is checked before the file itself
+	for i := 1; i <= len(parts); i++ {
+		sub := strings.Join(parts[:i], "/")
+		isDir := i < len(parts)
+		ignored := false
+		for _, r := range rules {
+			if r.matches(sub, isDir) {
+				ignored = !r.negate
+			}
+		}
+		if ignored {
+			return true
+		}
+	}
+	return false
+}
+
+// parseIgnoreFile reads the rules in a .gitignore file. A missing or
+// unreadable file has no rules.
+func parseIgnoreFile(path, base string) []ignoreRule {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	var rules []ignoreRule
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if rule, ok := parseIgnoreLine(scanner.Text(), base); ok {
+			rules = append(rules, rule)
+		}
+	}
+	return rules
+}
+
+// parseIgnoreLine parses one .gitignore line. ok is false for blank lines,
+// comments and patterns that can't be compiled.
+func parseIgnoreLine(line, base string) (rule ignoreRule, ok bool) {
+	line = strings.TrimRight(line, " \t\r")
+	if line == "" || strings.HasPrefix(line, "#") {
+		return rule, false
+	}
+
+	rule.base = base
+	if strings.HasPrefix(line, "!") {
+		rule.negate = true
+		line = line[1:]
+	} else if strings.HasPrefix(line, `\!`) || strings.HasPrefix(line, `\#`) {
+		line = line[1:]
+	}
+	if strings.HasSuffix(line, "/") {
+		rule.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+	if line == "" {
+		return rule, false
+	}
+
+	// A slash anywhere but the end anchors the pattern to the .gitignore's
+	// directory; otherwise it matches at any depth
+	anchored := strings.Contains(line, "/")
+	line = strings.TrimPrefix(line, "/")
+
+	prefix := "^(?:.*/)?"
+	if anchored {
+		prefix = "^"
+	}
+	re, err := regexp.Compile(prefix + globToRegexp(line) + "$")
+	if err != nil {
+		return rule, false
+	}
+	rule.re = re
+	return rule, true
+}
+
+// globToRegexp converts a gitignore glob to a regular expression body.
+func globToRegexp(glob string) string {
+	var b strings.Builder
+	for i := 0; i < len(glob); i++ {
+		c := glob[i]
+		switch {
+		case strings.HasPrefix(glob[i:], "**/") && (i == 0 || glob[i-1] == '/'):
+			b.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(glob[i:], "**"):
+			b.WriteString(".*")
+			i++
+		case c == '*':
+			b.WriteString("[^/]*")
+		case c == '?':
+			b.WriteString("[^/]")
+		case c == '[':
+			end := strings.IndexByte(glob[i+1:], ']')
+			if end < 0 {
+				b.WriteString(`\[`)
+				continue
+			}
+			class := glob[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			b.WriteString("[" + class + "]")
+			i += end + 1
+		case c == '\\' && i+1 < len(glob):
+			i++
+			b.WriteString(regexp.QuoteMeta(string(glob[i])))
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	return b.String()
+}
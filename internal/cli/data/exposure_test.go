@@ -0,0 +1,144 @@
+package data
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newFakeRepo creates a directory with an empty .git directory and the
+// given files (relative path to contents).
+func newFakeRepo(t *testing.T, files map[string]string) string {
+	t.Helper()
+	root := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(root, ".git"), 0755))
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+	return root
+}
+
+func TestFindGitRoot(t *testing.T) {
+	root := newFakeRepo(t, map[string]string{"a/b/keep": ""})
+
+	got, ok := findGitRoot(filepath.Join(root, "a", "b"))
+	assert.True(t, ok)
+	assert.Equal(t, root, got)
+
+	t.Run("git file for worktrees", func(t *testing.T) {
+		wt := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(wt, ".git"), []byte("gitdir: /elsewhere\n"), 0644))
+		got, ok := findGitRoot(wt)
+		assert.True(t, ok)
+		assert.Equal(t, wt, got)
+	})
+
+	t.Run("outside a repository", func(t *testing.T) {
+		_, ok := findGitRoot(t.TempDir())
+		assert.False(t, ok)
+	})
+}
+
+func TestIsGitIgnored(t *testing.T) {
+	root := newFakeRepo(t, map[string]string{
+		".gitignore":             "# exports\n*.json\n!package.json\n/build/\nsecrets/**\n\\#notes\n",
+		"sub/.gitignore":         "inbox-*.json\n!keep.json\n/local.txt\n",
+		".git/info/exclude":      "scratch?.txt\n",
+		"deep/nested/.gitignore": "",
+	})
+
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"backup.json", true},
+		{"deep/nested/backup.json", true},
+		{"package.json", false},
+		{"sub/keep.json", false},
+		{"sub/inbox-1.json", true},
+		{"sub/local.txt", true},
+		{"other/local.txt", false},
+		{"build/out.txt", true},
+		{"src/build/out.txt", false},
+		{"secrets/a/b.txt", true},
+		{"scratch1.txt", true},
+		{"scratch10.txt", false},
+		{"#notes", true},
+		{"notes.txt", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			assert.Equal(t, tt.want, isGitIgnored(root, tt.path))
+		})
+	}
+
+	t.Run("excluded parent directory cannot be re-included", func(t *testing.T) {
+		root := newFakeRepo(t, map[string]string{".gitignore": "exports/\n!exports/inbox.txt\n"})
+		assert.True(t, isGitIgnored(root, "exports/inbox.txt"))
+	})
+}
+
+func TestGlobToRegexp(t *testing.T) {
+	tests := []struct {
+		glob, want string
+	}{
+		{"*.json", `[^/]*\.json`},
+		{"a?c", `a[^/]c`},
+		{"**/logs", `(?:.*/)?logs`},
+		{"logs/**", `logs/.*`},
+		{"[!ab]x", `[^ab]x`},
+		{"[oops", `\[oops`},
+		{`\*lit`, `\*lit`},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, globToRegexp(tt.glob), tt.glob)
+	}
+}
+
+func TestGitExposure(t *testing.T) {
+	root := newFakeRepo(t, map[string]string{".gitignore": "*.json\n"})
+
+	assert.Nil(t, gitExposure(filepath.Join(root, "inbox.json")))
+	assert.Nil(t, gitExposure(filepath.Join(t.TempDir(), "inbox.txt")))
+
+	risk := gitExposure(filepath.Join(root, "keys", "inbox.txt"))
+	require.NotNil(t, risk)
+	assert.Contains(t, risk.Message, "is not ignored")
+	assert.Contains(t, risk.Suggestion, "echo '/keys/inbox.txt' >> "+filepath.Join(root, ".gitignore"))
+}
+
+func TestPermissionExposure(t *testing.T) {
+	oldGOOS := goos
+	defer func() { goos = oldGOOS }()
+	goos = "linux"
+
+	dir := t.TempDir()
+	private := filepath.Join(dir, "private.json")
+	require.NoError(t, os.WriteFile(private, []byte("{}"), 0600))
+	assert.Nil(t, permissionExposure(private))
+
+	shared := filepath.Join(dir, "shared.json")
+	require.NoError(t, os.WriteFile(shared, []byte("{}"), 0600))
+	require.NoError(t, os.Chmod(shared, 0644))
+	risk := permissionExposure(shared)
+	require.NotNil(t, risk)
+	assert.Contains(t, risk.Message, "mode 0644")
+
+	goos = "windows"
+	assert.Nil(t, permissionExposure(shared))
+}
+
+func TestCheckExportRisk(t *testing.T) {
+	assert.NoError(t, checkExportRisk(nil, true))
+
+	risk := &exportRisk{Message: "file is exposed", Suggestion: "fix it"}
+	assert.NoError(t, checkExportRisk(risk, false))
+
+	err := checkExportRisk(risk, true)
+	assert.EqualError(t, err, "refusing to export: file is exposed (fix it)")
+}
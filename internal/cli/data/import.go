@@ -1,7 +1,6 @@
 package data
 
 import (
-	"context"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -22,51 +21,66 @@ var ImportCmd = &cobra.Command{
 This adds the inbox to your local keystore and optionally verifies
 it's still valid on the server.
 
+The file's checksum is verified when present; files exported by older
+versions without one are still accepted. Use --verify-key to require a
+valid Ed25519 signature from 'vsb export --sign'.
+
+An inbox that is already in the keystore is rejected unless --force, which
+replaces it wholesale, or --update, which refreshes only its keys and expiry
+and keeps local state such as its label, tags, filter presets and read
+emails. --update does nothing (and succeeds) when the stored inbox is at
+least as new as the export, judged by the export's exportedAt time, so
+re-importing the same file is safe.
+
 Examples:
   vsb import backup.json      # Import and verify
   vsb import backup.json -l   # Skip server verification
-  vsb import backup.json -f   # Force overwrite existing`,
+  vsb import backup.json -f   # Force overwrite existing
+  vsb import backup.json --update  # Refresh keys/expiry if the export is newer
+  vsb import backup.json --verify-key signing-key.pub.pem
+  vsb import backup.json -o json  # Print address, expiry and outcome as JSON`,
 	Args: cobra.ExactArgs(1),
 	RunE: runImport,
 }
 
 var (
-	importLocal bool
-	importForce bool
+	importLocal     bool
+	importForce     bool
+	importUpdate    bool
+	importVerifyKey string
 )
 
 func init() {
+	cliutil.MarkSavesState(ImportCmd)
+	cliutil.AddOutputFormats(ImportCmd, cliutil.FormatJSON)
+
 	ImportCmd.Flags().BoolVarP(&importLocal, "local", "l", false,
 		"Skip server verification")
 	ImportCmd.Flags().BoolVarP(&importForce, "force", "f", false,
 		"Overwrite existing inbox with same email")
+	ImportCmd.Flags().BoolVar(&importUpdate, "update", false,
+		"Refresh an existing inbox's keys and expiry if the export is newer")
+	ImportCmd.Flags().StringVar(&importVerifyKey, "verify-key", "",
+		"Require a signature from this Ed25519 public key (PEM file or base64)")
+
+	ImportCmd.MarkFlagsMutuallyExclusive("force", "update")
 }
 
 func runImport(cmd *cobra.Command, args []string) error {
-	ctx := context.Background()
-	filePath := args[0]
-
-	// Read file
-	data, err := os.ReadFile(filePath)
+	ctx := cliutil.CommandContext(cmd)
+	exported, err := readExportFile(args[0], importVerifyKey)
 	if err != nil {
-		return fmt.Errorf("failed to read file: %w", err)
-	}
-
-	// Parse JSON
-	var exported config.ExportedInboxFile
-	if err := json.Unmarshal(data, &exported); err != nil {
-		return fmt.Errorf("invalid export file format: %w", err)
+		return err
 	}
 
-	// Validate version
-	if exported.Version != 1 {
-		return fmt.Errorf("unsupported export file version: %d", exported.Version)
-	}
+	jsonMode := cliutil.GetOutput(cmd) == cliutil.FormatJSON
 
 	// Check if expired
-	if exported.ExpiresAt.Before(time.Now()) {
-		errorBox := styles.ErrorBoxStyle.Render(styles.ErrorTitleStyle.Render("Error: This inbox has expired"))
-		fmt.Println(errorBox)
+	if exported.ExpiresAt.Before(config.ServerNow()) {
+		if !jsonMode {
+			errorBox := styles.ErrorBoxStyle.Render(styles.ErrorTitleStyle.Render("Error: This inbox has expired"))
+			fmt.Println(errorBox)
+		}
 		return fmt.Errorf("inbox expired on %s", exported.ExpiresAt.Format("2006-01-02"))
 	}
 
@@ -78,13 +92,19 @@ func runImport(cmd *cobra.Command, args []string) error {
 
 	// Check for existing inbox
 	existing, _ := keystore.GetInbox(exported.EmailAddress)
-	if existing != nil && !importForce {
-		return fmt.Errorf("inbox already exists: %s (use --force to overwrite)", exported.EmailAddress)
+	update := existing != nil && importUpdate
+	if existing != nil && !importForce && !importUpdate {
+		return fmt.Errorf("inbox already exists: %s (use --force to overwrite or --update to refresh)", exported.EmailAddress)
+	}
+	if update && !exported.ExportedAt.After(existing.UpdatedAt()) {
+		return reportUpToDate(*existing, exported, jsonMode)
 	}
 
 	// Server verification (unless --local)
 	if !importLocal {
-		fmt.Println(styles.MutedStyle.Render("• Verifying with server..."))
+		if !jsonMode {
+			fmt.Println(styles.MutedStyle.Render("• Verifying with server..."))
+		}
 
 		client, err := config.NewClient()
 		if err != nil {
@@ -101,28 +121,118 @@ func runImport(cmd *cobra.Command, args []string) error {
 
 		// Check sync status
 		status, err := inbox.GetSyncStatus(ctx)
-		if err != nil {
-			fmt.Println(styles.MutedStyle.Render("• Warning: Could not verify sync status"))
-		} else {
-			fmt.Println(styles.PassStyle.Render(fmt.Sprintf("✓ Inbox verified: %d emails", status.EmailCount)))
+		if !jsonMode {
+			if err != nil {
+				fmt.Println(styles.MutedStyle.Render("• Warning: Could not verify sync status"))
+			} else {
+				fmt.Println(styles.PassStyle.Render(fmt.Sprintf("✓ Inbox verified: %d emails", status.EmailCount)))
+			}
 		}
 	}
 
 	// Save to keystore
 	stored := exported.ToStoredInbox()
 
+	if update {
+		updated, err := keystore.UpdateInboxFromExport(exported)
+		if err != nil {
+			return err
+		}
+		if !updated {
+			// Another process stored a newer copy since the check above
+			return reportUpToDate(*existing, exported, jsonMode)
+		}
+		if jsonMode {
+			result := importResultJSON(stored, false, !importLocal)
+			result["updated"] = true
+			return cliutil.OutputJSON(result)
+		}
+		fmt.Println(styles.PassStyle.Render(fmt.Sprintf("✓ Updated %s from the export of %s (expires %s)",
+			stored.Email, exported.ExportedAt.Local().Format("2006-01-02 15:04"), stored.ExpiresAt.Local().Format("2006-01-02 15:04"))))
+		return nil
+	}
+
 	if err := keystore.AddInbox(stored); err != nil {
 		return err
 	}
 
+	if jsonMode {
+		result := importResultJSON(stored, existing != nil, !importLocal)
+		if importUpdate {
+			result["updated"] = false
+		}
+		return cliutil.OutputJSON(result)
+	}
+
 	// Success output
 	printImportSuccess(stored)
 
 	return nil
 }
 
+// importResultJSON describes a completed import for --output json. forced
+// reports whether an existing inbox was overwritten.
+func importResultJSON(inbox config.StoredInbox, forced, serverVerified bool) map[string]interface{} {
+	return map[string]interface{}{
+		"email":          inbox.Email,
+		"expiresAt":      inbox.ExpiresAt.Format(time.RFC3339),
+		"forced":         forced,
+		"serverVerified": serverVerified,
+	}
+}
+
+// reportUpToDate reports an --update import skipped because the stored
+// inbox is at least as new as the export.
+func reportUpToDate(existing config.StoredInbox, exported *config.ExportedInboxFile, jsonMode bool) error {
+	if jsonMode {
+		result := importResultJSON(existing, false, false)
+		result["updated"] = false
+		return cliutil.OutputJSON(result)
+	}
+	fmt.Printf("%s is already up to date (stored %s, export %s)\n", existing.Email,
+		existing.UpdatedAt().Local().Format("2006-01-02 15:04"), exported.ExportedAt.Local().Format("2006-01-02 15:04"))
+	return nil
+}
+
+// readExportFile parses an export file and checks its version, checksum and,
+// when verifyKey is set, its signature.
+func readExportFile(path, verifyKey string) (*config.ExportedInboxFile, error) {
+	// Read file
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	// Parse JSON
+	var exported config.ExportedInboxFile
+	if err := json.Unmarshal(data, &exported); err != nil {
+		return nil, fmt.Errorf("invalid export file format: %w", err)
+	}
+
+	// Validate version
+	if exported.Version < config.ExportVersion || exported.Version > config.ExportVersionLatest {
+		return nil, fmt.Errorf("unsupported export file version: %d", exported.Version)
+	}
+
+	if err := exported.VerifyChecksum(); err != nil {
+		return nil, err
+	}
+
+	if verifyKey != "" {
+		key, err := parseVerifyKey(verifyKey)
+		if err != nil {
+			return nil, err
+		}
+		if err := exported.VerifySignature(key); err != nil {
+			return nil, err
+		}
+	}
+
+	return &exported, nil
+}
+
 func printImportSuccess(inbox config.StoredInbox) {
-	remaining := time.Until(inbox.ExpiresAt).Round(time.Hour)
+	remaining := inbox.ExpiresAt.Sub(config.ServerNow()).Round(time.Hour)
 
 	content := fmt.Sprintf(`%s
 
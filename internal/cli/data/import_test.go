@@ -1,26 +1,32 @@
 package data
 
 import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
 	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
+	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/vaultsandbox/vsb-cli/internal/config"
 )
 
 func TestImportValidation(t *testing.T) {
 	t.Run("rejects unsupported version", func(t *testing.T) {
-		data := `{"version": 2, "emailAddress": "test@example.com"}`
+		data := `{"version": 3, "emailAddress": "test@example.com"}`
 		var exported config.ExportedInboxFile
 		err := json.Unmarshal([]byte(data), &exported)
 		assert.NoError(t, err) // Parsing succeeds
 
 		// Version validation (same logic as runImport)
-		if exported.Version != 1 {
-			// This is what runImport checks
-			assert.NotEqual(t, 1, exported.Version)
-		}
+		assert.Greater(t, exported.Version, config.ExportVersionLatest)
 	})
 
 	t.Run("rejects version 0", func(t *testing.T) {
@@ -112,3 +118,230 @@ func TestImportValidation(t *testing.T) {
 		assert.Equal(t, "server-sig-data", exported.Keys.ServerSigPK)
 	})
 }
+
+// writeExportFile writes a sealed (and optionally signed) export to disk.
+func writeExportFile(t *testing.T, priv ed25519.PrivateKey) (string, []byte) {
+	t.Helper()
+	exported := config.ExportedInboxFile{
+		Version:      1,
+		EmailAddress: "test@example.com",
+		InboxHash:    "hash123",
+		ExpiresAt:    time.Now().Add(24 * time.Hour),
+		ExportedAt:   time.Now(),
+		Keys: config.ExportedKeys{
+			KEMPrivate:  "cHJpdmF0ZUtleU1hdGVyaWFs",
+			KEMPublic:   "cHVibGljS2V5TWF0ZXJpYWw",
+			ServerSigPK: "c2VydmVyU2lnS2V5",
+		},
+	}
+	if priv != nil {
+		require.NoError(t, exported.Sign(priv))
+	}
+	require.NoError(t, exported.Seal())
+
+	data, err := json.MarshalIndent(exported, "", "  ")
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "export.json")
+	require.NoError(t, os.WriteFile(path, data, 0600))
+	return path, data
+}
+
+// flipBit flips the lowest bit of the first alphanumeric byte at or after
+// the value of field. Alphanumerics stay printable, so the JSON stays valid.
+func flipBit(t *testing.T, data []byte, field string) []byte {
+	t.Helper()
+	i := bytes.Index(data, []byte(`"`+field+`": "`))
+	require.GreaterOrEqual(t, i, 0, "field %s not found", field)
+	i += len(field) + 5
+	for ; i < len(data); i++ {
+		c := data[i]
+		if (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9') {
+			break
+		}
+	}
+	out := bytes.Clone(data)
+	out[i] ^= 1
+	return out
+}
+
+func TestReadExportFile(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	pubB64 := base64.StdEncoding.EncodeToString(pub)
+
+	t.Run("valid sealed file", func(t *testing.T) {
+		path, _ := writeExportFile(t, nil)
+		exported, err := readExportFile(path, "")
+		require.NoError(t, err)
+		assert.Equal(t, "test@example.com", exported.EmailAddress)
+	})
+
+	t.Run("file without checksum is accepted", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "old.json")
+		require.NoError(t, os.WriteFile(path, []byte(`{"version": 1, "emailAddress": "old@example.com"}`), 0600))
+		exported, err := readExportFile(path, "")
+		require.NoError(t, err)
+		assert.Equal(t, "old@example.com", exported.EmailAddress)
+	})
+
+	t.Run("version 2 with tags is accepted", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "tagged.json")
+		require.NoError(t, os.WriteFile(path, []byte(`{"version": 2, "emailAddress": "tagged@example.com", "tags": ["auth"]}`), 0600))
+		exported, err := readExportFile(path, "")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"auth"}, exported.Tags)
+	})
+
+	t.Run("newer version is rejected", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "newer.json")
+		require.NoError(t, os.WriteFile(path, []byte(`{"version": 3, "emailAddress": "new@example.com"}`), 0600))
+		_, err := readExportFile(path, "")
+		assert.ErrorContains(t, err, "unsupported export file version: 3")
+	})
+
+	for _, field := range []string{"emailAddress", "inboxHash", "kemPrivate", "kemPublic", "serverSigPk", "checksum"} {
+		t.Run("bit flip in "+field, func(t *testing.T) {
+			path, data := writeExportFile(t, nil)
+			require.NoError(t, os.WriteFile(path, flipBit(t, data, field), 0600))
+
+			_, err := readExportFile(path, "")
+			require.Error(t, err)
+			assert.ErrorIs(t, err, config.ErrExportCorrupted)
+			assert.Contains(t, err.Error(), "export file corrupted or modified")
+		})
+	}
+
+	t.Run("truncated file", func(t *testing.T) {
+		path, data := writeExportFile(t, nil)
+		require.NoError(t, os.WriteFile(path, data[:len(data)/2], 0600))
+
+		_, err := readExportFile(path, "")
+		assert.ErrorContains(t, err, "invalid export file format")
+	})
+
+	t.Run("signed file verifies", func(t *testing.T) {
+		path, _ := writeExportFile(t, priv)
+		_, err := readExportFile(path, pubB64)
+		assert.NoError(t, err)
+	})
+
+	t.Run("bit flip in signature", func(t *testing.T) {
+		path, data := writeExportFile(t, priv)
+		require.NoError(t, os.WriteFile(path, flipBit(t, data, "signature"), 0600))
+
+		_, err := readExportFile(path, pubB64)
+		assert.ErrorIs(t, err, config.ErrExportSignature)
+	})
+
+	t.Run("unsigned file with verify key", func(t *testing.T) {
+		path, _ := writeExportFile(t, nil)
+		_, err := readExportFile(path, pubB64)
+		assert.ErrorIs(t, err, config.ErrExportUnsigned)
+	})
+
+	t.Run("invalid verify key", func(t *testing.T) {
+		path, _ := writeExportFile(t, priv)
+		_, err := readExportFile(path, "not-a-key")
+		assert.ErrorContains(t, err, "invalid verify key")
+	})
+}
+
+func TestImportResultJSON(t *testing.T) {
+	inbox := config.StoredInbox{
+		Email:     "test@example.com",
+		ExpiresAt: time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC),
+	}
+
+	result := importResultJSON(inbox, true, false)
+	assert.Equal(t, "test@example.com", result["email"])
+	assert.Equal(t, "2026-03-01T12:00:00Z", result["expiresAt"])
+	assert.Equal(t, true, result["forced"])
+	assert.Equal(t, false, result["serverVerified"])
+}
+
+func TestImportUpdate(t *testing.T) {
+	// run imports path with --local --update and returns stdout
+	run := func(t *testing.T, path string) (string, error) {
+		t.Helper()
+		oldLocal, oldUpdate := importLocal, importUpdate
+		importLocal, importUpdate = true, true
+		defer func() { importLocal, importUpdate = oldLocal, oldUpdate }()
+
+		old := os.Stdout
+		r, w, err := os.Pipe()
+		require.NoError(t, err)
+		os.Stdout = w
+		runErr := runImport(&cobra.Command{}, []string{path})
+		w.Close()
+		os.Stdout = old
+		out, err := io.ReadAll(r)
+		require.NoError(t, err)
+		return string(out), runErr
+	}
+	// writeExport writes an export of the stored inbox taken at exportedAt
+	writeExport := func(t *testing.T, stored config.StoredInbox, exportedAt time.Time, priv string) string {
+		t.Helper()
+		exported := stored.ToExportFile()
+		exported.ExportedAt = exportedAt
+		exported.Keys.KEMPrivate = priv
+		exported.Filters = nil
+		require.NoError(t, exported.Seal())
+		data, err := json.Marshal(exported)
+		require.NoError(t, err)
+		path := filepath.Join(t.TempDir(), "export.json")
+		require.NoError(t, os.WriteFile(path, data, 0600))
+		return path
+	}
+
+	t.Setenv("VSB_CONFIG_DIR", t.TempDir())
+	ks, err := config.LoadKeystore()
+	require.NoError(t, err)
+	stored := config.StoredInbox{
+		Email:      "ci@example.com",
+		ID:         "hash",
+		Label:      "ci",
+		ExpiresAt:  time.Now().Add(time.Hour),
+		ExportedAt: time.Now().Add(-time.Hour),
+		Keys:       config.InboxKeys{KEMPrivate: "old-priv"},
+		Filters:    map[string]config.FilterPreset{"billing": {From: "billing@example.com"}},
+	}
+	require.NoError(t, ks.AddInbox(stored))
+
+	t.Run("skips an older export", func(t *testing.T) {
+		out, err := run(t, writeExport(t, stored, stored.ExportedAt.Add(-time.Minute), "older-priv"))
+		require.NoError(t, err)
+		assert.Contains(t, out, "ci@example.com is already up to date")
+	})
+
+	t.Run("updates from a newer export", func(t *testing.T) {
+		path := writeExport(t, stored, time.Now(), "new-priv")
+		out, err := run(t, path)
+		require.NoError(t, err)
+		assert.Contains(t, out, "Updated ci@example.com")
+
+		ks, err := config.LoadKeystore()
+		require.NoError(t, err)
+		inbox, err := ks.GetInbox("ci@example.com")
+		require.NoError(t, err)
+		assert.Equal(t, "new-priv", inbox.Keys.KEMPrivate)
+		assert.Equal(t, "ci", inbox.Label)
+		assert.Contains(t, inbox.Filters, "billing")
+
+		// Importing the same file again is a no-op
+		out, err = run(t, path)
+		require.NoError(t, err)
+		assert.Contains(t, out, "already up to date")
+	})
+
+	t.Run("adds a new inbox", func(t *testing.T) {
+		other := stored
+		other.Email = "new@example.com"
+		_, err := run(t, writeExport(t, other, time.Now(), "priv"))
+		require.NoError(t, err)
+
+		ks, err := config.LoadKeystore()
+		require.NoError(t, err)
+		_, err = ks.GetInbox("new@example.com")
+		assert.NoError(t, err)
+	})
+}
@@ -0,0 +1,59 @@
+package data
+
+import (
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// loadSigningKey reads an Ed25519 private key from a PKCS#8 PEM file, as
+// written by 'openssl genpkey -algorithm ed25519'.
+func loadSigningKey(path string) (ed25519.PrivateKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read signing key: %w", err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("invalid signing key: %s is not a PEM file", path)
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid signing key: %w", err)
+	}
+	priv, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("invalid signing key: not an Ed25519 key")
+	}
+	return priv, nil
+}
+
+// parseVerifyKey accepts an Ed25519 public key as a PEM file path, or as
+// the base64-encoded 32-byte key itself.
+func parseVerifyKey(value string) (ed25519.PublicKey, error) {
+	if data, err := os.ReadFile(value); err == nil {
+		value = string(data)
+	}
+
+	if block, _ := pem.Decode([]byte(value)); block != nil {
+		key, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("invalid verify key: %w", err)
+		}
+		pub, ok := key.(ed25519.PublicKey)
+		if !ok {
+			return nil, fmt.Errorf("invalid verify key: not an Ed25519 key")
+		}
+		return pub, nil
+	}
+
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(value))
+	if err != nil || len(raw) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("invalid verify key: expected a PEM file or base64-encoded Ed25519 public key")
+	}
+	return ed25519.PublicKey(raw), nil
+}
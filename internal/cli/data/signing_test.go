@@ -0,0 +1,62 @@
+package data
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSigningKeys(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	dir := t.TempDir()
+
+	privDER, err := x509.MarshalPKCS8PrivateKey(priv)
+	require.NoError(t, err)
+	privPath := filepath.Join(dir, "key.pem")
+	require.NoError(t, os.WriteFile(privPath, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privDER}), 0600))
+
+	pubDER, err := x509.MarshalPKIXPublicKey(pub)
+	require.NoError(t, err)
+	pubPath := filepath.Join(dir, "key.pub.pem")
+	require.NoError(t, os.WriteFile(pubPath, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubDER}), 0600))
+
+	t.Run("loads PEM private key", func(t *testing.T) {
+		key, err := loadSigningKey(privPath)
+		require.NoError(t, err)
+		assert.Equal(t, priv, key)
+	})
+
+	t.Run("rejects non-PEM private key", func(t *testing.T) {
+		_, err := loadSigningKey(pubPath + ".missing")
+		assert.ErrorContains(t, err, "failed to read signing key")
+
+		_, err = loadSigningKey(pubPath)
+		assert.ErrorContains(t, err, "invalid signing key")
+	})
+
+	t.Run("parses PEM public key file", func(t *testing.T) {
+		key, err := parseVerifyKey(pubPath)
+		require.NoError(t, err)
+		assert.Equal(t, pub, key)
+	})
+
+	t.Run("parses base64 public key", func(t *testing.T) {
+		key, err := parseVerifyKey(base64.StdEncoding.EncodeToString(pub))
+		require.NoError(t, err)
+		assert.Equal(t, pub, key)
+	})
+
+	t.Run("rejects short key", func(t *testing.T) {
+		_, err := parseVerifyKey(base64.StdEncoding.EncodeToString([]byte("short")))
+		assert.ErrorContains(t, err, "invalid verify key")
+	})
+}
@@ -0,0 +1,188 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/vaultsandbox/vsb-cli/internal/config"
+)
+
+// configDefaultAnnotation marks a flag whose default comes from the
+// defaults section of the config file, for its help
+const configDefaultAnnotation = "vsb_config_default"
+
+// applyFlagDefaults checks every entry of the defaults section against the
+// command tree under root, warning on w about entries that name no command
+// or flag, and makes the rest the flag defaults of the command args will
+// run. Defaults of a parent command apply to its subcommands' inherited
+// flags, with the closer command's winning. Flags given on the command line
+// are parsed later, so they always win.
+//
+// Only the command that runs is changed, as persistent flags are shared
+// by every command under the one defining them.
+func applyFlagDefaults(root *cobra.Command, args []string, defaults []config.FlagDefault, w io.Writer) {
+	target := targetCommand(root, args)
+
+	type pending struct {
+		key   string
+		depth int
+		flag  *pflag.Flag
+		value []string
+	}
+	var apply []pending
+	for _, d := range defaults {
+		cmd, err := defaultsCommand(root, d.Command)
+		if err != nil {
+			fmt.Fprintf(w, "Warning: ignoring config defaults.%s: %v\n", d.Key, err)
+			continue
+		}
+		flag := lookupFlag(cmd, d.Flag)
+		if flag == nil {
+			fmt.Fprintf(w, "Warning: ignoring config defaults.%s: unknown flag --%s for '%s'\n", d.Key, d.Flag, cmd.CommandPath())
+			continue
+		}
+		if len(d.Values) == 0 {
+			fmt.Fprintf(w, "Warning: ignoring config defaults.%s: no value\n", d.Key)
+			continue
+		}
+		// A local flag of a parent command is not the target's flag
+		if target != nil && isAncestor(cmd, target) && lookupFlag(target, d.Flag) == flag {
+			apply = append(apply, pending{d.Key, len(d.Command), flag, d.Values})
+		}
+	}
+
+	sort.SliceStable(apply, func(i, j int) bool { return apply[i].depth < apply[j].depth })
+	for _, p := range apply {
+		if err := setFlagDefault(p.flag, p.value); err != nil {
+			fmt.Fprintf(w, "Warning: ignoring config defaults.%s: %v\n", p.key, err)
+		}
+	}
+}
+
+// targetCommand returns the command args run, or the one 'help' shows
+func targetCommand(root *cobra.Command, args []string) *cobra.Command {
+	// Cobra adds the help command when it executes
+	root.InitDefaultHelpCmd()
+	cmd, rest, err := root.Find(args)
+	if err != nil {
+		return nil
+	}
+	if cmd.Name() == "help" && cmd.Parent() == root {
+		if cmd, _, err = root.Find(rest); err != nil {
+			return nil
+		}
+	}
+	return cmd
+}
+
+// defaultsCommand finds the command named by the path of a defaults entry.
+// Command aliases are accepted, as on the command line.
+func defaultsCommand(root *cobra.Command, path []string) (*cobra.Command, error) {
+	cmd := root
+	for _, name := range path {
+		next := findSubcommand(cmd, name)
+		if next == nil {
+			return nil, fmt.Errorf("unknown command '%s %s'", cmd.CommandPath(), name)
+		}
+		cmd = next
+	}
+	return cmd, nil
+}
+
+func findSubcommand(cmd *cobra.Command, name string) *cobra.Command {
+	for _, sub := range cmd.Commands() {
+		if sub.Name() == name || sub.HasAlias(name) {
+			return sub
+		}
+	}
+	return nil
+}
+
+// lookupFlag finds a flag of cmd, including the ones it inherits
+func lookupFlag(cmd *cobra.Command, name string) *pflag.Flag {
+	if flag := cmd.Flags().Lookup(name); flag != nil {
+		return flag
+	}
+	for c := cmd; c != nil; c = c.Parent() {
+		if flag := c.PersistentFlags().Lookup(name); flag != nil {
+			return flag
+		}
+	}
+	return nil
+}
+
+func isAncestor(parent, cmd *cobra.Command) bool {
+	for c := cmd; c != nil; c = c.Parent() {
+		if c == parent {
+			return true
+		}
+	}
+	return false
+}
+
+// setFlagDefault sets the flag to values, as if each had been given on the
+// command line, and makes the result its default
+func setFlagDefault(flag *pflag.Flag, values []string) error {
+	restore := func() { flag.Value.Set(flag.DefValue) }
+	if slice, ok := flag.Value.(pflag.SliceValue); ok {
+		previous := slice.GetSlice()
+		restore = func() { slice.Replace(previous) }
+	}
+	for _, value := range values {
+		// pflag may store a zero value before reporting the error
+		if err := flag.Value.Set(value); err != nil {
+			restore()
+			return fmt.Errorf("invalid value %q for --%s: %v", value, flag.Name, err)
+		}
+	}
+	flag.DefValue = flag.Value.String()
+	if flag.Annotations == nil {
+		flag.Annotations = make(map[string][]string)
+	}
+	flag.Annotations[configDefaultAnnotation] = []string{"true"}
+	return nil
+}
+
+// flagUsages is FlagSet.FlagUsages, with "(from config)" after defaults
+// that come from the config file
+func flagUsages(flags *pflag.FlagSet) string {
+	marked := pflag.NewFlagSet("", pflag.ContinueOnError)
+	marked.SortFlags = flags.SortFlags
+	flags.VisitAll(func(flag *pflag.Flag) {
+		if _, ok := flag.Annotations[configDefaultAnnotation]; !ok {
+			marked.AddFlag(flag)
+			return
+		}
+		// pflag leaves out defaults that print as empty, so the default is
+		// written into the usage instead, where it can be followed
+		c := *flag
+		c.Value = noDefaultValue{flag.Value}
+		format := " (default %s) (from config)"
+		if flag.Value.Type() == "string" {
+			format = " (default %q) (from config)"
+		}
+		c.Usage = flag.Usage + fmt.Sprintf(format, flag.DefValue)
+		marked.AddFlag(&c)
+	})
+	return marked.FlagUsages()
+}
+
+// noDefaultValue hides a flag's default from pflag's usage output
+type noDefaultValue struct {
+	pflag.Value
+}
+
+func (noDefaultValue) String() string { return "" }
+
+// withConfigDefaults makes a cobra usage template list flags with
+// flagUsages
+func withConfigDefaults(template string) string {
+	for _, set := range []string{"LocalFlags", "InheritedFlags"} {
+		template = strings.ReplaceAll(template, "{{."+set+".FlagUsages", "{{flagUsages ."+set)
+	}
+	return template
+}
@@ -0,0 +1,162 @@
+package cli
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vaultsandbox/vsb-cli/internal/config"
+)
+
+// defaultsTree is a small command tree for applyFlagDefaults tests
+type defaultsTree struct {
+	root                     *cobra.Command
+	output, metrics, timeout string
+	count                    int
+	inboxes                  []string
+	wide                     bool
+	ran                      string
+}
+
+func newDefaultsTree() *defaultsTree {
+	tr := &defaultsTree{}
+	run := func(cmd *cobra.Command, args []string) error {
+		tr.ran = cmd.CommandPath()
+		return nil
+	}
+	tr.root = &cobra.Command{Use: "vsb", RunE: run}
+	tr.root.SetUsageTemplate(withConfigDefaults(tr.root.UsageTemplate()))
+	tr.root.PersistentFlags().StringVarP(&tr.output, "output", "o", "", "Output format")
+	tr.root.Flags().StringVar(&tr.metrics, "metrics-file", "", "Metrics file")
+
+	email := &cobra.Command{Use: "email"}
+	wait := &cobra.Command{Use: "wait", Aliases: []string{"w"}, RunE: run}
+	wait.Flags().StringVar(&tr.timeout, "timeout", "30s", "Maximum time to wait")
+	wait.Flags().IntVar(&tr.count, "count", 1, "Number of emails")
+	wait.Flags().StringArrayVar(&tr.inboxes, "inbox", nil, "Inbox to watch")
+	list := &cobra.Command{Use: "list", RunE: run}
+	list.Flags().BoolVar(&tr.wide, "wide", false, "Do not truncate")
+
+	email.AddCommand(wait, list)
+	tr.root.AddCommand(email)
+	return tr
+}
+
+func flagDefault(key string, values ...string) config.FlagDefault {
+	parts := strings.Split(key, ".")
+	return config.FlagDefault{Key: key, Command: parts[:len(parts)-1], Flag: parts[len(parts)-1], Values: values}
+}
+
+// execute applies defaults for args and runs them
+func (tr *defaultsTree) execute(t *testing.T, defaults []config.FlagDefault, args ...string) string {
+	t.Helper()
+	var warnings bytes.Buffer
+	applyFlagDefaults(tr.root, args, defaults, &warnings)
+	tr.root.SetArgs(args)
+	tr.root.SetOut(&bytes.Buffer{})
+	require.NoError(t, tr.root.Execute())
+	return warnings.String()
+}
+
+func TestApplyFlagDefaults(t *testing.T) {
+	t.Run("config default replaces the built-in default", func(t *testing.T) {
+		tr := newDefaultsTree()
+		warnings := tr.execute(t, []config.FlagDefault{flagDefault("email.wait.timeout", "60s")}, "email", "wait")
+		assert.Empty(t, warnings)
+		assert.Equal(t, "vsb email wait", tr.ran)
+		assert.Equal(t, "60s", tr.timeout)
+	})
+
+	t.Run("flag on the command line wins", func(t *testing.T) {
+		tr := newDefaultsTree()
+		tr.execute(t, []config.FlagDefault{flagDefault("email.wait.timeout", "60s")}, "email", "wait", "--timeout", "5s")
+		assert.Equal(t, "5s", tr.timeout)
+	})
+
+	t.Run("command aliases", func(t *testing.T) {
+		tr := newDefaultsTree()
+		tr.execute(t, []config.FlagDefault{flagDefault("email.w.count", "3")}, "email", "w")
+		assert.Equal(t, 3, tr.count)
+	})
+
+	t.Run("repeatable flag takes a list", func(t *testing.T) {
+		tr := newDefaultsTree()
+		tr.execute(t, []config.FlagDefault{flagDefault("email.wait.inbox", "a@test.com", "b@test.com")}, "email", "wait")
+		assert.Equal(t, []string{"a@test.com", "b@test.com"}, tr.inboxes)
+	})
+
+	t.Run("only the command that runs", func(t *testing.T) {
+		tr := newDefaultsTree()
+		tr.execute(t, []config.FlagDefault{
+			flagDefault("email.wait.timeout", "60s"),
+			flagDefault("email.list.output", "json"),
+		}, "email", "wait")
+		assert.Equal(t, "60s", tr.timeout)
+		assert.Empty(t, tr.output, "a persistent flag is shared, so another command's default must not be set")
+	})
+
+	t.Run("closer command wins for inherited flags", func(t *testing.T) {
+		tr := newDefaultsTree()
+		defaults := []config.FlagDefault{
+			flagDefault("email.wait.output", "pretty"),
+			flagDefault("output", "json"),
+		}
+		tr.execute(t, defaults, "email", "wait")
+		assert.Equal(t, "pretty", tr.output)
+
+		tr = newDefaultsTree()
+		tr.execute(t, defaults, "email", "list")
+		assert.Equal(t, "json", tr.output)
+	})
+
+	t.Run("local flag of a parent is not inherited", func(t *testing.T) {
+		tr := newDefaultsTree()
+		warnings := tr.execute(t, []config.FlagDefault{flagDefault("metrics-file", "stats.json")}, "email", "list")
+		assert.Empty(t, warnings)
+		assert.Empty(t, tr.metrics)
+	})
+
+	t.Run("warnings name the bad entry", func(t *testing.T) {
+		tr := newDefaultsTree()
+		warnings := tr.execute(t, []config.FlagDefault{
+			flagDefault("wait.timeout", "60s"),
+			flagDefault("email.list.limit", "20"),
+			{Key: "email.wait.count", Command: []string{"email", "wait"}, Flag: "count"},
+			flagDefault("email.wait.count", "many"),
+			flagDefault("email.list.wide", "true"),
+		}, "email", "wait")
+
+		assert.Contains(t, warnings, "Warning: ignoring config defaults.wait.timeout: unknown command 'vsb wait'\n")
+		assert.Contains(t, warnings, "Warning: ignoring config defaults.email.list.limit: unknown flag --limit for 'vsb email list'\n")
+		assert.Contains(t, warnings, "Warning: ignoring config defaults.email.wait.count: no value\n")
+		assert.Contains(t, warnings, `Warning: ignoring config defaults.email.wait.count: invalid value "many" for --count`)
+		assert.NotContains(t, warnings, "email.list.wide")
+		assert.Equal(t, 1, tr.count)
+	})
+}
+
+func TestFlagDefaultsHelp(t *testing.T) {
+	tr := newDefaultsTree()
+	var warnings bytes.Buffer
+	args := []string{"help", "email", "wait"}
+	applyFlagDefaults(tr.root, args, []config.FlagDefault{
+		flagDefault("email.wait.timeout", "60s"),
+		flagDefault("email.wait.count", "0"),
+		flagDefault("output", "json"),
+	}, &warnings)
+	require.Empty(t, warnings.String())
+
+	var out bytes.Buffer
+	tr.root.SetArgs(args)
+	tr.root.SetOut(&out)
+	require.NoError(t, tr.root.Execute())
+
+	help := out.String()
+	assert.Regexp(t, `--timeout string +Maximum time to wait \(default "60s"\) \(from config\)\n`, help)
+	assert.Regexp(t, `--count int +Number of emails \(default 0\) \(from config\)\n`, help)
+	assert.Regexp(t, `-o, --output string +Output format \(default "json"\) \(from config\)\n`, help)
+	assert.Regexp(t, `--inbox stringArray +Inbox to watch\n`, help)
+}
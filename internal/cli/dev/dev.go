@@ -0,0 +1,17 @@
+package dev
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// Cmd is the dev parent command. It is hidden from 'vsb --help' because its
+// subcommands are for working on vsb and VaultSandbox itself, not for
+// everyday use.
+var Cmd = &cobra.Command{
+	Use:    "dev",
+	Short:  "Development utilities",
+	Hidden: true,
+	Long: `Utilities for local development against a VaultSandbox gateway and its
+SMTP endpoint. These commands are not part of the stable CLI and may change
+between releases.`,
+}
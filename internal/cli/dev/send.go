@@ -0,0 +1,230 @@
+package dev
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"net/smtp"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/vaultsandbox/vsb-cli/internal/cliutil"
+	"github.com/vaultsandbox/vsb-cli/internal/email"
+	"github.com/vaultsandbox/vsb-cli/internal/styles"
+)
+
+var sendCmd = &cobra.Command{
+	Use:   "send",
+	Short: "Send a test email over SMTP",
+	Long: `Send a test email to an inbox through an SMTP server, for exercising
+'vsb watch', 'vsb email wait' and the dashboard during development.
+
+This is a development utility: it talks plain SMTP without authentication,
+which is what a local or dev gateway accepts. It is not meant for sending
+mail through real providers.
+
+The recipient defaults to the active inbox. The SMTP server defaults to
+$SMTP_HOST and $SMTP_PORT (as used by the e2e tests), or localhost:25.
+
+Use --text - to read the text body from stdin. --html takes a file. Repeat
+--attach to attach several files. With --count, the subject of each message
+gets a " (n/N)" suffix.
+
+Examples:
+  vsb dev send --subject "Hello" --text "Testing 1 2 3"
+  vsb dev send --to abc@inbox.vsx.email --html welcome.html --attach invoice.pdf
+  git log -1 | vsb dev send --subject "Last commit" --text -
+  vsb dev send --count 20 --interval 500ms --smtp-host localhost --smtp-port 2525`,
+	Args: cobra.NoArgs,
+	RunE: runSend,
+}
+
+var (
+	sendTo       string
+	sendFrom     string
+	sendSubject  string
+	sendText     string
+	sendHTMLFile string
+	sendAttach   []string
+	sendSMTPHost string
+	sendSMTPPort int
+	sendCount    int
+	sendInterval time.Duration
+)
+
+// sendMailFunc is a variable for smtp.SendMail that can be overridden in tests
+var sendMailFunc = smtp.SendMail
+
+// stdin is read for --text - (overridden in tests)
+var stdin io.Reader = os.Stdin
+
+func init() {
+	Cmd.AddCommand(sendCmd)
+	cliutil.AddOutputFormats(sendCmd, cliutil.FormatJSON)
+
+	sendCmd.Flags().StringVar(&sendTo, "to", "",
+		"Recipient address (default: active inbox)")
+	sendCmd.Flags().StringVar(&sendFrom, "from", "vsb-dev@localhost",
+		"Sender address")
+	sendCmd.Flags().StringVar(&sendSubject, "subject", "vsb dev send",
+		"Subject line")
+	sendCmd.Flags().StringVar(&sendText, "text", "",
+		"Plain text body, or - to read it from stdin")
+	sendCmd.Flags().StringVar(&sendHTMLFile, "html", "",
+		"File with the HTML body")
+	sendCmd.Flags().StringArrayVar(&sendAttach, "attach", nil,
+		"File to attach (repeatable)")
+	sendCmd.Flags().StringVar(&sendSMTPHost, "smtp-host", "",
+		"SMTP server host (default: $SMTP_HOST or localhost)")
+	sendCmd.Flags().IntVar(&sendSMTPPort, "smtp-port", 0,
+		"SMTP server port (default: $SMTP_PORT or 25)")
+	sendCmd.Flags().IntVar(&sendCount, "count", 1,
+		"Number of emails to send")
+	sendCmd.Flags().DurationVar(&sendInterval, "interval", time.Second,
+		"Delay between emails with --count")
+}
+
+func runSend(cmd *cobra.Command, args []string) error {
+	if sendCount < 1 {
+		return fmt.Errorf("--count must be at least 1")
+	}
+	if sendInterval < 0 {
+		return fmt.Errorf("--interval must not be negative")
+	}
+
+	addr, err := smtpAddr(sendSMTPHost, sendSMTPPort, os.Getenv("SMTP_HOST"), os.Getenv("SMTP_PORT"))
+	if err != nil {
+		return err
+	}
+
+	to := sendTo
+	if to == "" {
+		ks, err := cliutil.LoadKeystoreOrError()
+		if err != nil {
+			return err
+		}
+		stored, err := cliutil.GetInbox(ks, "")
+		if err != nil {
+			return fmt.Errorf("%w (or pass --to)", err)
+		}
+		to = stored.Email
+	}
+
+	msg, err := buildMessage(to)
+	if err != nil {
+		return err
+	}
+
+	ctx := cliutil.CommandContext(cmd)
+	jsonOutput := cliutil.GetOutput(cmd) == cliutil.FormatJSON
+	var messageIDs []string
+
+	for i := 1; i <= sendCount; i++ {
+		if i > 1 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(sendInterval):
+			}
+		}
+
+		m := msg
+		m.Date = time.Now()
+		m.MessageID = fmt.Sprintf("<%d.%d@vsb-dev>", m.Date.UnixNano(), i)
+		if sendCount > 1 {
+			m.Subject = fmt.Sprintf("%s (%d/%d)", msg.Subject, i, sendCount)
+		}
+		raw, err := email.Compose(m)
+		if err != nil {
+			return fmt.Errorf("failed to compose email: %w", err)
+		}
+		if err := sendMailFunc(addr, nil, m.From, []string{to}, raw); err != nil {
+			return fmt.Errorf("failed to send email %d via %s: %w", i, addr, err)
+		}
+		messageIDs = append(messageIDs, m.MessageID)
+
+		if !jsonOutput {
+			fmt.Println(styles.PassStyle.Render(fmt.Sprintf("✓ Sent %q to %s via %s", m.Subject, to, addr)))
+		}
+	}
+
+	if jsonOutput {
+		return cliutil.OutputJSON(map[string]interface{}{
+			"to":         to,
+			"server":     addr,
+			"sent":       len(messageIDs),
+			"messageIds": messageIDs,
+		})
+	}
+	return nil
+}
+
+// buildMessage assembles the message from the flags, reading the body and
+// attachment files. Every email sent with --count is a copy of it.
+func buildMessage(to string) (email.Message, error) {
+	m := email.Message{
+		From:    sendFrom,
+		To:      to,
+		Subject: sendSubject,
+		Text:    sendText,
+	}
+
+	if sendText == "-" {
+		data, err := io.ReadAll(stdin)
+		if err != nil {
+			return m, fmt.Errorf("failed to read body from stdin: %w", err)
+		}
+		m.Text = string(data)
+	}
+
+	if sendHTMLFile != "" {
+		data, err := os.ReadFile(sendHTMLFile)
+		if err != nil {
+			return m, fmt.Errorf("failed to read --html file: %w", err)
+		}
+		m.HTML = string(data)
+	}
+
+	for _, path := range sendAttach {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return m, fmt.Errorf("failed to read attachment: %w", err)
+		}
+		m.Attachments = append(m.Attachments, email.Attachment{Filename: path, Data: data})
+	}
+
+	if m.Text == "" && m.HTML == "" {
+		m.Text = "Sent by vsb dev send at " + time.Now().Format(time.RFC3339)
+	}
+	return m, nil
+}
+
+// smtpAddr resolves the SMTP server from the flags, falling back to the
+// SMTP_HOST and SMTP_PORT environment variables and then localhost:25.
+func smtpAddr(flagHost string, flagPort int, envHost, envPort string) (string, error) {
+	host := flagHost
+	if host == "" {
+		host = envHost
+	}
+	if host == "" {
+		host = "localhost"
+	}
+
+	port := flagPort
+	if port == 0 && envPort != "" {
+		p, err := strconv.Atoi(envPort)
+		if err != nil {
+			return "", fmt.Errorf("invalid SMTP_PORT %q", envPort)
+		}
+		port = p
+	}
+	if port == 0 {
+		port = 25
+	}
+	if port < 1 || port > 65535 {
+		return "", fmt.Errorf("invalid SMTP port %d", port)
+	}
+	return net.JoinHostPort(host, strconv.Itoa(port)), nil
+}
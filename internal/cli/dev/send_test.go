@@ -0,0 +1,146 @@
+package dev
+
+import (
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSMTPAddr(t *testing.T) {
+	tests := []struct {
+		name     string
+		flagHost string
+		flagPort int
+		envHost  string
+		envPort  string
+		want     string
+		wantErr  bool
+	}{
+		{name: "defaults", want: "localhost:25"},
+		{name: "from env", envHost: "smtp.test", envPort: "2525", want: "smtp.test:2525"},
+		{name: "flags beat env", flagHost: "mx.local", flagPort: 587, envHost: "smtp.test", envPort: "2525", want: "mx.local:587"},
+		{name: "IPv6 host", flagHost: "::1", flagPort: 2525, want: "[::1]:2525"},
+		{name: "invalid env port", envPort: "smtp", wantErr: true},
+		{name: "port out of range", flagPort: 70000, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := smtpAddr(tt.flagHost, tt.flagPort, tt.envHost, tt.envPort)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestBuildMessage(t *testing.T) {
+	defer resetSendFlags()
+
+	t.Run("body from stdin", func(t *testing.T) {
+		resetSendFlags()
+		sendText = "-"
+		stdin = strings.NewReader("piped body\n")
+
+		m, err := buildMessage("abc@inbox.test")
+		require.NoError(t, err)
+		assert.Equal(t, "piped body\n", m.Text)
+		assert.Equal(t, "abc@inbox.test", m.To)
+	})
+
+	t.Run("HTML file and attachments", func(t *testing.T) {
+		resetSendFlags()
+		dir := t.TempDir()
+		htmlPath := filepath.Join(dir, "body.html")
+		require.NoError(t, os.WriteFile(htmlPath, []byte("<p>hi</p>"), 0600))
+		pdfPath := filepath.Join(dir, "a.pdf")
+		require.NoError(t, os.WriteFile(pdfPath, []byte("%PDF"), 0600))
+		sendHTMLFile = htmlPath
+		sendAttach = []string{pdfPath, htmlPath}
+
+		m, err := buildMessage("abc@inbox.test")
+		require.NoError(t, err)
+		assert.Equal(t, "<p>hi</p>", m.HTML)
+		assert.Empty(t, m.Text)
+		require.Len(t, m.Attachments, 2)
+		assert.Equal(t, []byte("%PDF"), m.Attachments[0].Data)
+	})
+
+	t.Run("default body", func(t *testing.T) {
+		resetSendFlags()
+
+		m, err := buildMessage("abc@inbox.test")
+		require.NoError(t, err)
+		assert.Contains(t, m.Text, "Sent by vsb dev send")
+	})
+
+	t.Run("missing attachment", func(t *testing.T) {
+		resetSendFlags()
+		sendAttach = []string{filepath.Join(t.TempDir(), "missing.pdf")}
+
+		_, err := buildMessage("abc@inbox.test")
+		assert.ErrorContains(t, err, "failed to read attachment")
+	})
+}
+
+func TestRunSend(t *testing.T) {
+	defer resetSendFlags()
+	original := sendMailFunc
+	defer func() { sendMailFunc = original }()
+
+	type sent struct {
+		addr string
+		to   []string
+		msg  string
+	}
+	var got []sent
+	sendMailFunc = func(addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+		got = append(got, sent{addr, to, string(msg)})
+		return nil
+	}
+
+	resetSendFlags()
+	sendTo = "abc@inbox.test"
+	sendSubject = "Load"
+	sendSMTPHost = "smtp.test"
+	sendSMTPPort = 2525
+	sendCount = 3
+	sendInterval = 0
+
+	require.NoError(t, runSend(&cobra.Command{}, nil))
+	require.Len(t, got, 3)
+	for i, s := range got {
+		assert.Equal(t, "smtp.test:2525", s.addr)
+		assert.Equal(t, []string{"abc@inbox.test"}, s.to)
+		assert.Contains(t, s.msg, "Subject: Load ("+string(rune('1'+i))+"/3)")
+	}
+
+	t.Run("invalid count", func(t *testing.T) {
+		sendCount = 0
+		assert.ErrorContains(t, runSend(&cobra.Command{}, nil), "--count")
+	})
+}
+
+func resetSendFlags() {
+	sendTo = ""
+	sendFrom = "vsb-dev@localhost"
+	sendSubject = "vsb dev send"
+	sendText = ""
+	sendHTMLFile = ""
+	sendAttach = nil
+	sendSMTPHost = ""
+	sendSMTPPort = 0
+	sendCount = 1
+	sendInterval = time.Second
+	stdin = os.Stdin
+}
@@ -0,0 +1,343 @@
+package email
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/spf13/cobra"
+	vaultsandbox "github.com/vaultsandbox/client-go"
+	"github.com/vaultsandbox/vsb-cli/internal/cliutil"
+	mailfile "github.com/vaultsandbox/vsb-cli/internal/email"
+	"github.com/vaultsandbox/vsb-cli/internal/styles"
+)
+
+// getArchiveEmailFunc fetches one full email for the archive; overridden in
+// tests
+var getArchiveEmailFunc = func(ctx context.Context, inbox *vaultsandbox.Inbox, emailID string) (*vaultsandbox.Email, error) {
+	return inbox.GetEmail(ctx, emailID)
+}
+
+var archiveCmd = &cobra.Command{
+	Use:   "archive",
+	Short: "Export every email in an inbox to an mbox or JSON archive",
+	Long: `Download every email in an inbox into a single portable archive file.
+
+--format mbox (the default) writes Unix mbox (mboxrd) that mail clients can
+import, using the raw RFC 5322 source of each email. When the server has no
+raw source for an email, or with --include-attachments=false, the message is
+rebuilt from its parsed headers, bodies and attachments instead.
+
+--format json writes a JSON array with one object per email: headers,
+bodies, links, authentication results and attachments, with attachment
+content base64-encoded.
+
+Emails are written in received order as they are downloaded, so large
+inboxes are never held in memory. The archive is created with 0600
+permissions and an existing file is never overwritten. It defaults to
+<inbox>.mbox or <inbox>.json in the current directory.
+
+Examples:
+  vsb email archive --out archive.mbox
+  vsb email archive --inbox abc@vsx.email --format json --out archive.json
+  vsb email archive --since 24h --include-attachments=false
+  vsb email archive --since 2026-01-13T00:00:00Z --until 2026-01-14T00:00:00Z`,
+	Args: cobra.NoArgs,
+	RunE: runArchive,
+}
+
+var (
+	archiveOut                string
+	archiveFormat             string
+	archiveSince              string
+	archiveUntil              string
+	archiveIncludeAttachments bool
+)
+
+func init() {
+	Cmd.AddCommand(archiveCmd)
+	cliutil.AddOutputFormats(archiveCmd, cliutil.FormatJSON)
+
+	archiveCmd.Flags().StringVar(&archiveOut, "out", "",
+		"Archive file path (default: <inbox>.mbox or <inbox>.json)")
+	archiveCmd.Flags().StringVar(&archiveFormat, "format", "mbox",
+		"Archive format: mbox or json")
+	archiveCmd.Flags().StringVar(&archiveSince, "since", "",
+		"Only archive emails received at or after this time (duration like 2h, or RFC 3339)")
+	archiveCmd.Flags().StringVar(&archiveUntil, "until", "",
+		"Only archive emails received at or before this time (duration like 30m, or RFC 3339)")
+	archiveCmd.Flags().BoolVar(&archiveIncludeAttachments, "include-attachments", true,
+		"Include attachment content in the archive")
+}
+
+// archiveSummary counts what was written to an archive
+type archiveSummary struct {
+	Emails        int
+	Attachments   int
+	Reconstructed int // mbox messages rebuilt because the raw source was missing
+}
+
+// archiveWriter appends emails to an archive file one at a time. raw is the
+// RFC 5322 source, or empty if it is not available.
+type archiveWriter interface {
+	Add(email *vaultsandbox.Email, raw string) (reconstructed bool, err error)
+	Close() error
+}
+
+func runArchive(cmd *cobra.Command, args []string) error {
+	ctx := cliutil.CommandContext(cmd)
+
+	if archiveFormat != "mbox" && archiveFormat != "json" {
+		return fmt.Errorf("invalid --format: %s (valid: mbox, json)", archiveFormat)
+	}
+	since, until, err := parseTimeWindow(archiveSince, archiveUntil, time.Now())
+	if err != nil {
+		return err
+	}
+
+	inbox, cleanup, err := cliutil.LoadAndImportInbox(ctx, InboxFlag)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	metadata, err := inbox.GetEmailsMetadataOnly(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get emails: %w", err)
+	}
+	metadata = filterMetadataByTimeWindow(metadata, since, until)
+	sort.SliceStable(metadata, func(i, j int) bool { return metadata[i].ReceivedAt.Before(metadata[j].ReceivedAt) })
+
+	name := archiveOut
+	if name == "" {
+		name = cliutil.SanitizeFilename(inbox.EmailAddress()) + "." + archiveFormat
+	}
+	path, err := filepath.Abs(name)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		if os.IsExist(err) {
+			return fmt.Errorf("file already exists: %s", path)
+		}
+		return fmt.Errorf("failed to create archive: %w", err)
+	}
+
+	counter := &countingWriter{w: f}
+	summary, err := writeArchive(counter, metadata, archiveFormat, archiveIncludeAttachments,
+		func(id string) (*vaultsandbox.Email, error) { return getArchiveEmailFunc(ctx, inbox, id) },
+		func(id string) (string, error) { return getRawEmailFunc(ctx, inbox, id) })
+	if closeErr := f.Close(); err == nil && closeErr != nil {
+		err = fmt.Errorf("failed to write archive: %w", closeErr)
+	}
+	if err != nil {
+		os.Remove(path)
+		return err
+	}
+
+	if cliutil.GetOutput(cmd) == cliutil.FormatJSON {
+		return cliutil.OutputJSON(map[string]interface{}{
+			"path":          path,
+			"format":        archiveFormat,
+			"emails":        summary.Emails,
+			"attachments":   summary.Attachments,
+			"reconstructed": summary.Reconstructed,
+			"bytes":         counter.n,
+		})
+	}
+
+	fmt.Println(styles.PassStyle.Render(fmt.Sprintf("✓ Archived %d email(s) to %s", summary.Emails, path)))
+	attachments := fmt.Sprintf("%d", summary.Attachments)
+	if !archiveIncludeAttachments {
+		attachments = "not included"
+	}
+	fmt.Printf("  Attachments: %s\n", attachments)
+	fmt.Printf("  Size:        %s\n", humanize.Bytes(uint64(counter.n)))
+	if summary.Reconstructed > 0 {
+		fmt.Printf("  Rebuilt:     %d email(s) without raw source\n", summary.Reconstructed)
+	}
+	return nil
+}
+
+// writeArchive fetches each email in turn and appends it to w in format.
+// The raw source is only fetched for mbox archives that keep attachments.
+func writeArchive(w io.Writer, metadata []*vaultsandbox.EmailMetadata, format string, includeAttachments bool,
+	fetch func(id string) (*vaultsandbox.Email, error), fetchRaw func(id string) (string, error)) (archiveSummary, error) {
+	var summary archiveSummary
+	bw := bufio.NewWriter(w)
+
+	var archive archiveWriter
+	if format == "json" {
+		archive = &jsonArchive{w: bw, includeAttachments: includeAttachments}
+	} else {
+		archive = &mboxArchive{w: bw, includeAttachments: includeAttachments}
+	}
+
+	for _, m := range metadata {
+		email, err := fetch(m.ID)
+		if err != nil {
+			return summary, fmt.Errorf("failed to get email %s: %w", m.ID, err)
+		}
+
+		var raw string
+		if format == "mbox" && includeAttachments {
+			raw, err = fetchRaw(m.ID)
+			if err != nil && !errors.Is(err, vaultsandbox.ErrEmailNotFound) {
+				return summary, fmt.Errorf("failed to get raw email %s: %w", m.ID, err)
+			}
+		}
+
+		reconstructed, err := archive.Add(email, raw)
+		if err != nil {
+			return summary, fmt.Errorf("failed to write email %s: %w", m.ID, err)
+		}
+		summary.Emails++
+		if includeAttachments {
+			summary.Attachments += len(email.Attachments)
+			if reconstructed {
+				summary.Reconstructed++
+			}
+		}
+	}
+
+	if err := archive.Close(); err != nil {
+		return summary, err
+	}
+	return summary, bw.Flush()
+}
+
+// mboxArchive writes emails in mboxrd format
+type mboxArchive struct {
+	w                  io.Writer
+	includeAttachments bool
+}
+
+func (a *mboxArchive) Add(email *vaultsandbox.Email, raw string) (bool, error) {
+	reconstructed := raw == ""
+	if reconstructed {
+		data, err := reconstructEmail(email, a.includeAttachments)
+		if err != nil {
+			return false, err
+		}
+		raw = string(data)
+	}
+	return reconstructed, mailfile.WriteMbox(a.w, email.From, email.ReceivedAt, raw)
+}
+
+func (a *mboxArchive) Close() error { return nil }
+
+// jsonArchive writes a JSON array one element at a time
+type jsonArchive struct {
+	w                  io.Writer
+	includeAttachments bool
+	n                  int
+}
+
+func (a *jsonArchive) Add(email *vaultsandbox.Email, raw string) (bool, error) {
+	data, err := json.MarshalIndent(cliutil.EmailJSON(email, cliutil.EmailJSONOptions{
+		IncludeTo:                true,
+		IncludeBody:              true,
+		IncludeLinks:             true,
+		IncludeHeaders:           true,
+		IncludeAuthResults:       true,
+		IncludeAttachments:       true,
+		IncludeAttachmentContent: a.includeAttachments,
+	}), "  ", "  ")
+	if err != nil {
+		return false, err
+	}
+
+	sep := ",\n  "
+	if a.n == 0 {
+		sep = "[\n  "
+	}
+	a.n++
+	if _, err := io.WriteString(a.w, sep); err != nil {
+		return false, err
+	}
+	_, err = a.w.Write(data)
+	return false, err
+}
+
+func (a *jsonArchive) Close() error {
+	end := "\n]\n"
+	if a.n == 0 {
+		end = "[]\n"
+	}
+	_, err := io.WriteString(a.w, end)
+	return err
+}
+
+// reconstructEmail rebuilds an RFC 5322 message from a parsed email, for
+// emails whose raw source is unavailable or whose attachments are dropped.
+func reconstructEmail(email *vaultsandbox.Email, includeAttachments bool) ([]byte, error) {
+	m := mailfile.Message{
+		From:      email.From,
+		To:        strings.Join(email.To, ", "),
+		Subject:   email.Subject,
+		Date:      email.ReceivedAt,
+		MessageID: lookupHeader(email.Headers, "Message-ID"),
+		Text:      email.Text,
+		HTML:      email.HTML,
+	}
+	if includeAttachments {
+		for _, a := range email.Attachments {
+			m.Attachments = append(m.Attachments, mailfile.Attachment{
+				Filename:    a.Filename,
+				ContentType: a.ContentType,
+				Data:        a.Content,
+			})
+		}
+	}
+	return mailfile.Compose(m)
+}
+
+// lookupHeader looks up a header case-insensitively
+func lookupHeader(headers map[string]string, name string) string {
+	for k, v := range headers {
+		if strings.EqualFold(k, name) {
+			return v
+		}
+	}
+	return ""
+}
+
+// filterMetadataByTimeWindow is filterByTimeWindow for email metadata.
+func filterMetadataByTimeWindow(metadata []*vaultsandbox.EmailMetadata, since, until time.Time) []*vaultsandbox.EmailMetadata {
+	if since.IsZero() && until.IsZero() {
+		return metadata
+	}
+	var result []*vaultsandbox.EmailMetadata
+	for _, m := range metadata {
+		if !since.IsZero() && m.ReceivedAt.Before(since) {
+			continue
+		}
+		if !until.IsZero() && m.ReceivedAt.After(until) {
+			continue
+		}
+		result = append(result, m)
+	}
+	return result
+}
+
+// countingWriter counts the bytes written through it
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,152 @@
+package email
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	vaultsandbox "github.com/vaultsandbox/client-go"
+)
+
+func archiveFixtures() ([]*vaultsandbox.EmailMetadata, map[string]*vaultsandbox.Email) {
+	first := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	emails := map[string]*vaultsandbox.Email{
+		"one": {
+			ID: "one", From: "Alice <alice@example.com>", To: []string{"inbox@vsx.email"},
+			Subject: "Hello", ReceivedAt: first,
+			Text: "From the top\nFrom here on\n>From quoted\n",
+			Attachments: []vaultsandbox.Attachment{
+				{Filename: "a.txt", ContentType: "text/plain", Size: 3, Content: []byte("abc")},
+			},
+		},
+		"two": {
+			ID: "two", From: "bob@example.com", To: []string{"inbox@vsx.email"},
+			Subject: "Second", ReceivedAt: first.Add(time.Hour), Text: "hi",
+			Headers: map[string]string{"message-id": "<two@example.com>"},
+		},
+	}
+	metadata := []*vaultsandbox.EmailMetadata{
+		{ID: "one", From: emails["one"].From, ReceivedAt: emails["one"].ReceivedAt},
+		{ID: "two", From: emails["two"].From, ReceivedAt: emails["two"].ReceivedAt},
+	}
+	return metadata, emails
+}
+
+func fetchFrom(emails map[string]*vaultsandbox.Email) func(string) (*vaultsandbox.Email, error) {
+	return func(id string) (*vaultsandbox.Email, error) {
+		if e, ok := emails[id]; ok {
+			return e, nil
+		}
+		return nil, fmt.Errorf("not found")
+	}
+}
+
+func TestWriteArchiveMbox(t *testing.T) {
+	metadata, emails := archiveFixtures()
+	raw := func(id string) (string, error) {
+		if id == "one" {
+			return "", vaultsandbox.ErrEmailNotFound
+		}
+		return "Subject: Second\r\n\r\nFrom the raw body\r\n", nil
+	}
+
+	var buf bytes.Buffer
+	summary, err := writeArchive(&buf, metadata, "mbox", true, fetchFrom(emails), raw)
+	require.NoError(t, err)
+	assert.Equal(t, archiveSummary{Emails: 2, Attachments: 1, Reconstructed: 1}, summary)
+
+	out := buf.String()
+	assert.True(t, strings.HasPrefix(out, "From alice@example.com Sun Mar  1 12:00:00 2026\n"))
+	assert.Contains(t, out, "\nFrom bob@example.com Sun Mar  1 13:00:00 2026\nSubject: Second\n\n>From the raw body\n\n")
+	assert.Contains(t, out, "\n>From the top\n>From here on\n>>From quoted\n")
+	assert.Contains(t, out, "filename=a.txt")
+
+	// Only the separator lines may start with "From " unquoted
+	var separators int
+	for _, line := range strings.Split(out, "\n") {
+		if strings.HasPrefix(line, "From ") {
+			separators++
+		}
+	}
+	assert.Equal(t, 2, separators)
+}
+
+func TestWriteArchiveMboxWithoutAttachments(t *testing.T) {
+	metadata, emails := archiveFixtures()
+	raw := func(id string) (string, error) {
+		t.Fatalf("raw source fetched for %s", id)
+		return "", nil
+	}
+
+	var buf bytes.Buffer
+	summary, err := writeArchive(&buf, metadata, "mbox", false, fetchFrom(emails), raw)
+	require.NoError(t, err)
+	assert.Equal(t, archiveSummary{Emails: 2}, summary)
+	assert.NotContains(t, buf.String(), "a.txt")
+	assert.Contains(t, buf.String(), "Message-ID: <two@example.com>")
+}
+
+func TestWriteArchiveJSON(t *testing.T) {
+	metadata, emails := archiveFixtures()
+	noRaw := func(string) (string, error) { return "", fmt.Errorf("unexpected") }
+
+	var buf bytes.Buffer
+	summary, err := writeArchive(&buf, metadata, "json", true, fetchFrom(emails), noRaw)
+	require.NoError(t, err)
+	assert.Equal(t, archiveSummary{Emails: 2, Attachments: 1}, summary)
+
+	var got []map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &got))
+	require.Len(t, got, 2)
+	assert.Equal(t, "one", got[0]["id"])
+	attachments := got[0]["attachments"].([]interface{})
+	assert.Equal(t, "YWJj", attachments[0].(map[string]interface{})["content"])
+
+	t.Run("without attachments", func(t *testing.T) {
+		buf.Reset()
+		_, err := writeArchive(&buf, metadata, "json", false, fetchFrom(emails), noRaw)
+		require.NoError(t, err)
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &got))
+		attachments := got[0]["attachments"].([]interface{})
+		assert.NotContains(t, attachments[0], "content")
+		assert.Equal(t, "a.txt", attachments[0].(map[string]interface{})["filename"])
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		buf.Reset()
+		_, err := writeArchive(&buf, nil, "json", true, fetchFrom(emails), noRaw)
+		require.NoError(t, err)
+		assert.Equal(t, "[]\n", buf.String())
+	})
+}
+
+func TestWriteArchiveErrors(t *testing.T) {
+	metadata, emails := archiveFixtures()
+
+	var buf bytes.Buffer
+	_, err := writeArchive(&buf, []*vaultsandbox.EmailMetadata{{ID: "missing"}}, "json", true, fetchFrom(emails), nil)
+	assert.EqualError(t, err, "failed to get email missing: not found")
+
+	_, err = writeArchive(&buf, metadata, "mbox", true, fetchFrom(emails), func(string) (string, error) {
+		return "", fmt.Errorf("connection reset")
+	})
+	assert.EqualError(t, err, "failed to get raw email one: connection reset")
+}
+
+func TestFilterMetadataByTimeWindow(t *testing.T) {
+	metadata, _ := archiveFixtures()
+	base := metadata[0].ReceivedAt
+
+	assert.Len(t, filterMetadataByTimeWindow(metadata, time.Time{}, time.Time{}), 2)
+	got := filterMetadataByTimeWindow(metadata, base.Add(time.Minute), time.Time{})
+	require.Len(t, got, 1)
+	assert.Equal(t, "two", got[0].ID)
+	got = filterMetadataByTimeWindow(metadata, time.Time{}, base)
+	require.Len(t, got, 1)
+	assert.Equal(t, "one", got[0].ID)
+}
@@ -1,15 +1,21 @@
 package email
 
 import (
-	"context"
+	"bytes"
 	"fmt"
+	"io"
+	"os"
+	"strings"
 
 	"github.com/dustin/go-humanize"
 	"github.com/spf13/cobra"
 	vaultsandbox "github.com/vaultsandbox/client-go"
 	"github.com/vaultsandbox/vsb-cli/internal/cliutil"
+	"github.com/vaultsandbox/vsb-cli/internal/config"
 	"github.com/vaultsandbox/vsb-cli/internal/files"
+	"github.com/vaultsandbox/vsb-cli/internal/format"
 	"github.com/vaultsandbox/vsb-cli/internal/styles"
+	"golang.org/x/term"
 )
 
 var attachmentCmd = &cobra.Command{
@@ -20,6 +26,18 @@ var attachmentCmd = &cobra.Command{
 By default, lists all attachments with their index, filename, type, and size.
 Use --save to download a specific attachment by its index number.
 Use --all to download all attachments at once.
+Use --extract-to-stdout to write raw attachment bytes to stdout for piping,
+or --stdout N as a shorthand for the Nth attachment.
+
+Saving shows a progress line on stderr when it is a terminal (not with
+--output json or --quiet). --max-size, or the attachment-max-size config
+key, refuses attachments larger than a size such as 10MB unless --force is
+given.
+
+Both only cover writing the attachment to disk or stdout: the server sends
+attachments as part of the email, so by the time they are saved they have
+already been downloaded in full. The limit keeps large files off disk and
+out of pipes; it does not reduce the network transfer.
 
 Examples:
   vsb email attachment              # List attachments from latest email
@@ -27,19 +45,42 @@ Examples:
   vsb email attachment --save 1     # Download first attachment
   vsb email attachment --all        # Download all attachments
   vsb email attachment --all -d ./downloads  # Download to specific directory
-  vsb email attachment -o json      # JSON output for scripting`,
+  vsb email attachment -o json      # JSON output for scripting
+  vsb email attachment --extract-to-stdout --index 1 > file.bin
+  vsb email attachment --extract-to-stdout --by-name report.csv | csvlook
+  vsb email attachment --stdout 1 | pdftotext - -
+  vsb email attachment --all --max-size 10MB
+  vsb email attachment --save 1 --max-size 10MB --force`,
 	Args: cobra.MaximumNArgs(1),
 	RunE: runAttachment,
 }
 
 var (
-	attachmentSave int
-	attachmentAll  bool
-	attachmentDir  string
+	attachmentSave     int
+	attachmentAll      bool
+	attachmentDir      string
+	attachmentToStdout bool
+	attachmentIndex    int
+	attachmentByName   string
+	attachmentDecode64 bool
+	attachmentStdout   int
+	attachmentMaxSize  string
+	attachmentForce    bool
+	attachmentQuiet    bool
+
+	// Set by runAttachment from the flags and config
+	attachmentSizeLimit    int64 // 0 for no limit
+	attachmentShowProgress bool
 )
 
+// stderrIsTerminal reports whether stderr is a terminal; overridden in tests
+var stderrIsTerminal = func() bool {
+	return term.IsTerminal(int(os.Stderr.Fd()))
+}
+
 func init() {
 	Cmd.AddCommand(attachmentCmd)
+	cliutil.AddOutputFormats(attachmentCmd, cliutil.FormatJSON)
 
 	attachmentCmd.Flags().IntVarP(&attachmentSave, "save", "s", 0,
 		"Download the Nth attachment (1=first, 0=don't download)")
@@ -47,23 +88,78 @@ func init() {
 		"Download all attachments")
 	attachmentCmd.Flags().StringVarP(&attachmentDir, "dir", "d", ".",
 		"Directory to save attachments (default: current directory)")
+	attachmentCmd.Flags().BoolVar(&attachmentToStdout, "extract-to-stdout", false,
+		"Write raw attachment bytes to stdout")
+	attachmentCmd.Flags().IntVar(&attachmentIndex, "index", 0,
+		"Attachment to extract by index (1=first)")
+	attachmentCmd.Flags().StringVar(&attachmentByName, "by-name", "",
+		"Attachment to extract by filename")
+	attachmentCmd.Flags().BoolVar(&attachmentDecode64, "decode-base64", false,
+		"Decode base64-encoded attachment content when extracting")
+	attachmentCmd.Flags().IntVar(&attachmentStdout, "stdout", 0,
+		"Write the Nth attachment's raw bytes to stdout (1=first)")
+	attachmentCmd.Flags().StringVar(&attachmentMaxSize, "max-size", "",
+		"Refuse to save attachments larger than this, e.g. 10MB (default: attachment-max-size config, else no limit)")
+	attachmentCmd.Flags().BoolVar(&attachmentForce, "force", false,
+		"Save attachments larger than --max-size")
+	attachmentCmd.Flags().BoolVarP(&attachmentQuiet, "quiet", "q", false,
+		"Don't show download progress or saved files")
+
+	attachmentCmd.MarkFlagsMutuallyExclusive("index", "by-name")
+	attachmentCmd.MarkFlagsMutuallyExclusive("extract-to-stdout", "save")
+	attachmentCmd.MarkFlagsMutuallyExclusive("extract-to-stdout", "all")
+	attachmentCmd.MarkFlagsMutuallyExclusive("stdout", "extract-to-stdout", "save", "all", "index", "by-name")
 }
 
 func runAttachment(cmd *cobra.Command, args []string) error {
-	ctx := context.Background()
+	ctx := cliutil.CommandContext(cmd)
 
 	emailID := cliutil.GetArg(args, 0, "")
 
+	stdoutIndex := cmd.Flags().Changed("stdout")
+	if (attachmentToStdout || stdoutIndex) && cliutil.GetOutput(cmd) == cliutil.FormatJSON {
+		name := "--extract-to-stdout"
+		if stdoutIndex {
+			name = "--stdout"
+		}
+		// JSON may also be the configured default output
+		if flag := cmd.Flag("output"); flag == nil || !flag.Changed {
+			return fmt.Errorf("%s cannot be used with JSON output (from VSB_OUTPUT or default_output); use --output text", name)
+		}
+		return fmt.Errorf("%s cannot be used with --output json", name)
+	}
+
+	limit, err := attachmentLimit()
+	if err != nil {
+		return err
+	}
+	attachmentSizeLimit = limit
+	attachmentShowProgress = !attachmentQuiet && cliutil.GetOutput(cmd) != cliutil.FormatJSON && stderrIsTerminal()
+
 	// Use shared helper
-	email, _, cleanup, err := cliutil.GetEmailByIDOrLatest(ctx, emailID, InboxFlag)
+	email, _, cleanup, err := getEmailByIDOrLatestFunc(ctx, emailID, InboxFlag)
 	if err != nil {
 		return err
 	}
 	defer cleanup()
 
+	// Write a single attachment to stdout
+	if stdoutIndex {
+		if len(email.Attachments) == 0 {
+			return fmt.Errorf("no attachments found in email")
+		}
+		if attachmentStdout < 1 || attachmentStdout > len(email.Attachments) {
+			return fmt.Errorf("attachment index %d out of range (1-%d)", attachmentStdout, len(email.Attachments))
+		}
+		return extractAttachment(os.Stdout, email.Attachments, attachmentStdout, "")
+	}
+	if attachmentToStdout {
+		return extractAttachment(os.Stdout, email.Attachments, attachmentIndex, attachmentByName)
+	}
+
 	// Check for attachments
 	if len(email.Attachments) == 0 {
-		if cliutil.GetOutput(cmd) == "json" {
+		if cliutil.GetOutput(cmd) == cliutil.FormatJSON {
 			return cliutil.OutputJSON([]struct{}{})
 		}
 		fmt.Println("No attachments found in email")
@@ -81,11 +177,14 @@ func runAttachment(cmd *cobra.Command, args []string) error {
 			return fmt.Errorf("attachment index %d out of range (1-%d)", attachmentSave, len(email.Attachments))
 		}
 		att := email.Attachments[attachmentSave-1]
+		if err := checkAttachmentSize(&att); err != nil {
+			return err
+		}
 		return downloadAttachment(att.Filename, att.Content)
 	}
 
 	// Default: list all attachments
-	if cliutil.GetOutput(cmd) == "json" {
+	if cliutil.GetOutput(cmd) == cliutil.FormatJSON {
 		// Build JSON-friendly output (without binary content)
 		type attachmentInfo struct {
 			Index       int    `json:"index"`
@@ -120,20 +219,119 @@ func runAttachment(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// extractAttachment writes the selected attachment's bytes to w. The
+// attachment is chosen by index or filename; if the email has exactly one
+// attachment, neither is required.
+func extractAttachment(w io.Writer, attachments []vaultsandbox.Attachment, index int, name string) error {
+	att, err := selectAttachment(attachments, index, name)
+	if err != nil {
+		return err
+	}
+	if err := checkAttachmentSize(att); err != nil {
+		return err
+	}
+
+	content := att.Content
+	if attachmentDecode64 {
+		content, _ = format.DecodeBase64Bytes(content, false)
+	}
+
+	_, err = w.Write(content)
+	return err
+}
+
+func selectAttachment(attachments []vaultsandbox.Attachment, index int, name string) (*vaultsandbox.Attachment, error) {
+	if len(attachments) == 0 {
+		return nil, fmt.Errorf("no attachments found in email")
+	}
+
+	if name != "" {
+		for i := range attachments {
+			if attachments[i].Filename == name {
+				return &attachments[i], nil
+			}
+		}
+		for i := range attachments {
+			if strings.EqualFold(attachments[i].Filename, name) {
+				return &attachments[i], nil
+			}
+		}
+		return nil, fmt.Errorf("attachment not found: %s", name)
+	}
+
+	if index == 0 {
+		if len(attachments) > 1 {
+			return nil, fmt.Errorf("email has %d attachments; choose one with --index or --by-name", len(attachments))
+		}
+		index = 1
+	}
+	if index < 1 || index > len(attachments) {
+		return nil, fmt.Errorf("attachment index %d out of range (1-%d)", index, len(attachments))
+	}
+	return &attachments[index-1], nil
+}
+
+// attachmentLimit returns the size limit from --max-size, or else the
+// attachment-max-size config key. --force lifts it.
+func attachmentLimit() (int64, error) {
+	value, source := attachmentMaxSize, "--max-size"
+	if value == "" {
+		value, source = config.GetAttachmentMaxSize(), "attachment-max-size"
+	}
+	if value == "" {
+		return 0, nil
+	}
+	limit, err := config.ParseByteSize(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %w", source, err)
+	}
+	if attachmentForce {
+		return 0, nil
+	}
+	return limit, nil
+}
+
+// checkAttachmentSize refuses an attachment larger than the size limit
+func checkAttachmentSize(att *vaultsandbox.Attachment) error {
+	size := int64(len(att.Content))
+	if attachmentSizeLimit > 0 && size > attachmentSizeLimit {
+		return fmt.Errorf("attachment %s is %s, larger than the %s limit (use --force to download it anyway)",
+			att.Filename, humanize.Bytes(uint64(size)), humanize.Bytes(uint64(attachmentSizeLimit)))
+	}
+	return nil
+}
+
+// downloadAttachment streams content to a file in --dir, showing progress
+// on stderr when enabled.
 func downloadAttachment(filename string, content []byte) error {
-	path, err := files.SaveFile(attachmentDir, filename, content)
+	var r io.Reader = bytes.NewReader(content)
+	var progress *cliutil.Progress
+	if attachmentShowProgress {
+		progress = cliutil.NewProgress(os.Stderr, filename, int64(len(content)))
+		r = io.TeeReader(r, progress)
+	}
+	path, n, err := files.SaveReader(attachmentDir, filename, r)
+	if progress != nil {
+		progress.Finish()
+	}
 	if err != nil {
 		return err
 	}
 
-	fmt.Println(styles.PassStyle.Render(fmt.Sprintf("✓ Saved: %s (%s)", path, humanize.Bytes(uint64(len(content))))))
+	if !attachmentQuiet {
+		fmt.Println(styles.PassStyle.Render(fmt.Sprintf("✓ Saved: %s (%s)", path, humanize.Bytes(uint64(n)))))
+	}
 	return nil
 }
 
 func downloadAllAttachments(attachments []vaultsandbox.Attachment) error {
 	saved := 0
 	for _, att := range attachments {
-		if err := downloadAttachment(att.Filename, att.Content); err != nil {
+		err := checkAttachmentSize(&att)
+		if err == nil {
+			err = downloadAttachment(att.Filename, att.Content)
+		}
+		if err != nil {
 			fmt.Println(styles.FailStyle.Render(fmt.Sprintf("✗ Failed to save %s: %v", att.Filename, err)))
 		} else {
 			saved++
@@ -141,7 +339,9 @@ func downloadAllAttachments(attachments []vaultsandbox.Attachment) error {
 	}
 
 	if saved == len(attachments) {
-		fmt.Printf("\n%s\n", styles.PassStyle.Render(fmt.Sprintf("✓ Downloaded all %d attachments", saved)))
+		if !attachmentQuiet {
+			fmt.Printf("\n%s\n", styles.PassStyle.Render(fmt.Sprintf("✓ Downloaded all %d attachments", saved)))
+		}
 	} else {
 		fmt.Printf("\nDownloaded %d of %d attachments\n", saved, len(attachments))
 	}
@@ -1,13 +1,18 @@
 package email
 
 import (
+	"bytes"
+	"io"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
+	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	vaultsandbox "github.com/vaultsandbox/client-go"
+	"github.com/vaultsandbox/vsb-cli/internal/cliutil"
 )
 
 func TestDownloadAttachment(t *testing.T) {
@@ -192,3 +197,325 @@ func TestDownloadAllAttachments(t *testing.T) {
 		assert.NoError(t, err) // function returns nil even on partial failure
 	})
 }
+
+func TestExtractAttachment(t *testing.T) {
+	attachments := []vaultsandbox.Attachment{
+		{Filename: "report.csv", Content: []byte("a,b\n1,2\n")},
+		{Filename: "image.png", Content: []byte{0x89, 0x50, 0x4E, 0x47, 0x00, 0xFF}},
+	}
+
+	t.Run("writes bytes by index", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, extractAttachment(&buf, attachments, 2, ""))
+		assert.Equal(t, attachments[1].Content, buf.Bytes())
+	})
+
+	t.Run("writes bytes by name", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, extractAttachment(&buf, attachments, 0, "report.csv"))
+		assert.Equal(t, "a,b\n1,2\n", buf.String())
+	})
+
+	t.Run("name match falls back to case-insensitive", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, extractAttachment(&buf, attachments, 0, "REPORT.CSV"))
+		assert.Equal(t, "a,b\n1,2\n", buf.String())
+	})
+
+	t.Run("index out of range", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := extractAttachment(&buf, attachments, 3, "")
+		assert.EqualError(t, err, "attachment index 3 out of range (1-2)")
+		assert.Empty(t, buf.Bytes())
+	})
+
+	t.Run("unknown name", func(t *testing.T) {
+		err := extractAttachment(&bytes.Buffer{}, attachments, 0, "missing.txt")
+		assert.EqualError(t, err, "attachment not found: missing.txt")
+	})
+
+	t.Run("requires selection with multiple attachments", func(t *testing.T) {
+		err := extractAttachment(&bytes.Buffer{}, attachments, 0, "")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "--index or --by-name")
+	})
+
+	t.Run("single attachment needs no selection", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, extractAttachment(&buf, attachments[:1], 0, ""))
+		assert.Equal(t, "a,b\n1,2\n", buf.String())
+	})
+
+	t.Run("decodes base64 content when requested", func(t *testing.T) {
+		attachmentDecode64 = true
+		defer func() { attachmentDecode64 = false }()
+
+		encoded := []vaultsandbox.Attachment{{Filename: "a.txt", Content: []byte("SGVsbG8sIGF0dGFjaG1lbnQgd29ybGQh")}}
+		var buf bytes.Buffer
+		require.NoError(t, extractAttachment(&buf, encoded, 1, ""))
+		assert.Equal(t, "Hello, attachment world!", buf.String())
+	})
+}
+
+func TestRunAttachmentExtractToStdout(t *testing.T) {
+	oldFetcher := getEmailByIDOrLatestFunc
+	defer func() {
+		getEmailByIDOrLatestFunc = oldFetcher
+		attachmentToStdout = false
+		attachmentIndex = 0
+	}()
+
+	email := &vaultsandbox.Email{
+		ID:          "email-1",
+		Attachments: []vaultsandbox.Attachment{{Filename: "data.bin", Content: []byte{0x00, 0x01, 0x02}}},
+	}
+	getEmailByIDOrLatestFunc = mockEmailFetcher(email, nil)
+	attachmentToStdout = true
+	attachmentIndex = 1
+
+	newCmd := func() *cobra.Command {
+		cmd := &cobra.Command{Use: "test", RunE: runAttachment}
+		cmd.Flags().StringP("output", "o", "", "Output format")
+		return cmd
+	}
+
+	t.Run("writes attachment bytes to stdout", func(t *testing.T) {
+		output := captureURLStdout(t, func() {
+			require.NoError(t, runAttachment(newCmd(), []string{}))
+		})
+		assert.Equal(t, []byte{0x00, 0x01, 0x02}, []byte(output))
+	})
+
+	t.Run("rejects json output", func(t *testing.T) {
+		cmd := newCmd()
+		require.NoError(t, cmd.Flags().Set("output", "json"))
+		err := runAttachment(cmd, []string{})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot be used with --output json")
+	})
+
+	t.Run("out of range index fails", func(t *testing.T) {
+		attachmentIndex = 5
+		defer func() { attachmentIndex = 1 }()
+		err := runAttachment(newCmd(), []string{})
+		assert.EqualError(t, err, "attachment index 5 out of range (1-1)")
+	})
+}
+
+func TestRunAttachmentStdoutIndex(t *testing.T) {
+	oldFetcher := getEmailByIDOrLatestFunc
+	defer func() {
+		getEmailByIDOrLatestFunc = oldFetcher
+		attachmentStdout = 0
+	}()
+
+	email := &vaultsandbox.Email{
+		ID: "email-1",
+		Attachments: []vaultsandbox.Attachment{
+			{Filename: "a.txt", Content: []byte("first")},
+			{Filename: "b.pdf", Content: []byte("%PDF\x00\xff")},
+		},
+	}
+	getEmailByIDOrLatestFunc = mockEmailFetcher(email, nil)
+
+	newCmd := func(index string) *cobra.Command {
+		cmd := &cobra.Command{Use: "test", RunE: runAttachment}
+		cmd.Flags().StringP("output", "o", "", "Output format")
+		cmd.Flags().IntVar(&attachmentStdout, "stdout", 0, "")
+		require.NoError(t, cmd.Flags().Set("stdout", index))
+		return cmd
+	}
+
+	t.Run("writes selected attachment without trailing newline", func(t *testing.T) {
+		output := captureURLStdout(t, func() {
+			require.NoError(t, runAttachment(newCmd("2"), []string{}))
+		})
+		assert.Equal(t, "%PDF\x00\xff", output)
+	})
+
+	t.Run("out of range index fails", func(t *testing.T) {
+		for _, index := range []string{"0", "3"} {
+			err := runAttachment(newCmd(index), []string{})
+			assert.EqualError(t, err, "attachment index "+index+" out of range (1-2)")
+		}
+	})
+
+	t.Run("rejects json output", func(t *testing.T) {
+		cmd := newCmd("1")
+		require.NoError(t, cmd.Flags().Set("output", "json"))
+		assert.EqualError(t, runAttachment(cmd, []string{}), "--stdout cannot be used with --output json")
+	})
+
+	t.Run("rejects configured json output", func(t *testing.T) {
+		t.Setenv("VSB_OUTPUT", "json")
+		cmd := newCmd("1")
+		cliutil.AddOutputFormats(cmd, cliutil.FormatJSON)
+		assert.EqualError(t, runAttachment(cmd, []string{}),
+			"--stdout cannot be used with JSON output (from VSB_OUTPUT or default_output); use --output text")
+
+		require.NoError(t, cmd.Flags().Set("output", "text"))
+		output := captureURLStdout(t, func() {
+			require.NoError(t, runAttachment(cmd, []string{}))
+		})
+		assert.Equal(t, "first", output)
+	})
+}
+
+func TestAttachmentLimit(t *testing.T) {
+	reset := func(t *testing.T) {
+		oldSize, oldForce := attachmentMaxSize, attachmentForce
+		t.Cleanup(func() { attachmentMaxSize, attachmentForce = oldSize, oldForce })
+		attachmentMaxSize, attachmentForce = "", false
+		t.Setenv("VSB_ATTACHMENT_MAX_SIZE", "")
+	}
+
+	t.Run("no limit by default", func(t *testing.T) {
+		reset(t)
+		limit, err := attachmentLimit()
+		require.NoError(t, err)
+		assert.Zero(t, limit)
+	})
+
+	t.Run("flag", func(t *testing.T) {
+		reset(t)
+		attachmentMaxSize = "10MB"
+		limit, err := attachmentLimit()
+		require.NoError(t, err)
+		assert.Equal(t, int64(10_000_000), limit)
+	})
+
+	t.Run("flag overrides config", func(t *testing.T) {
+		reset(t)
+		t.Setenv("VSB_ATTACHMENT_MAX_SIZE", "1KiB")
+		attachmentMaxSize = "2KiB"
+		limit, err := attachmentLimit()
+		require.NoError(t, err)
+		assert.Equal(t, int64(2048), limit)
+	})
+
+	t.Run("config", func(t *testing.T) {
+		reset(t)
+		t.Setenv("VSB_ATTACHMENT_MAX_SIZE", "1KiB")
+		limit, err := attachmentLimit()
+		require.NoError(t, err)
+		assert.Equal(t, int64(1024), limit)
+	})
+
+	t.Run("force lifts the limit", func(t *testing.T) {
+		reset(t)
+		attachmentMaxSize, attachmentForce = "10MB", true
+		limit, err := attachmentLimit()
+		require.NoError(t, err)
+		assert.Zero(t, limit)
+	})
+
+	t.Run("invalid flag", func(t *testing.T) {
+		reset(t)
+		attachmentMaxSize = "lots"
+		_, err := attachmentLimit()
+		assert.ErrorContains(t, err, "invalid --max-size")
+	})
+
+	t.Run("invalid config", func(t *testing.T) {
+		reset(t)
+		t.Setenv("VSB_ATTACHMENT_MAX_SIZE", "lots")
+		_, err := attachmentLimit()
+		assert.ErrorContains(t, err, "invalid attachment-max-size")
+	})
+}
+
+func TestAttachmentSizeLimit(t *testing.T) {
+	setLimit := func(t *testing.T, limit int64) {
+		old := attachmentSizeLimit
+		t.Cleanup(func() { attachmentSizeLimit = old })
+		attachmentSizeLimit = limit
+	}
+
+	t.Run("refuses oversize attachments with their size", func(t *testing.T) {
+		setLimit(t, 1000)
+		err := checkAttachmentSize(&vaultsandbox.Attachment{Filename: "big.zip", Content: make([]byte, 2000)})
+		require.Error(t, err)
+		assert.Equal(t, "attachment big.zip is 2.0 kB, larger than the 1.0 kB limit (use --force to download it anyway)", err.Error())
+	})
+
+	t.Run("allows attachments at the limit", func(t *testing.T) {
+		setLimit(t, 1000)
+		assert.NoError(t, checkAttachmentSize(&vaultsandbox.Attachment{Filename: "ok.txt", Content: make([]byte, 1000)}))
+	})
+
+	t.Run("download all skips oversize attachments", func(t *testing.T) {
+		setLimit(t, 10)
+		dir := t.TempDir()
+		oldDir := attachmentDir
+		attachmentDir = dir
+		defer func() { attachmentDir = oldDir }()
+
+		var err error
+		out := captureStdout(t, func() {
+			err = downloadAllAttachments([]vaultsandbox.Attachment{
+				{Filename: "small.txt", Content: []byte("small")},
+				{Filename: "big.bin", Content: make([]byte, 100)},
+			})
+		})
+		require.NoError(t, err)
+
+		assert.FileExists(t, filepath.Join(dir, "small.txt"))
+		assert.NoFileExists(t, filepath.Join(dir, "big.bin"))
+		assert.Contains(t, out, "Failed to save big.bin")
+		assert.Contains(t, out, "Downloaded 1 of 2 attachments")
+	})
+
+	t.Run("extract refuses oversize attachments", func(t *testing.T) {
+		setLimit(t, 10)
+		var buf bytes.Buffer
+		err := extractAttachment(&buf, []vaultsandbox.Attachment{{Filename: "big.bin", Content: make([]byte, 100)}}, 1, "")
+		assert.ErrorContains(t, err, "larger than the 10 B limit")
+		assert.Zero(t, buf.Len())
+	})
+}
+
+func TestDownloadAttachmentProgress(t *testing.T) {
+	dir := t.TempDir()
+	oldDir, oldProgress, oldQuiet := attachmentDir, attachmentShowProgress, attachmentQuiet
+	defer func() { attachmentDir, attachmentShowProgress, attachmentQuiet = oldDir, oldProgress, oldQuiet }()
+	attachmentDir = dir
+
+	captureStderr := func(f func()) string {
+		old := os.Stderr
+		r, w, err := os.Pipe()
+		require.NoError(t, err)
+		os.Stderr = w
+		f()
+		w.Close()
+		os.Stderr = old
+		data, err := io.ReadAll(r)
+		require.NoError(t, err)
+		return string(data)
+	}
+
+	t.Run("shows progress on stderr", func(t *testing.T) {
+		attachmentShowProgress, attachmentQuiet = true, false
+		var stdout string
+		stderr := captureStderr(func() {
+			stdout = captureStdout(t, func() {
+				require.NoError(t, downloadAttachment("report.pdf", make([]byte, 4000)))
+			})
+		})
+		assert.Contains(t, stderr, "report.pdf  4.0 kB / 4.0 kB (100%)")
+		assert.True(t, strings.HasSuffix(stderr, "\r\033[K"), "progress line is cleared")
+		assert.Contains(t, stdout, "Saved:")
+	})
+
+	t.Run("quiet shows nothing", func(t *testing.T) {
+		attachmentShowProgress, attachmentQuiet = false, true
+		var stdout string
+		stderr := captureStderr(func() {
+			stdout = captureStdout(t, func() {
+				require.NoError(t, downloadAttachment("quiet.txt", []byte("content")))
+			})
+		})
+		assert.Empty(t, stderr)
+		assert.Empty(t, stdout)
+		assert.FileExists(t, filepath.Join(dir, "quiet.txt"))
+	})
+}
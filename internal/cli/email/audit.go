@@ -1,12 +1,12 @@
 package email
 
 import (
-	"context"
 	"fmt"
 	"strings"
 
 	"github.com/spf13/cobra"
 	vaultsandbox "github.com/vaultsandbox/client-go"
+	"github.com/vaultsandbox/vsb-cli/internal/analysis"
 	"github.com/vaultsandbox/vsb-cli/internal/cliutil"
 	"github.com/vaultsandbox/vsb-cli/internal/styles"
 )
@@ -21,23 +21,72 @@ Proves the "Production Fidelity" of the email flow by displaying:
 - Transport Security: TLS version and cipher suite
 - MIME Structure: Headers, body parts, and attachments
 
+With -o sarif, failing SPF/DKIM/DMARC/reverse DNS results, suspicious
+links and a security score below 80 are reported as SARIF 2.1.0 results.
+
+With --explain, each SPF, DKIM or DMARC check that did not pass is
+explained: the domain that was checked, whether the record is missing, the
+signature broke or the From domain is not aligned, what the DNS record
+needs to contain, and a link to the specification. JSON output gains an
+"explanations" array.
+
+To use the audit as a regression gate, save a known-good email's audit
+with --save-baseline, then check later emails with --compare-baseline.
+The command lists what changed and exits non-zero when SPF, DKIM or DMARC
+got worse (e.g. pass to softfail or fail) or the security score dropped
+more than --tolerance points below the baseline's. Reverse DNS changes are
+listed but do not fail the check. A baseline is the audit's JSON output,
+so a file saved with -o json works too.
+
 Examples:
   vsb email audit              # Audit most recent email
   vsb email audit abc123       # Audit specific email
-  vsb email audit -o json      # JSON output for scripting`,
+  vsb email audit -o json      # JSON output for scripting
+  vsb email audit --explain    # Explain failing SPF/DKIM/DMARC checks
+  vsb email audit --save-baseline audit-baseline.json
+  vsb email audit --compare-baseline audit-baseline.json --tolerance 5
+  vsb email audit -o sarif     # SARIF 2.1.0 for code scanning dashboards`,
 	Args: cobra.MaximumNArgs(1),
 	RunE: runAudit,
 }
 
+var (
+	auditExplain         bool
+	auditCompareBaseline string
+	auditSaveBaseline    string
+	auditTolerance       int
+)
+
 func init() {
 	Cmd.AddCommand(auditCmd)
+	cliutil.AddOutputFormats(auditCmd, cliutil.FormatJSON, cliutil.FormatSARIF)
+
+	auditCmd.Flags().BoolVar(&auditExplain, "explain", false,
+		"Explain failing SPF, DKIM and DMARC checks and how to fix them")
+	auditCmd.Flags().StringVar(&auditCompareBaseline, "compare-baseline", "",
+		"Fail if authentication is worse than in this saved audit")
+	auditCmd.Flags().StringVar(&auditSaveBaseline, "save-baseline", "",
+		"Save this audit as a baseline for --compare-baseline")
+	auditCmd.Flags().IntVar(&auditTolerance, "tolerance", 0,
+		"Points the security score may drop below the baseline's")
+	auditCmd.MarkFlagsMutuallyExclusive("compare-baseline", "save-baseline")
 }
 
 func runAudit(cmd *cobra.Command, args []string) error {
-	ctx := context.Background()
+	ctx := cliutil.CommandContext(cmd)
 
 	emailID := cliutil.GetArg(args, 0, "")
 
+	if auditTolerance < 0 {
+		return fmt.Errorf("invalid --tolerance %d: must not be negative", auditTolerance)
+	}
+	if cmd.Flags().Changed("tolerance") && auditCompareBaseline == "" {
+		return fmt.Errorf("--tolerance requires --compare-baseline")
+	}
+	if auditCompareBaseline != "" && cliutil.GetOutput(cmd) == cliutil.FormatSARIF {
+		return fmt.Errorf("--compare-baseline cannot be used with -o sarif")
+	}
+
 	// Use shared helper to get email
 	email, _, cleanup, err := cliutil.GetEmailByIDOrLatest(ctx, emailID, InboxFlag)
 	if err != nil {
@@ -45,14 +94,38 @@ func runAudit(cmd *cobra.Command, args []string) error {
 	}
 	defer cleanup()
 
+	var comparison *baselineComparison
+	if auditCompareBaseline != "" {
+		if comparison, err = compareBaseline(email, auditCompareBaseline, auditTolerance); err != nil {
+			return err
+		}
+	}
+
 	// Render audit report
-	if cliutil.GetOutput(cmd) == "json" {
-		return renderAuditJSON(email)
+	switch cliutil.GetOutput(cmd) {
+	case cliutil.FormatJSON:
+		err = renderAuditJSON(email, auditExplain, comparison)
+	case cliutil.FormatSARIF:
+		err = renderAuditSARIF(email, cmd.Root().Version)
+	default:
+		err = renderAuditReport(email, auditExplain, comparison)
+	}
+	if err != nil {
+		return err
 	}
-	return renderAuditReport(email)
+
+	if auditSaveBaseline != "" {
+		if err := saveAuditBaseline(email, auditSaveBaseline); err != nil {
+			return err
+		}
+		if cliutil.GetOutput(cmd) == cliutil.FormatPretty {
+			fmt.Printf("Saved baseline to %s\n", auditSaveBaseline)
+		}
+	}
+	return comparison.err()
 }
 
-func renderAuditReport(email *vaultsandbox.Email) error {
+func renderAuditReport(email *vaultsandbox.Email, explain bool, comparison *baselineComparison) error {
 	labelStyle := styles.LabelStyle
 
 	// Title
@@ -73,6 +146,12 @@ func renderAuditReport(email *vaultsandbox.Email) error {
 	fmt.Println()
 	fmt.Println(styles.SectionStyle.Render("AUTHENTICATION"))
 	fmt.Println(styles.RenderAuthResults(email.AuthResults, labelStyle, true))
+	if explain {
+		if explanations := analysis.ExplainAuth(email); len(explanations) > 0 {
+			fmt.Println()
+			fmt.Println(styles.RenderAuthExplanations(explanations))
+		}
+	}
 
 	// Transport Security
 	fmt.Println()
@@ -103,6 +182,10 @@ func renderAuditReport(email *vaultsandbox.Email) error {
 	fmt.Println(styles.BoxStyle.Render(summary))
 	fmt.Println()
 
+	if comparison != nil {
+		fmt.Println(renderBaselineComparison(comparison))
+	}
+
 	return nil
 }
 
@@ -155,7 +238,18 @@ func buildMIMETree(email *vaultsandbox.Email) string {
 	return sb.String()
 }
 
-func renderAuditJSON(email *vaultsandbox.Email) error {
-	return cliutil.OutputJSON(cliutil.EmailAuditJSON(email))
+func renderAuditJSON(email *vaultsandbox.Email, explain bool, comparison *baselineComparison) error {
+	data := cliutil.EmailAuditJSON(email)
+	if comparison != nil {
+		data["baseline"] = comparison
+	}
+	if explain {
+		explanations := analysis.ExplainAuth(email)
+		if explanations == nil {
+			explanations = []analysis.AuthExplanation{}
+		}
+		data["explanations"] = explanations
+	}
+	return cliutil.OutputJSON(data)
 }
 
@@ -0,0 +1,252 @@
+package email
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	vaultsandbox "github.com/vaultsandbox/client-go"
+	"github.com/vaultsandbox/vsb-cli/internal/cliutil"
+	"github.com/vaultsandbox/vsb-cli/internal/styles"
+)
+
+// ErrAuthRegression is returned by 'email audit --compare-baseline' when
+// authentication results are worse than the baseline's.
+var ErrAuthRegression = errors.New("authentication regressed from the baseline")
+
+// Statuses of a baselineChange
+const (
+	changeRegressed = "regressed"
+	changeImproved  = "improved"
+	changeChanged   = "changed"
+)
+
+// auditBaseline is the part of an 'email audit -o json' document that is
+// compared. Baselines are saved in exactly that format, so either can be
+// used.
+type auditBaseline struct {
+	AuthResults *struct {
+		SPF        *baselineCheck `json:"spf"`
+		DKIM       *baselineCheck `json:"dkim"`
+		DMARC      *baselineCheck `json:"dmarc"`
+		ReverseDNS *baselineCheck `json:"reverseDns"`
+	} `json:"authResults"`
+	SecurityScore *int `json:"securityScore"`
+}
+
+type baselineCheck struct {
+	Result string `json:"result"`
+}
+
+// checks returns the result of each mechanism in a fixed order, "" for a
+// missing one
+func (b *auditBaseline) checks() [][2]string {
+	result := func(c *baselineCheck) string {
+		if c == nil {
+			return ""
+		}
+		return strings.ToLower(c.Result)
+	}
+	var spf, dkim, dmarc, reverseDNS string
+	if b.AuthResults != nil {
+		spf = result(b.AuthResults.SPF)
+		dkim = result(b.AuthResults.DKIM)
+		dmarc = result(b.AuthResults.DMARC)
+		reverseDNS = result(b.AuthResults.ReverseDNS)
+	}
+	return [][2]string{{"spf", spf}, {"dkim", dkim}, {"dmarc", dmarc}, {"reverseDns", reverseDNS}}
+}
+
+// baselineChange is one difference between the baseline and the email
+type baselineChange struct {
+	Check    string      `json:"check"` // spf, dkim, dmarc, reverseDns or securityScore
+	Baseline interface{} `json:"baseline"`
+	Current  interface{} `json:"current"`
+	Status   string      `json:"status"` // regressed, improved or changed
+}
+
+// baselineComparison is the outcome of comparing an email with a baseline
+type baselineComparison struct {
+	File        string           `json:"file"`
+	Tolerance   int              `json:"tolerance"`
+	Changes     []baselineChange `json:"changes"`
+	Regressions int              `json:"regressions"`
+}
+
+// regressionChecks are the mechanisms whose results must not get worse.
+// Reverse DNS changes are reported but do not fail the comparison.
+var regressionChecks = map[string]bool{"spf": true, "dkim": true, "dmarc": true}
+
+// authResultRank orders results from worst to best. A missing result ranks
+// with "none".
+func authResultRank(result string) int {
+	switch result {
+	case "pass", "skipped":
+		return 2
+	case "", "none", "neutral", "softfail":
+		return 1
+	}
+	return 0 // fail, hardfail, temperror, permerror
+}
+
+// loadAuditBaseline reads a baseline written by --save-baseline or -o json
+func loadAuditBaseline(path string) (*auditBaseline, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read baseline: %w", err)
+	}
+	var b auditBaseline
+	if err := json.Unmarshal(data, &b); err != nil {
+		return nil, fmt.Errorf("invalid baseline %s: %w", path, err)
+	}
+	if b.AuthResults == nil && b.SecurityScore == nil {
+		return nil, fmt.Errorf("invalid baseline %s: no authResults or securityScore (save one with --save-baseline)", path)
+	}
+	return &b, nil
+}
+
+// currentAudit returns the email's audit in baseline form, so both sides
+// of the comparison come from the same JSON
+func currentAudit(email *vaultsandbox.Email) (*auditBaseline, error) {
+	data, err := json.Marshal(cliutil.EmailAuditJSON(email))
+	if err != nil {
+		return nil, err
+	}
+	var b auditBaseline
+	if err := json.Unmarshal(data, &b); err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+// compareAudit lists what changed from baseline to current. The security
+// score regresses when it drops more than tolerance points.
+func compareAudit(baseline, current *auditBaseline, tolerance int) []baselineChange {
+	changes := []baselineChange{}
+	currentChecks := current.checks()
+	for i, check := range baseline.checks() {
+		name, was, now := check[0], check[1], currentChecks[i][1]
+		if was == now {
+			continue
+		}
+		status := changeChanged
+		switch {
+		case authResultRank(now) < authResultRank(was) && regressionChecks[name]:
+			status = changeRegressed
+		case authResultRank(now) > authResultRank(was):
+			status = changeImproved
+		}
+		changes = append(changes, baselineChange{Check: name, Baseline: was, Current: now, Status: status})
+	}
+
+	if baseline.SecurityScore != nil && current.SecurityScore != nil && *baseline.SecurityScore != *current.SecurityScore {
+		was, now := *baseline.SecurityScore, *current.SecurityScore
+		status := changeImproved
+		if now < was {
+			status = changeChanged
+			if now < was-tolerance {
+				status = changeRegressed
+			}
+		}
+		changes = append(changes, baselineChange{Check: "securityScore", Baseline: was, Current: now, Status: status})
+	}
+	return changes
+}
+
+// compareBaseline compares the email with the baseline in path
+func compareBaseline(email *vaultsandbox.Email, path string, tolerance int) (*baselineComparison, error) {
+	baseline, err := loadAuditBaseline(path)
+	if err != nil {
+		return nil, err
+	}
+	current, err := currentAudit(email)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &baselineComparison{File: path, Tolerance: tolerance, Changes: compareAudit(baseline, current, tolerance)}
+	for _, change := range c.Changes {
+		if change.Status == changeRegressed {
+			c.Regressions++
+		}
+	}
+	return c, nil
+}
+
+// err returns ErrAuthRegression, with the regressed checks, if any
+func (c *baselineComparison) err() error {
+	if c == nil || c.Regressions == 0 {
+		return nil
+	}
+	var checks []string
+	for _, change := range c.Changes {
+		if change.Status == changeRegressed {
+			checks = append(checks, checkLabel(change.Check))
+		}
+	}
+	return fmt.Errorf("%w: %s", ErrAuthRegression, strings.Join(checks, ", "))
+}
+
+// saveAuditBaseline writes the email's audit JSON to path
+func saveAuditBaseline(email *vaultsandbox.Email, path string) error {
+	data, err := json.MarshalIndent(cliutil.EmailAuditJSON(email), "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to save baseline: %w", err)
+	}
+	return nil
+}
+
+func checkLabel(check string) string {
+	switch check {
+	case "reverseDns":
+		return "Reverse DNS"
+	case "securityScore":
+		return "Security score"
+	}
+	return strings.ToUpper(check)
+}
+
+// renderBaselineComparison renders the BASELINE section of the audit report
+func renderBaselineComparison(c *baselineComparison) string {
+	var sb strings.Builder
+	sb.WriteString(styles.SectionStyle.Render("BASELINE") + " " + styles.MutedStyle.Render(c.File) + "\n")
+	if len(c.Changes) == 0 {
+		sb.WriteString(styles.PassStyle.Render("No changes from the baseline") + "\n")
+		return sb.String()
+	}
+
+	for _, change := range c.Changes {
+		var was, now string
+		if change.Check == "securityScore" {
+			was, now = strconv.Itoa(change.Baseline.(int)), strconv.Itoa(change.Current.(int))
+			if c.Tolerance > 0 {
+				now += fmt.Sprintf(" (tolerance %d)", c.Tolerance)
+			}
+		} else {
+			was, now = baselineResult(change.Baseline.(string)), baselineResult(change.Current.(string))
+		}
+
+		status := styles.MutedStyle.Render(change.Status)
+		switch change.Status {
+		case changeRegressed:
+			status = styles.FailStyle.Render("REGRESSED")
+		case changeImproved:
+			status = styles.PassStyle.Render("improved")
+		}
+		sb.WriteString(fmt.Sprintf("%s %s → %s  %s\n", styles.LabelStyle.Render(checkLabel(change.Check)+":"), was, now, status))
+	}
+	return sb.String()
+}
+
+func baselineResult(result string) string {
+	if result == "" {
+		return "(missing)"
+	}
+	return result
+}
@@ -0,0 +1,180 @@
+package email
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	vaultsandbox "github.com/vaultsandbox/client-go"
+	"github.com/vaultsandbox/client-go/authresults"
+)
+
+// auditEmail returns an email with the given SPF, DKIM and DMARC results
+func auditEmail(spf, dkim, dmarc string) *vaultsandbox.Email {
+	return &vaultsandbox.Email{
+		ID:         "baseline-id",
+		Subject:    "Baseline",
+		From:       "sender@example.com",
+		ReceivedAt: time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC),
+		AuthResults: &authresults.AuthResults{
+			SPF:   &authresults.SPFResult{Result: spf, Domain: "example.com"},
+			DKIM:  []authresults.DKIMResult{{Result: dkim, Domain: "example.com"}},
+			DMARC: &authresults.DMARCResult{Result: dmarc},
+		},
+	}
+}
+
+func TestCompareAudit(t *testing.T) {
+	score := func(n int) *int { return &n }
+	audit := func(spf, dkim, dmarc string, securityScore int) *auditBaseline {
+		b, err := currentAudit(auditEmail(spf, dkim, dmarc))
+		require.NoError(t, err)
+		b.SecurityScore = score(securityScore)
+		return b
+	}
+
+	tests := []struct {
+		name      string
+		baseline  *auditBaseline
+		current   *auditBaseline
+		tolerance int
+		want      []baselineChange
+	}{
+		{
+			name:     "unchanged",
+			baseline: audit("pass", "pass", "pass", 95),
+			current:  audit("pass", "pass", "pass", 95),
+			want:     []baselineChange{},
+		},
+		{
+			name:     "pass to fail regresses",
+			baseline: audit("pass", "pass", "pass", 95),
+			current:  audit("pass", "fail", "pass", 95),
+			want:     []baselineChange{{Check: "dkim", Baseline: "pass", Current: "fail", Status: changeRegressed}},
+		},
+		{
+			name:     "pass to softfail regresses",
+			baseline: audit("pass", "pass", "pass", 95),
+			current:  audit("softfail", "pass", "pass", 95),
+			want:     []baselineChange{{Check: "spf", Baseline: "pass", Current: "softfail", Status: changeRegressed}},
+		},
+		{
+			name:     "fail to pass improves",
+			baseline: audit("pass", "pass", "fail", 95),
+			current:  audit("pass", "pass", "pass", 95),
+			want:     []baselineChange{{Check: "dmarc", Baseline: "fail", Current: "pass", Status: changeImproved}},
+		},
+		{
+			name:     "between failures only changes",
+			baseline: audit("fail", "pass", "pass", 95),
+			current:  audit("temperror", "pass", "pass", 95),
+			want:     []baselineChange{{Check: "spf", Baseline: "fail", Current: "temperror", Status: changeChanged}},
+		},
+		{
+			name:     "skipped counts as pass",
+			baseline: audit("pass", "pass", "pass", 95),
+			current:  audit("skipped", "pass", "pass", 95),
+			want:     []baselineChange{{Check: "spf", Baseline: "pass", Current: "skipped", Status: changeChanged}},
+		},
+		{
+			name:      "score drop within tolerance",
+			baseline:  audit("pass", "pass", "pass", 95),
+			current:   audit("pass", "pass", "pass", 90),
+			tolerance: 5,
+			want:      []baselineChange{{Check: "securityScore", Baseline: 95, Current: 90, Status: changeChanged}},
+		},
+		{
+			name:      "score drop beyond tolerance",
+			baseline:  audit("pass", "pass", "pass", 95),
+			current:   audit("pass", "pass", "pass", 89),
+			tolerance: 5,
+			want:      []baselineChange{{Check: "securityScore", Baseline: 95, Current: 89, Status: changeRegressed}},
+		},
+		{
+			name:     "score rise",
+			baseline: audit("pass", "pass", "pass", 80),
+			current:  audit("pass", "pass", "pass", 95),
+			want:     []baselineChange{{Check: "securityScore", Baseline: 80, Current: 95, Status: changeImproved}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, compareAudit(tt.baseline, tt.current, tt.tolerance))
+		})
+	}
+
+	t.Run("missing mechanism regresses from pass", func(t *testing.T) {
+		current := audit("pass", "pass", "pass", 95)
+		current.AuthResults.DKIM = nil
+		changes := compareAudit(audit("pass", "pass", "pass", 95), current, 0)
+		assert.Equal(t, []baselineChange{{Check: "dkim", Baseline: "pass", Current: "", Status: changeRegressed}}, changes)
+	})
+
+	t.Run("reverse DNS does not regress", func(t *testing.T) {
+		baseline, current := audit("pass", "pass", "pass", 95), audit("pass", "pass", "pass", 95)
+		baseline.AuthResults.ReverseDNS = &baselineCheck{Result: "pass"}
+		current.AuthResults.ReverseDNS = &baselineCheck{Result: "fail"}
+		changes := compareAudit(baseline, current, 0)
+		assert.Equal(t, []baselineChange{{Check: "reverseDns", Baseline: "pass", Current: "fail", Status: changeChanged}}, changes)
+	})
+}
+
+func TestAuditBaselineRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "baseline.json")
+	require.NoError(t, saveAuditBaseline(auditEmail("pass", "pass", "pass"), path))
+
+	t.Run("same results pass", func(t *testing.T) {
+		c, err := compareBaseline(auditEmail("pass", "pass", "pass"), path, 0)
+		require.NoError(t, err)
+		assert.Empty(t, c.Changes)
+		assert.NoError(t, c.err())
+		assert.Contains(t, renderBaselineComparison(c), "No changes from the baseline")
+	})
+
+	t.Run("regression fails with a diff", func(t *testing.T) {
+		c, err := compareBaseline(auditEmail("pass", "fail", "fail"), path, 0)
+		require.NoError(t, err)
+		assert.Equal(t, 3, c.Regressions, "DKIM, DMARC and the score")
+		err = c.err()
+		assert.ErrorIs(t, err, ErrAuthRegression)
+		assert.EqualError(t, err, "authentication regressed from the baseline: DKIM, DMARC, Security score")
+
+		out := renderBaselineComparison(c)
+		assert.Contains(t, out, "pass → fail")
+		assert.Contains(t, out, "95 → 65")
+		assert.Contains(t, out, "REGRESSED")
+	})
+
+	t.Run("JSON output includes the comparison", func(t *testing.T) {
+		c, err := compareBaseline(auditEmail("pass", "fail", "pass"), path, 0)
+		require.NoError(t, err)
+		output := captureStdout(t, func() {
+			require.NoError(t, renderAuditJSON(auditEmail("pass", "fail", "pass"), false, c))
+		})
+		assert.Contains(t, output, `"baseline": {`)
+		assert.Contains(t, output, `"regressions": 2`)
+		assert.Contains(t, output, `"status": "regressed"`)
+	})
+}
+
+func TestLoadAuditBaselineErrors(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, data string) string {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte(data), 0600))
+		return path
+	}
+
+	_, err := loadAuditBaseline(filepath.Join(dir, "missing.json"))
+	assert.ErrorContains(t, err, "failed to read baseline")
+
+	_, err = loadAuditBaseline(write("bad.json", "not json"))
+	assert.ErrorContains(t, err, "invalid baseline")
+
+	_, err = loadAuditBaseline(write("other.json", `{"id": "abc"}`))
+	assert.ErrorContains(t, err, "no authResults or securityScore")
+}
@@ -0,0 +1,263 @@
+package email
+
+import (
+	"fmt"
+	"strings"
+
+	vaultsandbox "github.com/vaultsandbox/client-go"
+	"github.com/vaultsandbox/client-go/authresults"
+	"github.com/vaultsandbox/vsb-cli/internal/analysis"
+	"github.com/vaultsandbox/vsb-cli/internal/cliutil"
+	"github.com/vaultsandbox/vsb-cli/internal/styles"
+)
+
+// SARIF 2.1.0 output for 'email audit -o sarif'. Only the subset of the
+// format that code scanning dashboards read is modelled here.
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifToolURI = "https://github.com/vaultsandbox/vsb-cli"
+)
+
+// SARIF result levels
+const (
+	sarifError   = "error"
+	sarifWarning = "warning"
+)
+
+// Audit rule identifiers, as reported in SARIF ruleId.
+const (
+	ruleSPFNotPass        = "spf-not-pass"
+	ruleDKIMNotPass       = "dkim-not-pass"
+	ruleDMARCNotPass      = "dmarc-not-pass"
+	ruleReverseDNSNotPass = "reverse-dns-not-pass"
+	ruleSuspiciousLink    = "suspicious-link"
+	ruleLowSecurityScore  = "low-security-score"
+)
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version,omitempty"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID                   string            `json:"id"`
+	Name                 string            `json:"name"`
+	ShortDescription     sarifMessage      `json:"shortDescription"`
+	DefaultConfiguration sarifRuleConfig   `json:"defaultConfiguration"`
+	Properties           map[string]string `json:"properties"`
+}
+
+type sarifRuleConfig struct {
+	Level string `json:"level"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID     string                 `json:"ruleId"`
+	Level      string                 `json:"level"`
+	Message    sarifMessage           `json:"message"`
+	Locations  []sarifLocation        `json:"locations"`
+	Properties map[string]interface{} `json:"properties,omitempty"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation  `json:"physicalLocation"`
+	LogicalLocations []sarifLogicalLocation `json:"logicalLocations"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifLogicalLocation struct {
+	Name string `json:"name"`
+	Kind string `json:"kind"`
+}
+
+// auditRules describes every rule a finding can reference. The
+// security-severity property (0-10) is what GitHub code scanning uses to
+// rank findings.
+var auditRules = []sarifRule{
+	newSarifRule(ruleSPFNotPass, "SPFNotPass", "SPF check did not pass", sarifError, "7.0"),
+	newSarifRule(ruleDKIMNotPass, "DKIMNotPass", "No DKIM signature passed verification", sarifError, "7.0"),
+	newSarifRule(ruleDMARCNotPass, "DMARCNotPass", "DMARC check did not pass", sarifError, "6.0"),
+	newSarifRule(ruleReverseDNSNotPass, "ReverseDNSNotPass", "Reverse DNS of the sending server did not pass", sarifWarning, "3.0"),
+	newSarifRule(ruleSuspiciousLink, "SuspiciousLink", "Link redirects through a shortener, IP address or another domain", sarifWarning, "5.0"),
+	newSarifRule(ruleLowSecurityScore, "LowSecurityScore", "Security score is below 80", sarifWarning, "4.0"),
+}
+
+func newSarifRule(id, name, description, level, severity string) sarifRule {
+	return sarifRule{
+		ID:                   id,
+		Name:                 name,
+		ShortDescription:     sarifMessage{Text: description},
+		DefaultConfiguration: sarifRuleConfig{Level: level},
+		Properties:           map[string]string{"security-severity": severity},
+	}
+}
+
+func renderAuditSARIF(email *vaultsandbox.Email, toolVersion string) error {
+	return cliutil.OutputJSON(buildAuditSARIF(email, toolVersion))
+}
+
+// buildAuditSARIF turns the audit of one email into a SARIF log with a
+// single run. An email without findings produces an empty results list.
+func buildAuditSARIF(email *vaultsandbox.Email, toolVersion string) sarifLog {
+	return sarifLog{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs: []sarifRun{{
+			Tool: sarifTool{Driver: sarifDriver{
+				Name:           "vsb",
+				Version:        toolVersion,
+				InformationURI: sarifToolURI,
+				Rules:          auditRules,
+			}},
+			Results: auditFindings(email),
+		}},
+	}
+}
+
+// auditFindings lists the failing authentication mechanisms, suspicious
+// links and a low security score. Mechanisms that hard-fail are errors;
+// anything else short of a pass (softfail, none, missing, ...) is a warning.
+// The score finding is an error below 60 and a warning below 80, matching
+// the colours of the text report.
+func auditFindings(email *vaultsandbox.Email) []sarifResult {
+	score := styles.CalculateScore(email)
+	results := []sarifResult{}
+	add := func(ruleID, level, text string) {
+		results = append(results, sarifResult{
+			RuleID:     ruleID,
+			Level:      level,
+			Message:    sarifMessage{Text: text},
+			Locations:  emailLocations(email),
+			Properties: map[string]interface{}{"securityScore": score},
+		})
+	}
+
+	auth := email.AuthResults
+	if auth == nil {
+		auth = &authresults.AuthResults{}
+	}
+
+	spfResult := ""
+	if auth.SPF != nil {
+		spfResult = auth.SPF.Result
+	}
+	if level := mechanismLevel(spfResult); level != "" {
+		add(ruleSPFNotPass, level, "SPF result is "+resultText(spfResult))
+	}
+
+	dkimPassed := false
+	var dkimResults []string
+	for _, d := range auth.DKIM {
+		if strings.EqualFold(d.Result, "pass") {
+			dkimPassed = true
+		}
+		dkimResults = append(dkimResults, d.Result)
+	}
+	if !dkimPassed {
+		level := sarifWarning
+		for _, r := range dkimResults {
+			if mechanismLevel(r) == sarifError {
+				level = sarifError
+			}
+		}
+		text := "DKIM result is none"
+		if len(dkimResults) > 0 {
+			texts := make([]string, len(dkimResults))
+			for i, r := range dkimResults {
+				texts[i] = resultText(r)
+			}
+			text = "DKIM results are " + strings.Join(texts, ", ")
+		}
+		add(ruleDKIMNotPass, level, text)
+	}
+
+	dmarcResult := ""
+	if auth.DMARC != nil {
+		dmarcResult = auth.DMARC.Result
+	}
+	if level := mechanismLevel(dmarcResult); level != "" {
+		add(ruleDMARCNotPass, level, "DMARC result is "+resultText(dmarcResult))
+	}
+
+	rdnsResult := ""
+	if auth.ReverseDNS != nil {
+		rdnsResult = auth.ReverseDNS.Result
+	}
+	if level := mechanismLevel(rdnsResult); level != "" {
+		// reverse DNS only ever warns: plenty of legitimate senders lack it
+		add(ruleReverseDNSNotPass, sarifWarning, "Reverse DNS result is "+resultText(rdnsResult))
+	}
+
+	for _, link := range analysis.SuspiciousLinks(email) {
+		add(ruleSuspiciousLink, sarifWarning, "Suspicious link: "+link)
+	}
+
+	switch {
+	case score < 60:
+		add(ruleLowSecurityScore, sarifError, fmt.Sprintf("Security score is %d/100", score))
+	case score < 80:
+		add(ruleLowSecurityScore, sarifWarning, fmt.Sprintf("Security score is %d/100", score))
+	}
+
+	return results
+}
+
+// mechanismLevel returns the SARIF level for an authentication result, or
+// "" if it passed.
+func mechanismLevel(result string) string {
+	switch strings.ToLower(result) {
+	case "pass":
+		return ""
+	case "fail", "permerror":
+		return sarifError
+	default:
+		return sarifWarning
+	}
+}
+
+func resultText(result string) string {
+	if result == "" {
+		return "none"
+	}
+	return strings.ToLower(result)
+}
+
+// emailLocations points a finding at the email. The artifact URI matches the
+// <id>.eml file name the dashboard's --save-dir uses.
+func emailLocations(email *vaultsandbox.Email) []sarifLocation {
+	return []sarifLocation{{
+		PhysicalLocation: sarifPhysicalLocation{
+			ArtifactLocation: sarifArtifactLocation{URI: email.ID + ".eml"},
+		},
+		LogicalLocations: []sarifLogicalLocation{{Name: email.ID, Kind: "email"}},
+	}}
+}
@@ -0,0 +1,138 @@
+package email
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	vaultsandbox "github.com/vaultsandbox/client-go"
+	"github.com/vaultsandbox/client-go/authresults"
+)
+
+func passingAuth() *authresults.AuthResults {
+	return &authresults.AuthResults{
+		SPF:        &authresults.SPFResult{Result: "pass"},
+		DKIM:       []authresults.DKIMResult{{Result: "pass"}},
+		DMARC:      &authresults.DMARCResult{Result: "pass"},
+		ReverseDNS: &authresults.ReverseDNSResult{Result: "pass"},
+	}
+}
+
+func ruleIDs(results []sarifResult) []string {
+	ids := make([]string, len(results))
+	for i, r := range results {
+		ids[i] = r.RuleID
+	}
+	return ids
+}
+
+func TestAuditFindings(t *testing.T) {
+	t.Run("clean email has no findings", func(t *testing.T) {
+		results := auditFindings(&vaultsandbox.Email{ID: "e1", AuthResults: passingAuth()})
+		assert.Empty(t, results)
+		assert.NotNil(t, results)
+	})
+
+	t.Run("failing mechanisms", func(t *testing.T) {
+		auth := passingAuth()
+		auth.SPF.Result = "fail"
+		auth.DKIM = []authresults.DKIMResult{{Result: "neutral"}, {Result: "permerror"}}
+		auth.DMARC.Result = "softfail"
+
+		results := auditFindings(&vaultsandbox.Email{ID: "e1", AuthResults: auth})
+
+		// 50 + 5 (reverse DNS) = 55
+		assert.Equal(t, []string{ruleSPFNotPass, ruleDKIMNotPass, ruleDMARCNotPass, ruleLowSecurityScore}, ruleIDs(results))
+		assert.Equal(t, sarifError, results[0].Level)
+		assert.Equal(t, "SPF result is fail", results[0].Message.Text)
+		assert.Equal(t, sarifError, results[1].Level)
+		assert.Equal(t, "DKIM results are neutral, permerror", results[1].Message.Text)
+		assert.Equal(t, sarifWarning, results[2].Level)
+		assert.Equal(t, sarifError, results[3].Level)
+		assert.Equal(t, "Security score is 55/100", results[3].Message.Text)
+		assert.Equal(t, 55, results[0].Properties["securityScore"])
+	})
+
+	t.Run("missing auth results", func(t *testing.T) {
+		results := auditFindings(&vaultsandbox.Email{ID: "e1"})
+		assert.Equal(t, []string{ruleSPFNotPass, ruleDKIMNotPass, ruleDMARCNotPass, ruleReverseDNSNotPass, ruleLowSecurityScore}, ruleIDs(results))
+		assert.Equal(t, sarifWarning, results[0].Level)
+		assert.Equal(t, "SPF result is none", results[0].Message.Text)
+		assert.Equal(t, "DKIM result is none", results[1].Message.Text)
+	})
+
+	t.Run("warning score and reverse DNS", func(t *testing.T) {
+		auth := passingAuth()
+		auth.DMARC.Result = "fail"
+		auth.ReverseDNS.Result = "fail"
+
+		results := auditFindings(&vaultsandbox.Email{ID: "e1", AuthResults: auth})
+
+		// 50 + 15 + 20 = 85, above the warning threshold
+		assert.Equal(t, []string{ruleDMARCNotPass, ruleReverseDNSNotPass}, ruleIDs(results))
+		assert.Equal(t, sarifWarning, results[1].Level)
+
+		auth.DKIM[0].Result = "fail"
+		results = auditFindings(&vaultsandbox.Email{ID: "e1", AuthResults: auth})
+		require.Len(t, results, 4)
+		assert.Equal(t, ruleLowSecurityScore, results[3].RuleID)
+		assert.Equal(t, sarifWarning, results[3].Level)
+	})
+
+	t.Run("suspicious links", func(t *testing.T) {
+		results := auditFindings(&vaultsandbox.Email{
+			ID:          "e1",
+			AuthResults: passingAuth(),
+			Links:       []string{"https://example.com", "https://bit.ly/x", "http://192.0.2.1/"},
+		})
+		assert.Equal(t, []string{ruleSuspiciousLink, ruleSuspiciousLink}, ruleIDs(results))
+		assert.Equal(t, "Suspicious link: https://bit.ly/x", results[0].Message.Text)
+		assert.Equal(t, sarifWarning, results[0].Level)
+	})
+}
+
+func TestBuildAuditSARIF(t *testing.T) {
+	auth := passingAuth()
+	auth.SPF.Result = "fail"
+	log := buildAuditSARIF(&vaultsandbox.Email{ID: "abc123", AuthResults: auth}, "1.2.3")
+
+	data, err := json.Marshal(log)
+	require.NoError(t, err)
+
+	var doc map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &doc))
+	assert.Equal(t, "2.1.0", doc["version"])
+	assert.Equal(t, sarifSchema, doc["$schema"])
+
+	runs := doc["runs"].([]interface{})
+	require.Len(t, runs, 1)
+	run := runs[0].(map[string]interface{})
+
+	driver := run["tool"].(map[string]interface{})["driver"].(map[string]interface{})
+	assert.Equal(t, "vsb", driver["name"])
+	assert.Equal(t, "1.2.3", driver["version"])
+	assert.Len(t, driver["rules"], len(auditRules))
+
+	results := run["results"].([]interface{})
+	require.Len(t, results, 1)
+	result := results[0].(map[string]interface{})
+	assert.Equal(t, ruleSPFNotPass, result["ruleId"])
+	assert.Equal(t, "error", result["level"])
+
+	location := result["locations"].([]interface{})[0].(map[string]interface{})
+	artifact := location["physicalLocation"].(map[string]interface{})["artifactLocation"].(map[string]interface{})
+	assert.Equal(t, "abc123.eml", artifact["uri"])
+}
+
+func TestAuditRulesCoverFindings(t *testing.T) {
+	known := map[string]bool{}
+	for _, rule := range auditRules {
+		assert.False(t, known[rule.ID], "duplicate rule %s", rule.ID)
+		known[rule.ID] = true
+		assert.NotEmpty(t, rule.Properties["security-severity"])
+	}
+	for _, id := range []string{ruleSPFNotPass, ruleDKIMNotPass, ruleDMARCNotPass, ruleReverseDNSNotPass, ruleSuspiciousLink, ruleLowSecurityScore} {
+		assert.True(t, known[id], id)
+	}
+}
@@ -2,6 +2,7 @@ package email
 
 import (
 	"bytes"
+	"encoding/json"
 	"io"
 	"os"
 	"testing"
@@ -11,6 +12,7 @@ import (
 	"github.com/stretchr/testify/require"
 	vaultsandbox "github.com/vaultsandbox/client-go"
 	"github.com/vaultsandbox/client-go/authresults"
+	"github.com/vaultsandbox/vsb-cli/internal/analysis"
 )
 
 // captureStdout captures stdout during function execution
@@ -168,7 +170,7 @@ func TestRenderAuditReport(t *testing.T) {
 		}
 
 		output := captureStdout(t, func() {
-			err := renderAuditReport(email)
+			err := renderAuditReport(email, false, nil)
 			require.NoError(t, err)
 		})
 
@@ -207,7 +209,7 @@ func TestRenderAuditReport(t *testing.T) {
 		}
 
 		output := captureStdout(t, func() {
-			err := renderAuditReport(email)
+			err := renderAuditReport(email, false, nil)
 			require.NoError(t, err)
 		})
 
@@ -243,7 +245,7 @@ func TestRenderAuditReport(t *testing.T) {
 		}
 
 		output := captureStdout(t, func() {
-			err := renderAuditReport(email)
+			err := renderAuditReport(email, false, nil)
 			require.NoError(t, err)
 		})
 
@@ -266,7 +268,7 @@ func TestRenderAuditReport(t *testing.T) {
 		}
 
 		output := captureStdout(t, func() {
-			err := renderAuditReport(email)
+			err := renderAuditReport(email, false, nil)
 			require.NoError(t, err)
 		})
 
@@ -286,7 +288,7 @@ func TestRenderAuditReport(t *testing.T) {
 		}
 
 		output := captureStdout(t, func() {
-			err := renderAuditReport(email)
+			err := renderAuditReport(email, false, nil)
 			require.NoError(t, err)
 		})
 
@@ -309,7 +311,7 @@ func TestRenderAuditReport(t *testing.T) {
 		}
 
 		output := captureStdout(t, func() {
-			err := renderAuditReport(email)
+			err := renderAuditReport(email, false, nil)
 			require.NoError(t, err)
 		})
 
@@ -331,7 +333,7 @@ func TestRenderAuditReport(t *testing.T) {
 		}
 
 		output := captureStdout(t, func() {
-			err := renderAuditReport(email)
+			err := renderAuditReport(email, false, nil)
 			require.NoError(t, err)
 		})
 
@@ -350,7 +352,7 @@ func TestRenderAuditReport(t *testing.T) {
 		}
 
 		output := captureStdout(t, func() {
-			err := renderAuditReport(email)
+			err := renderAuditReport(email, false, nil)
 			require.NoError(t, err)
 		})
 
@@ -372,7 +374,7 @@ func TestRenderAuditJSON(t *testing.T) {
 		}
 
 		output := captureStdout(t, func() {
-			err := renderAuditJSON(email)
+			err := renderAuditJSON(email, false, nil)
 			require.NoError(t, err)
 		})
 
@@ -407,7 +409,7 @@ func TestRenderAuditJSON(t *testing.T) {
 		}
 
 		output := captureStdout(t, func() {
-			err := renderAuditJSON(email)
+			err := renderAuditJSON(email, false, nil)
 			require.NoError(t, err)
 		})
 
@@ -430,7 +432,7 @@ func TestRenderAuditJSON(t *testing.T) {
 		}
 
 		output := captureStdout(t, func() {
-			err := renderAuditJSON(email)
+			err := renderAuditJSON(email, false, nil)
 			require.NoError(t, err)
 		})
 
@@ -456,7 +458,7 @@ func TestRenderAuditJSON(t *testing.T) {
 		}
 
 		output := captureStdout(t, func() {
-			err := renderAuditJSON(email)
+			err := renderAuditJSON(email, false, nil)
 			require.NoError(t, err)
 		})
 
@@ -466,3 +468,58 @@ func TestRenderAuditJSON(t *testing.T) {
 		assert.Contains(t, output, `"securityScore": 65`)
 	})
 }
+
+func TestAuditExplain(t *testing.T) {
+	email := &vaultsandbox.Email{
+		ID:         "explain-id",
+		Subject:    "Explain",
+		From:       "sender@example.com",
+		To:         []string{"recipient@example.com"},
+		ReceivedAt: time.Now(),
+		AuthResults: &authresults.AuthResults{
+			SPF:   &authresults.SPFResult{Result: "pass", Domain: "example.com"},
+			DKIM:  []authresults.DKIMResult{{Result: "fail", Selector: "s1", Domain: "example.com"}},
+			DMARC: &authresults.DMARCResult{Result: "none"},
+		},
+	}
+
+	t.Run("text", func(t *testing.T) {
+		output := captureStdout(t, func() {
+			require.NoError(t, renderAuditReport(email, true, nil))
+		})
+		assert.Contains(t, output, "DKIM FAIL")
+		assert.Contains(t, output, "s1._domainkey.example.com")
+		assert.Contains(t, output, "No DMARC record was found for the From domain example.com.")
+		assert.Contains(t, output, analysis.DMARCDocURL)
+		assert.NotContains(t, output, analysis.SPFDocURL)
+	})
+
+	t.Run("text without --explain", func(t *testing.T) {
+		output := captureStdout(t, func() {
+			require.NoError(t, renderAuditReport(email, false, nil))
+		})
+		assert.NotContains(t, output, "_domainkey")
+	})
+
+	t.Run("json", func(t *testing.T) {
+		output := captureStdout(t, func() {
+			require.NoError(t, renderAuditJSON(email, true, nil))
+		})
+		var result struct {
+			Explanations []analysis.AuthExplanation `json:"explanations"`
+		}
+		require.NoError(t, json.Unmarshal([]byte(output), &result))
+		require.Len(t, result.Explanations, 2)
+		assert.Equal(t, "dkim", result.Explanations[0].Mechanism)
+		assert.Equal(t, analysis.KindSignature, result.Explanations[0].Kind)
+		assert.Equal(t, "dmarc", result.Explanations[1].Mechanism)
+		assert.Equal(t, analysis.KindMissingRecord, result.Explanations[1].Kind)
+	})
+
+	t.Run("json is an empty array when everything passes", func(t *testing.T) {
+		output := captureStdout(t, func() {
+			require.NoError(t, renderAuditJSON(&vaultsandbox.Email{ID: "ok", ReceivedAt: time.Now()}, true, nil))
+		})
+		assert.Contains(t, output, `"explanations": []`)
+	})
+}
@@ -0,0 +1,71 @@
+package email
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+	vaultsandbox "github.com/vaultsandbox/client-go"
+	"github.com/vaultsandbox/vsb-cli/internal/cliutil"
+)
+
+// Shared by view and wait
+var (
+	debugRaw     bool
+	debugRawFile string
+)
+
+// debugRawStderr is where payloads go without --debug-raw-file; overridden
+// in tests
+var debugRawStderr io.Writer = os.Stderr
+
+// addDebugRawFlags registers the hidden --debug-raw flags on cmd.
+func addDebugRawFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&debugRaw, "debug-raw", false,
+		"Also dump the SDK's decrypted email payload, unshaped, to stderr")
+	cmd.Flags().StringVar(&debugRawFile, "debug-raw-file", "",
+		"Write the --debug-raw payload to this file instead of stderr")
+	cmd.Flags().MarkHidden("debug-raw")
+	cmd.Flags().MarkHidden("debug-raw-file")
+}
+
+// rawOutput reports whether the payload replaces the normal output
+// (--output raw).
+func rawOutput(cmd *cobra.Command) bool {
+	return cliutil.GetOutput(cmd) == cliutil.FormatRaw
+}
+
+// writeDebugRaw dumps the SDK payloads of emails: to stdout with
+// --output raw, otherwise to --debug-raw-file or stderr if --debug-raw is
+// set. Each email is one JSON document.
+func writeDebugRaw(cmd *cobra.Command, emails ...*vaultsandbox.Email) error {
+	if !rawOutput(cmd) && !debugRaw && debugRawFile == "" {
+		return nil
+	}
+
+	var buf bytes.Buffer
+	for _, e := range emails {
+		data, err := cliutil.MarshalJSON(cliutil.NewEmailPayload(e))
+		if err != nil {
+			return err
+		}
+		buf.Write(data)
+		buf.WriteByte('\n')
+	}
+
+	switch {
+	case rawOutput(cmd):
+		_, err := os.Stdout.Write(buf.Bytes())
+		return err
+	case debugRawFile != "":
+		if err := os.WriteFile(debugRawFile, buf.Bytes(), 0600); err != nil {
+			return fmt.Errorf("failed to write --debug-raw-file: %w", err)
+		}
+		return nil
+	default:
+		_, err := debugRawStderr.Write(buf.Bytes())
+		return err
+	}
+}
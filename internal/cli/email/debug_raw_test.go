@@ -0,0 +1,85 @@
+package email
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	vaultsandbox "github.com/vaultsandbox/client-go"
+)
+
+func TestWriteDebugRaw(t *testing.T) {
+	email := &vaultsandbox.Email{ID: "e1", HTML: `<a href="https://example.com">x</a>`, IsRead: true}
+
+	newCmd := func(output string) *cobra.Command {
+		cmd := &cobra.Command{}
+		cmd.Flags().StringP("output", "o", "", "")
+		if output != "" {
+			cmd.Flags().Set("output", output)
+		}
+		return cmd
+	}
+	setFlags := func(t *testing.T, raw bool, file string) *bytes.Buffer {
+		oldRaw, oldFile, oldStderr := debugRaw, debugRawFile, debugRawStderr
+		t.Cleanup(func() { debugRaw, debugRawFile, debugRawStderr = oldRaw, oldFile, oldStderr })
+		var buf bytes.Buffer
+		debugRaw, debugRawFile, debugRawStderr = raw, file, &buf
+		return &buf
+	}
+
+	t.Run("disabled by default", func(t *testing.T) {
+		stderr := setFlags(t, false, "")
+		out := captureURLStdout(t, func() {
+			require.NoError(t, writeDebugRaw(newCmd(""), email))
+		})
+		assert.Empty(t, out)
+		assert.Empty(t, stderr.String())
+	})
+
+	t.Run("to stderr", func(t *testing.T) {
+		stderr := setFlags(t, true, "")
+		out := captureURLStdout(t, func() {
+			require.NoError(t, writeDebugRaw(newCmd(""), email))
+		})
+		assert.Empty(t, out)
+
+		var payload map[string]interface{}
+		require.NoError(t, json.Unmarshal(stderr.Bytes(), &payload))
+		assert.Equal(t, "e1", payload["id"])
+		assert.Equal(t, true, payload["isRead"])
+		assert.Contains(t, payload, "links")
+		assert.Nil(t, payload["links"])
+	})
+
+	t.Run("to file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "payload.json")
+		stderr := setFlags(t, false, path)
+		require.NoError(t, writeDebugRaw(newCmd(""), email, &vaultsandbox.Email{ID: "e2"}))
+		assert.Empty(t, stderr.String())
+
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Contains(t, string(data), `"id": "e1"`)
+		assert.Contains(t, string(data), `"id": "e2"`)
+
+		info, err := os.Stat(path)
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	})
+
+	t.Run("output raw goes to stdout", func(t *testing.T) {
+		stderr := setFlags(t, false, "")
+		out := captureURLStdout(t, func() {
+			require.NoError(t, writeDebugRaw(newCmd("raw"), email))
+		})
+		assert.Empty(t, stderr.String())
+		var payload map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(out), &payload))
+		assert.Equal(t, email.HTML, payload["html"])
+	})
+}
@@ -1,7 +1,6 @@
 package email
 
 import (
-	"context"
 	"fmt"
 
 	"github.com/spf13/cobra"
@@ -29,7 +28,7 @@ func init() {
 }
 
 func runDelete(cmd *cobra.Command, args []string) error {
-	ctx := context.Background()
+	ctx := cliutil.CommandContext(cmd)
 	emailID := args[0]
 
 	inbox, cleanup, err := cliutil.LoadAndImportInbox(ctx, InboxFlag)
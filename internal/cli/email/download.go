@@ -0,0 +1,248 @@
+package email
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	vaultsandbox "github.com/vaultsandbox/client-go"
+	"github.com/vaultsandbox/vsb-cli/internal/cliutil"
+	mailfile "github.com/vaultsandbox/vsb-cli/internal/email"
+	"github.com/vaultsandbox/vsb-cli/internal/files"
+	"github.com/vaultsandbox/vsb-cli/internal/styles"
+)
+
+// getRawEmailFunc fetches an email's RFC 5322 source; overridden in tests
+var getRawEmailFunc = func(ctx context.Context, inbox *vaultsandbox.Inbox, emailID string) (string, error) {
+	return inbox.GetRawEmail(ctx, emailID)
+}
+
+var downloadCmd = &cobra.Command{
+	Use:   "download [email-id]",
+	Short: "Save an email as an EML or mbox file",
+	Long: `Save the raw RFC 5322 source of an email to a file that mail clients
+can open or import.
+
+By default the latest email is saved as <subject>.eml in the current
+directory (or --dir). Use --out to choose the path. Files are created with
+0600 permissions, and existing files are never overwritten.
+
+--format mbox writes Unix mbox (mboxrd) instead. With --all, every email in
+the inbox is downloaded: as one .eml file each, or as a single mbox file
+(<inbox>.mbox unless --out is given) in received order.
+
+Examples:
+  vsb email download                        # Latest email to <subject>.eml
+  vsb email download abc123 --out message.eml
+  vsb email download --all --dir ./emails/  # One .eml file per email
+  vsb email download --all --format mbox --out inbox.mbox`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runDownload,
+}
+
+var (
+	downloadOut    string
+	downloadFormat string
+	downloadAll    bool
+	downloadDir    string
+)
+
+func init() {
+	Cmd.AddCommand(downloadCmd)
+	cliutil.AddOutputFormats(downloadCmd, cliutil.FormatJSON)
+
+	downloadCmd.Flags().StringVar(&downloadOut, "out", "",
+		"Output file path (default: <subject>.eml in --dir)")
+	downloadCmd.Flags().StringVar(&downloadFormat, "format", "eml",
+		"File format: eml or mbox")
+	downloadCmd.Flags().BoolVarP(&downloadAll, "all", "a", false,
+		"Download every email in the inbox")
+	downloadCmd.Flags().StringVarP(&downloadDir, "dir", "d", ".",
+		"Directory to save files in")
+}
+
+// downloadItem is an email to download and where it was saved
+type downloadItem struct {
+	ID         string
+	From       string
+	Subject    string
+	ReceivedAt time.Time
+	Path       string
+}
+
+func runDownload(cmd *cobra.Command, args []string) error {
+	ctx := cliutil.CommandContext(cmd)
+
+	if downloadFormat != "eml" && downloadFormat != "mbox" {
+		return fmt.Errorf("invalid --format: %s (valid: eml, mbox)", downloadFormat)
+	}
+	if downloadAll && len(args) > 0 {
+		return fmt.Errorf("--all cannot be used with an email ID")
+	}
+	if downloadAll && downloadFormat == "eml" && downloadOut != "" {
+		return fmt.Errorf("--out cannot be used with --all --format eml; use --dir")
+	}
+
+	var items []downloadItem
+	var inbox *vaultsandbox.Inbox
+	if downloadAll {
+		var cleanup func()
+		var err error
+		inbox, cleanup, err = cliutil.LoadAndImportInbox(ctx, InboxFlag)
+		if err != nil {
+			return err
+		}
+		defer cleanup()
+
+		metadata, err := inbox.GetEmailsMetadataOnly(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to get emails: %w", err)
+		}
+		for _, m := range metadata {
+			items = append(items, downloadItem{ID: m.ID, From: m.From, Subject: m.Subject, ReceivedAt: m.ReceivedAt})
+		}
+		sort.SliceStable(items, func(i, j int) bool { return items[i].ReceivedAt.Before(items[j].ReceivedAt) })
+	} else {
+		email, emailInbox, cleanup, err := getEmailByIDOrLatestFunc(ctx, cliutil.GetArg(args, 0, ""), InboxFlag)
+		if err != nil {
+			return err
+		}
+		defer cleanup()
+		inbox = emailInbox
+		items = []downloadItem{{ID: email.ID, From: email.From, Subject: email.Subject, ReceivedAt: email.ReceivedAt}}
+	}
+
+	if len(items) == 0 {
+		if cliutil.GetOutput(cmd) == cliutil.FormatJSON {
+			return cliutil.OutputJSON([]struct{}{})
+		}
+		fmt.Println("No emails in inbox")
+		return nil
+	}
+
+	fetch := func(id string) (string, error) { return getRawEmailFunc(ctx, inbox, id) }
+
+	var err error
+	if downloadFormat == "mbox" {
+		name := downloadFilename(items[0].Subject, items[0].ID, "mbox")
+		if downloadAll {
+			name = cliutil.SanitizeFilename(inbox.EmailAddress()) + ".mbox"
+		}
+		err = saveMbox(items, fetch, name)
+	} else {
+		err = saveEMLs(items, fetch)
+	}
+	if err != nil {
+		return err
+	}
+
+	if cliutil.GetOutput(cmd) == cliutil.FormatJSON {
+		result := make([]map[string]string, len(items))
+		for i, item := range items {
+			result[i] = map[string]string{"id": item.ID, "path": item.Path}
+		}
+		return cliutil.OutputJSON(result)
+	}
+
+	if downloadFormat == "mbox" {
+		fmt.Println(styles.PassStyle.Render(fmt.Sprintf("✓ Saved %d email(s) to %s", len(items), items[0].Path)))
+		return nil
+	}
+	for _, item := range items {
+		fmt.Println(styles.PassStyle.Render(fmt.Sprintf("✓ Saved: %s", item.Path)))
+	}
+	return nil
+}
+
+// saveEMLs writes each email to its own .eml file and records its path.
+func saveEMLs(items []downloadItem, fetch func(id string) (string, error)) error {
+	for i := range items {
+		raw, err := fetch(items[i].ID)
+		if err != nil {
+			return fmt.Errorf("failed to get raw email %s: %w", items[i].ID, err)
+		}
+		path, err := writeDownload(downloadFilename(items[i].Subject, items[i].ID, "eml"), mailfile.EML(raw))
+		if err != nil {
+			return err
+		}
+		items[i].Path = path
+	}
+	return nil
+}
+
+// saveMbox writes all emails to a single mbox file named name (unless --out
+// is set) and records its path on every item.
+func saveMbox(items []downloadItem, fetch func(id string) (string, error), name string) error {
+	var buf bytes.Buffer
+	for _, item := range items {
+		raw, err := fetch(item.ID)
+		if err != nil {
+			return fmt.Errorf("failed to get raw email %s: %w", item.ID, err)
+		}
+		if err := mailfile.WriteMbox(&buf, item.From, item.ReceivedAt, raw); err != nil {
+			return err
+		}
+	}
+
+	path, err := writeDownload(name, buf.Bytes())
+	if err != nil {
+		return err
+	}
+	for i := range items {
+		items[i].Path = path
+	}
+	return nil
+}
+
+// writeDownload writes data with 0600 permissions to --out, or to a unique
+// file called name in --dir.
+func writeDownload(name string, data []byte) (string, error) {
+	if downloadOut == "" {
+		return files.SavePrivateFile(downloadDir, name, data)
+	}
+
+	path, err := filepath.Abs(downloadOut)
+	if err != nil {
+		return "", err
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		if os.IsExist(err) {
+			return "", fmt.Errorf("file already exists: %s", path)
+		}
+		return "", fmt.Errorf("failed to write file: %w", err)
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return "", fmt.Errorf("failed to write file: %w", err)
+	}
+	return path, f.Close()
+}
+
+var unsafeFilenameChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// maxSubjectFilename caps the subject part of generated filenames
+const maxSubjectFilename = 80
+
+// downloadFilename builds "<sanitized-subject>.<ext>", falling back to the
+// email ID when the subject has no usable characters.
+func downloadFilename(subject, id, ext string) string {
+	name := strings.Trim(unsafeFilenameChars.ReplaceAllString(subject, "_"), "._-")
+	if len(name) > maxSubjectFilename {
+		name = strings.TrimRight(name[:maxSubjectFilename], "._-")
+	}
+	if name == "" {
+		name = cliutil.SanitizeFilename(id)
+	}
+	if name == "" {
+		name = "email"
+	}
+	return name + "." + ext
+}
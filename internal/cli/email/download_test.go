@@ -0,0 +1,134 @@
+package email
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	vaultsandbox "github.com/vaultsandbox/client-go"
+)
+
+func TestDownloadFilename(t *testing.T) {
+	tests := []struct {
+		subject, want string
+	}{
+		{"Welcome to Acme!", "Welcome_to_Acme.eml"},
+		{"Re: [ticket #42] reset/password", "Re_ticket_42_reset_password.eml"},
+		{"../../etc/passwd", "etc_passwd.eml"},
+		{"", "abc123.eml"},
+		{"¡¿", "abc123.eml"},
+		{strings.Repeat("a", 200), strings.Repeat("a", 80) + ".eml"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, downloadFilename(tt.subject, "abc123", "eml"), tt.subject)
+	}
+}
+
+func setDownloadFlags(t *testing.T, out, format, dir string) {
+	t.Helper()
+	oldOut, oldFormat, oldDir, oldAll := downloadOut, downloadFormat, downloadDir, downloadAll
+	t.Cleanup(func() {
+		downloadOut, downloadFormat, downloadDir, downloadAll = oldOut, oldFormat, oldDir, oldAll
+	})
+	downloadOut, downloadFormat, downloadDir, downloadAll = out, format, dir, false
+}
+
+func fakeRawFetch(id string) (string, error) {
+	if id == "broken" {
+		return "", fmt.Errorf("not found")
+	}
+	return "Subject: " + id + "\r\n\r\nFrom the body\r\n", nil
+}
+
+func TestSaveEMLs(t *testing.T) {
+	dir := t.TempDir()
+	setDownloadFlags(t, "", "eml", dir)
+
+	items := []downloadItem{{ID: "one", Subject: "Hello"}, {ID: "two", Subject: "Hello"}}
+	require.NoError(t, saveEMLs(items, fakeRawFetch))
+
+	assert.Equal(t, filepath.Join(dir, "Hello.eml"), items[0].Path)
+	assert.Equal(t, filepath.Join(dir, "Hello_1.eml"), items[1].Path)
+
+	data, err := os.ReadFile(items[1].Path)
+	require.NoError(t, err)
+	assert.Equal(t, "Subject: two\r\n\r\nFrom the body\r\n", string(data))
+
+	info, err := os.Stat(items[0].Path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	t.Run("fetch error", func(t *testing.T) {
+		err := saveEMLs([]downloadItem{{ID: "broken"}}, fakeRawFetch)
+		assert.EqualError(t, err, "failed to get raw email broken: not found")
+	})
+}
+
+func TestSaveMbox(t *testing.T) {
+	dir := t.TempDir()
+	out := filepath.Join(dir, "inbox.mbox")
+	setDownloadFlags(t, out, "mbox", dir)
+
+	received := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	items := []downloadItem{
+		{ID: "one", From: "a@example.com", ReceivedAt: received},
+		{ID: "two", From: "b@example.com", ReceivedAt: received.Add(time.Minute)},
+	}
+	require.NoError(t, saveMbox(items, fakeRawFetch, "ignored.mbox"))
+	assert.Equal(t, out, items[0].Path)
+	assert.Equal(t, out, items[1].Path)
+
+	data, err := os.ReadFile(out)
+	require.NoError(t, err)
+	assert.Equal(t, "From a@example.com Sun Mar  1 12:00:00 2026\nSubject: one\n\n>From the body\n\n"+
+		"From b@example.com Sun Mar  1 12:01:00 2026\nSubject: two\n\n>From the body\n\n", string(data))
+
+	t.Run("existing --out is not overwritten", func(t *testing.T) {
+		err := saveMbox(items, fakeRawFetch, "ignored.mbox")
+		assert.EqualError(t, err, "file already exists: "+out)
+	})
+}
+
+func TestRunDownload(t *testing.T) {
+	dir := t.TempDir()
+	setDownloadFlags(t, "", "eml", dir)
+
+	oldFetcher, oldRaw := getEmailByIDOrLatestFunc, getRawEmailFunc
+	defer func() { getEmailByIDOrLatestFunc, getRawEmailFunc = oldFetcher, oldRaw }()
+	getEmailByIDOrLatestFunc = mockEmailFetcher(&vaultsandbox.Email{ID: "abc", Subject: "Your code"}, nil)
+	getRawEmailFunc = func(ctx context.Context, inbox *vaultsandbox.Inbox, id string) (string, error) {
+		return fakeRawFetch(id)
+	}
+
+	cmd := &cobra.Command{RunE: runDownload}
+	cmd.Flags().StringP("output", "o", "", "")
+	cmd.Flags().Set("output", "json")
+
+	output := captureURLStdout(t, func() {
+		require.NoError(t, runDownload(cmd, nil))
+	})
+
+	var result []map[string]string
+	require.NoError(t, json.Unmarshal([]byte(output), &result))
+	assert.Equal(t, []map[string]string{{"id": "abc", "path": filepath.Join(dir, "Your_code.eml")}}, result)
+
+	t.Run("invalid format", func(t *testing.T) {
+		downloadFormat = "pst"
+		defer func() { downloadFormat = "eml" }()
+		assert.EqualError(t, runDownload(cmd, nil), "invalid --format: pst (valid: eml, mbox)")
+	})
+
+	t.Run("all with email ID", func(t *testing.T) {
+		downloadAll = true
+		defer func() { downloadAll = false }()
+		assert.EqualError(t, runDownload(cmd, []string{"abc"}), "--all cannot be used with an email ID")
+	})
+}
@@ -0,0 +1,101 @@
+package email
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	vaultsandbox "github.com/vaultsandbox/client-go"
+)
+
+// emailFilter holds the --subject, --subject-regex, --from, --from-regex and
+// --body-regex values of a command. These are also the filters a --preset
+// can set.
+type emailFilter struct {
+	Subject      string
+	SubjectRegex string
+	From         string
+	FromRegex    string
+	BodyRegex    string
+}
+
+// isSet reports whether any filter is given.
+func (f emailFilter) isSet() bool {
+	return f != emailFilter{}
+}
+
+// compile returns a function reporting whether an email matches every set
+// filter.
+func (f emailFilter) compile() (func(*vaultsandbox.Email) bool, error) {
+	var subjectRe, fromRe, bodyRe *regexp.Regexp
+	var err error
+	if f.SubjectRegex != "" {
+		if subjectRe, err = regexp.Compile(f.SubjectRegex); err != nil {
+			return nil, fmt.Errorf("invalid subject regex: %w", err)
+		}
+	}
+	if f.FromRegex != "" {
+		if fromRe, err = regexp.Compile(f.FromRegex); err != nil {
+			return nil, fmt.Errorf("invalid from regex: %w", err)
+		}
+	}
+	if f.BodyRegex != "" {
+		if bodyRe, err = regexp.Compile(f.BodyRegex); err != nil {
+			return nil, fmt.Errorf("invalid body regex: %w", err)
+		}
+	}
+
+	return func(e *vaultsandbox.Email) bool {
+		if f.Subject != "" && e.Subject != f.Subject {
+			return false
+		}
+		if subjectRe != nil && !subjectRe.MatchString(e.Subject) {
+			return false
+		}
+		if f.From != "" && e.From != f.From {
+			return false
+		}
+		if fromRe != nil && !fromRe.MatchString(e.From) {
+			return false
+		}
+		if bodyRe != nil && !bodyRe.MatchString(emailBody(e)) {
+			return false
+		}
+		return true
+	}, nil
+}
+
+// senderDomainMatcher returns a function reporting whether the domain of an
+// email's From address is one of domains (ignoring case and a leading @) and
+// matches pattern, for --sender-domain and --sender-domain-regex. It returns
+// nil if neither is given.
+func senderDomainMatcher(domains []string, pattern string) (func(*vaultsandbox.Email) bool, error) {
+	allowed := make(map[string]bool)
+	for _, d := range domains {
+		d = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(d), "@"))
+		if d != "" {
+			allowed[d] = true
+		}
+	}
+	var domainRe *regexp.Regexp
+	if pattern != "" {
+		var err error
+		if domainRe, err = regexp.Compile(pattern); err != nil {
+			return nil, fmt.Errorf("invalid sender domain regex: %w", err)
+		}
+	}
+	if len(allowed) == 0 && domainRe == nil {
+		return nil, nil
+	}
+
+	return func(e *vaultsandbox.Email) bool {
+		domain := senderDomain(e.From)
+		if domain == "" {
+			return false
+		}
+		if len(allowed) > 0 && !allowed[domain] {
+			return false
+		}
+		return domainRe == nil || domainRe.MatchString(domain)
+	}, nil
+}
@@ -2,11 +2,21 @@ package email
 
 import (
 	"context"
+	"encoding/csv"
 	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
+	vaultsandbox "github.com/vaultsandbox/client-go"
 	"github.com/vaultsandbox/vsb-cli/internal/cliutil"
+	"github.com/vaultsandbox/vsb-cli/internal/config"
 	"github.com/vaultsandbox/vsb-cli/internal/styles"
+	"github.com/vaultsandbox/vsb-cli/internal/table"
+	"github.com/vaultsandbox/vsb-cli/internal/timeparse"
 )
 
 var listCmd = &cobra.Command{
@@ -17,20 +27,131 @@ var listCmd = &cobra.Command{
 Displays email ID, subject, sender, and received time.
 Use the email ID with other commands like 'vsb view <id>'.
 
+--since and --until keep emails received inside a time window (both ends
+inclusive). They accept RFC3339 timestamps or durations relative to now,
+such as 2h or 30m.
+
+With --output csv, one row per email is written with the columns id,
+receivedAt, from, to, subject, links, attachments and sizeBytes (decoded
+size of the bodies and attachments). --output table prints an aligned
+table with ID, SUBJECT, FROM, RECEIVED and READ columns, truncating long
+subjects and senders and bolding unread emails on a terminal.
+
+The pretty table fits the terminal width (COLUMNS when set, 120 when not a
+terminal): subjects and senders are shortened with an ellipsis as needed.
+--wide keeps them whole.
+
+--count-only prints just the number of matching emails ({"count": N} with
+--output json). Without a time window the count comes from the inbox sync
+status; with --since or --until only email metadata is fetched, so bodies
+are never decrypted.
+
+--has-links keeps emails with at least one link and --has-attachment emails
+with at least one attachment. Both are applied to the fetched emails and
+combine with each other and with the other filters. With --count-only they
+need the full emails, so bodies are decrypted.
+
+--subject, --subject-regex, --from, --from-regex and --body-regex match
+like the 'email wait' filters of the same name. --preset applies a filter
+preset stored on the inbox with 'vsb inbox filter set'; filter flags given
+on the command line override the preset's values. Like --has-links, these
+filters need the full emails.
+
+--sender-domain keeps emails whose From address is at one of the given
+domains, ignoring case; separate several with commas or repeat the flag.
+Subdomains are not included: use --sender-domain-regex, which matches the
+sender's domain (not the whole address), for those.
+
+Emails shown by 'email view', 'email wait' or the dashboard are marked read
+on this machine (nothing is sent to the server). --unread lists only the
+others, and JSON output includes a "read" field. Use 'email mark-read' and
+'email mark-unread' to change the state by hand.
+
 Examples:
   vsb email list              # List emails in active inbox
   vsb email list --inbox abc  # List emails in specific inbox
-  vsb email list -o json      # JSON output`,
+  vsb email list -o json      # JSON output
+  vsb email list --since 2h   # Emails from the last two hours
+  vsb email list --count-only --since 10m
+  vsb email list --unread     # Emails not viewed yet
+  vsb email list --has-links --since 1h
+  vsb email list --has-attachment -o json
+  vsb email list --wide       # Do not truncate subjects and senders
+  vsb email list --from-regex '@billing\.example\.com$'
+  vsb email list --sender-domain github.com,gitlab.com
+  vsb email list --sender-domain-regex '(^|\.)example\.com$'
+  vsb email list --preset billing --since 24h
+  vsb email list --since 2026-01-13T14:00:00Z --until 2026-01-13T15:00:00Z -o csv > emails.csv`,
 	Aliases: []string{"ls"},
 	RunE:    runList,
 }
 
+var (
+	listSince     string
+	listUntil     string
+	listCountOnly bool
+	listUnread    bool
+
+	listHasLinks      bool
+	listHasAttachment bool
+
+	listFilter emailFilter
+	listWide   bool
+
+	listSenderDomains     []string
+	listSenderDomainRegex string
+)
+
 func init() {
 	Cmd.AddCommand(listCmd)
+	cliutil.AddOutputFormats(listCmd, cliutil.FormatJSON, cliutil.FormatCSV, cliutil.FormatTable)
+
+	listCmd.Flags().StringVar(&listSince, "since", "",
+		"Only emails received at or after this time (RFC3339 or duration ago, e.g. 2h)")
+	listCmd.Flags().StringVar(&listUntil, "until", "",
+		"Only emails received at or before this time (RFC3339 or duration ago, e.g. 30m)")
+	listCmd.Flags().BoolVar(&listCountOnly, "count-only", false,
+		"Print only the number of matching emails")
+	listCmd.Flags().BoolVar(&listUnread, "unread", false,
+		"Only emails not marked read on this machine")
+	listCmd.Flags().BoolVar(&listHasLinks, "has-links", false,
+		"Only emails containing at least one link")
+	listCmd.Flags().BoolVar(&listHasAttachment, "has-attachment", false,
+		"Only emails with at least one attachment")
+	listCmd.Flags().StringVar(&listFilter.Subject, "subject", "",
+		"Only emails with exactly this subject")
+	listCmd.Flags().StringVar(&listFilter.SubjectRegex, "subject-regex", "",
+		"Only emails whose subject matches this regex")
+	listCmd.Flags().StringVar(&listFilter.From, "from", "",
+		"Only emails from exactly this sender")
+	listCmd.Flags().StringVar(&listFilter.FromRegex, "from-regex", "",
+		"Only emails whose sender matches this regex")
+	listCmd.Flags().StringVar(&listFilter.BodyRegex, "body-regex", "",
+		"Only emails whose body matches this regex")
+	listCmd.Flags().StringSliceVar(&listSenderDomains, "sender-domain", nil,
+		"Only emails from these sender domains (comma-separated or repeated)")
+	listCmd.Flags().StringVar(&listSenderDomainRegex, "sender-domain-regex", "",
+		"Only emails whose sender domain matches this regex")
+	cliutil.AddPresetFlag(listCmd)
+	listCmd.Flags().BoolVar(&listWide, "wide", false,
+		"Do not truncate subjects and senders to fit the terminal")
 }
 
 func runList(cmd *cobra.Command, args []string) error {
-	ctx := context.Background()
+	ctx := cliutil.CommandContext(cmd)
+
+	since, until, err := parseTimeWindow(listSince, listUntil, time.Now())
+	if err != nil {
+		return err
+	}
+	matches, err := listFilter.compile()
+	if err != nil {
+		return err
+	}
+	fromDomain, err := senderDomainMatcher(listSenderDomains, listSenderDomainRegex)
+	if err != nil {
+		return err
+	}
 
 	inbox, cleanup, err := cliutil.LoadAndImportInbox(ctx, InboxFlag)
 	if err != nil {
@@ -38,41 +159,287 @@ func runList(cmd *cobra.Command, args []string) error {
 	}
 	defer cleanup()
 
+	readState := cliutil.LoadReadState(inbox.EmailAddress())
+	var unreadOnly *config.ReadState
+	if listUnread {
+		unreadOnly = readState
+	}
+
+	// Content filters need the full emails, so they can't use the
+	// metadata-only count
+	contentFilter := listHasLinks || listHasAttachment || listFilter.isSet() || fromDomain != nil
+	if listCountOnly && !contentFilter {
+		count, err := countEmails(ctx, inbox, since, until, unreadOnly)
+		if err != nil {
+			return err
+		}
+		return printCount(cmd, count)
+	}
+
 	emails, err := inbox.GetEmails(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to get emails: %w", err)
 	}
 
-	// JSON output
-	if cliutil.GetOutput(cmd) == "json" {
+	emails = filterByTimeWindow(emails, since, until)
+	if listUnread {
+		emails = filterUnread(emails, readState)
+	}
+	if contentFilter {
+		emails = filterByContent(emails, listHasLinks, listHasAttachment)
+		emails = filterEmails(emails, matches)
+		if fromDomain != nil {
+			emails = filterEmails(emails, fromDomain)
+		}
+	}
+	if listCountOnly {
+		return printCount(cmd, len(emails))
+	}
+
+	switch cliutil.GetOutput(cmd) {
+	case cliutil.FormatCSV:
+		return writeEmailsCSV(os.Stdout, emails)
+	case cliutil.FormatTable:
+		return writeEmailsTable(os.Stdout, cliutil.StdoutTableWidth(), emails, readState)
+	case cliutil.FormatJSON:
 		var result []map[string]interface{}
 		for _, email := range emails {
-			result = append(result, cliutil.EmailSummaryJSON(email))
+			data := cliutil.EmailSummaryJSON(email)
+			data["read"] = readState.IsRead(email.ID)
+			result = append(result, data)
 		}
 		return cliutil.OutputJSON(result)
 	}
 
 	// Pretty output
 	if len(emails) == 0 {
-		fmt.Println("No emails in inbox")
+		if listUnread {
+			fmt.Println("No unread emails in inbox")
+		} else {
+			fmt.Println("No emails in inbox")
+		}
 		return nil
 	}
 
-	// Header
-	table := cliutil.NewTable(
-		cliutil.Column{Header: "ID", Width: styles.ColWidthID}.WithStyle(styles.IDStyle),
-		cliutil.Column{Header: "SUBJECT", Width: styles.ColWidthSubject}.WithStyle(styles.SubjectStyle),
-		cliutil.Column{Header: "FROM", Width: styles.ColWidthFrom}.WithStyle(styles.FromStyle),
-		cliutil.Column{Header: "RECEIVED"}.WithStyle(styles.TimeStyle),
-	)
-	table.PrintHeader()
+	writeEmailsPretty(os.Stdout, table.Width(), emails)
 
-	for _, email := range emails {
-		table.PrintRow(email.ID, email.Subject, email.From, cliutil.FormatRelativeTime(email.ReceivedAt))
+	fmt.Println()
+	fmt.Printf("  %d email(s), %d unread\n\n", len(emails), len(filterUnread(emails, readState)))
+
+	return nil
+}
+
+// parseTimeWindow parses the --since and --until values. Empty values leave
+// that end of the window open (zero time).
+func parseTimeWindow(sinceFlag, untilFlag string, now time.Time) (since, until time.Time, err error) {
+	if sinceFlag != "" {
+		if since, err = timeparse.Time(sinceFlag, now); err != nil {
+			return since, until, fmt.Errorf("--since: %w", err)
+		}
+	}
+	if untilFlag != "" {
+		if until, err = timeparse.Time(untilFlag, now); err != nil {
+			return since, until, fmt.Errorf("--until: %w", err)
+		}
 	}
+	if !since.IsZero() && !until.IsZero() && since.After(until) {
+		return since, until, fmt.Errorf("--since (%s) is after --until (%s)",
+			since.Format(time.RFC3339), until.Format(time.RFC3339))
+	}
+	return since, until, nil
+}
 
-	fmt.Println()
-	fmt.Printf("  %d email(s)\n\n", len(emails))
+// filterByTimeWindow keeps emails received within [since, until]. A zero
+// bound is ignored.
+func filterByTimeWindow(emails []*vaultsandbox.Email, since, until time.Time) []*vaultsandbox.Email {
+	if since.IsZero() && until.IsZero() {
+		return emails
+	}
+	var filtered []*vaultsandbox.Email
+	for _, e := range emails {
+		if inTimeWindow(e.ReceivedAt, since, until) {
+			filtered = append(filtered, e)
+		}
+	}
+	return filtered
+}
+
+// inTimeWindow reports whether t lies within [since, until]. A zero bound
+// is ignored.
+func inTimeWindow(t, since, until time.Time) bool {
+	if !since.IsZero() && t.Before(since) {
+		return false
+	}
+	if !until.IsZero() && t.After(until) {
+		return false
+	}
+	return true
+}
+
+// filterUnread keeps emails that are not marked read.
+func filterUnread(emails []*vaultsandbox.Email, rs *config.ReadState) []*vaultsandbox.Email {
+	var filtered []*vaultsandbox.Email
+	for _, e := range emails {
+		if !rs.IsRead(e.ID) {
+			filtered = append(filtered, e)
+		}
+	}
+	return filtered
+}
+
+// filterByContent keeps emails with links (if hasLinks) and attachments (if
+// hasAttachment).
+func filterByContent(emails []*vaultsandbox.Email, hasLinks, hasAttachment bool) []*vaultsandbox.Email {
+	var filtered []*vaultsandbox.Email
+	for _, e := range emails {
+		if hasLinks && len(e.Links) == 0 {
+			continue
+		}
+		if hasAttachment && len(e.Attachments) == 0 {
+			continue
+		}
+		filtered = append(filtered, e)
+	}
+	return filtered
+}
 
+// filterEmails keeps emails for which match returns true.
+func filterEmails(emails []*vaultsandbox.Email, match func(*vaultsandbox.Email) bool) []*vaultsandbox.Email {
+	var filtered []*vaultsandbox.Email
+	for _, e := range emails {
+		if match(e) {
+			filtered = append(filtered, e)
+		}
+	}
+	return filtered
+}
+
+// printCount prints the --count-only result
+func printCount(cmd *cobra.Command, count int) error {
+	if cliutil.GetOutput(cmd) == cliutil.FormatJSON {
+		return cliutil.OutputJSON(map[string]int{"count": count})
+	}
+	fmt.Println(count)
 	return nil
 }
+
+// countEmails returns the number of emails received within the window,
+// avoiding full email downloads. A non-nil unreadOnly counts only emails it
+// doesn't mark read.
+func countEmails(ctx context.Context, inbox *vaultsandbox.Inbox, since, until time.Time, unreadOnly *config.ReadState) (int, error) {
+	if since.IsZero() && until.IsZero() && unreadOnly == nil {
+		status, err := inbox.GetSyncStatus(ctx)
+		if err != nil {
+			return 0, fmt.Errorf("failed to get inbox status: %w", err)
+		}
+		return status.EmailCount, nil
+	}
+
+	metadata, err := inbox.GetEmailsMetadataOnly(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get emails: %w", err)
+	}
+	return countInTimeWindow(metadata, since, until, unreadOnly), nil
+}
+
+// countInTimeWindow counts the emails received within [since, until],
+// skipping emails marked read in unreadOnly if it is non-nil.
+func countInTimeWindow(metadata []*vaultsandbox.EmailMetadata, since, until time.Time, unreadOnly *config.ReadState) int {
+	count := 0
+	for _, m := range metadata {
+		if unreadOnly != nil && unreadOnly.IsRead(m.ID) {
+			continue
+		}
+		if inTimeWindow(m.ReceivedAt, since, until) {
+			count++
+		}
+	}
+	return count
+}
+
+// writeEmailsPretty writes the pretty email table laid out for width
+// columns. Subjects and senders shrink to fit unless --wide is set.
+func writeEmailsPretty(w io.Writer, width int, emails []*vaultsandbox.Email) {
+	t := table.New(w, width,
+		table.Column{Header: "ID", Width: styles.ColWidthID}.WithStyle(styles.IDStyle),
+		table.Column{Header: "SUBJECT", Width: styles.ColWidthSubject, Flex: true}.WithStyle(styles.SubjectStyle),
+		table.Column{Header: "FROM", Width: styles.ColWidthFrom, Flex: true}.WithStyle(styles.FromStyle),
+		table.Column{Header: "RECEIVED", Width: colWidthReceived}.WithStyle(styles.TimeStyle),
+	).WithWide(listWide)
+	t.WriteHeader()
+
+	for _, email := range emails {
+		t.WriteRow(email.ID, email.Subject, email.From, cliutil.FormatRelativeTime(email.ReceivedAt))
+	}
+}
+
+// colWidthReceived fits relative times like "59 minutes ago"
+const colWidthReceived = 14
+
+// writeEmailsTable renders emails for --output table. Subjects and senders
+// shrink to fit width columns unless width is 0 or --wide is set.
+func writeEmailsTable(w io.Writer, width int, emails []*vaultsandbox.Email, readState *config.ReadState) error {
+	t := cliutil.NewTableWriter(w, "ID", "SUBJECT", "FROM", "RECEIVED", "READ").
+		SetMaxWidth(1, colWidthTableSubject).
+		SetMaxWidth(2, colWidthTableFrom)
+	t.Width = width
+	t.Truncate = width > 0 && !listWide
+	for _, email := range emails {
+		read := "no"
+		if readState.IsRead(email.ID) {
+			read = "yes"
+		}
+		cells := []string{email.ID, cliutil.SubjectOrDefault(email.Subject), email.From, cliutil.FormatRelativeTime(email.ReceivedAt), read}
+		if read == "no" {
+			t.AddStyledRow(styles.SubjectStyle, cells...)
+		} else {
+			t.AddRow(cells...)
+		}
+	}
+	return t.Render()
+}
+
+// Maximum widths of the variable --output table columns on a terminal,
+// before shrinking to the terminal width
+const (
+	colWidthTableSubject = 50
+	colWidthTableFrom    = 40
+)
+
+// csvHeader lists the columns written by writeEmailsCSV
+var csvHeader = []string{"id", "receivedAt", "from", "to", "subject", "links", "attachments", "sizeBytes"}
+
+// writeEmailsCSV writes email metadata as CSV with a header row. Fields
+// containing commas, quotes or newlines are quoted.
+func writeEmailsCSV(w io.Writer, emails []*vaultsandbox.Email) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvHeader); err != nil {
+		return err
+	}
+	for _, e := range emails {
+		record := []string{
+			e.ID,
+			e.ReceivedAt.Format(time.RFC3339),
+			e.From,
+			strings.Join(e.To, ", "),
+			e.Subject,
+			strconv.Itoa(len(e.Links)),
+			strconv.Itoa(len(e.Attachments)),
+			strconv.Itoa(emailSize(e)),
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// emailSize returns the decoded size of the bodies and attachments in bytes.
+func emailSize(e *vaultsandbox.Email) int {
+	size := len(e.Text) + len(e.HTML)
+	for _, a := range e.Attachments {
+		size += a.Size
+	}
+	return size
+}
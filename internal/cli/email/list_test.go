@@ -0,0 +1,280 @@
+package email
+
+import (
+	"bytes"
+	"encoding/csv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	vaultsandbox "github.com/vaultsandbox/client-go"
+	"github.com/vaultsandbox/vsb-cli/internal/config"
+)
+
+func TestParseTimeWindow(t *testing.T) {
+	now := time.Date(2026, 3, 1, 15, 0, 0, 0, time.UTC)
+
+	t.Run("open window", func(t *testing.T) {
+		since, until, err := parseTimeWindow("", "", now)
+		require.NoError(t, err)
+		assert.True(t, since.IsZero())
+		assert.True(t, until.IsZero())
+	})
+
+	t.Run("relative and absolute", func(t *testing.T) {
+		since, until, err := parseTimeWindow("2026-03-01T14:00:00Z", "30m", now)
+		require.NoError(t, err)
+		assert.Equal(t, time.Date(2026, 3, 1, 14, 0, 0, 0, time.UTC), since)
+		assert.Equal(t, now.Add(-30*time.Minute), until)
+	})
+
+	t.Run("since after until", func(t *testing.T) {
+		_, _, err := parseTimeWindow("30m", "2h", now)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "is after --until")
+	})
+
+	t.Run("invalid value names the flag", func(t *testing.T) {
+		_, _, err := parseTimeWindow("", "soon", now)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "--until")
+	})
+}
+
+func TestFilterByTimeWindow(t *testing.T) {
+	base := time.Date(2026, 3, 1, 14, 0, 0, 0, time.UTC)
+	emails := []*vaultsandbox.Email{
+		{ID: "before", ReceivedAt: base.Add(-time.Second)},
+		{ID: "start", ReceivedAt: base},
+		{ID: "middle", ReceivedAt: base.Add(30 * time.Minute)},
+		{ID: "end", ReceivedAt: base.Add(time.Hour)},
+		{ID: "after", ReceivedAt: base.Add(time.Hour + time.Second)},
+	}
+
+	ids := func(list []*vaultsandbox.Email) []string {
+		var out []string
+		for _, e := range list {
+			out = append(out, e.ID)
+		}
+		return out
+	}
+
+	t.Run("inclusive bounds", func(t *testing.T) {
+		got := filterByTimeWindow(emails, base, base.Add(time.Hour))
+		assert.Equal(t, []string{"start", "middle", "end"}, ids(got))
+	})
+
+	t.Run("since only", func(t *testing.T) {
+		got := filterByTimeWindow(emails, base.Add(time.Hour), time.Time{})
+		assert.Equal(t, []string{"end", "after"}, ids(got))
+	})
+
+	t.Run("no bounds keeps all", func(t *testing.T) {
+		assert.Len(t, filterByTimeWindow(emails, time.Time{}, time.Time{}), 5)
+	})
+}
+
+func TestCountInTimeWindow(t *testing.T) {
+	base := time.Date(2026, 3, 1, 14, 0, 0, 0, time.UTC)
+	metadata := []*vaultsandbox.EmailMetadata{
+		{ID: "before", ReceivedAt: base.Add(-time.Minute)},
+		{ID: "start", ReceivedAt: base},
+		{ID: "after", ReceivedAt: base.Add(time.Hour)},
+	}
+
+	assert.Equal(t, 2, countInTimeWindow(metadata, base, time.Time{}, nil))
+	assert.Equal(t, 1, countInTimeWindow(metadata, base, base.Add(time.Minute), nil))
+	assert.Equal(t, 3, countInTimeWindow(metadata, time.Time{}, time.Time{}, nil))
+	assert.Equal(t, 0, countInTimeWindow(nil, base, time.Time{}, nil))
+
+	read := &config.ReadState{}
+	read.MarkRead(base, "start")
+	assert.Equal(t, 2, countInTimeWindow(metadata, time.Time{}, time.Time{}, read))
+	assert.Equal(t, 1, countInTimeWindow(metadata, base, time.Time{}, read))
+}
+
+func TestFilterUnread(t *testing.T) {
+	emails := []*vaultsandbox.Email{{ID: "a"}, {ID: "b"}, {ID: "c"}}
+	read := &config.ReadState{}
+	read.MarkRead(time.Now(), "b")
+
+	filtered := filterUnread(emails, read)
+	require.Len(t, filtered, 2)
+	assert.Equal(t, "a", filtered[0].ID)
+	assert.Equal(t, "c", filtered[1].ID)
+
+	assert.Len(t, filterUnread(emails, &config.ReadState{}), 3)
+}
+
+func TestFilterByContent(t *testing.T) {
+	emails := []*vaultsandbox.Email{
+		{ID: "plain"},
+		{ID: "link", Links: []string{"https://example.com"}},
+		{ID: "attachment", Attachments: []vaultsandbox.Attachment{{Filename: "a.pdf"}}},
+		{ID: "both", Links: []string{"https://example.com"}, Attachments: []vaultsandbox.Attachment{{Filename: "b.pdf"}}},
+	}
+	ids := func(emails []*vaultsandbox.Email) []string {
+		var out []string
+		for _, e := range emails {
+			out = append(out, e.ID)
+		}
+		return out
+	}
+
+	assert.Equal(t, []string{"link", "both"}, ids(filterByContent(emails, true, false)))
+	assert.Equal(t, []string{"attachment", "both"}, ids(filterByContent(emails, false, true)))
+	assert.Equal(t, []string{"both"}, ids(filterByContent(emails, true, true)))
+	assert.Empty(t, filterByContent(emails[:1], true, false))
+}
+
+func TestEmailFilter(t *testing.T) {
+	emails := []*vaultsandbox.Email{
+		{ID: "invoice", Subject: "Invoice #42", From: "billing@ourapp.com", Text: "Total due"},
+		{ID: "receipt", Subject: "Receipt", From: "billing@ourapp.com", Text: "Paid"},
+		{ID: "welcome", Subject: "Welcome", From: "hello@ourapp.com", Text: "Hi"},
+	}
+	ids := func(emails []*vaultsandbox.Email) []string {
+		var out []string
+		for _, e := range emails {
+			out = append(out, e.ID)
+		}
+		return out
+	}
+	match := func(f emailFilter) []string {
+		m, err := f.compile()
+		require.NoError(t, err)
+		return ids(filterEmails(emails, m))
+	}
+
+	assert.False(t, emailFilter{}.isSet())
+	assert.True(t, emailFilter{From: "x"}.isSet())
+
+	assert.Equal(t, []string{"invoice", "receipt"}, match(emailFilter{From: "billing@ourapp.com"}))
+	assert.Equal(t, []string{"invoice"}, match(emailFilter{From: "billing@ourapp.com", SubjectRegex: "Invoice"}))
+	assert.Equal(t, []string{"welcome"}, match(emailFilter{FromRegex: "^hello@"}))
+	assert.Equal(t, []string{"receipt"}, match(emailFilter{Subject: "Receipt", BodyRegex: "Paid"}))
+	assert.Len(t, match(emailFilter{}), 3)
+
+	_, err := emailFilter{SubjectRegex: "("}.compile()
+	assert.ErrorContains(t, err, "invalid subject regex")
+}
+
+func TestSenderDomainMatcher(t *testing.T) {
+	emails := []*vaultsandbox.Email{
+		{ID: "bare", From: "noreply@github.com"},
+		{ID: "named", From: "GitHub <notifications@GitHub.com>"},
+		{ID: "quoted", From: `"Doe, Jane" <jane@mail.example.org>`},
+		{ID: "angle", From: "<billing@example.org>"},
+		{ID: "invalid", From: "not an address"},
+	}
+	ids := func(emails []*vaultsandbox.Email) []string {
+		var out []string
+		for _, e := range emails {
+			out = append(out, e.ID)
+		}
+		return out
+	}
+	match := func(domains []string, pattern string) []string {
+		m, err := senderDomainMatcher(domains, pattern)
+		require.NoError(t, err)
+		require.NotNil(t, m)
+		return ids(filterEmails(emails, m))
+	}
+
+	m, err := senderDomainMatcher(nil, "")
+	require.NoError(t, err)
+	assert.Nil(t, m)
+	m, err = senderDomainMatcher([]string{" ", ""}, "")
+	require.NoError(t, err)
+	assert.Nil(t, m)
+
+	assert.Equal(t, []string{"bare", "named"}, match([]string{"github.com"}, ""))
+	assert.Equal(t, []string{"bare", "named", "angle"}, match([]string{"@GitHub.com", " example.org"}, ""))
+	assert.Equal(t, []string{"quoted", "angle"}, match(nil, `(^|\.)example\.org$`))
+	assert.Equal(t, []string{"angle"}, match([]string{"example.org", "github.com"}, `^example\.`))
+
+	_, err = senderDomainMatcher(nil, "(")
+	assert.ErrorContains(t, err, "invalid sender domain regex")
+}
+
+func TestWriteEmailsTable(t *testing.T) {
+	emails := []*vaultsandbox.Email{
+		{ID: "a1", Subject: "Welcome", From: "hello@app.test", ReceivedAt: time.Now()},
+		{ID: "b2", From: "x@y.test", ReceivedAt: time.Now()},
+	}
+	read := &config.ReadState{}
+	read.MarkRead(time.Now(), "a1")
+
+	var buf bytes.Buffer
+	require.NoError(t, writeEmailsTable(&buf, 0, emails, read))
+	assert.Equal(t, ""+
+		"ID  SUBJECT       FROM            RECEIVED  READ\n"+
+		"a1  Welcome       hello@app.test  just now  yes\n"+
+		"b2  (no subject)  x@y.test        just now  no\n", buf.String())
+
+	long := []*vaultsandbox.Email{{
+		ID:         "c3",
+		Subject:    "Your weekly digest of everything that happened",
+		From:       "notifications@newsletter.example.com",
+		ReceivedAt: time.Now(),
+	}}
+
+	t.Run("fits width", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, writeEmailsTable(&buf, 50, long, read))
+		assert.Equal(t, ""+
+			"ID  SUBJECT           FROM          RECEIVED  READ\n"+
+			"c3  Your weekly dig…  notificatio…  just now  no\n", buf.String())
+	})
+
+	t.Run("wide keeps whole cells", func(t *testing.T) {
+		listWide = true
+		t.Cleanup(func() { listWide = false })
+
+		var buf bytes.Buffer
+		require.NoError(t, writeEmailsTable(&buf, 50, long, read))
+		assert.Contains(t, buf.String(), "  Your weekly digest of everything that happened  notifications@newsletter.example.com  ")
+	})
+}
+
+func TestWriteEmailsCSV(t *testing.T) {
+	received := time.Date(2026, 3, 1, 14, 30, 0, 0, time.UTC)
+	emails := []*vaultsandbox.Email{
+		{
+			ID:          "e1",
+			ReceivedAt:  received,
+			From:        `"Doe, Jane" <jane@example.com>`,
+			To:          []string{"a@vsx.email", "b@vsx.email"},
+			Subject:     "Hello, \"world\"\nsecond line",
+			Text:        "hello",
+			HTML:        "<p>hello</p>",
+			Links:       []string{"https://example.com"},
+			Attachments: []vaultsandbox.Attachment{{Filename: "a.txt", Size: 100}},
+		},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, writeEmailsCSV(&buf, emails))
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	assert.Equal(t, csvHeader, records[0])
+	assert.Equal(t, []string{
+		"e1",
+		"2026-03-01T14:30:00Z",
+		`"Doe, Jane" <jane@example.com>`,
+		"a@vsx.email, b@vsx.email",
+		"Hello, \"world\"\nsecond line",
+		"1",
+		"1",
+		"117",
+	}, records[1])
+
+	t.Run("header only when empty", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, writeEmailsCSV(&buf, nil))
+		assert.Equal(t, "id,receivedAt,from,to,subject,links,attachments,sizeBytes\n", buf.String())
+	})
+}
@@ -0,0 +1,134 @@
+package email
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/vaultsandbox/vsb-cli/internal/cliutil"
+	"github.com/vaultsandbox/vsb-cli/internal/config"
+	"github.com/vaultsandbox/vsb-cli/internal/styles"
+)
+
+var markReadCmd = &cobra.Command{
+	Use:   "mark-read [email-id...]",
+	Short: "Mark emails as read on this machine",
+	Long: `Mark emails as read in the local read state used by 'email list --unread'.
+
+Read state is kept per inbox in the config directory and is never sent to
+the server. With --all, every email currently in the inbox is marked.
+
+Examples:
+  vsb email mark-read abc123
+  vsb email mark-read --all
+  vsb email mark-read --all --inbox foo@abc123.vsx.email`,
+	RunE: runMarkRead,
+}
+
+var markUnreadCmd = &cobra.Command{
+	Use:   "mark-unread <email-id...>",
+	Short: "Mark emails as unread on this machine",
+	Long: `Remove emails from the local read state, so 'email list --unread' shows
+them again.
+
+Examples:
+  vsb email mark-unread abc123`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runMarkUnread,
+}
+
+var markReadAll bool
+
+func init() {
+	Cmd.AddCommand(markReadCmd)
+	Cmd.AddCommand(markUnreadCmd)
+	cliutil.AddOutputFormats(markReadCmd, cliutil.FormatJSON)
+	cliutil.AddOutputFormats(markUnreadCmd, cliutil.FormatJSON)
+
+	markReadCmd.Flags().BoolVarP(&markReadAll, "all", "a", false,
+		"Mark every email in the inbox as read")
+}
+
+func runMarkRead(cmd *cobra.Command, args []string) error {
+	if markReadAll && len(args) > 0 {
+		return fmt.Errorf("--all cannot be used with email IDs")
+	}
+	if !markReadAll && len(args) == 0 {
+		return fmt.Errorf("specify one or more email IDs, or --all")
+	}
+
+	var inboxEmail string
+	ids := args
+	if markReadAll {
+		ctx := cliutil.CommandContext(cmd)
+		inbox, cleanup, err := cliutil.LoadAndImportInbox(ctx, InboxFlag)
+		if err != nil {
+			return err
+		}
+		defer cleanup()
+
+		metadata, err := inbox.GetEmailsMetadataOnly(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to get emails: %w", err)
+		}
+		inboxEmail = inbox.EmailAddress()
+		ids = make([]string, len(metadata))
+		for i, m := range metadata {
+			ids[i] = m.ID
+		}
+	} else {
+		stored, err := loadStoredInbox()
+		if err != nil {
+			return err
+		}
+		inboxEmail = stored.Email
+	}
+
+	err := config.UpdateReadState(inboxEmail, func(rs *config.ReadState) {
+		rs.MarkRead(time.Now(), ids...)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update read state: %w", err)
+	}
+	return printMarkResult(cmd, inboxEmail, ids, "read")
+}
+
+func runMarkUnread(cmd *cobra.Command, args []string) error {
+	stored, err := loadStoredInbox()
+	if err != nil {
+		return err
+	}
+
+	err = config.UpdateReadState(stored.Email, func(rs *config.ReadState) {
+		rs.MarkUnread(args...)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update read state: %w", err)
+	}
+	return printMarkResult(cmd, stored.Email, args, "unread")
+}
+
+// loadStoredInbox returns the --inbox (or active) inbox from the keystore
+// without contacting the server.
+func loadStoredInbox() (*config.StoredInbox, error) {
+	ks, err := cliutil.LoadKeystoreOrError()
+	if err != nil {
+		return nil, err
+	}
+	return cliutil.GetInbox(ks, InboxFlag)
+}
+
+func printMarkResult(cmd *cobra.Command, inboxEmail string, ids []string, state string) error {
+	if cliutil.GetOutput(cmd) == cliutil.FormatJSON {
+		if ids == nil {
+			ids = []string{}
+		}
+		return cliutil.OutputJSON(map[string]interface{}{
+			"inbox": inboxEmail,
+			"ids":   ids,
+			"state": state,
+		})
+	}
+	fmt.Println(styles.PassStyle.Render(fmt.Sprintf("✓ Marked %d email(s) %s", len(ids), state)))
+	return nil
+}
@@ -0,0 +1,56 @@
+package email
+
+import (
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vaultsandbox/vsb-cli/internal/config"
+)
+
+func TestMarkReadUnread(t *testing.T) {
+	t.Setenv("VSB_CONFIG_DIR", t.TempDir())
+	ks, err := config.LoadKeystore()
+	require.NoError(t, err)
+	require.NoError(t, ks.AddInbox(config.StoredInbox{Email: "inbox@example.com", ExpiresAt: time.Now().Add(time.Hour)}))
+
+	cmd := &cobra.Command{}
+	cmd.Flags().StringP("output", "o", "", "")
+
+	readState := func() *config.ReadState {
+		rs, err := config.LoadReadState("inbox@example.com")
+		require.NoError(t, err)
+		return rs
+	}
+
+	captureURLStdout(t, func() {
+		require.NoError(t, runMarkRead(cmd, []string{"one", "two"}))
+	})
+	assert.True(t, readState().IsRead("one"))
+	assert.True(t, readState().IsRead("two"))
+
+	output := captureURLStdout(t, func() {
+		require.NoError(t, runMarkUnread(cmd, []string{"one"}))
+	})
+	assert.Contains(t, output, "Marked 1 email(s) unread")
+	assert.False(t, readState().IsRead("one"))
+	assert.True(t, readState().IsRead("two"))
+
+	t.Run("requires IDs or --all", func(t *testing.T) {
+		assert.EqualError(t, runMarkRead(cmd, nil), "specify one or more email IDs, or --all")
+	})
+
+	t.Run("--all with IDs", func(t *testing.T) {
+		markReadAll = true
+		defer func() { markReadAll = false }()
+		assert.EqualError(t, runMarkRead(cmd, []string{"one"}), "--all cannot be used with email IDs")
+	})
+
+	t.Run("unknown inbox", func(t *testing.T) {
+		InboxFlag = "nope"
+		defer func() { InboxFlag = "" }()
+		assert.Error(t, runMarkUnread(cmd, []string{"one"}))
+	})
+}
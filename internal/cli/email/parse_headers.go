@@ -0,0 +1,208 @@
+package email
+
+import (
+	"fmt"
+	"net/mail"
+	"net/textproto"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/vaultsandbox/vsb-cli/internal/cliutil"
+	"github.com/vaultsandbox/vsb-cli/internal/styles"
+)
+
+var parseHeadersCmd = &cobra.Command{
+	Use:   "parse-headers [email-id]",
+	Short: "Show all email headers grouped and sorted",
+	Long: `Display every header of an email, read from the raw message source.
+
+Headers are sorted by name and grouped into:
+- Standard: From, To, Subject, Date, Message-ID, MIME-Version, Content-Type
+- Routing:  Received, Return-Path, Delivered-To and forwarding headers
+- Custom:   Other X- headers
+- Other:    Everything else (DKIM-Signature, Authentication-Results, ...)
+
+Headers that appear more than once, such as Received, keep every value in
+the order they appear in the message. With --output json, multi-value
+headers are arrays.
+
+Examples:
+  vsb email parse-headers                   # Most recent email
+  vsb email parse-headers abc123            # Specific email
+  vsb email parse-headers --header received # Just the Received headers
+  vsb email parse-headers -o json`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runParseHeaders,
+}
+
+var parseHeadersName string
+
+func init() {
+	Cmd.AddCommand(parseHeadersCmd)
+	cliutil.AddOutputFormats(parseHeadersCmd, cliutil.FormatJSON)
+
+	parseHeadersCmd.Flags().StringVar(&parseHeadersName, "header", "",
+		"Print only the value of this header (case-insensitive)")
+}
+
+// Header group names, in display order
+const (
+	headerGroupStandard = "Standard"
+	headerGroupRouting  = "Routing"
+	headerGroupCustom   = "Custom"
+	headerGroupOther    = "Other"
+)
+
+var headerGroupOrder = []string{headerGroupStandard, headerGroupRouting, headerGroupCustom, headerGroupOther}
+
+var standardHeaders = canonicalSet(
+	"From", "To", "Subject", "Date", "Message-ID", "MIME-Version", "Content-Type",
+)
+
+var routingHeaders = canonicalSet(
+	"Received", "Return-Path", "Delivered-To",
+	"X-Forwarded-To", "X-Forwarded-For", "X-Original-To",
+)
+
+func canonicalSet(names ...string) map[string]bool {
+	set := make(map[string]bool, len(names))
+	for _, n := range names {
+		set[textproto.CanonicalMIMEHeaderKey(n)] = true
+	}
+	return set
+}
+
+// headerField is a header name with all of its values
+type headerField struct {
+	Name   string
+	Values []string
+}
+
+// headerGroup is a named set of headers sorted by name
+type headerGroup struct {
+	Name   string
+	Fields []headerField
+}
+
+func runParseHeaders(cmd *cobra.Command, args []string) error {
+	ctx := cliutil.CommandContext(cmd)
+
+	emailID := cliutil.GetArg(args, 0, "")
+
+	email, inbox, cleanup, err := cliutil.GetEmailByIDOrLatest(ctx, emailID, InboxFlag)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	raw, err := inbox.GetRawEmail(ctx, email.ID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch raw email: %w", err)
+	}
+
+	header, err := parseRawHeaders(raw)
+	if err != nil {
+		return err
+	}
+
+	if parseHeadersName != "" {
+		values := header[textproto.CanonicalMIMEHeaderKey(parseHeadersName)]
+		if len(values) == 0 {
+			return fmt.Errorf("header not found: %s", parseHeadersName)
+		}
+		if cliutil.GetOutput(cmd) == cliutil.FormatJSON {
+			return cliutil.OutputJSON(headerValue(values))
+		}
+		for _, v := range values {
+			fmt.Println(v)
+		}
+		return nil
+	}
+
+	if cliutil.GetOutput(cmd) == cliutil.FormatJSON {
+		return cliutil.OutputJSON(headersJSON(header))
+	}
+
+	for i, group := range groupHeaders(header) {
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Println(styles.SectionStyle.Render(strings.ToUpper(group.Name)))
+		for _, field := range group.Fields {
+			for _, v := range field.Values {
+				fmt.Printf("%s %s\n", styles.LabelStyle.Render(field.Name+":"), v)
+			}
+		}
+	}
+	return nil
+}
+
+// parseRawHeaders reads the header block of an RFC 5322 message. Folded
+// lines are unfolded; repeated headers keep all values.
+func parseRawHeaders(raw string) (mail.Header, error) {
+	// A header-only message may lack the blank line that ends the block
+	if !strings.Contains(raw, "\n\n") && !strings.Contains(raw, "\r\n\r\n") {
+		raw += "\r\n\r\n"
+	}
+	msg, err := mail.ReadMessage(strings.NewReader(raw))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse email headers: %w", err)
+	}
+	return msg.Header, nil
+}
+
+// headerGroupName returns the group a canonical header name belongs to.
+func headerGroupName(name string) string {
+	switch {
+	case standardHeaders[name]:
+		return headerGroupStandard
+	case routingHeaders[name]:
+		return headerGroupRouting
+	case strings.HasPrefix(name, "X-"):
+		return headerGroupCustom
+	default:
+		return headerGroupOther
+	}
+}
+
+// groupHeaders sorts headers by name into their display groups. Empty
+// groups are omitted.
+func groupHeaders(header mail.Header) []headerGroup {
+	names := make([]string, 0, len(header))
+	for name := range header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	byGroup := make(map[string][]headerField)
+	for _, name := range names {
+		g := headerGroupName(name)
+		byGroup[g] = append(byGroup[g], headerField{Name: name, Values: header[name]})
+	}
+
+	var groups []headerGroup
+	for _, g := range headerGroupOrder {
+		if fields := byGroup[g]; len(fields) > 0 {
+			groups = append(groups, headerGroup{Name: g, Fields: fields})
+		}
+	}
+	return groups
+}
+
+// headersJSON converts headers for JSON output: single values are strings,
+// repeated headers are arrays.
+func headersJSON(header mail.Header) map[string]interface{} {
+	result := make(map[string]interface{}, len(header))
+	for name, values := range header {
+		result[name] = headerValue(values)
+	}
+	return result
+}
+
+func headerValue(values []string) interface{} {
+	if len(values) == 1 {
+		return values[0]
+	}
+	return values
+}
@@ -0,0 +1,77 @@
+package email
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testRawHeaders = "Received: from mx2.example.net by mx.vsx.email\r\n" +
+	"Received: from client.example.net\r\n" +
+	"\tby mx2.example.net\r\n" +
+	"From: Sender <sender@example.com>\r\n" +
+	"To: user@vsx.email\r\n" +
+	"Subject: Hello\r\n" +
+	"Message-ID: <abc@example.com>\r\n" +
+	"DKIM-Signature: v=1; s=selector1; d=example.com\r\n" +
+	"X-Mailer: test\r\n" +
+	"X-Forwarded-To: other@vsx.email\r\n" +
+	"\r\n" +
+	"Body\r\n"
+
+func TestParseRawHeaders(t *testing.T) {
+	header, err := parseRawHeaders(testRawHeaders)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"from mx2.example.net by mx.vsx.email",
+		"from client.example.net by mx2.example.net",
+	}, header["Received"])
+	assert.Equal(t, "<abc@example.com>", header.Get("message-id"))
+
+	t.Run("headers without body", func(t *testing.T) {
+		header, err := parseRawHeaders("Subject: Only headers\r\nFrom: a@example.com")
+		require.NoError(t, err)
+		assert.Equal(t, "Only headers", header.Get("Subject"))
+	})
+}
+
+func TestGroupHeaders(t *testing.T) {
+	header, err := parseRawHeaders(testRawHeaders)
+	require.NoError(t, err)
+
+	groups := groupHeaders(header)
+	names := make(map[string][]string)
+	var order []string
+	for _, g := range groups {
+		order = append(order, g.Name)
+		for _, f := range g.Fields {
+			names[g.Name] = append(names[g.Name], f.Name)
+		}
+	}
+
+	assert.Equal(t, []string{"Standard", "Routing", "Custom", "Other"}, order)
+	assert.Equal(t, []string{"From", "Message-Id", "Subject", "To"}, names["Standard"])
+	assert.Equal(t, []string{"Received", "X-Forwarded-To"}, names["Routing"])
+	assert.Equal(t, []string{"X-Mailer"}, names["Custom"])
+	assert.Equal(t, []string{"Dkim-Signature"}, names["Other"])
+
+	t.Run("empty groups omitted", func(t *testing.T) {
+		header, err := parseRawHeaders("Subject: Hi\r\n\r\n")
+		require.NoError(t, err)
+		groups := groupHeaders(header)
+		require.Len(t, groups, 1)
+		assert.Equal(t, "Standard", groups[0].Name)
+	})
+}
+
+func TestHeadersJSON(t *testing.T) {
+	header, err := parseRawHeaders(testRawHeaders)
+	require.NoError(t, err)
+
+	result := headersJSON(header)
+	assert.Equal(t, "Hello", result["Subject"])
+	assert.Len(t, result["Received"], 2)
+	assert.IsType(t, []string{}, result["Received"])
+}
@@ -0,0 +1,153 @@
+package email
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	vaultsandbox "github.com/vaultsandbox/client-go"
+	"github.com/vaultsandbox/vsb-cli/internal/cliutil"
+)
+
+// loadRecheckInboxFunc opens the inbox an email is rechecked in; overridden
+// in tests
+var loadRecheckInboxFunc = cliutil.LoadAndImportInbox
+
+// getRecheckEmailFunc fetches the server's current copy of an email;
+// overridden in tests
+var getRecheckEmailFunc = func(ctx context.Context, inbox *vaultsandbox.Inbox, emailID string) (*vaultsandbox.Email, error) {
+	return inbox.GetEmail(ctx, emailID)
+}
+
+// recheckInterval is how often --wait-for-complete fetches the email again;
+// overridden in tests
+var recheckInterval = 2 * time.Second
+
+// Processing status of an email in 'email recheck' output
+const (
+	processingPending  = "pending"
+	processingComplete = "complete"
+)
+
+var recheckCmd = &cobra.Command{
+	Use:   "recheck <email-id>",
+	Short: "Fetch an email again to pick up server-side changes",
+	Long: `Fetch an email from the server again and print its current data as JSON.
+
+The server analyses received emails asynchronously, so the authentication
+results and the security score computed from them may be missing right
+after an email arrives. "processingStatus" in the output is "pending" until
+the server has attached the authentication results, then "complete".
+
+--wait-for-complete fetches the email every 2s until its processing is
+complete, failing with a timeout error if that takes longer than --timeout.
+
+Examples:
+  vsb email recheck abc123
+  vsb email recheck abc123 --wait-for-complete
+  vsb email recheck abc123 --wait-for-complete --timeout 2m --inbox foo@abc123.vsx.email`,
+	Args: cobra.ExactArgs(1),
+	RunE: runRecheck,
+}
+
+var (
+	recheckWaitForComplete bool
+	recheckTimeout         string
+)
+
+func init() {
+	Cmd.AddCommand(recheckCmd)
+	cliutil.AddOutputFormats(recheckCmd, cliutil.FormatJSON)
+
+	recheckCmd.Flags().BoolVar(&recheckWaitForComplete, "wait-for-complete", false,
+		"Fetch the email again until the server has finished processing it")
+	recheckCmd.Flags().StringVar(&recheckTimeout, "timeout", "60s",
+		"Maximum time --wait-for-complete waits")
+}
+
+func runRecheck(cmd *cobra.Command, args []string) error {
+	ctx := cliutil.CommandContext(cmd)
+	emailID := args[0]
+
+	timeout, err := time.ParseDuration(recheckTimeout)
+	if err != nil {
+		return fmt.Errorf("invalid timeout format: %w", err)
+	}
+
+	inbox, cleanup, err := loadRecheckInboxFunc(ctx, InboxFlag)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	email, err := getRecheckEmailFunc(ctx, inbox, emailID)
+	if err != nil {
+		return fmt.Errorf("failed to get email %s: %w", emailID, err)
+	}
+	if recheckWaitForComplete && processingStatus(email) == processingPending {
+		if email, err = waitForProcessing(ctx, inbox, emailID, timeout); err != nil {
+			return err
+		}
+	}
+
+	return cliutil.OutputJSON(recheckJSON(email))
+}
+
+// waitForProcessing fetches the email every recheckInterval until its
+// processing is complete or timeout is reached
+func waitForProcessing(ctx context.Context, inbox *vaultsandbox.Inbox, emailID string, timeout time.Duration) (*vaultsandbox.Email, error) {
+	waitCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	ticker := time.NewTicker(recheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-waitCtx.Done():
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			return nil, &cliutil.TimeoutError{What: "email processing", Timeout: timeout}
+		case <-ticker.C:
+		}
+
+		email, err := getRecheckEmailFunc(waitCtx, inbox, emailID)
+		if err != nil {
+			if errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil {
+				return nil, &cliutil.TimeoutError{What: "email processing", Timeout: timeout}
+			}
+			return nil, fmt.Errorf("failed to get email %s: %w", emailID, err)
+		}
+		if processingStatus(email) == processingComplete {
+			return email, nil
+		}
+	}
+}
+
+// processingStatus reports whether the server has finished analysing the
+// email. The SDK has no explicit status, so an email is pending until its
+// authentication results are attached.
+func processingStatus(email *vaultsandbox.Email) string {
+	if email.AuthResults == nil {
+		return processingPending
+	}
+	return processingComplete
+}
+
+// recheckJSON is everything known about the email, with its processing
+// status
+func recheckJSON(email *vaultsandbox.Email) map[string]interface{} {
+	data := cliutil.EmailJSON(email, cliutil.EmailJSONOptions{
+		IncludeTo:          true,
+		IncludeBody:        true,
+		IncludeLinks:       true,
+		IncludeHeaders:     true,
+		IncludeAuthResults: true,
+		IncludeScore:       true,
+		IncludeAttachments: true,
+	})
+	data["processingStatus"] = processingStatus(email)
+	return data
+}
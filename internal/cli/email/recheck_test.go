@@ -0,0 +1,147 @@
+package email
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	vaultsandbox "github.com/vaultsandbox/client-go"
+	"github.com/vaultsandbox/client-go/authresults"
+	"github.com/vaultsandbox/vsb-cli/internal/cliutil"
+)
+
+// recheckServer stands in for the server: each fetch returns the next
+// version of the email, and the last one once they run out
+type recheckServer struct {
+	mu       sync.Mutex
+	versions []*vaultsandbox.Email
+	err      error
+	fetches  int
+}
+
+func (s *recheckServer) get(ctx context.Context, inbox *vaultsandbox.Inbox, emailID string) (*vaultsandbox.Email, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.fetches++
+	if s.err != nil {
+		return nil, s.err
+	}
+	i := s.fetches - 1
+	if i >= len(s.versions) {
+		i = len(s.versions) - 1
+	}
+	return s.versions[i], nil
+}
+
+func useRecheckServer(t *testing.T, server *recheckServer) {
+	t.Helper()
+	oldLoad, oldGet, oldInterval := loadRecheckInboxFunc, getRecheckEmailFunc, recheckInterval
+	t.Cleanup(func() {
+		loadRecheckInboxFunc, getRecheckEmailFunc, recheckInterval = oldLoad, oldGet, oldInterval
+		recheckWaitForComplete, recheckTimeout = false, "60s"
+	})
+	loadRecheckInboxFunc = func(ctx context.Context, emailFlag string) (*vaultsandbox.Inbox, func(), error) {
+		return nil, func() {}, nil
+	}
+	getRecheckEmailFunc = server.get
+	recheckInterval = time.Millisecond
+}
+
+func runRecheckJSON(t *testing.T, emailID string) (map[string]interface{}, error) {
+	t.Helper()
+	var err error
+	output := captureURLStdout(t, func() {
+		err = runRecheck(&cobra.Command{Use: "recheck"}, []string{emailID})
+	})
+	if err != nil {
+		return nil, err
+	}
+	var data map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(output), &data))
+	return data, nil
+}
+
+func TestRunRecheck(t *testing.T) {
+	pending := &vaultsandbox.Email{ID: "email-1", Subject: "Welcome", From: "a@example.com"}
+	analysed := &vaultsandbox.Email{
+		ID: "email-1", Subject: "Welcome", From: "a@example.com",
+		AuthResults: &authresults.AuthResults{
+			SPF:   &authresults.SPFResult{Result: "pass", Domain: "example.com"},
+			DKIM:  []authresults.DKIMResult{{Result: "pass", Domain: "example.com"}},
+			DMARC: &authresults.DMARCResult{Result: "pass"},
+		},
+		Attachments: []vaultsandbox.Attachment{{Filename: "report.pdf", ContentType: "application/pdf", Size: 3}},
+	}
+
+	t.Run("prints what the server has now", func(t *testing.T) {
+		server := &recheckServer{versions: []*vaultsandbox.Email{pending, analysed}}
+		useRecheckServer(t, server)
+
+		data, err := runRecheckJSON(t, "email-1")
+		require.NoError(t, err)
+		assert.Equal(t, "pending", data["processingStatus"])
+		assert.NotContains(t, data, "authResults")
+
+		data, err = runRecheckJSON(t, "email-1")
+		require.NoError(t, err)
+		assert.Equal(t, "complete", data["processingStatus"])
+		assert.Contains(t, data, "authResults")
+		assert.Len(t, data["attachments"], 1)
+		assert.Greater(t, data["securityScore"], float64(0))
+		assert.Equal(t, 2, server.fetches)
+	})
+
+	t.Run("wait for complete fetches until processed", func(t *testing.T) {
+		server := &recheckServer{versions: []*vaultsandbox.Email{pending, pending, analysed}}
+		useRecheckServer(t, server)
+		recheckWaitForComplete = true
+
+		data, err := runRecheckJSON(t, "email-1")
+		require.NoError(t, err)
+		assert.Equal(t, "complete", data["processingStatus"])
+		assert.Equal(t, 3, server.fetches)
+	})
+
+	t.Run("wait for complete returns at once when processed", func(t *testing.T) {
+		server := &recheckServer{versions: []*vaultsandbox.Email{analysed}}
+		useRecheckServer(t, server)
+		recheckWaitForComplete = true
+
+		_, err := runRecheckJSON(t, "email-1")
+		require.NoError(t, err)
+		assert.Equal(t, 1, server.fetches)
+	})
+
+	t.Run("wait for complete times out", func(t *testing.T) {
+		server := &recheckServer{versions: []*vaultsandbox.Email{pending}}
+		useRecheckServer(t, server)
+		recheckWaitForComplete = true
+		recheckTimeout = "20ms"
+
+		_, err := runRecheckJSON(t, "email-1")
+		var timeoutErr *cliutil.TimeoutError
+		require.ErrorAs(t, err, &timeoutErr)
+		assert.Equal(t, 20*time.Millisecond, timeoutErr.Timeout)
+	})
+
+	t.Run("fetch errors are reported", func(t *testing.T) {
+		useRecheckServer(t, &recheckServer{err: errors.New("email not found")})
+
+		_, err := runRecheckJSON(t, "missing")
+		assert.EqualError(t, err, "failed to get email missing: email not found")
+	})
+
+	t.Run("invalid timeout", func(t *testing.T) {
+		useRecheckServer(t, &recheckServer{versions: []*vaultsandbox.Email{pending}})
+		recheckTimeout = "soon"
+
+		_, err := runRecheckJSON(t, "email-1")
+		assert.ErrorContains(t, err, "invalid timeout format")
+	})
+}
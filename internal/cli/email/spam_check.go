@@ -0,0 +1,87 @@
+package email
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/vaultsandbox/vsb-cli/internal/analysis"
+	"github.com/vaultsandbox/vsb-cli/internal/cliutil"
+	"github.com/vaultsandbox/vsb-cli/internal/styles"
+)
+
+var spamCheckCmd = &cobra.Command{
+	Use:   "spam-check [email-id]",
+	Short: "Check an email for common spam characteristics",
+	Long: `Run heuristic spam checks on an email and report a risk level.
+
+Checks:
+- allCaps:              Subject written in ALL CAPS
+- excessiveExclamation: Repeated exclamation marks in subject or body
+- mismatchedSender:     Display name names a different domain than the sender
+- suspiciousRedirect:   Links via URL shorteners, IP addresses or open redirects
+- missingUnsubscribe:   No unsubscribe link or List-Unsubscribe header
+
+The risk level (low, medium or high) is based on the weighted sum of the
+triggered rules. These are heuristics to catch obvious problems before
+real spam filters do, not a spam filter.
+
+Examples:
+  vsb email spam-check              # Check most recent email
+  vsb email spam-check abc123       # Check specific email
+  vsb email spam-check -o json      # JSON output for CI/CD`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runSpamCheck,
+}
+
+func init() {
+	Cmd.AddCommand(spamCheckCmd)
+	cliutil.AddOutputFormats(spamCheckCmd, cliutil.FormatJSON)
+}
+
+func runSpamCheck(cmd *cobra.Command, args []string) error {
+	ctx := cliutil.CommandContext(cmd)
+
+	emailID := cliutil.GetArg(args, 0, "")
+
+	email, _, cleanup, err := getEmailByIDOrLatestFunc(ctx, emailID, InboxFlag)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	report := analysis.CheckSpam(email)
+
+	if cliutil.GetOutput(cmd) == cliutil.FormatJSON {
+		return cliutil.OutputJSON(report)
+	}
+
+	triggered := make(map[string]bool, len(report.TriggeredRules))
+	for _, id := range report.TriggeredRules {
+		triggered[id] = true
+	}
+
+	fmt.Printf("Subject: %s\n", email.Subject)
+	fmt.Printf("From: %s\n\n", email.From)
+	for _, rule := range analysis.SpamRules {
+		if triggered[rule.ID] {
+			fmt.Println(styles.FailStyle.Render(fmt.Sprintf("✗ %s: %s", rule.ID, rule.Description)))
+		} else {
+			fmt.Println(styles.PassStyle.Render(fmt.Sprintf("✓ %s", rule.ID)))
+		}
+	}
+
+	fmt.Printf("\nRisk level: %s (score %d)\n", riskStyle(report.RiskLevel), report.Score)
+	return nil
+}
+
+// riskStyle colors a risk level for display
+func riskStyle(level string) string {
+	switch level {
+	case analysis.RiskHigh:
+		return styles.FailStyle.Render(level)
+	case analysis.RiskMedium:
+		return styles.WarnStyle.Render(level)
+	default:
+		return styles.PassStyle.Render(level)
+	}
+}
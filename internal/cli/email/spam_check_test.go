@@ -0,0 +1,47 @@
+package email
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	vaultsandbox "github.com/vaultsandbox/client-go"
+)
+
+func TestRunSpamCheck(t *testing.T) {
+	oldFetcher := getEmailByIDOrLatestFunc
+	defer func() { getEmailByIDOrLatestFunc = oldFetcher }()
+
+	getEmailByIDOrLatestFunc = mockEmailFetcher(&vaultsandbox.Email{
+		Subject: "ACCOUNT SUSPENDED",
+		From:    `"PayPal.com" <alerts@evil.example>`,
+		Text:    "Unsubscribe here.",
+	}, nil)
+
+	t.Run("JSON output", func(t *testing.T) {
+		cmd := createTestCommand()
+		cmd.Flags().Set("output", "json")
+
+		output := captureURLStdout(t, func() {
+			require.NoError(t, runSpamCheck(cmd, []string{}))
+		})
+
+		var report struct {
+			RiskLevel      string   `json:"riskLevel"`
+			TriggeredRules []string `json:"triggeredRules"`
+		}
+		require.NoError(t, json.Unmarshal([]byte(output), &report))
+		assert.Equal(t, "medium", report.RiskLevel)
+		assert.Equal(t, []string{"allCaps", "mismatchedSender"}, report.TriggeredRules)
+	})
+
+	t.Run("pretty output lists rules", func(t *testing.T) {
+		output := captureURLStdout(t, func() {
+			require.NoError(t, runSpamCheck(createTestCommand(), []string{}))
+		})
+		assert.Contains(t, output, "✗ allCaps")
+		assert.Contains(t, output, "✓ missingUnsubscribe")
+		assert.Contains(t, output, "Risk level: medium")
+	})
+}
@@ -0,0 +1,178 @@
+package email
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/spf13/cobra"
+	vaultsandbox "github.com/vaultsandbox/client-go"
+	"github.com/vaultsandbox/vsb-cli/internal/cliutil"
+	"github.com/vaultsandbox/vsb-cli/internal/styles"
+)
+
+var statsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Summarize the emails in an inbox",
+	Long: `Summarize the emails in an inbox: how many arrived, from which sender
+domains, how many have attachments or links, when the first and last ones
+were received, and their average security score (the score 'email audit'
+reports).
+
+--since and --until limit the summary to emails received inside a time
+window, as with 'email list'.
+
+Examples:
+  vsb email stats
+  vsb email stats --inbox signup --since 24h
+  vsb email stats -o json | jq '.senderDomains'`,
+	Args: cobra.NoArgs,
+	RunE: runStats,
+}
+
+var (
+	statsSince string
+	statsUntil string
+)
+
+func init() {
+	Cmd.AddCommand(statsCmd)
+	cliutil.AddOutputFormats(statsCmd, cliutil.FormatJSON)
+
+	statsCmd.Flags().StringVar(&statsSince, "since", "",
+		"Only emails received at or after this time (RFC3339 or duration ago, e.g. 2h)")
+	statsCmd.Flags().StringVar(&statsUntil, "until", "",
+		"Only emails received at or before this time (RFC3339 or duration ago, e.g. 30m)")
+}
+
+// unknownDomain groups senders whose address can't be parsed
+const unknownDomain = "(unknown)"
+
+// domainCount is the number of emails from one sender domain
+type domainCount struct {
+	Domain string `json:"domain"`
+	Count  int    `json:"count"`
+}
+
+// emailStats summarizes a set of emails
+type emailStats struct {
+	Inbox              string        `json:"inbox"`
+	Total              int           `json:"total"`
+	WithAttachments    int           `json:"withAttachments"`
+	WithLinks          int           `json:"withLinks"`
+	EarliestReceivedAt string        `json:"earliestReceivedAt,omitempty"`
+	LatestReceivedAt   string        `json:"latestReceivedAt,omitempty"`
+	AverageScore       *float64      `json:"averageSecurityScore"` // nil without emails
+	SenderDomains      []domainCount `json:"senderDomains"`
+
+	earliest, latest time.Time // for pretty output
+}
+
+func runStats(cmd *cobra.Command, args []string) error {
+	ctx := cliutil.CommandContext(cmd)
+
+	since, until, err := parseTimeWindow(statsSince, statsUntil, time.Now())
+	if err != nil {
+		return err
+	}
+
+	inbox, cleanup, err := cliutil.LoadAndImportInbox(ctx, InboxFlag)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	emails, err := inbox.GetEmails(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get emails: %w", err)
+	}
+	emails = filterByTimeWindow(emails, since, until)
+
+	stats := computeStats(inbox.EmailAddress(), emails)
+	if cliutil.GetOutput(cmd) == cliutil.FormatJSON {
+		return cliutil.OutputJSON(stats)
+	}
+	printStats(stats)
+	return nil
+}
+
+// computeStats summarizes emails. Sender domains are ordered by count, then
+// name.
+func computeStats(inbox string, emails []*vaultsandbox.Email) emailStats {
+	stats := emailStats{Inbox: inbox, Total: len(emails), SenderDomains: []domainCount{}}
+	if len(emails) == 0 {
+		return stats
+	}
+
+	domains := map[string]int{}
+	earliest, latest := emails[0].ReceivedAt, emails[0].ReceivedAt
+	scoreSum := 0
+	for _, e := range emails {
+		if len(e.Attachments) > 0 {
+			stats.WithAttachments++
+		}
+		if len(e.Links) > 0 {
+			stats.WithLinks++
+		}
+		domain := senderDomain(e.From)
+		if domain == "" {
+			domain = unknownDomain
+		}
+		domains[domain]++
+		if e.ReceivedAt.Before(earliest) {
+			earliest = e.ReceivedAt
+		}
+		if e.ReceivedAt.After(latest) {
+			latest = e.ReceivedAt
+		}
+		scoreSum += styles.CalculateScore(e)
+	}
+
+	for domain, count := range domains {
+		stats.SenderDomains = append(stats.SenderDomains, domainCount{Domain: domain, Count: count})
+	}
+	sort.Slice(stats.SenderDomains, func(i, j int) bool {
+		a, b := stats.SenderDomains[i], stats.SenderDomains[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		return a.Domain < b.Domain
+	})
+
+	stats.earliest, stats.latest = earliest, latest
+	stats.EarliestReceivedAt = earliest.Format(time.RFC3339)
+	stats.LatestReceivedAt = latest.Format(time.RFC3339)
+	avg := math.Round(float64(scoreSum)/float64(len(emails))*10) / 10
+	stats.AverageScore = &avg
+	return stats
+}
+
+func printStats(stats emailStats) {
+	labelStyle := styles.LabelStyle
+
+	fmt.Println(styles.SectionStyle.Render("EMAIL STATS"))
+	fmt.Printf("%s %s\n", labelStyle.Render("Inbox:"), stats.Inbox)
+	fmt.Printf("%s %d\n", labelStyle.Render("Emails:"), stats.Total)
+	if stats.Total == 0 {
+		fmt.Println()
+		return
+	}
+	fmt.Printf("%s %d\n", labelStyle.Render("With attachments:"), stats.WithAttachments)
+	fmt.Printf("%s %d\n", labelStyle.Render("With links:"), stats.WithLinks)
+	fmt.Printf("%s %s\n", labelStyle.Render("Earliest:"), stats.earliest.Local().Format(cliutil.TimeFormatWithZone))
+	fmt.Printf("%s %s\n", labelStyle.Render("Latest:"), stats.latest.Local().Format(cliutil.TimeFormatWithZone))
+	score := int(math.Round(*stats.AverageScore))
+	fmt.Printf("%s %s\n", labelStyle.Render("Avg security score:"),
+		styles.ScoreStyle(score).Render(fmt.Sprintf("%.1f/100", *stats.AverageScore)))
+
+	fmt.Println(styles.SectionStyle.Render("SENDER DOMAINS"))
+	width := 0
+	for _, d := range stats.SenderDomains {
+		width = max(width, len(d.Domain))
+	}
+	for _, d := range stats.SenderDomains {
+		fmt.Printf("  %-*s  %d\n", width, d.Domain, d.Count)
+	}
+	fmt.Println()
+}
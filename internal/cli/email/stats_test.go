@@ -0,0 +1,78 @@
+package email
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	vaultsandbox "github.com/vaultsandbox/client-go"
+)
+
+func TestComputeStats(t *testing.T) {
+	base := time.Date(2026, 3, 1, 14, 0, 0, 0, time.UTC)
+	emails := []*vaultsandbox.Email{
+		{
+			ID: "1", From: "Billing <billing@Shop.test>", ReceivedAt: base.Add(time.Hour),
+			Links: []string{"https://shop.test/invoice"}, AuthResults: passingAuth(),
+		},
+		{
+			ID: "2", From: "news@app.test", ReceivedAt: base,
+			Attachments: []vaultsandbox.Attachment{{Filename: "a.pdf"}},
+		},
+		{
+			ID: "3", From: "orders@shop.test", ReceivedAt: base.Add(30 * time.Minute),
+			Links: []string{"https://shop.test/order"}, Attachments: []vaultsandbox.Attachment{{Filename: "b.pdf"}},
+		},
+		{ID: "4", From: "not an address", ReceivedAt: base.Add(10 * time.Minute)},
+	}
+
+	stats := computeStats("test@vsx.email", emails)
+	assert.Equal(t, "test@vsx.email", stats.Inbox)
+	assert.Equal(t, 4, stats.Total)
+	assert.Equal(t, 2, stats.WithAttachments)
+	assert.Equal(t, 2, stats.WithLinks)
+	assert.Equal(t, "2026-03-01T14:00:00Z", stats.EarliestReceivedAt)
+	assert.Equal(t, "2026-03-01T15:00:00Z", stats.LatestReceivedAt)
+	require.NotNil(t, stats.AverageScore)
+	// One email scores 100, the three without auth results 50
+	assert.Equal(t, 62.5, *stats.AverageScore)
+	assert.Equal(t, []domainCount{
+		{Domain: "shop.test", Count: 2},
+		{Domain: "(unknown)", Count: 1},
+		{Domain: "app.test", Count: 1},
+	}, stats.SenderDomains)
+}
+
+func TestComputeStatsEmpty(t *testing.T) {
+	stats := computeStats("test@vsx.email", nil)
+
+	data, err := json.Marshal(stats)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"inbox": "test@vsx.email",
+		"total": 0,
+		"withAttachments": 0,
+		"withLinks": 0,
+		"averageSecurityScore": null,
+		"senderDomains": []
+	}`, string(data))
+}
+
+func TestPrintStats(t *testing.T) {
+	base := time.Date(2026, 3, 1, 14, 0, 0, 0, time.UTC)
+	stats := computeStats("test@vsx.email", []*vaultsandbox.Email{
+		{ID: "1", From: "a@mail.example.com", ReceivedAt: base, AuthResults: passingAuth()},
+		{ID: "2", From: "b@x.test", ReceivedAt: base},
+	})
+
+	out := captureStdout(t, func() { printStats(stats) })
+	assert.Contains(t, out, "test@vsx.email")
+	assert.Contains(t, out, "75.0/100")
+	assert.Contains(t, out, "  mail.example.com  1\n")
+	assert.Contains(t, out, "  x.test            1\n")
+
+	out = captureStdout(t, func() { printStats(computeStats("test@vsx.email", nil)) })
+	assert.NotContains(t, out, "SENDER DOMAINS")
+}
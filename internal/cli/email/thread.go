@@ -0,0 +1,130 @@
+package email
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/vaultsandbox/vsb-cli/internal/cliutil"
+	mailthread "github.com/vaultsandbox/vsb-cli/internal/email"
+	"github.com/vaultsandbox/vsb-cli/internal/styles"
+)
+
+var threadCmd = &cobra.Command{
+	Use:   "thread",
+	Short: "Group emails in the active inbox into conversation threads",
+	Long: `Group emails into conversation threads using their Message-ID,
+In-Reply-To and References headers, and display them as a tree.
+
+A reply whose parent is not in the inbox starts its own thread. Threads and
+replies are ordered by received time.
+
+With --output json, an array of thread roots is returned, each with a
+"children" array. --flatten returns a depth-first list instead, where each
+email has a "depth" field (0 for thread roots).
+
+Examples:
+  vsb email thread
+  vsb email thread -o json
+  vsb email thread --flatten -o json | jq '.[] | select(.depth > 0)'`,
+	Args: cobra.NoArgs,
+	RunE: runThread,
+}
+
+var threadFlatten bool
+
+func init() {
+	Cmd.AddCommand(threadCmd)
+	cliutil.AddOutputFormats(threadCmd, cliutil.FormatJSON)
+
+	threadCmd.Flags().BoolVar(&threadFlatten, "flatten", false,
+		"Output a depth-first flat list with a depth for each email")
+}
+
+func runThread(cmd *cobra.Command, args []string) error {
+	ctx := cliutil.CommandContext(cmd)
+
+	inbox, cleanup, err := cliutil.LoadAndImportInbox(ctx, InboxFlag)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	emails, err := inbox.GetEmails(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get emails: %w", err)
+	}
+
+	threads := mailthread.BuildThreads(emails)
+
+	if cliutil.GetOutput(cmd) == cliutil.FormatJSON {
+		if threadFlatten {
+			return cliutil.OutputJSON(flatThreadsJSON(threads))
+		}
+		return cliutil.OutputJSON(threadsJSON(threads))
+	}
+
+	if len(emails) == 0 {
+		fmt.Println("No emails in inbox")
+		return nil
+	}
+
+	if threadFlatten {
+		for _, entry := range mailthread.Flatten(threads) {
+			fmt.Println(strings.Repeat("  ", entry.Depth) + threadLine(entry.Email.ID, entry.Email.Subject, entry.Email.From))
+		}
+	} else {
+		for _, t := range threads {
+			printThread(t, "", "")
+		}
+	}
+
+	fmt.Println()
+	fmt.Printf("  %d email(s) in %d thread(s)\n\n", len(emails), len(threads))
+	return nil
+}
+
+// threadsJSON converts threads to nested JSON objects.
+func threadsJSON(threads []*mailthread.Thread) []map[string]interface{} {
+	result := make([]map[string]interface{}, 0, len(threads))
+	for _, t := range threads {
+		data := cliutil.EmailSummaryJSON(t.Email)
+		data["messageId"] = mailthread.MessageID(t.Email)
+		data["children"] = threadsJSON(t.Children)
+		result = append(result, data)
+	}
+	return result
+}
+
+// flatThreadsJSON converts threads to a depth-first list of JSON objects.
+func flatThreadsJSON(threads []*mailthread.Thread) []map[string]interface{} {
+	flat := mailthread.Flatten(threads)
+	result := make([]map[string]interface{}, 0, len(flat))
+	for _, entry := range flat {
+		data := cliutil.EmailSummaryJSON(entry.Email)
+		data["messageId"] = mailthread.MessageID(entry.Email)
+		data["depth"] = entry.Depth
+		result = append(result, data)
+	}
+	return result
+}
+
+// printThread prints t and its replies with tree connectors. prefix is the
+// connector for t itself and indent is prepended to its descendants.
+func printThread(t *mailthread.Thread, prefix, indent string) {
+	fmt.Println(styles.MutedStyle.Render(prefix) + threadLine(t.Email.ID, t.Email.Subject, t.Email.From))
+	for i, child := range t.Children {
+		if i == len(t.Children)-1 {
+			printThread(child, indent+"└─ ", indent+"   ")
+		} else {
+			printThread(child, indent+"├─ ", indent+"│  ")
+		}
+	}
+}
+
+func threadLine(id, subject, from string) string {
+	return fmt.Sprintf("%s  %s  %s",
+		styles.IDStyle.Render(id),
+		styles.SubjectStyle.Render(subject),
+		styles.FromStyle.Render(from))
+}
@@ -0,0 +1,42 @@
+package email
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	vaultsandbox "github.com/vaultsandbox/client-go"
+	mailthread "github.com/vaultsandbox/vsb-cli/internal/email"
+)
+
+func TestThreadsJSON(t *testing.T) {
+	now := time.Now()
+	root := &vaultsandbox.Email{ID: "root", ReceivedAt: now, Headers: map[string]string{"message-id": "<1@x>"}}
+	reply := &vaultsandbox.Email{ID: "reply", ReceivedAt: now.Add(time.Minute), Headers: map[string]string{
+		"message-id":  "<2@x>",
+		"in-reply-to": "<1@x>",
+	}}
+	threads := mailthread.BuildThreads([]*vaultsandbox.Email{reply, root})
+
+	t.Run("nested", func(t *testing.T) {
+		result := threadsJSON(threads)
+		require.Len(t, result, 1)
+		assert.Equal(t, "root", result[0]["id"])
+		assert.Equal(t, "<1@x>", result[0]["messageId"])
+		children := result[0]["children"].([]map[string]interface{})
+		require.Len(t, children, 1)
+		assert.Equal(t, "reply", children[0]["id"])
+		assert.Empty(t, children[0]["children"])
+	})
+
+	t.Run("flat", func(t *testing.T) {
+		result := flatThreadsJSON(threads)
+		require.Len(t, result, 2)
+		assert.Equal(t, "root", result[0]["id"])
+		assert.Equal(t, 0, result[0]["depth"])
+		assert.Equal(t, "reply", result[1]["id"])
+		assert.Equal(t, 1, result[1]["depth"])
+		assert.NotContains(t, result[1], "children")
+	})
+}
@@ -2,12 +2,21 @@ package email
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/http"
+	"net/mail"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	vaultsandbox "github.com/vaultsandbox/client-go"
 	"github.com/vaultsandbox/vsb-cli/internal/browser"
+	"github.com/vaultsandbox/vsb-cli/internal/ci"
 	"github.com/vaultsandbox/vsb-cli/internal/cliutil"
+	"github.com/vaultsandbox/vsb-cli/internal/config"
 )
 
 // getEmailByIDOrLatestFunc is a variable for cliutil.GetEmailByIDOrLatest that can be overridden in tests
@@ -25,6 +34,22 @@ var urlCmd = &cobra.Command{
 	Long: `Extract HTTP/HTTPS URLs from an email.
 
 By default, lists all URLs. Use --open to open a URL in your browser.
+Use --follow-redirects (or --resolve) to resolve click-tracking links:
+each URL is requested with HEAD (falling back to GET) and redirects are
+followed (up to --follow-limit) to report the final destination, which is
+also what --open opens. Each request is bounded by --timeout. Only http and
+https URLs are requested, and a redirect to a scheme the browser would
+refuse to open stops the chain. This is the only mode that makes network
+requests; plain extraction stays local.
+Use --group-by-domain to list URLs by hostname, e.g. to spot tracking
+domains, and --domain-filter to keep only URLs from one domain (and its
+subdomains).
+
+For finer control, --domain keeps only URLs whose host is one of the given
+domains and --exclude-domain drops them (both repeatable). --external-only
+hides URLs pointing back to the sender's domain. These match the exact host
+unless --include-subdomains is set. With --follow-redirects, filters apply
+to the final destination.
 This is useful for quickly following verification links, password reset links,
 or any other actionable URLs in emails.
 
@@ -33,22 +58,150 @@ Examples:
   vsb email url abc123       # List URLs from specific email
   vsb email url --open 1     # Open first URL in browser
   vsb email url --open 2     # Open second URL in browser
-  vsb email url -o json      # JSON output for CI/CD`,
+  vsb email url -o json      # JSON output for CI/CD
+  vsb email url --github-output  # Set link/link_count step outputs
+  vsb email url --follow-redirects   # Resolve tracking links to their destination
+  vsb email url --resolve --timeout 10s -o json
+  vsb email url --group-by-domain    # Group URLs by hostname
+  vsb email url --domain-filter example.com
+  vsb email url --domain example.com --domain example.org --include-subdomains
+  vsb email url --external-only --exclude-domain tracking.example.net`,
 	Args: cobra.MaximumNArgs(1),
 	RunE: runURL,
 }
 
-var urlOpen int
+var (
+	urlOpen            int
+	urlGitHubOutput    bool
+	urlFollowRedirects bool
+	urlResolve         bool
+	urlFollowLimit     int
+	urlTimeout         string
+	urlGroupByDomain   bool
+	urlDomainFilter    string
+	urlDomains         []string
+	urlExcludeDomains  []string
+	urlExternalOnly    bool
+	urlSubdomains      bool
+)
+
+// defaultRedirectTimeout bounds each request made while following redirects
+// unless --timeout is set
+const defaultRedirectTimeout = 3 * time.Second
+
+// resolvedURL is a link and the destination it redirects to
+type resolvedURL struct {
+	Original string `json:"original"`
+	Resolved string `json:"resolved"`
+	Error    string `json:"error,omitempty"`
+}
 
 func init() {
 	Cmd.AddCommand(urlCmd)
+	cliutil.AddOutputFormats(urlCmd, cliutil.FormatJSON)
 
 	urlCmd.Flags().IntVarP(&urlOpen, "open", "O", 0,
 		"Open the Nth URL in browser (1=first, 0=don't open)")
+	urlCmd.Flags().BoolVar(&urlGitHubOutput, "github-output", false,
+		"Write link and link_count to $GITHUB_OUTPUT and annotate failures")
+	urlCmd.Flags().BoolVar(&urlFollowRedirects, "follow-redirects", false,
+		"Follow redirects with HEAD requests and report the final URL")
+	urlCmd.Flags().BoolVar(&urlResolve, "resolve", false,
+		"Alias for --follow-redirects")
+	urlCmd.Flags().StringVar(&urlTimeout, "timeout", "",
+		"Timeout for each request made while resolving redirects (default 3s)")
+	urlCmd.Flags().IntVar(&urlFollowLimit, "follow-limit", 5,
+		"Maximum number of redirects to follow")
+	urlCmd.Flags().BoolVar(&urlGroupByDomain, "group-by-domain", false,
+		"Group URLs by hostname")
+	urlCmd.Flags().StringVar(&urlDomainFilter, "domain-filter", "",
+		"Only show URLs from this domain or its subdomains")
+	urlCmd.Flags().StringArrayVar(&urlDomains, "domain", nil,
+		"Only show URLs whose host is this domain (repeatable)")
+	urlCmd.Flags().StringArrayVar(&urlExcludeDomains, "exclude-domain", nil,
+		"Hide URLs whose host is this domain (repeatable)")
+	urlCmd.Flags().BoolVar(&urlExternalOnly, "external-only", false,
+		"Hide URLs pointing to the sender's domain")
+	urlCmd.Flags().BoolVar(&urlSubdomains, "include-subdomains", false,
+		"Make --domain, --exclude-domain and --external-only match subdomains too")
+}
+
+// urlHostFilter keeps or drops URLs by host for --domain, --exclude-domain
+// and --external-only
+type urlHostFilter struct {
+	include    []string
+	exclude    []string
+	subdomains bool
+}
+
+// newURLHostFilter builds the filter from the flags. --external-only adds
+// the sender's domain to the excluded domains.
+func newURLHostFilter(email *vaultsandbox.Email) urlHostFilter {
+	f := urlHostFilter{
+		include:    urlDomains,
+		exclude:    append([]string(nil), urlExcludeDomains...),
+		subdomains: urlSubdomains,
+	}
+	if urlExternalOnly {
+		if domain := senderDomain(email.From); domain != "" {
+			f.exclude = append(f.exclude, domain)
+		}
+	}
+	return f
+}
+
+func (f urlHostFilter) active() bool {
+	return len(f.include) > 0 || len(f.exclude) > 0
+}
+
+// keep reports whether rawURL passes the filter
+func (f urlHostFilter) keep(rawURL string) bool {
+	host := urlHost(rawURL)
+	for _, d := range f.exclude {
+		if hostMatchesDomain(host, d, f.subdomains) {
+			return false
+		}
+	}
+	if len(f.include) == 0 {
+		return true
+	}
+	for _, d := range f.include {
+		if hostMatchesDomain(host, d, f.subdomains) {
+			return true
+		}
+	}
+	return false
 }
 
-func runURL(cmd *cobra.Command, args []string) error {
-	ctx := context.Background()
+// senderDomain returns the lowercased domain of a From address, or "" if it
+// can't be parsed
+func senderDomain(from string) string {
+	addr, err := mail.ParseAddress(from)
+	if err != nil {
+		return ""
+	}
+	at := strings.LastIndex(addr.Address, "@")
+	if at < 0 {
+		return ""
+	}
+	return strings.ToLower(addr.Address[at+1:])
+}
+
+// domainGroup is the URLs found for one hostname
+type domainGroup struct {
+	Domain string
+	URLs   []string
+}
+
+func runURL(cmd *cobra.Command, args []string) (err error) {
+	ctx := cliutil.CommandContext(cmd)
+
+	reporter := ci.NewReporter(urlGitHubOutput)
+	defer func() {
+		if err != nil {
+			reporter.Error(err.Error())
+		}
+	}()
 
 	emailID := cliutil.GetArg(args, 0, "")
 
@@ -59,32 +212,314 @@ func runURL(cmd *cobra.Command, args []string) error {
 	}
 	defer cleanup()
 
+	links := email.Links
+	var resolved []resolvedURL
+	if (urlFollowRedirects || urlResolve) && len(links) > 0 {
+		if urlFollowLimit < 0 {
+			return fmt.Errorf("invalid --follow-limit: %d", urlFollowLimit)
+		}
+		timeout := defaultRedirectTimeout
+		if urlTimeout != "" {
+			timeout, err = time.ParseDuration(urlTimeout)
+			if err != nil || timeout <= 0 {
+				return fmt.Errorf("invalid --timeout: %s", urlTimeout)
+			}
+		}
+		resolved = resolveURLs(ctx, links, urlFollowLimit, timeout)
+		if urlDomainFilter != "" {
+			resolved = filterResolvedByDomain(resolved, urlDomainFilter)
+		}
+		links = make([]string, len(resolved))
+		for i, r := range resolved {
+			links[i] = r.Resolved
+		}
+	} else if urlDomainFilter != "" {
+		links = filterByDomain(links, urlDomainFilter)
+	}
+
+	hostFilter := newURLHostFilter(email)
+	if hostFilter.active() {
+		if resolved != nil {
+			var kept []resolvedURL
+			for _, r := range resolved {
+				if hostFilter.keep(r.Resolved) {
+					kept = append(kept, r)
+				}
+			}
+			resolved = kept
+			links = make([]string, len(resolved))
+			for i, r := range resolved {
+				links[i] = r.Resolved
+			}
+		} else {
+			var kept []string
+			for _, link := range links {
+				if hostFilter.keep(link) {
+					kept = append(kept, link)
+				}
+			}
+			links = kept
+		}
+	}
+
+	if err := reportURLs(reporter, links); err != nil {
+		return err
+	}
+
 	// Check for URLs
-	if len(email.Links) == 0 {
-		if cliutil.GetOutput(cmd) == "json" {
+	if len(links) == 0 {
+		if cliutil.GetOutput(cmd) == cliutil.FormatJSON {
+			if urlGroupByDomain {
+				return cliutil.OutputJSON(map[string][]string{})
+			}
 			return cliutil.OutputJSON([]struct{}{})
 		}
+		if urlDomainFilter != "" {
+			fmt.Printf("No URLs from %s found in email\n", urlDomainFilter)
+			return nil
+		}
+		if hostFilter.active() {
+			fmt.Println("No URLs matching the domain filters found in email")
+			return nil
+		}
 		fmt.Println("No URLs found in email")
 		return nil
 	}
 
 	// If --open is specified, open the URL
 	if urlOpen > 0 {
-		if urlOpen > len(email.Links) {
-			return fmt.Errorf("URL index %d out of range (1-%d)", urlOpen, len(email.Links))
+		if urlOpen > len(links) {
+			return fmt.Errorf("URL index %d out of range (1-%d)", urlOpen, len(links))
 		}
-		url := email.Links[urlOpen-1]
+		url := links[urlOpen-1]
 		fmt.Printf("Opening: %s\n", url)
 		return openURLInBrowserFunc(url)
 	}
 
+	if urlGroupByDomain {
+		groups := groupByDomain(links)
+		if cliutil.GetOutput(cmd) == cliutil.FormatJSON {
+			data := make(map[string][]string, len(groups))
+			for _, g := range groups {
+				data[g.Domain] = g.URLs
+			}
+			return cliutil.OutputJSON(data)
+		}
+		for i, g := range groups {
+			if i > 0 {
+				fmt.Println()
+			}
+			fmt.Printf("%s (%d):\n", g.Domain, len(g.URLs))
+			for _, u := range g.URLs {
+				fmt.Printf("  %s\n", u)
+			}
+		}
+		return nil
+	}
+
+	// Resolved URLs: show original and final destination
+	if resolved != nil {
+		if cliutil.GetOutput(cmd) == cliutil.FormatJSON {
+			return cliutil.OutputJSON(resolved)
+		}
+		for i, r := range resolved {
+			fmt.Printf("%d. %s\n", i+1, r.Original)
+			if r.Error != "" {
+				fmt.Printf("   ✗ %s\n", r.Error)
+			} else if r.Resolved != r.Original {
+				fmt.Printf("   → %s\n", r.Resolved)
+			}
+		}
+		return nil
+	}
+
 	// Default: list all URLs
-	if cliutil.GetOutput(cmd) == "json" {
-		return cliutil.OutputJSON(email.Links)
+	if cliutil.GetOutput(cmd) == cliutil.FormatJSON {
+		return cliutil.OutputJSON(links)
 	} else {
-		for i, url := range email.Links {
+		for i, url := range links {
 			fmt.Printf("%d. %s\n", i+1, url)
 		}
 	}
 	return nil
 }
+
+// reportURLs writes the link count and the first (or --open) link as CI
+// step outputs.
+func reportURLs(reporter ci.Reporter, links []string) error {
+	if err := reporter.SetOutput("link_count", strconv.Itoa(len(links))); err != nil {
+		return err
+	}
+	if len(links) == 0 {
+		reporter.Warning("no URLs found in email")
+		return nil
+	}
+
+	index := 1
+	if urlOpen > 0 && urlOpen <= len(links) {
+		index = urlOpen
+	}
+	return reporter.SetOutput("link", links[index-1])
+}
+
+// urlHost returns the lowercased hostname of a URL, without port
+func urlHost(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(u.Hostname())
+}
+
+// matchesDomain reports whether the URL's host is domain or a subdomain of it
+func matchesDomain(rawURL, domain string) bool {
+	return hostMatchesDomain(urlHost(rawURL), domain, true)
+}
+
+// hostMatchesDomain reports whether host is domain, or with subdomains set,
+// a subdomain of it
+func hostMatchesDomain(host, domain string, subdomains bool) bool {
+	domain = strings.ToLower(strings.TrimPrefix(domain, "."))
+	if host == "" || domain == "" {
+		return false
+	}
+	return host == domain || (subdomains && strings.HasSuffix(host, "."+domain))
+}
+
+// filterByDomain keeps the URLs that match domain
+func filterByDomain(links []string, domain string) []string {
+	var filtered []string
+	for _, link := range links {
+		if matchesDomain(link, domain) {
+			filtered = append(filtered, link)
+		}
+	}
+	return filtered
+}
+
+// filterResolvedByDomain keeps the resolved URLs whose final destination
+// matches domain
+func filterResolvedByDomain(resolved []resolvedURL, domain string) []resolvedURL {
+	var filtered []resolvedURL
+	for _, r := range resolved {
+		if matchesDomain(r.Resolved, domain) {
+			filtered = append(filtered, r)
+		}
+	}
+	return filtered
+}
+
+// groupByDomain groups URLs by hostname, in order of first appearance.
+// URLs without a hostname are grouped under "(no host)".
+func groupByDomain(links []string) []domainGroup {
+	var groups []domainGroup
+	index := make(map[string]int)
+	for _, link := range links {
+		host := urlHost(link)
+		if host == "" {
+			host = "(no host)"
+		}
+		i, ok := index[host]
+		if !ok {
+			i = len(groups)
+			index[host] = i
+			groups = append(groups, domainGroup{Domain: host})
+		}
+		groups[i].URLs = append(groups[i].URLs, link)
+	}
+	return groups
+}
+
+// resolveURLs follows redirects for each link, bounding each request by
+// timeout. Links that fail to resolve keep the last URL reached as their
+// resolved URL.
+func resolveURLs(ctx context.Context, links []string, limit int, timeout time.Duration) []resolvedURL {
+	client := config.NewHTTPClient(0)
+	// Redirects are followed manually to count them and record the chain
+	client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+
+	results := make([]resolvedURL, len(links))
+	for i, link := range links {
+		final, err := followRedirects(ctx, client, link, limit, timeout)
+		results[i] = resolvedURL{Original: link, Resolved: final}
+		if err != nil {
+			results[i].Error = err.Error()
+		}
+	}
+	return results
+}
+
+// followRedirects issues HEAD requests starting at rawURL, following up to
+// limit redirects, and returns the last URL reached.
+func followRedirects(ctx context.Context, client *http.Client, rawURL string, limit int, timeout time.Duration) (string, error) {
+	current := rawURL
+	for hops := 0; ; hops++ {
+		u, err := url.Parse(current)
+		if err != nil {
+			return current, fmt.Errorf("invalid URL: %w", err)
+		}
+		if u.Scheme != "http" && u.Scheme != "https" {
+			return current, nil
+		}
+
+		location, err := headLocation(ctx, client, current, timeout)
+		if err != nil {
+			return current, err
+		}
+		if location == "" {
+			return current, nil
+		}
+		if hops >= limit {
+			return current, fmt.Errorf("stopped after %d redirects", limit)
+		}
+
+		next, err := u.Parse(location)
+		if err != nil {
+			return current, fmt.Errorf("invalid redirect location %q: %w", location, err)
+		}
+		if err := browser.ValidateURL(next.String()); err != nil {
+			return current, fmt.Errorf("refusing redirect to %s: %w", next.Redacted(), err)
+		}
+		current = next.String()
+	}
+}
+
+// headLocation makes a single HEAD request and returns the redirect target,
+// or "" if the response is not a redirect. Servers that reject HEAD are
+// retried with GET.
+func headLocation(ctx context.Context, client *http.Client, rawURL string, timeout time.Duration) (string, error) {
+	resp, err := doRedirectRequest(ctx, client, http.MethodHead, rawURL, timeout)
+	if err == nil && resp.StatusCode == http.StatusMethodNotAllowed {
+		resp, err = doRedirectRequest(ctx, client, http.MethodGet, rawURL, timeout)
+	}
+	if err != nil {
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) && urlErr.Timeout() {
+			return "", fmt.Errorf("request timed out after %s", timeout)
+		}
+		return "", err
+	}
+
+	if resp.StatusCode >= 300 && resp.StatusCode < 400 {
+		return resp.Header.Get("Location"), nil
+	}
+	return "", nil
+}
+
+func doRedirectRequest(ctx context.Context, client *http.Client, method, rawURL string, timeout time.Duration) (*http.Response, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, method, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+	return resp, nil
+}
@@ -3,10 +3,14 @@ package email
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"io"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/assert"
@@ -555,3 +559,451 @@ func TestRunURLManyURLs(t *testing.T) {
 		assert.Equal(t, "https://example.com/linko", openedURL) // 15th (index 14) = 'a' + 14 = 'o'
 	})
 }
+
+func TestReportURLs(t *testing.T) {
+	defer func() { urlOpen = 0 }()
+
+	t.Run("first link and count", func(t *testing.T) {
+		urlOpen = 0
+		r := newRecordingReporter()
+		require.NoError(t, reportURLs(r, []string{"https://a.example", "https://b.example"}))
+		assert.Equal(t, map[string]string{"link_count": "2", "link": "https://a.example"}, r.outputs)
+	})
+
+	t.Run("opened link", func(t *testing.T) {
+		urlOpen = 2
+		r := newRecordingReporter()
+		require.NoError(t, reportURLs(r, []string{"https://a.example", "https://b.example"}))
+		assert.Equal(t, "https://b.example", r.outputs["link"])
+	})
+
+	t.Run("no links warns", func(t *testing.T) {
+		urlOpen = 0
+		r := newRecordingReporter()
+		require.NoError(t, reportURLs(r, nil))
+		assert.Equal(t, map[string]string{"link_count": "0"}, r.outputs)
+		assert.Len(t, r.warnings, 1)
+	})
+}
+
+// newRedirectServer returns a server where /track redirects twice before
+// reaching /final, /loop redirects to itself and /script redirects to a
+// javascript: URL.
+func newRedirectServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/track", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodHead, r.Method)
+		http.Redirect(w, r, "/step?id=1", http.StatusFound)
+	})
+	mux.HandleFunc("/step", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/final", http.StatusMovedPermanently)
+	})
+	mux.HandleFunc("/final", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	mux.HandleFunc("/loop", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/loop", http.StatusFound)
+	})
+	mux.HandleFunc("/script", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Location", "javascript:alert(1)")
+		w.WriteHeader(http.StatusFound)
+	})
+	mux.HandleFunc("/no-head", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodHead {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		http.Redirect(w, r, "/final", http.StatusFound)
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestResolveURLs(t *testing.T) {
+	srv := newRedirectServer(t)
+
+	t.Run("follows redirect chain", func(t *testing.T) {
+		results := resolveURLs(context.Background(), []string{srv.URL + "/track"}, 5, defaultRedirectTimeout)
+		require.Len(t, results, 1)
+		assert.Equal(t, srv.URL+"/track", results[0].Original)
+		assert.Equal(t, srv.URL+"/final", results[0].Resolved)
+		assert.Empty(t, results[0].Error)
+	})
+
+	t.Run("non-redirect unchanged", func(t *testing.T) {
+		results := resolveURLs(context.Background(), []string{srv.URL + "/final"}, 5, defaultRedirectTimeout)
+		assert.Equal(t, srv.URL+"/final", results[0].Resolved)
+		assert.Empty(t, results[0].Error)
+	})
+
+	t.Run("limit stops chain", func(t *testing.T) {
+		results := resolveURLs(context.Background(), []string{srv.URL + "/track"}, 1, defaultRedirectTimeout)
+		assert.Equal(t, srv.URL+"/step?id=1", results[0].Resolved)
+		assert.Equal(t, "stopped after 1 redirects", results[0].Error)
+	})
+
+	t.Run("redirect loop hits limit", func(t *testing.T) {
+		results := resolveURLs(context.Background(), []string{srv.URL + "/loop"}, 5, defaultRedirectTimeout)
+		assert.Contains(t, results[0].Error, "stopped after 5 redirects")
+	})
+
+	t.Run("falls back to GET when HEAD is rejected", func(t *testing.T) {
+		results := resolveURLs(context.Background(), []string{srv.URL + "/no-head"}, 5, defaultRedirectTimeout)
+		assert.Equal(t, srv.URL+"/final", results[0].Resolved)
+	})
+
+	t.Run("disallowed redirect scheme is not followed", func(t *testing.T) {
+		results := resolveURLs(context.Background(), []string{srv.URL + "/script"}, 5, defaultRedirectTimeout)
+		assert.Equal(t, srv.URL+"/script", results[0].Resolved)
+		assert.Contains(t, results[0].Error, `URL scheme "javascript" not allowed`)
+	})
+
+	t.Run("non-http scheme is not requested", func(t *testing.T) {
+		results := resolveURLs(context.Background(), []string{"mailto:a@example.com"}, 5, defaultRedirectTimeout)
+		assert.Equal(t, "mailto:a@example.com", results[0].Resolved)
+		assert.Empty(t, results[0].Error)
+	})
+
+	t.Run("request timeout", func(t *testing.T) {
+		release := make(chan struct{})
+		slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			<-release
+		}))
+		defer slow.Close()
+		defer close(release)
+
+		results := resolveURLs(context.Background(), []string{slow.URL}, 5, 50*time.Millisecond)
+		assert.Equal(t, slow.URL, results[0].Resolved)
+		assert.Equal(t, "request timed out after 50ms", results[0].Error)
+	})
+}
+
+func TestRunURLFollowRedirects(t *testing.T) {
+	srv := newRedirectServer(t)
+
+	oldFetcher := getEmailByIDOrLatestFunc
+	oldOpenURL := openURLInBrowserFunc
+	oldURLOpen := urlOpen
+	defer resetURLTestState(oldFetcher, oldOpenURL, oldURLOpen)
+	defer func() {
+		urlFollowRedirects = false
+		urlResolve = false
+		urlFollowLimit = 5
+		urlTimeout = ""
+	}()
+
+	urlFollowRedirects = true
+	urlFollowLimit = 5
+	getEmailByIDOrLatestFunc = mockEmailFetcher(&vaultsandbox.Email{
+		Links: []string{srv.URL + "/track"},
+	}, nil)
+
+	t.Run("JSON reports original and final", func(t *testing.T) {
+		urlOpen = 0
+		cmd := createTestCommand()
+		cmd.Flags().Set("output", "json")
+
+		output := captureURLStdout(t, func() {
+			require.NoError(t, runURL(cmd, []string{}))
+		})
+
+		var results []map[string]string
+		require.NoError(t, json.Unmarshal([]byte(output), &results))
+		assert.Equal(t, []map[string]string{{
+			"original": srv.URL + "/track",
+			"resolved": srv.URL + "/final",
+		}}, results)
+	})
+
+	t.Run("resolve flag with timeout", func(t *testing.T) {
+		urlFollowRedirects = false
+		urlResolve = true
+		urlTimeout = "7s"
+		defer func() {
+			urlFollowRedirects = true
+			urlResolve = false
+			urlTimeout = ""
+		}()
+
+		urlOpen = 0
+		output := captureURLStdout(t, func() {
+			require.NoError(t, runURL(createTestCommand(), []string{}))
+		})
+		assert.Contains(t, output, "→ "+srv.URL+"/final")
+	})
+
+	t.Run("timeout bounds each request", func(t *testing.T) {
+		release := make(chan struct{})
+		slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			<-release
+		}))
+		defer slow.Close()
+		defer close(release)
+
+		getEmailByIDOrLatestFunc = mockEmailFetcher(&vaultsandbox.Email{Links: []string{slow.URL}}, nil)
+		urlTimeout = "50ms"
+		defer func() {
+			getEmailByIDOrLatestFunc = mockEmailFetcher(&vaultsandbox.Email{Links: []string{srv.URL + "/track"}}, nil)
+			urlTimeout = ""
+		}()
+
+		urlOpen = 0
+		output := captureURLStdout(t, func() {
+			require.NoError(t, runURL(createTestCommand(), []string{}))
+		})
+		assert.Contains(t, output, "✗ request timed out after 50ms")
+	})
+
+	t.Run("invalid timeout", func(t *testing.T) {
+		urlTimeout = "soon"
+		defer func() { urlTimeout = "" }()
+
+		err := runURL(createTestCommand(), []string{})
+		assert.EqualError(t, err, "invalid --timeout: soon")
+	})
+
+	t.Run("open uses final URL", func(t *testing.T) {
+		urlOpen = 1
+		var opened string
+		openURLInBrowserFunc = func(u string) error {
+			opened = u
+			return nil
+		}
+
+		captureURLStdout(t, func() {
+			require.NoError(t, runURL(createTestCommand(), []string{}))
+		})
+		assert.Equal(t, srv.URL+"/final", opened)
+	})
+}
+
+func TestGroupByDomain(t *testing.T) {
+	groups := groupByDomain([]string{
+		"https://example.com/a",
+		"https://track.other.com/pixel.gif",
+		"https://EXAMPLE.com:8443/b",
+		"not a url",
+	})
+
+	assert.Equal(t, []domainGroup{
+		{Domain: "example.com", URLs: []string{"https://example.com/a", "https://EXAMPLE.com:8443/b"}},
+		{Domain: "track.other.com", URLs: []string{"https://track.other.com/pixel.gif"}},
+		{Domain: "(no host)", URLs: []string{"not a url"}},
+	}, groups)
+
+	assert.Empty(t, groupByDomain(nil))
+}
+
+func TestMatchesDomain(t *testing.T) {
+	tests := []struct {
+		url    string
+		domain string
+		want   bool
+	}{
+		{"https://example.com/a", "example.com", true},
+		{"https://www.example.com/a", "example.com", true},
+		{"https://Example.COM/a", ".example.com", true},
+		{"https://notexample.com/a", "example.com", false},
+		{"https://example.com.evil.io/a", "example.com", false},
+		{"mailto:user@example.com", "example.com", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			assert.Equal(t, tt.want, matchesDomain(tt.url, tt.domain))
+		})
+	}
+}
+
+func TestRunURLGroupByDomain(t *testing.T) {
+	oldFetcher := getEmailByIDOrLatestFunc
+	oldOpenURL := openURLInBrowserFunc
+	oldURLOpen := urlOpen
+	defer resetURLTestState(oldFetcher, oldOpenURL, oldURLOpen)
+	defer func() {
+		urlGroupByDomain = false
+		urlDomainFilter = ""
+	}()
+
+	urlOpen = 0
+	getEmailByIDOrLatestFunc = mockEmailFetcher(&vaultsandbox.Email{
+		Links: []string{
+			"https://example.com/verify",
+			"https://tracker.net/open",
+			"https://example.com/help",
+		},
+	}, nil)
+
+	t.Run("pretty sections", func(t *testing.T) {
+		urlGroupByDomain = true
+		urlDomainFilter = ""
+
+		output := captureURLStdout(t, func() {
+			require.NoError(t, runURL(createTestCommand(), []string{}))
+		})
+		assert.Contains(t, output, "example.com (2):\n  https://example.com/verify\n  https://example.com/help\n")
+		assert.Contains(t, output, "tracker.net (1):\n  https://tracker.net/open\n")
+	})
+
+	t.Run("JSON map", func(t *testing.T) {
+		urlGroupByDomain = true
+		urlDomainFilter = ""
+		cmd := createTestCommand()
+		cmd.Flags().Set("output", "json")
+
+		output := captureURLStdout(t, func() {
+			require.NoError(t, runURL(cmd, []string{}))
+		})
+
+		var result map[string][]string
+		require.NoError(t, json.Unmarshal([]byte(output), &result))
+		assert.Equal(t, map[string][]string{
+			"example.com": {"https://example.com/verify", "https://example.com/help"},
+			"tracker.net": {"https://tracker.net/open"},
+		}, result)
+	})
+
+	t.Run("domain filter", func(t *testing.T) {
+		urlGroupByDomain = false
+		urlDomainFilter = "tracker.net"
+		cmd := createTestCommand()
+		cmd.Flags().Set("output", "json")
+
+		output := captureURLStdout(t, func() {
+			require.NoError(t, runURL(cmd, []string{}))
+		})
+
+		var links []string
+		require.NoError(t, json.Unmarshal([]byte(output), &links))
+		assert.Equal(t, []string{"https://tracker.net/open"}, links)
+	})
+
+	t.Run("domain filter without matches", func(t *testing.T) {
+		urlGroupByDomain = true
+		urlDomainFilter = "nowhere.org"
+		cmd := createTestCommand()
+		cmd.Flags().Set("output", "json")
+
+		output := captureURLStdout(t, func() {
+			require.NoError(t, runURL(cmd, []string{}))
+		})
+		assert.Equal(t, "{}\n", output)
+	})
+}
+
+func TestHostMatchesDomain(t *testing.T) {
+	assert.True(t, hostMatchesDomain("example.com", "Example.com", false))
+	assert.False(t, hostMatchesDomain("www.example.com", "example.com", false))
+	assert.True(t, hostMatchesDomain("www.example.com", "example.com", true))
+	assert.False(t, hostMatchesDomain("badexample.com", "example.com", true))
+	assert.False(t, hostMatchesDomain("", "example.com", true))
+	assert.False(t, hostMatchesDomain("example.com", "", true))
+}
+
+func TestSenderDomain(t *testing.T) {
+	assert.Equal(t, "shop.example.com", senderDomain("Shop <news@Shop.Example.com>"))
+	assert.Equal(t, "example.com", senderDomain("a@example.com"))
+	assert.Equal(t, "example.org", senderDomain(`"Doe, Jane" <jane@example.org>`))
+	assert.Equal(t, "example.org", senderDomain("<jane@example.org>"))
+	assert.Empty(t, senderDomain("not an address"))
+}
+
+func TestURLHostFilter(t *testing.T) {
+	defer func() {
+		urlDomains = nil
+		urlExcludeDomains = nil
+		urlExternalOnly = false
+		urlSubdomains = false
+	}()
+
+	email := &vaultsandbox.Email{From: "Shop <news@shop.example>"}
+	links := []string{
+		"https://shop.example/order",
+		"https://www.shop.example/help",
+		"https://track.ads.example/c?id=1",
+		"https://partner.example/offer",
+	}
+	apply := func() []string {
+		f := newURLHostFilter(email)
+		var kept []string
+		for _, l := range links {
+			if f.keep(l) {
+				kept = append(kept, l)
+			}
+		}
+		return kept
+	}
+
+	t.Run("no flags keeps everything", func(t *testing.T) {
+		assert.False(t, newURLHostFilter(email).active())
+		assert.Equal(t, links, apply())
+	})
+
+	t.Run("include exact hosts", func(t *testing.T) {
+		urlDomains = []string{"shop.example", "partner.example"}
+		defer func() { urlDomains = nil }()
+		assert.Equal(t, []string{links[0], links[3]}, apply())
+	})
+
+	t.Run("include with subdomains", func(t *testing.T) {
+		urlDomains = []string{"shop.example"}
+		urlSubdomains = true
+		defer func() { urlDomains, urlSubdomains = nil, false }()
+		assert.Equal(t, []string{links[0], links[1]}, apply())
+	})
+
+	t.Run("exclude", func(t *testing.T) {
+		urlExcludeDomains = []string{"ads.example"}
+		urlSubdomains = true
+		defer func() { urlExcludeDomains, urlSubdomains = nil, false }()
+		assert.Equal(t, []string{links[0], links[1], links[3]}, apply())
+	})
+
+	t.Run("external only", func(t *testing.T) {
+		urlExternalOnly = true
+		defer func() { urlExternalOnly = false }()
+		assert.Equal(t, []string{links[1], links[2], links[3]}, apply())
+
+		urlSubdomains = true
+		defer func() { urlSubdomains = false }()
+		assert.Equal(t, []string{links[2], links[3]}, apply())
+	})
+
+	t.Run("external only without sender domain", func(t *testing.T) {
+		urlExternalOnly = true
+		defer func() { urlExternalOnly = false }()
+		f := newURLHostFilter(&vaultsandbox.Email{From: "unknown"})
+		assert.False(t, f.active())
+	})
+}
+
+func TestRunURLHostFilter(t *testing.T) {
+	oldFetcher := getEmailByIDOrLatestFunc
+	oldOpenURL := openURLInBrowserFunc
+	oldURLOpen := urlOpen
+	defer resetURLTestState(oldFetcher, oldOpenURL, oldURLOpen)
+	defer func() { urlExternalOnly = false }()
+
+	urlOpen = 0
+	urlExternalOnly = true
+	getEmailByIDOrLatestFunc = mockEmailFetcher(&vaultsandbox.Email{
+		From:  "news@shop.example",
+		Links: []string{"https://shop.example/a", "https://other.example/b"},
+	}, nil)
+
+	output := captureURLStdout(t, func() {
+		require.NoError(t, runURL(createTestCommand(), []string{}))
+	})
+	assert.Equal(t, "1. https://other.example/b\n", output)
+
+	getEmailByIDOrLatestFunc = mockEmailFetcher(&vaultsandbox.Email{
+		From:  "news@shop.example",
+		Links: []string{"https://shop.example/a"},
+	}, nil)
+	output = captureURLStdout(t, func() {
+		require.NoError(t, runURL(createTestCommand(), []string{}))
+	})
+	assert.Equal(t, "No URLs matching the domain filters found in email\n", output)
+}
@@ -1,46 +1,142 @@
 package email
 
 import (
-	"context"
+	"errors"
 	"fmt"
+	"os"
+	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	vaultsandbox "github.com/vaultsandbox/client-go"
 	"github.com/vaultsandbox/vsb-cli/internal/browser"
 	"github.com/vaultsandbox/vsb-cli/internal/cliutil"
+	"github.com/vaultsandbox/vsb-cli/internal/config"
+	"github.com/vaultsandbox/vsb-cli/internal/format"
+	"golang.org/x/term"
 )
 
 var viewCmd = &cobra.Command{
-	Use:   "view [email-id]",
-	Short: "Preview email content",
+	Use:     "view [email-id]",
+	Aliases: []string{"v"},
+	Short:   "Preview email content",
 	Long: `View email content in various formats.
 
+With --preview (or 'vsb config set html-renderer terminal') the HTML body
+is rendered in the terminal using w3m, or lynx if w3m is not installed.
+If neither is available the plain text body is shown instead.
+
+--parts (or --list-parts) lists the MIME tree of the raw message: part
+number, content type, size, charset, transfer encoding, disposition and
+filename. --part writes one part's decoded content to stdout; binary parts
+are refused when stdout is a terminal unless --force is given.
+
+--raw --headers-only prints just the header block of the raw message,
+everything before the first blank line, with the original header order and
+folded lines kept as they are.
+
 Examples:
   vsb email view              # View latest email HTML in browser
   vsb email view abc123       # View specific email
   vsb email view -t           # Print plain text to terminal
+  vsb email view -p           # Render HTML in the terminal (w3m/lynx)
   vsb email view -r           # Print raw email source (RFC 5322)
-  vsb email view -o json      # JSON output`,
+  vsb email view -r --headers-only  # Print only the raw header block
+  vsb email view -t --word-wrap 100
+  vsb email view -t --no-wrap # Don't wrap long lines
+  vsb email view -t --decode-base64  # Decode a base64-encoded body
+  vsb email view -o json      # JSON output
+  vsb email view --parts      # Show the MIME part tree
+  vsb email view --part 1.2   # Print the decoded content of part 1.2
+  vsb email view --part 2 > logo.png`,
 	Args: cobra.MaximumNArgs(1),
 	RunE: runView,
 }
 
 var (
-	viewText bool
-	viewRaw  bool
+	viewText     bool
+	viewRaw      bool
+	viewHeaders  bool
+	viewWordWrap int
+	viewNoWrap   bool
+	viewDecode64 bool
+	viewPreview  bool
+	viewParts    bool
+	viewPart     string
+	viewForce    bool
 )
 
+// renderHTMLInTerminalFunc is a variable for browser.RenderHTMLInTerminal that can be overridden in tests
+var renderHTMLInTerminalFunc = browser.RenderHTMLInTerminal
+
 func init() {
 	Cmd.AddCommand(viewCmd)
+	cliutil.AddOutputFormats(viewCmd, cliutil.FormatJSON, cliutil.FormatRaw)
 
 	viewCmd.Flags().BoolVarP(&viewText, "text", "t", false,
 		"Show plain text version in terminal")
 	viewCmd.Flags().BoolVarP(&viewRaw, "raw", "r", false,
 		"Show raw email source (RFC 5322)")
+	viewCmd.Flags().BoolVar(&viewHeaders, "headers-only", false,
+		"With --raw, show only the header block")
+	viewCmd.Flags().IntVar(&viewWordWrap, "word-wrap", 0,
+		"Wrap plain text at this column (default: terminal width, or 80)")
+	viewCmd.Flags().BoolVar(&viewNoWrap, "no-wrap", false,
+		"Disable wrapping of plain text")
+	viewCmd.Flags().BoolVar(&viewDecode64, "decode-base64", false,
+		"Decode base64-encoded text and HTML bodies before display")
+	viewCmd.Flags().BoolVarP(&viewPreview, "preview", "p", false,
+		"Render HTML in the terminal with w3m or lynx")
+	viewCmd.Flags().BoolVar(&viewParts, "parts", false,
+		"List the MIME part tree")
+	viewCmd.Flags().StringVar(&viewPart, "part", "",
+		"Write the decoded content of this MIME part (e.g. 1.2) to stdout")
+	viewCmd.Flags().BoolVar(&viewForce, "force", false,
+		"Write binary parts to a terminal with --part")
+
+	viewCmd.MarkFlagsMutuallyExclusive("parts", "part", "raw", "text", "preview")
+	viewCmd.Flags().SetNormalizeFunc(viewFlagAliases)
+	addDebugRawFlags(viewCmd)
+}
+
+// viewFlagAliases accepts --list-parts for --parts
+func viewFlagAliases(f *pflag.FlagSet, name string) pflag.NormalizedName {
+	if name == "list-parts" {
+		name = "parts"
+	}
+	return pflag.NormalizedName(name)
+}
+
+// terminalWidth returns the width of stdout, or 0 if it is not a terminal.
+var terminalWidth = func() int {
+	width, _, err := term.GetSize(int(os.Stdout.Fd()))
+	if err != nil {
+		return 0
+	}
+	return width
+}
+
+// textWrapWidth returns the column to wrap plain text at, or 0 for no wrapping.
+func textWrapWidth() int {
+	if viewNoWrap {
+		return 0
+	}
+	if viewWordWrap > 0 {
+		return viewWordWrap
+	}
+	if width := terminalWidth(); width > 0 {
+		return width
+	}
+	return format.DefaultWrapWidth
 }
 
 func runView(cmd *cobra.Command, args []string) error {
-	ctx := context.Background()
+	if viewHeaders && !viewRaw {
+		return fmt.Errorf("--headers-only requires --raw")
+	}
+
+	ctx := cliutil.CommandContext(cmd)
 
 	emailID := cliutil.GetArg(args, 0, "")
 
@@ -51,8 +147,30 @@ func runView(cmd *cobra.Command, args []string) error {
 	}
 	defer cleanup()
 
+	cliutil.MarkRead(inbox.EmailAddress(), email.ID)
+
+	// Dump the payload before anything below reshapes it
+	if err := writeDebugRaw(cmd, email); err != nil {
+		return err
+	}
+	if rawOutput(cmd) {
+		return nil
+	}
+
+	if viewParts || viewPart != "" {
+		raw, err := inbox.GetRawEmail(ctx, email.ID)
+		if err != nil {
+			return err
+		}
+		return runViewParts(cmd, os.Stdout, raw)
+	}
+
+	if viewDecode64 {
+		decodeBase64Bodies(email)
+	}
+
 	// JSON output
-	if cliutil.GetOutput(cmd) == "json" {
+	if cliutil.GetOutput(cmd) == cliutil.FormatJSON {
 		return cliutil.OutputJSON(cliutil.EmailFullJSON(email))
 	}
 
@@ -62,6 +180,9 @@ func runView(cmd *cobra.Command, args []string) error {
 		if err != nil {
 			return err
 		}
+		if viewHeaders {
+			raw = rawHeaderBlock(raw)
+		}
 		fmt.Println(raw)
 		return nil
 	}
@@ -72,17 +193,20 @@ func runView(cmd *cobra.Command, args []string) error {
 			fmt.Println("No plain text version available")
 			return nil
 		}
-		fmt.Printf("Subject: %s\n", email.Subject)
-		fmt.Printf("From: %s\n", email.From)
-		fmt.Printf("Date: %s\n\n", email.ReceivedAt.Format(cliutil.TimeFormatFull))
-		fmt.Println(email.Text)
+		printViewHeader(email)
+		fmt.Println(format.Wrap(email.Text, textWrapWidth()))
 		return nil
 	}
 
+	// Terminal preview - render HTML with w3m/lynx
+	if viewPreview || config.GetHTMLRenderer() == "terminal" {
+		return previewInTerminal(email)
+	}
+
 	// HTML mode - open in browser
 	if email.HTML == "" {
 		fmt.Println("No HTML version, showing text:")
-		fmt.Println(email.Text)
+		fmt.Println(format.Wrap(email.Text, textWrapWidth()))
 		return nil
 	}
 
@@ -93,3 +217,62 @@ func runView(cmd *cobra.Command, args []string) error {
 
 	return browser.ViewEmailHTML(email.Subject, email.From, email.ReceivedAt, email.HTML)
 }
+
+// rawHeaderBlock returns the header section of an RFC 5322 message: the
+// lines before the first blank line, unchanged. A message without a body is
+// all headers.
+func rawHeaderBlock(raw string) string {
+	end := len(raw)
+	for _, sep := range []string{"\r\n\r\n", "\n\n"} {
+		if i := strings.Index(raw, sep); i >= 0 && i < end {
+			end = i
+		}
+	}
+	if strings.HasPrefix(raw, "\r\n") || strings.HasPrefix(raw, "\n") {
+		end = 0
+	}
+	return strings.TrimRight(raw[:end], "\r\n")
+}
+
+func printViewHeader(email *vaultsandbox.Email) {
+	fmt.Printf("Subject: %s\n", email.Subject)
+	fmt.Printf("From: %s\n", email.From)
+	fmt.Printf("Date: %s\n\n", email.ReceivedAt.Format(cliutil.TimeFormatFull))
+}
+
+// previewInTerminal renders the HTML body in the terminal, falling back to
+// the plain text body when there is no HTML or no renderer is installed.
+func previewInTerminal(email *vaultsandbox.Email) error {
+	printViewHeader(email)
+
+	if email.HTML != "" {
+		err := renderHTMLInTerminalFunc(email.HTML)
+		if !errors.Is(err, browser.ErrNoTerminalRenderer) {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "%v, showing plain text\n", err)
+	}
+
+	if email.Text == "" {
+		fmt.Println("No plain text version available")
+		return nil
+	}
+	fmt.Println(format.Wrap(email.Text, textWrapWidth()))
+	return nil
+}
+
+// decodeBase64Bodies decodes the text and HTML bodies in place when they are
+// base64. A Content-Transfer-Encoding: base64 header forces decoding;
+// otherwise the content must look like base64.
+func decodeBase64Bodies(email *vaultsandbox.Email) {
+	force := false
+	for key, value := range email.Headers {
+		if strings.EqualFold(key, "Content-Transfer-Encoding") {
+			force = format.IsBase64Encoding(value)
+			break
+		}
+	}
+
+	email.Text, _ = format.DecodeBase64(email.Text, force)
+	email.HTML, _ = format.DecodeBase64(email.HTML, force)
+}
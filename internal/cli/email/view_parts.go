@@ -0,0 +1,94 @@
+package email
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/dustin/go-humanize"
+	"github.com/spf13/cobra"
+	"github.com/vaultsandbox/vsb-cli/internal/cliutil"
+	"github.com/vaultsandbox/vsb-cli/internal/mimewalk"
+	"github.com/vaultsandbox/vsb-cli/internal/styles"
+	"golang.org/x/term"
+)
+
+// stdoutIsTerminal reports whether stdout is a terminal; overridden in tests
+var stdoutIsTerminal = func() bool {
+	return term.IsTerminal(int(os.Stdout.Fd()))
+}
+
+// runViewParts handles --parts and --part for a raw message.
+func runViewParts(cmd *cobra.Command, w io.Writer, raw string) error {
+	root, err := mimewalk.Parse([]byte(raw))
+	if err != nil {
+		return err
+	}
+
+	if viewPart != "" {
+		return writePart(w, root, viewPart, viewForce)
+	}
+
+	if cliutil.GetOutput(cmd) == cliutil.FormatJSON {
+		return cliutil.OutputJSON(root)
+	}
+	printPartTree(w, root)
+	return nil
+}
+
+// writePart writes the decoded content of the part at path. Binary parts
+// are only written to a terminal with force.
+func writePart(w io.Writer, root *mimewalk.Part, path string, force bool) error {
+	part := root.Find(path)
+	if part == nil {
+		return fmt.Errorf("part %s not found (use --parts to list them)", path)
+	}
+	if len(part.Children) > 0 || strings.HasPrefix(part.ContentType, "multipart/") {
+		return fmt.Errorf("part %s is a %s container; choose one of its parts", path, part.ContentType)
+	}
+	if !part.IsText() && !force && stdoutIsTerminal() {
+		return fmt.Errorf("part %s is binary (%s); use --force or redirect stdout to a file", path, part.ContentType)
+	}
+
+	content, err := part.Decode()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: part %s: %v\n", path, err)
+	}
+	_, err = w.Write(content)
+	return err
+}
+
+// printPartTree prints one line per part, indented by depth.
+func printPartTree(w io.Writer, root *mimewalk.Part) {
+	root.Walk(func(p *mimewalk.Part, depth int) {
+		path := p.Path
+		if path == "" {
+			path = "-"
+		}
+
+		details := []string{humanize.Bytes(uint64(p.Size))}
+		if p.Charset != "" {
+			details = append(details, "charset="+p.Charset)
+		}
+		if p.Encoding != "" {
+			details = append(details, p.Encoding)
+		}
+		if p.Disposition != "" {
+			details = append(details, p.Disposition)
+		}
+		if p.Filename != "" {
+			details = append(details, fmt.Sprintf("%q", p.Filename))
+		}
+
+		line := fmt.Sprintf("%s%s  %s  %s",
+			strings.Repeat("  ", depth),
+			styles.IDStyle.Render(path),
+			p.ContentType,
+			styles.MutedStyle.Render(strings.Join(details, ", ")))
+		if p.Error != "" {
+			line += "  " + styles.FailStyle.Render("✗ "+p.Error)
+		}
+		fmt.Fprintln(w, line)
+	})
+}
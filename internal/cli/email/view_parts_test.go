@@ -0,0 +1,94 @@
+package email
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vaultsandbox/vsb-cli/internal/mimewalk"
+)
+
+const partsMessage = "Content-Type: multipart/mixed; boundary=b\r\n\r\n" +
+	"--b\r\nContent-Type: text/plain; charset=utf-8\r\nContent-Transfer-Encoding: base64\r\n\r\naGVsbG8=\r\n" +
+	"--b\r\nContent-Type: image/png\r\nContent-Disposition: inline; filename=logo.png\r\nContent-Transfer-Encoding: base64\r\n\r\niVBORw==\r\n" +
+	"--b--\r\n"
+
+func TestWritePart(t *testing.T) {
+	root, err := mimewalk.Parse([]byte(partsMessage))
+	require.NoError(t, err)
+
+	oldIsTerminal := stdoutIsTerminal
+	defer func() { stdoutIsTerminal = oldIsTerminal }()
+	stdoutIsTerminal = func() bool { return true }
+
+	t.Run("text part is decoded", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, writePart(&buf, root, "1", false))
+		assert.Equal(t, "hello", buf.String())
+	})
+
+	t.Run("binary part refused on a terminal", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := writePart(&buf, root, "2", false)
+		assert.EqualError(t, err, "part 2 is binary (image/png); use --force or redirect stdout to a file")
+		assert.Empty(t, buf.String())
+	})
+
+	t.Run("binary part with force", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, writePart(&buf, root, "2", true))
+		assert.Equal(t, "\x89PNG", buf.String())
+	})
+
+	t.Run("binary part when redirected", func(t *testing.T) {
+		stdoutIsTerminal = func() bool { return false }
+		defer func() { stdoutIsTerminal = func() bool { return true } }()
+
+		var buf bytes.Buffer
+		require.NoError(t, writePart(&buf, root, "2", false))
+		assert.Equal(t, "\x89PNG", buf.String())
+	})
+
+	t.Run("unknown part", func(t *testing.T) {
+		err := writePart(&bytes.Buffer{}, root, "3", false)
+		assert.EqualError(t, err, "part 3 not found (use --parts to list them)")
+	})
+
+	t.Run("container part", func(t *testing.T) {
+		nested, err := mimewalk.Parse([]byte("Content-Type: multipart/mixed; boundary=o\r\n\r\n" +
+			"--o\r\nContent-Type: multipart/alternative; boundary=i\r\n\r\n--i\r\n\r\ntext\r\n--i--\r\n--o--\r\n"))
+		require.NoError(t, err)
+
+		err = writePart(&bytes.Buffer{}, nested, "1", false)
+		assert.EqualError(t, err, "part 1 is a multipart/alternative container; choose one of its parts")
+	})
+}
+
+func TestPrintPartTree(t *testing.T) {
+	root, err := mimewalk.Parse([]byte(partsMessage))
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	printPartTree(&buf, root)
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	require.Len(t, lines, 3)
+	assert.Contains(t, lines[0], "-  multipart/mixed")
+	assert.True(t, strings.HasPrefix(lines[1], "  "))
+	assert.Contains(t, lines[1], "1  text/plain")
+	assert.Contains(t, lines[1], "charset=utf-8, base64")
+	assert.Contains(t, lines[2], `inline, "logo.png"`)
+}
+
+func TestViewFlagAliases(t *testing.T) {
+	var parts bool
+	fs := pflag.NewFlagSet("view", pflag.ContinueOnError)
+	fs.BoolVar(&parts, "parts", false, "")
+	fs.SetNormalizeFunc(viewFlagAliases)
+
+	require.NoError(t, fs.Parse([]string{"--list-parts"}))
+	assert.True(t, parts)
+}
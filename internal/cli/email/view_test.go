@@ -0,0 +1,172 @@
+package email
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	vaultsandbox "github.com/vaultsandbox/client-go"
+	"github.com/vaultsandbox/vsb-cli/internal/browser"
+)
+
+func TestTextWrapWidth(t *testing.T) {
+	origWidth := terminalWidth
+	defer func() {
+		terminalWidth = origWidth
+		viewWordWrap = 0
+		viewNoWrap = false
+	}()
+
+	t.Run("uses terminal width by default", func(t *testing.T) {
+		terminalWidth = func() int { return 120 }
+		viewWordWrap, viewNoWrap = 0, false
+		assert.Equal(t, 120, textWrapWidth())
+	})
+
+	t.Run("falls back to 80 without terminal", func(t *testing.T) {
+		terminalWidth = func() int { return 0 }
+		viewWordWrap, viewNoWrap = 0, false
+		assert.Equal(t, 80, textWrapWidth())
+	})
+
+	t.Run("explicit width wins", func(t *testing.T) {
+		terminalWidth = func() int { return 120 }
+		viewWordWrap, viewNoWrap = 60, false
+		assert.Equal(t, 60, textWrapWidth())
+	})
+
+	t.Run("no-wrap disables wrapping", func(t *testing.T) {
+		viewWordWrap, viewNoWrap = 60, true
+		assert.Equal(t, 0, textWrapWidth())
+	})
+}
+
+func TestDecodeBase64Bodies(t *testing.T) {
+	t.Run("decodes base64 text body", func(t *testing.T) {
+		email := &vaultsandbox.Email{
+			Text: "SGVsbG8sIHRoaXMgaXMgYSB0ZXN0IGVtYWlsIGJvZHku",
+			HTML: "<p>Hello</p>",
+		}
+		decodeBase64Bodies(email)
+		assert.Equal(t, "Hello, this is a test email body.", email.Text)
+		assert.Equal(t, "<p>Hello</p>", email.HTML)
+	})
+
+	t.Run("header forces decoding of short content", func(t *testing.T) {
+		email := &vaultsandbox.Email{
+			Text:    "dGVzdA==",
+			Headers: map[string]string{"content-transfer-encoding": "base64"},
+		}
+		decodeBase64Bodies(email)
+		assert.Equal(t, "test", email.Text)
+	})
+
+	t.Run("plain text untouched", func(t *testing.T) {
+		email := &vaultsandbox.Email{Text: "Just a normal email."}
+		decodeBase64Bodies(email)
+		assert.Equal(t, "Just a normal email.", email.Text)
+	})
+}
+
+func TestPreviewInTerminal(t *testing.T) {
+	originalRender := renderHTMLInTerminalFunc
+	defer func() { renderHTMLInTerminalFunc = originalRender }()
+	viewNoWrap = true
+	defer func() { viewNoWrap = false }()
+
+	email := &vaultsandbox.Email{
+		Subject:    "Welcome",
+		From:       "sender@example.com",
+		ReceivedAt: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Text:       "Plain body",
+		HTML:       "<p>HTML body</p>",
+	}
+
+	t.Run("renders HTML", func(t *testing.T) {
+		var rendered string
+		renderHTMLInTerminalFunc = func(html string) error {
+			rendered = html
+			fmt.Println("HTML body")
+			return nil
+		}
+
+		out := captureURLStdout(t, func() {
+			require.NoError(t, previewInTerminal(email))
+		})
+		assert.Equal(t, email.HTML, rendered)
+		assert.Contains(t, out, "Subject: Welcome")
+		assert.Contains(t, out, "HTML body")
+		assert.NotContains(t, out, "Plain body")
+	})
+
+	t.Run("falls back to text without renderer", func(t *testing.T) {
+		renderHTMLInTerminalFunc = func(string) error { return browser.ErrNoTerminalRenderer }
+
+		out := captureURLStdout(t, func() {
+			require.NoError(t, previewInTerminal(email))
+		})
+		assert.Contains(t, out, "Plain body")
+	})
+
+	t.Run("renderer failure is returned", func(t *testing.T) {
+		renderHTMLInTerminalFunc = func(string) error { return errors.New("boom") }
+
+		var err error
+		captureURLStdout(t, func() { err = previewInTerminal(email) })
+		assert.EqualError(t, err, "boom")
+	})
+
+	t.Run("text only email", func(t *testing.T) {
+		renderHTMLInTerminalFunc = func(string) error {
+			t.Fatal("renderer should not be called")
+			return nil
+		}
+
+		out := captureURLStdout(t, func() {
+			require.NoError(t, previewInTerminal(&vaultsandbox.Email{Text: "Only text"}))
+		})
+		assert.Contains(t, out, "Only text")
+	})
+}
+
+func TestRawHeaderBlock(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+		want string
+	}{
+		{
+			name: "CRLF message",
+			raw:  "Received: from a\r\n\tby b\r\nSubject: Hi\r\n\r\nBody\r\n\r\nMore\r\n",
+			want: "Received: from a\r\n\tby b\r\nSubject: Hi",
+		},
+		{
+			name: "LF message",
+			raw:  "From: a@example.com\nTo: b@example.com\n\nBody\n",
+			want: "From: a@example.com\nTo: b@example.com",
+		},
+		{
+			name: "keeps order and folding",
+			raw:  "X-B: 2\nX-A: 1\nDKIM-Signature: v=1;\n  b=abc\n\n",
+			want: "X-B: 2\nX-A: 1\nDKIM-Signature: v=1;\n  b=abc",
+		},
+		{
+			name: "headers without body",
+			raw:  "Subject: Only headers\r\n",
+			want: "Subject: Only headers",
+		},
+		{
+			name: "no headers",
+			raw:  "\r\nBody",
+			want: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, rawHeaderBlock(tt.raw))
+		})
+	}
+}
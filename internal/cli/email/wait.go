@@ -3,18 +3,31 @@ package email
 import (
 	"context"
 	"fmt"
+	"io"
+	"net/http"
 	"os"
+	"os/exec"
 	"regexp"
+	"runtime"
+	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
 	vaultsandbox "github.com/vaultsandbox/client-go"
+	"github.com/vaultsandbox/vsb-cli/internal/browser"
+	"github.com/vaultsandbox/vsb-cli/internal/ci"
 	"github.com/vaultsandbox/vsb-cli/internal/cliutil"
+	"github.com/vaultsandbox/vsb-cli/internal/config"
+	"github.com/vaultsandbox/vsb-cli/internal/timeparse"
 )
 
+// isHeadlessFunc is a variable for browser.IsHeadless that can be overridden in tests
+var isHeadlessFunc = browser.IsHeadless
+
 var waitCmd = &cobra.Command{
-	Use:   "wait",
-	Short: "Wait for an email matching criteria (CI/CD)",
+	Use:     "wait",
+	Aliases: []string{"w"},
+	Short:   "Wait for an email matching criteria (CI/CD)",
 	Long: `Block until an email matching the specified criteria arrives.
 
 Designed for CI/CD pipelines and automated testing. Returns exit code 0
@@ -25,10 +38,101 @@ Filter Options:
   --subject-regex Subject regex pattern
   --from          Exact sender match
   --from-regex    Sender regex pattern
+  --body-regex    Body regex pattern (plain text, or HTML if there is none)
+  --not-subject, --not-subject-regex, --not-from, --not-from-regex
+                  Skip emails matching these and keep waiting
+
+An email must match every positive filter and none of the --not-* filters.
+
+  --preset        Apply a filter preset stored on the inbox with
+                  'vsb inbox filter set'; filter flags given on the
+                  command line override the preset's values
 
 Output Options:
   --quiet         No output, just exit code
   --extract-link  Output first link from email body
+  --print-id      Output only the email ID (one per line)
+  --extract-regex Output only the named capture group of --body-regex
+  --open[=N]      Open the first (or Nth) link in the browser
+  --link-match    Select the link to extract/open by regex
+  --github-output Write email_id, subject and link to $GITHUB_OUTPUT and
+                  annotate failures (automatic with ci-integration config)
+
+Age Options:
+  --only-new      Only count emails received after the wait began
+  --min-received  Only count emails received at or after this time
+                  (RFC3339, or a duration like 10m meaning 10 minutes ago)
+
+By default an email already in the inbox matches, which suits sending the
+email before waiting. In reused inboxes, use --only-new (or --trigger, which
+runs after the wait began) so an older email with the same subject is not
+mistaken for the new one. Receive times come from the server, so keep the
+local clock in sync.
+
+Polling Options:
+  --poll-interval Poll for new email at this fixed interval (at least 500ms,
+                  shorter than --timeout), overriding the strategy and
+                  poll-interval config for this wait
+
+Inbox Options:
+  --inbox         Inbox to watch; repeat to watch several at once
+  --all-inboxes   Watch every inbox in the keystore (same as --inbox all)
+  --per-inbox     With --count, wait for N emails in each inbox
+
+When several inboxes are watched, the first matching email in any of them
+completes the wait and the output includes the inbox it arrived in.
+
+Forwarding Options:
+  --post          POST each matched email as JSON to this URL
+  --post-header   Extra request header ('Name: value'), repeatable
+  --post-timeout  Timeout for each POST attempt
+  --include-attachments  Include base64 attachment content in the POST
+  --post-best-effort     Only warn when the POST fails
+
+Failed POSTs are retried with backoff on network errors, 429 and 5xx
+responses. Unless --post-best-effort is set, a POST that still fails makes
+the command exit non-zero.
+
+Webhook Options:
+  --webhook       Send each matched email as JSON to this URL
+  --webhook-method  HTTP method: POST (default), PUT or PATCH
+  --webhook-header  Extra request header ('Name: value'), repeatable
+  --webhook-timeout Timeout for each webhook attempt
+  --webhook-fail-on-error  Exit non-zero when the webhook fails
+
+A webhook is a notification on top of the normal output: it is retried like
+--post, but if it still fails only a warning is printed and the command
+exits 0, since the email was received. Use --webhook-fail-on-error to make
+the failure fatal.
+
+Saving Options:
+  --save-to-dir   Save each matched email to this directory
+  --save-format   eml (default, the raw source), json or text
+
+Saved files are named <received time>-<id>.<ext>, with the time in UTC, and
+each is written as soon as its email matches, so files saved before a
+timeout are kept; each path is reported on stderr. The normal output is
+unchanged, and a failed save makes the command fail after it.
+
+Exec Options:
+  --exec          Shell command to run with each matched email
+
+--exec replaces the normal output with the command's own, streamed as it
+runs, and the wait exits with the command's exit status. With --count > 1
+the command runs once per matched email, in received order, stopping at the
+first one that fails. In the command, {id}, {subject}, {from} and {inbox}
+are replaced by the email's values, each shell-quoted as a single word, so
+do not put quotes around them; write {{id}} for a literal {id}. The same
+values are in $VSB_EMAIL_ID, $VSB_EMAIL_SUBJECT, $VSB_EMAIL_FROM and
+$VSB_EMAIL_INBOX. Like --post, the command is not bound by --timeout.
+
+Trigger Options:
+  --trigger       Shell command to run once the inbox is being watched
+  --trigger-url   URL to request once the inbox is being watched
+
+The trigger runs after the inbox subscription is established and before
+blocking, so an email sent by the trigger cannot be missed. If the trigger
+fails (non-zero exit or non-2xx response) the wait is aborted.
 
 Examples:
   # Wait for any email
@@ -37,27 +141,112 @@ Examples:
   # Wait for password reset email
   vsb email wait --subject-regex "password reset" --timeout 30s
 
+  # Wait using the filters saved as the "billing" preset
+  vsb email wait --preset billing
+
+  # Wait for any email not sent by the newsletter system
+  vsb email wait --not-from newsletter@example.com --timeout 30s
+
   # Extract verification link
   LINK=$(vsb email wait --subject "Verify" --extract-link)
 
+  # Capture a one-time password
+  OTP=$(vsb email wait --body-regex 'Your OTP is: (?P<otp>\d{6})' --extract-regex otp --timeout 30s)
+
+  # Ignore emails left over from earlier runs
+  vsb email wait --subject "Welcome" --only-new
+  vsb email wait --subject "Welcome" --min-received 2026-01-02T15:04:05Z
+
+  # Capture the email ID
+  ID=$(vsb email wait --subject "Welcome" --print-id)
+
+  # Keep the emails of a test run as EML files
+  vsb email wait --count 2 --save-to-dir ./emails
+
+  # Wait, then audit the email that arrived
+  vsb email wait --subject "Welcome" --exec 'vsb email audit {id}'
+
+  # Open the verification link in the browser
+  vsb email wait --subject "Verify" --open
+  vsb email wait --open --link-match "/verify\?token="
+
+  # Trigger a password reset, then wait for the email
+  vsb email wait --subject-regex "reset" --trigger 'curl -fsS -X POST https://app/reset'
+  vsb email wait --subject-regex "reset" --trigger-url https://app/reset
+
+  # Poll every 750ms on a flaky environment where SSE drops
+  vsb email wait --subject "Verify" --poll-interval 750ms --timeout 20s
+
+  # Wait on two inboxes, whichever receives the email first
+  vsb email wait --inbox signup@abc.vsx.email --inbox admin@abc.vsx.email
+
+  # Wait on every inbox in the keystore
+  vsb email wait --inbox all --subject "Welcome"
+
+  # Forward the email to an HTTP endpoint
+  vsb email wait --subject "Welcome" --post https://qa.example.com/ingest --post-header 'X-Token: abc'
+
+  # Notify a webhook when the email arrives
+  vsb email wait --subject "Deploy complete" --webhook https://hooks.example.com/notify --webhook-header 'X-API-Key: abc'
+
   # JSON output for parsing
   vsb email wait --from "noreply@example.com" -o json | jq .subject`,
 	RunE: runWait,
 }
 
 var (
-	waitForSubject      string
-	waitForSubjectRegex string
-	waitForFrom         string
-	waitForFromRegex    string
-	waitForTimeout      string
-	waitForQuiet        bool
-	waitForExtractLink  bool
-	waitForCount        int
+	waitForSubject       string
+	waitForSubjectRegex  string
+	waitForFrom          string
+	waitForFromRegex     string
+	waitForBodyRegex     string
+	waitForNotSubject    string
+	waitForNotSubjectRe  string
+	waitForNotFrom       string
+	waitForNotFromRe     string
+	waitForExtractRegex  string
+	waitForTimeout       string
+	waitForQuiet         bool
+	waitForExtractLink   bool
+	waitForCount         int
+	waitForOpen          int
+	waitForLinkMatch     string
+	waitForPrintID       bool
+	waitForTrigger       string
+	waitForTriggerURL    string
+	waitForTriggerMethod string
+	waitForGitHubOutput  bool
+	waitForInboxes       []string
+	waitForAllInboxes    bool
+	waitForPerInbox      bool
+	waitForOnlyNew       bool
+	waitForMinReceived   string
+	waitForPollInterval  string
+	waitForExec          string
+
+	// waitReceivedAfter is the cutoff resolved from --only-new and
+	// --min-received; zero when emails of any age count
+	waitReceivedAfter time.Time
+
+	waitForPost               string
+	waitForPostHeaders        []string
+	waitForPostTimeout        string
+	waitForIncludeAttachments bool
+	waitForPostBestEffort     bool
+
+	waitForWebhook            string
+	waitForWebhookMethod      string
+	waitForWebhookHeaders     []string
+	waitForWebhookTimeout     string
+	waitForWebhookFailOnError bool
+
+	waitForSaveToDir  string
+	waitForSaveFormat string
 )
 
 func init() {
 	Cmd.AddCommand(waitCmd)
+	cliutil.AddOutputFormats(waitCmd, cliutil.FormatJSON, cliutil.FormatRaw)
 
 	// Filters
 	waitCmd.Flags().StringVar(&waitForSubject, "subject", "",
@@ -68,124 +257,674 @@ func init() {
 		"Exact sender match")
 	waitCmd.Flags().StringVar(&waitForFromRegex, "from-regex", "",
 		"Sender regex pattern")
+	waitCmd.Flags().StringVar(&waitForBodyRegex, "body-regex", "",
+		"Body regex pattern")
+	waitCmd.Flags().StringVar(&waitForNotSubject, "not-subject", "",
+		"Skip emails with exactly this subject")
+	waitCmd.Flags().StringVar(&waitForNotSubjectRe, "not-subject-regex", "",
+		"Skip emails whose subject matches this regex")
+	waitCmd.Flags().StringVar(&waitForNotFrom, "not-from", "",
+		"Skip emails from exactly this sender")
+	waitCmd.Flags().StringVar(&waitForNotFromRe, "not-from-regex", "",
+		"Skip emails whose sender matches this regex")
 
 	// Timing
 	waitCmd.Flags().StringVar(&waitForTimeout, "timeout", "60s",
 		"Maximum time to wait")
 	waitCmd.Flags().IntVar(&waitForCount, "count", 1,
 		"Number of matching emails to wait for")
+	waitCmd.Flags().BoolVar(&waitForOnlyNew, "only-new", false,
+		"Ignore emails received before the wait began")
+	waitCmd.Flags().StringVar(&waitForMinReceived, "min-received", "",
+		"Ignore emails received before this time (RFC3339 or relative, e.g. 10m)")
+	waitCmd.Flags().StringVar(&waitForPollInterval, "poll-interval", "",
+		"Poll at this interval instead of the configured strategy (min 500ms)")
+
+	// Inboxes (shadows the email command's single --inbox flag)
+	waitCmd.Flags().StringArrayVar(&waitForInboxes, "inbox", nil,
+		"Inbox to watch, repeatable, or \"all\" (default: active)")
+	waitCmd.Flags().BoolVar(&waitForAllInboxes, "all-inboxes", false,
+		"Watch every inbox in the keystore")
+	waitCmd.Flags().BoolVar(&waitForPerInbox, "per-inbox", false,
+		"Wait for --count emails in each watched inbox")
 
 	// Output
 	waitCmd.Flags().BoolVarP(&waitForQuiet, "quiet", "q", false,
 		"No output, exit code only")
 	waitCmd.Flags().BoolVar(&waitForExtractLink, "extract-link", false,
 		"Output first link from email")
+	waitCmd.Flags().IntVar(&waitForOpen, "open", 0,
+		"Open a link in the browser (--open for the first, --open=N for the Nth)")
+	waitCmd.Flags().Lookup("open").NoOptDefVal = "1"
+	waitCmd.Flags().StringVar(&waitForLinkMatch, "link-match", "",
+		"Only consider links matching this regex for --extract-link and --open")
+	waitCmd.Flags().BoolVar(&waitForPrintID, "print-id", false,
+		"Output only the matched email ID")
+	waitCmd.Flags().StringVar(&waitForExtractRegex, "extract-regex", "",
+		"Output only this named capture group from --body-regex")
+	waitCmd.Flags().BoolVar(&waitForGitHubOutput, "github-output", false,
+		"Write results to $GITHUB_OUTPUT and annotate failures")
+
+	// Exec
+	waitCmd.Flags().StringVar(&waitForExec, "exec", "",
+		"Shell command to run with each matched email ({id}, {subject}, {from}, {inbox})")
+
+	// Trigger
+	waitCmd.Flags().StringVar(&waitForTrigger, "trigger", "",
+		"Shell command to run after the watch starts")
+	waitCmd.Flags().StringVar(&waitForTriggerURL, "trigger-url", "",
+		"URL to request after the watch starts")
+	waitCmd.Flags().StringVar(&waitForTriggerMethod, "trigger-method", http.MethodPost,
+		"HTTP method for --trigger-url")
+
+	// Forwarding
+	waitCmd.Flags().StringVar(&waitForPost, "post", "",
+		"POST each matched email as JSON to this URL")
+	waitCmd.Flags().StringArrayVar(&waitForPostHeaders, "post-header", nil,
+		"Header for --post requests ('Name: value'), repeatable")
+	waitCmd.Flags().StringVar(&waitForPostTimeout, "post-timeout", "10s",
+		"Timeout for each --post attempt")
+	waitCmd.Flags().BoolVar(&waitForIncludeAttachments, "include-attachments", false,
+		"Include base64 attachment content in --post and --webhook requests")
+	waitCmd.Flags().BoolVar(&waitForPostBestEffort, "post-best-effort", false,
+		"Warn instead of failing when --post fails")
+	waitCmd.Flags().StringVar(&waitForWebhook, "webhook", "",
+		"Send each matched email as JSON to this webhook URL")
+	waitCmd.Flags().StringVar(&waitForWebhookMethod, "webhook-method", http.MethodPost,
+		"HTTP method for --webhook: POST, PUT or PATCH")
+	waitCmd.Flags().StringArrayVar(&waitForWebhookHeaders, "webhook-header", nil,
+		"Header for --webhook requests ('Name: value'), repeatable")
+	waitCmd.Flags().StringVar(&waitForWebhookTimeout, "webhook-timeout", "10s",
+		"Timeout for each --webhook attempt")
+	waitCmd.Flags().BoolVar(&waitForWebhookFailOnError, "webhook-fail-on-error", false,
+		"Exit non-zero when --webhook fails (default: warn only)")
+
+	// Saving
+	waitCmd.Flags().StringVar(&waitForSaveToDir, "save-to-dir", "",
+		"Save each matched email to this directory (created if needed)")
+	waitCmd.Flags().StringVar(&waitForSaveFormat, "save-format", "eml",
+		"Format of saved emails: eml, json or text")
+
+	waitCmd.MarkFlagsMutuallyExclusive("print-id", "extract-link", "extract-regex")
+	waitCmd.MarkFlagsMutuallyExclusive("exec", "print-id", "extract-link", "extract-regex")
+	waitCmd.MarkFlagsMutuallyExclusive("trigger", "trigger-url")
+	waitCmd.MarkFlagsMutuallyExclusive("inbox", "all-inboxes")
+	cliutil.AddPresetFlag(waitCmd)
+	addDebugRawFlags(waitCmd)
 }
 
-func runWait(cmd *cobra.Command, args []string) error {
+func runWait(cmd *cobra.Command, args []string) (err error) {
+	reporter := ci.NewReporter(waitForGitHubOutput)
+	defer func() {
+		if err != nil {
+			reporter.Error(err.Error())
+		}
+	}()
+
+	// Taken first so --only-new covers everything that happens below
+	startedAt := time.Now()
+
 	// Parse timeout
 	timeout, err := time.ParseDuration(waitForTimeout)
 	if err != nil {
 		return fmt.Errorf("invalid timeout format: %w", err)
 	}
 
+	waitReceivedAfter, err = receivedCutoff(waitForOnlyNew, waitForMinReceived, startedAt)
+	if err != nil {
+		return err
+	}
+
+	pollInterval, err := parsePollInterval(waitForPollInterval, timeout)
+	if err != nil {
+		return err
+	}
+	config.SetPollIntervalOverride(pollInterval)
+	defer config.SetPollIntervalOverride(0)
+
 	// Create context with timeout
-	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	ctx, cancel := context.WithTimeout(cliutil.CommandContext(cmd), timeout)
 	defer cancel()
 
-	// Use shared helper
-	inbox, cleanup, err := cliutil.LoadAndImportInbox(ctx, InboxFlag)
+	linkMatch, err := compileLinkMatch()
 	if err != nil {
 		return err
 	}
-	defer cleanup()
 
-	// Build wait options
-	opts, err := buildWaitOptions(timeout)
+	// Validate the capture group before waiting, not after the email arrives
+	extractRe, err := compileExtractRegex(waitForBodyRegex, waitForExtractRegex)
 	if err != nil {
 		return err
 	}
 
+	poster, err := newEmailPoster()
+	if err != nil {
+		return err
+	}
+	webhook, err := newWebhookPoster()
+	if err != nil {
+		return err
+	}
+
+	saver, err := newEmailSaver(cliutil.CommandContext(cmd))
+	if err != nil {
+		return err
+	}
+
+	inboxFlags, allInboxes, err := resolveWaitInboxes(waitForInboxes, waitForAllInboxes)
+	if err != nil {
+		return err
+	}
+	multi := allInboxes || len(inboxFlags) > 1
+
+	var matches []matchedEmail
+	if multi {
+		matches, err = waitMultipleInboxes(ctx, timeout, inboxFlags, allInboxes, saver)
+	} else {
+		matches, err = waitSingleInbox(ctx, timeout, inboxFlags, saver)
+	}
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return &cliutil.TimeoutError{What: "email", Timeout: timeout}
+		}
+		return err
+	}
+	// Matches saved before a timeout stay on disk; a failed save is reported
+	// once every match has been printed
+	defer func() {
+		if err == nil {
+			err = saver.Err()
+		}
+	}()
+
+	// Output result
+	emails := make([]*vaultsandbox.Email, len(matches))
+	for i, m := range matches {
+		emails[i] = m.Email
+	}
+	if err := writeDebugRaw(cmd, emails...); err != nil {
+		return err
+	}
+	// With --output raw the payload replaces the normal output, and with
+	// --exec the command's output does
+	showOutput := !rawOutput(cmd) && waitForExec == ""
+	if extractRe != nil && showOutput {
+		if err := outputExtracted(matches, extractRe, waitForExtractRegex); err != nil {
+			return err
+		}
+	} else if showOutput {
+		if err := outputEmails(cmd, matches, linkMatch, multi); err != nil {
+			return err
+		}
+	}
+	if !waitForQuiet {
+		for _, m := range matches {
+			cliutil.MarkRead(m.Inbox, m.Email.ID)
+		}
+	}
+	if err := reportWaitResult(reporter, matches[0].Email, linkMatch); err != nil {
+		return err
+	}
+
+	// Not bound by --timeout, which only covers waiting for the email
+	if poster != nil {
+		if err := poster.PostAll(cliutil.CommandContext(cmd), matches); err != nil {
+			if !waitForPostBestEffort {
+				return err
+			}
+			if !waitForQuiet {
+				fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+			}
+		}
+	}
+
+	if webhook != nil {
+		if err := notifyWebhook(cliutil.CommandContext(cmd), webhook, matches, os.Stderr); err != nil {
+			return err
+		}
+	}
+
+	if waitForOpen > 0 {
+		if err := openWaitLink(matches[0].Email, linkMatch); err != nil {
+			return err
+		}
+	}
+
+	if waitForExec != "" {
+		stdout, stderr := io.Writer(os.Stdout), io.Writer(os.Stderr)
+		if waitForQuiet {
+			stdout, stderr = io.Discard, io.Discard
+		}
+		return runExec(cliutil.CommandContext(cmd), waitForExec, matches, stdout, stderr)
+	}
+	return nil
+}
+
+// waitSingleInbox waits on the selected (or active) inbox using the SDK's
+// filtered wait, one email at a time so saver, if any, saves each match as
+// it arrives.
+func waitSingleInbox(ctx context.Context, timeout time.Duration, inboxFlags []string, saver *emailSaver) ([]matchedEmail, error) {
+	inboxFlag := ""
+	if len(inboxFlags) == 1 {
+		inboxFlag = inboxFlags[0]
+	}
+
+	// Use shared helper
+	inbox, cleanup, err := cliutil.LoadAndImportInbox(ctx, inboxFlag)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+
+	// Build wait options; seen keeps each wait from returning an email an
+	// earlier one already matched
+	seen := make(map[string]bool)
+	opts, err := buildWaitOptions(timeout, seen)
+	if err != nil {
+		return nil, err
+	}
+
 	// Show waiting message (unless quiet)
+	address := inbox.EmailAddress()
 	if !waitForQuiet {
 		fmt.Fprintf(os.Stderr, "Waiting for email on %s (timeout: %s)...\n",
-			inbox.Export().EmailAddress, timeout)
+			address, timeout)
+	}
+
+	// The inbox is imported and subscribed, so anything the trigger causes to
+	// be sent is delivered to us. The SDK wait also checks existing emails,
+	// covering delivery between the trigger returning and the wait starting.
+	if err := runTrigger(ctx, os.Stderr); err != nil {
+		return nil, err
 	}
 
 	// Wait for email(s)
-	var emails []*vaultsandbox.Email
-	if waitForCount > 1 {
-		emails, err = inbox.WaitForEmailCount(ctx, waitForCount, opts...)
-	} else {
-		email, waitErr := inbox.WaitForEmail(ctx, opts...)
-		if waitErr != nil {
-			err = waitErr
-		} else {
-			emails = []*vaultsandbox.Email{email}
+	var matches []matchedEmail
+	for len(matches) < max(waitForCount, 1) {
+		email, err := inbox.WaitForEmail(ctx, opts...)
+		if err != nil {
+			return nil, err
 		}
+		seen[email.ID] = true
+		m := matchedEmail{Inbox: address, Email: email}
+		matches = append(matches, m)
+		saver.Save(inbox, m, saveLog())
 	}
+	return matches, nil
+}
 
+// waitMultipleInboxes watches several inboxes at once and returns as soon as
+// enough matching emails arrived across them (or in each, with --per-inbox).
+// Like waitSingleInbox, it saves each match with saver, if any, as it
+// arrives.
+func waitMultipleInboxes(ctx context.Context, timeout time.Duration, inboxFlags []string, all bool, saver *emailSaver) ([]matchedEmail, error) {
+	match, err := buildEmailMatcher()
 	if err != nil {
-		if ctx.Err() == context.DeadlineExceeded {
-			return fmt.Errorf("timeout waiting for email")
+		return nil, err
+	}
+
+	client, inboxes, cleanup, err := cliutil.LoadAndImportInboxes(ctx, inboxFlags, all)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+
+	addresses := make([]string, len(inboxes))
+	byAddress := make(map[string]*vaultsandbox.Inbox, len(inboxes))
+	for i, inbox := range inboxes {
+		addresses[i] = inbox.EmailAddress()
+		byAddress[addresses[i]] = inbox
+	}
+
+	// Subscribe before the trigger runs and before listing existing emails,
+	// so nothing delivered in between is missed; duplicates are ignored.
+	incoming := watchInboxEmails(ctx, client, inboxes)
+
+	if !waitForQuiet {
+		fmt.Fprintf(os.Stderr, "Waiting for email on %d inboxes (timeout: %s)...\n",
+			len(inboxes), timeout)
+	}
+
+	if err := runTrigger(ctx, os.Stderr); err != nil {
+		return nil, err
+	}
+
+	existing, err := existingInboxEmails(ctx, inboxes)
+	if err != nil {
+		return nil, err
+	}
+
+	return collectMatches(ctx, existing, incoming, match, addresses, waitForCount, waitForPerInbox,
+		func(m matchedEmail) { saver.Save(byAddress[m.Inbox], m, saveLog()) })
+}
+
+// saveLog is where saved file paths are reported: stderr, so stdout keeps
+// the normal output, or nowhere with --quiet
+func saveLog() io.Writer {
+	if waitForQuiet {
+		return io.Discard
+	}
+	return os.Stderr
+}
+
+// resolveWaitInboxes interprets the --inbox values: "all" selects every
+// inbox in the keystore, like --all-inboxes, and cannot be combined with
+// specific inboxes.
+func resolveWaitInboxes(flags []string, all bool) ([]string, bool, error) {
+	for _, f := range flags {
+		if strings.EqualFold(f, "all") {
+			if len(flags) > 1 {
+				return nil, false, fmt.Errorf("--inbox all cannot be combined with other inboxes")
+			}
+			return nil, true, nil
 		}
-		return err
 	}
+	return flags, all, nil
+}
 
-	// Output result
-	outputEmails(cmd, emails)
+// runTrigger runs the --trigger command or requests the --trigger-url.
+// Command output goes to w so stdout stays reserved for the wait result.
+func runTrigger(ctx context.Context, w io.Writer) error {
+	if waitForQuiet {
+		w = io.Discard
+	}
+
+	switch {
+	case waitForTrigger != "":
+		var cmd *exec.Cmd
+		if runtime.GOOS == "windows" {
+			cmd = exec.CommandContext(ctx, "cmd", "/C", waitForTrigger)
+		} else {
+			cmd = exec.CommandContext(ctx, "sh", "-c", waitForTrigger)
+		}
+		cmd.Stdout = w
+		cmd.Stderr = w
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("trigger command failed: %w", err)
+		}
+	case waitForTriggerURL != "":
+		req, err := http.NewRequestWithContext(ctx, waitForTriggerMethod, waitForTriggerURL, nil)
+		if err != nil {
+			return fmt.Errorf("invalid trigger URL: %w", err)
+		}
+		resp, err := config.NewHTTPClient(0).Do(req)
+		if err != nil {
+			return fmt.Errorf("trigger request failed: %w", err)
+		}
+		defer resp.Body.Close()
+		_, _ = io.Copy(io.Discard, resp.Body)
+		if resp.StatusCode < 200 || resp.StatusCode > 299 {
+			return fmt.Errorf("trigger request failed: %s %s returned %s",
+				req.Method, waitForTriggerURL, resp.Status)
+		}
+	}
 	return nil
 }
 
-func buildWaitOptions(timeout time.Duration) ([]vaultsandbox.WaitOption, error) {
-	var opts []vaultsandbox.WaitOption
+// buildWaitOptions returns the SDK wait options for the filter flags,
+// skipping emails whose ID is in seen. Every filter goes into a single
+// predicate: WithPredicate replaces the previous predicate rather than
+// adding to it.
+func buildWaitOptions(timeout time.Duration, seen map[string]bool) ([]vaultsandbox.WaitOption, error) {
+	matches, err := buildEmailMatcher()
+	if err != nil {
+		return nil, err
+	}
+	return []vaultsandbox.WaitOption{
+		vaultsandbox.WithWaitTimeout(timeout),
+		vaultsandbox.WithPredicate(func(e *vaultsandbox.Email) bool {
+			return !seen[e.ID] && matches(e)
+		}),
+	}, nil
+}
 
-	// Set timeout
-	opts = append(opts, vaultsandbox.WithWaitTimeout(timeout))
+// receivedCutoff returns the earliest receive time an email may have to
+// count: startedAt with onlyNew, the parsed minReceived, or the later of the
+// two. It returns the zero time when neither is set.
+func receivedCutoff(onlyNew bool, minReceived string, startedAt time.Time) (time.Time, error) {
+	var cutoff time.Time
+	if minReceived != "" {
+		t, err := timeparse.Time(minReceived, startedAt)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid --min-received: %w", err)
+		}
+		cutoff = t
+	}
+	if onlyNew && startedAt.After(cutoff) {
+		cutoff = startedAt
+	}
+	return cutoff, nil
+}
 
-	// Subject filters
-	if waitForSubject != "" {
-		opts = append(opts, vaultsandbox.WithSubject(waitForSubject))
+// parsePollInterval validates --poll-interval, which must leave time for
+// at least one poll before the timeout. Zero means the flag was not given.
+func parsePollInterval(value string, timeout time.Duration) (time.Duration, error) {
+	if value == "" {
+		return 0, nil
 	}
-	if waitForSubjectRegex != "" {
-		re, err := regexp.Compile(waitForSubjectRegex)
-		if err != nil {
-			return nil, fmt.Errorf("invalid subject regex: %w", err)
+	interval, err := config.ParsePollInterval(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid --poll-interval: %w", err)
+	}
+	if interval >= timeout {
+		return 0, fmt.Errorf("--poll-interval %s must be shorter than --timeout %s", interval, timeout)
+	}
+	return interval, nil
+}
+
+// waitFilter returns the positive filter flags.
+func waitFilter() emailFilter {
+	return emailFilter{
+		Subject:      waitForSubject,
+		SubjectRegex: waitForSubjectRegex,
+		From:         waitForFrom,
+		FromRegex:    waitForFromRegex,
+		BodyRegex:    waitForBodyRegex,
+	}
+}
+
+// buildExclusionFilter returns a function reporting whether an email matches
+// any of the --not-* filters, or nil if none are set.
+func buildExclusionFilter() (func(*vaultsandbox.Email) bool, error) {
+	if waitForNotSubject == "" && waitForNotSubjectRe == "" && waitForNotFrom == "" && waitForNotFromRe == "" {
+		return nil, nil
+	}
+
+	var subjectRe, fromRe *regexp.Regexp
+	var err error
+	if waitForNotSubjectRe != "" {
+		if subjectRe, err = regexp.Compile(waitForNotSubjectRe); err != nil {
+			return nil, fmt.Errorf("invalid not-subject regex: %w", err)
 		}
-		opts = append(opts, vaultsandbox.WithSubjectRegex(re))
 	}
+	if waitForNotFromRe != "" {
+		if fromRe, err = regexp.Compile(waitForNotFromRe); err != nil {
+			return nil, fmt.Errorf("invalid not-from regex: %w", err)
+		}
+	}
+
+	return func(e *vaultsandbox.Email) bool {
+		return (waitForNotSubject != "" && e.Subject == waitForNotSubject) ||
+			(subjectRe != nil && subjectRe.MatchString(e.Subject)) ||
+			(waitForNotFrom != "" && e.From == waitForNotFrom) ||
+			(fromRe != nil && fromRe.MatchString(e.From))
+	}, nil
+}
+
+// emailBody returns the text matched by --body-regex: the plain text body,
+// or the HTML body for HTML-only emails.
+func emailBody(e *vaultsandbox.Email) string {
+	if e.Text != "" {
+		return e.Text
+	}
+	return e.HTML
+}
+
+// compileExtractRegex compiles the --body-regex used by --extract-regex and
+// checks that it defines the named group. It returns nil if --extract-regex
+// is unset.
+func compileExtractRegex(bodyRegex, group string) (*regexp.Regexp, error) {
+	if group == "" {
+		return nil, nil
+	}
+	if bodyRegex == "" {
+		return nil, fmt.Errorf("--extract-regex requires --body-regex")
+	}
+	re, err := regexp.Compile(bodyRegex)
+	if err != nil {
+		return nil, fmt.Errorf("invalid body regex: %w", err)
+	}
+	if re.SubexpIndex(group) < 0 {
+		return nil, fmt.Errorf("--body-regex has no capture group named %q", group)
+	}
+	return re, nil
+}
 
-	// From filters
-	if waitForFrom != "" {
-		opts = append(opts, vaultsandbox.WithFrom(waitForFrom))
+// extractGroup returns the value of the named capture group in the email
+// body. It fails if the group did not take part in the match.
+func extractGroup(e *vaultsandbox.Email, re *regexp.Regexp, group string) (string, error) {
+	body := emailBody(e)
+	m := re.FindStringSubmatchIndex(body)
+	i := re.SubexpIndex(group)
+	if m == nil || m[2*i] < 0 {
+		return "", fmt.Errorf("capture group %q did not match in email %s", group, e.ID)
 	}
-	if waitForFromRegex != "" {
-		re, err := regexp.Compile(waitForFromRegex)
+	return body[m[2*i]:m[2*i+1]], nil
+}
+
+// outputExtracted prints the captured value from each matched email, one per
+// line. It stops at the first email where the group did not match.
+func outputExtracted(matches []matchedEmail, re *regexp.Regexp, group string) error {
+	for _, m := range matches {
+		value, err := extractGroup(m.Email, re, group)
 		if err != nil {
-			return nil, fmt.Errorf("invalid from regex: %w", err)
+			return err
+		}
+		if !waitForQuiet {
+			fmt.Println(value)
 		}
-		opts = append(opts, vaultsandbox.WithFromRegex(re))
 	}
+	return nil
+}
 
-	return opts, nil
+// compileLinkMatch compiles the --link-match regex, or returns nil if unset.
+func compileLinkMatch() (*regexp.Regexp, error) {
+	if waitForLinkMatch == "" {
+		return nil, nil
+	}
+	re, err := regexp.Compile(waitForLinkMatch)
+	if err != nil {
+		return nil, fmt.Errorf("invalid link-match regex: %w", err)
+	}
+	return re, nil
 }
 
-func outputEmails(cmd *cobra.Command, emails []*vaultsandbox.Email) {
+// selectLink returns the Nth (1-based) link, counting only links that match re.
+func selectLink(links []string, index int, re *regexp.Regexp) (string, error) {
+	var candidates []string
+	for _, link := range links {
+		if re == nil || re.MatchString(link) {
+			candidates = append(candidates, link)
+		}
+	}
+
+	if len(candidates) == 0 {
+		if re != nil {
+			return "", fmt.Errorf("no links match %q", re.String())
+		}
+		return "", fmt.Errorf("no links found in email")
+	}
+	if index < 1 || index > len(candidates) {
+		return "", fmt.Errorf("link index %d out of range (1-%d)", index, len(candidates))
+	}
+	return candidates[index-1], nil
+}
+
+// openWaitLink opens the selected link in the browser. When no browser is
+// available the URL is printed instead, since the wait itself succeeded.
+func openWaitLink(email *vaultsandbox.Email, re *regexp.Regexp) error {
+	link, err := selectLink(email.Links, waitForOpen, re)
+	if err != nil {
+		return err
+	}
+	if err := browser.ValidateURL(link); err != nil {
+		return err
+	}
+
+	openErr := fmt.Errorf("no display available")
+	if !isHeadlessFunc() {
+		openErr = openURLInBrowserFunc(link)
+	}
+
+	if openErr != nil {
+		if !waitForQuiet {
+			fmt.Fprintf(os.Stderr, "Could not open browser (%v), open this link manually:\n%s\n", openErr, link)
+		}
+		return nil
+	}
+
+	if !waitForQuiet {
+		fmt.Fprintf(os.Stderr, "Opened %s\n", link)
+	}
+	return nil
+}
+
+// reportWaitResult writes the matched email's details as CI step outputs.
+func reportWaitResult(reporter ci.Reporter, email *vaultsandbox.Email, linkMatch *regexp.Regexp) error {
+	if err := reporter.SetOutput("email_id", email.ID); err != nil {
+		return err
+	}
+	if err := reporter.SetOutput("subject", email.Subject); err != nil {
+		return err
+	}
+
+	index := 1
+	if waitForOpen > 0 {
+		index = waitForOpen
+	}
+	link, err := selectLink(email.Links, index, linkMatch)
+	if err != nil {
+		if waitForExtractLink || waitForOpen > 0 {
+			reporter.Warning(err.Error())
+		}
+		return nil
+	}
+	return reporter.SetOutput("link", link)
+}
+
+// outputEmails prints the matched emails. JSON output always includes the
+// inbox; the human-readable output shows it when several inboxes were watched.
+// With --extract-link, an email without the selected link is an error.
+func outputEmails(cmd *cobra.Command, matches []matchedEmail, linkMatch *regexp.Regexp, showInbox bool) error {
 	if waitForQuiet {
-		return
+		return nil
 	}
 
-	for _, email := range emails {
-		if cliutil.GetOutput(cmd) == "json" {
+	for _, m := range matches {
+		email := m.Email
+		if waitForPrintID {
+			fmt.Println(email.ID)
+		} else if cliutil.GetOutput(cmd) == cliutil.FormatJSON {
 			// JSON output
-			_ = cliutil.OutputJSON(cliutil.EmailFullJSON(email))
+			data := cliutil.EmailFullJSON(email)
+			data["inbox"] = m.Inbox
+			_ = cliutil.OutputJSON(data)
 		} else if waitForExtractLink {
-			// Extract first link
-			if len(email.Links) > 0 {
-				fmt.Println(email.Links[0])
+			// Extract first (or selected) link
+			index := 1
+			if waitForOpen > 0 {
+				index = waitForOpen
+			}
+			link, err := selectLink(email.Links, index, linkMatch)
+			if err != nil {
+				return err
 			}
+			fmt.Println(link)
 		} else {
 			// Human-readable output
+			if showInbox {
+				fmt.Printf("Inbox: %s\n", m.Inbox)
+			}
 			fmt.Printf("Subject: %s\n", email.Subject)
 			fmt.Printf("From: %s\n", email.From)
 			fmt.Printf("Received: %s\n", email.ReceivedAt.Format(time.RFC3339))
@@ -194,5 +933,5 @@ func outputEmails(cmd *cobra.Command, emails []*vaultsandbox.Email) {
 			}
 		}
 	}
+	return nil
 }
-
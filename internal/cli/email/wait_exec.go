@@ -0,0 +1,86 @@
+package email
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"strings"
+
+	"github.com/vaultsandbox/vsb-cli/internal/cliutil"
+)
+
+// execPlaceholder matches the --exec placeholders, and the doubled-brace
+// form that stands for the placeholder text itself
+var execPlaceholder = regexp.MustCompile(`\{\{(id|subject|from|inbox)\}\}|\{(id|subject|from|inbox)\}`)
+
+// expandExecCommand substitutes the matched email into an --exec command.
+// Each value is shell-quoted for goos so it stays a single argument whatever
+// it contains. {{name}} is left as the literal {name}.
+func expandExecCommand(command string, m matchedEmail, goos string) string {
+	values := map[string]string{
+		"id":      m.Email.ID,
+		"subject": m.Email.Subject,
+		"from":    m.Email.From,
+		"inbox":   m.Inbox,
+	}
+	return execPlaceholder.ReplaceAllStringFunc(command, func(s string) string {
+		if strings.HasPrefix(s, "{{") {
+			return s[1 : len(s)-1]
+		}
+		return shellQuote(values[s[1:len(s)-1]], goos)
+	})
+}
+
+// shellQuote quotes s as one word for sh, or for cmd.exe on Windows.
+func shellQuote(s, goos string) string {
+	if goos == "windows" {
+		return `"` + strings.ReplaceAll(s, `"`, `""`) + `"`
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// execEnv returns the environment for an --exec command: vsb's own plus the
+// matched email's fields, for scripts that would rather not parse arguments.
+func execEnv(m matchedEmail) []string {
+	return append(os.Environ(),
+		"VSB_EMAIL_ID="+m.Email.ID,
+		"VSB_EMAIL_SUBJECT="+m.Email.Subject,
+		"VSB_EMAIL_FROM="+m.Email.From,
+		"VSB_EMAIL_INBOX="+m.Inbox,
+	)
+}
+
+// runExec runs the --exec command once per matched email, in order, with
+// its output streamed to stdout and stderr. It stops at the first command
+// that fails and returns an ExitError carrying its exit status.
+func runExec(ctx context.Context, command string, matches []matchedEmail, stdout, stderr io.Writer) error {
+	for _, m := range matches {
+		expanded := expandExecCommand(command, m, runtime.GOOS)
+		var cmd *exec.Cmd
+		if runtime.GOOS == "windows" {
+			cmd = exec.CommandContext(ctx, "cmd", "/C", expanded)
+		} else {
+			cmd = exec.CommandContext(ctx, "sh", "-c", expanded)
+		}
+		cmd.Env = execEnv(m)
+		cmd.Stdout = stdout
+		cmd.Stderr = stderr
+
+		if err := cmd.Run(); err != nil {
+			var exitErr *exec.ExitError
+			if errors.As(err, &exitErr) && exitErr.ExitCode() > 0 {
+				return &cliutil.ExitError{
+					Code: exitErr.ExitCode(),
+					Err:  fmt.Errorf("exec command exited with status %d for email %s", exitErr.ExitCode(), m.Email.ID),
+				}
+			}
+			return fmt.Errorf("exec command failed for email %s: %w", m.Email.ID, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package email
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	vaultsandbox "github.com/vaultsandbox/client-go"
+	"github.com/vaultsandbox/vsb-cli/internal/cliutil"
+)
+
+func execMatch(id, subject string) matchedEmail {
+	return matchedEmail{
+		Inbox: "inbox@vsx.email",
+		Email: &vaultsandbox.Email{ID: id, Subject: subject, From: "Acme <noreply@acme.test>"},
+	}
+}
+
+func TestExpandExecCommand(t *testing.T) {
+	m := execMatch("abc123", "It's $(rm -rf /) time")
+
+	tests := []struct {
+		name, command, goos, want string
+	}{
+		{"id", "process.sh {id}", "linux", "process.sh 'abc123'"},
+		{"quotes hostile values", "echo {subject}", "linux", `echo 'It'\''s $(rm -rf /) time'`},
+		{"all placeholders", "{from} {inbox}", "linux", "'Acme <noreply@acme.test>' 'inbox@vsx.email'"},
+		{"escaped placeholder", "echo {{id}} {id}", "linux", "echo {id} 'abc123'"},
+		{"other braces untouched", "echo ${HOME} {name}", "linux", "echo ${HOME} {name}"},
+		{"windows quoting", `echo {from}`, "windows", `echo "Acme <noreply@acme.test>"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, expandExecCommand(tt.command, m, tt.goos))
+		})
+	}
+
+	t.Run("windows doubles quotes", func(t *testing.T) {
+		assert.Equal(t, `"say ""hi"""`, shellQuote(`say "hi"`, "windows"))
+	})
+}
+
+func TestRunExec(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh syntax")
+	}
+	ctx := context.Background()
+
+	t.Run("runs once per email with output streamed", func(t *testing.T) {
+		var stdout, stderr bytes.Buffer
+		matches := []matchedEmail{execMatch("one", "First"), execMatch("two", "It's second")}
+
+		err := runExec(ctx, `echo {id} {subject}; echo "$VSB_EMAIL_ID" >&2`, matches, &stdout, &stderr)
+		require.NoError(t, err)
+		assert.Equal(t, "one First\ntwo It's second\n", stdout.String())
+		assert.Equal(t, "one\ntwo\n", stderr.String())
+	})
+
+	t.Run("propagates exit status and stops", func(t *testing.T) {
+		var stdout bytes.Buffer
+		matches := []matchedEmail{execMatch("one", ""), execMatch("two", "")}
+
+		err := runExec(ctx, `echo {id}; exit 7`, matches, &stdout, &stdout)
+		var exitErr *cliutil.ExitError
+		require.True(t, errors.As(err, &exitErr))
+		assert.Equal(t, 7, exitErr.Code)
+		assert.EqualError(t, err, "exec command exited with status 7 for email one")
+		assert.Equal(t, "one\n", stdout.String())
+	})
+
+	t.Run("cancelled command is not an exit status", func(t *testing.T) {
+		cancelled, cancel := context.WithCancel(ctx)
+		cancel()
+
+		err := runExec(cancelled, "true", []matchedEmail{execMatch("one", "")}, &bytes.Buffer{}, &bytes.Buffer{})
+		var exitErr *cliutil.ExitError
+		assert.False(t, errors.As(err, &exitErr))
+		assert.ErrorContains(t, err, "exec command failed for email one")
+	})
+}
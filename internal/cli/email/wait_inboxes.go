@@ -0,0 +1,143 @@
+package email
+
+import (
+	"context"
+	"fmt"
+
+	vaultsandbox "github.com/vaultsandbox/client-go"
+)
+
+// matchedEmail is an email that satisfied the wait, with the address of the
+// inbox it arrived in.
+type matchedEmail struct {
+	Inbox string
+	Email *vaultsandbox.Email
+}
+
+// buildEmailMatcher returns a predicate applying every filter flag: the
+// positive filters, the --not-* exclusions and the receive cutoff. Waits on
+// one inbox pass it to the SDK (see buildWaitOptions); waits that span
+// several inboxes apply it themselves.
+func buildEmailMatcher() (func(*vaultsandbox.Email) bool, error) {
+	matches, err := waitFilter().compile()
+	if err != nil {
+		return nil, err
+	}
+	excluded, err := buildExclusionFilter()
+	if err != nil {
+		return nil, err
+	}
+
+	return func(e *vaultsandbox.Email) bool {
+		if !matches(e) {
+			return false
+		}
+		if excluded != nil && excluded(e) {
+			return false
+		}
+		if !waitReceivedAfter.IsZero() && e.ReceivedAt.Before(waitReceivedAfter) {
+			return false
+		}
+		return true
+	}, nil
+}
+
+// watchInboxEmails subscribes to every inbox and forwards incoming emails
+// until ctx is done.
+func watchInboxEmails(ctx context.Context, client *vaultsandbox.Client, inboxes []*vaultsandbox.Inbox) <-chan matchedEmail {
+	events := client.WatchInboxes(ctx, inboxes...)
+	out := make(chan matchedEmail)
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event := <-events:
+				if event == nil {
+					continue
+				}
+				select {
+				case out <- matchedEmail{Inbox: event.Inbox.EmailAddress(), Email: event.Email}:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return out
+}
+
+// existingInboxEmails fetches the emails already in each inbox, so an email
+// delivered before the watch started still counts.
+func existingInboxEmails(ctx context.Context, inboxes []*vaultsandbox.Inbox) ([]matchedEmail, error) {
+	var existing []matchedEmail
+	for _, inbox := range inboxes {
+		emails, err := inbox.GetEmails(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get emails for %s: %w", inbox.EmailAddress(), err)
+		}
+		for _, email := range emails {
+			existing = append(existing, matchedEmail{Inbox: inbox.EmailAddress(), Email: email})
+		}
+	}
+	return existing, nil
+}
+
+// collectMatches returns matching emails from existing and then incoming, in
+// arrival order, once count have matched across all inboxes. With perInbox,
+// it waits for count matches in each of inboxes instead. onMatch, if not
+// nil, is called with each match as it is collected.
+func collectMatches(ctx context.Context, existing []matchedEmail, incoming <-chan matchedEmail,
+	match func(*vaultsandbox.Email) bool, inboxes []string, count int, perInbox bool,
+	onMatch func(matchedEmail)) ([]matchedEmail, error) {
+	var matches []matchedEmail
+	seen := make(map[string]bool)
+	perInboxCount := make(map[string]int)
+
+	done := func() bool {
+		if !perInbox {
+			return len(matches) >= count
+		}
+		for _, inbox := range inboxes {
+			if perInboxCount[inbox] < count {
+				return false
+			}
+		}
+		return true
+	}
+
+	add := func(m matchedEmail) {
+		key := m.Inbox + "/" + m.Email.ID
+		if seen[key] || !match(m.Email) {
+			return
+		}
+		seen[key] = true
+		if perInbox && perInboxCount[m.Inbox] >= count {
+			return
+		}
+		perInboxCount[m.Inbox]++
+		matches = append(matches, m)
+		if onMatch != nil {
+			onMatch(m)
+		}
+	}
+
+	for _, m := range existing {
+		add(m)
+		if done() {
+			return matches, nil
+		}
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case m := <-incoming:
+			add(m)
+			if done() {
+				return matches, nil
+			}
+		}
+	}
+}
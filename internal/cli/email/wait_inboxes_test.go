@@ -0,0 +1,215 @@
+package email
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	vaultsandbox "github.com/vaultsandbox/client-go"
+)
+
+func TestBuildEmailMatcher(t *testing.T) {
+	defer func() {
+		waitForSubject = ""
+		waitForSubjectRegex = ""
+		waitForFrom = ""
+		waitForFromRegex = ""
+		waitForBodyRegex = ""
+		waitForNotFrom = ""
+	}()
+
+	email := &vaultsandbox.Email{Subject: "Verify your account", From: "noreply@example.com", Text: "Your OTP is: 123456"}
+
+	t.Run("no filters match everything", func(t *testing.T) {
+		match, err := buildEmailMatcher()
+		require.NoError(t, err)
+		assert.True(t, match(email))
+	})
+
+	t.Run("filters combine with AND", func(t *testing.T) {
+		waitForSubjectRegex = "^Verify"
+		waitForFrom = "noreply@example.com"
+		match, err := buildEmailMatcher()
+		require.NoError(t, err)
+		assert.True(t, match(email))
+
+		waitForFrom = "other@example.com"
+		match, err = buildEmailMatcher()
+		require.NoError(t, err)
+		assert.False(t, match(email))
+	})
+
+	t.Run("body regex", func(t *testing.T) {
+		waitForFrom = ""
+		waitForBodyRegex = `OTP is: \d{6}`
+		match, err := buildEmailMatcher()
+		require.NoError(t, err)
+		assert.True(t, match(email))
+
+		waitForBodyRegex = `OTP is: [a-z]+`
+		match, err = buildEmailMatcher()
+		require.NoError(t, err)
+		assert.False(t, match(email))
+		waitForBodyRegex = ""
+	})
+
+	t.Run("positive and negative filters compose", func(t *testing.T) {
+		waitForSubjectRegex = "^Verify"
+		waitForNotFrom = "noreply@example.com"
+		match, err := buildEmailMatcher()
+		require.NoError(t, err)
+		assert.False(t, match(email))
+
+		waitForNotFrom = "other@example.com"
+		match, err = buildEmailMatcher()
+		require.NoError(t, err)
+		assert.True(t, match(email))
+		waitForSubjectRegex = ""
+		waitForNotFrom = ""
+	})
+
+	t.Run("emails received before the cutoff are skipped", func(t *testing.T) {
+		defer func() { waitReceivedAfter = time.Time{} }()
+		received := time.Date(2026, 1, 2, 15, 0, 0, 0, time.UTC)
+		old := &vaultsandbox.Email{Subject: "Welcome", ReceivedAt: received}
+
+		waitReceivedAfter = received.Add(time.Second)
+		match, err := buildEmailMatcher()
+		require.NoError(t, err)
+		assert.False(t, match(old))
+
+		waitReceivedAfter = received
+		match, err = buildEmailMatcher()
+		require.NoError(t, err)
+		assert.True(t, match(old))
+	})
+
+	t.Run("invalid regex", func(t *testing.T) {
+		waitForFromRegex = "[invalid"
+		_, err := buildEmailMatcher()
+		assert.ErrorContains(t, err, "invalid from regex")
+	})
+}
+
+func TestCollectMatches(t *testing.T) {
+	inboxes := []string{"first@example.com", "second@example.com"}
+	matchAll := func(*vaultsandbox.Email) bool { return true }
+	newEmail := func(inbox, id string) matchedEmail {
+		return matchedEmail{Inbox: inbox, Email: &vaultsandbox.Email{ID: id, Subject: id}}
+	}
+
+	t.Run("returns email landing in second inbox", func(t *testing.T) {
+		incoming := make(chan matchedEmail, 1)
+		incoming <- newEmail("second@example.com", "e1")
+
+		matches, err := collectMatches(context.Background(), nil, incoming, matchAll, inboxes, 1, false, nil)
+		require.NoError(t, err)
+		require.Len(t, matches, 1)
+		assert.Equal(t, "second@example.com", matches[0].Inbox)
+		assert.Equal(t, "e1", matches[0].Email.ID)
+	})
+
+	t.Run("count spans inboxes", func(t *testing.T) {
+		existing := []matchedEmail{newEmail("first@example.com", "e1")}
+		incoming := make(chan matchedEmail, 1)
+		incoming <- newEmail("second@example.com", "e2")
+
+		matches, err := collectMatches(context.Background(), existing, incoming, matchAll, inboxes, 2, false, nil)
+		require.NoError(t, err)
+		assert.Len(t, matches, 2)
+	})
+
+	t.Run("per-inbox waits for each inbox", func(t *testing.T) {
+		existing := []matchedEmail{
+			newEmail("first@example.com", "e1"),
+			newEmail("first@example.com", "e2"),
+		}
+		incoming := make(chan matchedEmail, 1)
+		incoming <- newEmail("second@example.com", "e3")
+
+		matches, err := collectMatches(context.Background(), existing, incoming, matchAll, inboxes, 1, true, nil)
+		require.NoError(t, err)
+		require.Len(t, matches, 2)
+		assert.Equal(t, "first@example.com", matches[0].Inbox)
+		assert.Equal(t, "second@example.com", matches[1].Inbox)
+	})
+
+	t.Run("skips non-matching and duplicate emails", func(t *testing.T) {
+		existing := []matchedEmail{newEmail("first@example.com", "skip")}
+		incoming := make(chan matchedEmail, 3)
+		incoming <- newEmail("first@example.com", "skip")
+		incoming <- newEmail("second@example.com", "e1")
+		match := func(e *vaultsandbox.Email) bool { return e.Subject != "skip" }
+
+		matches, err := collectMatches(context.Background(), existing, incoming, match, inboxes, 1, false, nil)
+		require.NoError(t, err)
+		require.Len(t, matches, 1)
+		assert.Equal(t, "e1", matches[0].Email.ID)
+	})
+
+	t.Run("times out", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		_, err := collectMatches(ctx, nil, make(chan matchedEmail), matchAll, inboxes, 1, false, nil)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+}
+
+func TestOutputEmailsInbox(t *testing.T) {
+	matches := []matchedEmail{{
+		Inbox: "second@example.com",
+		Email: &vaultsandbox.Email{ID: "e1", Subject: "Hello", From: "a@b.com"},
+	}}
+
+	cmd := &cobra.Command{}
+	cmd.Flags().StringP("output", "o", "", "Output format")
+
+	t.Run("pretty shows inbox when several are watched", func(t *testing.T) {
+		out := captureURLStdout(t, func() { require.NoError(t, outputEmails(cmd, matches, nil, true)) })
+		assert.Contains(t, out, "Inbox: second@example.com\nSubject: Hello\n")
+
+		out = captureURLStdout(t, func() { require.NoError(t, outputEmails(cmd, matches, nil, false)) })
+		assert.NotContains(t, out, "Inbox:")
+	})
+
+	t.Run("json includes inbox", func(t *testing.T) {
+		require.NoError(t, cmd.Flags().Set("output", "json"))
+		out := captureURLStdout(t, func() { require.NoError(t, outputEmails(cmd, matches, nil, false)) })
+
+		var data map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(out), &data))
+		assert.Equal(t, "second@example.com", data["inbox"])
+	})
+}
+
+func TestResolveWaitInboxes(t *testing.T) {
+	t.Run("specific inboxes", func(t *testing.T) {
+		flags, all, err := resolveWaitInboxes([]string{"a", "b"}, false)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"a", "b"}, flags)
+		assert.False(t, all)
+	})
+
+	t.Run("all keyword", func(t *testing.T) {
+		flags, all, err := resolveWaitInboxes([]string{"ALL"}, false)
+		require.NoError(t, err)
+		assert.Empty(t, flags)
+		assert.True(t, all)
+	})
+
+	t.Run("all-inboxes flag", func(t *testing.T) {
+		_, all, err := resolveWaitInboxes(nil, true)
+		require.NoError(t, err)
+		assert.True(t, all)
+	})
+
+	t.Run("all combined with inbox fails", func(t *testing.T) {
+		_, _, err := resolveWaitInboxes([]string{"a", "all"}, false)
+		assert.EqualError(t, err, "--inbox all cannot be combined with other inboxes")
+	})
+}
@@ -0,0 +1,202 @@
+package email
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/vaultsandbox/vsb-cli/internal/cliutil"
+	"github.com/vaultsandbox/vsb-cli/internal/config"
+)
+
+// postAttempts is how many times a webhook POST is tried before giving up
+const postAttempts = 3
+
+// postBackoff is the delay before the first retry; it doubles after each
+// attempt (overridden in tests)
+var postBackoff = 500 * time.Millisecond
+
+// emailPoster forwards matched emails as JSON to the --post or --webhook URL
+type emailPoster struct {
+	url                string
+	method             string // POST if empty
+	headers            http.Header
+	client             *http.Client
+	includeAttachments bool
+}
+
+// newEmailPoster validates the --post flags. It returns nil if --post is
+// unset.
+func newEmailPoster() (*emailPoster, error) {
+	if waitForPost == "" {
+		return nil, nil
+	}
+	return newPoster("post", waitForPost, http.MethodPost, waitForPostHeaders, waitForPostTimeout)
+}
+
+// newWebhookPoster validates the --webhook flags. It returns nil if
+// --webhook is unset.
+func newWebhookPoster() (*emailPoster, error) {
+	if waitForWebhook == "" {
+		return nil, nil
+	}
+	method := strings.ToUpper(waitForWebhookMethod)
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch:
+	default:
+		return nil, fmt.Errorf("invalid --webhook-method: %s (use POST, PUT or PATCH)", waitForWebhookMethod)
+	}
+	return newPoster("webhook", waitForWebhook, method, waitForWebhookHeaders, waitForWebhookTimeout)
+}
+
+// newPoster builds a poster from the --<flag>, --<flag>-header and
+// --<flag>-timeout values.
+func newPoster(flag, rawURL, method string, headerValues []string, timeoutValue string) (*emailPoster, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("invalid --%s URL: %s (must be http or https)", flag, rawURL)
+	}
+
+	headers, err := parsePostHeaders(flag+"-header", headerValues)
+	if err != nil {
+		return nil, err
+	}
+
+	timeout, err := time.ParseDuration(timeoutValue)
+	if err != nil || timeout <= 0 {
+		return nil, fmt.Errorf("invalid --%s-timeout: %s", flag, timeoutValue)
+	}
+
+	return &emailPoster{
+		url:     rawURL,
+		method:  method,
+		headers: headers,
+		// Uses the configured proxy and the system CAs
+		client:             config.NewHTTPClient(timeout),
+		includeAttachments: waitForIncludeAttachments,
+	}, nil
+}
+
+// parsePostHeaders parses repeated "Name: value" header flags; flag names
+// the flag in errors.
+func parsePostHeaders(flag string, values []string) (http.Header, error) {
+	headers := make(http.Header)
+	for _, v := range values {
+		name, value, ok := strings.Cut(v, ":")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" || strings.ContainsAny(name, " \t") {
+			return nil, fmt.Errorf("invalid --%s %q (use 'Name: value')", flag, v)
+		}
+		headers.Add(name, strings.TrimSpace(value))
+	}
+	return headers, nil
+}
+
+// postPayload is the JSON body sent for one email.
+func postPayload(m matchedEmail, includeAttachments bool) map[string]interface{} {
+	data := cliutil.EmailJSON(m.Email, cliutil.EmailJSONOptions{
+		IncludeTo:                true,
+		IncludeBody:              true,
+		IncludeLinks:             true,
+		IncludeAttachments:       true,
+		IncludeAttachmentContent: includeAttachments,
+	})
+	data["inbox"] = m.Inbox
+	return data
+}
+
+// PostAll posts every matched email, attempting all of them even if some
+// fail. Progress goes to stderr unless --quiet.
+func (p *emailPoster) PostAll(ctx context.Context, matches []matchedEmail) error {
+	var errs []error
+	for _, m := range matches {
+		if err := p.Post(ctx, m); err != nil {
+			errs = append(errs, fmt.Errorf("failed to post email %s: %w", m.Email.ID, err))
+			continue
+		}
+		if !waitForQuiet {
+			fmt.Fprintf(os.Stderr, "Sent email %s to %s\n", m.Email.ID, p.url)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// notifyWebhook sends the matched emails to the --webhook URL. The email was
+// received whatever happens to the webhook, so a failure is only a warning
+// on w unless --webhook-fail-on-error is set.
+func notifyWebhook(ctx context.Context, webhook *emailPoster, matches []matchedEmail, w io.Writer) error {
+	err := webhook.PostAll(ctx, matches)
+	if err == nil {
+		return nil
+	}
+	if waitForWebhookFailOnError {
+		return err
+	}
+	if !waitForQuiet {
+		fmt.Fprintf(w, "Warning: %v\n", err)
+	}
+	return nil
+}
+
+// Post sends one email, retrying network errors, 429 and 5xx responses with
+// exponential backoff.
+func (p *emailPoster) Post(ctx context.Context, m matchedEmail) error {
+	body, err := json.Marshal(postPayload(m, p.includeAttachments))
+	if err != nil {
+		return err
+	}
+
+	backoff := postBackoff
+	for attempt := 1; ; attempt++ {
+		retry, err := p.send(ctx, body)
+		if err == nil {
+			return nil
+		}
+		if !retry || attempt == postAttempts {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// send makes a single request and reports whether a failure is worth
+// retrying.
+func (p *emailPoster) send(ctx context.Context, body []byte) (retry bool, err error) {
+	method := p.method
+	if method == "" {
+		method = http.MethodPost
+	}
+	req, err := http.NewRequestWithContext(ctx, method, p.url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header = p.headers.Clone()
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return ctx.Err() == nil, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode >= 200 && resp.StatusCode <= 299 {
+		return false, nil
+	}
+	transient := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	return transient, fmt.Errorf("%s returned %s", p.url, resp.Status)
+}
@@ -0,0 +1,336 @@
+package email
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	vaultsandbox "github.com/vaultsandbox/client-go"
+)
+
+func resetPostFlags() {
+	waitForPost = ""
+	waitForPostHeaders = nil
+	waitForPostTimeout = "10s"
+	waitForIncludeAttachments = false
+	waitForPostBestEffort = false
+	waitForQuiet = false
+	waitForWebhook = ""
+	waitForWebhookMethod = http.MethodPost
+	waitForWebhookHeaders = nil
+	waitForWebhookTimeout = "10s"
+	waitForWebhookFailOnError = false
+}
+
+func TestNewEmailPoster(t *testing.T) {
+	t.Cleanup(resetPostFlags)
+
+	t.Run("unset", func(t *testing.T) {
+		resetPostFlags()
+		p, err := newEmailPoster()
+		require.NoError(t, err)
+		assert.Nil(t, p)
+	})
+
+	t.Run("invalid URL", func(t *testing.T) {
+		resetPostFlags()
+		waitForPost = "ftp://example.com/ingest"
+		_, err := newEmailPoster()
+		assert.ErrorContains(t, err, "invalid --post URL")
+	})
+
+	t.Run("invalid timeout", func(t *testing.T) {
+		resetPostFlags()
+		waitForPost = "https://example.com/ingest"
+		waitForPostTimeout = "0s"
+		_, err := newEmailPoster()
+		assert.ErrorContains(t, err, "invalid --post-timeout")
+	})
+
+	t.Run("invalid header", func(t *testing.T) {
+		resetPostFlags()
+		waitForPost = "https://example.com/ingest"
+		waitForPostHeaders = []string{"no-colon"}
+		_, err := newEmailPoster()
+		assert.ErrorContains(t, err, "invalid --post-header")
+	})
+}
+
+func TestParsePostHeaders(t *testing.T) {
+	headers, err := parsePostHeaders("post-header", []string{"X-Token: abc", "X-Tag:a:b", "X-Tag: c"})
+	require.NoError(t, err)
+	assert.Equal(t, "abc", headers.Get("X-Token"))
+	assert.Equal(t, []string{"a:b", "c"}, headers.Values("X-Tag"))
+
+	_, err = parsePostHeaders("post-header", []string{"Bad Name: x"})
+	assert.Error(t, err)
+	_, err = parsePostHeaders("post-header", []string{": x"})
+	assert.Error(t, err)
+}
+
+func TestPostPayload(t *testing.T) {
+	m := matchedEmail{
+		Inbox: "inbox@vsx.email",
+		Email: &vaultsandbox.Email{
+			ID:      "e1",
+			From:    "a@example.com",
+			To:      []string{"inbox@vsx.email"},
+			Subject: "Hi",
+			Text:    "Hello",
+			Links:   []string{"https://example.com"},
+			Attachments: []vaultsandbox.Attachment{
+				{Filename: "a.txt", ContentType: "text/plain", Size: 5, Content: []byte("hello")},
+			},
+		},
+	}
+
+	data := postPayload(m, false)
+	assert.Equal(t, "e1", data["id"])
+	assert.Equal(t, "inbox@vsx.email", data["inbox"])
+	assert.Equal(t, "Hello", data["text"])
+	attachments := data["attachments"].([]map[string]interface{})
+	assert.Nil(t, attachments[0]["content"])
+
+	data = postPayload(m, true)
+	attachments = data["attachments"].([]map[string]interface{})
+	assert.Equal(t, "aGVsbG8=", attachments[0]["content"])
+}
+
+func TestEmailPosterPost(t *testing.T) {
+	oldBackoff := postBackoff
+	postBackoff = time.Millisecond
+	t.Cleanup(func() { postBackoff = oldBackoff })
+
+	m := matchedEmail{Inbox: "inbox@vsx.email", Email: &vaultsandbox.Email{ID: "e1", Subject: "Hi"}}
+
+	newPoster := func(url string) *emailPoster {
+		headers, _ := parsePostHeaders("post-header", []string{"X-Token: abc"})
+		return &emailPoster{url: url, headers: headers, client: &http.Client{Timeout: time.Second}}
+	}
+
+	t.Run("sends JSON with headers", func(t *testing.T) {
+		var got map[string]interface{}
+		var token, contentType string
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			token = r.Header.Get("X-Token")
+			contentType = r.Header.Get("Content-Type")
+			body, _ := io.ReadAll(r.Body)
+			_ = json.Unmarshal(body, &got)
+			w.WriteHeader(http.StatusAccepted)
+		}))
+		defer srv.Close()
+
+		require.NoError(t, newPoster(srv.URL).Post(context.Background(), m))
+		assert.Equal(t, "abc", token)
+		assert.Equal(t, "application/json", contentType)
+		assert.Equal(t, "e1", got["id"])
+	})
+
+	t.Run("retries transient failures", func(t *testing.T) {
+		var calls atomic.Int32
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if calls.Add(1) < postAttempts {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer srv.Close()
+
+		require.NoError(t, newPoster(srv.URL).Post(context.Background(), m))
+		assert.Equal(t, int32(postAttempts), calls.Load())
+	})
+
+	t.Run("gives up after max attempts", func(t *testing.T) {
+		var calls atomic.Int32
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls.Add(1)
+			w.WriteHeader(http.StatusBadGateway)
+		}))
+		defer srv.Close()
+
+		err := newPoster(srv.URL).Post(context.Background(), m)
+		assert.ErrorContains(t, err, "502")
+		assert.Equal(t, int32(postAttempts), calls.Load())
+	})
+
+	t.Run("client errors are not retried", func(t *testing.T) {
+		var calls atomic.Int32
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls.Add(1)
+			w.WriteHeader(http.StatusUnauthorized)
+		}))
+		defer srv.Close()
+
+		err := newPoster(srv.URL).Post(context.Background(), m)
+		assert.ErrorContains(t, err, "401")
+		assert.Equal(t, int32(1), calls.Load())
+	})
+
+	t.Run("PostAll attempts every email", func(t *testing.T) {
+		resetPostFlags()
+		waitForQuiet = true
+		defer resetPostFlags()
+
+		var calls atomic.Int32
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls.Add(1)
+			w.WriteHeader(http.StatusForbidden)
+		}))
+		defer srv.Close()
+
+		matches := []matchedEmail{m, {Inbox: "inbox@vsx.email", Email: &vaultsandbox.Email{ID: "e2"}}}
+		err := newPoster(srv.URL).PostAll(context.Background(), matches)
+		assert.ErrorContains(t, err, "failed to post email e1")
+		assert.ErrorContains(t, err, "failed to post email e2")
+		assert.Equal(t, int32(2), calls.Load())
+	})
+}
+
+func TestNewWebhookPoster(t *testing.T) {
+	t.Cleanup(resetPostFlags)
+
+	t.Run("unset", func(t *testing.T) {
+		resetPostFlags()
+		p, err := newWebhookPoster()
+		require.NoError(t, err)
+		assert.Nil(t, p)
+	})
+
+	t.Run("method is normalized", func(t *testing.T) {
+		resetPostFlags()
+		waitForWebhook = "https://hooks.example.com/notify"
+		waitForWebhookMethod = "put"
+		p, err := newWebhookPoster()
+		require.NoError(t, err)
+		assert.Equal(t, http.MethodPut, p.method)
+	})
+
+	t.Run("invalid method", func(t *testing.T) {
+		resetPostFlags()
+		waitForWebhook = "https://hooks.example.com/notify"
+		waitForWebhookMethod = "GET"
+		_, err := newWebhookPoster()
+		assert.ErrorContains(t, err, "invalid --webhook-method")
+	})
+
+	t.Run("errors name the webhook flags", func(t *testing.T) {
+		resetPostFlags()
+		waitForWebhook = "hooks.example.com"
+		_, err := newWebhookPoster()
+		assert.ErrorContains(t, err, "invalid --webhook URL")
+
+		waitForWebhook = "https://hooks.example.com/notify"
+		waitForWebhookHeaders = []string{"no-colon"}
+		_, err = newWebhookPoster()
+		assert.ErrorContains(t, err, "invalid --webhook-header")
+
+		waitForWebhookHeaders = nil
+		waitForWebhookTimeout = "soon"
+		_, err = newWebhookPoster()
+		assert.ErrorContains(t, err, "invalid --webhook-timeout")
+	})
+}
+
+func TestNotifyWebhook(t *testing.T) {
+	oldBackoff := postBackoff
+	postBackoff = time.Millisecond
+	t.Cleanup(func() {
+		postBackoff = oldBackoff
+		resetPostFlags()
+	})
+
+	m := matchedEmail{Inbox: "inbox@vsx.email", Email: &vaultsandbox.Email{ID: "e1", Subject: "Deploy complete"}}
+
+	t.Run("sends email with method and headers", func(t *testing.T) {
+		resetPostFlags()
+		var got map[string]interface{}
+		var method, apiKey, contentType string
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			method = r.Method
+			apiKey = r.Header.Get("X-API-Key")
+			contentType = r.Header.Get("Content-Type")
+			body, _ := io.ReadAll(r.Body)
+			_ = json.Unmarshal(body, &got)
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		defer srv.Close()
+
+		waitForWebhook = srv.URL
+		waitForWebhookMethod = "PATCH"
+		waitForWebhookHeaders = []string{"X-API-Key: secret"}
+		webhook, err := newWebhookPoster()
+		require.NoError(t, err)
+
+		var stderr bytes.Buffer
+		require.NoError(t, notifyWebhook(context.Background(), webhook, []matchedEmail{m}, &stderr))
+		assert.Equal(t, http.MethodPatch, method)
+		assert.Equal(t, "secret", apiKey)
+		assert.Equal(t, "application/json", contentType)
+		assert.Equal(t, "e1", got["id"])
+		assert.Equal(t, "Deploy complete", got["subject"])
+		assert.Equal(t, "inbox@vsx.email", got["inbox"])
+	})
+
+	t.Run("goes through the configured proxy", func(t *testing.T) {
+		resetPostFlags()
+		var proxied string
+		proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			proxied = r.Method + " " + r.URL.String()
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		defer proxy.Close()
+		t.Setenv("VSB_PROXY", proxy.URL)
+		t.Setenv("VSB_NO_PROXY", "")
+
+		waitForWebhook = "http://hooks.example.test/deploy"
+		webhook, err := newWebhookPoster()
+		require.NoError(t, err)
+
+		var stderr bytes.Buffer
+		require.NoError(t, notifyWebhook(context.Background(), webhook, []matchedEmail{m}, &stderr))
+		assert.Equal(t, "POST http://hooks.example.test/deploy", proxied)
+	})
+
+	failing := func() *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+	}
+
+	t.Run("failure only warns by default", func(t *testing.T) {
+		resetPostFlags()
+		srv := failing()
+		defer srv.Close()
+		waitForWebhook = srv.URL
+		webhook, err := newWebhookPoster()
+		require.NoError(t, err)
+
+		var stderr bytes.Buffer
+		require.NoError(t, notifyWebhook(context.Background(), webhook, []matchedEmail{m}, &stderr))
+		assert.Contains(t, stderr.String(), "Warning: failed to post email e1")
+	})
+
+	t.Run("failure is fatal with fail-on-error", func(t *testing.T) {
+		resetPostFlags()
+		srv := failing()
+		defer srv.Close()
+		waitForWebhook = srv.URL
+		waitForWebhookFailOnError = true
+		webhook, err := newWebhookPoster()
+		require.NoError(t, err)
+
+		var stderr bytes.Buffer
+		err = notifyWebhook(context.Background(), webhook, []matchedEmail{m}, &stderr)
+		assert.ErrorContains(t, err, "500")
+		assert.NotContains(t, stderr.String(), "Warning")
+	})
+}
@@ -0,0 +1,131 @@
+package email
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	vaultsandbox "github.com/vaultsandbox/client-go"
+	"github.com/vaultsandbox/vsb-cli/internal/cliutil"
+	mailfile "github.com/vaultsandbox/vsb-cli/internal/email"
+	"github.com/vaultsandbox/vsb-cli/internal/files"
+)
+
+// saveTimeLayout is the receive time prefix of saved file names, chosen so
+// the files sort in received order
+const saveTimeLayout = "20060102T150405Z"
+
+// emailSaver writes each matched email to a directory (--save-to-dir)
+type emailSaver struct {
+	// ctx is the command's context: saving is not bound by --timeout,
+	// which only covers waiting for the email
+	ctx    context.Context
+	dir    string
+	format string // eml, json or text
+	err    error  // first failed save
+}
+
+// newEmailSaver validates the --save-to-dir and --save-format flags and
+// creates the directory, so a bad path fails before waiting. It returns nil
+// if --save-to-dir is unset.
+func newEmailSaver(ctx context.Context) (*emailSaver, error) {
+	if waitForSaveToDir == "" {
+		return nil, nil
+	}
+	switch waitForSaveFormat {
+	case "eml", "json", "text":
+	default:
+		return nil, fmt.Errorf("invalid --save-format: %s (use eml, json or text)", waitForSaveFormat)
+	}
+	if err := os.MkdirAll(waitForSaveToDir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create directory: %w", err)
+	}
+	return &emailSaver{ctx: ctx, dir: waitForSaveToDir, format: waitForSaveFormat}, nil
+}
+
+// saveFileName returns "<received time>-<id>.<ext>" for an email, with the
+// time in UTC
+func saveFileName(email *vaultsandbox.Email, format string) string {
+	id := cliutil.SanitizeFilename(email.ID)
+	if id == "" {
+		id = "email"
+	}
+	ext := format
+	if format == "text" {
+		ext = "txt"
+	}
+	return email.ReceivedAt.UTC().Format(saveTimeLayout) + "-" + id + "." + ext
+}
+
+// Save saves one match as soon as it is collected, fetching the raw source
+// from inbox for EML files, and reports the path to w. A failure is
+// remembered for Err rather than returned, so the wait goes on and the
+// other matches are still saved and printed.
+func (s *emailSaver) Save(inbox *vaultsandbox.Inbox, m matchedEmail, w io.Writer) {
+	if s == nil {
+		return
+	}
+	data, err := s.content(inbox, m.Email)
+	if err != nil {
+		s.fail(fmt.Errorf("failed to save email %s: %w", m.Email.ID, err))
+		return
+	}
+	path, err := files.SavePrivateFile(s.dir, saveFileName(m.Email, s.format), data)
+	if err != nil {
+		s.fail(err)
+		return
+	}
+	fmt.Fprintf(w, "Saved %s\n", path)
+}
+
+// Err returns the first save failure, if any
+func (s *emailSaver) Err() error {
+	if s == nil {
+		return nil
+	}
+	return s.err
+}
+
+func (s *emailSaver) fail(err error) {
+	if s.err == nil {
+		s.err = err
+	}
+}
+
+func (s *emailSaver) content(inbox *vaultsandbox.Inbox, email *vaultsandbox.Email) ([]byte, error) {
+	switch s.format {
+	case "json":
+		data, err := json.MarshalIndent(cliutil.EmailFullJSON(email), "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		return append(data, '\n'), nil
+	case "text":
+		return []byte(emailText(email)), nil
+	}
+	if inbox == nil {
+		return nil, fmt.Errorf("inbox not found")
+	}
+	raw, err := getRawEmailFunc(s.ctx, inbox, email.ID)
+	if err != nil {
+		return nil, err
+	}
+	return mailfile.EML(raw), nil
+}
+
+// emailText renders an email as a few headers and its plain text body
+func emailText(email *vaultsandbox.Email) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "From: %s\n", email.From)
+	fmt.Fprintf(&sb, "To: %s\n", strings.Join(email.To, ", "))
+	fmt.Fprintf(&sb, "Subject: %s\n", email.Subject)
+	fmt.Fprintf(&sb, "Date: %s\n\n", email.ReceivedAt.Format(cliutil.TimeFormatFull))
+	sb.WriteString(email.Text)
+	if !strings.HasSuffix(email.Text, "\n") {
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
@@ -0,0 +1,174 @@
+package email
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	vaultsandbox "github.com/vaultsandbox/client-go"
+)
+
+func resetSaveFlags() {
+	waitForSaveToDir = ""
+	waitForSaveFormat = "eml"
+}
+
+func TestSaveFileName(t *testing.T) {
+	received := time.Date(2026, 3, 4, 5, 6, 7, 0, time.FixedZone("CET", 3600))
+
+	tests := []struct {
+		name   string
+		id     string
+		format string
+		want   string
+	}{
+		{"eml in UTC", "abc123", "eml", "20260304T040607Z-abc123.eml"},
+		{"json", "abc123", "json", "20260304T040607Z-abc123.json"},
+		{"text uses txt", "abc123", "text", "20260304T040607Z-abc123.txt"},
+		{"unsafe id characters dropped", "../a/b c", "eml", "20260304T040607Z-__abc.eml"},
+		{"empty id", "", "eml", "20260304T040607Z-email.eml"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			email := &vaultsandbox.Email{ID: tt.id, ReceivedAt: received}
+			assert.Equal(t, tt.want, saveFileName(email, tt.format))
+		})
+	}
+}
+
+func TestNewEmailSaver(t *testing.T) {
+	t.Cleanup(resetSaveFlags)
+
+	t.Run("unset", func(t *testing.T) {
+		resetSaveFlags()
+		s, err := newEmailSaver(context.Background())
+		require.NoError(t, err)
+		assert.Nil(t, s)
+	})
+
+	t.Run("invalid format", func(t *testing.T) {
+		resetSaveFlags()
+		waitForSaveToDir = t.TempDir()
+		waitForSaveFormat = "pdf"
+		_, err := newEmailSaver(context.Background())
+		assert.ErrorContains(t, err, "invalid --save-format: pdf")
+	})
+
+	t.Run("creates directory", func(t *testing.T) {
+		resetSaveFlags()
+		waitForSaveToDir = filepath.Join(t.TempDir(), "a", "b")
+		s, err := newEmailSaver(context.Background())
+		require.NoError(t, err)
+		require.NotNil(t, s)
+		assert.DirExists(t, waitForSaveToDir)
+	})
+}
+
+func TestEmailSaverSave(t *testing.T) {
+	received := time.Date(2026, 3, 4, 5, 6, 7, 0, time.UTC)
+	matches := []matchedEmail{
+		{Inbox: "a@test.com", Email: &vaultsandbox.Email{ID: "one", From: "x@test.com", To: []string{"a@test.com"}, Subject: "First", Text: "hello", ReceivedAt: received}},
+		{Inbox: "a@test.com", Email: &vaultsandbox.Email{ID: "two", Subject: "Second", ReceivedAt: received.Add(time.Second)}},
+	}
+	inbox := &vaultsandbox.Inbox{}
+
+	t.Run("eml", func(t *testing.T) {
+		orig := getRawEmailFunc
+		t.Cleanup(func() { getRawEmailFunc = orig })
+		getRawEmailFunc = func(ctx context.Context, inbox *vaultsandbox.Inbox, id string) (string, error) {
+			return "Subject: " + id + "\n\nbody", nil
+		}
+
+		dir := t.TempDir()
+		s := &emailSaver{ctx: context.Background(), dir: dir, format: "eml"}
+		var log bytes.Buffer
+		for _, m := range matches {
+			s.Save(inbox, m, &log)
+		}
+		require.NoError(t, s.Err())
+
+		data, err := os.ReadFile(filepath.Join(dir, "20260304T050607Z-one.eml"))
+		require.NoError(t, err)
+		assert.Equal(t, "Subject: one\r\n\r\nbody\r\n", string(data))
+		assert.FileExists(t, filepath.Join(dir, "20260304T050608Z-two.eml"))
+		assert.Contains(t, log.String(), "Saved "+filepath.Join(dir, "20260304T050607Z-one.eml"))
+	})
+
+	t.Run("json", func(t *testing.T) {
+		dir := t.TempDir()
+		s := &emailSaver{ctx: context.Background(), dir: dir, format: "json"}
+		s.Save(inbox, matches[0], &bytes.Buffer{})
+		require.NoError(t, s.Err())
+
+		data, err := os.ReadFile(filepath.Join(dir, "20260304T050607Z-one.json"))
+		require.NoError(t, err)
+		var got map[string]interface{}
+		require.NoError(t, json.Unmarshal(data, &got))
+		assert.Equal(t, "First", got["subject"])
+	})
+
+	t.Run("text", func(t *testing.T) {
+		dir := t.TempDir()
+		s := &emailSaver{ctx: context.Background(), dir: dir, format: "text"}
+		s.Save(inbox, matches[0], &bytes.Buffer{})
+		require.NoError(t, s.Err())
+
+		data, err := os.ReadFile(filepath.Join(dir, "20260304T050607Z-one.txt"))
+		require.NoError(t, err)
+		assert.Contains(t, string(data), "From: x@test.com\nTo: a@test.com\nSubject: First\n")
+		assert.Contains(t, string(data), "\n\nhello\n")
+	})
+
+	t.Run("failure is kept and later matches are still saved", func(t *testing.T) {
+		orig := getRawEmailFunc
+		t.Cleanup(func() { getRawEmailFunc = orig })
+		getRawEmailFunc = func(ctx context.Context, inbox *vaultsandbox.Inbox, id string) (string, error) {
+			if id == "one" {
+				return "", errors.New("not found")
+			}
+			return "Subject: " + id + "\n\nbody", nil
+		}
+
+		dir := t.TempDir()
+		s := &emailSaver{ctx: context.Background(), dir: dir, format: "eml"}
+		for _, m := range matches {
+			s.Save(inbox, m, &bytes.Buffer{})
+		}
+		assert.EqualError(t, s.Err(), "failed to save email one: not found")
+		assert.NoFileExists(t, filepath.Join(dir, "20260304T050607Z-one.eml"))
+		assert.FileExists(t, filepath.Join(dir, "20260304T050608Z-two.eml"))
+	})
+
+	t.Run("nil saver", func(t *testing.T) {
+		var s *emailSaver
+		s.Save(inbox, matches[0], &bytes.Buffer{})
+		assert.NoError(t, s.Err())
+	})
+}
+
+func TestCollectMatchesSavesBeforeTimeout(t *testing.T) {
+	dir := t.TempDir()
+	s := &emailSaver{ctx: context.Background(), dir: dir, format: "text"}
+	received := time.Date(2026, 3, 4, 5, 6, 7, 0, time.UTC)
+
+	incoming := make(chan matchedEmail, 2)
+	incoming <- matchedEmail{Inbox: "a@test.com", Email: &vaultsandbox.Email{ID: "one", ReceivedAt: received}}
+	incoming <- matchedEmail{Inbox: "a@test.com", Email: &vaultsandbox.Email{ID: "two", ReceivedAt: received}}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err := collectMatches(ctx, nil, incoming, func(*vaultsandbox.Email) bool { return true },
+		[]string{"a@test.com"}, 3, false, func(m matchedEmail) { s.Save(nil, m, &bytes.Buffer{}) })
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	require.NoError(t, s.Err())
+	assert.FileExists(t, filepath.Join(dir, "20260304T050607Z-one.txt"))
+	assert.FileExists(t, filepath.Join(dir, "20260304T050607Z-two.txt"))
+}
@@ -1,11 +1,22 @@
 package email
 
 import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"regexp"
+	"runtime"
 	"testing"
 	"time"
 
+	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	vaultsandbox "github.com/vaultsandbox/client-go"
 )
 
 func TestBuildWaitOptions(t *testing.T) {
@@ -15,36 +26,36 @@ func TestBuildWaitOptions(t *testing.T) {
 		waitForSubjectRegex = ""
 		waitForFrom = ""
 		waitForFromRegex = ""
+		waitForBodyRegex = ""
+		waitForNotSubject = ""
+		waitForNotSubjectRe = ""
+		waitForNotFrom = ""
+		waitForNotFromRe = ""
 	}
 
-	t.Run("no filters returns only timeout option", func(t *testing.T) {
+	t.Run("no filters matches everything", func(t *testing.T) {
 		resetWaitFlags()
 
-		opts, err := buildWaitOptions(30 * time.Second)
+		opts, err := buildWaitOptions(30*time.Second, nil)
 		require.NoError(t, err)
-		// Should have at least the timeout option
-		assert.NotEmpty(t, opts)
+		assert.Len(t, opts, 2)
+		assert.Len(t, waitMatchIDs(t, waitSampleEmails()), 5)
 	})
 
-	t.Run("subject filter adds option", func(t *testing.T) {
+	t.Run("subject filter", func(t *testing.T) {
 		resetWaitFlags()
-		waitForSubject = "Test Subject"
+		waitForSubject = "Welcome"
 
-		opts, err := buildWaitOptions(30 * time.Second)
-		require.NoError(t, err)
-		// Should have timeout + subject options
-		assert.Len(t, opts, 2)
+		assert.Equal(t, []string{"no-otp"}, waitMatchIDs(t, waitSampleEmails()))
 
 		resetWaitFlags()
 	})
 
-	t.Run("subject regex filter adds option", func(t *testing.T) {
+	t.Run("subject regex filter", func(t *testing.T) {
 		resetWaitFlags()
-		waitForSubjectRegex = "^Test.*"
+		waitForSubjectRegex = "^News"
 
-		opts, err := buildWaitOptions(30 * time.Second)
-		require.NoError(t, err)
-		assert.Len(t, opts, 2)
+		assert.Equal(t, []string{"newsletter"}, waitMatchIDs(t, waitSampleEmails()))
 
 		resetWaitFlags()
 	})
@@ -53,31 +64,27 @@ func TestBuildWaitOptions(t *testing.T) {
 		resetWaitFlags()
 		waitForSubjectRegex = "[invalid"
 
-		_, err := buildWaitOptions(30 * time.Second)
+		_, err := buildWaitOptions(30*time.Second, nil)
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "invalid subject regex")
 
 		resetWaitFlags()
 	})
 
-	t.Run("from filter adds option", func(t *testing.T) {
+	t.Run("from filter", func(t *testing.T) {
 		resetWaitFlags()
-		waitForFrom = "sender@example.com"
+		waitForFrom = "bot@spam.com"
 
-		opts, err := buildWaitOptions(30 * time.Second)
-		require.NoError(t, err)
-		assert.Len(t, opts, 2)
+		assert.Equal(t, []string{"spam"}, waitMatchIDs(t, waitSampleEmails()))
 
 		resetWaitFlags()
 	})
 
-	t.Run("from regex filter adds option", func(t *testing.T) {
+	t.Run("from regex filter", func(t *testing.T) {
 		resetWaitFlags()
-		waitForFromRegex = "@example\\.com$"
+		waitForFromRegex = "@spam\\.com$"
 
-		opts, err := buildWaitOptions(30 * time.Second)
-		require.NoError(t, err)
-		assert.Len(t, opts, 2)
+		assert.Equal(t, []string{"spam"}, waitMatchIDs(t, waitSampleEmails()))
 
 		resetWaitFlags()
 	})
@@ -86,7 +93,7 @@ func TestBuildWaitOptions(t *testing.T) {
 		resetWaitFlags()
 		waitForFromRegex = "[invalid"
 
-		_, err := buildWaitOptions(30 * time.Second)
+		_, err := buildWaitOptions(30*time.Second, nil)
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "invalid from regex")
 
@@ -95,28 +102,89 @@ func TestBuildWaitOptions(t *testing.T) {
 
 	t.Run("combined filters (AND logic)", func(t *testing.T) {
 		resetWaitFlags()
-		waitForSubject = "Welcome"
-		waitForFrom = "noreply@example.com"
+		waitForSubject = "Your code"
+		waitForFrom = "app@example.com"
 
-		opts, err := buildWaitOptions(30 * time.Second)
+		opts, err := buildWaitOptions(30*time.Second, nil)
 		require.NoError(t, err)
-		// timeout + subject + from = 3 options
-		assert.Len(t, opts, 3)
+		assert.Len(t, opts, 2)
+		assert.Equal(t, []string{"otp", "old"}, waitMatchIDs(t, waitSampleEmails()))
 
 		resetWaitFlags()
 	})
 
 	t.Run("all filters together", func(t *testing.T) {
 		resetWaitFlags()
-		waitForSubject = "Subject"
-		waitForSubjectRegex = "Sub.*"
-		waitForFrom = "from@test.com"
-		waitForFromRegex = "@test\\.com$"
+		waitForSubject = "Your code"
+		waitForSubjectRegex = "code$"
+		waitForFrom = "bot@spam.com"
+		waitForFromRegex = "@spam\\.com$"
 
-		opts, err := buildWaitOptions(60 * time.Second)
+		opts, err := buildWaitOptions(60*time.Second, nil)
 		require.NoError(t, err)
-		// timeout + 4 filters = 5 options
-		assert.Len(t, opts, 5)
+		assert.Len(t, opts, 2)
+		assert.Equal(t, []string{"spam"}, waitMatchIDs(t, waitSampleEmails()))
+
+		resetWaitFlags()
+	})
+
+	t.Run("body and exclusion filters compose", func(t *testing.T) {
+		resetWaitFlags()
+		waitForBodyRegex = `OTP is: \d{6}`
+		waitForNotSubject = "Newsletter"
+		waitForNotFromRe = "@spam\\.com$"
+
+		opts, err := buildWaitOptions(30*time.Second, nil)
+		require.NoError(t, err)
+		// timeout + one predicate holding every filter
+		assert.Len(t, opts, 2)
+
+		assert.Equal(t, []string{"otp", "old"}, waitMatchIDs(t, waitSampleEmails()))
+
+		waitForNotFromRe = "[invalid"
+		_, err = buildWaitOptions(30*time.Second, nil)
+		assert.ErrorContains(t, err, "invalid not-from regex")
+
+		resetWaitFlags()
+	})
+
+	t.Run("body regex", func(t *testing.T) {
+		resetWaitFlags()
+		waitForBodyRegex = `OTP is: \d{6}`
+
+		opts, err := buildWaitOptions(30*time.Second, nil)
+		require.NoError(t, err)
+		assert.Len(t, opts, 2)
+		assert.Equal(t, []string{"otp", "newsletter", "spam", "old"}, waitMatchIDs(t, waitSampleEmails()))
+
+		waitForFrom = "app@example.com"
+		assert.Equal(t, []string{"otp", "newsletter", "old"}, waitMatchIDs(t, waitSampleEmails()))
+
+		waitForBodyRegex = "[invalid"
+		_, err = buildWaitOptions(30*time.Second, nil)
+		assert.ErrorContains(t, err, "invalid body regex")
+
+		resetWaitFlags()
+	})
+
+	t.Run("receive cutoff composes with body and exclusion filters", func(t *testing.T) {
+		resetWaitFlags()
+		defer func() { waitReceivedAfter = time.Time{} }()
+		// As set by --only-new or --min-received
+		waitReceivedAfter = time.Date(2026, 1, 2, 14, 30, 0, 0, time.UTC)
+
+		opts, err := buildWaitOptions(30*time.Second, nil)
+		require.NoError(t, err)
+		assert.Len(t, opts, 2)
+		assert.Equal(t, []string{"otp", "no-otp", "newsletter", "spam"}, waitMatchIDs(t, waitSampleEmails()))
+
+		// A --preset fills in the same variables as the flags
+		waitForBodyRegex = `OTP is: \d{6}`
+		assert.Equal(t, []string{"otp", "newsletter", "spam"}, waitMatchIDs(t, waitSampleEmails()))
+
+		waitForNotSubject = "Newsletter"
+		waitForNotFrom = "bot@spam.com"
+		assert.Equal(t, []string{"otp"}, waitMatchIDs(t, waitSampleEmails()))
 
 		resetWaitFlags()
 	})
@@ -133,7 +201,7 @@ func TestBuildWaitOptions(t *testing.T) {
 		}
 
 		for _, timeout := range testCases {
-			opts, err := buildWaitOptions(timeout)
+			opts, err := buildWaitOptions(timeout, nil)
 			require.NoError(t, err)
 			assert.NotEmpty(t, opts)
 		}
@@ -155,7 +223,7 @@ func TestBuildWaitOptions(t *testing.T) {
 
 		for _, pattern := range validPatterns {
 			waitForSubjectRegex = pattern
-			opts, err := buildWaitOptions(30 * time.Second)
+			opts, err := buildWaitOptions(30*time.Second, nil)
 			require.NoError(t, err, "pattern %q should be valid", pattern)
 			assert.NotEmpty(t, opts)
 		}
@@ -163,3 +231,489 @@ func TestBuildWaitOptions(t *testing.T) {
 		resetWaitFlags()
 	})
 }
+
+// waitSampleEmails covers each filter of TestBuildWaitOptions
+func waitSampleEmails() []*vaultsandbox.Email {
+	received := time.Date(2026, 1, 2, 15, 0, 0, 0, time.UTC)
+	return []*vaultsandbox.Email{
+		{ID: "otp", Subject: "Your code", From: "app@example.com", Text: "Your OTP is: 123456", ReceivedAt: received},
+		{ID: "no-otp", Subject: "Welcome", From: "app@example.com", Text: "Hello", ReceivedAt: received},
+		{ID: "newsletter", Subject: "Newsletter", From: "app@example.com", Text: "Your OTP is: 654321", ReceivedAt: received},
+		{ID: "spam", Subject: "Your code", From: "bot@spam.com", Text: "Your OTP is: 111111", ReceivedAt: received},
+		{ID: "old", Subject: "Your code", From: "app@example.com", Text: "Your OTP is: 222222", ReceivedAt: received.Add(-time.Hour)},
+	}
+}
+
+// waitMatchIDs returns the IDs of the emails the wait filters accept
+func waitMatchIDs(t *testing.T, emails []*vaultsandbox.Email) []string {
+	t.Helper()
+	matches, err := buildEmailMatcher()
+	require.NoError(t, err)
+	var ids []string
+	for _, e := range emails {
+		if matches(e) {
+			ids = append(ids, e.ID)
+		}
+	}
+	return ids
+}
+
+func TestSelectLink(t *testing.T) {
+	links := []string{
+		"https://example.com/home",
+		"https://example.com/verify?token=abc",
+		"https://example.com/unsubscribe",
+	}
+
+	t.Run("first link by default", func(t *testing.T) {
+		link, err := selectLink(links, 1, nil)
+		require.NoError(t, err)
+		assert.Equal(t, links[0], link)
+	})
+
+	t.Run("nth link", func(t *testing.T) {
+		link, err := selectLink(links, 3, nil)
+		require.NoError(t, err)
+		assert.Equal(t, links[2], link)
+	})
+
+	t.Run("regex selects matching link", func(t *testing.T) {
+		link, err := selectLink(links, 1, regexp.MustCompile(`verify\?token=`))
+		require.NoError(t, err)
+		assert.Equal(t, links[1], link)
+	})
+
+	t.Run("index counts only matching links", func(t *testing.T) {
+		_, err := selectLink(links, 2, regexp.MustCompile(`verify`))
+		assert.EqualError(t, err, "link index 2 out of range (1-1)")
+	})
+
+	t.Run("no links", func(t *testing.T) {
+		_, err := selectLink(nil, 1, nil)
+		assert.EqualError(t, err, "no links found in email")
+	})
+
+	t.Run("no matching links", func(t *testing.T) {
+		_, err := selectLink(links, 1, regexp.MustCompile(`reset`))
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "no links match")
+	})
+}
+
+func TestOpenWaitLink(t *testing.T) {
+	oldOpen := openURLInBrowserFunc
+	oldHeadless := isHeadlessFunc
+	defer func() {
+		openURLInBrowserFunc = oldOpen
+		isHeadlessFunc = oldHeadless
+		waitForOpen = 0
+		waitForQuiet = false
+	}()
+
+	email := &vaultsandbox.Email{Links: []string{"https://example.com/a", "https://example.com/b"}}
+
+	t.Run("opens selected link", func(t *testing.T) {
+		var opened string
+		openURLInBrowserFunc = func(u string) error { opened = u; return nil }
+		isHeadlessFunc = func() bool { return false }
+		waitForOpen = 2
+
+		require.NoError(t, openWaitLink(email, nil))
+		assert.Equal(t, "https://example.com/b", opened)
+	})
+
+	t.Run("headless does not try to open", func(t *testing.T) {
+		called := false
+		openURLInBrowserFunc = func(string) error { called = true; return nil }
+		isHeadlessFunc = func() bool { return true }
+		waitForOpen = 1
+
+		assert.NoError(t, openWaitLink(email, nil))
+		assert.False(t, called)
+	})
+
+	t.Run("open failure is not an error", func(t *testing.T) {
+		openURLInBrowserFunc = func(string) error { return errors.New("xdg-open not found") }
+		isHeadlessFunc = func() bool { return false }
+		waitForOpen = 1
+		waitForQuiet = true
+
+		assert.NoError(t, openWaitLink(email, nil))
+	})
+
+	t.Run("disallowed scheme is rejected", func(t *testing.T) {
+		called := false
+		openURLInBrowserFunc = func(string) error { called = true; return nil }
+		isHeadlessFunc = func() bool { return false }
+		waitForOpen = 1
+
+		bad := &vaultsandbox.Email{Links: []string{"javascript:alert(1)"}}
+		assert.Error(t, openWaitLink(bad, nil))
+		assert.False(t, called)
+	})
+}
+
+func TestOutputEmailsPrintID(t *testing.T) {
+	defer func() {
+		waitForPrintID = false
+		waitForQuiet = false
+	}()
+
+	emails := []matchedEmail{
+		{Inbox: "a@example.com", Email: &vaultsandbox.Email{ID: "email-1", Subject: "First"}},
+		{Inbox: "a@example.com", Email: &vaultsandbox.Email{ID: "email-2", Subject: "Second"}},
+	}
+
+	capture := func(f func()) string {
+		old := os.Stdout
+		r, w, err := os.Pipe()
+		require.NoError(t, err)
+		os.Stdout = w
+		f()
+		w.Close()
+		os.Stdout = old
+		var buf bytes.Buffer
+		_, _ = io.Copy(&buf, r)
+		return buf.String()
+	}
+
+	cmd := &cobra.Command{}
+	cmd.Flags().StringP("output", "o", "", "Output format")
+
+	t.Run("prints one ID per line", func(t *testing.T) {
+		waitForPrintID = true
+		out := capture(func() { require.NoError(t, outputEmails(cmd, emails, nil, false)) })
+		assert.Equal(t, "email-1\nemail-2\n", out)
+	})
+
+	t.Run("takes precedence over json", func(t *testing.T) {
+		waitForPrintID = true
+		require.NoError(t, cmd.Flags().Set("output", "json"))
+		out := capture(func() { require.NoError(t, outputEmails(cmd, emails[:1], nil, false)) })
+		assert.Equal(t, "email-1\n", out)
+	})
+
+	t.Run("quiet prints nothing", func(t *testing.T) {
+		waitForPrintID = true
+		waitForQuiet = true
+		out := capture(func() { require.NoError(t, outputEmails(cmd, emails, nil, false)) })
+		assert.Empty(t, out)
+	})
+}
+
+func TestOutputEmailsExtractLink(t *testing.T) {
+	defer func() {
+		waitForExtractLink = false
+		waitForOpen = 0
+	}()
+	waitForExtractLink = true
+
+	cmd := &cobra.Command{}
+	cmd.Flags().StringP("output", "o", "", "Output format")
+	matches := []matchedEmail{{Inbox: "a@example.com", Email: &vaultsandbox.Email{
+		ID:    "email-1",
+		Links: []string{"https://example.com/home", "https://example.com/verify"},
+	}}}
+
+	t.Run("prints the selected link", func(t *testing.T) {
+		waitForOpen = 2
+		out := captureURLStdout(t, func() { require.NoError(t, outputEmails(cmd, matches, nil, false)) })
+		assert.Equal(t, "https://example.com/verify\n", out)
+	})
+
+	t.Run("missing link is an error", func(t *testing.T) {
+		waitForOpen = 0
+		var err error
+		out := captureURLStdout(t, func() { err = outputEmails(cmd, matches, regexp.MustCompile("reset"), false) })
+		assert.EqualError(t, err, `no links match "reset"`)
+		assert.Empty(t, out)
+	})
+
+	t.Run("index out of range is an error", func(t *testing.T) {
+		waitForOpen = 3
+		err := outputEmails(cmd, matches, nil, false)
+		assert.EqualError(t, err, "link index 3 out of range (1-2)")
+	})
+}
+
+func TestBuildExclusionFilter(t *testing.T) {
+	reset := func() {
+		waitForNotSubject = ""
+		waitForNotSubjectRe = ""
+		waitForNotFrom = ""
+		waitForNotFromRe = ""
+	}
+	t.Cleanup(reset)
+
+	welcome := &vaultsandbox.Email{Subject: "Welcome", From: "app@example.com"}
+	spam := &vaultsandbox.Email{Subject: "Win a prize", From: "noreply@spam.com"}
+
+	t.Run("none set", func(t *testing.T) {
+		reset()
+		excluded, err := buildExclusionFilter()
+		require.NoError(t, err)
+		assert.Nil(t, excluded)
+	})
+
+	t.Run("exact sender", func(t *testing.T) {
+		reset()
+		waitForNotFrom = "noreply@spam.com"
+		excluded, err := buildExclusionFilter()
+		require.NoError(t, err)
+		assert.True(t, excluded(spam))
+		assert.False(t, excluded(welcome))
+	})
+
+	t.Run("any filter excludes", func(t *testing.T) {
+		reset()
+		waitForNotSubjectRe = "(?i)prize"
+		waitForNotFrom = "other@example.com"
+		excluded, err := buildExclusionFilter()
+		require.NoError(t, err)
+		assert.True(t, excluded(spam))
+		assert.False(t, excluded(welcome))
+
+		waitForNotSubject = "Welcome"
+		excluded, err = buildExclusionFilter()
+		require.NoError(t, err)
+		assert.True(t, excluded(welcome))
+	})
+
+	t.Run("invalid regex", func(t *testing.T) {
+		reset()
+		waitForNotSubjectRe = "[invalid"
+		_, err := buildExclusionFilter()
+		assert.ErrorContains(t, err, "invalid not-subject regex")
+	})
+}
+
+func TestCompileExtractRegex(t *testing.T) {
+	t.Run("unset", func(t *testing.T) {
+		re, err := compileExtractRegex(`(?P<otp>\d+)`, "")
+		require.NoError(t, err)
+		assert.Nil(t, re)
+	})
+
+	t.Run("named group present", func(t *testing.T) {
+		re, err := compileExtractRegex(`Your OTP is: (?P<otp>\d{6})`, "otp")
+		require.NoError(t, err)
+		assert.NotNil(t, re)
+	})
+
+	t.Run("missing named group", func(t *testing.T) {
+		_, err := compileExtractRegex(`Your OTP is: (\d{6})`, "otp")
+		assert.ErrorContains(t, err, `no capture group named "otp"`)
+	})
+
+	t.Run("requires body regex", func(t *testing.T) {
+		_, err := compileExtractRegex("", "otp")
+		assert.ErrorContains(t, err, "requires --body-regex")
+	})
+
+	t.Run("invalid regex", func(t *testing.T) {
+		_, err := compileExtractRegex("(?P<otp>[", "otp")
+		assert.ErrorContains(t, err, "invalid body regex")
+	})
+}
+
+func TestExtractGroup(t *testing.T) {
+	re := regexp.MustCompile(`Your OTP is: (?P<otp>\d{6})(?: \(ref (?P<ref>\w+)\))?`)
+
+	t.Run("extracts from text body", func(t *testing.T) {
+		email := &vaultsandbox.Email{ID: "e1", Text: "Hello,\nYour OTP is: 123456\nThanks"}
+		value, err := extractGroup(email, re, "otp")
+		require.NoError(t, err)
+		assert.Equal(t, "123456", value)
+	})
+
+	t.Run("falls back to html body", func(t *testing.T) {
+		email := &vaultsandbox.Email{ID: "e1", HTML: "<p>Your OTP is: 654321</p>"}
+		value, err := extractGroup(email, re, "otp")
+		require.NoError(t, err)
+		assert.Equal(t, "654321", value)
+	})
+
+	t.Run("optional group not matched", func(t *testing.T) {
+		email := &vaultsandbox.Email{ID: "e1", Text: "Your OTP is: 123456"}
+		_, err := extractGroup(email, re, "ref")
+		assert.ErrorContains(t, err, `capture group "ref" did not match in email e1`)
+	})
+
+	t.Run("body changed", func(t *testing.T) {
+		email := &vaultsandbox.Email{ID: "e2", Text: "Your code: 123456"}
+		_, err := extractGroup(email, re, "otp")
+		assert.Error(t, err)
+	})
+}
+
+func TestRunTrigger(t *testing.T) {
+	reset := func() {
+		waitForTrigger = ""
+		waitForTriggerURL = ""
+		waitForTriggerMethod = http.MethodPost
+		waitForQuiet = false
+	}
+	defer reset()
+
+	t.Run("no trigger is a no-op", func(t *testing.T) {
+		reset()
+		assert.NoError(t, runTrigger(context.Background(), io.Discard))
+	})
+
+	t.Run("command output goes to writer", func(t *testing.T) {
+		if runtime.GOOS == "windows" {
+			t.Skip("uses sh syntax")
+		}
+		reset()
+		waitForTrigger = "echo triggered"
+
+		var buf bytes.Buffer
+		require.NoError(t, runTrigger(context.Background(), &buf))
+		assert.Equal(t, "triggered\n", buf.String())
+	})
+
+	t.Run("failing command aborts", func(t *testing.T) {
+		if runtime.GOOS == "windows" {
+			t.Skip("uses sh syntax")
+		}
+		reset()
+		waitForTrigger = "exit 3"
+
+		err := runTrigger(context.Background(), io.Discard)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "trigger command failed")
+		assert.Contains(t, err.Error(), "exit status 3")
+	})
+
+	t.Run("URL request uses method", func(t *testing.T) {
+		reset()
+		var gotMethod string
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			gotMethod = r.Method
+			w.WriteHeader(http.StatusAccepted)
+		}))
+		defer srv.Close()
+		waitForTriggerURL = srv.URL + "/reset"
+
+		require.NoError(t, runTrigger(context.Background(), io.Discard))
+		assert.Equal(t, http.MethodPost, gotMethod)
+
+		waitForTriggerMethod = http.MethodGet
+		require.NoError(t, runTrigger(context.Background(), io.Discard))
+		assert.Equal(t, http.MethodGet, gotMethod)
+	})
+
+	t.Run("non-2xx response aborts", func(t *testing.T) {
+		reset()
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer srv.Close()
+		waitForTriggerURL = srv.URL
+
+		err := runTrigger(context.Background(), io.Discard)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "trigger request failed")
+		assert.Contains(t, err.Error(), "500")
+	})
+}
+
+// recordingReporter captures CI outputs and annotations
+type recordingReporter struct {
+	outputs  map[string]string
+	errors   []string
+	warnings []string
+}
+
+func newRecordingReporter() *recordingReporter {
+	return &recordingReporter{outputs: map[string]string{}}
+}
+
+func (r *recordingReporter) SetOutput(key, value string) error {
+	r.outputs[key] = value
+	return nil
+}
+func (r *recordingReporter) Error(msg string)   { r.errors = append(r.errors, msg) }
+func (r *recordingReporter) Warning(msg string) { r.warnings = append(r.warnings, msg) }
+
+func TestReportWaitResult(t *testing.T) {
+	defer func() {
+		waitForOpen = 0
+		waitForExtractLink = false
+	}()
+
+	email := &vaultsandbox.Email{
+		ID:      "email-1",
+		Subject: "Verify your account",
+		Links:   []string{"https://example.com/home", "https://example.com/verify?t=1"},
+	}
+
+	t.Run("writes id, subject and first link", func(t *testing.T) {
+		r := newRecordingReporter()
+		require.NoError(t, reportWaitResult(r, email, nil))
+		assert.Equal(t, map[string]string{
+			"email_id": "email-1",
+			"subject":  "Verify your account",
+			"link":     "https://example.com/home",
+		}, r.outputs)
+	})
+
+	t.Run("link respects link-match", func(t *testing.T) {
+		r := newRecordingReporter()
+		require.NoError(t, reportWaitResult(r, email, regexp.MustCompile("/verify")))
+		assert.Equal(t, "https://example.com/verify?t=1", r.outputs["link"])
+	})
+
+	t.Run("missing link warns when extracting", func(t *testing.T) {
+		waitForExtractLink = true
+		r := newRecordingReporter()
+		require.NoError(t, reportWaitResult(r, &vaultsandbox.Email{ID: "e"}, nil))
+		assert.NotContains(t, r.outputs, "link")
+		assert.Equal(t, []string{"no links found in email"}, r.warnings)
+	})
+}
+
+func TestReceivedCutoff(t *testing.T) {
+	startedAt := time.Date(2026, 1, 2, 15, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		onlyNew     bool
+		minReceived string
+		want        time.Time
+	}{
+		{name: "neither set", want: time.Time{}},
+		{name: "only new", onlyNew: true, want: startedAt},
+		{name: "absolute", minReceived: "2026-01-02T14:00:00Z", want: startedAt.Add(-time.Hour)},
+		{name: "relative", minReceived: "10m", want: startedAt.Add(-10 * time.Minute)},
+		{name: "only new is later", onlyNew: true, minReceived: "1h", want: startedAt},
+		{name: "min received is later", onlyNew: true, minReceived: "2026-01-02T16:00:00Z", want: startedAt.Add(time.Hour)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := receivedCutoff(tt.onlyNew, tt.minReceived, startedAt)
+			require.NoError(t, err)
+			assert.True(t, tt.want.Equal(got), "got %v, want %v", got, tt.want)
+		})
+	}
+
+	_, err := receivedCutoff(false, "yesterday", startedAt)
+	assert.ErrorContains(t, err, "invalid --min-received")
+}
+
+func TestParsePollInterval(t *testing.T) {
+	d, err := parsePollInterval("", time.Minute)
+	require.NoError(t, err)
+	assert.Zero(t, d, "flag not given")
+
+	d, err = parsePollInterval("750ms", 10*time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, 750*time.Millisecond, d)
+
+	_, err = parsePollInterval("200ms", time.Minute)
+	assert.ErrorContains(t, err, "invalid --poll-interval")
+
+	_, err = parsePollInterval("10s", 10*time.Second)
+	assert.ErrorContains(t, err, "must be shorter than --timeout 10s")
+}
@@ -0,0 +1,148 @@
+package cli
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/spf13/cobra"
+	vaultsandbox "github.com/vaultsandbox/client-go"
+	"github.com/vaultsandbox/vsb-cli/internal/cli/email"
+	"github.com/vaultsandbox/vsb-cli/internal/cliutil"
+	"github.com/vaultsandbox/vsb-cli/internal/config"
+)
+
+// Codes of the JSON error object written on failure with --output json
+const (
+	codeError           = "error"
+	codeInterrupted     = "interrupted"
+	codeNoAPIKey        = "no_api_key"
+	codeExitStatus      = "exit_status"
+	codeTimeout         = "timeout"
+	codeInboxNotFound   = "inbox_not_found"
+	codeNoActiveInbox   = "no_active_inbox"
+	codeAmbiguousInbox  = "ambiguous_inbox"
+	codePresetNotFound  = "preset_not_found"
+	codeExportCorrupted = "export_corrupted"
+	codeExportUnsigned  = "export_unsigned"
+	codeExportSignature = "export_signature"
+	codeUnauthorized    = "unauthorized"
+	codeNetwork         = "network_error"
+	codeKeystoreInvalid = "keystore_invalid"
+	codeConfigDir       = "config_dir_not_writable"
+	codeAuthRegression  = "auth_regression"
+)
+
+// classifyError returns the code and exit status for an error returned by
+// Execute. Both the JSON error object and the process exit status come from
+// here, so they always agree.
+func classifyError(err error) (code string, exitStatus int) {
+	var exitErr *ExitError
+	var timeoutErr *cliutil.TimeoutError
+	var netErr *vaultsandbox.NetworkError
+	switch {
+	case errors.Is(err, ErrInterrupted):
+		return codeInterrupted, 130
+	case errors.Is(err, ErrNoAPIKey):
+		return codeNoAPIKey, 3
+	case errors.As(err, &exitErr):
+		return codeExitStatus, exitErr.Code
+	case errors.As(err, &timeoutErr):
+		return codeTimeout, 1
+	case errors.Is(err, config.ErrInboxNotFound):
+		return codeInboxNotFound, 1
+	case errors.Is(err, config.ErrNoActiveInbox):
+		return codeNoActiveInbox, 1
+	case errors.Is(err, config.ErrMultipleMatches):
+		return codeAmbiguousInbox, 1
+	case errors.Is(err, config.ErrPresetNotFound):
+		return codePresetNotFound, 1
+	case errors.Is(err, config.ErrExportCorrupted):
+		return codeExportCorrupted, 1
+	case errors.Is(err, config.ErrExportUnsigned):
+		return codeExportUnsigned, 1
+	case errors.Is(err, config.ErrExportSignature):
+		return codeExportSignature, 1
+	case errors.Is(err, config.ErrConfigDirNotWritable):
+		return codeConfigDir, 1
+	case errors.Is(err, config.ErrKeystoreInvalid):
+		return codeKeystoreInvalid, 1
+	case errors.Is(err, email.ErrAuthRegression):
+		return codeAuthRegression, 1
+	case errors.Is(err, vaultsandbox.ErrUnauthorized):
+		return codeUnauthorized, 1
+	case errors.As(err, &netErr):
+		return codeNetwork, 1
+	}
+	return codeError, 1
+}
+
+// ExitCode returns the status vsb exits with when Execute returns err.
+func ExitCode(err error) int {
+	_, status := classifyError(err)
+	return status
+}
+
+// detailedError is implemented by errors that add fields to the JSON error
+// object
+type detailedError interface {
+	ErrorDetails() map[string]interface{}
+}
+
+// errorJSON returns the JSON error object for err:
+// {"error": {"code": ..., "message": ..., "details": {...}}}.
+func errorJSON(err error) map[string]interface{} {
+	code, _ := classifyError(err)
+	obj := map[string]interface{}{
+		"code":    code,
+		"message": err.Error(),
+	}
+	var detailed detailedError
+	if errors.As(err, &detailed) {
+		obj["details"] = detailed.ErrorDetails()
+	}
+	return map[string]interface{}{"error": obj}
+}
+
+// writeJSONError writes the JSON error object for err to stdout when cmd
+// ran with --output json, so tools reading only stdout see why it failed.
+// It comes after anything the command already printed, so streamed output
+// ends with it. The human-readable error still goes to stderr.
+func writeJSONError(cmd *cobra.Command, err error) {
+	if cmd == nil || cliutil.GetOutput(cmd) != cliutil.FormatJSON {
+		return
+	}
+	_ = cliutil.OutputJSON(errorJSON(err))
+}
+
+// writeArgsJSONError is writeJSONError for errors found before cobra has
+// chosen a command, such as a bad alias: JSON output is read from the
+// --output flag in args, or else the configured default.
+func writeArgsJSONError(args []string, err error) {
+	format := outputFlagValue(args)
+	if format == "" {
+		format = config.GetDefaultOutput()
+	}
+	if cliutil.NormalizeOutputFormat(format) != cliutil.FormatJSON {
+		return
+	}
+	_ = cliutil.OutputJSON(errorJSON(err))
+}
+
+// outputFlagValue returns the value of --output (or -o) in args, or "" if
+// it is not given
+func outputFlagValue(args []string) string {
+	if value := flagValue(args, "output"); value != "" {
+		return value
+	}
+	for i, arg := range args {
+		switch {
+		case arg == "--":
+			return ""
+		case arg == "-o" && i+1 < len(args):
+			return args[i+1]
+		case strings.HasPrefix(arg, "-o") && len(arg) > 2:
+			return strings.TrimPrefix(arg[2:], "=")
+		}
+	}
+	return ""
+}
@@ -0,0 +1,151 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	vaultsandbox "github.com/vaultsandbox/client-go"
+	"github.com/vaultsandbox/vsb-cli/internal/cli/email"
+	"github.com/vaultsandbox/vsb-cli/internal/cliutil"
+	"github.com/vaultsandbox/vsb-cli/internal/config"
+)
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		code   string
+		status int
+	}{
+		{"plain", errors.New("boom"), codeError, 1},
+		{"interrupted", ErrInterrupted, codeInterrupted, 130},
+		{"no api key", fmt.Errorf("client: %w", ErrNoAPIKey), codeNoAPIKey, 3},
+		{"exit status", &ExitError{Code: 7, Err: errors.New("exec failed")}, codeExitStatus, 7},
+		{"timeout", &cliutil.TimeoutError{What: "email", Timeout: time.Second}, codeTimeout, 1},
+		{"inbox not found", &cliutil.InboxNotFoundError{Query: "gamma"}, codeInboxNotFound, 1},
+		{"no active inbox", &cliutil.NoActiveInboxError{}, codeNoActiveInbox, 1},
+		{"ambiguous inbox", &cliutil.AmbiguousInboxError{Query: "a"}, codeAmbiguousInbox, 1},
+		{"unknown preset", &cliutil.UnknownPresetError{Name: "x"}, codePresetNotFound, 1},
+		{"unsigned export", fmt.Errorf("import: %w", config.ErrExportUnsigned), codeExportUnsigned, 1},
+		{"config dir not writable", fmt.Errorf("%w: /ro", config.ErrConfigDirNotWritable), codeConfigDir, 1},
+		{"invalid keystore", fmt.Errorf("%w: 1 of 2 failed verification", config.ErrKeystoreInvalid), codeKeystoreInvalid, 1},
+		{"auth regression", fmt.Errorf("%w: DKIM", email.ErrAuthRegression), codeAuthRegression, 1},
+		{"unauthorized", &vaultsandbox.APIError{StatusCode: 401}, codeUnauthorized, 1},
+		{"network", fmt.Errorf("check: %w", &vaultsandbox.NetworkError{Err: errors.New("refused")}), codeNetwork, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, status := classifyError(tt.err)
+			assert.Equal(t, tt.code, code)
+			assert.Equal(t, tt.status, status)
+			assert.Equal(t, tt.status, ExitCode(tt.err))
+		})
+	}
+}
+
+func TestErrorJSON(t *testing.T) {
+	t.Run("with details", func(t *testing.T) {
+		err := &cliutil.TimeoutError{What: "email", Timeout: 30 * time.Second}
+		assert.Equal(t, map[string]interface{}{
+			"error": map[string]interface{}{
+				"code":    "timeout",
+				"message": "timeout waiting for email",
+				"details": map[string]interface{}{"timeout": "30s"},
+			},
+		}, errorJSON(err))
+	})
+
+	t.Run("details of a wrapped error", func(t *testing.T) {
+		err := fmt.Errorf("wait: %w", &ExitError{Code: 2, Err: errors.New("exec failed")})
+		obj := errorJSON(err)["error"].(map[string]interface{})
+		assert.Equal(t, "exit_status", obj["code"])
+		assert.Equal(t, map[string]interface{}{"exitStatus": 2}, obj["details"])
+	})
+
+	t.Run("without details", func(t *testing.T) {
+		obj := errorJSON(errors.New("boom"))["error"].(map[string]interface{})
+		assert.Equal(t, "boom", obj["message"])
+		assert.NotContains(t, obj, "details")
+	})
+}
+
+func TestWriteJSONError(t *testing.T) {
+	newCmd := func(output string) *cobra.Command {
+		var format cliutil.OutputFormat
+		cmd := &cobra.Command{Use: "test"}
+		cmd.Flags().Var(&format, "output", "")
+		if output != "" {
+			require.NoError(t, cmd.Flags().Set("output", output))
+		}
+		return cmd
+	}
+	err := &cliutil.InboxNotFoundError{Query: "gamma"}
+
+	t.Run("json output", func(t *testing.T) {
+		out := captureStdout(t, func() { writeJSONError(newCmd("json"), err) })
+
+		var got struct {
+			Error struct {
+				Code    string            `json:"code"`
+				Message string            `json:"message"`
+				Details map[string]string `json:"details"`
+			} `json:"error"`
+		}
+		require.NoError(t, json.Unmarshal([]byte(out), &got))
+		assert.Equal(t, "inbox_not_found", got.Error.Code)
+		assert.Equal(t, "inbox not found: gamma", got.Error.Message)
+		assert.Equal(t, map[string]string{"query": "gamma"}, got.Error.Details)
+	})
+
+	t.Run("pretty output writes nothing", func(t *testing.T) {
+		t.Setenv("VSB_OUTPUT", "")
+		assert.Empty(t, captureStdout(t, func() { writeJSONError(newCmd(""), err) }))
+		assert.Empty(t, captureStdout(t, func() { writeJSONError(newCmd("pretty"), err) }))
+	})
+
+	t.Run("no command", func(t *testing.T) {
+		assert.Empty(t, captureStdout(t, func() { writeJSONError(nil, err) }))
+	})
+}
+
+func TestWriteArgsJSONError(t *testing.T) {
+	err := errors.New(`alias "x" expands to nothing`)
+
+	for _, args := range [][]string{
+		{"x", "--output", "json"},
+		{"x", "--output=json"},
+		{"x", "-o", "json"},
+		{"x", "-ojson"},
+		{"x", "-o=json"},
+	} {
+		t.Run(strings.Join(args, " "), func(t *testing.T) {
+			t.Setenv("VSB_OUTPUT", "")
+			out := captureStdout(t, func() { writeArgsJSONError(args, err) })
+
+			var got map[string]map[string]string
+			require.NoError(t, json.Unmarshal([]byte(out), &got))
+			assert.Equal(t, map[string]string{"code": "error", "message": err.Error()}, got["error"])
+		})
+	}
+
+	t.Run("configured default", func(t *testing.T) {
+		t.Setenv("VSB_OUTPUT", "json")
+		assert.Contains(t, captureStdout(t, func() { writeArgsJSONError([]string{"x"}, err) }), `"code": "error"`)
+
+		// The flag wins over the default
+		assert.Empty(t, captureStdout(t, func() { writeArgsJSONError([]string{"x", "-o", "text"}, err) }))
+	})
+
+	t.Run("pretty output writes nothing", func(t *testing.T) {
+		t.Setenv("VSB_OUTPUT", "")
+		assert.Empty(t, captureStdout(t, func() { writeArgsJSONError([]string{"x"}, err) }))
+		assert.Empty(t, captureStdout(t, func() { writeArgsJSONError([]string{"x", "--", "-o", "json"}, err) }))
+	})
+}
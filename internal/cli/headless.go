@@ -0,0 +1,170 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	vaultsandbox "github.com/vaultsandbox/client-go"
+	"github.com/vaultsandbox/vsb-cli/internal/cliutil"
+	mailfile "github.com/vaultsandbox/vsb-cli/internal/email"
+	"github.com/vaultsandbox/vsb-cli/internal/metrics"
+	"github.com/vaultsandbox/vsb-cli/internal/tui/emails"
+)
+
+// runHeadless is 'vsb --no-tui': the dashboard's watch without a terminal.
+// It reports to the --metrics-listen/--metrics-file recorder and saves to
+// --save-dir like the dashboard, and runs until ctx is cancelled or the
+// process receives SIGTERM.
+func runHeadless(ctx context.Context, client *vaultsandbox.Client, inboxes []*vaultsandbox.Inbox) error {
+	// Sidecars are stopped with SIGTERM; shut down as cleanly as on Ctrl-C
+	ctx, stop := signal.NotifyContext(ctx, syscall.SIGTERM)
+	defer stop()
+
+	w := newHeadlessWatcher(client, inboxes)
+	if saveDir != "" {
+		if err := createSaveDir(); err != nil {
+			return err
+		}
+		w.saveDir = saveDir
+	}
+
+	rec, stopMetrics, err := startMetrics(ctx, inboxes)
+	if err != nil {
+		return err
+	}
+	defer stopMetrics()
+	w.rec = rec
+
+	return w.run(ctx)
+}
+
+// headlessWatcher loads the existing emails of its inboxes, then follows
+// the event stream, reconnecting with the dashboard's backoff when it ends.
+// Each email is handled once: recorded, printed and saved.
+type headlessWatcher struct {
+	inboxes []*vaultsandbox.Inbox
+	out     io.Writer         // a line per email
+	errOut  io.Writer         // load, save and reconnection problems
+	rec     *metrics.Recorder // nil unless --metrics-listen/--metrics-file
+	saveDir string
+	seen    map[string]bool
+
+	// The client calls and backoff, overridden in tests
+	getEmails      func(ctx context.Context, inbox *vaultsandbox.Inbox) ([]*vaultsandbox.Email, error)
+	watch          func(ctx context.Context, inboxes ...*vaultsandbox.Inbox) <-chan *vaultsandbox.InboxEvent
+	rawEmail       func(ctx context.Context, inbox *vaultsandbox.Inbox, id string) (string, error)
+	reconnectDelay func(attempt int) time.Duration
+}
+
+func newHeadlessWatcher(client *vaultsandbox.Client, inboxes []*vaultsandbox.Inbox) *headlessWatcher {
+	return &headlessWatcher{
+		inboxes: inboxes,
+		out:     os.Stdout,
+		errOut:  os.Stderr,
+		seen:    make(map[string]bool),
+		getEmails: func(ctx context.Context, inbox *vaultsandbox.Inbox) ([]*vaultsandbox.Email, error) {
+			return inbox.GetEmails(ctx)
+		},
+		watch: client.WatchInboxes,
+		rawEmail: func(ctx context.Context, inbox *vaultsandbox.Inbox, id string) (string, error) {
+			return inbox.GetRawEmail(ctx, id)
+		},
+		reconnectDelay: emails.ReconnectDelay,
+	}
+}
+
+// run watches until ctx is cancelled
+func (w *headlessWatcher) run(ctx context.Context) error {
+	for _, inbox := range w.inboxes {
+		list, err := w.getEmails(ctx, inbox)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err != nil {
+			fmt.Fprintf(w.errOut, "failed to load emails for %s: %v\n", inbox.EmailAddress(), err)
+			continue
+		}
+		for _, email := range list {
+			w.handle(ctx, inbox, email)
+		}
+	}
+
+	attempt := 0
+	for {
+		started := time.Now()
+		w.rec.SetUp(true)
+		received := w.stream(ctx)
+		if ctx.Err() != nil {
+			return nil
+		}
+		w.rec.SetUp(false)
+
+		// The stream does not report when it is established, so only an
+		// email or a long enough run shows the last reconnect worked
+		if received || time.Since(started) >= emails.ReconnectStableAfter {
+			attempt = 0
+		}
+		attempt++
+		delay := w.reconnectDelay(attempt)
+		fmt.Fprintf(w.errOut, "event stream closed, reconnecting in %s\n", delay)
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(delay):
+		}
+		w.rec.Reconnected()
+	}
+}
+
+// stream handles events until the stream closes or ctx is cancelled, and
+// reports whether any email arrived
+func (w *headlessWatcher) stream(ctx context.Context) bool {
+	// Stop the subscriptions when the stream closes on its own
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	events := w.watch(ctx, w.inboxes...)
+	received := false
+	for {
+		select {
+		case <-ctx.Done():
+			return received
+		case event, ok := <-events:
+			if !ok {
+				return received
+			}
+			if event != nil {
+				received = true
+				w.handle(ctx, event.Inbox, event.Email)
+			}
+		}
+	}
+}
+
+// handle records, prints and saves an email the first time it is seen
+func (w *headlessWatcher) handle(ctx context.Context, inbox *vaultsandbox.Inbox, email *vaultsandbox.Email) {
+	if w.seen[email.ID] {
+		return
+	}
+	w.seen[email.ID] = true
+
+	w.rec.EmailReceived(inbox.EmailAddress(), email.ReceivedAt)
+	fmt.Fprintf(w.out, "%s  %s  %s  %s  %s\n", email.ReceivedAt.Format(time.RFC3339),
+		inbox.EmailAddress(), email.ID, email.From, cliutil.SubjectOrDefault(email.Subject))
+
+	if w.saveDir == "" {
+		return
+	}
+	raw, err := w.rawEmail(ctx, inbox, email.ID)
+	if err == nil {
+		_, err = emails.WriteEMLOnce(w.saveDir, email.ID, mailfile.EML(raw))
+	}
+	if err != nil {
+		fmt.Fprintf(w.errOut, "failed to save email %s: %v\n", email.ID, err)
+	}
+}
@@ -0,0 +1,140 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	vaultsandbox "github.com/vaultsandbox/client-go"
+	"github.com/vaultsandbox/vsb-cli/internal/metrics"
+)
+
+// testHeadlessWatcher returns a watcher for one inbox whose existing emails
+// are existing and whose event streams are taken from streams in turn; once
+// they run out, watch blocks until ctx is cancelled.
+func testHeadlessWatcher(existing []*vaultsandbox.Email, streams ...chan *vaultsandbox.InboxEvent) (*headlessWatcher, *bytes.Buffer, *bytes.Buffer) {
+	var out, errOut bytes.Buffer
+	w := &headlessWatcher{
+		inboxes: []*vaultsandbox.Inbox{{}},
+		out:     &out,
+		errOut:  &errOut,
+		rec:     metrics.NewRecorder(""),
+		seen:    make(map[string]bool),
+		getEmails: func(ctx context.Context, inbox *vaultsandbox.Inbox) ([]*vaultsandbox.Email, error) {
+			return existing, nil
+		},
+		watch: func(ctx context.Context, inboxes ...*vaultsandbox.Inbox) <-chan *vaultsandbox.InboxEvent {
+			if len(streams) == 0 {
+				return make(chan *vaultsandbox.InboxEvent)
+			}
+			ch := streams[0]
+			streams = streams[1:]
+			return ch
+		},
+		reconnectDelay: func(int) time.Duration { return time.Millisecond },
+	}
+	return w, &out, &errOut
+}
+
+// runUntil runs w until done reports true, then cancels it
+func runUntil(t *testing.T, w *headlessWatcher, done func() bool) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	result := make(chan error, 1)
+	go func() { result <- w.run(ctx) }()
+	require.Eventually(t, done, time.Second, time.Millisecond)
+	cancel()
+	require.NoError(t, <-result)
+}
+
+func TestHeadlessWatcher(t *testing.T) {
+	received := time.Date(2026, 3, 1, 14, 30, 0, 0, time.UTC)
+	email := func(id string) *vaultsandbox.Email {
+		return &vaultsandbox.Email{ID: id, From: "app@example.com", Subject: "Welcome " + id, ReceivedAt: received}
+	}
+
+	t.Run("records existing and live emails once", func(t *testing.T) {
+		live := make(chan *vaultsandbox.InboxEvent, 3)
+		live <- &vaultsandbox.InboxEvent{Inbox: &vaultsandbox.Inbox{}, Email: email("e1")}
+		live <- nil
+		live <- &vaultsandbox.InboxEvent{Inbox: &vaultsandbox.Inbox{}, Email: email("e2")}
+		w, out, _ := testHeadlessWatcher([]*vaultsandbox.Email{email("e1")}, live)
+
+		runUntil(t, w, func() bool { return w.rec.Snapshot().EmailsReceivedTotal == 2 })
+
+		stats := w.rec.Snapshot()
+		assert.True(t, stats.WatchUp)
+		assert.Zero(t, stats.SSEReconnects)
+		assert.Equal(t, received, *stats.LastEmailAt)
+		assert.Equal(t, ""+
+			"2026-03-01T14:30:00Z    e1  app@example.com  Welcome e1\n"+
+			"2026-03-01T14:30:00Z    e2  app@example.com  Welcome e2\n", out.String())
+	})
+
+	t.Run("reconnects when the stream closes", func(t *testing.T) {
+		closed := make(chan *vaultsandbox.InboxEvent)
+		close(closed)
+		w, _, errOut := testHeadlessWatcher(nil, closed, closed)
+
+		runUntil(t, w, func() bool { return w.rec.Snapshot().SSEReconnects == 2 })
+
+		assert.Equal(t, 2, strings.Count(errOut.String(), "event stream closed, reconnecting in 1ms\n"))
+	})
+
+	t.Run("backs off until an email arrives", func(t *testing.T) {
+		closed := make(chan *vaultsandbox.InboxEvent)
+		close(closed)
+		live := make(chan *vaultsandbox.InboxEvent, 1)
+		live <- &vaultsandbox.InboxEvent{Inbox: &vaultsandbox.Inbox{}, Email: email("e1")}
+		close(live)
+		w, _, _ := testHeadlessWatcher(nil, closed, closed, live, closed)
+		var attempts []int
+		w.reconnectDelay = func(attempt int) time.Duration {
+			attempts = append(attempts, attempt)
+			return time.Millisecond
+		}
+
+		runUntil(t, w, func() bool { return w.rec.Snapshot().SSEReconnects == 4 })
+
+		assert.Equal(t, []int{1, 2, 1, 2}, attempts)
+	})
+
+	t.Run("load failures are reported", func(t *testing.T) {
+		w, _, errOut := testHeadlessWatcher(nil)
+		w.getEmails = func(ctx context.Context, inbox *vaultsandbox.Inbox) ([]*vaultsandbox.Email, error) {
+			return nil, errors.New("boom")
+		}
+
+		runUntil(t, w, func() bool { return w.rec.Snapshot().WatchUp })
+
+		assert.Equal(t, "failed to load emails for : boom\n", errOut.String())
+	})
+
+	t.Run("saves to the save directory", func(t *testing.T) {
+		live := make(chan *vaultsandbox.InboxEvent, 1)
+		live <- &vaultsandbox.InboxEvent{Inbox: &vaultsandbox.Inbox{}, Email: email("e2")}
+		w, _, errOut := testHeadlessWatcher([]*vaultsandbox.Email{email("e1")}, live)
+		w.saveDir = t.TempDir()
+		w.rawEmail = func(ctx context.Context, inbox *vaultsandbox.Inbox, id string) (string, error) {
+			if id == "e2" {
+				return "", errors.New("gone")
+			}
+			return "Subject: " + id + "\r\n\r\nbody", nil
+		}
+
+		runUntil(t, w, func() bool { return w.rec.Snapshot().EmailsReceivedTotal == 2 })
+
+		data, err := os.ReadFile(filepath.Join(w.saveDir, "e1.eml"))
+		require.NoError(t, err)
+		assert.Equal(t, "Subject: e1\r\n\r\nbody\r\n", string(data))
+		assert.NoFileExists(t, filepath.Join(w.saveDir, "e2.eml"))
+		assert.Equal(t, "failed to save email e2: gone\n", errOut.String())
+	})
+}
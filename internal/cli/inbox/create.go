@@ -2,8 +2,12 @@ package inbox
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
 	"fmt"
-	"strconv"
+	"net/http"
+	"regexp"
 	"strings"
 	"time"
 
@@ -12,6 +16,7 @@ import (
 	"github.com/vaultsandbox/vsb-cli/internal/cliutil"
 	"github.com/vaultsandbox/vsb-cli/internal/config"
 	"github.com/vaultsandbox/vsb-cli/internal/styles"
+	"github.com/vaultsandbox/vsb-cli/internal/timeparse"
 )
 
 // ExportableInbox interface for inbox operations (allows mocking in tests)
@@ -22,9 +27,17 @@ type ExportableInbox interface {
 // InboxCreator interface for creating inboxes (allows mocking in tests)
 type InboxCreator interface {
 	CreateInbox(ctx context.Context, opts ...vaultsandbox.InboxOption) (ExportableInbox, error)
+	DeleteInbox(ctx context.Context, emailAddress string) error
+	ServerInfo() *vaultsandbox.ServerInfo
 	Close() error
 }
 
+// ReadinessChecker is implemented by inboxes that can query their status on
+// the server (allows mocking in tests)
+type ReadinessChecker interface {
+	GetSyncStatus(ctx context.Context) (*vaultsandbox.SyncStatus, error)
+}
+
 // KeystoreWriter interface for saving inboxes (allows mocking in tests)
 type KeystoreWriter interface {
 	AddInbox(inbox config.StoredInbox) error
@@ -39,6 +52,14 @@ func (w *clientWrapper) CreateInbox(ctx context.Context, opts ...vaultsandbox.In
 	return w.client.CreateInbox(ctx, opts...)
 }
 
+func (w *clientWrapper) DeleteInbox(ctx context.Context, emailAddress string) error {
+	return w.client.DeleteInbox(ctx, emailAddress)
+}
+
+func (w *clientWrapper) ServerInfo() *vaultsandbox.ServerInfo {
+	return w.client.ServerInfo()
+}
+
 func (w *clientWrapper) Close() error {
 	return w.client.Close()
 }
@@ -57,9 +78,27 @@ var loadKeystoreFunc = func() (KeystoreWriter, error) {
 	return cliutil.LoadKeystoreOrError()
 }
 
+// randomSuffixFunc generates the unique part of prefixed addresses (overridden in tests)
+var randomSuffixFunc = func() (string, error) {
+	b := make([]byte, 4)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// maxPrefixLength keeps prefixed local parts well under the 64 character limit
+const maxPrefixLength = 32
+
+var prefixPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9.-]*$`)
+
+// readyPollInterval is the delay between readiness checks (overridden in tests)
+var readyPollInterval = 250 * time.Millisecond
+
 var createCmd = &cobra.Command{
-	Use:   "create",
-	Short: "Create a new temporary inbox",
+	Use:     "create",
+	Aliases: []string{"new"},
+	Short:   "Create a new temporary inbox",
 	Long: `Create a new temporary encrypted email inbox.
 
 The inbox uses ML-KEM-768 for key encapsulation and ML-DSA-65 for signatures.
@@ -68,48 +107,99 @@ Your private key never leaves your machine - all decryption happens locally.
 Examples:
   vsb inbox create
   vsb inbox create --ttl 1h
-  vsb inbox create --ttl 7d`,
+  vsb inbox create --ttl 7d
+  vsb inbox create --prefix signup-test   # e.g. signup-test-3f9a1c2e@domain
+  vsb inbox create --domain sandboxmail.example.com
+  vsb inbox create --domain-regex '^team-a\.'
+  vsb inbox create --wait-ready           # Block until deliverable (30s max)
+  vsb inbox create --wait-ready=2m
+  vsb inbox create --label-prefix ci-     # Label it ci-<local part>
+  vsb inbox create --ttl 7d --prefix signup --dry-run
+
+--dry-run validates the flags and prints the request that would be sent,
+with the computed expiry, without contacting the server or writing the
+keystore. The domain is not checked against the server's allowed domains.
+
+The keystore is checked before the inbox is requested. If saving the new
+inbox still fails, it is deleted from the server again rather than left
+there without its keys.
+
+Without --ttl, the lifetime comes from VSB_DEFAULT_TTL or the default-ttl
+config key (24h if neither is set). Without --label-prefix, the prefix comes
+from VSB_DEFAULT_LABEL_PREFIX or the default-label-prefix config key.`,
 	RunE: runCreate,
 }
 
 var (
-	createTTL        string
-	createEmailAuth  string
-	createEncryption string
+	createTTL         string
+	createEmailAuth   string
+	createEncryption  string
+	createPrefix      string
+	createWaitReady   string
+	createDomain      string
+	createDomainRE    string
+	createLabelPrefix string
+	createDryRun      bool
 )
 
 func init() {
 	Cmd.AddCommand(createCmd)
+	cliutil.AddOutputFormats(createCmd, cliutil.FormatJSON)
+	cliutil.MarkSavesState(createCmd)
 
-	createCmd.Flags().StringVar(&createTTL, "ttl", "24h",
-		"Inbox lifetime (e.g., 1h, 24h, 7d)")
+	createCmd.Flags().StringVar(&createTTL, "ttl", "",
+		"Inbox lifetime (e.g., 1h, 24h, 7d; default: default-ttl config or 24h)")
 	createCmd.Flags().StringVar(&createEmailAuth, "email-auth", "",
 		"Enable/disable email authentication (true/false, omit for server default)")
 	createCmd.Flags().StringVar(&createEncryption, "encryption", "",
 		"Encryption mode (encrypted/plain, omit for server default)")
+	createCmd.Flags().StringVar(&createPrefix, "prefix", "",
+		"Start the address local part with this prefix (a unique suffix is added)")
+	createCmd.Flags().StringVar(&createWaitReady, "wait-ready", "",
+		"After creating, wait until the server reports the inbox ready (optional timeout, default 30s)")
+	createCmd.Flags().Lookup("wait-ready").NoOptDefVal = "30s"
+	createCmd.Flags().StringVar(&createDomain, "domain", "",
+		"Create the inbox on this domain (see 'vsb inbox list-domains')")
+	createCmd.Flags().StringVar(&createDomainRE, "domain-regex", "",
+		"Create the inbox on the first domain matching this regular expression")
+	createCmd.MarkFlagsMutuallyExclusive("domain", "domain-regex")
+	createCmd.Flags().StringVar(&createLabelPrefix, "label-prefix", "",
+		"Label the inbox <prefix><local part> (default: default-label-prefix config)")
+	createCmd.Flags().BoolVar(&createDryRun, "dry-run", false,
+		"Validate the flags and show the request without creating the inbox")
 }
 
 func runCreate(cmd *cobra.Command, args []string) error {
-	ctx := context.Background()
-	jsonMode := cliutil.GetOutput(cmd) == "json"
+	ctx := cliutil.CommandContext(cmd)
+	jsonMode := cliutil.GetOutput(cmd) == cliutil.FormatJSON
 
 	// Parse TTL
-	ttl, err := parseTTL(createTTL)
+	ttl, err := parseTTL(resolveCreateTTL(createTTL))
 	if err != nil {
 		return fmt.Errorf("invalid TTL format: %w", err)
 	}
 
-	// Show progress (not in JSON mode)
-	if !jsonMode {
-		fmt.Println(styles.MutedStyle.Render("• Generating keys..."))
+	if createPrefix != "" {
+		if err := validatePrefix(createPrefix); err != nil {
+			return err
+		}
 	}
 
-	// Create client
-	client, err := newClientFunc()
-	if err != nil {
-		return err
+	var domainPattern *regexp.Regexp
+	if createDomainRE != "" {
+		domainPattern, err = regexp.Compile(createDomainRE)
+		if err != nil {
+			return fmt.Errorf("invalid --domain-regex: %w", err)
+		}
+	}
+
+	var readyTimeout time.Duration
+	if createWaitReady != "" {
+		readyTimeout, err = time.ParseDuration(createWaitReady)
+		if err != nil || readyTimeout <= 0 {
+			return fmt.Errorf("invalid --wait-ready timeout: %s", createWaitReady)
+		}
 	}
-	defer client.Close()
 
 	// Build inbox options
 	opts := []vaultsandbox.InboxOption{vaultsandbox.WithTTL(ttl)}
@@ -138,6 +228,46 @@ func runCreate(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	if createDryRun {
+		return printCreateDryRun(ttl, readyTimeout, jsonMode)
+	}
+
+	// Load the keystore before touching the server, so a keystore problem
+	// cannot leave an inbox on the server that vsb does not know about
+	keystore, err := loadKeystoreFunc()
+	if err != nil {
+		return err
+	}
+
+	// Create client
+	client, err := newClientFunc()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	// Show progress (not in JSON mode)
+	if !jsonMode {
+		fmt.Println(styles.MutedStyle.Render("• Generating keys..."))
+	}
+
+	// Pick the requested domain, if any
+	domain, err := selectDomain(client.ServerInfo(), createDomain, domainPattern)
+	if err != nil {
+		return err
+	}
+
+	// Request a prefixed address, or just the domain
+	if createPrefix != "" {
+		address, err := buildPrefixedAddress(createPrefix, domain, client.ServerInfo())
+		if err != nil {
+			return err
+		}
+		opts = append(opts, vaultsandbox.WithEmailAddress(address))
+	} else if domain != "" {
+		opts = append(opts, vaultsandbox.WithEmailAddress(domain))
+	}
+
 	// Create inbox with SDK
 	if !jsonMode {
 		fmt.Println(styles.MutedStyle.Render("• Registering with VaultSandbox..."))
@@ -145,6 +275,10 @@ func runCreate(cmd *cobra.Command, args []string) error {
 
 	inbox, err := client.CreateInbox(ctx, opts...)
 	if err != nil {
+		var apiErr *vaultsandbox.APIError
+		if createPrefix != "" && errors.As(err, &apiErr) && isUnsupportedAddressStatus(apiErr.StatusCode) {
+			return fmt.Errorf("server does not support custom address prefixes: %w", err)
+		}
 		return fmt.Errorf("failed to create inbox: %w", err)
 	}
 
@@ -152,14 +286,30 @@ func runCreate(cmd *cobra.Command, args []string) error {
 	exported := inbox.Export()
 
 	// Save to keystore
-	keystore, err := loadKeystoreFunc()
-	if err != nil {
-		return err
-	}
-
 	stored := config.StoredInboxFromExport(exported)
+	stored.Label = inboxLabel(resolveLabelPrefix(createLabelPrefix), stored.Email)
 	if err := keystore.AddInbox(stored); err != nil {
-		return fmt.Errorf("failed to save inbox: %w", err)
+		return cliutil.RollbackCreate(ctx, client, stored.Email, err)
+	}
+
+	// Wait for the server to accept mail for the new inbox. The inbox is
+	// already saved, so a timeout leaves it usable for a later retry.
+	var readyAfter time.Duration
+	if readyTimeout > 0 {
+		checker, ok := inbox.(ReadinessChecker)
+		if !ok {
+			return fmt.Errorf("inbox readiness check not supported")
+		}
+		if !jsonMode {
+			fmt.Println(styles.MutedStyle.Render("• Waiting for inbox to be ready..."))
+		}
+		readyAfter, err = waitInboxReady(ctx, checker, readyTimeout)
+		if err != nil {
+			return err
+		}
+		if !jsonMode {
+			fmt.Println(styles.MutedStyle.Render(fmt.Sprintf("• Ready after %s", readyAfter.Round(time.Millisecond))))
+		}
 	}
 
 	// Output
@@ -169,6 +319,9 @@ func runCreate(cmd *cobra.Command, args []string) error {
 			"expiresAt": stored.ExpiresAt.Format(time.RFC3339),
 			"createdAt": stored.CreatedAt.Format(time.RFC3339),
 		}
+		if readyTimeout > 0 {
+			data["readyAfterMs"] = readyAfter.Milliseconds()
+		}
 		return cliutil.OutputJSON(data)
 	} else {
 		printInboxCreated(stored)
@@ -177,6 +330,30 @@ func runCreate(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// waitInboxReady polls the inbox status until the server answers for it,
+// returning how long that took.
+func waitInboxReady(ctx context.Context, inbox ReadinessChecker, timeout time.Duration) (time.Duration, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	for {
+		_, err := inbox.GetSyncStatus(ctx)
+		if err == nil {
+			return time.Since(start), nil
+		}
+
+		select {
+		case <-ctx.Done():
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return 0, fmt.Errorf("inbox not ready after %s: %w", timeout, err)
+			}
+			return 0, ctx.Err()
+		case <-time.After(readyPollInterval):
+		}
+	}
+}
+
 func printInboxCreated(inbox config.StoredInbox) {
 	// Title
 	title := styles.SuccessTitleStyle.Render("Inbox Ready!")
@@ -185,7 +362,7 @@ func printInboxCreated(inbox config.StoredInbox) {
 	emailBox := styles.EmailBoxStyle.Render(inbox.Email)
 
 	// Details
-	expiry := time.Until(inbox.ExpiresAt).Round(time.Hour)
+	expiry := inbox.ExpiresAt.Sub(config.ServerNow()).Round(time.Hour)
 	expiryStr := fmt.Sprintf("%v", expiry)
 
 	details := fmt.Sprintf(`
@@ -204,15 +381,175 @@ Run 'vsb' to see emails arrive live.`,
 	fmt.Println()
 }
 
-func parseTTL(s string) (time.Duration, error) {
-	// Handle days suffix (not supported by time.ParseDuration)
-	if strings.HasSuffix(s, "d") {
-		days := strings.TrimSuffix(s, "d")
-		n, err := strconv.Atoi(days)
-		if err != nil {
-			return 0, fmt.Errorf("invalid day value: %s", days)
+// printCreateDryRun shows the inbox that runCreate would request. Parts
+// that depend on the server, such as the random suffix and the default
+// domain, are shown as placeholders.
+func printCreateDryRun(ttl, readyTimeout time.Duration, jsonMode bool) error {
+	expiresAt := config.ServerNow().Add(ttl)
+
+	domain := createDomain
+	if createDomainRE != "" {
+		domain = "<first domain matching " + createDomainRE + ">"
+	}
+	localPart := "<random>"
+	if createPrefix != "" {
+		localPart = strings.TrimRight(createPrefix, ".-") + "-<random>"
+	}
+	address := localPart + "@"
+	if domain != "" {
+		address += domain
+	} else {
+		address += "<server domain>"
+	}
+	label := inboxLabel(resolveLabelPrefix(createLabelPrefix), address)
+
+	emailAuth := strings.ToLower(createEmailAuth)
+	encryption := strings.ToLower(createEncryption)
+
+	if jsonMode {
+		data := map[string]interface{}{
+			"dryRun":     true,
+			"address":    address,
+			"ttl":        ttl.String(),
+			"expiresAt":  expiresAt.Format(time.RFC3339),
+			"label":      label,
+			"emailAuth":  emailAuth,
+			"encryption": encryption,
+		}
+		if readyTimeout > 0 {
+			data["waitReady"] = readyTimeout.String()
 		}
-		return time.Duration(n) * 24 * time.Hour, nil
+		return cliutil.OutputJSON(data)
+	}
+
+	orDefault := func(v string) string {
+		if v == "" {
+			return "(server default)"
+		}
+		return v
+	}
+	if label == "" {
+		label = "(none)"
 	}
-	return time.ParseDuration(s)
+
+	fmt.Println(styles.WarningTitleStyle.Render("Dry run: no inbox was created"))
+	fmt.Println()
+	fmt.Printf("  Address:     %s\n", address)
+	fmt.Printf("  TTL:         %s\n", ttl)
+	fmt.Printf("  Expires:     %s\n", expiresAt.Format(time.RFC3339))
+	fmt.Printf("  Label:       %s\n", label)
+	fmt.Printf("  Email auth:  %s\n", orDefault(emailAuth))
+	fmt.Printf("  Encryption:  %s\n", orDefault(encryption))
+	if readyTimeout > 0 {
+		fmt.Printf("  Wait ready:  %s\n", readyTimeout)
+	}
+	return nil
+}
+
+// validatePrefix checks that a local-part prefix only uses lowercase
+// alphanumerics, dots and dashes.
+func validatePrefix(prefix string) error {
+	if len(prefix) > maxPrefixLength {
+		return fmt.Errorf("invalid --prefix: must be at most %d characters", maxPrefixLength)
+	}
+	if !prefixPattern.MatchString(prefix) {
+		return fmt.Errorf("invalid --prefix %q: use lowercase letters, digits, dots and dashes, starting with a letter or digit", prefix)
+	}
+	if strings.Contains(prefix, "..") {
+		return fmt.Errorf("invalid --prefix %q: consecutive dots are not allowed", prefix)
+	}
+	return nil
+}
+
+// buildPrefixedAddress returns prefix-<random>@<domain>. An empty domain
+// uses the server's first allowed domain.
+func buildPrefixedAddress(prefix, domain string, info *vaultsandbox.ServerInfo) (string, error) {
+	if domain == "" {
+		if info == nil || len(info.AllowedDomains) == 0 {
+			return "", fmt.Errorf("server does not support custom address prefixes (no allowed domains reported)")
+		}
+		domain = info.AllowedDomains[0]
+	}
+
+	suffix, err := randomSuffixFunc()
+	if err != nil {
+		return "", fmt.Errorf("failed to generate address suffix: %w", err)
+	}
+
+	prefix = strings.TrimRight(prefix, ".-")
+	return fmt.Sprintf("%s-%s@%s", prefix, suffix, domain), nil
+}
+
+// selectDomain returns the allowed domain matching domain (case-insensitive)
+// or the first one matching pattern. It returns "" when neither is set, so
+// the server picks the domain.
+func selectDomain(info *vaultsandbox.ServerInfo, domain string, pattern *regexp.Regexp) (string, error) {
+	if domain == "" && pattern == nil {
+		return "", nil
+	}
+
+	var available []string
+	if info != nil {
+		available = info.AllowedDomains
+	}
+
+	for _, d := range available {
+		if domain != "" && strings.EqualFold(d, domain) {
+			return d, nil
+		}
+		if pattern != nil && pattern.MatchString(d) {
+			return d, nil
+		}
+	}
+
+	requested := domain
+	if pattern != nil {
+		requested = "matching " + pattern.String()
+	}
+	if len(available) == 0 {
+		return "", fmt.Errorf("domain %s not available: server reports no allowed domains", requested)
+	}
+	return "", fmt.Errorf("domain %s not available (available: %s)", requested, strings.Join(available, ", "))
+}
+
+// isUnsupportedAddressStatus reports whether an API status means the
+// requested address was refused rather than a general failure.
+func isUnsupportedAddressStatus(status int) bool {
+	switch status {
+	case http.StatusBadRequest, http.StatusForbidden, http.StatusUnprocessableEntity:
+		return true
+	}
+	return false
+}
+
+// resolveCreateTTL applies flag > env > config file > built-in default
+// precedence to the inbox lifetime.
+func resolveCreateTTL(flagValue string) string {
+	if flagValue != "" {
+		return flagValue
+	}
+	return config.GetDefaultTTL()
+}
+
+// resolveLabelPrefix applies flag > env > config file precedence to the
+// label prefix.
+func resolveLabelPrefix(flagValue string) string {
+	if flagValue != "" {
+		return flagValue
+	}
+	return config.GetDefaultLabelPrefix()
+}
+
+// inboxLabel returns <prefix><local part> for a new inbox, or an empty label
+// when no prefix is configured.
+func inboxLabel(prefix, email string) string {
+	if prefix == "" {
+		return ""
+	}
+	local, _, _ := strings.Cut(email, "@")
+	return prefix + local
+}
+
+func parseTTL(s string) (time.Duration, error) {
+	return timeparse.Duration(s)
 }
@@ -3,9 +3,13 @@ package inbox
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"io"
 	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
 	"testing"
 	"time"
 
@@ -25,20 +29,52 @@ func (m *mockInbox) Export() *vaultsandbox.ExportedInbox {
 	return m.exported
 }
 
+// mockReadyInbox is a mockInbox that also implements ReadinessChecker,
+// failing the first failures checks
+type mockReadyInbox struct {
+	mockInbox
+	failures int
+	calls    int
+}
+
+func (m *mockReadyInbox) GetSyncStatus(ctx context.Context) (*vaultsandbox.SyncStatus, error) {
+	m.calls++
+	if m.calls <= m.failures {
+		return nil, errors.New("inbox not found")
+	}
+	return &vaultsandbox.SyncStatus{}, nil
+}
+
 // mockClient implements InboxCreator for testing
 type mockClient struct {
-	inbox     ExportableInbox
-	createErr error
-	closed    bool
+	inbox      ExportableInbox
+	createErr  error
+	closed     bool
+	serverInfo *vaultsandbox.ServerInfo
+	opts       []vaultsandbox.InboxOption
+	creates    int
+	deleted    []string
+	deleteErr  error
 }
 
 func (m *mockClient) CreateInbox(ctx context.Context, opts ...vaultsandbox.InboxOption) (ExportableInbox, error) {
+	m.creates++
+	m.opts = opts
 	if m.createErr != nil {
 		return nil, m.createErr
 	}
 	return m.inbox, nil
 }
 
+func (m *mockClient) DeleteInbox(ctx context.Context, emailAddress string) error {
+	m.deleted = append(m.deleted, emailAddress)
+	return m.deleteErr
+}
+
+func (m *mockClient) ServerInfo() *vaultsandbox.ServerInfo {
+	return m.serverInfo
+}
+
 func (m *mockClient) Close() error {
 	m.closed = true
 	return nil
@@ -92,6 +128,12 @@ func resetCreateTestState(oldClientFunc func() (InboxCreator, error), oldKeystor
 	newClientFunc = oldClientFunc
 	loadKeystoreFunc = oldKeystoreFunc
 	createTTL = oldTTL
+	createPrefix = ""
+	createWaitReady = ""
+	createDomain = ""
+	createDomainRE = ""
+	createLabelPrefix = ""
+	createDryRun = false
 }
 
 func TestParseTTL(t *testing.T) {
@@ -184,6 +226,9 @@ func TestPrintInboxCreated(t *testing.T) {
 }
 
 func TestRunCreate(t *testing.T) {
+	// Subtests that keep the real keystore loader must not touch the user's
+	t.Setenv("VSB_CONFIG_DIR", t.TempDir())
+
 	t.Run("creates inbox successfully", func(t *testing.T) {
 		oldClientFunc := newClientFunc
 		oldKeystoreFunc := loadKeystoreFunc
@@ -398,7 +443,58 @@ func TestRunCreate(t *testing.T) {
 			err := runCreate(cmd, []string{})
 			require.Error(t, err)
 			assert.Contains(t, err.Error(), "failed to save inbox")
+			assert.Contains(t, err.Error(), "deleted test@example.com from the server again")
 		})
+
+		// The inbox must not be left on the server without its keys
+		assert.Equal(t, []string{"test@example.com"}, mockCl.deleted)
+	})
+
+	t.Run("reports a failed rollback", func(t *testing.T) {
+		oldClientFunc := newClientFunc
+		oldKeystoreFunc := loadKeystoreFunc
+		oldTTL := createTTL
+		defer resetCreateTestState(oldClientFunc, oldKeystoreFunc, oldTTL)
+
+		createTTL = "24h"
+		mockKS := &mockKeystore{addErr: errors.New("disk full")}
+		mockCl := &mockClient{
+			inbox: &mockInbox{exported: &vaultsandbox.ExportedInbox{
+				EmailAddress: "test@example.com",
+				ExpiresAt:    time.Now().Add(24 * time.Hour),
+			}},
+			deleteErr: errors.New("network down"),
+		}
+		newClientFunc = func() (InboxCreator, error) { return mockCl, nil }
+		loadKeystoreFunc = func() (KeystoreWriter, error) { return mockKS, nil }
+
+		captureCreateStdout(t, func() {
+			err := runCreate(createTestCommand(), []string{})
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "could not be deleted: network down")
+		})
+		assert.Equal(t, []string{"test@example.com"}, mockCl.deleted)
+	})
+
+	t.Run("keystore error stops before the server call", func(t *testing.T) {
+		oldClientFunc := newClientFunc
+		oldKeystoreFunc := loadKeystoreFunc
+		oldTTL := createTTL
+		defer resetCreateTestState(oldClientFunc, oldKeystoreFunc, oldTTL)
+
+		// A config dir below a regular file cannot be created, even by root
+		file := filepath.Join(t.TempDir(), "file")
+		require.NoError(t, os.WriteFile(file, nil, 0600))
+		t.Setenv("VSB_CONFIG_DIR", filepath.Join(file, "vsb"))
+
+		createTTL = "24h"
+		mockCl := &mockClient{inbox: &mockInbox{}}
+		newClientFunc = func() (InboxCreator, error) { return mockCl, nil }
+
+		err := runCreate(createTestCommand(), []string{})
+		assert.ErrorContains(t, err, "failed to load keystore")
+		assert.Zero(t, mockCl.creates, "no inbox may be created on the server")
+		assert.Empty(t, mockCl.deleted)
 	})
 
 	t.Run("shows progress messages in non-JSON mode", func(t *testing.T) {
@@ -518,4 +614,491 @@ func TestRunCreate(t *testing.T) {
 
 		assert.NotNil(t, mockKS.addedInbox)
 	})
+
+	t.Run("labels inbox from default label prefix", func(t *testing.T) {
+		oldClientFunc := newClientFunc
+		oldKeystoreFunc := loadKeystoreFunc
+		oldTTL := createTTL
+		defer resetCreateTestState(oldClientFunc, oldKeystoreFunc, oldTTL)
+
+		t.Setenv("VSB_DEFAULT_LABEL_PREFIX", "ci-")
+		createTTL = "24h"
+
+		mockKS := &mockKeystore{}
+		mockInb := &mockInbox{
+			exported: &vaultsandbox.ExportedInbox{
+				Version:      1,
+				EmailAddress: "abc123@example.com",
+				InboxHash:    "hash",
+				ExpiresAt:    time.Now().Add(24 * time.Hour),
+				ExportedAt:   time.Now(),
+				SecretKey:    "key",
+				ServerSigPk:  "sig",
+			},
+		}
+		newClientFunc = func() (InboxCreator, error) {
+			return &mockClient{inbox: mockInb}, nil
+		}
+		loadKeystoreFunc = func() (KeystoreWriter, error) {
+			return mockKS, nil
+		}
+
+		cmd := createTestCommand()
+		captureCreateStdout(t, func() {
+			require.NoError(t, runCreate(cmd, []string{}))
+		})
+
+		require.NotNil(t, mockKS.addedInbox)
+		assert.Equal(t, "ci-abc123", mockKS.addedInbox.Label)
+	})
+}
+
+func TestResolveCreateTTL(t *testing.T) {
+	t.Run("flag wins over env", func(t *testing.T) {
+		t.Setenv("VSB_DEFAULT_TTL", "2h")
+		assert.Equal(t, "1h", resolveCreateTTL("1h"))
+	})
+
+	t.Run("env used without flag", func(t *testing.T) {
+		t.Setenv("VSB_DEFAULT_TTL", "2h")
+		assert.Equal(t, "2h", resolveCreateTTL(""))
+	})
+
+	t.Run("built-in default", func(t *testing.T) {
+		t.Setenv("VSB_DEFAULT_TTL", "")
+		assert.Equal(t, config.DefaultTTL, resolveCreateTTL(""))
+	})
+}
+
+func TestResolveLabelPrefix(t *testing.T) {
+	t.Setenv("VSB_DEFAULT_LABEL_PREFIX", "ci-")
+	assert.Equal(t, "local-", resolveLabelPrefix("local-"))
+	assert.Equal(t, "ci-", resolveLabelPrefix(""))
+}
+
+func TestInboxLabel(t *testing.T) {
+	assert.Equal(t, "ci-abc123", inboxLabel("ci-", "abc123@vsx.email"))
+	assert.Empty(t, inboxLabel("", "abc123@vsx.email"))
+}
+
+func TestValidatePrefix(t *testing.T) {
+	valid := []string{"signup-test", "ci.run-42", "a", "abc123"}
+	for _, p := range valid {
+		t.Run("valid "+p, func(t *testing.T) {
+			assert.NoError(t, validatePrefix(p))
+		})
+	}
+
+	invalid := []string{"Signup", "-leading", ".leading", "has space", "under_score", "a..b", "plus+tag", strings.Repeat("a", 33)}
+	for _, p := range invalid {
+		t.Run("invalid "+p, func(t *testing.T) {
+			assert.Error(t, validatePrefix(p))
+		})
+	}
+}
+
+func TestBuildPrefixedAddress(t *testing.T) {
+	oldSuffix := randomSuffixFunc
+	defer func() { randomSuffixFunc = oldSuffix }()
+	randomSuffixFunc = func() (string, error) { return "abcd1234", nil }
+
+	t.Run("uses first allowed domain", func(t *testing.T) {
+		info := &vaultsandbox.ServerInfo{AllowedDomains: []string{"vsx.email", "other.email"}}
+		addr, err := buildPrefixedAddress("signup-test", "", info)
+		require.NoError(t, err)
+		assert.Equal(t, "signup-test-abcd1234@vsx.email", addr)
+	})
+
+	t.Run("trims trailing separators", func(t *testing.T) {
+		info := &vaultsandbox.ServerInfo{AllowedDomains: []string{"vsx.email"}}
+		addr, err := buildPrefixedAddress("ci-", "", info)
+		require.NoError(t, err)
+		assert.Equal(t, "ci-abcd1234@vsx.email", addr)
+	})
+
+	t.Run("errors without allowed domains", func(t *testing.T) {
+		_, err := buildPrefixedAddress("ci", "", &vaultsandbox.ServerInfo{})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "does not support custom address prefixes")
+	})
+}
+
+func TestRunCreateWithPrefix(t *testing.T) {
+	oldSuffix := randomSuffixFunc
+	defer func() { randomSuffixFunc = oldSuffix }()
+	randomSuffixFunc = func() (string, error) { return "abcd1234", nil }
+
+	t.Run("passes address option and keeps prefix in output", func(t *testing.T) {
+		oldClientFunc := newClientFunc
+		oldKeystoreFunc := loadKeystoreFunc
+		oldTTL := createTTL
+		defer resetCreateTestState(oldClientFunc, oldKeystoreFunc, oldTTL)
+
+		createTTL = "24h"
+		createPrefix = "signup-test"
+
+		mockKS := &mockKeystore{}
+		mockCl := &mockClient{
+			serverInfo: &vaultsandbox.ServerInfo{AllowedDomains: []string{"vsx.email"}},
+			inbox: &mockInbox{
+				exported: &vaultsandbox.ExportedInbox{
+					Version:      1,
+					EmailAddress: "signup-test-abcd1234@vsx.email",
+					ExpiresAt:    time.Now().Add(24 * time.Hour),
+					ExportedAt:   time.Now(),
+				},
+			},
+		}
+		newClientFunc = func() (InboxCreator, error) { return mockCl, nil }
+		loadKeystoreFunc = func() (KeystoreWriter, error) { return mockKS, nil }
+
+		cmd := createTestCommand()
+		cmd.Flags().Set("output", "json")
+		output := captureCreateStdout(t, func() {
+			require.NoError(t, runCreate(cmd, []string{}))
+		})
+
+		// TTL + email address
+		assert.Len(t, mockCl.opts, 2)
+		assert.Contains(t, output, "signup-test-abcd1234@vsx.email")
+		assert.Equal(t, "signup-test-abcd1234@vsx.email", mockKS.addedInbox.Email)
+	})
+
+	t.Run("invalid prefix fails before contacting server", func(t *testing.T) {
+		oldClientFunc := newClientFunc
+		oldKeystoreFunc := loadKeystoreFunc
+		oldTTL := createTTL
+		defer resetCreateTestState(oldClientFunc, oldKeystoreFunc, oldTTL)
+
+		createTTL = "24h"
+		createPrefix = "Bad_Prefix"
+		called := false
+		newClientFunc = func() (InboxCreator, error) {
+			called = true
+			return &mockClient{}, nil
+		}
+
+		err := runCreate(createTestCommand(), []string{})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid --prefix")
+		assert.False(t, called)
+	})
+
+	t.Run("reports unsupported server", func(t *testing.T) {
+		oldClientFunc := newClientFunc
+		oldKeystoreFunc := loadKeystoreFunc
+		oldTTL := createTTL
+		defer resetCreateTestState(oldClientFunc, oldKeystoreFunc, oldTTL)
+
+		createTTL = "24h"
+		createPrefix = "ci"
+		mockCl := &mockClient{
+			serverInfo: &vaultsandbox.ServerInfo{AllowedDomains: []string{"vsx.email"}},
+			createErr:  &vaultsandbox.APIError{StatusCode: 403, Message: "custom addresses not allowed"},
+		}
+		newClientFunc = func() (InboxCreator, error) { return mockCl, nil }
+
+		captureCreateStdout(t, func() {
+			err := runCreate(createTestCommand(), []string{})
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "server does not support custom address prefixes")
+		})
+	})
+}
+
+func TestWaitInboxReady(t *testing.T) {
+	oldInterval := readyPollInterval
+	readyPollInterval = time.Millisecond
+	defer func() { readyPollInterval = oldInterval }()
+
+	t.Run("ready immediately", func(t *testing.T) {
+		inbox := &mockReadyInbox{}
+		_, err := waitInboxReady(context.Background(), inbox, time.Second)
+		require.NoError(t, err)
+		assert.Equal(t, 1, inbox.calls)
+	})
+
+	t.Run("retries until ready", func(t *testing.T) {
+		inbox := &mockReadyInbox{failures: 3}
+		_, err := waitInboxReady(context.Background(), inbox, time.Second)
+		require.NoError(t, err)
+		assert.Equal(t, 4, inbox.calls)
+	})
+
+	t.Run("times out with last error", func(t *testing.T) {
+		inbox := &mockReadyInbox{failures: 1 << 30}
+		_, err := waitInboxReady(context.Background(), inbox, 20*time.Millisecond)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "inbox not ready after 20ms")
+		assert.Contains(t, err.Error(), "inbox not found")
+	})
+}
+
+func TestRunCreateWaitReady(t *testing.T) {
+	oldClientFunc := newClientFunc
+	oldKeystoreFunc := loadKeystoreFunc
+	oldTTL := createTTL
+	oldInterval := readyPollInterval
+	defer resetCreateTestState(oldClientFunc, oldKeystoreFunc, oldTTL)
+	defer func() { readyPollInterval = oldInterval }()
+
+	readyPollInterval = time.Millisecond
+	createTTL = "24h"
+	createWaitReady = "1s"
+
+	mockKS := &mockKeystore{}
+	mockInb := &mockReadyInbox{
+		mockInbox: mockInbox{exported: &vaultsandbox.ExportedInbox{
+			EmailAddress: "ready@example.com",
+			ExpiresAt:    time.Now().Add(24 * time.Hour),
+			ExportedAt:   time.Now(),
+		}},
+		failures: 2,
+	}
+	newClientFunc = func() (InboxCreator, error) {
+		return &mockClient{inbox: mockInb}, nil
+	}
+	loadKeystoreFunc = func() (KeystoreWriter, error) {
+		return mockKS, nil
+	}
+
+	cmd := createTestCommand()
+	cmd.Flags().Set("output", "json")
+	output := captureCreateStdout(t, func() {
+		require.NoError(t, runCreate(cmd, []string{}))
+	})
+
+	assert.Equal(t, 3, mockInb.calls)
+	assert.NotNil(t, mockKS.addedInbox)
+	assert.Contains(t, output, `"readyAfterMs"`)
+
+	t.Run("invalid timeout", func(t *testing.T) {
+		createWaitReady = "soon"
+		err := runCreate(createTestCommand(), []string{})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid --wait-ready timeout")
+	})
+}
+
+func TestSelectDomain(t *testing.T) {
+	info := &vaultsandbox.ServerInfo{AllowedDomains: []string{"vsx.email", "team-a.example.com", "team-b.example.com"}}
+
+	t.Run("no selection leaves domain to server", func(t *testing.T) {
+		domain, err := selectDomain(info, "", nil)
+		require.NoError(t, err)
+		assert.Empty(t, domain)
+	})
+
+	t.Run("exact domain", func(t *testing.T) {
+		domain, err := selectDomain(info, "TEAM-B.example.com", nil)
+		require.NoError(t, err)
+		assert.Equal(t, "team-b.example.com", domain)
+	})
+
+	t.Run("first domain matching pattern", func(t *testing.T) {
+		domain, err := selectDomain(info, "", regexp.MustCompile(`^team-`))
+		require.NoError(t, err)
+		assert.Equal(t, "team-a.example.com", domain)
+	})
+
+	t.Run("unavailable domain lists available", func(t *testing.T) {
+		_, err := selectDomain(info, "other.example.com", nil)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "other.example.com not available")
+		assert.Contains(t, err.Error(), "vsx.email, team-a.example.com, team-b.example.com")
+	})
+
+	t.Run("no pattern match", func(t *testing.T) {
+		_, err := selectDomain(info, "", regexp.MustCompile(`^nope`))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "matching ^nope")
+	})
+
+	t.Run("server without domains", func(t *testing.T) {
+		_, err := selectDomain(&vaultsandbox.ServerInfo{}, "vsx.email", nil)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "no allowed domains")
+	})
+}
+
+func TestBuildPrefixedAddressWithDomain(t *testing.T) {
+	oldSuffix := randomSuffixFunc
+	defer func() { randomSuffixFunc = oldSuffix }()
+	randomSuffixFunc = func() (string, error) { return "abcd1234", nil }
+
+	info := &vaultsandbox.ServerInfo{AllowedDomains: []string{"vsx.email", "team-a.example.com"}}
+	addr, err := buildPrefixedAddress("ci", "team-a.example.com", info)
+	require.NoError(t, err)
+	assert.Equal(t, "ci-abcd1234@team-a.example.com", addr)
+}
+
+func TestRunCreateWithDomain(t *testing.T) {
+	serverInfo := &vaultsandbox.ServerInfo{AllowedDomains: []string{"vsx.email", "team-a.example.com"}}
+
+	t.Run("passes domain option", func(t *testing.T) {
+		oldClientFunc := newClientFunc
+		oldKeystoreFunc := loadKeystoreFunc
+		oldTTL := createTTL
+		defer resetCreateTestState(oldClientFunc, oldKeystoreFunc, oldTTL)
+
+		createTTL = "24h"
+		createDomain = "team-a.example.com"
+
+		mockKS := &mockKeystore{}
+		mockCl := &mockClient{
+			serverInfo: serverInfo,
+			inbox: &mockInbox{
+				exported: &vaultsandbox.ExportedInbox{
+					Version:      1,
+					EmailAddress: "x1y2@team-a.example.com",
+					ExpiresAt:    time.Now().Add(24 * time.Hour),
+					ExportedAt:   time.Now(),
+				},
+			},
+		}
+		newClientFunc = func() (InboxCreator, error) { return mockCl, nil }
+		loadKeystoreFunc = func() (KeystoreWriter, error) { return mockKS, nil }
+
+		cmd := createTestCommand()
+		cmd.Flags().Set("output", "json")
+		captureCreateStdout(t, func() {
+			require.NoError(t, runCreate(cmd, []string{}))
+		})
+
+		// TTL + email address
+		assert.Len(t, mockCl.opts, 2)
+		assert.Equal(t, "x1y2@team-a.example.com", mockKS.addedInbox.Email)
+	})
+
+	t.Run("unavailable domain fails before creating", func(t *testing.T) {
+		oldClientFunc := newClientFunc
+		oldKeystoreFunc := loadKeystoreFunc
+		oldTTL := createTTL
+		defer resetCreateTestState(oldClientFunc, oldKeystoreFunc, oldTTL)
+
+		createTTL = "24h"
+		createDomain = "other.example.com"
+		mockCl := &mockClient{serverInfo: serverInfo}
+		newClientFunc = func() (InboxCreator, error) { return mockCl, nil }
+
+		cmd := createTestCommand()
+		cmd.Flags().Set("output", "json")
+		err := runCreate(cmd, []string{})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "available: vsx.email, team-a.example.com")
+		assert.Nil(t, mockCl.opts)
+	})
+
+	t.Run("invalid regex fails before contacting server", func(t *testing.T) {
+		oldClientFunc := newClientFunc
+		oldKeystoreFunc := loadKeystoreFunc
+		oldTTL := createTTL
+		defer resetCreateTestState(oldClientFunc, oldKeystoreFunc, oldTTL)
+
+		createTTL = "24h"
+		createDomainRE = "("
+		called := false
+		newClientFunc = func() (InboxCreator, error) {
+			called = true
+			return &mockClient{}, nil
+		}
+
+		err := runCreate(createTestCommand(), []string{})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid --domain-regex")
+		assert.False(t, called)
+	})
+}
+
+func TestRunCreateDryRun(t *testing.T) {
+	oldClientFunc := newClientFunc
+	oldKeystoreFunc := loadKeystoreFunc
+	oldTTL := createTTL
+	defer resetCreateTestState(oldClientFunc, oldKeystoreFunc, oldTTL)
+	defer func() { createEncryption = "" }()
+
+	newClientFunc = func() (InboxCreator, error) {
+		t.Fatal("dry run must not contact the server")
+		return nil, nil
+	}
+	loadKeystoreFunc = func() (KeystoreWriter, error) {
+		t.Fatal("dry run must not write the keystore")
+		return nil, nil
+	}
+
+	t.Run("prints the would-be request", func(t *testing.T) {
+		createDryRun = true
+		createTTL = "7d"
+		createPrefix = "signup"
+		createDomain = "sandboxmail.example.com"
+		createLabelPrefix = "ci-"
+		createEncryption = "Plain"
+		defer func() { createPrefix, createDomain, createLabelPrefix, createEncryption = "", "", "", "" }()
+
+		output := captureCreateStdout(t, func() {
+			require.NoError(t, runCreate(createTestCommand(), nil))
+		})
+		assert.Contains(t, output, "Dry run: no inbox was created")
+		assert.Contains(t, output, "signup-<random>@sandboxmail.example.com")
+		assert.Contains(t, output, "168h0m0s")
+		assert.Contains(t, output, "ci-signup-<random>")
+		assert.Contains(t, output, "Encryption:  plain")
+		assert.Contains(t, output, "Email auth:  (server default)")
+	})
+
+	t.Run("JSON output", func(t *testing.T) {
+		createDryRun = true
+		createTTL = "2h"
+		createDomainRE = `^team-a\.`
+
+		cmd := createTestCommand()
+		cmd.Flags().Set("output", "json")
+		output := captureCreateStdout(t, func() {
+			require.NoError(t, runCreate(cmd, nil))
+		})
+
+		var data map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(output), &data))
+		assert.Equal(t, true, data["dryRun"])
+		assert.Equal(t, `<random>@<first domain matching ^team-a\.>`, data["address"])
+		assert.Equal(t, "2h0m0s", data["ttl"])
+		expiresAt, err := time.Parse(time.RFC3339, data["expiresAt"].(string))
+		require.NoError(t, err)
+		assert.WithinDuration(t, time.Now().Add(2*time.Hour), expiresAt, time.Minute)
+	})
+
+	t.Run("invalid input still fails", func(t *testing.T) {
+		createDryRun = true
+		createDomainRE = ""
+
+		createTTL = "soon"
+		assert.ErrorContains(t, runCreate(createTestCommand(), nil), "invalid TTL format")
+
+		createTTL = "1h"
+		createPrefix = "Bad_Prefix"
+		assert.ErrorContains(t, runCreate(createTestCommand(), nil), "invalid --prefix")
+		createPrefix = ""
+
+		createEncryption = "rot13"
+		assert.ErrorContains(t, runCreate(createTestCommand(), nil), "invalid --encryption")
+	})
+}
+
+func TestRunListDomains(t *testing.T) {
+	oldClientFunc := newClientFunc
+	defer func() { newClientFunc = oldClientFunc }()
+
+	mockCl := &mockClient{serverInfo: &vaultsandbox.ServerInfo{AllowedDomains: []string{"vsx.email", "team-a.example.com"}}}
+	newClientFunc = func() (InboxCreator, error) { return mockCl, nil }
+
+	cmd := createTestCommand()
+	cmd.Flags().Set("output", "json")
+	output := captureCreateStdout(t, func() {
+		require.NoError(t, runListDomains(cmd, nil))
+	})
+
+	var domains []string
+	require.NoError(t, json.Unmarshal([]byte(output), &domains))
+	assert.Equal(t, []string{"vsx.email", "team-a.example.com"}, domains)
+	assert.True(t, mockCl.closed)
 }
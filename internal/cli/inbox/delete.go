@@ -1,48 +1,163 @@
 package inbox
 
 import (
+	"bufio"
 	"context"
 	"errors"
 	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
+	vaultsandbox "github.com/vaultsandbox/client-go"
 	"github.com/vaultsandbox/vsb-cli/internal/cliutil"
 	"github.com/vaultsandbox/vsb-cli/internal/config"
 	"github.com/vaultsandbox/vsb-cli/internal/styles"
 )
 
 var deleteCmd = &cobra.Command{
-	Use:   "delete <email>",
-	Short: "Delete an inbox",
-	Long: `Delete an inbox from both the server and local keystore.
+	Use:   "delete [email...]",
+	Short: "Delete one or more inboxes",
+	Long: `Delete inboxes from both the server and local keystore.
 
-Supports partial matching - if only one inbox contains the given string,
-it will be deleted automatically.
+Supports partial matching (exact address, label, start of the address,
+then anywhere in the address) - if only one inbox matches, it will be
+deleted automatically.
+
+Multiple inboxes can be deleted at once by passing several arguments or
+by using --all, --expired or --label-prefix. Arguments and --all add
+inboxes, while --expired and --label-prefix narrow each other: together
+they only remove expired entries with that label prefix. Deleting more than
+one inbox asks for confirmation unless --yes is given. Every inbox is
+attempted and reported individually, followed by a summary; the command
+exits non-zero if any deletion failed. Server deletions are spaced out so clearing many inboxes
+doesn't trip the API rate limit.
 
 Examples:
   vsb inbox delete test@abc123.vsx.email
   vsb inbox delete abc       # Partial match
-  vsb inbox delete abc -l    # Local only (don't delete on server)`,
+  vsb inbox delete abc -l    # Local only (don't delete on server)
+  vsb inbox delete abc def   # Delete several inboxes
+  vsb inbox delete --all --yes
+  vsb inbox delete --expired
+  vsb inbox delete --label-prefix ci- --yes`,
 	Aliases: []string{"rm"},
-	Args:    cobra.ExactArgs(1),
 	RunE:    runDelete,
 }
 
 var (
-	deleteLocal bool
+	deleteLocal       bool
+	deleteAll         bool
+	deleteExpired     bool
+	deleteLabelPrefix string
+	deleteYes         bool
 )
 
 func init() {
 	Cmd.AddCommand(deleteCmd)
+	cliutil.AddOutputFormats(deleteCmd, cliutil.FormatJSON)
+	cliutil.MarkSavesState(deleteCmd)
 
 	deleteCmd.Flags().BoolVarP(&deleteLocal, "local", "l", false,
 		"Only remove from local keystore, don't delete on server")
+	deleteCmd.Flags().BoolVar(&deleteAll, "all", false,
+		"Delete every inbox in the keystore")
+	deleteCmd.Flags().BoolVar(&deleteExpired, "expired", false,
+		"Remove expired inboxes from the keystore (server call skipped)")
+	deleteCmd.Flags().StringVar(&deleteLabelPrefix, "label-prefix", "",
+		"Delete inboxes whose label starts with this prefix")
+	deleteCmd.Flags().BoolVarP(&deleteYes, "yes", "y", false,
+		"Skip confirmation when deleting multiple inboxes")
+}
+
+// serverDeleteInterval is the minimum time between server deletions in a
+// batch (overridden in tests)
+var serverDeleteInterval = 100 * time.Millisecond
+
+// deleteResult is the outcome of deleting a single inbox
+type deleteResult struct {
+	Email   string `json:"email"`
+	Success bool   `json:"success"`
+	Server  bool   `json:"server"` // deleted on server
+	Local   bool   `json:"local"`  // removed from keystore
+	Error   string `json:"error,omitempty"`
+	Warning string `json:"warning,omitempty"` // single inbox: server deletion failed
+}
+
+// deleteTarget is an inbox selected for deletion
+type deleteTarget struct {
+	Email      string
+	Err        error // resolution error, reported as a failed result
+	SkipServer bool  // expired entries are only removed locally
+	Pruned     bool  // already removed from keystore on load
 }
 
 func runDelete(cmd *cobra.Command, args []string) error {
-	ctx := context.Background()
-	partial := args[0]
+	batch := len(args) > 1 || deleteAll || deleteExpired || deleteLabelPrefix != ""
+	if !batch {
+		if len(args) == 0 {
+			return fmt.Errorf("specify an inbox to delete, or use --all, --expired or --label-prefix")
+		}
+		return runDeleteSingle(cliutil.CommandContext(cmd), args[0], cliutil.GetOutput(cmd) == cliutil.FormatJSON)
+	}
+
+	ks, err := cliutil.LoadKeystoreOrError()
+	if err != nil {
+		return err
+	}
+
+	// --expired and --label-prefix narrow each other: with --expired the
+	// prefix only selects among the expired entries
+	var targets []deleteTarget
+	if deleteExpired {
+		targets = selectDeleteTargets(ks, args, deleteAll, "")
+		targets = appendExpiredTargets(targets, ks.PrunedInboxes(), ks.ListInboxes(), deleteLabelPrefix)
+	} else {
+		targets = selectDeleteTargets(ks, args, deleteAll, deleteLabelPrefix)
+	}
+
+	if len(targets) == 0 {
+		if cliutil.GetOutput(cmd) == cliutil.FormatJSON {
+			return cliutil.OutputJSON([]deleteResult{})
+		}
+		fmt.Println(styles.MutedStyle.Render("No inboxes matched."))
+		return nil
+	}
+
+	if len(targets) > 1 && !deleteYes {
+		if !confirmDelete(os.Stdin, os.Stderr, targets) {
+			return fmt.Errorf("deletion cancelled")
+		}
+	}
 
+	results := deleteInboxes(cliutil.CommandContext(cmd), ks, targets, deleteLocal)
+
+	if cliutil.GetOutput(cmd) == cliutil.FormatJSON {
+		if err := cliutil.OutputJSON(results); err != nil {
+			return err
+		}
+	} else {
+		printDeleteResults(results)
+	}
+
+	failed := 0
+	for _, r := range results {
+		if !r.Success {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("failed to delete %d of %d inboxes", failed, len(results))
+	}
+	return nil
+}
+
+// runDeleteSingle deletes a single inbox. Server failures are reported as a
+// warning and the inbox is still removed from the keystore. With jsonOut the
+// result is printed as a one-element array, like a batch.
+func runDeleteSingle(ctx context.Context, partial string, jsonOut bool) error {
 	ks, err := cliutil.LoadKeystoreOrError()
 	if err != nil {
 		return err
@@ -53,6 +168,7 @@ func runDelete(cmd *cobra.Command, args []string) error {
 		return err
 	}
 	email := inbox.Email
+	result := deleteResult{Email: email}
 
 	// Delete from server unless --local
 	if !deleteLocal {
@@ -64,9 +180,15 @@ func runDelete(cmd *cobra.Command, args []string) error {
 
 		if err := client.DeleteInbox(ctx, email); err != nil {
 			// Continue with local deletion even if server fails
-			fmt.Println(styles.FailStyle.Render(fmt.Sprintf("✗ Warning: server deletion failed: %v", err)))
+			result.Warning = fmt.Sprintf("server deletion failed: %v", err)
+			if !jsonOut {
+				fmt.Println(styles.FailStyle.Render("✗ Warning: " + result.Warning))
+			}
 		} else {
-			fmt.Println(styles.PassStyle.Render("✓ Deleted from server"))
+			result.Server = true
+			if !jsonOut {
+				fmt.Println(styles.PassStyle.Render("✓ Deleted from server"))
+			}
 		}
 	}
 
@@ -78,6 +200,187 @@ func runDelete(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	result.Local, result.Success = true, true
+
+	if jsonOut {
+		return cliutil.OutputJSON([]deleteResult{result})
+	}
 	fmt.Println(styles.PassStyle.Render("✓ Deleted from keystore"))
 	return nil
 }
+
+// selectDeleteTargets resolves arguments and filters into a deduplicated list
+// of inboxes. Arguments that cannot be resolved become failed targets so the
+// remaining inboxes are still attempted.
+func selectDeleteTargets(ks cliutil.KeystoreReader, args []string, all bool, labelPrefix string) []deleteTarget {
+	var targets []deleteTarget
+	seen := make(map[string]bool)
+
+	add := func(t deleteTarget) {
+		if t.Email != "" {
+			if seen[t.Email] {
+				return
+			}
+			seen[t.Email] = true
+		}
+		targets = append(targets, t)
+	}
+
+	for _, arg := range args {
+		inbox, err := cliutil.GetInbox(ks, arg)
+		if err != nil {
+			add(deleteTarget{Email: arg, Err: err})
+			continue
+		}
+		add(deleteTarget{Email: inbox.Email})
+	}
+
+	for _, inbox := range ks.ListInboxes() {
+		if all || (labelPrefix != "" && strings.HasPrefix(inbox.Label, labelPrefix)) {
+			add(deleteTarget{Email: inbox.Email})
+		}
+	}
+
+	return targets
+}
+
+// appendExpiredTargets adds expired inboxes as local-only targets, only those
+// whose label starts with labelPrefix if it is set. Inboxes pruned when the
+// keystore was loaded are already gone from disk.
+func appendExpiredTargets(targets []deleteTarget, pruned, stored []config.StoredInbox, labelPrefix string) []deleteTarget {
+	seen := make(map[string]bool, len(targets))
+	for _, t := range targets {
+		seen[t.Email] = true
+	}
+
+	matches := func(inbox config.StoredInbox) bool {
+		return !seen[inbox.Email] && strings.HasPrefix(inbox.Label, labelPrefix)
+	}
+	for _, inbox := range pruned {
+		if matches(inbox) {
+			seen[inbox.Email] = true
+			targets = append(targets, deleteTarget{Email: inbox.Email, SkipServer: true, Pruned: true})
+		}
+	}
+	for _, inbox := range stored {
+		if cliutil.IsExpired(inbox.ExpiresAt) && matches(inbox) {
+			seen[inbox.Email] = true
+			targets = append(targets, deleteTarget{Email: inbox.Email, SkipServer: true})
+		}
+	}
+	return targets
+}
+
+// confirmDelete lists the targets and asks for confirmation.
+func confirmDelete(r io.Reader, w io.Writer, targets []deleteTarget) bool {
+	fmt.Fprintf(w, "The following %d inboxes will be deleted:\n", len(targets))
+	for _, t := range targets {
+		fmt.Fprintf(w, "  %s\n", t.Email)
+	}
+	fmt.Fprint(w, "Continue? [y/N]: ")
+
+	answer, _ := bufio.NewReader(r).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
+// deleteInboxes deletes each target and collects the results. In batch mode
+// a failed server deletion keeps the keystore entry so it can be retried.
+func deleteInboxes(ctx context.Context, ks cliutil.Keystore, targets []deleteTarget, localOnly bool) []deleteResult {
+	var client *vaultsandbox.Client
+	var clientErr error
+	defer func() {
+		if client != nil {
+			client.Close()
+		}
+	}()
+
+	var lastServerCall time.Time
+	results := make([]deleteResult, 0, len(targets))
+	for _, t := range targets {
+		result := deleteResult{Email: t.Email}
+
+		if t.Err != nil {
+			result.Error = t.Err.Error()
+			results = append(results, result)
+			continue
+		}
+
+		if !localOnly && !t.SkipServer {
+			if client == nil && clientErr == nil {
+				client, clientErr = config.NewClient()
+			}
+			if clientErr != nil {
+				result.Error = clientErr.Error()
+				results = append(results, result)
+				continue
+			}
+			if wait := serverDeleteInterval - time.Since(lastServerCall); !lastServerCall.IsZero() && wait > 0 {
+				if err := sleepContext(ctx, wait); err != nil {
+					result.Error = fmt.Sprintf("server deletion failed: %v", err)
+					results = append(results, result)
+					continue
+				}
+			}
+			lastServerCall = time.Now()
+			if err := client.DeleteInbox(ctx, t.Email); err != nil {
+				result.Error = fmt.Sprintf("server deletion failed: %v", err)
+				results = append(results, result)
+				continue
+			}
+			result.Server = true
+		}
+
+		if !t.Pruned {
+			if err := ks.RemoveInbox(t.Email); err != nil {
+				result.Error = fmt.Sprintf("keystore removal failed: %v", err)
+				results = append(results, result)
+				continue
+			}
+		}
+		result.Local = true
+		result.Success = true
+		results = append(results, result)
+	}
+	return results
+}
+
+// sleepContext waits for d, or returns ctx's error if it is done first
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func printDeleteResults(results []deleteResult) {
+	for _, r := range results {
+		if !r.Success {
+			fmt.Println(styles.FailStyle.Render(fmt.Sprintf("✗ %s: %s", r.Email, r.Error)))
+			continue
+		}
+		where := "keystore"
+		if r.Server {
+			where = "server and keystore"
+		}
+		fmt.Println(styles.PassStyle.Render(fmt.Sprintf("✓ %s: deleted from %s", r.Email, where)))
+	}
+
+	fmt.Println()
+	fmt.Println(deleteSummary(results))
+}
+
+// deleteSummary counts deleted and failed inboxes.
+func deleteSummary(results []deleteResult) string {
+	failed := 0
+	for _, r := range results {
+		if !r.Success {
+			failed++
+		}
+	}
+	return fmt.Sprintf("Deleted %d of %d inboxes, %d failed", len(results)-failed, len(results), failed)
+}
@@ -0,0 +1,232 @@
+package inbox
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vaultsandbox/vsb-cli/internal/cliutil"
+	"github.com/vaultsandbox/vsb-cli/internal/config"
+)
+
+func targetEmails(targets []deleteTarget) []string {
+	emails := make([]string, len(targets))
+	for i, t := range targets {
+		emails[i] = t.Email
+	}
+	return emails
+}
+
+func TestSelectDeleteTargets(t *testing.T) {
+	future := time.Now().Add(time.Hour)
+	ks := &cliutil.MockKeystore{
+		Inboxes: []config.StoredInbox{
+			{Email: "alpha@example.com", Label: "ci-1", ExpiresAt: future},
+			{Email: "beta@example.com", Label: "ci-2", ExpiresAt: future},
+			{Email: "gamma@example.com", Label: "manual", ExpiresAt: future},
+		},
+	}
+
+	t.Run("resolves multiple arguments", func(t *testing.T) {
+		targets := selectDeleteTargets(ks, []string{"alpha", "gamma"}, false, "")
+		assert.Equal(t, []string{"alpha@example.com", "gamma@example.com"}, targetEmails(targets))
+	})
+
+	t.Run("unresolved argument becomes failed target", func(t *testing.T) {
+		targets := selectDeleteTargets(ks, []string{"missing", "beta"}, false, "")
+		require.Len(t, targets, 2)
+		assert.Error(t, targets[0].Err)
+		assert.NoError(t, targets[1].Err)
+		assert.Equal(t, "beta@example.com", targets[1].Email)
+	})
+
+	t.Run("all selects every inbox", func(t *testing.T) {
+		targets := selectDeleteTargets(ks, nil, true, "")
+		assert.Len(t, targets, 3)
+	})
+
+	t.Run("label prefix filter", func(t *testing.T) {
+		targets := selectDeleteTargets(ks, nil, false, "ci-")
+		assert.Equal(t, []string{"alpha@example.com", "beta@example.com"}, targetEmails(targets))
+	})
+
+	t.Run("deduplicates arguments and filters", func(t *testing.T) {
+		targets := selectDeleteTargets(ks, []string{"alpha", "alpha@example.com"}, false, "ci-")
+		assert.Equal(t, []string{"alpha@example.com", "beta@example.com"}, targetEmails(targets))
+	})
+}
+
+func TestAppendExpiredTargets(t *testing.T) {
+	past := time.Now().Add(-time.Hour)
+	future := time.Now().Add(time.Hour)
+
+	pruned := []config.StoredInbox{{Email: "pruned@example.com", ExpiresAt: past}}
+	stored := []config.StoredInbox{
+		{Email: "stale@example.com", ExpiresAt: past},
+		{Email: "live@example.com", ExpiresAt: future},
+	}
+
+	targets := appendExpiredTargets(nil, pruned, stored, "")
+	require.Len(t, targets, 2)
+	assert.Equal(t, "pruned@example.com", targets[0].Email)
+	assert.True(t, targets[0].Pruned)
+	assert.True(t, targets[0].SkipServer)
+	assert.Equal(t, "stale@example.com", targets[1].Email)
+	assert.False(t, targets[1].Pruned)
+	assert.True(t, targets[1].SkipServer)
+
+	t.Run("label prefix narrows expired entries", func(t *testing.T) {
+		pruned := []config.StoredInbox{
+			{Email: "old-ci@example.com", Label: "ci-old", ExpiresAt: past},
+			{Email: "old@example.com", Label: "manual", ExpiresAt: past},
+		}
+		stored := []config.StoredInbox{
+			{Email: "stale-ci@example.com", Label: "ci-1", ExpiresAt: past},
+			{Email: "stale@example.com", Label: "manual", ExpiresAt: past},
+			{Email: "live-ci@example.com", Label: "ci-2", ExpiresAt: future},
+		}
+
+		targets := appendExpiredTargets(nil, pruned, stored, "ci-")
+		assert.Equal(t, []string{"old-ci@example.com", "stale-ci@example.com"}, targetEmails(targets))
+	})
+}
+
+func TestRunDeleteExpiredWithLabelPrefix(t *testing.T) {
+	t.Setenv("VSB_CONFIG_DIR", t.TempDir())
+	t.Cleanup(func() { deleteExpired, deleteLabelPrefix, deleteYes = false, "", false })
+
+	ks, err := config.LoadKeystore()
+	require.NoError(t, err)
+	future := time.Now().Add(time.Hour)
+	// Expired a minute ago: within the prune grace, so still in the keystore
+	past := time.Now().Add(-time.Minute)
+	require.NoError(t, ks.AddInbox(config.StoredInbox{Email: "live-ci@example.com", Label: "ci-1", ExpiresAt: future}))
+	require.NoError(t, ks.AddInbox(config.StoredInbox{Email: "live@example.com", Label: "manual", ExpiresAt: future}))
+	require.NoError(t, ks.AddInbox(config.StoredInbox{Email: "stale-ci@example.com", Label: "ci-2", ExpiresAt: past}))
+	require.NoError(t, ks.AddInbox(config.StoredInbox{Email: "stale@example.com", Label: "manual", ExpiresAt: past}))
+
+	deleteExpired, deleteLabelPrefix, deleteYes = true, "ci-", true
+	cmd := &cobra.Command{Use: "delete"}
+	cmd.Flags().StringP("output", "o", "", "")
+	require.NoError(t, cmd.Flags().Set("output", "json"))
+
+	var results []deleteResult
+	out := captureCreateStdout(t, func() { require.NoError(t, runDelete(cmd, nil)) })
+	require.NoError(t, json.Unmarshal([]byte(out), &results))
+	require.Len(t, results, 1)
+	assert.Equal(t, "stale-ci@example.com", results[0].Email)
+	assert.False(t, results[0].Server)
+
+	ks, err = config.LoadKeystore()
+	require.NoError(t, err)
+	for _, email := range []string{"live-ci@example.com", "live@example.com"} {
+		_, err := ks.GetInbox(email)
+		assert.NoError(t, err, email)
+	}
+	_, err = ks.GetInbox("stale-ci@example.com")
+	assert.Error(t, err)
+}
+
+func TestRunDeleteSingleJSON(t *testing.T) {
+	t.Setenv("VSB_CONFIG_DIR", t.TempDir())
+	t.Cleanup(func() { deleteLocal = false })
+
+	ks, err := config.LoadKeystore()
+	require.NoError(t, err)
+	require.NoError(t, ks.AddInbox(config.StoredInbox{Email: "solo@example.com", ExpiresAt: time.Now().Add(time.Hour)}))
+
+	deleteLocal = true
+	out := captureCreateStdout(t, func() {
+		require.NoError(t, runDeleteSingle(context.Background(), "solo", true))
+	})
+
+	var results []deleteResult
+	require.NoError(t, json.Unmarshal([]byte(out), &results), out)
+	assert.Equal(t, []deleteResult{{Email: "solo@example.com", Success: true, Local: true}}, results)
+}
+
+func TestConfirmDelete(t *testing.T) {
+	targets := []deleteTarget{{Email: "a@example.com"}, {Email: "b@example.com"}}
+
+	tests := []struct {
+		input string
+		want  bool
+	}{
+		{"y\n", true},
+		{"YES\n", true},
+		{"n\n", false},
+		{"\n", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(strings.TrimSpace(tt.input), func(t *testing.T) {
+			var out bytes.Buffer
+			got := confirmDelete(strings.NewReader(tt.input), &out, targets)
+			assert.Equal(t, tt.want, got)
+			assert.Contains(t, out.String(), "a@example.com")
+			assert.Contains(t, out.String(), "2 inboxes")
+		})
+	}
+}
+
+func TestDeleteInboxesLocal(t *testing.T) {
+	t.Setenv("VSB_CONFIG_DIR", t.TempDir())
+
+	ks, err := config.LoadKeystore()
+	require.NoError(t, err)
+	future := time.Now().Add(time.Hour)
+	require.NoError(t, ks.AddInbox(config.StoredInbox{Email: "keep@example.com", ExpiresAt: future}))
+	require.NoError(t, ks.AddInbox(config.StoredInbox{Email: "drop@example.com", ExpiresAt: future}))
+	// drop@example.com is active (last added)
+
+	targets := []deleteTarget{
+		{Email: "drop@example.com"},
+		{Email: "gone@example.com", SkipServer: true, Pruned: true},
+		{Email: "bad", Err: assert.AnError},
+	}
+	results := deleteInboxes(context.Background(), ks, targets, true)
+
+	require.Len(t, results, 3)
+	assert.True(t, results[0].Success)
+	assert.True(t, results[0].Local)
+	assert.False(t, results[0].Server)
+	assert.True(t, results[1].Success)
+	assert.False(t, results[2].Success)
+	assert.NotEmpty(t, results[2].Error)
+
+	assert.Len(t, ks.ListInboxes(), 1)
+	assert.Equal(t, "keep@example.com", ks.ActiveInbox)
+}
+
+func TestDeleteSummary(t *testing.T) {
+	results := []deleteResult{
+		{Email: "a@example.com", Success: true},
+		{Email: "b@example.com", Success: true},
+		{Email: "c@example.com", Error: "server deletion failed"},
+	}
+	assert.Equal(t, "Deleted 2 of 3 inboxes, 1 failed", deleteSummary(results))
+	assert.Equal(t, "Deleted 0 of 0 inboxes, 0 failed", deleteSummary(nil))
+}
+
+func TestSleepContext(t *testing.T) {
+	t.Run("waits", func(t *testing.T) {
+		start := time.Now()
+		require.NoError(t, sleepContext(context.Background(), 20*time.Millisecond))
+		assert.GreaterOrEqual(t, time.Since(start), 20*time.Millisecond)
+	})
+
+	t.Run("stops when the context is done", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		start := time.Now()
+		assert.ErrorIs(t, sleepContext(ctx, time.Minute), context.Canceled)
+		assert.Less(t, time.Since(start), time.Second)
+	})
+}
@@ -0,0 +1,160 @@
+package inbox
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/vaultsandbox/vsb-cli/internal/cliutil"
+	"github.com/vaultsandbox/vsb-cli/internal/config"
+	"github.com/vaultsandbox/vsb-cli/internal/styles"
+)
+
+var exportToEnvCmd = &cobra.Command{
+	Use:   "export-to-env [email-address]",
+	Short: "Print inbox credentials as shell export statements",
+	Long: `Print the active (or given) inbox's credentials as shell statements that
+set the VSB_INBOX_* variables read by 'vsb inbox import-from-env', to pass
+an inbox between CI jobs or stages.
+
+The KEM private key is masked unless --show-secrets is given. Anyone with
+the unmasked output can read emails sent to the inbox.
+
+Shells (--shell):
+  bash        export VSB_INBOX_EMAIL='...'   (also sh and zsh)
+  fish        set -gx VSB_INBOX_EMAIL '...'
+  powershell  $env:VSB_INBOX_EMAIL = '...'
+
+With --no-export, plain KEY=VALUE lines are printed for .env files.
+
+Examples:
+  eval "$(vsb inbox export-to-env --show-secrets)"
+  vsb inbox export-to-env --shell fish --show-secrets | source
+  vsb inbox export-to-env --no-export --show-secrets >> .env
+  vsb inbox export-to-env ci@abc123.vsx.email`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runExportToEnv,
+}
+
+var (
+	exportEnvShell       string
+	exportEnvNoExport    bool
+	exportEnvShowSecrets bool
+)
+
+// Output formats for export-to-env. envFormatDotenv is selected with
+// --no-export rather than --shell.
+const (
+	envFormatBash       = "bash"
+	envFormatFish       = "fish"
+	envFormatPowerShell = "powershell"
+	envFormatDotenv     = "dotenv"
+)
+
+// maskedSecret replaces the KEM private key unless --show-secrets is given
+const maskedSecret = "********"
+
+func init() {
+	Cmd.AddCommand(exportToEnvCmd)
+	cliutil.AddOutputFormats(exportToEnvCmd, cliutil.FormatJSON)
+
+	exportToEnvCmd.Flags().StringVar(&exportEnvShell, "shell", envFormatBash,
+		"Shell syntax: bash, fish, powershell")
+	exportToEnvCmd.Flags().BoolVar(&exportEnvNoExport, "no-export", false,
+		"Print KEY=VALUE lines (.env format) instead of shell statements")
+	exportToEnvCmd.Flags().BoolVar(&exportEnvShowSecrets, "show-secrets", false,
+		"Print the KEM private key instead of masking it")
+	exportToEnvCmd.MarkFlagsMutuallyExclusive("shell", "no-export")
+}
+
+func runExportToEnv(cmd *cobra.Command, args []string) error {
+	format := envFormatDotenv
+	if !exportEnvNoExport {
+		format = strings.ToLower(exportEnvShell)
+		switch format {
+		case envFormatBash, envFormatFish, envFormatPowerShell:
+		default:
+			return fmt.Errorf("invalid --shell %q (use bash, fish or powershell)", exportEnvShell)
+		}
+	}
+
+	ks, err := cliutil.LoadKeystoreOrError()
+	if err != nil {
+		return err
+	}
+	stored, err := cliutil.GetInbox(ks, cliutil.GetArg(args, 0, ""))
+	if err != nil {
+		return err
+	}
+
+	if stored.ExpiresAt.Before(config.ServerNow()) {
+		fmt.Fprintln(os.Stderr, styles.WarningTitleStyle.Render("Warning: This inbox has expired"))
+	}
+	if !exportEnvShowSecrets {
+		fmt.Fprintln(os.Stderr, styles.MutedStyle.Render(
+			"VSB_INBOX_KEM_PRIVATE is masked; use --show-secrets to print it"))
+	}
+
+	vars := inboxEnvValues(stored, exportEnvShowSecrets)
+
+	if cliutil.GetOutput(cmd) == cliutil.FormatJSON {
+		m := make(map[string]string, len(vars))
+		for _, v := range vars {
+			m[v.name] = v.value
+		}
+		return cliutil.OutputJSON(m)
+	}
+
+	for _, v := range vars {
+		fmt.Println(formatEnvAssignment(format, v.name, v.value))
+	}
+	return nil
+}
+
+type envVar struct {
+	name  string
+	value string
+}
+
+// inboxEnvValues returns the VSB_INBOX_* variables for an inbox in the order
+// of inboxEnvVars.
+func inboxEnvValues(stored *config.StoredInbox, showSecrets bool) []envVar {
+	kemPrivate := stored.Keys.KEMPrivate
+	if !showSecrets {
+		kemPrivate = maskedSecret
+	}
+	values := map[string]string{
+		"VSB_INBOX_EMAIL":         stored.Email,
+		"VSB_INBOX_HASH":          stored.ID,
+		"VSB_INBOX_KEM_PRIVATE":   kemPrivate,
+		"VSB_INBOX_SERVER_SIG_PK": stored.Keys.ServerSigPK,
+		"VSB_INBOX_EXPIRES_AT":    stored.ExpiresAt.UTC().Format(time.RFC3339),
+	}
+	vars := make([]envVar, len(inboxEnvVars))
+	for i, name := range inboxEnvVars {
+		vars[i] = envVar{name: name, value: values[name]}
+	}
+	return vars
+}
+
+// formatEnvAssignment renders one variable assignment. Values are single
+// quoted so nothing in them is expanded by the shell.
+func formatEnvAssignment(format, name, value string) string {
+	switch format {
+	case envFormatFish:
+		// fish single quotes only treat \' and \\ specially
+		escaped := strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(value)
+		return fmt.Sprintf("set -gx %s '%s'", name, escaped)
+	case envFormatPowerShell:
+		return fmt.Sprintf("$env:%s = '%s'", name, strings.ReplaceAll(value, "'", "''"))
+	case envFormatDotenv:
+		if strings.ContainsAny(value, " \t\"'#$\\`") {
+			return fmt.Sprintf("%s=%q", name, value)
+		}
+		return name + "=" + value
+	default:
+		return fmt.Sprintf("export %s='%s'", name, strings.ReplaceAll(value, "'", `'\''`))
+	}
+}
@@ -0,0 +1,76 @@
+package inbox
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vaultsandbox/vsb-cli/internal/config"
+)
+
+func TestFormatEnvAssignment(t *testing.T) {
+	tests := []struct {
+		format string
+		value  string
+		want   string
+	}{
+		{envFormatBash, "ci@vaultsandbox.com", `export VSB_INBOX_EMAIL='ci@vaultsandbox.com'`},
+		{envFormatBash, "it's $HOME", `export VSB_INBOX_EMAIL='it'\''s $HOME'`},
+		{envFormatFish, "ci@vaultsandbox.com", `set -gx VSB_INBOX_EMAIL 'ci@vaultsandbox.com'`},
+		{envFormatFish, `it's a\b`, `set -gx VSB_INBOX_EMAIL 'it\'s a\\b'`},
+		{envFormatPowerShell, "ci@vaultsandbox.com", `$env:VSB_INBOX_EMAIL = 'ci@vaultsandbox.com'`},
+		{envFormatPowerShell, "it's", `$env:VSB_INBOX_EMAIL = 'it''s'`},
+		{envFormatDotenv, "ci@vaultsandbox.com", `VSB_INBOX_EMAIL=ci@vaultsandbox.com`},
+		{envFormatDotenv, "two words", `VSB_INBOX_EMAIL="two words"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format+" "+tt.value, func(t *testing.T) {
+			assert.Equal(t, tt.want, formatEnvAssignment(tt.format, "VSB_INBOX_EMAIL", tt.value))
+		})
+	}
+}
+
+func TestInboxEnvValues(t *testing.T) {
+	env := testInboxEnv()
+	stored := &config.StoredInbox{
+		Email:     env["VSB_INBOX_EMAIL"],
+		ID:        env["VSB_INBOX_HASH"],
+		ExpiresAt: time.Date(2026, 2, 1, 11, 0, 0, 0, time.FixedZone("CET", 3600)),
+		Keys: config.InboxKeys{
+			KEMPrivate:  env["VSB_INBOX_KEM_PRIVATE"],
+			ServerSigPK: env["VSB_INBOX_SERVER_SIG_PK"],
+		},
+	}
+
+	t.Run("masks private key", func(t *testing.T) {
+		vars := inboxEnvValues(stored, false)
+		require.Len(t, vars, len(inboxEnvVars))
+		for i, v := range vars {
+			assert.Equal(t, inboxEnvVars[i], v.name)
+		}
+		assert.Equal(t, maskedSecret, vars[2].value)
+		assert.Equal(t, env["VSB_INBOX_SERVER_SIG_PK"], vars[3].value)
+	})
+
+	t.Run("round trips through import-from-env", func(t *testing.T) {
+		vars := inboxEnvValues(stored, true)
+		got := map[string]string{}
+		for _, v := range vars {
+			line := formatEnvAssignment(envFormatDotenv, v.name, v.value)
+			name, value, ok := strings.Cut(line, "=")
+			require.True(t, ok)
+			got[name] = value
+		}
+		assert.Equal(t, env, got)
+
+		now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+		imported, err := storedInboxFromEnv(func(k string) string { return got[k] }, now)
+		require.NoError(t, err)
+		assert.Equal(t, stored.Email, imported.Email)
+		assert.Equal(t, stored.Keys, imported.Keys)
+		assert.True(t, stored.ExpiresAt.Equal(imported.ExpiresAt))
+	})
+}
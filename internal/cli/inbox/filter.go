@@ -0,0 +1,226 @@
+package inbox
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/vaultsandbox/vsb-cli/internal/cliutil"
+	"github.com/vaultsandbox/vsb-cli/internal/config"
+	"github.com/vaultsandbox/vsb-cli/internal/styles"
+)
+
+var filterCmd = &cobra.Command{
+	Use:   "filter",
+	Short: "Manage filter presets stored on an inbox",
+	Long: `Manage named filter presets stored on an inbox.
+
+A preset saves a set of --subject, --subject-regex, --from, --from-regex
+and --body-regex filters under a name. 'email wait --preset <name>' and
+'email list --preset <name>' expand it into those flags; a filter flag
+given on the command line overrides the preset's value for it.
+
+Presets are stored with the inbox in the keystore and travel with it
+through 'vsb export' and 'vsb import'. Each command works on the active
+inbox unless --inbox is given.
+
+Examples:
+  vsb inbox filter set billing --from 'billing@ourapp.com' --subject-regex 'Invoice'
+  vsb inbox filter list
+  vsb email wait --preset billing
+  vsb inbox filter delete billing`,
+	RunE: runInbox,
+}
+
+var filterSetCmd = &cobra.Command{
+	Use:   "set <name>",
+	Short: "Create or replace a filter preset",
+	Long: `Save the given filters as a preset, replacing any preset with the same
+name. At least one filter is required, and regexes must compile.
+
+Examples:
+  vsb inbox filter set billing --from 'billing@ourapp.com' --subject-regex 'Invoice'
+  vsb inbox filter set auth --from-regex '^auth@' --inbox signup`,
+	Args: cobra.ExactArgs(1),
+	RunE: runFilterSet,
+}
+
+var filterListCmd = &cobra.Command{
+	Use:     "list",
+	Short:   "List the filter presets of an inbox",
+	Aliases: []string{"ls"},
+	Args:    cobra.NoArgs,
+	RunE:    runFilterList,
+}
+
+var filterDeleteCmd = &cobra.Command{
+	Use:     "delete <name>",
+	Short:   "Delete a filter preset",
+	Aliases: []string{"rm"},
+	Args:    cobra.ExactArgs(1),
+	RunE:    runFilterDelete,
+}
+
+var (
+	filterInbox  string
+	filterPreset config.FilterPreset
+)
+
+// presetNamePattern restricts preset names to something easy to type
+var presetNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+func init() {
+	Cmd.AddCommand(filterCmd)
+	filterCmd.AddCommand(filterSetCmd, filterListCmd, filterDeleteCmd)
+	cliutil.AddOutputFormats(filterSetCmd, cliutil.FormatJSON)
+	cliutil.AddOutputFormats(filterListCmd, cliutil.FormatJSON)
+	cliutil.AddOutputFormats(filterDeleteCmd, cliutil.FormatJSON)
+	cliutil.MarkSavesState(filterSetCmd)
+	cliutil.MarkSavesState(filterDeleteCmd)
+
+	filterCmd.PersistentFlags().StringVar(&filterInbox, "inbox", "",
+		"Inbox to manage presets of (default: active)")
+
+	filterSetCmd.Flags().StringVar(&filterPreset.Subject, "subject", "",
+		"Exact subject match")
+	filterSetCmd.Flags().StringVar(&filterPreset.SubjectRegex, "subject-regex", "",
+		"Subject regex pattern")
+	filterSetCmd.Flags().StringVar(&filterPreset.From, "from", "",
+		"Exact sender match")
+	filterSetCmd.Flags().StringVar(&filterPreset.FromRegex, "from-regex", "",
+		"Sender regex pattern")
+	filterSetCmd.Flags().StringVar(&filterPreset.BodyRegex, "body-regex", "",
+		"Body regex pattern")
+}
+
+func runFilterSet(cmd *cobra.Command, args []string) error {
+	name := args[0]
+	if !presetNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid preset name %q (use letters, digits, '.', '_' and '-')", name)
+	}
+	if err := validatePreset(filterPreset); err != nil {
+		return err
+	}
+
+	ks, stored, err := loadFilterInbox()
+	if err != nil {
+		return err
+	}
+	if err := ks.SetFilterPreset(stored.Email, name, filterPreset); err != nil {
+		return err
+	}
+
+	if cliutil.GetOutput(cmd) == cliutil.FormatJSON {
+		return cliutil.OutputJSON(map[string]interface{}{
+			"inbox":  stored.Email,
+			"name":   name,
+			"preset": filterPreset,
+		})
+	}
+	fmt.Println(styles.PassStyle.Render(fmt.Sprintf("✓ Saved preset %s for %s: %s", name, stored.Email, describePreset(filterPreset))))
+	return nil
+}
+
+func runFilterList(cmd *cobra.Command, args []string) error {
+	_, stored, err := loadFilterInbox()
+	if err != nil {
+		return err
+	}
+
+	if cliutil.GetOutput(cmd) == cliutil.FormatJSON {
+		filters := stored.Filters
+		if filters == nil {
+			filters = map[string]config.FilterPreset{}
+		}
+		return cliutil.OutputJSON(map[string]interface{}{
+			"inbox":   stored.Email,
+			"presets": filters,
+		})
+	}
+
+	names := stored.PresetNames()
+	if len(names) == 0 {
+		fmt.Printf("No filter presets for %s\n", stored.Email)
+		return nil
+	}
+	fmt.Printf("Filter presets for %s:\n\n", stored.Email)
+	for _, name := range names {
+		fmt.Printf("  %s  %s\n", styles.PrimaryBoldStyle.Render(name), describePreset(stored.Filters[name]))
+	}
+	return nil
+}
+
+func runFilterDelete(cmd *cobra.Command, args []string) error {
+	name := args[0]
+	ks, stored, err := loadFilterInbox()
+	if err != nil {
+		return err
+	}
+	if err := ks.DeleteFilterPreset(stored.Email, name); err != nil {
+		if errors.Is(err, config.ErrPresetNotFound) {
+			return &cliutil.UnknownPresetError{Name: name, Inbox: stored.Email, Available: stored.PresetNames()}
+		}
+		return err
+	}
+
+	if cliutil.GetOutput(cmd) == cliutil.FormatJSON {
+		return cliutil.OutputJSON(map[string]interface{}{
+			"inbox":   stored.Email,
+			"name":    name,
+			"deleted": true,
+		})
+	}
+	fmt.Println(styles.PassStyle.Render(fmt.Sprintf("✓ Deleted preset %s from %s", name, stored.Email)))
+	return nil
+}
+
+// loadFilterInbox returns the keystore and the inbox selected by --inbox.
+func loadFilterInbox() (*config.Keystore, *config.StoredInbox, error) {
+	ks, err := cliutil.LoadKeystoreOrError()
+	if err != nil {
+		return nil, nil, err
+	}
+	stored, err := cliutil.GetInbox(ks, filterInbox)
+	if err != nil {
+		return nil, nil, err
+	}
+	return ks, stored, nil
+}
+
+// validatePreset requires at least one filter and checks that the regexes
+// compile, so a broken preset is caught when it is saved rather than used.
+func validatePreset(p config.FilterPreset) error {
+	if p.IsEmpty() {
+		return fmt.Errorf("a preset needs at least one of --subject, --subject-regex, --from, --from-regex or --body-regex")
+	}
+	for flag, pattern := range map[string]string{
+		"subject-regex": p.SubjectRegex,
+		"from-regex":    p.FromRegex,
+		"body-regex":    p.BodyRegex,
+	} {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("invalid --%s: %w", flag, err)
+		}
+	}
+	return nil
+}
+
+// describePreset renders a preset as the flags it expands to, e.g.
+// "--from billing@ourapp.com --subject-regex Invoice".
+func describePreset(p config.FilterPreset) string {
+	flags := p.Flags()
+	names := make([]string, 0, len(flags))
+	for name := range flags {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	parts := make([]string, len(names))
+	for i, name := range names {
+		parts[i] = fmt.Sprintf("--%s %s", name, flags[name])
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,28 @@
+package inbox
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vaultsandbox/vsb-cli/internal/config"
+)
+
+func TestValidatePreset(t *testing.T) {
+	assert.NoError(t, validatePreset(config.FilterPreset{From: "billing@ourapp.com"}))
+	assert.ErrorContains(t, validatePreset(config.FilterPreset{}), "at least one of")
+	assert.ErrorContains(t, validatePreset(config.FilterPreset{BodyRegex: "("}), "invalid --body-regex")
+}
+
+func TestDescribePreset(t *testing.T) {
+	p := config.FilterPreset{SubjectRegex: "Invoice", From: "billing@ourapp.com"}
+	assert.Equal(t, "--from billing@ourapp.com --subject-regex Invoice", describePreset(p))
+}
+
+func TestPresetNamePattern(t *testing.T) {
+	for _, name := range []string{"billing", "auth-codes", "v1.2_x"} {
+		assert.True(t, presetNamePattern.MatchString(name), name)
+	}
+	for _, name := range []string{"", "-x", "has space", "a/b"} {
+		assert.False(t, presetNamePattern.MatchString(name), name)
+	}
+}
@@ -0,0 +1,130 @@
+package inbox
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/vaultsandbox/vsb-cli/internal/cliutil"
+	"github.com/vaultsandbox/vsb-cli/internal/config"
+	"github.com/vaultsandbox/vsb-cli/internal/styles"
+)
+
+var importFromEnvCmd = &cobra.Command{
+	Use:   "import-from-env",
+	Short: "Add an inbox to the keystore from environment variables",
+	Long: `Add an inbox to the local keystore from environment variables, so CI/CD
+systems can inject inbox credentials as secrets instead of export files.
+
+Required variables:
+  VSB_INBOX_EMAIL          Inbox email address
+  VSB_INBOX_HASH           Inbox hash
+  VSB_INBOX_KEM_PRIVATE    ML-KEM private key (base64url)
+  VSB_INBOX_SERVER_SIG_PK  Pinned server signing key (base64url)
+  VSB_INBOX_EXPIRES_AT     Expiry time (RFC3339)
+
+The values match the emailAddress, inboxHash, secretKey, serverSigPk and
+expiresAt fields of a 'vsb export' file. The keys are
+checked locally; the server is not contacted. An existing inbox with the
+same address is replaced. The imported inbox becomes active if --set-active
+is given or no inbox is active yet.
+
+Examples:
+  vsb inbox import-from-env
+  vsb inbox import-from-env --set-active`,
+	Args: cobra.NoArgs,
+	RunE: runImportFromEnv,
+}
+
+var importEnvSetActive bool
+
+// inboxEnvVars are the variables read by import-from-env, in the order
+// missing ones are reported
+var inboxEnvVars = []string{
+	"VSB_INBOX_EMAIL",
+	"VSB_INBOX_HASH",
+	"VSB_INBOX_KEM_PRIVATE",
+	"VSB_INBOX_SERVER_SIG_PK",
+	"VSB_INBOX_EXPIRES_AT",
+}
+
+func init() {
+	Cmd.AddCommand(importFromEnvCmd)
+	cliutil.AddOutputFormats(importFromEnvCmd, cliutil.FormatJSON)
+	cliutil.MarkSavesState(importFromEnvCmd)
+
+	importFromEnvCmd.Flags().BoolVar(&importEnvSetActive, "set-active", false,
+		"Make the imported inbox the active inbox")
+}
+
+func runImportFromEnv(cmd *cobra.Command, args []string) error {
+	stored, err := storedInboxFromEnv(os.Getenv, config.ServerNow())
+	if err != nil {
+		return err
+	}
+
+	ks, err := cliutil.LoadKeystoreOrError()
+	if err != nil {
+		return err
+	}
+	if err := ks.PutInbox(stored, importEnvSetActive); err != nil {
+		return err
+	}
+
+	active := false
+	if current, err := ks.GetActiveInbox(); err == nil {
+		active = current.Email == stored.Email
+	}
+
+	if cliutil.GetOutput(cmd) == cliutil.FormatJSON {
+		return cliutil.OutputJSON(cliutil.InboxSummaryJSON(&stored, active, config.ServerNow()))
+	}
+
+	fmt.Println(styles.PassStyle.Render(fmt.Sprintf("✓ Imported %s from environment", stored.Email)))
+	if active {
+		fmt.Println(styles.MutedStyle.Render("  Active inbox set to " + stored.Email))
+	}
+	return nil
+}
+
+// storedInboxFromEnv builds a keystore entry from the VSB_INBOX_*
+// variables. All variables are required and the keys must be well-formed.
+func storedInboxFromEnv(getenv func(string) string, now time.Time) (config.StoredInbox, error) {
+	values := make(map[string]string, len(inboxEnvVars))
+	var missing []string
+	for _, name := range inboxEnvVars {
+		values[name] = strings.TrimSpace(getenv(name))
+		if values[name] == "" {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return config.StoredInbox{}, fmt.Errorf("missing required environment variables: %s", strings.Join(missing, ", "))
+	}
+
+	expiresAt, err := time.Parse(time.RFC3339, values["VSB_INBOX_EXPIRES_AT"])
+	if err != nil {
+		return config.StoredInbox{}, fmt.Errorf("invalid VSB_INBOX_EXPIRES_AT: %s (use RFC3339, e.g. 2026-01-13T15:00:00Z)", values["VSB_INBOX_EXPIRES_AT"])
+	}
+	if !expiresAt.After(now) {
+		return config.StoredInbox{}, fmt.Errorf("inbox expired on %s", expiresAt.Format("2006-01-02"))
+	}
+
+	stored := config.StoredInbox{
+		Email:     values["VSB_INBOX_EMAIL"],
+		ID:        values["VSB_INBOX_HASH"],
+		CreatedAt: now,
+		ExpiresAt: expiresAt,
+		Keys: config.InboxKeys{
+			KEMPrivate:  values["VSB_INBOX_KEM_PRIVATE"],
+			ServerSigPK: values["VSB_INBOX_SERVER_SIG_PK"],
+		},
+		Encrypted: true,
+	}
+	if err := stored.ToExportedInbox().Validate(); err != nil {
+		return config.StoredInbox{}, fmt.Errorf("invalid inbox credentials: %w", err)
+	}
+	return stored, nil
+}
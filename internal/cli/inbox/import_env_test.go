@@ -0,0 +1,85 @@
+package inbox
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testInboxEnv() map[string]string {
+	return map[string]string{
+		"VSB_INBOX_EMAIL":         "ci@vaultsandbox.com",
+		"VSB_INBOX_HASH":          "hash123",
+		"VSB_INBOX_KEM_PRIVATE":   base64.RawURLEncoding.EncodeToString(make([]byte, 2400)),
+		"VSB_INBOX_SERVER_SIG_PK": base64.RawURLEncoding.EncodeToString(make([]byte, 1952)),
+		"VSB_INBOX_EXPIRES_AT":    "2026-02-01T10:00:00Z",
+	}
+}
+
+func TestStoredInboxFromEnv(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("builds stored inbox", func(t *testing.T) {
+		env := testInboxEnv()
+		stored, err := storedInboxFromEnv(func(k string) string { return env[k] }, now)
+		require.NoError(t, err)
+
+		assert.Equal(t, "ci@vaultsandbox.com", stored.Email)
+		assert.Equal(t, "hash123", stored.ID)
+		assert.Equal(t, now, stored.CreatedAt)
+		assert.Equal(t, time.Date(2026, 2, 1, 10, 0, 0, 0, time.UTC), stored.ExpiresAt)
+		assert.Equal(t, env["VSB_INBOX_KEM_PRIVATE"], stored.Keys.KEMPrivate)
+		assert.Equal(t, env["VSB_INBOX_SERVER_SIG_PK"], stored.Keys.ServerSigPK)
+		assert.True(t, stored.Encrypted)
+	})
+
+	t.Run("lists all missing variables", func(t *testing.T) {
+		env := testInboxEnv()
+		delete(env, "VSB_INBOX_HASH")
+		env["VSB_INBOX_EXPIRES_AT"] = "  "
+		_, err := storedInboxFromEnv(func(k string) string { return env[k] }, now)
+		assert.EqualError(t, err, "missing required environment variables: VSB_INBOX_HASH, VSB_INBOX_EXPIRES_AT")
+	})
+
+	t.Run("nothing set", func(t *testing.T) {
+		_, err := storedInboxFromEnv(func(string) string { return "" }, now)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), strings.Join(inboxEnvVars, ", "))
+	})
+
+	t.Run("invalid expiry", func(t *testing.T) {
+		env := testInboxEnv()
+		env["VSB_INBOX_EXPIRES_AT"] = "tomorrow"
+		_, err := storedInboxFromEnv(func(k string) string { return env[k] }, now)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid VSB_INBOX_EXPIRES_AT: tomorrow")
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		env := testInboxEnv()
+		env["VSB_INBOX_EXPIRES_AT"] = "2025-12-31T00:00:00Z"
+		_, err := storedInboxFromEnv(func(k string) string { return env[k] }, now)
+		assert.EqualError(t, err, "inbox expired on 2025-12-31")
+	})
+
+	t.Run("invalid key size", func(t *testing.T) {
+		env := testInboxEnv()
+		env["VSB_INBOX_KEM_PRIVATE"] = base64.RawURLEncoding.EncodeToString(make([]byte, 10))
+		_, err := storedInboxFromEnv(func(k string) string { return env[k] }, now)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid inbox credentials")
+		assert.Contains(t, err.Error(), "secretKey size 10")
+	})
+
+	t.Run("invalid email", func(t *testing.T) {
+		env := testInboxEnv()
+		env["VSB_INBOX_EMAIL"] = "not-an-address"
+		_, err := storedInboxFromEnv(func(k string) string { return env[k] }, now)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "emailAddress must contain exactly one @")
+	})
+}
@@ -3,12 +3,16 @@ package inbox
 import (
 	"context"
 	"fmt"
+	"io"
+	"os"
+	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/vaultsandbox/vsb-cli/internal/cliutil"
 	"github.com/vaultsandbox/vsb-cli/internal/config"
 	"github.com/vaultsandbox/vsb-cli/internal/styles"
+	"golang.org/x/term"
 )
 
 var infoCmd = &cobra.Command{
@@ -16,22 +20,59 @@ var infoCmd = &cobra.Command{
 	Short: "Show inbox details",
 	Long: `Display detailed information about an inbox.
 
-Shows email address, creation date, expiry, email count, and sync status.
+Shows email address, creation date, expiry, email count, unread count and
+sync status. Unread counts use the local read state (see 'email list
+--unread').
+
+With --countdown, the time left until the inbox expires is shown below the
+details and, in a terminal, updated every second until Ctrl-C. JSON output
+gets a remainingSeconds field instead.
+
+With --server, the keystore entry is checked against the server: whether
+the inbox still exists there and its email count. Values that differ are
+marked, and JSON output has "local", "server" and "drift" objects. An inbox
+the server no longer has is reported as drift, not an error; a rejected API
+key or an unreachable server is an error. The server does not report an
+inbox's expiry or creation time, so those are not compared.
 
 Examples:
-  vsb inbox info           # Info for active inbox
-  vsb inbox info abc       # Info for inbox matching 'abc'
-  vsb inbox info -o json   # JSON output`,
+  vsb inbox info              # Info for active inbox
+  vsb inbox info abc          # Info for inbox matching 'abc'
+  vsb inbox info --countdown  # Live time-to-expiry
+  vsb inbox info --server     # Compare the keystore with the server
+  vsb inbox info -o json      # JSON output`,
 	Args: cobra.MaximumNArgs(1),
 	RunE: runInfo,
 }
 
+var (
+	infoCountdown bool
+	infoServer    bool
+)
+
+// countdownInterval is how often a live countdown is redrawn
+var countdownInterval = time.Second
+
+// stdoutIsTerminal reports whether stdout is a terminal, where the countdown
+// can be redrawn in place
+var stdoutIsTerminal = func() bool {
+	return term.IsTerminal(int(os.Stdout.Fd()))
+}
+
 func init() {
 	Cmd.AddCommand(infoCmd)
+	cliutil.AddOutputFormats(infoCmd, cliutil.FormatJSON)
+
+	infoCmd.Flags().BoolVar(&infoCountdown, "countdown", false,
+		"Show the time left until expiry, updated every second in a terminal")
+	infoCmd.Flags().BoolVar(&infoServer, "server", false,
+		"Compare the keystore entry with the server's view of the inbox")
+
+	infoCmd.MarkFlagsMutuallyExclusive("countdown", "server")
 }
 
 func runInfo(cmd *cobra.Command, args []string) error {
-	ctx := context.Background()
+	ctx := cliutil.CommandContext(cmd)
 
 	emailArg := cliutil.GetArg(args, 0, "")
 
@@ -47,29 +88,92 @@ func runInfo(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	// Get email count from server
-	emailCount, syncErr := getInboxEmailCount(ctx, stored)
+	if infoServer {
+		return runInfoServer(cmd, ks, stored)
+	}
+
+	// Get email counts from server
+	emailCount, unreadCount, syncErr := getInboxEmailCounts(ctx, stored)
 
 	isExpired := cliutil.IsExpired(stored.ExpiresAt)
 	isActive := stored.Email == ks.ActiveInbox
 
 	// JSON output
-	if cliutil.GetOutput(cmd) == "json" {
-		return cliutil.OutputJSON(cliutil.InboxFullJSON(stored, isActive, emailCount, syncErr, time.Now()))
+	if cliutil.GetOutput(cmd) == cliutil.FormatJSON {
+		data := cliutil.InboxFullJSON(stored, isActive, emailCount, syncErr, config.ServerNow())
+		if syncErr == nil {
+			data["unreadCount"] = unreadCount
+		}
+		if infoCountdown {
+			data["remainingSeconds"] = remainingSeconds(stored.ExpiresAt, config.ServerNow())
+		}
+		return cliutil.OutputJSON(data)
 	}
 
 	// Pretty output
-	content := formatInboxInfoContent(stored, isActive, isExpired, emailCount, syncErr)
+	content := formatInboxInfoContent(stored, isActive, isExpired, emailCount, unreadCount, syncErr)
 
 	fmt.Println()
 	fmt.Println(styles.BoxStyle.Render(content))
 	fmt.Println()
 
+	if infoCountdown {
+		return renderCountdown(ctx, os.Stdout, stored.ExpiresAt, stdoutIsTerminal())
+	}
 	return nil
 }
 
+// remainingSeconds returns the whole seconds until expiresAt, never negative.
+func remainingSeconds(expiresAt, now time.Time) int {
+	if remaining := expiresAt.Sub(now); remaining > 0 {
+		return int(remaining / time.Second)
+	}
+	return 0
+}
+
+// countdownLine describes the time left until expiresAt and reports whether
+// the inbox has expired.
+func countdownLine(expiresAt, now time.Time) (string, bool) {
+	remaining := expiresAt.Sub(now)
+	switch {
+	case remaining <= 0:
+		return styles.FailStyle.Render("expired"), true
+	case remaining < time.Hour:
+		return styles.WarnStyle.Render("expires in " + cliutil.FormatCountdown(remaining)), false
+	}
+	return "expires in " + cliutil.FormatCountdown(remaining), false
+}
+
+// renderCountdown prints the time left until expiresAt. When live, the line
+// is redrawn in place every countdownInterval until the inbox expires or ctx
+// is cancelled; otherwise it is printed once.
+func renderCountdown(ctx context.Context, w io.Writer, expiresAt time.Time, live bool) error {
+	line, expired := countdownLine(expiresAt, config.ServerNow())
+	if !live {
+		fmt.Fprintln(w, line)
+		return nil
+	}
+
+	ticker := time.NewTicker(countdownInterval)
+	defer ticker.Stop()
+	for {
+		fmt.Fprintf(w, "\r\033[K%s", line)
+		if expired {
+			fmt.Fprintln(w)
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			fmt.Fprintln(w)
+			return nil
+		case <-ticker.C:
+		}
+		line, expired = countdownLine(expiresAt, config.ServerNow())
+	}
+}
+
 // formatInboxInfoContent builds the formatted content string for inbox info display.
-func formatInboxInfoContent(stored *config.StoredInbox, isActive, isExpired bool, emailCount int, syncErr error) string {
+func formatInboxInfoContent(stored *config.StoredInbox, isActive, isExpired bool, emailCount, unreadCount int, syncErr error) string {
 	labelStyle := styles.LabelStyle.Width(14)
 
 	var content string
@@ -94,33 +198,44 @@ func formatInboxInfoContent(stored *config.StoredInbox, isActive, isExpired bool
 	}
 	content += fmt.Sprintf("%s %s\n", labelStyle.Render("Expires:"), expiryStr)
 
+	if len(stored.Tags) > 0 {
+		content += fmt.Sprintf("%s %s\n", labelStyle.Render("Tags:"), strings.Join(stored.Tags, ", "))
+	}
+
 	// Email count
 	if syncErr != nil {
 		content += fmt.Sprintf("%s %s\n", labelStyle.Render("Emails:"), styles.WarnStyle.Render("(sync error)"))
 	} else {
-		content += fmt.Sprintf("%s %d\n", labelStyle.Render("Emails:"), emailCount)
+		content += fmt.Sprintf("%s %d (%d unread)\n", labelStyle.Render("Emails:"), emailCount, unreadCount)
 	}
 
 	return content
 }
 
-// getInboxEmailCount fetches the email count for an inbox from the server.
-func getInboxEmailCount(ctx context.Context, stored *config.StoredInbox) (int, error) {
+// getInboxEmailCounts fetches the email list for an inbox from the server
+// and counts the emails not marked read locally.
+func getInboxEmailCounts(ctx context.Context, stored *config.StoredInbox) (total, unread int, err error) {
 	client, err := config.NewClient()
 	if err != nil {
-		return 0, err
+		return 0, 0, err
 	}
 	defer client.Close()
 
 	inbox, err := client.ImportInbox(ctx, stored.ToExportedInbox())
 	if err != nil {
-		return 0, err
+		return 0, 0, err
 	}
 
-	status, err := inbox.GetSyncStatus(ctx)
+	metadata, err := inbox.GetEmailsMetadataOnly(ctx)
 	if err != nil {
-		return 0, err
+		return 0, 0, err
 	}
 
-	return status.EmailCount, nil
+	readState := cliutil.LoadReadState(stored.Email)
+	for _, m := range metadata {
+		if !readState.IsRead(m.ID) {
+			unread++
+		}
+	}
+	return len(metadata), unread, nil
 }
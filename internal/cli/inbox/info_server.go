@@ -0,0 +1,213 @@
+package inbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	vaultsandbox "github.com/vaultsandbox/client-go"
+	"github.com/vaultsandbox/vsb-cli/internal/cliutil"
+	"github.com/vaultsandbox/vsb-cli/internal/config"
+	"github.com/vaultsandbox/vsb-cli/internal/styles"
+)
+
+// serverInboxState is the server's view of an inbox. The gateway only
+// reports whether the inbox exists and how many emails it holds: the SDK
+// has no call returning an inbox's expiry or creation time.
+type serverInboxState struct {
+	Exists     bool
+	EmailCount int
+}
+
+// fetchServerState asks the server about stored. An inbox the server does
+// not know is reported with Exists false rather than as an error; other
+// failures (auth, network) are errors. Overridden in tests.
+var fetchServerState = func(ctx context.Context, stored *config.StoredInbox) (*serverInboxState, error) {
+	client, err := config.NewClient()
+	if err != nil {
+		return nil, err
+	}
+	defer client.Close()
+
+	inbox, err := client.ImportInbox(ctx, stored.ToExportedInbox())
+	if errors.Is(err, vaultsandbox.ErrInboxNotFound) {
+		return &serverInboxState{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	status, err := inbox.GetSyncStatus(ctx)
+	if errors.Is(err, vaultsandbox.ErrInboxNotFound) {
+		return &serverInboxState{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &serverInboxState{Exists: true, EmailCount: status.EmailCount}, nil
+}
+
+// serverCheckError explains why the server could not be asked about an
+// inbox. A rejected key and an unreachable server need different fixes,
+// and neither means the inbox is gone.
+func serverCheckError(err error) error {
+	var netErr *vaultsandbox.NetworkError
+	switch {
+	case errors.Is(err, vaultsandbox.ErrUnauthorized):
+		return fmt.Errorf("server rejected the API key (check it with 'vsb config show'): %w", err)
+	case errors.As(err, &netErr):
+		return fmt.Errorf("could not reach the server: %w", err)
+	}
+	return fmt.Errorf("server check failed: %w", err)
+}
+
+// inboxReconciliation compares the keystore entry of an inbox with the
+// server's view of it.
+type inboxReconciliation struct {
+	stored     *config.StoredInbox
+	active     bool
+	server     *serverInboxState
+	now        time.Time
+	mismatches []string // names of the fields that differ
+}
+
+// reconcileInbox lists where the keystore and the server disagree: the
+// server no longer has the inbox, or the keystore thinks it expired while
+// the server still has it.
+func reconcileInbox(stored *config.StoredInbox, active bool, server *serverInboxState, now time.Time) *inboxReconciliation {
+	r := &inboxReconciliation{stored: stored, active: active, server: server, now: now}
+	switch {
+	case !server.Exists:
+		r.mismatches = append(r.mismatches, "exists")
+	case !stored.ExpiresAt.After(now):
+		r.mismatches = append(r.mismatches, "expired")
+	}
+	return r
+}
+
+func (r *inboxReconciliation) differs(field string) bool {
+	for _, m := range r.mismatches {
+		if m == field {
+			return true
+		}
+	}
+	return false
+}
+
+// hints returns what the user can do about the mismatches
+func (r *inboxReconciliation) hints() []string {
+	var hints []string
+	if r.differs("exists") {
+		hints = append(hints, fmt.Sprintf("The server no longer has this inbox. Remove it locally with 'vsb inbox delete --local %s'", r.stored.Email))
+	}
+	return hints
+}
+
+// toJSON returns the local and server views and a drift summary
+func (r *inboxReconciliation) toJSON() map[string]interface{} {
+	local := map[string]interface{}{
+		"exists":    true,
+		"active":    r.active,
+		"expired":   !r.stored.ExpiresAt.After(r.now),
+		"expiresAt": r.stored.ExpiresAt.Format(time.RFC3339),
+		"createdAt": r.stored.CreatedAt.Format(time.RFC3339),
+	}
+	server := map[string]interface{}{
+		"exists":     r.server.Exists,
+		"emailCount": nil,
+	}
+	if r.server.Exists {
+		server["emailCount"] = r.server.EmailCount
+	}
+
+	mismatches := r.mismatches
+	if mismatches == nil {
+		mismatches = []string{}
+	}
+	hints := r.hints()
+	if hints == nil {
+		hints = []string{}
+	}
+	return map[string]interface{}{
+		"email":  r.stored.Email,
+		"local":  local,
+		"server": server,
+		"drift": map[string]interface{}{
+			"inSync":     len(r.mismatches) == 0,
+			"mismatches": mismatches,
+			"hints":      hints,
+		},
+	}
+}
+
+// format renders the local and server values side by side, marking the
+// values that differ.
+func (r *inboxReconciliation) format() string {
+	title := styles.TitleStyle.Render(r.stored.Email)
+	if r.active {
+		title += "  " + styles.BadgeStyle.Background(styles.Green).Render("ACTIVE")
+	}
+
+	serverExists, emailCount := "yes", fmt.Sprintf("%d", r.server.EmailCount)
+	if !r.server.Exists {
+		serverExists, emailCount = "no", "-"
+	}
+	localExpires := r.stored.ExpiresAt.Local().Format(cliutil.TimeFormatShort)
+	if !r.stored.ExpiresAt.After(r.now) {
+		localExpires += " (expired)"
+	}
+
+	rows := []struct {
+		field, local, server string
+		mismatch             bool
+	}{
+		{"Exists", "yes", serverExists, r.differs("exists")},
+		{"Expires", localExpires, "-", r.differs("expired")},
+		{"Emails", "-", emailCount, false},
+	}
+
+	var b strings.Builder
+	b.WriteString(title + "\n\n")
+	labelStyle := styles.LabelStyle.Width(14)
+	fmt.Fprintf(&b, "%s %s\n", labelStyle.Render(""), styles.MutedStyle.Render(fmt.Sprintf("%-24s %s", "LOCAL", "SERVER")))
+	for _, row := range rows {
+		line := fmt.Sprintf("%-24s %s", row.local, row.server)
+		if row.mismatch {
+			line = styles.FailStyle.Render(line + "  ≠")
+		}
+		fmt.Fprintf(&b, "%s %s\n", labelStyle.Render(row.field+":"), line)
+	}
+
+	b.WriteString("\n")
+	switch {
+	case len(r.mismatches) == 0:
+		b.WriteString(styles.PassStyle.Render("✓ Keystore matches the server"))
+	default:
+		b.WriteString(styles.WarnStyle.Render("⚠ Keystore differs from the server: " + strings.Join(r.mismatches, ", ")))
+	}
+	for _, hint := range r.hints() {
+		b.WriteString("\n" + styles.MutedStyle.Render(hint))
+	}
+	return b.String()
+}
+
+// runInfoServer prints the reconciliation of stored with the server.
+func runInfoServer(cmd *cobra.Command, ks *config.Keystore, stored *config.StoredInbox) error {
+	ctx := cliutil.CommandContext(cmd)
+	server, err := fetchServerState(ctx, stored)
+	if err != nil {
+		return serverCheckError(err)
+	}
+
+	r := reconcileInbox(stored, stored.Email == ks.ActiveInbox, server, config.ServerNow())
+
+	if cliutil.GetOutput(cmd) == cliutil.FormatJSON {
+		return cliutil.OutputJSON(r.toJSON())
+	}
+	fmt.Println()
+	fmt.Println(styles.BoxStyle.Render(r.format()))
+	fmt.Println()
+	return nil
+}
@@ -0,0 +1,91 @@
+package inbox
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	vaultsandbox "github.com/vaultsandbox/client-go"
+	"github.com/vaultsandbox/vsb-cli/internal/config"
+)
+
+func TestReconcileInbox(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	stored := &config.StoredInbox{
+		Email:     "ci@example.com",
+		CreatedAt: now.Add(-time.Hour),
+		ExpiresAt: now.Add(time.Hour),
+	}
+
+	t.Run("in sync", func(t *testing.T) {
+		r := reconcileInbox(stored, true, &serverInboxState{Exists: true, EmailCount: 3}, now)
+		assert.Empty(t, r.mismatches)
+		assert.Empty(t, r.hints())
+
+		data := r.toJSON()
+		assert.Equal(t, map[string]interface{}{
+			"exists":     true,
+			"emailCount": 3,
+		}, data["server"])
+		drift := data["drift"].(map[string]interface{})
+		assert.Equal(t, true, drift["inSync"])
+		assert.Equal(t, []string{}, drift["mismatches"])
+		assert.Equal(t, true, data["local"].(map[string]interface{})["active"])
+
+		assert.Contains(t, r.format(), "Keystore matches the server")
+	})
+
+	t.Run("gone from the server", func(t *testing.T) {
+		r := reconcileInbox(stored, false, &serverInboxState{}, now)
+		assert.Equal(t, []string{"exists"}, r.mismatches)
+		require.Len(t, r.hints(), 1)
+		assert.Contains(t, r.hints()[0], "vsb inbox delete --local ci@example.com")
+
+		data := r.toJSON()
+		assert.Nil(t, data["server"].(map[string]interface{})["emailCount"])
+		assert.Equal(t, false, data["drift"].(map[string]interface{})["inSync"])
+		assert.Contains(t, r.format(), "differs from the server: exists")
+	})
+
+	t.Run("expired locally but on the server", func(t *testing.T) {
+		expired := *stored
+		expired.ExpiresAt = now.Add(-time.Minute)
+		r := reconcileInbox(&expired, false, &serverInboxState{Exists: true}, now)
+		assert.Equal(t, []string{"expired"}, r.mismatches)
+		assert.Contains(t, r.format(), "differs from the server: expired")
+	})
+}
+
+func TestRunInfoServerErrors(t *testing.T) {
+	t.Setenv("VSB_CONFIG_DIR", t.TempDir())
+	ks, err := config.LoadKeystore()
+	require.NoError(t, err)
+	stored := &config.StoredInbox{Email: "ci@example.com", ExpiresAt: time.Now().Add(time.Hour)}
+
+	old := fetchServerState
+	t.Cleanup(func() { fetchServerState = old })
+
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"unauthorized", &vaultsandbox.APIError{StatusCode: 401}, "server rejected the API key"},
+		{"network", &vaultsandbox.NetworkError{Err: errors.New("connection refused")}, "could not reach the server"},
+		{"other", errors.New("boom"), "server check failed: boom"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			fetchServerState = func(context.Context, *config.StoredInbox) (*serverInboxState, error) {
+				return nil, tc.err
+			}
+			err := runInfoServer(&cobra.Command{}, ks, stored)
+			assert.ErrorContains(t, err, tc.want)
+			assert.ErrorIs(t, err, tc.err)
+		})
+	}
+}
@@ -1,11 +1,15 @@
 package inbox
 
 import (
+	"bytes"
+	"context"
 	"errors"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/vaultsandbox/vsb-cli/internal/config"
 )
 
@@ -19,7 +23,7 @@ func TestFormatInboxInfoContent(t *testing.T) {
 	}
 
 	t.Run("active inbox shows ACTIVE badge", func(t *testing.T) {
-		content := formatInboxInfoContent(baseInbox, true, false, 5, nil)
+		content := formatInboxInfoContent(baseInbox, true, false, 5, 0, nil)
 
 		assert.Contains(t, content, "test@example.com")
 		assert.Contains(t, content, "ACTIVE")
@@ -27,7 +31,7 @@ func TestFormatInboxInfoContent(t *testing.T) {
 	})
 
 	t.Run("inactive inbox does not show ACTIVE badge", func(t *testing.T) {
-		content := formatInboxInfoContent(baseInbox, false, false, 5, nil)
+		content := formatInboxInfoContent(baseInbox, false, false, 5, 0, nil)
 
 		assert.Contains(t, content, "test@example.com")
 		assert.NotContains(t, content, "ACTIVE")
@@ -41,13 +45,13 @@ func TestFormatInboxInfoContent(t *testing.T) {
 			ExpiresAt: now.Add(-24 * time.Hour),
 		}
 
-		content := formatInboxInfoContent(expiredInbox, false, true, 0, nil)
+		content := formatInboxInfoContent(expiredInbox, false, true, 0, 0, nil)
 
 		assert.Contains(t, content, "EXPIRED")
 	})
 
 	t.Run("non-expired inbox shows remaining time", func(t *testing.T) {
-		content := formatInboxInfoContent(baseInbox, false, false, 5, nil)
+		content := formatInboxInfoContent(baseInbox, false, false, 5, 0, nil)
 
 		assert.Contains(t, content, "(1d)")
 		assert.NotContains(t, content, "EXPIRED")
@@ -55,27 +59,42 @@ func TestFormatInboxInfoContent(t *testing.T) {
 
 	t.Run("sync error shows error message", func(t *testing.T) {
 		syncErr := errors.New("connection failed")
-		content := formatInboxInfoContent(baseInbox, false, false, 0, syncErr)
+		content := formatInboxInfoContent(baseInbox, false, false, 0, 0, syncErr)
 
 		assert.Contains(t, content, "(sync error)")
 	})
 
 	t.Run("no sync error shows email count", func(t *testing.T) {
-		content := formatInboxInfoContent(baseInbox, false, false, 42, nil)
+		content := formatInboxInfoContent(baseInbox, false, false, 42, 0, nil)
 
 		assert.Contains(t, content, "42")
 		assert.NotContains(t, content, "(sync error)")
 	})
 
+	t.Run("shows tags when set", func(t *testing.T) {
+		assert.NotContains(t, formatInboxInfoContent(baseInbox, false, false, 0, 0, nil), "Tags:")
+
+		tagged := *baseInbox
+		tagged.Tags = []string{"auth", "billing"}
+		content := formatInboxInfoContent(&tagged, false, false, 0, 0, nil)
+		assert.Contains(t, content, "auth, billing")
+	})
+
+	t.Run("shows unread count", func(t *testing.T) {
+		content := formatInboxInfoContent(baseInbox, false, false, 42, 7, nil)
+
+		assert.Contains(t, content, "42 (7 unread)")
+	})
+
 	t.Run("shows created date formatted", func(t *testing.T) {
-		content := formatInboxInfoContent(baseInbox, false, false, 0, nil)
+		content := formatInboxInfoContent(baseInbox, false, false, 0, 0, nil)
 
 		expectedDate := baseInbox.CreatedAt.Format("2006-01-02 15:04")
 		assert.Contains(t, content, expectedDate)
 	})
 
 	t.Run("shows expiry date when not expired", func(t *testing.T) {
-		content := formatInboxInfoContent(baseInbox, false, false, 0, nil)
+		content := formatInboxInfoContent(baseInbox, false, false, 0, 0, nil)
 
 		expectedDate := baseInbox.ExpiresAt.Format("2006-01-02 15:04")
 		assert.Contains(t, content, expectedDate)
@@ -89,14 +108,14 @@ func TestFormatInboxInfoContent(t *testing.T) {
 			ExpiresAt: now.Add(-24 * time.Hour),
 		}
 
-		content := formatInboxInfoContent(expiredInbox, true, true, 0, nil)
+		content := formatInboxInfoContent(expiredInbox, true, true, 0, 0, nil)
 
 		assert.Contains(t, content, "ACTIVE")
 		assert.Contains(t, content, "EXPIRED")
 	})
 
 	t.Run("zero email count", func(t *testing.T) {
-		content := formatInboxInfoContent(baseInbox, false, false, 0, nil)
+		content := formatInboxInfoContent(baseInbox, false, false, 0, 0, nil)
 
 		// Should show 0 emails, not sync error
 		assert.NotContains(t, content, "(sync error)")
@@ -110,8 +129,60 @@ func TestFormatInboxInfoContent(t *testing.T) {
 			ExpiresAt: now.Add(30 * time.Minute),
 		}
 
-		content := formatInboxInfoContent(shortInbox, false, false, 0, nil)
+		content := formatInboxInfoContent(shortInbox, false, false, 0, 0, nil)
 
 		assert.Contains(t, content, "(30m)")
 	})
 }
+
+func TestRemainingSeconds(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	assert.Equal(t, 3*3600+12*60, remainingSeconds(now.Add(3*time.Hour+12*time.Minute+500*time.Millisecond), now))
+	assert.Equal(t, 0, remainingSeconds(now, now))
+	assert.Equal(t, 0, remainingSeconds(now.Add(-time.Hour), now))
+}
+
+func TestCountdownLine(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	line, expired := countdownLine(now.Add(3*time.Hour+12*time.Minute), now)
+	assert.Contains(t, line, "expires in 3h12m0s")
+	assert.False(t, expired)
+
+	line, expired = countdownLine(now.Add(-time.Second), now)
+	assert.Contains(t, line, "expired")
+	assert.True(t, expired)
+}
+
+func TestRenderCountdown(t *testing.T) {
+	original := countdownInterval
+	t.Cleanup(func() { countdownInterval = original })
+	countdownInterval = 10 * time.Millisecond
+
+	t.Run("prints once when not live", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := renderCountdown(context.Background(), &buf, time.Now().Add(2*time.Hour), false)
+		require.NoError(t, err)
+		assert.Contains(t, buf.String(), "expires in 1h59m")
+		assert.NotContains(t, buf.String(), "\r")
+	})
+
+	t.Run("redraws until expired", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := renderCountdown(context.Background(), &buf, time.Now().Add(30*time.Millisecond), true)
+		require.NoError(t, err)
+		assert.GreaterOrEqual(t, strings.Count(buf.String(), "\r\033[K"), 2)
+		assert.Contains(t, buf.String(), "expired")
+		assert.True(t, strings.HasSuffix(buf.String(), "\n"))
+	})
+
+	t.Run("stops when cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+		defer cancel()
+		var buf bytes.Buffer
+		err := renderCountdown(ctx, &buf, time.Now().Add(time.Hour), true)
+		require.NoError(t, err)
+		assert.Contains(t, buf.String(), "expires in")
+		assert.True(t, strings.HasSuffix(buf.String(), "\n"))
+	})
+}
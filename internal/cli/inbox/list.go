@@ -2,31 +2,99 @@ package inbox
 
 import (
 	"fmt"
+	"io"
+	"os"
+	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/vaultsandbox/vsb-cli/internal/cliutil"
 	"github.com/vaultsandbox/vsb-cli/internal/config"
 	"github.com/vaultsandbox/vsb-cli/internal/styles"
+	"github.com/vaultsandbox/vsb-cli/internal/table"
 )
 
 var listCmd = &cobra.Command{
-	Use:     "list",
-	Short:   "List all stored inboxes",
-	Long:    `Display all inboxes stored in the local keystore.`,
+	Use:   "list",
+	Short: "List all stored inboxes",
+	Long: `Display all inboxes stored in the local keystore.
+
+--format email-only prints one address per line with no decoration, for use
+in shell scripts. --active-only prints just the active inbox address.
+
+--output table prints an aligned table with EMAIL, LABEL, EXPIRES and ACTIVE
+columns. On a terminal long addresses are truncated and the active inbox is
+highlighted (unless NO_COLOR or --color never is set); when piped it is
+plain text, unless --color always is set.
+
+The default table fits the terminal width (COLUMNS when set, 120 when not a
+terminal), shortening long addresses with an ellipsis. --wide keeps them
+whole.
+
+--details asks the server for each inbox's email count and the time of its
+most recent email. Inboxes that cannot be queried (deleted on the server,
+auth errors, or slower than --details-timeout) show "-" and a note on
+stderr; the rest of the list is still printed. --sort last-activity orders
+inboxes by their most recent email and implies --details.
+
+--tag shows only inboxes with the given tag (see 'vsb inbox tag'). Repeat it
+to require several tags.
+
+Examples:
+  vsb inbox list
+  vsb inbox list --all
+  vsb inbox list --details
+  vsb inbox list --tag auth
+  vsb inbox list --sort last-activity --details-timeout 5s
+  vsb inbox list --format email-only
+  vsb inbox list --active-only
+  vsb inbox list -o table
+  vsb inbox list --wide
+  for addr in $(vsb inbox list --format email-only); do vsb email list --inbox "$addr"; done`,
 	Aliases: []string{"ls"},
 	RunE:    runList,
 }
 
 var (
 	listShowExpired bool
+	listFormat      string
+	listActiveOnly  bool
+	listDetails     bool
+	listSort        string
+	listDetailsTime time.Duration
+	listWide        bool
+	listTags        []string
+)
+
+// List formats for --format
+const (
+	listFormatTable     = "table"
+	listFormatEmailOnly = "email-only"
 )
 
+// listSortLastActivity orders inboxes by their most recent email
+const listSortLastActivity = "last-activity"
+
 func init() {
 	Cmd.AddCommand(listCmd)
 
 	listCmd.Flags().BoolVarP(&listShowExpired, "all", "a", false,
 		"Show expired inboxes too")
+	listCmd.Flags().StringVar(&listFormat, "format", listFormatTable,
+		"Pretty output format: table or email-only (one address per line)")
+	listCmd.Flags().BoolVar(&listActiveOnly, "active-only", false,
+		"Print only the active inbox address")
+	listCmd.Flags().BoolVar(&listDetails, "details", false,
+		"Fetch each inbox's email count and last email time from the server")
+	listCmd.Flags().StringVar(&listSort, "sort", "",
+		"Sort order: last-activity (most recent email first, implies --details)")
+	listCmd.Flags().DurationVar(&listDetailsTime, "details-timeout", 10*time.Second,
+		"Maximum time to spend fetching details for one inbox")
+	listCmd.Flags().BoolVar(&listWide, "wide", false,
+		"Do not truncate addresses to fit the terminal")
+	listCmd.Flags().StringArrayVar(&listTags, "tag", nil,
+		"Only show inboxes with this tag (repeatable; all must match)")
+	cliutil.AddOutputFormats(listCmd, cliutil.FormatJSON, cliutil.FormatTable)
 }
 
 // filterInboxes returns inboxes, optionally filtering out expired ones.
@@ -41,41 +109,142 @@ func filterInboxes(inboxes []config.StoredInbox, showExpired bool) []config.Stor
 	return filtered
 }
 
+// filterByTags returns the inboxes that have every one of tags.
+func filterByTags(inboxes []config.StoredInbox, tags []string) []config.StoredInbox {
+	if len(tags) == 0 {
+		return inboxes
+	}
+	var filtered []config.StoredInbox
+	for _, inbox := range inboxes {
+		matches := true
+		for _, tag := range tags {
+			if !inbox.HasTag(tag) {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			filtered = append(filtered, inbox)
+		}
+	}
+	return filtered
+}
+
+// writeEmailOnly prints one address per line.
+func writeEmailOnly(w io.Writer, inboxes []config.StoredInbox) {
+	for _, inbox := range inboxes {
+		fmt.Fprintln(w, inbox.Email)
+	}
+}
+
 func runList(cmd *cobra.Command, args []string) error {
+	if listFormat != listFormatTable && listFormat != listFormatEmailOnly {
+		return fmt.Errorf("invalid --format: %s (valid: table, email-only)", listFormat)
+	}
+	if listSort != "" && listSort != listSortLastActivity {
+		return fmt.Errorf("invalid --sort: %s (valid: last-activity)", listSort)
+	}
+	if listDetailsTime <= 0 {
+		return fmt.Errorf("--details-timeout must be positive")
+	}
+	details := listDetails || listSort == listSortLastActivity
+
 	keystore, err := cliutil.LoadKeystoreOrError()
 	if err != nil {
 		return err
 	}
 
 	inboxes := keystore.ListInboxes()
-	filtered := filterInboxes(inboxes, listShowExpired)
+	filtered := filterByTags(filterInboxes(inboxes, listShowExpired), listTags)
+
+	if listActiveOnly {
+		active, err := activeInbox(filtered, keystore.ActiveInbox)
+		if err != nil {
+			return err
+		}
+		filtered = []config.StoredInbox{*active}
+	}
+
+	// Plain addresses have no room for details, so skip the server round trips
+	jsonOutput := cliutil.GetOutput(cmd) == cliutil.FormatJSON
+	if !jsonOutput && (listFormat == listFormatEmailOnly || listActiveOnly) {
+		details = false
+	}
+	var activity []inboxActivity
+	if details && len(filtered) > 0 {
+		fetch, cleanup, err := newActivityFetcher()
+		if err != nil {
+			return err
+		}
+		activity = collectInboxActivity(cliutil.CommandContext(cmd), filtered, listDetailsTime, fetch)
+		cleanup()
+		for i, a := range activity {
+			if a.Err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: could not fetch details for %s: %v\n", filtered[i].Email, a.Err)
+			}
+		}
+		if listSort == listSortLastActivity {
+			sortByLastActivity(filtered, activity)
+		}
+	}
 
 	// JSON output
-	if cliutil.GetOutput(cmd) == "json" {
-		now := time.Now()
+	if jsonOutput {
+		now := config.ServerNow()
 		var result []map[string]interface{}
-		for _, inbox := range filtered {
+		for i, inbox := range filtered {
 			isActive := inbox.Email == keystore.ActiveInbox
-			result = append(result, cliutil.InboxSummaryJSON(&inbox, isActive, now))
+			data := cliutil.InboxSummaryJSON(&inbox, isActive, now)
+			if activity != nil {
+				addActivityJSON(data, activity[i])
+			}
+			result = append(result, data)
 		}
 		return cliutil.OutputJSON(result)
 	}
 
+	if listFormat == listFormatEmailOnly || listActiveOnly {
+		writeEmailOnly(os.Stdout, filtered)
+		return nil
+	}
+
+	if cliutil.GetOutput(cmd) == cliutil.FormatTable {
+		return writeInboxTable(os.Stdout, cliutil.StdoutTableWidth(), filtered, keystore.ActiveInbox, activity)
+	}
+
 	// Pretty output
 	if len(filtered) == 0 {
+		if len(listTags) > 0 {
+			fmt.Printf("No inboxes tagged %s\n", strings.Join(listTags, ", "))
+			return nil
+		}
 		fmt.Println("No inboxes found. Create one with 'vsb inbox create'")
 		return nil
 	}
 
-	// Header
-	table := cliutil.NewTable(
-		cliutil.Column{Header: "EMAIL", Width: styles.ColWidthEmail},
-		cliutil.Column{Header: "EXPIRES"},
-	).WithIndent("   ")
-	table.PrintHeader()
+	writeInboxesPretty(os.Stdout, table.Width(), filtered, keystore.ActiveInbox, activity)
+	fmt.Println()
+	return nil
+}
+
+// writeInboxesPretty writes the pretty inbox table laid out for width
+// columns, with the --details columns when activity is not nil. Addresses
+// shrink to fit unless --wide is set.
+func writeInboxesPretty(w io.Writer, width int, inboxes []config.StoredInbox, active string, activity []inboxActivity) {
+	columns := []table.Column{
+		{Header: "EMAIL", Width: styles.ColWidthEmail, Flex: true},
+		{Header: "EXPIRES", Width: colWidthExpires},
+	}
+	if activity != nil {
+		columns = append(columns,
+			table.Column{Header: "COUNT", Width: colWidthCount},
+			table.Column{Header: "LAST EMAIL", Width: colWidthLastEmail})
+	}
+	t := table.New(w, width, columns...).WithWide(listWide)
+	t.WriteHeader()
 
-	for _, inbox := range filtered {
-		isActive := inbox.Email == keystore.ActiveInbox
+	for i, inbox := range inboxes {
+		isActive := inbox.Email == active
 		isExpired := cliutil.IsExpired(inbox.ExpiresAt)
 
 		// Active marker
@@ -85,7 +254,7 @@ func runList(cmd *cobra.Command, args []string) error {
 		}
 
 		// Email (pad before styling to preserve alignment)
-		emailPadded := fmt.Sprintf("%-*s", styles.ColWidthEmail, inbox.Email)
+		emailPadded := t.Cell(0, inbox.Email)
 		if isExpired {
 			emailPadded = styles.ExpiredStyle.Render(emailPadded)
 		} else if isActive {
@@ -94,14 +263,104 @@ func runList(cmd *cobra.Command, args []string) error {
 
 		// Expiry
 		expiry := cliutil.FormatExpiry(inbox.ExpiresAt)
+		if activity != nil {
+			expiry = t.Cell(1, expiry)
+		}
 		if isExpired {
 			expiry = styles.ExpiredStyle.Render(expiry)
 		}
 
-		fmt.Printf("%s%s  %s\n", marker, emailPadded, expiry)
+		if activity == nil {
+			fmt.Fprintf(w, "%s%s  %s\n", marker, emailPadded, expiry)
+			continue
+		}
+		count, last := activityColumns(activity[i])
+		fmt.Fprintf(w, "%s%s  %s  %s  %s\n", marker, emailPadded, expiry, t.Cell(2, count), last)
 	}
+}
 
-	fmt.Println()
-	return nil
+// writeInboxTable renders inboxes for --output table, with the --details
+// columns when activity is not nil. The active inbox is highlighted and
+// expired ones dimmed. Addresses and labels shrink to fit width columns
+// unless width is 0 or --wide is set.
+func writeInboxTable(w io.Writer, width int, inboxes []config.StoredInbox, active string, activity []inboxActivity) error {
+	headers := []string{"EMAIL", "LABEL", "EXPIRES", "ACTIVE"}
+	if activity != nil {
+		headers = append(headers, "COUNT", "LAST EMAIL")
+	}
+	t := cliutil.NewTableWriter(w, headers...).
+		SetMaxWidth(0, styles.ColWidthEmail).
+		SetMaxWidth(1, colWidthLabel)
+	t.Width = width
+	t.Truncate = width > 0 && !listWide
+
+	for i, inbox := range inboxes {
+		isActive := inbox.Email == active
+		activeCell := ""
+		if isActive {
+			activeCell = "yes"
+		}
+		cells := []string{inbox.Email, inbox.Label, cliutil.FormatExpiry(inbox.ExpiresAt), activeCell}
+		if activity != nil {
+			count, last := activityColumns(activity[i])
+			cells = append(cells, count, last)
+		}
+
+		switch {
+		case cliutil.IsExpired(inbox.ExpiresAt):
+			t.AddStyledRow(styles.ExpiredStyle, cells...)
+		case isActive:
+			t.AddStyledRow(styles.ActiveStyle, cells...)
+		default:
+			t.AddRow(cells...)
+		}
+	}
+	return t.Render()
+}
+
+// Column widths for the --details table
+const (
+	colWidthExpires   = 10
+	colWidthCount     = 5
+	colWidthLabel     = 20
+	colWidthLastEmail = 14
+)
+
+// activityColumns formats the COUNT and LAST EMAIL cells, "-" when the
+// details are unknown.
+func activityColumns(a inboxActivity) (count, last string) {
+	if !a.known() {
+		return "-", "-"
+	}
+	if a.LastEmailAt.IsZero() {
+		return fmt.Sprint(a.Count), "-"
+	}
+	return fmt.Sprint(a.Count), cliutil.FormatRelativeTime(a.LastEmailAt)
 }
 
+// addActivityJSON adds the --details fields to an inbox's JSON. Unknown
+// values are null, with the reason in detailsError when the fetch failed.
+func addActivityJSON(data map[string]interface{}, a inboxActivity) {
+	data["emailCount"] = nil
+	data["lastEmailAt"] = nil
+	if a.Err != nil {
+		data["detailsError"] = a.Err.Error()
+	}
+	if !a.known() {
+		return
+	}
+	data["emailCount"] = a.Count
+	if !a.LastEmailAt.IsZero() {
+		data["lastEmailAt"] = a.LastEmailAt.Format(time.RFC3339)
+	}
+}
+
+// activeInbox returns the active inbox from inboxes.
+func activeInbox(inboxes []config.StoredInbox, active string) (*config.StoredInbox, error) {
+	for i := range inboxes {
+		if inboxes[i].Email == active {
+			return &inboxes[i], nil
+		}
+	}
+	return nil, fmt.Errorf("no active inbox. Create one with 'vsb inbox create' or set with 'vsb inbox use'")
+}
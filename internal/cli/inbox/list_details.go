@@ -0,0 +1,130 @@
+package inbox
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	vaultsandbox "github.com/vaultsandbox/client-go"
+	"github.com/vaultsandbox/vsb-cli/internal/cliutil"
+	"github.com/vaultsandbox/vsb-cli/internal/config"
+)
+
+// detailsConcurrency bounds how many inboxes --details queries at once
+const detailsConcurrency = 4
+
+// inboxActivity is what --details reports for one inbox. Err is set when the
+// server could not be asked; Skipped when the inbox has expired locally and
+// was not asked at all.
+type inboxActivity struct {
+	Count       int
+	LastEmailAt time.Time // zero if the inbox has no emails
+	Err         error
+	Skipped     bool
+}
+
+// known reports whether the activity was fetched successfully.
+func (a inboxActivity) known() bool {
+	return a.Err == nil && !a.Skipped
+}
+
+// activityFetcher returns the activity of one inbox.
+type activityFetcher func(ctx context.Context, stored config.StoredInbox) (inboxActivity, error)
+
+// newActivityFetcher is a variable so tests can replace the server calls. It
+// returns the fetcher and a cleanup function.
+var newActivityFetcher = func() (activityFetcher, func(), error) {
+	client, err := config.NewClient()
+	if err != nil {
+		return nil, func() {}, err
+	}
+	fetch := func(ctx context.Context, stored config.StoredInbox) (inboxActivity, error) {
+		inbox, err := client.ImportInbox(ctx, stored.ToExportedInbox())
+		if err != nil {
+			return inboxActivity{}, err
+		}
+		metadata, err := inbox.GetEmailsMetadataOnly(ctx)
+		if err != nil {
+			return inboxActivity{}, err
+		}
+		return activityFromMetadata(metadata), nil
+	}
+	return fetch, func() { client.Close() }, nil
+}
+
+func activityFromMetadata(metadata []*vaultsandbox.EmailMetadata) inboxActivity {
+	a := inboxActivity{Count: len(metadata)}
+	for _, m := range metadata {
+		if m.ReceivedAt.After(a.LastEmailAt) {
+			a.LastEmailAt = m.ReceivedAt
+		}
+	}
+	return a
+}
+
+// collectInboxActivity fetches the activity of every inbox, at most
+// detailsConcurrency at a time. Each fetch is bounded by timeout as well as
+// ctx, so one unresponsive inbox cannot hold up the rest. Locally expired
+// inboxes are skipped. Results are in the order of inboxes.
+func collectInboxActivity(ctx context.Context, inboxes []config.StoredInbox, timeout time.Duration, fetch activityFetcher) []inboxActivity {
+	results := make([]inboxActivity, len(inboxes))
+	sem := make(chan struct{}, detailsConcurrency)
+	var wg sync.WaitGroup
+
+	for i, stored := range inboxes {
+		if cliutil.IsExpired(stored.ExpiresAt) {
+			results[i] = inboxActivity{Skipped: true}
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int, stored config.StoredInbox) {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				results[i] = inboxActivity{Err: ctx.Err()}
+				return
+			}
+
+			fetchCtx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+			a, err := fetch(fetchCtx, stored)
+			if err != nil {
+				if fetchCtx.Err() == context.DeadlineExceeded && ctx.Err() == nil {
+					err = fmt.Errorf("timed out after %s", timeout)
+				}
+				a = inboxActivity{Err: err}
+			}
+			results[i] = a
+		}(i, stored)
+	}
+
+	wg.Wait()
+	return results
+}
+
+// sortByLastActivity orders inboxes (and their activity) by most recent
+// email first. Inboxes without emails or details keep their relative order
+// at the end.
+func sortByLastActivity(inboxes []config.StoredInbox, activity []inboxActivity) {
+	idx := make([]int, len(inboxes))
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.SliceStable(idx, func(a, b int) bool {
+		return activity[idx[a]].LastEmailAt.After(activity[idx[b]].LastEmailAt)
+	})
+
+	sortedInboxes := make([]config.StoredInbox, len(inboxes))
+	sortedActivity := make([]inboxActivity, len(activity))
+	for to, from := range idx {
+		sortedInboxes[to] = inboxes[from]
+		sortedActivity[to] = activity[from]
+	}
+	copy(inboxes, sortedInboxes)
+	copy(activity, sortedActivity)
+}
@@ -0,0 +1,150 @@
+package inbox
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	vaultsandbox "github.com/vaultsandbox/client-go"
+	"github.com/vaultsandbox/vsb-cli/internal/config"
+)
+
+func TestActivityFromMetadata(t *testing.T) {
+	older := time.Date(2026, 1, 2, 10, 0, 0, 0, time.UTC)
+	newer := older.Add(time.Hour)
+
+	a := activityFromMetadata([]*vaultsandbox.EmailMetadata{
+		{ID: "1", ReceivedAt: newer},
+		{ID: "2", ReceivedAt: older},
+	})
+	assert.Equal(t, 2, a.Count)
+	assert.Equal(t, newer, a.LastEmailAt)
+
+	a = activityFromMetadata(nil)
+	assert.Equal(t, 0, a.Count)
+	assert.True(t, a.LastEmailAt.IsZero())
+}
+
+func TestCollectInboxActivity(t *testing.T) {
+	future := time.Now().Add(time.Hour)
+	last := time.Date(2026, 1, 2, 10, 0, 0, 0, time.UTC)
+	inboxes := []config.StoredInbox{
+		{Email: "ok@example.com", ExpiresAt: future},
+		{Email: "broken@example.com", ExpiresAt: future},
+		{Email: "hangs@example.com", ExpiresAt: future},
+		{Email: "expired@example.com", ExpiresAt: time.Now().Add(-time.Hour)},
+	}
+
+	var calls atomic.Int32
+	fetch := func(ctx context.Context, stored config.StoredInbox) (inboxActivity, error) {
+		calls.Add(1)
+		switch stored.Email {
+		case "broken@example.com":
+			return inboxActivity{}, errors.New("inbox not found")
+		case "hangs@example.com":
+			<-ctx.Done()
+			return inboxActivity{}, ctx.Err()
+		}
+		return inboxActivity{Count: 3, LastEmailAt: last}, nil
+	}
+
+	start := time.Now()
+	got := collectInboxActivity(context.Background(), inboxes, 50*time.Millisecond, fetch)
+	assert.Less(t, time.Since(start), 5*time.Second)
+
+	require.Len(t, got, 4)
+	assert.Equal(t, inboxActivity{Count: 3, LastEmailAt: last}, got[0])
+	assert.ErrorContains(t, got[1].Err, "inbox not found")
+	assert.ErrorContains(t, got[2].Err, "timed out after 50ms")
+	assert.True(t, got[3].Skipped)
+	assert.Equal(t, int32(3), calls.Load(), "expired inboxes are not fetched")
+}
+
+func TestCollectInboxActivityBoundsConcurrency(t *testing.T) {
+	inboxes := make([]config.StoredInbox, 10)
+	for i := range inboxes {
+		inboxes[i] = config.StoredInbox{Email: "inbox@example.com", ExpiresAt: time.Now().Add(time.Hour)}
+	}
+
+	var running, peak atomic.Int32
+	fetch := func(ctx context.Context, stored config.StoredInbox) (inboxActivity, error) {
+		n := running.Add(1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		running.Add(-1)
+		return inboxActivity{}, nil
+	}
+
+	collectInboxActivity(context.Background(), inboxes, time.Second, fetch)
+	assert.LessOrEqual(t, peak.Load(), int32(detailsConcurrency))
+}
+
+func TestSortByLastActivity(t *testing.T) {
+	base := time.Date(2026, 1, 2, 10, 0, 0, 0, time.UTC)
+	inboxes := []config.StoredInbox{
+		{Email: "empty@example.com"},
+		{Email: "old@example.com"},
+		{Email: "failed@example.com"},
+		{Email: "recent@example.com"},
+	}
+	activity := []inboxActivity{
+		{Count: 0},
+		{Count: 1, LastEmailAt: base},
+		{Err: errors.New("boom")},
+		{Count: 2, LastEmailAt: base.Add(time.Hour)},
+	}
+
+	sortByLastActivity(inboxes, activity)
+
+	emails := make([]string, len(inboxes))
+	for i, inbox := range inboxes {
+		emails[i] = inbox.Email
+	}
+	assert.Equal(t, []string{"recent@example.com", "old@example.com", "empty@example.com", "failed@example.com"}, emails)
+	assert.Equal(t, 2, activity[0].Count)
+	assert.Error(t, activity[3].Err)
+}
+
+func TestActivityColumns(t *testing.T) {
+	count, last := activityColumns(inboxActivity{Count: 4, LastEmailAt: time.Now().Add(-2 * time.Hour)})
+	assert.Equal(t, "4", count)
+	assert.Equal(t, "2h ago", last)
+
+	count, last = activityColumns(inboxActivity{})
+	assert.Equal(t, "0", count)
+	assert.Equal(t, "-", last)
+
+	count, last = activityColumns(inboxActivity{Err: errors.New("boom")})
+	assert.Equal(t, "-", count)
+	assert.Equal(t, "-", last)
+}
+
+func TestAddActivityJSON(t *testing.T) {
+	last := time.Date(2026, 1, 2, 10, 0, 0, 0, time.UTC)
+
+	data := map[string]interface{}{}
+	addActivityJSON(data, inboxActivity{Count: 2, LastEmailAt: last})
+	assert.Equal(t, 2, data["emailCount"])
+	assert.Equal(t, "2026-01-02T10:00:00Z", data["lastEmailAt"])
+	assert.NotContains(t, data, "detailsError")
+
+	data = map[string]interface{}{}
+	addActivityJSON(data, inboxActivity{})
+	assert.Equal(t, 0, data["emailCount"])
+	assert.Nil(t, data["lastEmailAt"])
+
+	data = map[string]interface{}{}
+	addActivityJSON(data, inboxActivity{Err: errors.New("unauthorized")})
+	assert.Contains(t, data, "emailCount")
+	assert.Nil(t, data["emailCount"])
+	assert.Equal(t, "unauthorized", data["detailsError"])
+}
@@ -0,0 +1,54 @@
+package inbox
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/vaultsandbox/vsb-cli/internal/cliutil"
+	"github.com/vaultsandbox/vsb-cli/internal/styles"
+)
+
+var listDomainsCmd = &cobra.Command{
+	Use:   "list-domains",
+	Short: "List the domains inboxes can be created on",
+	Long: `List the email domains the server allows for new inboxes.
+
+Pass one of them to 'vsb inbox create --domain'.
+
+Examples:
+  vsb inbox list-domains
+  vsb inbox list-domains -o json`,
+	Args: cobra.NoArgs,
+	RunE: runListDomains,
+}
+
+func init() {
+	Cmd.AddCommand(listDomainsCmd)
+	cliutil.AddOutputFormats(listDomainsCmd, cliutil.FormatJSON)
+}
+
+func runListDomains(cmd *cobra.Command, args []string) error {
+	client, err := newClientFunc()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	domains := []string{}
+	if info := client.ServerInfo(); info != nil && info.AllowedDomains != nil {
+		domains = info.AllowedDomains
+	}
+
+	if cliutil.GetOutput(cmd) == cliutil.FormatJSON {
+		return cliutil.OutputJSON(domains)
+	}
+
+	if len(domains) == 0 {
+		fmt.Println(styles.MutedStyle.Render("Server reports no allowed domains."))
+		return nil
+	}
+	for _, d := range domains {
+		fmt.Println(d)
+	}
+	return nil
+}
@@ -1,10 +1,12 @@
 package inbox
 
 import (
+	"bytes"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/vaultsandbox/vsb-cli/internal/config"
 )
 
@@ -70,3 +72,117 @@ func TestFilterInboxes(t *testing.T) {
 		assert.Equal(t, "active@example.com", result[1].Email)
 	})
 }
+
+func TestFilterByTags(t *testing.T) {
+	inboxes := []config.StoredInbox{
+		{Email: "auth@example.com", Tags: []string{"auth"}},
+		{Email: "both@example.com", Tags: []string{"auth", "billing"}},
+		{Email: "plain@example.com"},
+	}
+	emails := func(inboxes []config.StoredInbox) []string {
+		var out []string
+		for _, inbox := range inboxes {
+			out = append(out, inbox.Email)
+		}
+		return out
+	}
+
+	assert.Len(t, filterByTags(inboxes, nil), 3)
+	assert.Equal(t, []string{"auth@example.com", "both@example.com"}, emails(filterByTags(inboxes, []string{"auth"})))
+	assert.Equal(t, []string{"both@example.com"}, emails(filterByTags(inboxes, []string{"auth", "billing"})))
+	assert.Empty(t, filterByTags(inboxes, []string{"other"}))
+}
+
+func TestWriteEmailOnly(t *testing.T) {
+	inboxes := []config.StoredInbox{
+		{Email: "one@example.com", Label: "first"},
+		{Email: "two@example.com"},
+	}
+
+	var buf bytes.Buffer
+	writeEmailOnly(&buf, inboxes)
+	assert.Equal(t, "one@example.com\ntwo@example.com\n", buf.String())
+
+	t.Run("empty list prints nothing", func(t *testing.T) {
+		var buf bytes.Buffer
+		writeEmailOnly(&buf, nil)
+		assert.Empty(t, buf.String())
+	})
+}
+
+func TestWriteInboxesPretty(t *testing.T) {
+	inboxes := []config.StoredInbox{
+		{Email: "signup-test-3f9a1c2e5b7d@vaultsandbox.example.com", ExpiresAt: time.Now().Add(-time.Hour)},
+	}
+
+	t.Run("narrow terminal truncates addresses", func(t *testing.T) {
+		var buf bytes.Buffer
+		writeInboxesPretty(&buf, 40, inboxes, "", nil)
+		assert.Equal(t, "\n"+
+			"  EMAIL                       EXPIRES\n"+
+			"----------------------------------------\n"+
+			"  signup-test-3f9a1c2e5b7d@…  expired\n", buf.String())
+	})
+
+	t.Run("wide keeps whole addresses", func(t *testing.T) {
+		listWide = true
+		t.Cleanup(func() { listWide = false })
+
+		var buf bytes.Buffer
+		writeInboxesPretty(&buf, 40, inboxes, "", nil)
+		assert.Contains(t, buf.String(), "  signup-test-3f9a1c2e5b7d@vaultsandbox.example.com  expired\n")
+	})
+}
+
+func TestWriteInboxTable(t *testing.T) {
+	inboxes := []config.StoredInbox{
+		{Email: "one@example.com", Label: "signup", ExpiresAt: time.Now().Add(-time.Hour)},
+		{Email: "two-longer@example.com", ExpiresAt: time.Now().Add(-time.Minute)},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, writeInboxTable(&buf, 0, inboxes, "two-longer@example.com", nil))
+	assert.Equal(t, ""+
+		"EMAIL                   LABEL   EXPIRES  ACTIVE\n"+
+		"one@example.com         signup  expired\n"+
+		"two-longer@example.com          expired  yes\n", buf.String())
+
+	t.Run("details columns", func(t *testing.T) {
+		var buf bytes.Buffer
+		activity := []inboxActivity{{Count: 3}, {Err: assert.AnError}}
+		require.NoError(t, writeInboxTable(&buf, 0, inboxes, "", activity))
+		assert.Equal(t, ""+
+			"EMAIL                   LABEL   EXPIRES  ACTIVE  COUNT  LAST EMAIL\n"+
+			"one@example.com         signup  expired          3      -\n"+
+			"two-longer@example.com          expired          -      -\n", buf.String())
+	})
+
+	t.Run("fits width", func(t *testing.T) {
+		long := []config.StoredInbox{
+			{Email: "signup-test-3f9a1c2e5b7d@vaultsandbox.example.com", Label: "nightly-regression", ExpiresAt: time.Now().Add(-time.Hour)},
+		}
+
+		var buf bytes.Buffer
+		require.NoError(t, writeInboxTable(&buf, 50, long, "", nil))
+		assert.Equal(t, ""+
+			"EMAIL                   LABEL      EXPIRES  ACTIVE\n"+
+			"signup-test-3f9a1c2e5…  nightly-…  expired\n", buf.String())
+	})
+}
+
+func TestActiveInbox(t *testing.T) {
+	inboxes := []config.StoredInbox{
+		{Email: "one@example.com"},
+		{Email: "two@example.com"},
+	}
+
+	active, err := activeInbox(inboxes, "two@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, "two@example.com", active.Email)
+
+	_, err = activeInbox(inboxes, "gone@example.com")
+	assert.Error(t, err)
+
+	_, err = activeInbox(inboxes, "")
+	assert.Error(t, err)
+}
@@ -0,0 +1,104 @@
+package inbox
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/vaultsandbox/vsb-cli/internal/cliutil"
+	"github.com/vaultsandbox/vsb-cli/internal/config"
+	"github.com/vaultsandbox/vsb-cli/internal/styles"
+)
+
+var tagCmd = &cobra.Command{
+	Use:   "tag <inbox> <tag>...",
+	Short: "Add tags to an inbox",
+	Long: `Add one or more tags to an inbox, to group inboxes by purpose (for
+example one inbox per service under test). Tags the inbox already has are
+left alone.
+
+The inbox is resolved like 'vsb inbox use': exact address, label, start of
+the address, then anywhere in the address. Tags are stored in the keystore,
+shown by 'vsb inbox info' and in 'vsb inbox list -o json', travel with the
+inbox through 'vsb export' and 'vsb import', and select inboxes with
+'vsb inbox list --tag'.
+
+Examples:
+  vsb inbox tag signup auth billing
+  vsb inbox list --tag auth`,
+	Args: cobra.MinimumNArgs(2),
+	RunE: runTag,
+}
+
+var untagCmd = &cobra.Command{
+	Use:   "untag <inbox> <tag>...",
+	Short: "Remove tags from an inbox",
+	Long: `Remove one or more tags from an inbox. Tags the inbox does not have are
+ignored.
+
+Examples:
+  vsb inbox untag signup billing`,
+	Args: cobra.MinimumNArgs(2),
+	RunE: runUntag,
+}
+
+// tagPattern restricts tags to something easy to type and to pass to --tag
+var tagPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+func init() {
+	Cmd.AddCommand(tagCmd, untagCmd)
+	cliutil.AddOutputFormats(tagCmd, cliutil.FormatJSON)
+	cliutil.AddOutputFormats(untagCmd, cliutil.FormatJSON)
+	cliutil.MarkSavesState(tagCmd)
+	cliutil.MarkSavesState(untagCmd)
+}
+
+func runTag(cmd *cobra.Command, args []string) error {
+	tags := args[1:]
+	for _, tag := range tags {
+		if !tagPattern.MatchString(tag) {
+			return fmt.Errorf("invalid tag %q (use letters, digits, '.', '_' and '-')", tag)
+		}
+	}
+	return updateTags(cmd, args[0], tags, (*config.Keystore).TagInbox, "Tagged")
+}
+
+func runUntag(cmd *cobra.Command, args []string) error {
+	return updateTags(cmd, args[0], args[1:], (*config.Keystore).UntagInbox, "Untagged")
+}
+
+// updateTags applies change to the tags of the inbox selected by partial
+// and reports the inbox's tags afterwards.
+func updateTags(cmd *cobra.Command, partial string, tags []string,
+	change func(*config.Keystore, string, []string) ([]string, error), verb string) error {
+	ks, err := cliutil.LoadKeystoreOrError()
+	if err != nil {
+		return err
+	}
+	stored, err := cliutil.GetInbox(ks, partial)
+	if err != nil {
+		return err
+	}
+	result, err := change(ks, stored.Email, tags)
+	if err != nil {
+		return err
+	}
+	if result == nil {
+		result = []string{}
+	}
+
+	if cliutil.GetOutput(cmd) == cliutil.FormatJSON {
+		return cliutil.OutputJSON(map[string]interface{}{
+			"inbox": stored.Email,
+			"tags":  result,
+		})
+	}
+
+	current := "(none)"
+	if len(result) > 0 {
+		current = strings.Join(result, ", ")
+	}
+	fmt.Println(styles.PassStyle.Render(fmt.Sprintf("✓ %s %s; tags: %s", verb, stored.Email, current)))
+	return nil
+}
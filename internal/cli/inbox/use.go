@@ -13,8 +13,9 @@ var useCmd = &cobra.Command{
 	Short: "Switch active inbox",
 	Long: `Set the active inbox for commands.
 
-Supports partial matching - if only one inbox contains the given string,
-it will be selected automatically.
+Supports partial matching. The identifier is resolved in order: exact
+address, label, start of the address, then anywhere in the address. If
+several inboxes match at the same level, they are listed so you can pick one.
 
 Examples:
   vsb inbox use test@abc123.vsx.email
@@ -25,6 +26,7 @@ Examples:
 
 func init() {
 	Cmd.AddCommand(useCmd)
+	cliutil.MarkSavesState(useCmd)
 }
 
 func runUse(cmd *cobra.Command, args []string) error {
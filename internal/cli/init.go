@@ -0,0 +1,388 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	vaultsandbox "github.com/vaultsandbox/client-go"
+	"github.com/vaultsandbox/vsb-cli/internal/cliutil"
+	"github.com/vaultsandbox/vsb-cli/internal/config"
+	"github.com/vaultsandbox/vsb-cli/internal/styles"
+	"github.com/vaultsandbox/vsb-cli/internal/timeparse"
+)
+
+var initCmd = &cobra.Command{
+	Use:   "init",
+	Short: "Set up vsb for the first time",
+	Long: `Guide first-time setup:
+
+  1. Server URL and API key (the key is checked against the server)
+  2. Default lifetime of new inboxes
+  3. Delivery strategy: sse (real-time) or polling
+  4. Optionally, a first inbox
+
+Each answer is validated before moving on. If a configuration already
+exists you are asked before it is overwritten.
+
+With --non-interactive nothing is asked: answers come from the flags, the
+API key is required, and an existing configuration is only replaced with
+--force.
+
+Examples:
+  vsb init
+  vsb init --non-interactive --api-key vsb_abc123 --create-inbox
+  vsb init --non-interactive --api-key vsb_abc123 --ttl 2h --strategy polling --force`,
+	Args: cobra.NoArgs,
+	RunE: runInit,
+}
+
+var (
+	initNonInteractive bool
+	initAPIKey         string
+	initBaseURL        string
+	initTTL            string
+	initStrategy       string
+	initCreateInbox    bool
+	initForce          bool
+)
+
+// initAnswers are the settings collected by init.
+type initAnswers struct {
+	BaseURL     string
+	APIKey      string
+	DefaultTTL  string
+	Strategy    string
+	CreateInbox bool
+}
+
+// checkAPIKeyFunc verifies an API key against the server (overridden in tests)
+var checkAPIKeyFunc = func(apiKey, baseURL string) error {
+	client, err := vaultsandbox.New(apiKey,
+		vaultsandbox.WithBaseURL(baseURL),
+		vaultsandbox.WithDeliveryStrategy(vaultsandbox.StrategyPolling),
+		vaultsandbox.WithHTTPClient(config.NewHTTPClient(15*time.Second)))
+	if err != nil {
+		return err
+	}
+	return client.Close()
+}
+
+// createFirstInboxFunc creates an inbox with the new settings and stores it
+// as the active inbox (overridden in tests)
+var createFirstInboxFunc = func(ctx context.Context, answers initAnswers) (*config.StoredInbox, error) {
+	ttl, err := timeparse.Duration(answers.DefaultTTL)
+	if err != nil {
+		return nil, err
+	}
+	client, err := vaultsandbox.New(answers.APIKey,
+		vaultsandbox.WithBaseURL(answers.BaseURL),
+		vaultsandbox.WithDeliveryStrategy(vaultsandbox.StrategyPolling),
+		vaultsandbox.WithHTTPClient(config.NewHTTPClient(config.DefaultHTTPTimeout)))
+	if err != nil {
+		return nil, err
+	}
+	defer client.Close()
+
+	inbox, err := client.CreateInbox(ctx, vaultsandbox.WithTTL(ttl))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create inbox: %w", err)
+	}
+	stored := config.StoredInboxFromExport(inbox.Export())
+	if err := saveFirstInbox(stored); err != nil {
+		return nil, cliutil.RollbackCreate(ctx, client, stored.Email, err)
+	}
+	return &stored, nil
+}
+
+// saveFirstInbox adds the inbox created by init to the keystore
+func saveFirstInbox(stored config.StoredInbox) error {
+	ks, err := cliutil.LoadKeystoreOrError()
+	if err != nil {
+		return err
+	}
+	return ks.AddInbox(stored)
+}
+
+func init() {
+	rootCmd.AddCommand(initCmd)
+	cliutil.AddOutputFormats(initCmd, cliutil.FormatJSON)
+	cliutil.MarkSavesState(initCmd)
+
+	initCmd.Flags().BoolVar(&initNonInteractive, "non-interactive", false,
+		"Take answers from flags instead of prompting")
+	initCmd.Flags().StringVar(&initAPIKey, "api-key", "",
+		"API key (required with --non-interactive)")
+	initCmd.Flags().StringVar(&initBaseURL, "base-url", "",
+		"API server URL (default: https://api.vaultsandbox.com)")
+	initCmd.Flags().StringVar(&initTTL, "ttl", "",
+		"Default lifetime of new inboxes, e.g. 1h or 7d (default: 24h)")
+	initCmd.Flags().StringVar(&initStrategy, "strategy", "",
+		"Delivery strategy: sse or polling (default: sse)")
+	initCmd.Flags().BoolVar(&initCreateInbox, "create-inbox", false,
+		"Create a first inbox (with --non-interactive)")
+	initCmd.Flags().BoolVar(&initForce, "force", false,
+		"Overwrite an existing configuration without asking")
+}
+
+func runInit(cmd *cobra.Command, args []string) error {
+	ctx := cliutil.CommandContext(cmd)
+	jsonMode := cliutil.GetOutput(cmd) == cliutil.FormatJSON
+
+	// Prompts go to stderr when stdout is reserved for JSON
+	var out io.Writer = os.Stdout
+	if jsonMode {
+		out = os.Stderr
+	}
+	reader := bufio.NewReader(os.Stdin)
+
+	configPath, err := config.Path()
+	if err != nil {
+		return fmt.Errorf("failed to get config path: %w", err)
+	}
+	if _, err := os.Stat(configPath); err == nil && !initForce {
+		if initNonInteractive {
+			return fmt.Errorf("configuration already exists at %s (use --force to overwrite)", configPath)
+		}
+		fmt.Fprintf(out, "A configuration already exists at %s.\n", configPath)
+		if !confirm(reader, out, "Overwrite it? [y/N]: ") {
+			fmt.Fprintln(out, "Keeping the existing configuration.")
+			return nil
+		}
+		fmt.Fprintln(out)
+	}
+
+	var answers initAnswers
+	if initNonInteractive {
+		answers, err = initAnswersFromFlags()
+	} else {
+		answers, err = promptInitAnswers(reader, out, initDefaults())
+	}
+	if err != nil {
+		return err
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	cfg.BaseURL = answers.BaseURL
+	cfg.APIKey = answers.APIKey
+	cfg.DefaultTTL = answers.DefaultTTL
+	cfg.Strategy = answers.Strategy
+	if err := config.Save(cfg); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+
+	var inbox *config.StoredInbox
+	if answers.CreateInbox {
+		if !jsonMode {
+			fmt.Fprintln(out, styles.MutedStyle.Render("• Creating your first inbox..."))
+		}
+		inbox, err = createFirstInboxFunc(ctx, answers)
+		if err != nil {
+			return err
+		}
+	}
+
+	if jsonMode {
+		data := map[string]interface{}{
+			"configFile": configPath,
+			"baseUrl":    answers.BaseURL,
+			"strategy":   answers.Strategy,
+			"defaultTtl": answers.DefaultTTL,
+		}
+		if inbox != nil {
+			data["inbox"] = inbox.Email
+		}
+		return cliutil.OutputJSON(data)
+	}
+
+	fmt.Println()
+	fmt.Printf("Config saved to %s\n", configPath)
+	if inbox != nil {
+		fmt.Println(styles.PassStyle.Render("You're ready! Your inbox is: " + inbox.Email))
+	} else {
+		fmt.Println(styles.PassStyle.Render("You're ready! Create an inbox with 'vsb inbox create'."))
+	}
+	return nil
+}
+
+// initDefaults are the answers offered when the user just presses Enter.
+func initDefaults() initAnswers {
+	baseURL := config.GetBaseURL()
+	if baseURL == config.DefaultBaseURL {
+		baseURL = "https://api.vaultsandbox.com"
+	}
+	return initAnswers{
+		BaseURL:     baseURL,
+		DefaultTTL:  config.GetDefaultTTL(),
+		Strategy:    config.GetStrategy(),
+		CreateInbox: true,
+	}
+}
+
+// initAnswersFromFlags validates the flag values for --non-interactive.
+func initAnswersFromFlags() (initAnswers, error) {
+	answers := initDefaults()
+	answers.CreateInbox = initCreateInbox
+
+	if initBaseURL != "" {
+		answers.BaseURL = initBaseURL
+	}
+	if err := validateBaseURL(answers.BaseURL); err != nil {
+		return initAnswers{}, err
+	}
+
+	answers.APIKey = strings.TrimSpace(initAPIKey)
+	if answers.APIKey == "" {
+		return initAnswers{}, fmt.Errorf("--api-key is required with --non-interactive")
+	}
+	if err := checkAPIKey(answers.APIKey, answers.BaseURL); err != nil {
+		return initAnswers{}, err
+	}
+
+	if initTTL != "" {
+		answers.DefaultTTL = initTTL
+	}
+	if err := validateDefaultTTL(answers.DefaultTTL); err != nil {
+		return initAnswers{}, err
+	}
+
+	if initStrategy != "" {
+		answers.Strategy = initStrategy
+	}
+	strategy, err := parseStrategyChoice(answers.Strategy)
+	if err != nil {
+		return initAnswers{}, err
+	}
+	answers.Strategy = strategy
+	return answers, nil
+}
+
+// promptInitAnswers asks each question in turn, repeating it until the
+// answer is valid.
+func promptInitAnswers(r *bufio.Reader, w io.Writer, defaults initAnswers) (initAnswers, error) {
+	answers := defaults
+	var err error
+
+	fmt.Fprintln(w, "Step 1/4: API access")
+	answers.BaseURL, err = ask(r, w, fmt.Sprintf("Server URL [%s]: ", defaults.BaseURL), defaults.BaseURL,
+		func(s string) (string, error) { return s, validateBaseURL(s) })
+	if err != nil {
+		return initAnswers{}, err
+	}
+	answers.APIKey, err = ask(r, w, "API key: ", "", func(s string) (string, error) {
+		if s == "" {
+			return "", fmt.Errorf("API key is required")
+		}
+		fmt.Fprintln(w, styles.MutedStyle.Render("  Checking API key..."))
+		return s, checkAPIKey(s, answers.BaseURL)
+	})
+	if err != nil {
+		return initAnswers{}, err
+	}
+
+	fmt.Fprintln(w, "\nStep 2/4: Default inbox lifetime (e.g. 1h, 24h, 7d)")
+	answers.DefaultTTL, err = ask(r, w, fmt.Sprintf("Lifetime [%s]: ", defaults.DefaultTTL), defaults.DefaultTTL,
+		func(s string) (string, error) { return s, validateDefaultTTL(s) })
+	if err != nil {
+		return initAnswers{}, err
+	}
+
+	fmt.Fprintln(w, "\nStep 3/4: Delivery strategy")
+	fmt.Fprintln(w, "  [1] sse - Server-Sent Events (real-time)")
+	fmt.Fprintln(w, "  [2] polling - Periodic API calls")
+	defaultChoice := "1"
+	if defaults.Strategy == "polling" {
+		defaultChoice = "2"
+	}
+	answers.Strategy, err = ask(r, w, fmt.Sprintf("Choice [%s]: ", defaultChoice), defaultChoice, parseStrategyChoice)
+	if err != nil {
+		return initAnswers{}, err
+	}
+
+	fmt.Fprintln(w, "\nStep 4/4: First inbox")
+	answers.CreateInbox = confirmDefaultYes(r, w, "Create an inbox now? [Y/n]: ")
+	return answers, nil
+}
+
+// ask prints prompt and reads a line, using def for an empty answer. Invalid
+// answers are reported and the question is asked again until input ends.
+func ask(r *bufio.Reader, w io.Writer, prompt, def string, validate func(string) (string, error)) (string, error) {
+	for {
+		fmt.Fprint(w, prompt)
+		line, readErr := r.ReadString('\n')
+		answer := strings.TrimSpace(line)
+		if answer == "" {
+			answer = def
+		}
+		if answer == "" && readErr != nil {
+			return "", fmt.Errorf("setup cancelled: no answer given")
+		}
+
+		value, err := validate(answer)
+		if err == nil {
+			return value, nil
+		}
+		fmt.Fprintln(w, styles.FailStyle.Render("  "+err.Error()))
+		if readErr != nil {
+			return "", err
+		}
+	}
+}
+
+// confirm asks a yes/no question that defaults to no.
+func confirm(r *bufio.Reader, w io.Writer, prompt string) bool {
+	fmt.Fprint(w, prompt)
+	answer, _ := r.ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
+// confirmDefaultYes asks a yes/no question that defaults to yes.
+func confirmDefaultYes(r *bufio.Reader, w io.Writer, prompt string) bool {
+	fmt.Fprint(w, prompt)
+	answer, _ := r.ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "" || answer == "y" || answer == "yes"
+}
+
+// validateBaseURL requires an absolute http(s) URL.
+func validateBaseURL(value string) error {
+	u, err := url.Parse(value)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid server URL: %s (use http:// or https://)", value)
+	}
+	return nil
+}
+
+// checkAPIKey verifies the key with the server, explaining a rejected key.
+func checkAPIKey(apiKey, baseURL string) error {
+	err := checkAPIKeyFunc(apiKey, baseURL)
+	if errors.Is(err, vaultsandbox.ErrUnauthorized) {
+		return fmt.Errorf("API key was rejected by %s", baseURL)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to check API key: %w", err)
+	}
+	return nil
+}
+
+// parseStrategyChoice accepts a strategy name or its number in the prompt.
+func parseStrategyChoice(value string) (string, error) {
+	switch strings.ToLower(value) {
+	case "1", "sse":
+		return "sse", nil
+	case "2", "polling":
+		return "polling", nil
+	}
+	return "", fmt.Errorf("invalid strategy: %s (valid: sse, polling)", value)
+}
@@ -0,0 +1,222 @@
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	vaultsandbox "github.com/vaultsandbox/client-go"
+	"github.com/vaultsandbox/vsb-cli/internal/config"
+)
+
+// stubInitServer replaces the API key check and inbox creation. Keys other
+// than "good-key" are rejected.
+func stubInitServer(t *testing.T) *[]initAnswers {
+	t.Helper()
+	originalCheck, originalCreate := checkAPIKeyFunc, createFirstInboxFunc
+	t.Cleanup(func() {
+		checkAPIKeyFunc, createFirstInboxFunc = originalCheck, originalCreate
+	})
+
+	checkAPIKeyFunc = func(apiKey, baseURL string) error {
+		if apiKey != "good-key" {
+			return vaultsandbox.ErrUnauthorized
+		}
+		return nil
+	}
+	var created []initAnswers
+	createFirstInboxFunc = func(ctx context.Context, answers initAnswers) (*config.StoredInbox, error) {
+		created = append(created, answers)
+		return &config.StoredInbox{Email: "first@vaultsandbox.test", ExpiresAt: time.Now().Add(time.Hour)}, nil
+	}
+	return &created
+}
+
+// captureStdout captures stdout during function execution
+func captureStdout(t *testing.T, f func()) string {
+	t.Helper()
+	old := os.Stdout
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+	os.Stdout = w
+
+	f()
+
+	w.Close()
+	os.Stdout = old
+
+	var buf bytes.Buffer
+	_, err = io.Copy(&buf, r)
+	require.NoError(t, err)
+	return buf.String()
+}
+
+func resetInitFlags(t *testing.T) {
+	t.Helper()
+	t.Cleanup(func() {
+		initNonInteractive, initAPIKey, initBaseURL, initTTL, initStrategy = false, "", "", "", ""
+		initCreateInbox, initForce = false, false
+	})
+}
+
+func TestPromptInitAnswers(t *testing.T) {
+	stubInitServer(t)
+	defaults := initAnswers{BaseURL: "https://api.vaultsandbox.com", DefaultTTL: "24h", Strategy: "sse", CreateInbox: true}
+
+	t.Run("accepts defaults", func(t *testing.T) {
+		var out bytes.Buffer
+		answers, err := promptInitAnswers(bufio.NewReader(strings.NewReader("\ngood-key\n\n\n\n")), &out, defaults)
+		require.NoError(t, err)
+		assert.Equal(t, initAnswers{
+			BaseURL:     "https://api.vaultsandbox.com",
+			APIKey:      "good-key",
+			DefaultTTL:  "24h",
+			Strategy:    "sse",
+			CreateInbox: true,
+		}, answers)
+		assert.Contains(t, out.String(), "Step 4/4")
+	})
+
+	t.Run("repeats invalid answers", func(t *testing.T) {
+		input := strings.Join([]string{
+			"ftp://example.com", "http://localhost:8080",
+			"", "bad-key", "good-key",
+			"soon", "-1h", "2h",
+			"3", "2",
+			"n",
+		}, "\n") + "\n"
+		var out bytes.Buffer
+		answers, err := promptInitAnswers(bufio.NewReader(strings.NewReader(input)), &out, defaults)
+		require.NoError(t, err)
+		assert.Equal(t, initAnswers{
+			BaseURL:    "http://localhost:8080",
+			APIKey:     "good-key",
+			DefaultTTL: "2h",
+			Strategy:   "polling",
+		}, answers)
+
+		assert.Contains(t, out.String(), "invalid server URL: ftp://example.com")
+		assert.Contains(t, out.String(), "API key is required")
+		assert.Contains(t, out.String(), "API key was rejected by http://localhost:8080")
+		assert.Contains(t, out.String(), "invalid default-ttl")
+		assert.Contains(t, out.String(), "invalid strategy: 3")
+	})
+
+	t.Run("input ending early fails", func(t *testing.T) {
+		_, err := promptInitAnswers(bufio.NewReader(strings.NewReader("\nbad-key")), &bytes.Buffer{}, defaults)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "rejected")
+
+		_, err = promptInitAnswers(bufio.NewReader(strings.NewReader("\n")), &bytes.Buffer{}, defaults)
+		assert.EqualError(t, err, "setup cancelled: no answer given")
+	})
+}
+
+func TestInitAnswersFromFlags(t *testing.T) {
+	stubInitServer(t)
+	resetInitFlags(t)
+	t.Setenv("VSB_BASE_URL", "")
+	t.Setenv("VSB_DEFAULT_TTL", "")
+	t.Setenv("VSB_STRATEGY", "")
+
+	t.Run("requires api key", func(t *testing.T) {
+		initAPIKey = ""
+		_, err := initAnswersFromFlags()
+		assert.EqualError(t, err, "--api-key is required with --non-interactive")
+	})
+
+	t.Run("uses defaults", func(t *testing.T) {
+		initAPIKey = "good-key"
+		answers, err := initAnswersFromFlags()
+		require.NoError(t, err)
+		assert.Equal(t, "https://api.vaultsandbox.com", answers.BaseURL)
+		assert.Equal(t, config.DefaultTTL, answers.DefaultTTL)
+		assert.Equal(t, "sse", answers.Strategy)
+		assert.False(t, answers.CreateInbox)
+	})
+
+	t.Run("validates each flag", func(t *testing.T) {
+		tests := []struct {
+			setup   func()
+			wantErr string
+		}{
+			{func() { initBaseURL = "not a url" }, "invalid server URL"},
+			{func() { initAPIKey = "bad-key" }, "API key was rejected"},
+			{func() { initTTL = "forever" }, "invalid default-ttl"},
+			{func() { initStrategy = "push" }, "invalid strategy: push"},
+		}
+		for _, tt := range tests {
+			initAPIKey, initBaseURL, initTTL, initStrategy = "good-key", "", "", ""
+			tt.setup()
+			_, err := initAnswersFromFlags()
+			require.Error(t, err, tt.wantErr)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		}
+	})
+}
+
+func TestCheckAPIKey(t *testing.T) {
+	original := checkAPIKeyFunc
+	t.Cleanup(func() { checkAPIKeyFunc = original })
+
+	checkAPIKeyFunc = func(apiKey, baseURL string) error { return errors.New("connection refused") }
+	err := checkAPIKey("key", "http://localhost:1")
+	assert.EqualError(t, err, "failed to check API key: connection refused")
+}
+
+func TestRunInitNonInteractive(t *testing.T) {
+	created := stubInitServer(t)
+	resetInitFlags(t)
+	configDir := t.TempDir()
+	t.Setenv("VSB_CONFIG_DIR", configDir)
+	t.Setenv("VSB_BASE_URL", "")
+	t.Setenv("VSB_DEFAULT_TTL", "")
+	t.Setenv("VSB_STRATEGY", "")
+
+	initNonInteractive = true
+	initAPIKey = "good-key"
+	initTTL = "2h"
+	initStrategy = "polling"
+	initCreateInbox = true
+
+	out := captureStdout(t, func() {
+		require.NoError(t, runInit(initCmd, nil))
+	})
+	assert.Contains(t, out, "You're ready! Your inbox is: first@vaultsandbox.test")
+	require.Len(t, *created, 1)
+	assert.Equal(t, "2h", (*created)[0].DefaultTTL)
+
+	data, err := os.ReadFile(filepath.Join(configDir, "config.yaml"))
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "api_key: good-key")
+	assert.Contains(t, string(data), "strategy: polling")
+	assert.Contains(t, string(data), "default_ttl: 2h")
+
+	t.Run("existing config needs --force", func(t *testing.T) {
+		err := runInit(initCmd, nil)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "use --force to overwrite")
+
+		initForce = true
+		initCreateInbox = false
+		initStrategy = "sse"
+		out := captureStdout(t, func() {
+			require.NoError(t, runInit(initCmd, nil))
+		})
+		assert.Contains(t, out, "Create an inbox with 'vsb inbox create'")
+		assert.Len(t, *created, 1)
+
+		data, err := os.ReadFile(filepath.Join(configDir, "config.yaml"))
+		require.NoError(t, err)
+		assert.Contains(t, string(data), "strategy: sse")
+	})
+}
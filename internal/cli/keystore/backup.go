@@ -0,0 +1,102 @@
+package keystore
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/vaultsandbox/vsb-cli/internal/cliutil"
+	"github.com/vaultsandbox/vsb-cli/internal/config"
+	"github.com/vaultsandbox/vsb-cli/internal/styles"
+)
+
+var backupCmd = &cobra.Command{
+	Use:   "backup",
+	Short: "Back up the whole keystore to a file",
+	Long: `Write a copy of keystore.json, with every inbox, its private keys, labels
+and the active inbox, to a backup file that 'vsb keystore restore' reads.
+Unlike 'vsb export', which writes one inbox, a backup covers the keystore
+as a whole.
+
+The backup records when and by which vsb version it was made, and carries
+a sha256 checksum that restore verifies.
+
+WARNING: A plain backup contains your PRIVATE KEYS. With
+--encrypt-with-password the keystore is encrypted (AES-256-GCM, with a key
+derived from the password by PBKDF2) instead. The password is read from
+VSB_BACKUP_PASSWORD if set, otherwise prompted for.
+
+Examples:
+  vsb keystore backup --out keystore-backup.json
+  vsb keystore backup --out keystore-backup.json --encrypt-with-password`,
+	Args: cobra.NoArgs,
+	RunE: runBackup,
+}
+
+var (
+	backupOut     string
+	backupEncrypt bool
+)
+
+func init() {
+	Cmd.AddCommand(backupCmd)
+	cliutil.AddOutputFormats(backupCmd, cliutil.FormatJSON)
+
+	backupCmd.Flags().StringVar(&backupOut, "out", "",
+		"Output file path (default: keystore-backup-<time>.json)")
+	backupCmd.Flags().BoolVar(&backupEncrypt, "encrypt-with-password", false,
+		"Encrypt the backup with a password")
+}
+
+func runBackup(cmd *cobra.Command, args []string) error {
+	outPath := backupOut
+	if outPath == "" {
+		outPath = fmt.Sprintf("keystore-backup-%s.json", time.Now().Format("20060102-150405"))
+	}
+	absPath, err := filepath.Abs(outPath)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(absPath); err == nil {
+		return fmt.Errorf("file already exists: %s (use --out to specify different path)", absPath)
+	}
+
+	var password string
+	if backupEncrypt {
+		if password, err = backupPassword(true); err != nil {
+			return err
+		}
+	}
+
+	backup, err := config.NewKeystoreBackup(password)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(backup, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(absPath, data, 0600); err != nil {
+		return err
+	}
+
+	if cliutil.GetOutput(cmd) == cliutil.FormatJSON {
+		return cliutil.OutputJSON(map[string]interface{}{
+			"path":       absPath,
+			"inboxes":    backup.Inboxes,
+			"encrypted":  backup.Encrypted(),
+			"createdAt":  backup.CreatedAt.Format(time.RFC3339),
+			"vsbVersion": backup.VSBVersion,
+			"checksum":   backup.Checksum,
+		})
+	}
+
+	fmt.Printf("Backed up %d inboxes to %s\n", backup.Inboxes, absPath)
+	if !backup.Encrypted() {
+		fmt.Println(styles.WarnStyle.Render("The backup contains private keys. Keep it secure!"))
+	}
+	return nil
+}
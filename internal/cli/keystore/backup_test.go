@@ -0,0 +1,124 @@
+package keystore
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vaultsandbox/vsb-cli/internal/config"
+)
+
+// stubPasswords makes readPassword return answers in order
+func stubPasswords(t *testing.T, answers ...string) {
+	t.Helper()
+	t.Setenv(backupPasswordEnv, "")
+	old := readPassword
+	t.Cleanup(func() { readPassword = old })
+	readPassword = func(prompt string) (string, error) {
+		if len(answers) == 0 {
+			return "", fmt.Errorf("unexpected prompt %q", prompt)
+		}
+		answer := answers[0]
+		answers = answers[1:]
+		return answer, nil
+	}
+}
+
+func TestBackupPassword(t *testing.T) {
+	t.Run("environment variable skips the prompt", func(t *testing.T) {
+		stubPasswords(t)
+		t.Setenv(backupPasswordEnv, "from-env")
+
+		password, err := backupPassword(true)
+		require.NoError(t, err)
+		assert.Equal(t, "from-env", password)
+	})
+
+	t.Run("confirmed password", func(t *testing.T) {
+		stubPasswords(t, "secret", "secret")
+
+		password, err := backupPassword(true)
+		require.NoError(t, err)
+		assert.Equal(t, "secret", password)
+	})
+
+	t.Run("mismatch", func(t *testing.T) {
+		stubPasswords(t, "secret", "typo")
+
+		_, err := backupPassword(true)
+		assert.EqualError(t, err, "passwords do not match")
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		stubPasswords(t, "")
+
+		_, err := backupPassword(false)
+		assert.EqualError(t, err, "password cannot be empty")
+	})
+}
+
+func TestBackupRestore(t *testing.T) {
+	setup := func(t *testing.T) string {
+		dir := t.TempDir()
+		t.Setenv("VSB_CONFIG_DIR", dir)
+		path := filepath.Join(dir, "keystore.json")
+		require.NoError(t, os.WriteFile(path, []byte(v1Keystore), 0600))
+
+		oldOut, oldEncrypt, oldForce := backupOut, backupEncrypt, restoreForce
+		t.Cleanup(func() { backupOut, backupEncrypt, restoreForce = oldOut, oldEncrypt, oldForce })
+		backupOut = filepath.Join(t.TempDir(), "backup.json")
+		backupEncrypt, restoreForce = false, false
+		return path
+	}
+
+	t.Run("round trip", func(t *testing.T) {
+		path := setup(t)
+
+		var err error
+		out := captureStdout(t, func() { err = runBackup(&cobra.Command{}, nil) })
+		require.NoError(t, err)
+		assert.Contains(t, out, "Backed up 2 inboxes to "+backupOut)
+		assert.Contains(t, out, "private keys")
+
+		err = runRestore(&cobra.Command{}, []string{backupOut})
+		assert.ErrorIs(t, err, config.ErrKeystoreExists)
+
+		require.NoError(t, os.Remove(path))
+		out = captureStdout(t, func() { err = runRestore(&cobra.Command{}, []string{backupOut}) })
+		require.NoError(t, err)
+		assert.Contains(t, out, "Restored 2 inboxes to "+path)
+
+		ks, err := config.LoadKeystore()
+		require.NoError(t, err)
+		assert.Len(t, ks.Inboxes, 2)
+	})
+
+	t.Run("refuses to overwrite the backup file", func(t *testing.T) {
+		setup(t)
+		require.NoError(t, os.WriteFile(backupOut, []byte("{}"), 0600))
+
+		err := runBackup(&cobra.Command{}, nil)
+		assert.ErrorContains(t, err, "file already exists")
+	})
+
+	t.Run("encrypted", func(t *testing.T) {
+		setup(t)
+		backupEncrypt = true
+		stubPasswords(t, "secret", "secret", "wrong", "secret")
+
+		var err error
+		captureStdout(t, func() { err = runBackup(&cobra.Command{}, nil) })
+		require.NoError(t, err)
+
+		restoreForce = true
+		err = runRestore(&cobra.Command{}, []string{backupOut})
+		assert.ErrorIs(t, err, config.ErrBackupPassword)
+
+		captureStdout(t, func() { err = runRestore(&cobra.Command{}, []string{backupOut}) })
+		require.NoError(t, err)
+	})
+}
@@ -0,0 +1,23 @@
+package keystore
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// Cmd is the keystore parent command
+var Cmd = &cobra.Command{
+	Use:   "keystore",
+	Short: "Maintain the local inbox keystore",
+	Long: `Maintain keystore.json, the file in the config directory that holds the
+addresses and private keys of your inboxes.`,
+	RunE: runKeystore,
+}
+
+func runKeystore(cmd *cobra.Command, args []string) error {
+	if len(args) > 0 {
+		return fmt.Errorf("unknown command %q for %q", args[0], cmd.CommandPath())
+	}
+	return cmd.Help()
+}
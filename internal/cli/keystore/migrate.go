@@ -0,0 +1,106 @@
+package keystore
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/vaultsandbox/vsb-cli/internal/cliutil"
+	"github.com/vaultsandbox/vsb-cli/internal/config"
+	"github.com/vaultsandbox/vsb-cli/internal/styles"
+)
+
+var migrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Upgrade keystore.json to a newer schema version",
+	Long: `Rewrite keystore.json written by an older version of vsb in the current
+format.
+
+The keystore records its schema version, and each version has a migration
+that upgrades a keystore from the version before it. Migrate runs them in
+order, after copying the old file to keystore.json.v<version>-<time>.bak
+next to it. The file is only rewritten if something changed, so it is safe
+to run on a current keystore. Use --dry-run to list the changes without
+writing anything.
+
+--from-version treats the file as that version whatever it records, e.g. to
+rerun a migration on a hand-edited file. --to-version stops at an older
+version than the current one.
+
+Examples:
+  vsb keystore migrate --dry-run
+  vsb keystore migrate
+  vsb keystore migrate --from-version 1 --to-version 2`,
+	Args: cobra.NoArgs,
+	RunE: runMigrate,
+}
+
+var (
+	migrateFrom   int
+	migrateTo     int
+	migrateDryRun bool
+)
+
+func init() {
+	Cmd.AddCommand(migrateCmd)
+	cliutil.MarkSavesState(migrateCmd)
+	cliutil.AddOutputFormats(migrateCmd, cliutil.FormatJSON)
+
+	migrateCmd.Flags().IntVar(&migrateFrom, "from-version", 0,
+		"Schema version to migrate from (default: the version in the file)")
+	migrateCmd.Flags().IntVar(&migrateTo, "to-version", config.KeystoreSchemaVersion,
+		"Schema version to migrate to")
+	migrateCmd.Flags().BoolVar(&migrateDryRun, "dry-run", false,
+		"Show the changes without writing any file")
+}
+
+func runMigrate(cmd *cobra.Command, args []string) error {
+	from := -1
+	if cmd.Flags().Changed("from-version") {
+		if migrateFrom < 0 {
+			return fmt.Errorf("invalid --from-version %d", migrateFrom)
+		}
+		from = migrateFrom
+	}
+
+	m, err := config.MigrateKeystoreVersions(from, migrateTo, migrateDryRun)
+	if err != nil {
+		return fmt.Errorf("migration failed: %w", err)
+	}
+
+	if cliutil.GetOutput(cmd) == cliutil.FormatJSON {
+		changes := m.Changes
+		if changes == nil {
+			changes = []string{}
+		}
+		return cliutil.OutputJSON(map[string]interface{}{
+			"dryRun":  migrateDryRun,
+			"path":    m.Path,
+			"exists":  m.Exists,
+			"from":    m.From,
+			"to":      m.To,
+			"changes": changes,
+			"written": m.Written,
+			"backup":  m.Backup,
+		})
+	}
+
+	if migrateDryRun {
+		fmt.Println(styles.WarningTitleStyle.Render("Dry run: no files were changed"))
+		fmt.Println()
+	}
+	switch {
+	case !m.Exists:
+		fmt.Printf("No keystore at %s, nothing to migrate\n", m.Path)
+	case len(m.Changes) == 0:
+		fmt.Printf("keystore.json: up to date (schema version %d)\n", m.From)
+	default:
+		fmt.Printf("keystore.json: schema version %d -> %d\n", m.From, m.To)
+		for _, change := range m.Changes {
+			fmt.Printf("  - %s\n", change)
+		}
+		if m.Backup != "" {
+			fmt.Printf("\nBacked up the old keystore to %s\n", m.Backup)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package keystore
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vaultsandbox/vsb-cli/internal/config"
+)
+
+// captureStdout captures stdout during function execution
+func captureStdout(t *testing.T, f func()) string {
+	t.Helper()
+	old := os.Stdout
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+	os.Stdout = w
+
+	f()
+
+	w.Close()
+	os.Stdout = old
+
+	var buf bytes.Buffer
+	_, err = io.Copy(&buf, r)
+	require.NoError(t, err)
+	return buf.String()
+}
+
+const v1Keystore = `{
+  "version": 1,
+  "inboxes": [
+    {"email": "a@example.com", "id": "h1", "label": "ci", "expiresAt": "2099-01-01T00:00:00Z", "keys": {}},
+    {"email": "b@example.com", "id": "h2", "label": "ci", "expiresAt": "2099-01-01T00:00:00Z", "keys": {}}
+  ],
+  "active_inbox": "a@example.com"
+}`
+
+func TestRunMigrate(t *testing.T) {
+	setup := func(t *testing.T) string {
+		dir := t.TempDir()
+		t.Setenv("VSB_CONFIG_DIR", dir)
+		path := filepath.Join(dir, "keystore.json")
+		require.NoError(t, os.WriteFile(path, []byte(v1Keystore), 0600))
+
+		oldFrom, oldTo, oldDryRun := migrateFrom, migrateTo, migrateDryRun
+		t.Cleanup(func() { migrateFrom, migrateTo, migrateDryRun = oldFrom, oldTo, oldDryRun })
+		migrateFrom, migrateTo, migrateDryRun = 0, config.KeystoreSchemaVersion, false
+		return path
+	}
+	newCmd := func() *cobra.Command {
+		cmd := &cobra.Command{}
+		cmd.Flags().IntVar(&migrateFrom, "from-version", 0, "")
+		return cmd
+	}
+
+	t.Run("dry run lists the changes", func(t *testing.T) {
+		path := setup(t)
+		migrateDryRun = true
+
+		var err error
+		out := captureStdout(t, func() { err = runMigrate(newCmd(), nil) })
+		require.NoError(t, err)
+		assert.Contains(t, out, "Dry run")
+		assert.Contains(t, out, "schema version 1 -> 2")
+		assert.Contains(t, out, `b@example.com: renamed label "ci" to "ci-2"`)
+
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, v1Keystore, string(data))
+	})
+
+	t.Run("migrates and backs up", func(t *testing.T) {
+		path := setup(t)
+
+		var err error
+		out := captureStdout(t, func() { err = runMigrate(newCmd(), nil) })
+		require.NoError(t, err)
+		assert.Contains(t, out, "Backed up the old keystore to "+path+".v1-")
+
+		out = captureStdout(t, func() { err = runMigrate(newCmd(), nil) })
+		require.NoError(t, err)
+		assert.Contains(t, out, "up to date (schema version 2)")
+	})
+
+	t.Run("from-version overrides the file", func(t *testing.T) {
+		setup(t)
+		migrateDryRun = true
+		cmd := newCmd()
+		require.NoError(t, cmd.Flags().Set("from-version", "2"))
+
+		var err error
+		out := captureStdout(t, func() { err = runMigrate(cmd, nil) })
+		require.NoError(t, err)
+		assert.Contains(t, out, "up to date")
+	})
+
+	t.Run("rejects unsupported versions", func(t *testing.T) {
+		setup(t)
+		migrateTo = 99
+
+		err := runMigrate(newCmd(), nil)
+		assert.ErrorContains(t, err, "unsupported keystore schema version 99")
+	})
+}
@@ -0,0 +1,54 @@
+package keystore
+
+import (
+	"fmt"
+	"os"
+
+	"golang.org/x/term"
+)
+
+// backupPasswordEnv names the environment variable that supplies the backup
+// password without a prompt, e.g. in CI
+const backupPasswordEnv = "VSB_BACKUP_PASSWORD"
+
+// readPassword prompts for a password on the terminal without echoing it
+// (overridden in tests)
+var readPassword = func(prompt string) (string, error) {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return "", fmt.Errorf("a password is required: set %s or run in a terminal", backupPasswordEnv)
+	}
+	fmt.Fprint(os.Stderr, prompt)
+	password, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", fmt.Errorf("failed to read password: %w", err)
+	}
+	return string(password), nil
+}
+
+// backupPassword returns the backup password from VSB_BACKUP_PASSWORD or a
+// prompt. A new password is asked for twice to catch typos.
+func backupPassword(confirm bool) (string, error) {
+	if password := os.Getenv(backupPasswordEnv); password != "" {
+		return password, nil
+	}
+
+	password, err := readPassword("Backup password: ")
+	if err != nil {
+		return "", err
+	}
+	if password == "" {
+		return "", fmt.Errorf("password cannot be empty")
+	}
+	if confirm {
+		again, err := readPassword("Repeat password: ")
+		if err != nil {
+			return "", err
+		}
+		if again != password {
+			return "", fmt.Errorf("passwords do not match")
+		}
+	}
+	return password, nil
+}
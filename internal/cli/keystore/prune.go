@@ -0,0 +1,131 @@
+package keystore
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/vaultsandbox/vsb-cli/internal/cliutil"
+	"github.com/vaultsandbox/vsb-cli/internal/config"
+	"github.com/vaultsandbox/vsb-cli/internal/styles"
+	"github.com/vaultsandbox/vsb-cli/internal/timeparse"
+)
+
+var pruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Remove expired inboxes from the keystore",
+	Long: `Remove every inbox whose expiry time has passed from keystore.json, along
+with its local read state. The server deletes expired inboxes, so their
+keys are of no further use.
+
+Loading the keystore already drops expired inboxes as a side effect; prune
+does it on purpose and reports what was removed. With --older-than only
+inboxes that expired more than that long ago are removed, e.g. to keep
+yesterday's inboxes around for a while. If the active inbox is removed,
+the first remaining inbox becomes active. --dry-run lists the inboxes that
+would be removed without changing anything.
+
+Examples:
+  vsb keystore prune
+  vsb keystore prune --older-than 7d
+  vsb keystore prune --dry-run`,
+	Args: cobra.NoArgs,
+	RunE: runPrune,
+}
+
+var (
+	pruneOlderThan string
+	pruneDryRun    bool
+)
+
+func init() {
+	Cmd.AddCommand(pruneCmd)
+	cliutil.AddOutputFormats(pruneCmd, cliutil.FormatJSON)
+	cliutil.MarkSavesState(pruneCmd)
+
+	pruneCmd.Flags().StringVar(&pruneOlderThan, "older-than", "",
+		"Only remove inboxes that expired more than this long ago (e.g. 12h, 7d)")
+	pruneCmd.Flags().BoolVar(&pruneDryRun, "dry-run", false,
+		"List the inboxes that would be removed without changing the keystore")
+}
+
+func runPrune(cmd *cobra.Command, args []string) error {
+	var olderThan time.Duration
+	if pruneOlderThan != "" {
+		d, err := timeparse.Duration(pruneOlderThan)
+		if err != nil || d < 0 {
+			return fmt.Errorf("invalid --older-than %q (use a duration like 12h or 7d)", pruneOlderThan)
+		}
+		olderThan = d
+	}
+
+	now := config.ServerNow()
+	result, err := config.PruneKeystore(now, olderThan, pruneDryRun)
+	if err != nil {
+		return err
+	}
+
+	if cliutil.GetOutput(cmd) == cliutil.FormatJSON {
+		pruned := make([]map[string]interface{}, 0, len(result.Pruned))
+		for _, inbox := range result.Pruned {
+			pruned = append(pruned, map[string]interface{}{
+				"email":     inbox.Email,
+				"expiresAt": inbox.ExpiresAt.Format(time.RFC3339),
+			})
+		}
+		return cliutil.OutputJSON(map[string]interface{}{
+			"dryRun":      pruneDryRun,
+			"pruned":      pruned,
+			"remaining":   result.Remaining,
+			"keptExpired": result.KeptExpired,
+			"activeInbox": result.ActiveInbox,
+		})
+	}
+
+	if pruneDryRun {
+		fmt.Println(styles.WarningTitleStyle.Render("Dry run: the keystore was not changed"))
+		fmt.Println()
+	}
+	for _, inbox := range result.Pruned {
+		fmt.Printf("  - %s %s\n", inbox.Email,
+			styles.MutedStyle.Render("(expired "+cliutil.FormatDuration(now.Sub(inbox.ExpiresAt))+" ago)"))
+	}
+	if len(result.Pruned) > 0 {
+		fmt.Println()
+	}
+	fmt.Println(pruneSummary(result, pruneDryRun))
+	if result.KeptExpired > 0 {
+		fmt.Printf("Kept %s that expired less than %s ago.\n", inboxCount(result.KeptExpired, "inbox"), pruneOlderThan)
+	}
+	if result.ActiveChanged && !pruneDryRun {
+		if result.ActiveInbox != "" {
+			fmt.Printf("Active inbox is now %s\n", result.ActiveInbox)
+		} else {
+			fmt.Println("No active inbox remains")
+		}
+	}
+	return nil
+}
+
+// pruneSummary is the last line of 'keystore prune' output, e.g. "Pruned 3
+// expired inboxes. 2 active inboxes remain."
+func pruneSummary(result *config.PruneResult, dryRun bool) string {
+	pruned := inboxCount(len(result.Pruned), "expired inbox")
+	remaining := inboxCount(result.Remaining, "active inbox")
+	remain := "remain"
+	if result.Remaining == 1 {
+		remain = "remains"
+	}
+	if dryRun {
+		return fmt.Sprintf("Would prune %s. %s would remain.", pruned, remaining)
+	}
+	return fmt.Sprintf("Pruned %s. %s %s.", pruned, remaining, remain)
+}
+
+// inboxCount returns e.g. "1 expired inbox" or "3 expired inboxes"
+func inboxCount(n int, noun string) string {
+	if n == 1 {
+		return "1 " + noun
+	}
+	return fmt.Sprintf("%d %ses", n, noun)
+}
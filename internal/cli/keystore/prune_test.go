@@ -0,0 +1,99 @@
+package keystore
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vaultsandbox/vsb-cli/internal/config"
+)
+
+func TestPruneSummary(t *testing.T) {
+	expired := func(n int) []config.StoredInbox { return make([]config.StoredInbox, n) }
+
+	tests := []struct {
+		result config.PruneResult
+		dryRun bool
+		want   string
+	}{
+		{config.PruneResult{Pruned: expired(3), Remaining: 2}, false, "Pruned 3 expired inboxes. 2 active inboxes remain."},
+		{config.PruneResult{Pruned: expired(1), Remaining: 1}, false, "Pruned 1 expired inbox. 1 active inbox remains."},
+		{config.PruneResult{Remaining: 0}, false, "Pruned 0 expired inboxes. 0 active inboxes remain."},
+		{config.PruneResult{Pruned: expired(2), Remaining: 1}, true, "Would prune 2 expired inboxes. 1 active inbox would remain."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			assert.Equal(t, tt.want, pruneSummary(&tt.result, tt.dryRun))
+		})
+	}
+}
+
+func TestRunPrune(t *testing.T) {
+	setup := func(t *testing.T) string {
+		dir := t.TempDir()
+		t.Setenv("VSB_CONFIG_DIR", dir)
+		path := filepath.Join(dir, "keystore.json")
+		now := time.Now()
+		data := fmt.Sprintf(`{"version": 2, "inboxes": [
+  {"email": "live@example.com", "id": "h1", "expiresAt": %q, "keys": {}},
+  {"email": "old@example.com", "id": "h2", "expiresAt": %q, "keys": {}}
+], "active_inbox": "old@example.com"}`, now.Add(time.Hour).Format(time.RFC3339), now.Add(-48*time.Hour).Format(time.RFC3339))
+		require.NoError(t, os.WriteFile(path, []byte(data), 0600))
+
+		oldOlderThan, oldDryRun := pruneOlderThan, pruneDryRun
+		t.Cleanup(func() { pruneOlderThan, pruneDryRun = oldOlderThan, oldDryRun })
+		pruneOlderThan, pruneDryRun = "", false
+		return path
+	}
+
+	t.Run("prunes and reports the new active inbox", func(t *testing.T) {
+		setup(t)
+
+		var err error
+		out := captureStdout(t, func() { err = runPrune(&cobra.Command{}, nil) })
+		require.NoError(t, err)
+		assert.Contains(t, out, "old@example.com (expired 2d ago)")
+		assert.Contains(t, out, "Pruned 1 expired inbox. 1 active inbox remains.")
+		assert.Contains(t, out, "Active inbox is now live@example.com")
+	})
+
+	t.Run("dry run", func(t *testing.T) {
+		path := setup(t)
+		pruneDryRun = true
+
+		var err error
+		out := captureStdout(t, func() { err = runPrune(&cobra.Command{}, nil) })
+		require.NoError(t, err)
+		assert.Contains(t, out, "Would prune 1 expired inbox.")
+		assert.NotContains(t, out, "Active inbox is now")
+
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Contains(t, string(data), "old@example.com")
+	})
+
+	t.Run("older than", func(t *testing.T) {
+		setup(t)
+		pruneOlderThan = "3d"
+
+		var err error
+		out := captureStdout(t, func() { err = runPrune(&cobra.Command{}, nil) })
+		require.NoError(t, err)
+		assert.Contains(t, out, "Pruned 0 expired inboxes. 1 active inbox remains.")
+		assert.Contains(t, out, "Kept 1 inbox that expired less than 3d ago.")
+	})
+
+	t.Run("invalid older than", func(t *testing.T) {
+		setup(t)
+		pruneOlderThan = "soon"
+
+		err := runPrune(&cobra.Command{}, nil)
+		assert.EqualError(t, err, `invalid --older-than "soon" (use a duration like 12h or 7d)`)
+	})
+}
@@ -0,0 +1,81 @@
+package keystore
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/vaultsandbox/vsb-cli/internal/cliutil"
+	"github.com/vaultsandbox/vsb-cli/internal/config"
+)
+
+var restoreCmd = &cobra.Command{
+	Use:   "restore <backup-file>",
+	Short: "Restore the keystore from a backup",
+	Long: `Replace keystore.json with the keystore in a file written by
+'vsb keystore backup'.
+
+The backup's checksum is verified first, and an encrypted backup asks for
+its password (or reads it from VSB_BACKUP_PASSWORD). Restore refuses to
+overwrite an existing keystore unless --force is given; inboxes only in
+the current keystore are lost, so back it up first if unsure.
+
+Examples:
+  vsb keystore restore keystore-backup.json
+  vsb keystore restore keystore-backup.json --force`,
+	Args: cobra.ExactArgs(1),
+	RunE: runRestore,
+}
+
+var restoreForce bool
+
+func init() {
+	Cmd.AddCommand(restoreCmd)
+	cliutil.AddOutputFormats(restoreCmd, cliutil.FormatJSON)
+	cliutil.MarkSavesState(restoreCmd)
+
+	restoreCmd.Flags().BoolVarP(&restoreForce, "force", "f", false,
+		"Overwrite the existing keystore")
+}
+
+func runRestore(cmd *cobra.Command, args []string) error {
+	data, err := os.ReadFile(args[0])
+	if err != nil {
+		return fmt.Errorf("failed to read backup: %w", err)
+	}
+	backup, err := config.ParseKeystoreBackup(data)
+	if err != nil {
+		return err
+	}
+
+	var password string
+	if backup.Encrypted() {
+		if password, err = backupPassword(false); err != nil {
+			return err
+		}
+	}
+	keystore, err := backup.Open(password)
+	if err != nil {
+		return err
+	}
+
+	path, inboxes, err := config.RestoreKeystore(keystore, restoreForce)
+	if err != nil {
+		return err
+	}
+
+	if cliutil.GetOutput(cmd) == cliutil.FormatJSON {
+		return cliutil.OutputJSON(map[string]interface{}{
+			"path":       path,
+			"inboxes":    inboxes,
+			"encrypted":  backup.Encrypted(),
+			"createdAt":  backup.CreatedAt.Format(time.RFC3339),
+			"vsbVersion": backup.VSBVersion,
+		})
+	}
+
+	fmt.Printf("Restored %d inboxes to %s (backup from %s, vsb %s)\n",
+		inboxes, path, backup.CreatedAt.Local().Format(cliutil.TimeFormatShort), backup.VSBVersion)
+	return nil
+}
@@ -0,0 +1,201 @@
+package keystore
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/vaultsandbox/vsb-cli/internal/cliutil"
+	"github.com/vaultsandbox/vsb-cli/internal/config"
+	"github.com/vaultsandbox/vsb-cli/internal/styles"
+)
+
+var verifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "Check the keys of every stored inbox",
+	Long: `Check every inbox in the keystore without contacting the server:
+
+  - the KEM private key decodes to a well-formed ML-KEM-768 key, whose
+    embedded public key matches its hash (and the stored public key, if any)
+  - the pinned server signature key decodes to an ML-DSA-65 public key
+  - the inbox has not expired
+
+Plain (unencrypted) inboxes store no keys, so only their expiry is checked.
+Expired inboxes are removed from the keystore whenever it is loaded, so
+they are reported once and need no repair; --repair never removes an inbox
+whose keys are valid.
+
+Verify exits non-zero while inboxes with invalid keys remain. An inbox
+whose private key is damaged cannot decrypt its emails, and the key cannot
+be recovered; --repair removes such inboxes from the keystore (back it up
+first with 'vsb keystore backup' if unsure). --verbose adds the decoded key
+sizes and SHA-256 fingerprints of the public keys.
+
+Examples:
+  vsb keystore verify
+  vsb keystore verify --verbose
+  vsb keystore verify --repair
+  vsb keystore verify -o json`,
+	Args: cobra.NoArgs,
+	RunE: runVerify,
+}
+
+var verifyRepair bool
+
+func init() {
+	Cmd.AddCommand(verifyCmd)
+	cliutil.AddOutputFormats(verifyCmd, cliutil.FormatJSON)
+	cliutil.MarkSavesStateWith(verifyCmd, "repair")
+
+	verifyCmd.Flags().BoolVar(&verifyRepair, "repair", false,
+		"Remove inboxes with invalid keys from the keystore")
+}
+
+// Statuses of an inbox in 'keystore verify' output
+const (
+	verifyStatusOK      = "ok"
+	verifyStatusInvalid = "invalid"
+	verifyStatusExpired = "expired"
+	verifyStatusRemoved = "removed"
+)
+
+// verifyResult is one inbox in 'keystore verify' output
+type verifyResult struct {
+	config.InboxVerification
+	Status string
+}
+
+func runVerify(cmd *cobra.Command, args []string) error {
+	ks, err := cliutil.LoadKeystoreOrError()
+	if err != nil {
+		return err
+	}
+
+	now := config.ServerNow()
+	var results []verifyResult
+	for _, inbox := range ks.ListInboxes() {
+		v := config.VerifyInbox(&inbox, now)
+		status := verifyStatusOK
+		switch {
+		case v.KeysInvalid():
+			status = verifyStatusInvalid
+		case !v.OK():
+			// Expired, but kept by load until the server's clock is known
+			status = verifyStatusExpired
+		}
+		results = append(results, verifyResult{v, status})
+	}
+	// Load already dropped these, but they are worth a mention
+	for _, inbox := range ks.PrunedInboxes() {
+		results = append(results, verifyResult{config.VerifyInbox(&inbox, now), verifyStatusExpired})
+	}
+
+	invalid := 0
+	for i := range results {
+		r := &results[i]
+		if r.Status != verifyStatusInvalid {
+			continue
+		}
+		if !verifyRepair {
+			invalid++
+			continue
+		}
+		if err := ks.RemoveInbox(r.Email); err != nil {
+			return fmt.Errorf("failed to remove %s: %w", r.Email, err)
+		}
+		r.Status = verifyStatusRemoved
+	}
+
+	if cliutil.GetOutput(cmd) == cliutil.FormatJSON {
+		if err := cliutil.OutputJSON(verifyJSON(results, cliutil.IsVerbose())); err != nil {
+			return err
+		}
+	} else if err := writeVerifyTable(results, cliutil.IsVerbose()); err != nil {
+		return err
+	}
+
+	if invalid > 0 {
+		return fmt.Errorf("%w: %d of %d failed verification (use --repair to remove them)",
+			config.ErrKeystoreInvalid, invalid, len(results))
+	}
+	return nil
+}
+
+// writeVerifyTable prints one row per inbox, with the key sizes and
+// fingerprints when verbose.
+func writeVerifyTable(results []verifyResult, verbose bool) error {
+	if len(results) == 0 {
+		fmt.Println("No inboxes in the keystore")
+		return nil
+	}
+
+	headers := []string{"EMAIL", "STATUS", "PROBLEMS"}
+	if verbose {
+		headers = append(headers, "KEM PRIVATE", "KEM PUBLIC", "SERVER KEY")
+	}
+	t := cliutil.NewTableWriter(os.Stdout, headers...)
+	for _, r := range results {
+		problems := make([]string, len(r.Problems))
+		for i, p := range r.Problems {
+			problems[i] = p.Error()
+		}
+		cells := []string{r.Email, r.Status, strings.Join(problems, "; ")}
+		if verbose {
+			cells = append(cells, describeKey(r.KEMPrivate), describeKey(r.KEMPublic), describeKey(r.ServerSigPK))
+		}
+
+		switch r.Status {
+		case verifyStatusOK:
+			t.AddRow(cells...)
+		case verifyStatusExpired:
+			t.AddStyledRow(styles.ExpiredStyle, cells...)
+		default:
+			t.AddStyledRow(styles.FailStyle, cells...)
+		}
+	}
+	return t.Render()
+}
+
+// describeKey formats a key's size and fingerprint for the verbose table.
+func describeKey(k config.KeyInfo) string {
+	if k.Size == 0 {
+		return "-"
+	}
+	if k.Fingerprint == "" {
+		return fmt.Sprintf("%d bytes", k.Size)
+	}
+	return fmt.Sprintf("%d bytes %s", k.Size, k.Fingerprint)
+}
+
+func verifyJSON(results []verifyResult, verbose bool) map[string]interface{} {
+	counts := map[string]int{}
+	inboxes := make([]map[string]interface{}, 0, len(results))
+	for _, r := range results {
+		counts[r.Status]++
+		problems := make([]string, len(r.Problems))
+		for i, p := range r.Problems {
+			problems[i] = p.Error()
+		}
+		entry := map[string]interface{}{
+			"email":    r.Email,
+			"status":   r.Status,
+			"problems": problems,
+		}
+		if verbose {
+			entry["keys"] = map[string]config.KeyInfo{
+				"kemPrivate":  r.KEMPrivate,
+				"kemPublic":   r.KEMPublic,
+				"serverSigPk": r.ServerSigPK,
+			}
+		}
+		inboxes = append(inboxes, entry)
+	}
+	return map[string]interface{}{
+		"inboxes": inboxes,
+		"ok":      counts[verifyStatusOK],
+		"invalid": counts[verifyStatusInvalid],
+		"expired": counts[verifyStatusExpired],
+		"removed": counts[verifyStatusRemoved],
+	}
+}
@@ -0,0 +1,152 @@
+package keystore
+
+import (
+	"crypto/rand"
+	"crypto/sha3"
+	"encoding/base64"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vaultsandbox/vsb-cli/internal/cliutil"
+	"github.com/vaultsandbox/vsb-cli/internal/config"
+)
+
+// verifyKeystore has one valid encrypted inbox, one with a truncated
+// private key and one plain inbox; keys are filled in by writeVerifyKeystore
+const verifyKeystore = `{
+  "version": 2,
+  "inboxes": [
+    {"email": "good@example.com", "id": "h1", "expiresAt": "2099-01-01T00:00:00Z", "encrypted": true, "keys": {"kem_private": "%PRIV%", "server_sig_pk": "%SIG%"}},
+    {"email": "bad@example.com", "id": "h2", "expiresAt": "2099-01-01T00:00:00Z", "encrypted": true, "keys": {"kem_private": "dHJ1bmNhdGVk", "server_sig_pk": "%SIG%"}},
+    {"email": "plain@example.com", "id": "h3", "expiresAt": "2099-01-01T00:00:00Z", "keys": {}},
+    {"email": "old@example.com", "id": "h4", "expiresAt": "2000-01-01T00:00:00Z", "keys": {}}
+  ],
+  "active_inbox": "bad@example.com"
+}`
+
+func writeVerifyKeystore(t *testing.T) {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("VSB_CONFIG_DIR", dir)
+
+	priv := make([]byte, config.KEMPrivateKeySize)
+	_, err := rand.Read(priv)
+	require.NoError(t, err)
+	ekStart := config.KEMPrivateKeySize - config.KEMPublicKeySize - 64
+	sum := sha3.Sum256(priv[ekStart : ekStart+config.KEMPublicKeySize])
+	copy(priv[ekStart+config.KEMPublicKeySize:], sum[:])
+	sig := make([]byte, config.ServerSigPKKeySize)
+
+	data := verifyKeystore
+	for placeholder, key := range map[string][]byte{"%PRIV%": priv, "%SIG%": sig} {
+		data = strings.ReplaceAll(data, placeholder, base64.RawURLEncoding.EncodeToString(key))
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "keystore.json"), []byte(data), 0600))
+
+	oldRepair := verifyRepair
+	t.Cleanup(func() {
+		verifyRepair = oldRepair
+		cliutil.SetVerbose(false)
+	})
+	verifyRepair = false
+}
+
+func TestRunVerify(t *testing.T) {
+	t.Run("reports invalid keys", func(t *testing.T) {
+		writeVerifyKeystore(t)
+
+		var err error
+		out := captureStdout(t, func() { err = runVerify(&cobra.Command{}, nil) })
+		assert.ErrorIs(t, err, config.ErrKeystoreInvalid)
+		assert.ErrorContains(t, err, "1 of 4 failed verification")
+		assert.Contains(t, out, "good@example.com")
+		assert.Contains(t, out, "invalid KEM private key: 9 bytes, expected 2400")
+		assert.Contains(t, out, "expired")
+
+		ks, err := config.LoadKeystore()
+		require.NoError(t, err)
+		assert.Len(t, ks.Inboxes, 3, "verify without --repair changes nothing")
+	})
+
+	t.Run("repair removes invalid inboxes", func(t *testing.T) {
+		writeVerifyKeystore(t)
+		verifyRepair = true
+
+		var err error
+		out := captureStdout(t, func() { err = runVerify(&cobra.Command{}, nil) })
+		require.NoError(t, err)
+		assert.Contains(t, out, "removed")
+
+		ks, err := config.LoadKeystore()
+		require.NoError(t, err)
+		require.Len(t, ks.Inboxes, 2)
+		assert.Equal(t, "good@example.com", ks.Inboxes[0].Email)
+		assert.Equal(t, "good@example.com", ks.ActiveInbox)
+	})
+
+	t.Run("repair keeps expired inboxes with valid keys", func(t *testing.T) {
+		writeVerifyKeystore(t)
+		ks, err := config.LoadKeystore()
+		require.NoError(t, err)
+		// Load keeps it while the server's clock is unknown
+		require.NoError(t, ks.PutInbox(config.StoredInbox{
+			Email: "recent@example.com", ID: "h5", ExpiresAt: time.Now().Add(-10 * time.Minute),
+		}, false))
+		verifyRepair = true
+
+		out := captureStdout(t, func() { err = runVerify(&cobra.Command{}, nil) })
+		require.NoError(t, err)
+		assert.Regexp(t, `recent@example\.com\s+expired`, out)
+
+		ks, err = config.LoadKeystore()
+		require.NoError(t, err)
+		_, err = ks.GetInbox("recent@example.com")
+		assert.NoError(t, err)
+		_, err = ks.GetInbox("bad@example.com")
+		assert.ErrorIs(t, err, config.ErrInboxNotFound)
+	})
+
+	t.Run("verbose JSON", func(t *testing.T) {
+		writeVerifyKeystore(t)
+		cliutil.SetVerbose(true)
+		cmd := &cobra.Command{}
+		cmd.Flags().String("output", "pretty", "output format")
+		require.NoError(t, cmd.Flags().Set("output", "json"))
+
+		var err error
+		out := captureStdout(t, func() { err = runVerify(cmd, nil) })
+		assert.ErrorIs(t, err, config.ErrKeystoreInvalid)
+
+		var result struct {
+			Inboxes []struct {
+				Email    string   `json:"email"`
+				Status   string   `json:"status"`
+				Problems []string `json:"problems"`
+				Keys     map[string]config.KeyInfo
+			} `json:"inboxes"`
+			OK      int `json:"ok"`
+			Invalid int `json:"invalid"`
+			Expired int `json:"expired"`
+		}
+		require.NoError(t, json.Unmarshal([]byte(out), &result))
+		assert.Equal(t, 2, result.OK)
+		assert.Equal(t, 1, result.Invalid)
+		assert.Equal(t, 1, result.Expired)
+		require.Len(t, result.Inboxes, 4)
+
+		good := result.Inboxes[0]
+		assert.Equal(t, "ok", good.Status)
+		assert.Empty(t, good.Problems)
+		assert.Equal(t, config.KEMPrivateKeySize, good.Keys["kemPrivate"].Size)
+		assert.Equal(t, config.KEMPublicKeySize, good.Keys["kemPublic"].Size)
+		assert.Contains(t, good.Keys["kemPublic"].Fingerprint, "SHA256:")
+		assert.Equal(t, config.ServerSigPKKeySize, good.Keys["serverSigPk"].Size)
+	})
+}
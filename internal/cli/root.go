@@ -2,21 +2,45 @@ package cli
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"os"
+	"os/signal"
 	"path/filepath"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/spf13/cobra"
 	vaultsandbox "github.com/vaultsandbox/client-go"
 	"github.com/vaultsandbox/vsb-cli/internal/cli/data"
+	"github.com/vaultsandbox/vsb-cli/internal/cli/dev"
 	"github.com/vaultsandbox/vsb-cli/internal/cli/email"
 	"github.com/vaultsandbox/vsb-cli/internal/cli/inbox"
+	"github.com/vaultsandbox/vsb-cli/internal/cli/keystore"
 	"github.com/vaultsandbox/vsb-cli/internal/cliutil"
 	"github.com/vaultsandbox/vsb-cli/internal/config"
+	"github.com/vaultsandbox/vsb-cli/internal/metrics"
+	"github.com/vaultsandbox/vsb-cli/internal/styles"
 	"github.com/vaultsandbox/vsb-cli/internal/tui/emails"
+	"golang.org/x/term"
 )
 
-var cfgFile string
+var (
+	cfgFile         string
+	outputFormat    cliutil.OutputFormat
+	jsonCompact     bool
+	verbose         bool
+	rememberInbox   bool
+	browserCmd      string
+	apiKeyFile      string
+	metricsListen   string
+	metricsFile     string
+	metricsInterval string
+	saveDir         string
+	noTUI           bool
+	colorFlag       styles.ColorMode
+)
 
 // Version is set via ldflags at build time
 var Version = "dev"
@@ -29,12 +53,161 @@ var rootCmd = &cobra.Command{
 It provides temporary encrypted inboxes. Emails are encrypted on receipt and
 can only be decrypted locally with your private keys.
 
-Running 'vsb' opens the real-time email dashboard for all inboxes.`,
+Running 'vsb' opens the real-time email dashboard for all inboxes. With
+--no-tui it watches the same inboxes without a terminal, printing a line per
+email to stdout until it is interrupted or sent SIGTERM, e.g. as a sidecar
+in CI or staging.
+
+For monitoring, the watch can report delivery statistics while it runs:
+  --metrics-listen :9090   Serve Prometheus metrics at http://<addr>/metrics
+  --metrics-file <path>    Rewrite a JSON stats file every --metrics-interval
+
+Metrics are emails_received_total (per inbox), sse_reconnects_total,
+last_email_timestamp_seconds and watch_up. Only counts and timestamps are
+exported, never email contents.
+
+--save-dir <path> writes every email the watch receives, including ones
+already in the inbox at startup, to <path>/<id>.eml with 0600 permissions.
+Existing files are left alone, so restarting with the same directory only
+adds new emails.
+
+--env-file <path> loads KEY=VALUE lines into the environment before the
+config is read, keeping credentials such as VSB_API_KEY out of shell
+history. Lines starting with # are comments and values may be quoted.
+Variables already set in the environment are kept, unless the line starts
+with "override":
+
+  # .vsb.env
+  VSB_API_KEY="vsb_..."
+  override VSB_BASE_URL=https://staging.example.com`,
 	RunE: runRoot,
+	// Usage only helps with argument and flag mistakes, which are reported
+	// before this runs. An unsupported --output is one of them.
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if err := cliutil.ValidateOutput(cmd); err != nil {
+			return err
+		}
+		if err := cliutil.ApplyFilterPreset(cmd); err != nil {
+			// An unknown preset name is a usage mistake, a keystore error is not
+			cmd.SilenceUsage = !errors.Is(err, config.ErrPresetNotFound)
+			return err
+		}
+		cmd.SilenceUsage = true
+		if err := cliutil.CheckSavesState(cmd); err != nil {
+			return err
+		}
+		if interactiveSession(cmd) {
+			cliutil.SetInboxPrompt(&cliutil.NumberedInboxPrompt{In: os.Stdin, Out: os.Stderr}, rememberInbox)
+		}
+		return nil
+	},
+	// Execute's caller prints the error
+	SilenceErrors: true,
 }
 
+// ErrInterrupted is returned by Execute when the command was cancelled by
+// SIGINT (Ctrl-C).
+var ErrInterrupted = errors.New("interrupted")
+
+// ErrNoAPIKey is returned by Execute when a command needs the API but no API
+// key is configured. Print NoAPIKeyHelp instead of the error.
+var ErrNoAPIKey = config.ErrNoAPIKey
+
+// ExitError is returned by Execute when the command asks for a specific
+// exit code, e.g. one passed on from 'email wait --exec'. ExitCode returns
+// its Code.
+type ExitError = cliutil.ExitError
+
+// NoAPIKeyHelp explains how to configure an API key.
+const NoAPIKeyHelp = `No API key configured.
+
+Run the setup wizard:
+  vsb init
+
+set one in the config file:
+  vsb config set api-key <key>
+
+or export it in your shell:
+  export VSB_API_KEY=<key>`
+
+// interruptGracePeriod is how long a cancelled command gets to return
+// before Execute stops waiting for it.
+var interruptGracePeriod = 2 * time.Second
+
+// Execute runs the root command with a context that is cancelled on SIGINT.
+// Commands pass this context to client calls so in-flight requests stop;
+// a command that does not return within interruptGracePeriod is abandoned.
+// When a command run with --output json fails, Execute also writes a JSON
+// error object to stdout; exit with ExitCode(err).
 func Execute() error {
-	return rootCmd.Execute()
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	// An interrupted command's "context canceled" error and usage are noise
+	rootCmd.SetErr(&cancelAwareWriter{ctx: ctx, w: os.Stderr})
+
+	type result struct {
+		cmd *cobra.Command
+		err error
+	}
+	// Before anything reads VSB_CONFIG_DIR or the config file
+	if path := flagValue(os.Args[1:], "env-file"); path != "" {
+		if _, err := config.LoadEnvFile(path); err != nil {
+			writeArgsJSONError(os.Args[1:], err)
+			return err
+		}
+	}
+
+	args, err := expandAliases(rootCmd, os.Args[1:], loadAliases(os.Args[1:]))
+	if err != nil {
+		writeArgsJSONError(os.Args[1:], err)
+		return err
+	}
+	// loadAliases read the config file
+	applyFlagDefaults(rootCmd, args, config.GetFlagDefaults(), os.Stderr)
+	rootCmd.SetArgs(args)
+
+	done := make(chan result, 1)
+	go func() {
+		cmd, err := rootCmd.ExecuteContextC(ctx)
+		done <- result{cmd, err}
+	}()
+
+	select {
+	case res := <-done:
+		err := res.err
+		if ctx.Err() != nil {
+			err = ErrInterrupted
+		}
+		if err != nil {
+			writeJSONError(res.cmd, err)
+		}
+		warnClockSkew()
+		return err
+	case <-ctx.Done():
+	}
+
+	// Restore default handling so a second Ctrl-C exits immediately
+	stop()
+
+	select {
+	case <-done:
+	case <-time.After(interruptGracePeriod):
+	}
+	return ErrInterrupted
+}
+
+// cancelAwareWriter discards writes once ctx is cancelled.
+type cancelAwareWriter struct {
+	ctx context.Context
+	w   io.Writer
+}
+
+func (c *cancelAwareWriter) Write(p []byte) (int, error) {
+	if c.ctx.Err() != nil {
+		return len(p), nil
+	}
+	return c.w.Write(p)
 }
 
 func init() {
@@ -42,35 +215,118 @@ func init() {
 
 	rootCmd.Version = Version
 
+	cobra.AddTemplateFunc("shortcuts", commandShortcuts)
+	cobra.AddTemplateFunc("flagUsages", flagUsages)
+	rootCmd.SetUsageTemplate(withConfigDefaults(withShortcuts(rootCmd.UsageTemplate())))
+
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "",
 		"config file (default is $HOME/.config/vsb/config.yaml)")
 
 	// Global output format flag
-	rootCmd.PersistentFlags().StringP("output", "o", "", "Output format: pretty, json")
+	rootCmd.PersistentFlags().VarP(&outputFormat, "output", "o",
+		"Output format: text (or pretty), or json for commands with JSON output; email list also supports csv, email audit sarif, email view and wait raw, inbox list and email list table")
+	rootCmd.RegisterFlagCompletionFunc("output", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return cliutil.OutputFormats(cmd), cobra.ShellCompDirectiveNoFileComp
+	})
+	rootCmd.PersistentFlags().BoolVar(&jsonCompact, "json-compact", false,
+		"Print JSON output on a single line instead of indented")
+	rootCmd.PersistentFlags().Var(&colorFlag, "color",
+		"Color output: auto (on a terminal, unless NO_COLOR is set), always or never")
+	rootCmd.RegisterFlagCompletionFunc("color", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{styles.ColorAuto, styles.ColorAlways, styles.ColorNever}, cobra.ShellCompDirectiveNoFileComp
+	})
+	rootCmd.PersistentFlags().BoolVar(&verbose, "verbose", false,
+		"Print diagnostic messages to stderr")
+	rootCmd.PersistentFlags().BoolVar(&rememberInbox, "remember", false,
+		"Make an inbox picked from the interactive prompt the active inbox")
+	rootCmd.PersistentFlags().StringVar(&browserCmd, "browser", "",
+		"Command used to open URLs instead of the browser config key (%u is replaced with the URL)")
+	rootCmd.PersistentFlags().StringVar(&apiKeyFile, "api-key-file", "",
+		"Read the API key from this file instead of VSB_API_KEY or the config")
+	// Read by Execute before the command line is parsed
+	rootCmd.PersistentFlags().String("env-file", "",
+		"Load environment variables such as VSB_API_KEY from a file of KEY=VALUE lines")
+
+	// Dashboard and --no-tui watch
+	rootCmd.Flags().BoolVar(&noTUI, "no-tui", false,
+		"Watch for emails without the dashboard, printing a line per email")
+	rootCmd.Flags().StringVar(&metricsListen, "metrics-listen", "",
+		"Serve Prometheus metrics on this address (e.g. :9090)")
+	rootCmd.Flags().StringVar(&metricsFile, "metrics-file", "",
+		"Periodically write delivery stats as JSON to this file")
+	rootCmd.Flags().StringVar(&metricsInterval, "metrics-interval", "15s",
+		"How often --metrics-file is rewritten")
+	rootCmd.Flags().StringVar(&saveDir, "save-dir", "",
+		"Save every received email as <id>.eml in this directory")
 
 	// Register subpackage commands
 	rootCmd.AddCommand(inbox.Cmd)
 	rootCmd.AddCommand(email.Cmd)
 	rootCmd.AddCommand(data.ExportCmd)
 	rootCmd.AddCommand(data.ImportCmd)
+	rootCmd.AddCommand(keystore.Cmd)
+	rootCmd.AddCommand(dev.Cmd)
+	rootCmd.AddCommand(aliasCmd)
+}
+
+// stdioIsTerminal reports whether both stdin and stderr are terminals, so the
+// user can see and answer a prompt
+var stdioIsTerminal = func() bool {
+	return term.IsTerminal(int(os.Stdin.Fd())) && term.IsTerminal(int(os.Stderr.Fd()))
+}
+
+// interactiveSession reports whether cmd may prompt the user: only in a
+// terminal, with the default output format and without --quiet.
+func interactiveSession(cmd *cobra.Command) bool {
+	switch cliutil.GetOutput(cmd) {
+	case cliutil.FormatPretty:
+	default:
+		return false
+	}
+	if quiet := cmd.Flags().Lookup("quiet"); quiet != nil && quiet.Value.String() == "true" {
+		return false
+	}
+	return stdioIsTerminal()
 }
 
 func initConfig() {
-	var configPath string
-	if cfgFile != "" {
-		configPath = cfgFile
-	} else {
-		dir, err := config.Dir()
-		if err != nil {
-			return
-		}
-		configPath = filepath.Join(dir, "config.yaml")
+	styles.SetColorMode(colorFlag)
+	cliutil.SetJSONCompact(jsonCompact)
+	cliutil.SetVerbose(verbose)
+	config.SetBrowserOverride(browserCmd)
+	config.SetAPIKeyFileOverride(apiKeyFile)
+	config.SetVersion(Version)
+
+	if configPath, ok := configFilePath(cfgFile); ok {
+		config.LoadFromFile(configPath)
 	}
-	config.LoadFromFile(configPath)
+}
+
+// configFilePath returns the config file to read: flagValue (the --config
+// flag) if set, else config.yaml in the config directory.
+func configFilePath(flagValue string) (string, bool) {
+	if flagValue != "" {
+		return flagValue, true
+	}
+	dir, err := config.Dir()
+	if err != nil {
+		return "", false
+	}
+	return filepath.Join(dir, "config.yaml"), true
+}
+
+// loadAliases reads the user-defined aliases before the command line is
+// parsed, as expanding them changes what is parsed. The config file is read
+// again by initConfig.
+func loadAliases(args []string) map[string]string {
+	if configPath, ok := configFilePath(flagValue(args, "config")); ok {
+		config.LoadFromFile(configPath)
+	}
+	return config.GetAliases()
 }
 
 func runRoot(cmd *cobra.Command, args []string) error {
-	ctx := context.Background()
+	ctx := cliutil.CommandContext(cmd)
 
 	// Load keystore
 	keystore, err := cliutil.LoadKeystoreOrError()
@@ -113,8 +369,25 @@ func runRoot(cmd *cobra.Command, args []string) error {
 		inboxes = append(inboxes, inbox)
 	}
 
+	if noTUI {
+		return runHeadless(ctx, client, inboxes)
+	}
+
 	// Create TUI model starting on active inbox
 	model := emails.NewModel(client, inboxes, activeIdx, keystore)
+	model.SetReadTracker(cliutil.NewReadTracker())
+	model.SetTransport(config.GetStrategy())
+
+	if err := setupSaveDir(&model); err != nil {
+		return err
+	}
+
+	rec, stopMetrics, err := startMetrics(ctx, inboxes)
+	if err != nil {
+		return err
+	}
+	defer stopMetrics()
+	model.SetMetrics(rec)
 
 	// Create and run TUI program
 	p := tea.NewProgram(&model, tea.WithAltScreen())
@@ -132,3 +405,81 @@ func runRoot(cmd *cobra.Command, args []string) error {
 
 	return nil
 }
+
+// startMetrics starts the --metrics-listen server and --metrics-file writer,
+// if requested, and returns the recorder they report. The recorder is nil
+// when neither is enabled. The returned function stops both and is safe to
+// call either way.
+func startMetrics(ctx context.Context, inboxes []*vaultsandbox.Inbox) (*metrics.Recorder, func(), error) {
+	if metricsListen == "" && metricsFile == "" {
+		return nil, func() {}, nil
+	}
+
+	interval, err := time.ParseDuration(metricsInterval)
+	if err != nil || interval <= 0 {
+		return nil, nil, fmt.Errorf("invalid --metrics-interval: %s", metricsInterval)
+	}
+
+	addresses := make([]string, len(inboxes))
+	for i, inbox := range inboxes {
+		addresses[i] = inbox.EmailAddress()
+	}
+	rec := metrics.NewRecorder(addresses...)
+
+	var stops []func()
+	stop := func() {
+		rec.SetUp(false)
+		for i := len(stops) - 1; i >= 0; i-- {
+			stops[i]()
+		}
+	}
+
+	if metricsListen != "" {
+		_, stopServer, err := metrics.Listen(metricsListen, rec.Handler())
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to start metrics listener: %w", err)
+		}
+		stops = append(stops, stopServer)
+	}
+
+	if metricsFile != "" {
+		// Fail now rather than silently on every tick
+		if err := rec.WriteFile(metricsFile); err != nil {
+			stop()
+			return nil, nil, fmt.Errorf("failed to write metrics file: %w", err)
+		}
+		fileCtx, cancel := context.WithCancel(ctx)
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			rec.WriteFileEvery(fileCtx, metricsFile, interval)
+		}()
+		stops = append(stops, func() {
+			cancel()
+			<-done
+		})
+	}
+
+	return rec, stop, nil
+}
+
+// setupSaveDir creates the --save-dir directory, if requested, and enables
+// saving on the model.
+func setupSaveDir(model *emails.Model) error {
+	if saveDir == "" {
+		return nil
+	}
+	if err := createSaveDir(); err != nil {
+		return err
+	}
+	model.SetSaveDir(saveDir)
+	return nil
+}
+
+// createSaveDir creates the --save-dir directory
+func createSaveDir() error {
+	if err := os.MkdirAll(saveDir, 0700); err != nil {
+		return fmt.Errorf("failed to create --save-dir: %w", err)
+	}
+	return nil
+}
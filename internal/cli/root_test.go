@@ -1,13 +1,25 @@
 package cli
 
 import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/vaultsandbox/vsb-cli/internal/cliutil"
+	"github.com/vaultsandbox/vsb-cli/internal/tui/emails"
 )
 
 func TestRootCmd_E2E(t *testing.T) {
@@ -58,6 +70,96 @@ func TestRootCmd_E2E(t *testing.T) {
 		assert.Contains(t, string(output), "vsb version")
 	})
 
+	t.Run("exits 130 on SIGINT", func(t *testing.T) {
+		if runtime.GOOS == "windows" {
+			t.Skip("SIGINT delivery not supported")
+		}
+
+		// Server that never answers, so the command blocks on the network
+		release := make(chan struct{})
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			<-release
+		}))
+		defer srv.Close()
+		defer close(release)
+
+		cmd := exec.Command(binPath, "inbox", "create")
+		cmd.Env = append(os.Environ(),
+			"VSB_CONFIG_DIR="+t.TempDir(),
+			"VSB_API_KEY=test-key",
+			"VSB_BASE_URL="+srv.URL,
+		)
+		var stderr strings.Builder
+		cmd.Stderr = &stderr
+		require.NoError(t, cmd.Start())
+
+		time.Sleep(500 * time.Millisecond)
+		require.NoError(t, cmd.Process.Signal(os.Interrupt))
+
+		start := time.Now()
+		err := cmd.Wait()
+		var exitErr *exec.ExitError
+		require.ErrorAs(t, err, &exitErr)
+		assert.Equal(t, 130, exitErr.ExitCode())
+		assert.Less(t, time.Since(start), 10*time.Second)
+		assert.Contains(t, stderr.String(), "Interrupted")
+		assert.NotContains(t, stderr.String(), "Usage:")
+	})
+
+	t.Run("exits 3 with setup help when no API key", func(t *testing.T) {
+		cmd := exec.Command(binPath, "inbox", "create")
+		cmd.Env = append(os.Environ(),
+			"VSB_CONFIG_DIR="+t.TempDir(),
+			"VSB_API_KEY=",
+		)
+		var stderr strings.Builder
+		cmd.Stderr = &stderr
+
+		err := cmd.Run()
+		var exitErr *exec.ExitError
+		require.ErrorAs(t, err, &exitErr)
+		assert.Equal(t, 3, exitErr.ExitCode())
+		assert.Contains(t, stderr.String(), "vsb config set api-key")
+		assert.NotContains(t, stderr.String(), "Usage:")
+	})
+
+	t.Run("fails before contacting the server when the config dir is not writable", func(t *testing.T) {
+		var requests atomic.Int32
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests.Add(1)
+			http.Error(w, "unexpected request", http.StatusInternalServerError)
+		}))
+		defer srv.Close()
+
+		// Below a regular file, so not even root can create it
+		file := filepath.Join(t.TempDir(), "file")
+		require.NoError(t, os.WriteFile(file, nil, 0600))
+		configDir := filepath.Join(file, "vsb")
+
+		cmd := exec.Command(binPath, "inbox", "create")
+		cmd.Env = append(os.Environ(),
+			"VSB_CONFIG_DIR="+configDir,
+			"VSB_API_KEY=test-key",
+			"VSB_BASE_URL="+srv.URL,
+		)
+		var stderr strings.Builder
+		cmd.Stderr = &stderr
+
+		err := cmd.Run()
+		var exitErr *exec.ExitError
+		require.ErrorAs(t, err, &exitErr)
+		assert.Equal(t, 1, exitErr.ExitCode())
+		assert.Contains(t, stderr.String(), "config directory is not writable: "+configDir)
+		assert.Contains(t, stderr.String(), "VSB_CONFIG_DIR")
+		assert.Zero(t, requests.Load(), "no request may reach the server")
+
+		// A dry run writes nothing, so it still works
+		cmd = exec.Command(binPath, "inbox", "create", "--dry-run")
+		cmd.Env = append(os.Environ(), "VSB_CONFIG_DIR="+configDir)
+		output, err := cmd.CombinedOutput()
+		assert.NoError(t, err, "output: %s", output)
+	})
+
 	t.Run("shows help with --help flag", func(t *testing.T) {
 		cmd := exec.Command(binPath, "--help")
 		output, err := cmd.CombinedOutput()
@@ -109,3 +211,183 @@ func TestRootCmdStructure(t *testing.T) {
 	assert.Contains(t, cmdNames, "export")
 	assert.Contains(t, cmdNames, "import")
 }
+
+func TestCancelAwareWriter(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var buf bytes.Buffer
+	w := &cancelAwareWriter{ctx: ctx, w: &buf}
+
+	_, err := w.Write([]byte("before\n"))
+	require.NoError(t, err)
+
+	cancel()
+	n, err := w.Write([]byte("after\n"))
+	require.NoError(t, err)
+	assert.Equal(t, 6, n)
+
+	assert.Equal(t, "before\n", buf.String())
+}
+
+func TestStartMetrics(t *testing.T) {
+	reset := func() {
+		metricsListen = ""
+		metricsFile = ""
+		metricsInterval = "15s"
+	}
+	t.Cleanup(reset)
+
+	t.Run("disabled by default", func(t *testing.T) {
+		reset()
+		rec, stop, err := startMetrics(context.Background(), nil)
+		require.NoError(t, err)
+		assert.Nil(t, rec)
+		stop()
+	})
+
+	t.Run("invalid interval", func(t *testing.T) {
+		reset()
+		metricsFile = filepath.Join(t.TempDir(), "stats.json")
+		metricsInterval = "soon"
+		_, _, err := startMetrics(context.Background(), nil)
+		assert.ErrorContains(t, err, "invalid --metrics-interval")
+	})
+
+	t.Run("file written on start and stop", func(t *testing.T) {
+		reset()
+		metricsFile = filepath.Join(t.TempDir(), "stats.json")
+		rec, stop, err := startMetrics(context.Background(), nil)
+		require.NoError(t, err)
+		assert.NotNil(t, rec)
+		assert.FileExists(t, metricsFile)
+		stop()
+
+		data, err := os.ReadFile(metricsFile)
+		require.NoError(t, err)
+		assert.Contains(t, string(data), `"watchUp": false`)
+	})
+
+	t.Run("unwritable file fails", func(t *testing.T) {
+		reset()
+		metricsFile = filepath.Join(t.TempDir(), "missing", "stats.json")
+		_, _, err := startMetrics(context.Background(), nil)
+		assert.ErrorContains(t, err, "failed to write metrics file")
+	})
+}
+
+func TestSetupSaveDir(t *testing.T) {
+	t.Cleanup(func() { saveDir = "" })
+
+	t.Run("disabled by default", func(t *testing.T) {
+		saveDir = ""
+		model := emails.NewModel(nil, nil, 0, nil)
+		require.NoError(t, setupSaveDir(&model))
+	})
+
+	t.Run("creates directory", func(t *testing.T) {
+		saveDir = filepath.Join(t.TempDir(), "emails", "run1")
+		model := emails.NewModel(nil, nil, 0, nil)
+		require.NoError(t, setupSaveDir(&model))
+		assert.DirExists(t, saveDir)
+	})
+
+	t.Run("path under a file fails", func(t *testing.T) {
+		file := filepath.Join(t.TempDir(), "file")
+		require.NoError(t, os.WriteFile(file, nil, 0600))
+		saveDir = filepath.Join(file, "emails")
+		model := emails.NewModel(nil, nil, 0, nil)
+		assert.ErrorContains(t, setupSaveDir(&model), "failed to create --save-dir")
+	})
+}
+
+func TestInteractiveSession(t *testing.T) {
+	original := stdioIsTerminal
+	t.Cleanup(func() { stdioIsTerminal = original })
+
+	newCmd := func(args ...string) *cobra.Command {
+		cmd := &cobra.Command{Use: "test"}
+		cmd.Flags().StringP("output", "o", "", "")
+		cmd.Flags().BoolP("quiet", "q", false, "")
+		require.NoError(t, cmd.ParseFlags(args))
+		return cmd
+	}
+
+	stdioIsTerminal = func() bool { return true }
+	assert.True(t, interactiveSession(newCmd()))
+	assert.True(t, interactiveSession(newCmd("-o", "pretty")))
+	assert.False(t, interactiveSession(newCmd("-o", "json")))
+	assert.False(t, interactiveSession(newCmd("--output", "csv")))
+	assert.False(t, interactiveSession(newCmd("--quiet")))
+
+	stdioIsTerminal = func() bool { return false }
+	assert.False(t, interactiveSession(newCmd()))
+}
+
+// textOnlyCommands print no JSON, so they must reject --output json
+var textOnlyCommands = []string{"vsb email delete", "vsb inbox use", "vsb config set", "vsb keystore"}
+
+// TestOutputFlagValidation walks the command tree and checks that every
+// command validates --output through the root hook, with the same message.
+func TestOutputFlagValidation(t *testing.T) {
+	t.Setenv("VSB_OUTPUT", "")
+	output := rootCmd.PersistentFlags().Lookup("output")
+	t.Cleanup(func() {
+		output.Value.Set("")
+		output.Changed = false
+	})
+	setOutput := func(value string) {
+		require.NoError(t, output.Value.Set(value))
+		output.Changed = true
+	}
+
+	var commands []*cobra.Command
+	var walk func(cmd *cobra.Command)
+	walk = func(cmd *cobra.Command) {
+		commands = append(commands, cmd)
+		for _, sub := range cmd.Commands() {
+			walk(sub)
+		}
+	}
+	walk(rootCmd)
+	require.Greater(t, len(commands), 20)
+
+	for _, cmd := range commands {
+		t.Run(cmd.CommandPath(), func(t *testing.T) {
+			if cmd != rootCmd {
+				assert.Nil(t, cmd.PersistentPreRunE, "would replace the root --output validation")
+				assert.Nil(t, cmd.PersistentPreRun, "would replace the root --output validation")
+			}
+			formats := cliutil.OutputFormats(cmd)
+			names := strings.Replace(strings.Join(formats, ", "), cliutil.FormatPretty, "text", 1)
+
+			setOutput("yamml")
+			err := rootCmd.PersistentPreRunE(cmd, nil)
+			require.Error(t, err)
+			assert.Equal(t, "invalid output format 'yamml', valid: "+names, err.Error())
+
+			for _, format := range []string{"", "text"} {
+				setOutput(format)
+				assert.NoError(t, rootCmd.PersistentPreRunE(cmd, nil), format)
+			}
+
+			// JSON is only accepted by commands that print it
+			setOutput("JSON")
+			err = rootCmd.PersistentPreRunE(cmd, nil)
+			if slices.Contains(formats, cliutil.FormatJSON) {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, "invalid output format 'json', valid: "+names)
+			}
+			if slices.Contains(textOnlyCommands, cmd.CommandPath()) {
+				assert.NotContains(t, formats, cliutil.FormatJSON)
+			}
+
+			setOutput("csv")
+			err = rootCmd.PersistentPreRunE(cmd, nil)
+			if cmd.CommandPath() == "vsb email list" {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
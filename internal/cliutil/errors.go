@@ -0,0 +1,74 @@
+package cliutil
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/vaultsandbox/vsb-cli/internal/config"
+)
+
+// InboxNotFoundError is returned by GetInbox when no stored inbox matches
+// the requested address.
+type InboxNotFoundError struct {
+	Query string
+}
+
+func (e *InboxNotFoundError) Error() string {
+	return fmt.Sprintf("inbox not found: %s", e.Query)
+}
+
+func (e *InboxNotFoundError) Unwrap() error {
+	return config.ErrInboxNotFound
+}
+
+// ErrorDetails returns the fields of the JSON error object.
+func (e *InboxNotFoundError) ErrorDetails() map[string]interface{} {
+	return map[string]interface{}{"query": e.Query}
+}
+
+// NoActiveInboxError is returned by GetInbox when no inbox was requested
+// and none is active.
+type NoActiveInboxError struct{}
+
+func (e *NoActiveInboxError) Error() string {
+	return "no active inbox. Create one with 'vsb inbox create' or set with 'vsb inbox use'"
+}
+
+func (e *NoActiveInboxError) Unwrap() error {
+	return config.ErrNoActiveInbox
+}
+
+// TimeoutError is returned when a command gives up waiting, e.g. 'email
+// wait' reaching --timeout.
+type TimeoutError struct {
+	What    string        // what was waited for, e.g. "email"
+	Timeout time.Duration // the limit that was reached
+}
+
+func (e *TimeoutError) Error() string {
+	return "timeout waiting for " + e.What
+}
+
+// ErrorDetails returns the fields of the JSON error object.
+func (e *TimeoutError) ErrorDetails() map[string]interface{} {
+	return map[string]interface{}{"timeout": e.Timeout.String()}
+}
+
+// ErrorDetails returns the fields of the JSON error object.
+func (e *ExitError) ErrorDetails() map[string]interface{} {
+	return map[string]interface{}{"exitStatus": e.Code}
+}
+
+// ErrorDetails returns the fields of the JSON error object.
+func (e *AmbiguousInboxError) ErrorDetails() map[string]interface{} {
+	return map[string]interface{}{"query": e.Query, "candidates": e.Candidates}
+}
+
+// ErrorDetails returns the fields of the JSON error object.
+func (e *UnknownPresetError) ErrorDetails() map[string]interface{} {
+	available := e.Available
+	if available == nil {
+		available = []string{}
+	}
+	return map[string]interface{}{"name": e.Name, "inbox": e.Inbox, "available": available}
+}
@@ -2,7 +2,9 @@ package cliutil
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
 
 	vaultsandbox "github.com/vaultsandbox/client-go"
 	"github.com/vaultsandbox/vsb-cli/internal/config"
@@ -22,25 +24,101 @@ func LoadKeystoreOrError() (*config.Keystore, error) {
 
 // GetInbox returns an inbox by email flag (with partial matching), or the active inbox if emailFlag is empty.
 // Accepts KeystoreReader interface to allow testing with mock implementations.
+// If an inbox prompt is set (see SetInboxPrompt), an ambiguous match or a
+// missing active inbox asks the user to pick one instead of failing.
 func GetInbox(ks KeystoreReader, emailFlag string) (*config.StoredInbox, error) {
 	if emailFlag != "" {
 		inbox, matches, err := ks.FindInbox(emailFlag)
-		if err == config.ErrMultipleMatches {
-			return nil, fmt.Errorf("multiple inboxes match '%s': %v", emailFlag, matches)
+		if errors.Is(err, config.ErrMultipleMatches) {
+			if inboxPrompt != nil {
+				return pickInbox(ks, fmt.Sprintf("Multiple inboxes match '%s':", emailFlag), matchingInboxes(ks, matches))
+			}
+			return nil, newAmbiguousInboxError(ks, emailFlag, matches)
 		}
 		if err != nil {
-			return nil, fmt.Errorf("inbox not found: %s", emailFlag)
+			return nil, &InboxNotFoundError{Query: emailFlag}
 		}
 		return inbox, nil
 	}
 
 	inbox, err := ks.GetActiveInbox()
 	if err != nil {
-		return nil, fmt.Errorf("no active inbox. Create one with 'vsb inbox create' or set with 'vsb inbox use'")
+		if all := ks.ListInboxes(); inboxPrompt != nil && len(all) > 0 {
+			return pickInbox(ks, "No active inbox. Choose one:", all)
+		}
+		return nil, &NoActiveInboxError{}
 	}
 	return inbox, nil
 }
 
+// matchingInboxes looks up the stored inboxes for the emails FindInbox
+// reported as matches.
+func matchingInboxes(ks KeystoreReader, emails []string) []config.StoredInbox {
+	inboxes := make([]config.StoredInbox, 0, len(emails))
+	for _, email := range emails {
+		if stored, err := ks.GetInbox(email); err == nil {
+			inboxes = append(inboxes, *stored)
+		}
+	}
+	return inboxes
+}
+
+// InboxCandidate is one of several inboxes matching an ambiguous identifier.
+type InboxCandidate struct {
+	Email string `json:"email"`
+	Label string `json:"label,omitempty"`
+}
+
+// ExitError makes vsb exit with Code instead of 1, for commands that pass on
+// the exit status of a command they ran.
+type ExitError struct {
+	Code int
+	Err  error
+}
+
+func (e *ExitError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ExitError) Unwrap() error {
+	return e.Err
+}
+
+// AmbiguousInboxError is returned when an inbox identifier matches more than
+// one inbox. It lists the candidates so the user can pick one.
+type AmbiguousInboxError struct {
+	Query      string           `json:"query"`
+	Candidates []InboxCandidate `json:"candidates"`
+}
+
+func (e *AmbiguousInboxError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "multiple inboxes match '%s':", e.Query)
+	for _, c := range e.Candidates {
+		if c.Label != "" {
+			fmt.Fprintf(&b, "\n  %s (%s)", c.Email, c.Label)
+		} else {
+			fmt.Fprintf(&b, "\n  %s", c.Email)
+		}
+	}
+	return b.String()
+}
+
+func (e *AmbiguousInboxError) Unwrap() error {
+	return config.ErrMultipleMatches
+}
+
+func newAmbiguousInboxError(ks KeystoreReader, query string, emails []string) *AmbiguousInboxError {
+	candidates := make([]InboxCandidate, len(emails))
+	for i, email := range emails {
+		candidates[i] = InboxCandidate{Email: email}
+		if stored, err := ks.GetInbox(email); err == nil {
+			candidates[i].Label = stored.Label
+		}
+	}
+	return &AmbiguousInboxError{Query: query, Candidates: candidates}
+}
+
 // LoadAndImportInbox loads the keystore, gets an inbox (by emailFlag or active),
 // creates a client, and imports the inbox into the SDK.
 // Returns the imported inbox, a cleanup function (closes client), and any error.
@@ -78,6 +156,58 @@ func LoadAndImportInbox(ctx context.Context, emailFlag string) (*vaultsandbox.In
 	return inbox, cleanup, nil
 }
 
+// LoadAndImportInboxes loads the keystore, resolves each of emailFlags (with
+// partial matching), or every stored inbox when all is set, creates a client,
+// and imports the inboxes into the SDK. Duplicate matches are imported once.
+// Returns the client, the imported inboxes, a cleanup function (closes client),
+// and any error. The caller must call the cleanup function when done.
+func LoadAndImportInboxes(ctx context.Context, emailFlags []string, all bool) (*vaultsandbox.Client, []*vaultsandbox.Inbox, func(), error) {
+	ks, err := LoadKeystoreOrError()
+	if err != nil {
+		return nil, nil, noopCleanup, err
+	}
+
+	var stored []config.StoredInbox
+	if all {
+		stored = ks.ListInboxes()
+		if len(stored) == 0 {
+			return nil, nil, noopCleanup, fmt.Errorf("no inboxes found. Create one with 'vsb inbox create'")
+		}
+	} else {
+		seen := make(map[string]bool)
+		for _, flag := range emailFlags {
+			inbox, err := GetInbox(ks, flag)
+			if err != nil {
+				return nil, nil, noopCleanup, err
+			}
+			if !seen[inbox.Email] {
+				seen[inbox.Email] = true
+				stored = append(stored, *inbox)
+			}
+		}
+	}
+
+	client, err := config.NewClient()
+	if err != nil {
+		return nil, nil, noopCleanup, err
+	}
+	cleanup := func() {
+		client.Close()
+	}
+
+	inboxes := make([]*vaultsandbox.Inbox, 0, len(stored))
+	for _, s := range stored {
+		inbox, err := client.ImportInbox(ctx, s.ToExportedInbox())
+		if err != nil {
+			cleanup()
+			return nil, nil, noopCleanup, fmt.Errorf("failed to import inbox %s: %w", s.Email, err)
+		}
+		inboxes = append(inboxes, inbox)
+	}
+
+	return client, inboxes, cleanup, nil
+}
+
 // GetArg returns args[index] if it exists, otherwise returns defaultValue.
 func GetArg(args []string, index int, defaultValue string) string {
 	if index < len(args) {
@@ -57,6 +57,7 @@ func TestGetInbox(t *testing.T) {
 		_, err := GetInbox(ks, "")
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "no active inbox")
+		assert.ErrorIs(t, err, config.ErrNoActiveInbox)
 	})
 
 	t.Run("exact email match", func(t *testing.T) {
@@ -91,6 +92,28 @@ func TestGetInbox(t *testing.T) {
 		assert.Contains(t, err.Error(), "multiple inboxes match")
 	})
 
+	t.Run("ambiguity error lists candidates with labels", func(t *testing.T) {
+		ks := &MockKeystore{
+			Inboxes: []config.StoredInbox{
+				{Email: "ci-run-1@example.com", Label: "nightly"},
+				{Email: "ci-run-2@example.com"},
+			},
+		}
+
+		_, err := GetInbox(ks, "ci-run")
+		require.Error(t, err)
+		assert.ErrorIs(t, err, config.ErrMultipleMatches)
+
+		var ambErr *AmbiguousInboxError
+		require.ErrorAs(t, err, &ambErr)
+		assert.Equal(t, "ci-run", ambErr.Query)
+		assert.Equal(t, []InboxCandidate{
+			{Email: "ci-run-1@example.com", Label: "nightly"},
+			{Email: "ci-run-2@example.com"},
+		}, ambErr.Candidates)
+		assert.Equal(t, "multiple inboxes match 'ci-run':\n  ci-run-1@example.com (nightly)\n  ci-run-2@example.com", err.Error())
+	})
+
 	t.Run("no match returns error", func(t *testing.T) {
 		ks := &MockKeystore{
 			Inboxes: []config.StoredInbox{inbox1},
@@ -1,6 +1,7 @@
 package cliutil
 
 import (
+	"encoding/base64"
 	"time"
 
 	"github.com/vaultsandbox/client-go"
@@ -16,6 +17,10 @@ type EmailJSONOptions struct {
 	IncludeHeaders     bool
 	IncludeAuthResults bool
 	IncludeScore       bool
+	IncludeAttachments bool // metadata only
+	// IncludeAttachmentContent adds base64 content to each attachment;
+	// implies IncludeAttachments
+	IncludeAttachmentContent bool
 }
 
 // EmailJSON returns a map for JSON output with configurable fields.
@@ -46,10 +51,36 @@ func EmailJSON(email *vaultsandbox.Email, opts EmailJSONOptions) map[string]inte
 	if opts.IncludeScore {
 		m["securityScore"] = styles.CalculateScore(email)
 	}
+	if opts.IncludeAttachments || opts.IncludeAttachmentContent {
+		m["attachments"] = buildAttachmentsJSON(email.Attachments, opts.IncludeAttachmentContent)
+	}
 
 	return m
 }
 
+// buildAttachmentsJSON builds the attachment list for JSON output.
+func buildAttachmentsJSON(attachments []vaultsandbox.Attachment, includeContent bool) []map[string]interface{} {
+	result := make([]map[string]interface{}, 0, len(attachments))
+	for _, a := range attachments {
+		item := map[string]interface{}{
+			"filename":    a.Filename,
+			"contentType": a.ContentType,
+			"size":        a.Size,
+		}
+		if a.ContentID != "" {
+			item["contentId"] = a.ContentID
+		}
+		if a.Checksum != "" {
+			item["checksum"] = a.Checksum
+		}
+		if includeContent {
+			item["content"] = base64.StdEncoding.EncodeToString(a.Content)
+		}
+		result = append(result, item)
+	}
+	return result
+}
+
 // buildAuthResultsJSON builds auth results map for JSON output.
 func buildAuthResultsJSON(email *vaultsandbox.Email) map[string]interface{} {
 	auth := email.AuthResults
@@ -129,6 +160,10 @@ func InboxJSON(inbox *config.StoredInbox, isActive bool, now time.Time, opts Inb
 		"expiresAt": inbox.ExpiresAt.Format(time.RFC3339),
 		"isActive":  isActive,
 		"isExpired": inbox.ExpiresAt.Before(now),
+		"tags":      inbox.Tags,
+	}
+	if inbox.Tags == nil {
+		m["tags"] = []string{}
 	}
 
 	if opts.IncludeID {
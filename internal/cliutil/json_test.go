@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	vaultsandbox "github.com/vaultsandbox/client-go"
 	"github.com/vaultsandbox/vsb-cli/internal/config"
 )
@@ -238,6 +239,24 @@ func TestEmailJSON_Options(t *testing.T) {
 		assert.Equal(t, []string{"https://example.com"}, result["links"])
 		assert.Equal(t, map[string]string{"X-Test": "value"}, result["headers"])
 	})
+
+	t.Run("attachments metadata and content", func(t *testing.T) {
+		withAttachment := *email
+		withAttachment.Attachments = []vaultsandbox.Attachment{
+			{Filename: "a.txt", ContentType: "text/plain", Size: 5, Content: []byte("hello")},
+		}
+
+		result := EmailJSON(&withAttachment, EmailJSONOptions{IncludeAttachments: true})
+		attachments := result["attachments"].([]map[string]interface{})
+		require.Len(t, attachments, 1)
+		assert.Equal(t, "a.txt", attachments[0]["filename"])
+		assert.Equal(t, 5, attachments[0]["size"])
+		assert.Nil(t, attachments[0]["content"])
+
+		result = EmailJSON(&withAttachment, EmailJSONOptions{IncludeAttachmentContent: true})
+		attachments = result["attachments"].([]map[string]interface{})
+		assert.Equal(t, "aGVsbG8=", attachments[0]["content"])
+	})
 }
 
 func TestInboxJSON_Options(t *testing.T) {
@@ -259,6 +278,14 @@ func TestInboxJSON_Options(t *testing.T) {
 		assert.Nil(t, result["id"])
 		assert.Nil(t, result["createdAt"])
 		assert.Nil(t, result["emailCount"])
+		assert.Equal(t, []string{}, result["tags"])
+	})
+
+	t.Run("tags", func(t *testing.T) {
+		tagged := *inbox
+		tagged.Tags = []string{"auth", "billing"}
+		result := InboxJSON(&tagged, true, now, InboxJSONOptions{})
+		assert.Equal(t, []string{"auth", "billing"}, result["tags"])
 	})
 
 	t.Run("include id only", func(t *testing.T) {
@@ -1,8 +1,6 @@
 package cliutil
 
 import (
-	"strings"
-
 	"github.com/vaultsandbox/vsb-cli/internal/config"
 )
 
@@ -37,24 +35,18 @@ func (m *MockKeystore) FindInbox(partial string) (*config.StoredInbox, []string,
 	if m.FindInboxFunc != nil {
 		return m.FindInboxFunc(partial)
 	}
-	var matches []config.StoredInbox
-	var matchEmails []string
-	for i := range m.Inboxes {
-		if m.Inboxes[i].Email == partial {
-			return &m.Inboxes[i], nil, nil // Exact match
-		}
-		if strings.Contains(m.Inboxes[i].Email, partial) {
-			matches = append(matches, m.Inboxes[i])
-			matchEmails = append(matchEmails, m.Inboxes[i].Email)
-		}
-	}
+	matches := config.MatchInboxes(m.Inboxes, partial)
 	if len(matches) == 0 {
 		return nil, nil, config.ErrInboxNotFound
 	}
 	if len(matches) > 1 {
+		matchEmails := make([]string, len(matches))
+		for i, idx := range matches {
+			matchEmails[i] = m.Inboxes[idx].Email
+		}
 		return nil, matchEmails, config.ErrMultipleMatches
 	}
-	return &matches[0], nil, nil
+	return &m.Inboxes[matches[0]], nil, nil
 }
 
 func (m *MockKeystore) GetInbox(email string) (*config.StoredInbox, error) {
@@ -0,0 +1,117 @@
+package cliutil
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/vaultsandbox/vsb-cli/internal/config"
+)
+
+// Output formats for --output. "text" and "" are accepted as pretty.
+const (
+	FormatPretty = "pretty"
+	FormatJSON   = "json"
+	FormatCSV    = "csv"
+	FormatSARIF  = "sarif"
+	FormatRaw    = "raw"
+	FormatTable  = "table"
+)
+
+// outputFormats lists every format some command supports
+var outputFormats = []string{FormatPretty, FormatJSON, FormatCSV, FormatSARIF, FormatRaw, FormatTable}
+
+// defaultOutputFormats are the formats every command supports; the others,
+// json included, are declared with AddOutputFormats
+var defaultOutputFormats = []string{FormatPretty}
+
+// outputFormatsAnnotation holds a command's declared formats, comma-separated
+const outputFormatsAnnotation = "vsb_output_formats"
+
+// NormalizeOutputFormat lowercases a format name and maps "" and "text" to
+// pretty.
+func NormalizeOutputFormat(format string) string {
+	format = strings.ToLower(strings.TrimSpace(format))
+	if format == "" || format == "text" {
+		return FormatPretty
+	}
+	return format
+}
+
+// OutputFormat is the pflag.Value of --output. It only normalizes the
+// name; ValidateOutput checks it against the formats of the command that
+// runs, so the error can list them.
+type OutputFormat string
+
+func (f *OutputFormat) String() string { return string(*f) }
+
+func (f *OutputFormat) Set(value string) error {
+	*f = OutputFormat(NormalizeOutputFormat(value))
+	return nil
+}
+
+func (f *OutputFormat) Type() string { return "format" }
+
+// AddOutputFormats declares the formats cmd supports besides pretty. A
+// command only accepts --output json if it declares FormatJSON.
+func AddOutputFormats(cmd *cobra.Command, formats ...string) {
+	if cmd.Annotations == nil {
+		cmd.Annotations = map[string]string{}
+	}
+	cmd.Annotations[outputFormatsAnnotation] = strings.Join(formats, ",")
+}
+
+// OutputFormats returns the formats cmd supports, pretty first.
+func OutputFormats(cmd *cobra.Command) []string {
+	formats := slices.Clone(defaultOutputFormats)
+	if extra := cmd.Annotations[outputFormatsAnnotation]; extra != "" {
+		formats = append(formats, strings.Split(extra, ",")...)
+	}
+	return formats
+}
+
+// ValidateOutput checks the --output value, or the configured default when
+// the flag is not given, against the formats cmd supports. A configured
+// default that cmd does not support falls back to pretty, so default_output:
+// csv only applies where CSV exists.
+func ValidateOutput(cmd *cobra.Command) error {
+	if flag := cmd.Flag("output"); flag != nil && flag.Changed {
+		format := NormalizeOutputFormat(flag.Value.String())
+		if !slices.Contains(OutputFormats(cmd), format) {
+			return invalidOutputError(format, OutputFormats(cmd))
+		}
+		return nil
+	}
+
+	format := NormalizeOutputFormat(config.GetDefaultOutput())
+	if !slices.Contains(outputFormats, format) {
+		return fmt.Errorf("%w (set by VSB_OUTPUT or default_output)", invalidOutputError(config.GetDefaultOutput(), outputFormats))
+	}
+	return nil
+}
+
+// GetOutput returns the output format with priority: flag > env > config > default.
+func GetOutput(cmd *cobra.Command) string {
+	if flag := cmd.Flag("output"); flag != nil && flag.Changed {
+		return NormalizeOutputFormat(flag.Value.String())
+	}
+	format := NormalizeOutputFormat(config.GetDefaultOutput())
+	if !slices.Contains(OutputFormats(cmd), format) {
+		return FormatPretty
+	}
+	return format
+}
+
+// invalidOutputError lists the valid formats, calling pretty "text" as the
+// help does
+func invalidOutputError(value string, valid []string) error {
+	names := make([]string, len(valid))
+	for i, format := range valid {
+		names[i] = format
+		if format == FormatPretty {
+			names[i] = "text"
+		}
+	}
+	return fmt.Errorf("invalid output format '%s', valid: %s", value, strings.Join(names, ", "))
+}
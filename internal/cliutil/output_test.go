@@ -0,0 +1,69 @@
+package cliutil
+
+import (
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeOutputFormat(t *testing.T) {
+	assert.Equal(t, FormatPretty, NormalizeOutputFormat(""))
+	assert.Equal(t, FormatPretty, NormalizeOutputFormat("text"))
+	assert.Equal(t, FormatJSON, NormalizeOutputFormat(" JSON "))
+	assert.Equal(t, "yamml", NormalizeOutputFormat("yamml"))
+}
+
+func TestValidateOutput(t *testing.T) {
+	newCmd := func(args ...string) *cobra.Command {
+		var format OutputFormat
+		cmd := &cobra.Command{Use: "test"}
+		cmd.Flags().VarP(&format, "output", "o", "")
+		require.NoError(t, cmd.ParseFlags(args))
+		return cmd
+	}
+
+	t.Run("flag", func(t *testing.T) {
+		t.Setenv("VSB_OUTPUT", "")
+		assert.NoError(t, ValidateOutput(newCmd("-o", "text")))
+		assert.EqualError(t, ValidateOutput(newCmd("-o", "json")), "invalid output format 'json', valid: text")
+		assert.EqualError(t, ValidateOutput(newCmd("-o", "yamml")), "invalid output format 'yamml', valid: text")
+
+		cmd := newCmd("-o", "json")
+		AddOutputFormats(cmd, FormatJSON)
+		assert.NoError(t, ValidateOutput(cmd))
+		assert.EqualError(t, ValidateOutput(newCmd("-o", "csv")), "invalid output format 'csv', valid: text")
+
+		cmd = newCmd("-o", "CSV")
+		AddOutputFormats(cmd, FormatCSV)
+		assert.NoError(t, ValidateOutput(cmd))
+		assert.Equal(t, FormatCSV, GetOutput(cmd))
+	})
+
+	t.Run("configured default", func(t *testing.T) {
+		t.Setenv("VSB_OUTPUT", "yamml")
+		assert.ErrorContains(t, ValidateOutput(newCmd()), "VSB_OUTPUT or default_output")
+		assert.NoError(t, ValidateOutput(newCmd("-o", "text")), "the flag overrides the default")
+
+		// A default the command does not support falls back to pretty
+		t.Setenv("VSB_OUTPUT", "json")
+		assert.NoError(t, ValidateOutput(newCmd()))
+		assert.Equal(t, FormatPretty, GetOutput(newCmd()))
+		t.Setenv("VSB_OUTPUT", "csv")
+		assert.NoError(t, ValidateOutput(newCmd()))
+		assert.Equal(t, FormatPretty, GetOutput(newCmd()))
+
+		cmd := newCmd()
+		AddOutputFormats(cmd, FormatCSV)
+		assert.Equal(t, FormatCSV, GetOutput(cmd))
+	})
+}
+
+func TestOutputFormats(t *testing.T) {
+	cmd := &cobra.Command{}
+	assert.Equal(t, []string{"pretty"}, OutputFormats(cmd))
+
+	AddOutputFormats(cmd, FormatJSON, FormatSARIF)
+	assert.Equal(t, []string{"pretty", "json", "sarif"}, OutputFormats(cmd))
+}
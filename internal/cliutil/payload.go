@@ -0,0 +1,75 @@
+package cliutil
+
+import (
+	"time"
+
+	vaultsandbox "github.com/vaultsandbox/client-go"
+	"github.com/vaultsandbox/client-go/authresults"
+)
+
+// EmailPayload is an email exactly as the SDK returned it after decryption,
+// for --debug-raw. Unlike EmailJSON it keeps every field, including ones the
+// CLI never shows, and does no formatting, so a missing value can be traced
+// to the SDK rather than the CLI. Emails carry no inbox keys, so nothing
+// secret ends up here.
+//
+// Keep it in sync with vaultsandbox.Email; TestEmailPayloadCoversSDK fails
+// when the SDK gains a field.
+type EmailPayload struct {
+	ID          string                   `json:"id"`
+	From        string                   `json:"from"`
+	To          []string                 `json:"to"`
+	Subject     string                   `json:"subject"`
+	Text        string                   `json:"text"`
+	HTML        string                   `json:"html"`
+	ReceivedAt  time.Time                `json:"receivedAt"`
+	Headers     map[string]string        `json:"headers"`
+	Attachments []AttachmentPayload      `json:"attachments"`
+	Links       []string                 `json:"links"`
+	AuthResults *authresults.AuthResults `json:"authResults"`
+	IsRead      bool                     `json:"isRead"`
+}
+
+// AttachmentPayload mirrors vaultsandbox.Attachment. Content is base64.
+type AttachmentPayload struct {
+	Filename           string `json:"filename"`
+	ContentType        string `json:"contentType"`
+	Size               int    `json:"size"`
+	ContentID          string `json:"contentId"`
+	ContentDisposition string `json:"contentDisposition"`
+	Content            []byte `json:"content"`
+	Checksum           string `json:"checksum"`
+}
+
+// NewEmailPayload copies every field of an SDK email. Nil slices and maps
+// stay nil (null in JSON) so they can be told apart from empty ones.
+func NewEmailPayload(email *vaultsandbox.Email) EmailPayload {
+	p := EmailPayload{
+		ID:          email.ID,
+		From:        email.From,
+		To:          email.To,
+		Subject:     email.Subject,
+		Text:        email.Text,
+		HTML:        email.HTML,
+		ReceivedAt:  email.ReceivedAt,
+		Headers:     email.Headers,
+		Links:       email.Links,
+		AuthResults: email.AuthResults,
+		IsRead:      email.IsRead,
+	}
+	if email.Attachments != nil {
+		p.Attachments = make([]AttachmentPayload, len(email.Attachments))
+		for i, a := range email.Attachments {
+			p.Attachments[i] = AttachmentPayload{
+				Filename:           a.Filename,
+				ContentType:        a.ContentType,
+				Size:               a.Size,
+				ContentID:          a.ContentID,
+				ContentDisposition: a.ContentDisposition,
+				Content:            a.Content,
+				Checksum:           a.Checksum,
+			}
+		}
+	}
+	return p
+}
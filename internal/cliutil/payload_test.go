@@ -0,0 +1,74 @@
+package cliutil
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	vaultsandbox "github.com/vaultsandbox/client-go"
+	"github.com/vaultsandbox/client-go/authresults"
+)
+
+// TestEmailPayloadCoversSDK fails when the SDK adds a field that the
+// payload mapping would silently drop.
+func TestEmailPayloadCoversSDK(t *testing.T) {
+	pairs := []struct{ sdk, payload reflect.Type }{
+		{reflect.TypeOf(vaultsandbox.Email{}), reflect.TypeOf(EmailPayload{})},
+		{reflect.TypeOf(vaultsandbox.Attachment{}), reflect.TypeOf(AttachmentPayload{})},
+	}
+	for _, p := range pairs {
+		for i := 0; i < p.sdk.NumField(); i++ {
+			name := p.sdk.Field(i).Name
+			_, ok := p.payload.FieldByName(name)
+			assert.True(t, ok, "%s.%s is missing from %s", p.sdk.Name(), name, p.payload.Name())
+		}
+	}
+}
+
+func TestNewEmailPayload(t *testing.T) {
+	received := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	email := &vaultsandbox.Email{
+		ID:         "e1",
+		From:       "a@example.com",
+		To:         []string{"b@example.com"},
+		Subject:    "Hi",
+		Text:       "text",
+		HTML:       "<a href=\"https://x\">x</a>",
+		ReceivedAt: received,
+		Headers:    map[string]string{"X-Test": "1"},
+		Attachments: []vaultsandbox.Attachment{{
+			Filename: "a.txt", ContentType: "text/plain", Size: 2, ContentID: "cid",
+			ContentDisposition: "attachment", Content: []byte("hi"), Checksum: "sum",
+		}},
+		AuthResults: &authresults.AuthResults{SPF: &authresults.SPFResult{Result: "pass", IP: "192.0.2.1"}},
+		IsRead:      true,
+	}
+
+	p := NewEmailPayload(email)
+	assert.Equal(t, "e1", p.ID)
+	assert.Equal(t, received, p.ReceivedAt)
+	assert.True(t, p.IsRead)
+	require.Len(t, p.Attachments, 1)
+	assert.Equal(t, "attachment", p.Attachments[0].ContentDisposition)
+	assert.Equal(t, []byte("hi"), p.Attachments[0].Content)
+	assert.Same(t, email.AuthResults, p.AuthResults)
+
+	data, err := json.Marshal(p)
+	require.NoError(t, err)
+	var decoded map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	// Nil links stay distinguishable from an empty list
+	assert.Nil(t, decoded["links"])
+	assert.Contains(t, decoded, "links")
+	assert.Equal(t, "aGk=", decoded["attachments"].([]interface{})[0].(map[string]interface{})["content"])
+	assert.Equal(t, "192.0.2.1", decoded["authResults"].(map[string]interface{})["spf"].(map[string]interface{})["ip"])
+
+	t.Run("empty email", func(t *testing.T) {
+		p := NewEmailPayload(&vaultsandbox.Email{Links: []string{}})
+		assert.Nil(t, p.Attachments)
+		assert.NotNil(t, p.Links)
+	})
+}
@@ -0,0 +1,101 @@
+package cliutil
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/vaultsandbox/vsb-cli/internal/config"
+)
+
+// InboxPrompt asks the user to choose one of several inboxes.
+type InboxPrompt interface {
+	// SelectInbox shows inboxes under title and returns the index of the
+	// chosen one.
+	SelectInbox(title string, inboxes []config.StoredInbox) (int, error)
+}
+
+var (
+	inboxPrompt    InboxPrompt
+	rememberChoice bool
+)
+
+// SetInboxPrompt makes GetInbox ask with p instead of failing when a partial
+// match is ambiguous or no inbox is active. With remember, the chosen inbox
+// is made active. A nil p restores the errors; only set one for interactive
+// sessions.
+func SetInboxPrompt(p InboxPrompt, remember bool) {
+	inboxPrompt = p
+	rememberChoice = remember
+}
+
+// activeInboxSetter is implemented by keystores that can change the active
+// inbox.
+type activeInboxSetter interface {
+	SetActiveInbox(email string) error
+}
+
+// pickInbox asks the configured prompt to choose among inboxes.
+func pickInbox(ks KeystoreReader, title string, inboxes []config.StoredInbox) (*config.StoredInbox, error) {
+	idx, err := inboxPrompt.SelectInbox(title, inboxes)
+	if err != nil {
+		return nil, err
+	}
+	if idx < 0 || idx >= len(inboxes) {
+		return nil, fmt.Errorf("invalid inbox selection")
+	}
+
+	chosen, err := ks.GetInbox(inboxes[idx].Email)
+	if err != nil {
+		return nil, fmt.Errorf("inbox not found: %s", inboxes[idx].Email)
+	}
+	if rememberChoice {
+		if setter, ok := ks.(activeInboxSetter); ok {
+			if err := setter.SetActiveInbox(chosen.Email); err != nil {
+				return nil, fmt.Errorf("failed to set active inbox: %w", err)
+			}
+		}
+	}
+	return chosen, nil
+}
+
+// NumberedInboxPrompt lists inboxes with numbers on Out and reads the chosen
+// number from In.
+type NumberedInboxPrompt struct {
+	In  io.Reader
+	Out io.Writer
+}
+
+// SelectInbox implements InboxPrompt.
+func (p *NumberedInboxPrompt) SelectInbox(title string, inboxes []config.StoredInbox) (int, error) {
+	fmt.Fprintln(p.Out, title)
+	for i, inbox := range inboxes {
+		line := fmt.Sprintf("  %d) %s", i+1, inbox.Email)
+		if inbox.Label != "" {
+			line += "  [" + inbox.Label + "]"
+		}
+		if IsExpired(inbox.ExpiresAt) {
+			line += "  (expired)"
+		} else {
+			line += "  (expires in " + FormatExpiry(inbox.ExpiresAt) + ")"
+		}
+		fmt.Fprintln(p.Out, line)
+	}
+	fmt.Fprintf(p.Out, "Select inbox [1-%d]: ", len(inboxes))
+
+	answer, err := bufio.NewReader(p.In).ReadString('\n')
+	answer = strings.TrimSpace(answer)
+	if answer == "" {
+		if err != nil && err != io.EOF {
+			return 0, fmt.Errorf("failed to read selection: %w", err)
+		}
+		return 0, fmt.Errorf("no inbox selected")
+	}
+	n, convErr := strconv.Atoi(answer)
+	if convErr != nil || n < 1 || n > len(inboxes) {
+		return 0, fmt.Errorf("invalid selection %q (enter a number from 1 to %d)", answer, len(inboxes))
+	}
+	return n - 1, nil
+}
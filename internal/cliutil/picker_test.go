@@ -0,0 +1,158 @@
+package cliutil
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vaultsandbox/vsb-cli/internal/config"
+)
+
+// fakeInboxPrompt records what it was shown and picks a fixed index.
+type fakeInboxPrompt struct {
+	choice int
+	err    error
+
+	title  string
+	emails []string
+}
+
+func (p *fakeInboxPrompt) SelectInbox(title string, inboxes []config.StoredInbox) (int, error) {
+	p.title = title
+	p.emails = nil
+	for _, inbox := range inboxes {
+		p.emails = append(p.emails, inbox.Email)
+	}
+	return p.choice, p.err
+}
+
+func usePrompt(t *testing.T, p InboxPrompt, remember bool) {
+	t.Helper()
+	SetInboxPrompt(p, remember)
+	t.Cleanup(func() { SetInboxPrompt(nil, false) })
+}
+
+func pickerKeystore() *MockKeystore {
+	return &MockKeystore{Inboxes: []config.StoredInbox{
+		{Email: "alpha@test.com", Label: "ci"},
+		{Email: "alpha2@test.com"},
+		{Email: "beta@test.com"},
+	}}
+}
+
+func TestGetInboxPicker(t *testing.T) {
+	t.Run("ambiguous match prompts with candidates", func(t *testing.T) {
+		prompt := &fakeInboxPrompt{choice: 1}
+		usePrompt(t, prompt, false)
+		ks := pickerKeystore()
+
+		inbox, err := GetInbox(ks, "alpha")
+		require.NoError(t, err)
+		assert.Equal(t, "alpha2@test.com", inbox.Email)
+		assert.Equal(t, []string{"alpha@test.com", "alpha2@test.com"}, prompt.emails)
+		assert.Contains(t, prompt.title, "'alpha'")
+		assert.Empty(t, ks.ActiveEmail)
+	})
+
+	t.Run("no active inbox prompts with all inboxes", func(t *testing.T) {
+		prompt := &fakeInboxPrompt{choice: 2}
+		usePrompt(t, prompt, false)
+
+		inbox, err := GetInbox(pickerKeystore(), "")
+		require.NoError(t, err)
+		assert.Equal(t, "beta@test.com", inbox.Email)
+		assert.Len(t, prompt.emails, 3)
+	})
+
+	t.Run("remember sets the chosen inbox active", func(t *testing.T) {
+		usePrompt(t, &fakeInboxPrompt{choice: 0}, true)
+		ks := pickerKeystore()
+
+		_, err := GetInbox(ks, "alpha")
+		require.NoError(t, err)
+		assert.Equal(t, "alpha@test.com", ks.ActiveEmail)
+	})
+
+	t.Run("prompt error is returned", func(t *testing.T) {
+		usePrompt(t, &fakeInboxPrompt{err: errors.New("no inbox selected")}, false)
+
+		_, err := GetInbox(pickerKeystore(), "alpha")
+		assert.EqualError(t, err, "no inbox selected")
+	})
+
+	t.Run("out of range choice is rejected", func(t *testing.T) {
+		usePrompt(t, &fakeInboxPrompt{choice: 5}, false)
+
+		_, err := GetInbox(pickerKeystore(), "alpha")
+		assert.Error(t, err)
+	})
+
+	t.Run("empty keystore still fails", func(t *testing.T) {
+		prompt := &fakeInboxPrompt{}
+		usePrompt(t, prompt, false)
+
+		_, err := GetInbox(&MockKeystore{}, "")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "no active inbox")
+		assert.Empty(t, prompt.title)
+	})
+
+	t.Run("unknown inbox is not prompted", func(t *testing.T) {
+		prompt := &fakeInboxPrompt{}
+		usePrompt(t, prompt, false)
+
+		_, err := GetInbox(pickerKeystore(), "gamma")
+		assert.EqualError(t, err, "inbox not found: gamma")
+		assert.ErrorIs(t, err, config.ErrInboxNotFound)
+		assert.Empty(t, prompt.title)
+	})
+
+	t.Run("without a prompt errors are unchanged", func(t *testing.T) {
+		SetInboxPrompt(nil, false)
+
+		_, err := GetInbox(pickerKeystore(), "alpha")
+		var ambiguous *AmbiguousInboxError
+		require.ErrorAs(t, err, &ambiguous)
+
+		_, err = GetInbox(pickerKeystore(), "")
+		assert.Contains(t, err.Error(), "no active inbox")
+	})
+}
+
+func TestNumberedInboxPrompt(t *testing.T) {
+	inboxes := []config.StoredInbox{
+		{Email: "a@test.com", Label: "ci", ExpiresAt: time.Now().Add(3 * time.Hour)},
+		{Email: "b@test.com", ExpiresAt: time.Now().Add(-time.Hour)},
+	}
+
+	t.Run("lists inboxes and reads choice", func(t *testing.T) {
+		var out bytes.Buffer
+		p := &NumberedInboxPrompt{In: strings.NewReader("2\n"), Out: &out}
+
+		idx, err := p.SelectInbox("Choose:", inboxes)
+		require.NoError(t, err)
+		assert.Equal(t, 1, idx)
+		assert.Contains(t, out.String(), "Choose:")
+		assert.Contains(t, out.String(), "1) a@test.com  [ci]  (expires in 3h)")
+		assert.Contains(t, out.String(), "2) b@test.com  (expired)")
+		assert.Contains(t, out.String(), "Select inbox [1-2]: ")
+	})
+
+	t.Run("rejects invalid input", func(t *testing.T) {
+		for _, input := range []string{"3\n", "0\n", "abc\n"} {
+			p := &NumberedInboxPrompt{In: strings.NewReader(input), Out: &bytes.Buffer{}}
+			_, err := p.SelectInbox("Choose:", inboxes)
+			assert.Error(t, err, input)
+		}
+	})
+
+	t.Run("empty input selects nothing", func(t *testing.T) {
+		p := &NumberedInboxPrompt{In: strings.NewReader(""), Out: &bytes.Buffer{}}
+		_, err := p.SelectInbox("Choose:", inboxes)
+		assert.EqualError(t, err, "no inbox selected")
+	})
+}
@@ -0,0 +1,111 @@
+package cliutil
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/vaultsandbox/vsb-cli/internal/config"
+)
+
+// presetFlag selects a filter preset stored on the inbox
+const presetFlag = "preset"
+
+// AddPresetFlag adds --preset to a command. The command must define a flag
+// for every filter a preset can set (see config.FilterPreset.Flags);
+// ApplyFilterPreset fills them in before the command runs.
+func AddPresetFlag(cmd *cobra.Command) {
+	cmd.Flags().String(presetFlag, "",
+		"Apply a filter preset stored on the inbox (see 'vsb inbox filter')")
+}
+
+// ApplyFilterPreset expands --preset into the command's filter flags. A flag
+// given on the command line wins over the preset's value for it. Commands
+// without --preset, or with it unset, are left alone.
+//
+// The preset is looked up on the inbox the command targets: its --inbox
+// flag (the first one, for commands that take several) or the active inbox.
+func ApplyFilterPreset(cmd *cobra.Command) error {
+	flag := cmd.Flags().Lookup(presetFlag)
+	if flag == nil || flag.Value.String() == "" {
+		return nil
+	}
+	name := flag.Value.String()
+
+	ks, err := LoadKeystoreOrError()
+	if err != nil {
+		return err
+	}
+	stored, err := GetInbox(ks, presetInbox(cmd.Flags()))
+	if err != nil {
+		return err
+	}
+	preset, ok := stored.Filters[name]
+	if !ok {
+		return &UnknownPresetError{Name: name, Inbox: stored.Email, Available: stored.PresetNames()}
+	}
+	return applyPreset(cmd.Flags(), preset)
+}
+
+// applyPreset sets each flag the preset defines, unless it was given on the
+// command line.
+func applyPreset(flags *pflag.FlagSet, preset config.FilterPreset) error {
+	values := preset.Flags()
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		f := flags.Lookup(name)
+		if f == nil {
+			return fmt.Errorf("--%s is not supported here", name)
+		}
+		if f.Changed {
+			continue
+		}
+		if err := flags.Set(name, values[name]); err != nil {
+			return fmt.Errorf("preset --%s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// presetInbox returns the --inbox value to look presets up on, or "" for
+// the active inbox.
+func presetInbox(flags *pflag.FlagSet) string {
+	f := flags.Lookup("inbox")
+	if f == nil {
+		return ""
+	}
+	values := []string{f.Value.String()}
+	if slice, ok := f.Value.(pflag.SliceValue); ok {
+		values = slice.GetSlice()
+	}
+	if len(values) == 0 || strings.EqualFold(values[0], "all") {
+		return ""
+	}
+	return values[0]
+}
+
+// UnknownPresetError is returned by ApplyFilterPreset when the inbox has no
+// preset with the requested name. It lists the ones it has.
+type UnknownPresetError struct {
+	Name      string
+	Inbox     string
+	Available []string
+}
+
+func (e *UnknownPresetError) Error() string {
+	if len(e.Available) == 0 {
+		return fmt.Sprintf("unknown preset %q: %s has no filter presets (add one with 'vsb inbox filter set')", e.Name, e.Inbox)
+	}
+	return fmt.Sprintf("unknown preset %q for %s (available: %s)", e.Name, e.Inbox, strings.Join(e.Available, ", "))
+}
+
+func (e *UnknownPresetError) Unwrap() error {
+	return config.ErrPresetNotFound
+}
@@ -0,0 +1,138 @@
+package cliutil
+
+import (
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vaultsandbox/vsb-cli/internal/config"
+)
+
+// presetTestCmd returns a command with the filter flags and --preset, like
+// 'email list'.
+func presetTestCmd() *cobra.Command {
+	cmd := &cobra.Command{Use: "list"}
+	for _, name := range []string{"subject", "subject-regex", "from", "from-regex", "body-regex", "inbox"} {
+		cmd.Flags().String(name, "", "")
+	}
+	AddPresetFlag(cmd)
+	return cmd
+}
+
+func TestApplyPreset(t *testing.T) {
+	preset := config.FilterPreset{From: "billing@ourapp.com", SubjectRegex: "Invoice"}
+
+	t.Run("fills unset flags", func(t *testing.T) {
+		cmd := presetTestCmd()
+		require.NoError(t, applyPreset(cmd.Flags(), preset))
+
+		from, _ := cmd.Flags().GetString("from")
+		subject, _ := cmd.Flags().GetString("subject-regex")
+		body, _ := cmd.Flags().GetString("body-regex")
+		assert.Equal(t, "billing@ourapp.com", from)
+		assert.Equal(t, "Invoice", subject)
+		assert.Empty(t, body)
+	})
+
+	t.Run("explicit flags override preset", func(t *testing.T) {
+		cmd := presetTestCmd()
+		require.NoError(t, cmd.Flags().Parse([]string{"--subject-regex", "Receipt"}))
+		require.NoError(t, applyPreset(cmd.Flags(), preset))
+
+		from, _ := cmd.Flags().GetString("from")
+		subject, _ := cmd.Flags().GetString("subject-regex")
+		assert.Equal(t, "billing@ourapp.com", from)
+		assert.Equal(t, "Receipt", subject)
+	})
+
+	t.Run("explicit empty value still wins", func(t *testing.T) {
+		cmd := presetTestCmd()
+		require.NoError(t, cmd.Flags().Parse([]string{"--from="}))
+		require.NoError(t, applyPreset(cmd.Flags(), preset))
+
+		from, _ := cmd.Flags().GetString("from")
+		assert.Empty(t, from)
+	})
+
+	t.Run("unsupported flag", func(t *testing.T) {
+		flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+		err := applyPreset(flags, config.FilterPreset{BodyRegex: "x"})
+		assert.EqualError(t, err, "--body-regex is not supported here")
+	})
+}
+
+func TestPresetInbox(t *testing.T) {
+	t.Run("no inbox flag", func(t *testing.T) {
+		assert.Empty(t, presetInbox(pflag.NewFlagSet("test", pflag.ContinueOnError)))
+	})
+
+	t.Run("string flag", func(t *testing.T) {
+		flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+		flags.String("inbox", "", "")
+		require.NoError(t, flags.Parse([]string{"--inbox", "signup"}))
+		assert.Equal(t, "signup", presetInbox(flags))
+	})
+
+	t.Run("slice flag uses first inbox", func(t *testing.T) {
+		flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+		flags.StringSlice("inbox", nil, "")
+		require.NoError(t, flags.Parse([]string{"--inbox", "one,two"}))
+		assert.Equal(t, "one", presetInbox(flags))
+	})
+
+	t.Run("all means active inbox", func(t *testing.T) {
+		flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+		flags.StringSlice("inbox", nil, "")
+		require.NoError(t, flags.Parse([]string{"--inbox", "all"}))
+		assert.Empty(t, presetInbox(flags))
+	})
+}
+
+func TestApplyFilterPreset(t *testing.T) {
+	t.Setenv("VSB_CONFIG_DIR", t.TempDir())
+	ks, err := config.LoadKeystore()
+	require.NoError(t, err)
+	require.NoError(t, ks.AddInbox(config.StoredInbox{
+		Email:     "billing@vsx.email",
+		ID:        "hash",
+		CreatedAt: time.Now(),
+		ExpiresAt: time.Now().Add(time.Hour),
+	}))
+	require.NoError(t, ks.SetFilterPreset("billing@vsx.email", "billing", config.FilterPreset{From: "billing@ourapp.com"}))
+
+	t.Run("without preset", func(t *testing.T) {
+		cmd := presetTestCmd()
+		require.NoError(t, ApplyFilterPreset(cmd))
+		from, _ := cmd.Flags().GetString("from")
+		assert.Empty(t, from)
+	})
+
+	t.Run("command without preset flag", func(t *testing.T) {
+		require.NoError(t, ApplyFilterPreset(&cobra.Command{Use: "other"}))
+	})
+
+	t.Run("expands preset", func(t *testing.T) {
+		cmd := presetTestCmd()
+		require.NoError(t, cmd.Flags().Parse([]string{"--preset", "billing"}))
+		require.NoError(t, ApplyFilterPreset(cmd))
+		from, _ := cmd.Flags().GetString("from")
+		assert.Equal(t, "billing@ourapp.com", from)
+	})
+
+	t.Run("unknown preset lists available", func(t *testing.T) {
+		cmd := presetTestCmd()
+		require.NoError(t, cmd.Flags().Parse([]string{"--preset", "nope"}))
+		err := ApplyFilterPreset(cmd)
+		assert.ErrorIs(t, err, config.ErrPresetNotFound)
+		assert.EqualError(t, err, `unknown preset "nope" for billing@vsx.email (available: billing)`)
+	})
+}
+
+func TestUnknownPresetError(t *testing.T) {
+	err := &UnknownPresetError{Name: "x", Inbox: "a@vsx.email"}
+	assert.EqualError(t, err, `unknown preset "x": a@vsx.email has no filter presets (add one with 'vsb inbox filter set')`)
+	assert.ErrorIs(t, err, config.ErrPresetNotFound)
+}
@@ -0,0 +1,53 @@
+package cliutil
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/dustin/go-humanize"
+)
+
+// progressInterval limits how often Progress redraws its line
+const progressInterval = 100 * time.Millisecond
+
+// Progress is an io.Writer that counts the bytes of a transfer written
+// through it and shows them on a single, redrawn line of w, e.g.
+// "report.pdf  12 MB / 40 MB (30%)". Use it with io.TeeReader or
+// io.MultiWriter; call Finish when the transfer ends.
+type Progress struct {
+	w     io.Writer
+	label string
+	total int64 // 0 if unknown
+	done  int64
+	drawn time.Time
+	now   func() time.Time // overridden in tests
+}
+
+// NewProgress creates a progress line for a transfer of total bytes. A
+// total of zero or less shows the byte count only.
+func NewProgress(w io.Writer, label string, total int64) *Progress {
+	return &Progress{w: w, label: label, total: max(total, 0), now: time.Now}
+}
+
+func (p *Progress) Write(b []byte) (int, error) {
+	p.done += int64(len(b))
+	if now := p.now(); now.Sub(p.drawn) >= progressInterval || p.done == p.total {
+		p.drawn = now
+		fmt.Fprintf(p.w, "\r\033[K%s", p.line())
+	}
+	return len(b), nil
+}
+
+// Finish clears the progress line.
+func (p *Progress) Finish() {
+	fmt.Fprint(p.w, "\r\033[K")
+}
+
+func (p *Progress) line() string {
+	done := humanize.Bytes(uint64(p.done))
+	if p.total == 0 {
+		return fmt.Sprintf("%s  %s", p.label, done)
+	}
+	return fmt.Sprintf("%s  %s / %s (%d%%)", p.label, done, humanize.Bytes(uint64(p.total)), p.done*100/p.total)
+}
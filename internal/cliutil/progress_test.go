@@ -0,0 +1,55 @@
+package cliutil
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProgress(t *testing.T) {
+	t.Run("shows bytes, total and percentage", func(t *testing.T) {
+		var buf bytes.Buffer
+		p := NewProgress(&buf, "report.pdf", 4000)
+
+		p.Write(make([]byte, 1000))
+		assert.Equal(t, "\r\033[Kreport.pdf  1.0 kB / 4.0 kB (25%)", buf.String())
+	})
+
+	t.Run("shows the byte count when the total is unknown", func(t *testing.T) {
+		var buf bytes.Buffer
+		p := NewProgress(&buf, "stream", 0)
+
+		p.Write(make([]byte, 2000))
+		assert.Equal(t, "\r\033[Kstream  2.0 kB", buf.String())
+	})
+
+	t.Run("throttles redraws but always draws completion", func(t *testing.T) {
+		var buf bytes.Buffer
+		now := time.Unix(1000, 0)
+		p := NewProgress(&buf, "file", 300)
+		p.now = func() time.Time { return now }
+
+		p.Write(make([]byte, 100))
+		p.Write(make([]byte, 100))
+		assert.Equal(t, 1, strings.Count(buf.String(), "\r"))
+
+		now = now.Add(progressInterval)
+		p.Write(make([]byte, 50))
+		assert.Contains(t, buf.String(), "(83%)")
+
+		p.Write(make([]byte, 50))
+		assert.True(t, strings.HasSuffix(buf.String(), "(100%)"))
+		assert.Equal(t, 3, strings.Count(buf.String(), "\r"))
+	})
+
+	t.Run("finish clears the line", func(t *testing.T) {
+		var buf bytes.Buffer
+		p := NewProgress(&buf, "file", 10)
+
+		p.Finish()
+		assert.Equal(t, "\r\033[K", buf.String())
+	})
+}
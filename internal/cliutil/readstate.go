@@ -0,0 +1,102 @@
+package cliutil
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/vaultsandbox/vsb-cli/internal/config"
+)
+
+// verbose enables diagnostic messages on stderr (set from --verbose).
+var verbose bool
+
+// verboseOut is where Verbosef writes; overridden in tests
+var verboseOut io.Writer = os.Stderr
+
+// SetVerbose toggles diagnostic messages.
+func SetVerbose(v bool) {
+	verbose = v
+}
+
+// IsVerbose reports whether --verbose is set.
+func IsVerbose() bool {
+	return verbose
+}
+
+// Verbosef prints a diagnostic message to stderr when --verbose is set.
+func Verbosef(format string, args ...interface{}) {
+	if verbose {
+		fmt.Fprintf(verboseOut, "vsb: "+format+"\n", args...)
+	}
+}
+
+// MarkRead records emails as read in the inbox's local read state. The read
+// state is a convenience, so failures never fail the command; they are only
+// reported with --verbose.
+func MarkRead(inboxEmail string, emailIDs ...string) {
+	if inboxEmail == "" || len(emailIDs) == 0 {
+		return
+	}
+	err := config.UpdateReadState(inboxEmail, func(rs *config.ReadState) {
+		rs.MarkRead(time.Now(), emailIDs...)
+	})
+	if err != nil {
+		Verbosef("could not update read state for %s: %v", inboxEmail, err)
+	}
+}
+
+// LoadReadState returns the inbox's local read state. If it can't be read,
+// every email is treated as unread and the error is reported with --verbose.
+func LoadReadState(inboxEmail string) *config.ReadState {
+	rs, err := config.LoadReadState(inboxEmail)
+	if err != nil {
+		Verbosef("could not read read state for %s: %v", inboxEmail, err)
+		return &config.ReadState{Read: map[string]time.Time{}}
+	}
+	return rs
+}
+
+// ReadTracker caches the read state of several inboxes for long-running
+// views such as the dashboard. It is safe for concurrent use.
+type ReadTracker struct {
+	mu     sync.Mutex
+	states map[string]*config.ReadState
+}
+
+// NewReadTracker returns a tracker that loads each inbox's state on first use.
+func NewReadTracker() *ReadTracker {
+	return &ReadTracker{states: make(map[string]*config.ReadState)}
+}
+
+func (t *ReadTracker) stateLocked(inboxEmail string) *config.ReadState {
+	rs, ok := t.states[inboxEmail]
+	if !ok {
+		rs = LoadReadState(inboxEmail)
+		t.states[inboxEmail] = rs
+	}
+	return rs
+}
+
+// IsRead reports whether the email is marked read.
+func (t *ReadTracker) IsRead(inboxEmail, emailID string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.stateLocked(inboxEmail).IsRead(emailID)
+}
+
+// MarkRead marks the email read, in memory even if the state file can't be
+// written.
+func (t *ReadTracker) MarkRead(inboxEmail, emailID string) {
+	t.mu.Lock()
+	rs := t.stateLocked(inboxEmail)
+	already := rs.IsRead(emailID)
+	rs.MarkRead(time.Now(), emailID)
+	t.mu.Unlock()
+
+	if !already {
+		MarkRead(inboxEmail, emailID)
+	}
+}
@@ -0,0 +1,58 @@
+package cliutil
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMarkRead(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("VSB_CONFIG_DIR", dir)
+
+	MarkRead("inbox@example.com", "one", "two")
+	rs := LoadReadState("inbox@example.com")
+	assert.True(t, rs.IsRead("one"))
+	assert.True(t, rs.IsRead("two"))
+	assert.False(t, rs.IsRead("three"))
+
+	t.Run("unwritable state is ignored", func(t *testing.T) {
+		var buf bytes.Buffer
+		oldOut := verboseOut
+		verboseOut = &buf
+		defer func() { verboseOut = oldOut }()
+
+		// A file where the readstate directory should be
+		blocked := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(blocked, "readstate"), nil, 0600))
+		t.Setenv("VSB_CONFIG_DIR", blocked)
+
+		MarkRead("inbox@example.com", "one")
+		assert.Empty(t, buf.String(), "silent without --verbose")
+
+		SetVerbose(true)
+		defer SetVerbose(false)
+		MarkRead("inbox@example.com", "one")
+		assert.Contains(t, buf.String(), "vsb: could not update read state for inbox@example.com")
+
+		assert.False(t, LoadReadState("inbox@example.com").IsRead("one"))
+	})
+}
+
+func TestReadTracker(t *testing.T) {
+	t.Setenv("VSB_CONFIG_DIR", t.TempDir())
+	MarkRead("a@example.com", "old")
+
+	tracker := NewReadTracker()
+	assert.True(t, tracker.IsRead("a@example.com", "old"))
+	assert.False(t, tracker.IsRead("a@example.com", "new"))
+	assert.False(t, tracker.IsRead("b@example.com", "old"))
+
+	tracker.MarkRead("a@example.com", "new")
+	assert.True(t, tracker.IsRead("a@example.com", "new"))
+	assert.True(t, LoadReadState("a@example.com").IsRead("new"), "persisted")
+}
@@ -0,0 +1,72 @@
+package cliutil
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/vaultsandbox/vsb-cli/internal/config"
+)
+
+// savesStateAnnotation marks commands that write to the config directory:
+// "true", or the name of the flag that makes them write
+const savesStateAnnotation = "vsb_saves_state"
+
+// rollbackTimeout bounds deleting an inbox that could not be saved
+const rollbackTimeout = 10 * time.Second
+
+// MarkSavesState declares that cmd writes to the config directory, e.g.
+// the keystore. CheckSavesState then makes it fail before it runs if the
+// directory is not writable, rather than after it has changed the server.
+func MarkSavesState(cmd *cobra.Command) {
+	if cmd.Annotations == nil {
+		cmd.Annotations = map[string]string{}
+	}
+	cmd.Annotations[savesStateAnnotation] = "true"
+}
+
+// MarkSavesStateWith is MarkSavesState for a command that only writes to
+// the config directory when the boolean flag is set, e.g. --repair.
+func MarkSavesStateWith(cmd *cobra.Command, flag string) {
+	if cmd.Annotations == nil {
+		cmd.Annotations = map[string]string{}
+	}
+	cmd.Annotations[savesStateAnnotation] = flag
+}
+
+// CheckSavesState checks that the config directory is writable when cmd
+// was marked with MarkSavesState, unless it is doing a --dry-run.
+func CheckSavesState(cmd *cobra.Command) error {
+	mark := cmd.Annotations[savesStateAnnotation]
+	if mark == "" {
+		return nil
+	}
+	if mark != "true" {
+		if flag := cmd.Flags().Lookup(mark); flag == nil || flag.Value.String() != "true" {
+			return nil
+		}
+	}
+	if dryRun := cmd.Flags().Lookup("dry-run"); dryRun != nil && dryRun.Value.String() == "true" {
+		return nil
+	}
+	return config.CheckDirWritable()
+}
+
+// InboxDeleter deletes inboxes on the server
+type InboxDeleter interface {
+	DeleteInbox(ctx context.Context, emailAddress string) error
+}
+
+// RollbackCreate deletes an inbox that was created on the server but could
+// not be saved, as nothing could read its emails without the keys. It
+// returns saveErr, saying whether the inbox was deleted.
+func RollbackCreate(ctx context.Context, client InboxDeleter, email string, saveErr error) error {
+	// Delete even if the command was interrupted
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), rollbackTimeout)
+	defer cancel()
+	if err := client.DeleteInbox(ctx, email); err != nil {
+		return fmt.Errorf("failed to save inbox: %w (%s was created on the server but could not be deleted: %v)", saveErr, email, err)
+	}
+	return fmt.Errorf("failed to save inbox: %w (deleted %s from the server again)", saveErr, email)
+}
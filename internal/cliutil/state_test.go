@@ -0,0 +1,89 @@
+package cliutil
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vaultsandbox/vsb-cli/internal/config"
+)
+
+func TestCheckSavesState(t *testing.T) {
+	// Below a regular file, so the directory can't be created
+	file := filepath.Join(t.TempDir(), "file")
+	require.NoError(t, os.WriteFile(file, nil, 0600))
+	t.Setenv("VSB_CONFIG_DIR", filepath.Join(file, "vsb"))
+
+	t.Run("unmarked command is not checked", func(t *testing.T) {
+		assert.NoError(t, CheckSavesState(&cobra.Command{}))
+	})
+
+	t.Run("marked command fails", func(t *testing.T) {
+		cmd := &cobra.Command{}
+		MarkSavesState(cmd)
+		assert.ErrorIs(t, CheckSavesState(cmd), config.ErrConfigDirNotWritable)
+	})
+
+	t.Run("dry run is not checked", func(t *testing.T) {
+		cmd := &cobra.Command{}
+		MarkSavesState(cmd)
+		cmd.Flags().Bool("dry-run", false, "")
+		require.NoError(t, cmd.Flags().Set("dry-run", "true"))
+		assert.NoError(t, CheckSavesState(cmd))
+	})
+
+	t.Run("command marked with a flag is only checked with it", func(t *testing.T) {
+		cmd := &cobra.Command{}
+		MarkSavesStateWith(cmd, "repair")
+		cmd.Flags().Bool("repair", false, "")
+		assert.NoError(t, CheckSavesState(cmd))
+
+		require.NoError(t, cmd.Flags().Set("repair", "true"))
+		assert.ErrorIs(t, CheckSavesState(cmd), config.ErrConfigDirNotWritable)
+	})
+}
+
+type fakeDeleter struct {
+	deleted []string
+	ctxErr  error // of the context the delete ran with
+	err     error
+}
+
+func (d *fakeDeleter) DeleteInbox(ctx context.Context, emailAddress string) error {
+	d.deleted = append(d.deleted, emailAddress)
+	d.ctxErr = ctx.Err()
+	return d.err
+}
+
+func TestRollbackCreate(t *testing.T) {
+	saveErr := errors.New("disk full")
+
+	t.Run("deletes the inbox", func(t *testing.T) {
+		d := &fakeDeleter{}
+		err := RollbackCreate(context.Background(), d, "a@example.com", saveErr)
+		assert.ErrorIs(t, err, saveErr)
+		assert.EqualError(t, err, "failed to save inbox: disk full (deleted a@example.com from the server again)")
+		assert.Equal(t, []string{"a@example.com"}, d.deleted)
+	})
+
+	t.Run("even when cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		d := &fakeDeleter{}
+		RollbackCreate(ctx, d, "a@example.com", saveErr)
+		assert.Equal(t, []string{"a@example.com"}, d.deleted)
+		assert.NoError(t, d.ctxErr)
+	})
+
+	t.Run("reports a failed delete", func(t *testing.T) {
+		d := &fakeDeleter{err: errors.New("network down")}
+		err := RollbackCreate(context.Background(), d, "a@example.com", saveErr)
+		assert.ErrorIs(t, err, saveErr)
+		assert.ErrorContains(t, err, "a@example.com was created on the server but could not be deleted: network down")
+	})
+}
@@ -0,0 +1,185 @@
+package cliutil
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/vaultsandbox/vsb-cli/internal/styles"
+	"github.com/vaultsandbox/vsb-cli/internal/table"
+	"golang.org/x/term"
+)
+
+// TableWriter renders rows as a table whose columns are as wide as their
+// widest cell. On a terminal it bolds the header, styles marked rows and
+// truncates the columns given a maximum width so rows fit the table width,
+// using the same layout as package table; elsewhere (or with NO_COLOR or
+// --color never for the styling) it writes plain aligned text so piped
+// output stays clean.
+type TableWriter struct {
+	w        io.Writer
+	headers  []string
+	maxWidth []int
+	rows     []tableRow
+
+	// Color enables header and row styles. Truncate applies SetMaxWidth
+	// and fits rows to Width. Both default to whether w is a terminal;
+	// Color follows --color and NO_COLOR as decided by styles.UseColor.
+	// Width defaults to table.Width.
+	Color    bool
+	Truncate bool
+	Width    int
+}
+
+type tableRow struct {
+	cells  []string
+	style  lipgloss.Style
+	styled bool
+}
+
+// NewTableWriter creates a table with the given column headers.
+func NewTableWriter(w io.Writer, headers ...string) *TableWriter {
+	tty := isTerminal(w)
+	return &TableWriter{
+		w:        w,
+		headers:  headers,
+		maxWidth: make([]int, len(headers)),
+		Color:    styles.UseColor(tty),
+		Truncate: tty,
+		Width:    table.Width(),
+	}
+}
+
+// isTerminal reports whether w is a terminal
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	return ok && term.IsTerminal(int(f.Fd()))
+}
+
+// StdoutTableWidth returns the width to fit a TableWriter on stdout to:
+// table.Width on a terminal, and 0 to keep cells whole elsewhere.
+func StdoutTableWidth() int {
+	if !isTerminal(os.Stdout) {
+		return 0
+	}
+	return table.Width()
+}
+
+// SetMaxWidth limits column col to width characters when truncating. Such
+// columns also shrink, like flexible table columns, when a row would be
+// wider than Width.
+func (t *TableWriter) SetMaxWidth(col, width int) *TableWriter {
+	t.maxWidth[col] = width
+	return t
+}
+
+// AddRow adds a row of cells, one per header.
+func (t *TableWriter) AddRow(cells ...string) {
+	t.rows = append(t.rows, tableRow{cells: cells})
+}
+
+// AddStyledRow adds a row rendered with style when colors are enabled.
+func (t *TableWriter) AddStyledRow(style lipgloss.Style, cells ...string) {
+	t.rows = append(t.rows, tableRow{cells: cells, style: style, styled: true})
+}
+
+// Render writes the header and rows.
+func (t *TableWriter) Render() error {
+	rows := make([][]string, len(t.rows))
+	for i, row := range t.rows {
+		rows[i] = t.pad(row.cells)
+	}
+
+	widths := t.naturalWidths(rows)
+	if t.Truncate {
+		widths = t.layout(widths)
+		for _, cells := range rows {
+			for i := range cells {
+				if t.maxWidth[i] > 0 {
+					cells[i] = table.Truncate(cells[i], widths[i])
+				}
+			}
+		}
+		// Measure again: Layout can give a column more than its widest cell
+		widths = t.naturalWidths(rows)
+	}
+
+	bw := bufio.NewWriter(t.w)
+	headerStyle := lipgloss.NewStyle()
+	if t.Color {
+		headerStyle = styles.PrimaryBoldStyle
+	}
+	t.writeLine(bw, t.headers, widths, headerStyle)
+	for i, row := range t.rows {
+		style := lipgloss.NewStyle()
+		if t.Color && row.styled {
+			style = row.style
+		}
+		t.writeLine(bw, rows[i], widths, style)
+	}
+	return bw.Flush()
+}
+
+// pad returns a row with one cell per header
+func (t *TableWriter) pad(cells []string) []string {
+	padded := make([]string, len(t.headers))
+	copy(padded, cells)
+	return padded
+}
+
+// naturalWidths returns the width of the widest cell or header per column
+func (t *TableWriter) naturalWidths(rows [][]string) []int {
+	widths := make([]int, len(t.headers))
+	for i, h := range t.headers {
+		widths[i] = lipgloss.Width(h)
+	}
+	for _, cells := range rows {
+		for i, cell := range cells {
+			widths[i] = max(widths[i], lipgloss.Width(cell))
+		}
+	}
+	return widths
+}
+
+// layout returns the column widths for truncating: columns with a maximum
+// width are flexible with a preferred width of at most that maximum, the
+// others keep their natural width
+func (t *TableWriter) layout(natural []int) []int {
+	columns := make([]table.Column, len(t.headers))
+	flex := false
+	for i, h := range t.headers {
+		columns[i] = table.Column{Header: h, Width: natural[i]}
+		if t.maxWidth[i] > 0 {
+			columns[i].Width = min(natural[i], t.maxWidth[i])
+			columns[i].Flex = true
+			flex = true
+		}
+	}
+	if !flex {
+		return natural
+	}
+	return table.Layout(columns, t.Width)
+}
+
+// writeLine writes cells padded to widths, two spaces apart, without
+// trailing whitespace. Cells are padded before styling to keep alignment.
+func (t *TableWriter) writeLine(w io.Writer, cells []string, widths []int, style lipgloss.Style) {
+	last := len(cells) - 1
+	for last > 0 && cells[last] == "" {
+		last--
+	}
+	var b strings.Builder
+	for i := 0; i <= last; i++ {
+		cell := cells[i]
+		if i < last {
+			cell += strings.Repeat(" ", widths[i]-lipgloss.Width(cell))
+		}
+		if i > 0 {
+			b.WriteString("  ")
+		}
+		b.WriteString(style.Render(cell))
+	}
+	io.WriteString(w, b.String()+"\n")
+}
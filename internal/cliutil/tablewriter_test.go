@@ -0,0 +1,68 @@
+package cliutil
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vaultsandbox/vsb-cli/internal/styles"
+)
+
+func TestTableWriter(t *testing.T) {
+	t.Run("aligns columns as plain text off a terminal", func(t *testing.T) {
+		var buf bytes.Buffer
+		tw := NewTableWriter(&buf, "EMAIL", "LABEL", "ACTIVE")
+		assert.False(t, tw.Color)
+		assert.False(t, tw.Truncate)
+
+		tw.SetMaxWidth(0, 5)
+		tw.AddRow("long-address@vsx.email", "ci", "yes")
+		tw.AddStyledRow(styles.ActiveStyle, "b@vsx.email", "", "")
+		require.NoError(t, tw.Render())
+
+		assert.Equal(t, ""+
+			"EMAIL                   LABEL  ACTIVE\n"+
+			"long-address@vsx.email  ci     yes\n"+
+			"b@vsx.email\n", buf.String())
+	})
+
+	t.Run("truncates to max widths", func(t *testing.T) {
+		var buf bytes.Buffer
+		tw := NewTableWriter(&buf, "EMAIL", "EXPIRES").SetMaxWidth(0, 8)
+		tw.Truncate = true
+		tw.AddRow("long-address@vsx.email", "1h")
+		tw.AddRow("a@b", "2h")
+		require.NoError(t, tw.Render())
+
+		assert.Equal(t, ""+
+			"EMAIL     EXPIRES\n"+
+			"long-ad…  1h\n"+
+			"a@b       2h\n", buf.String())
+	})
+
+	t.Run("shrinks max width columns to fit the width", func(t *testing.T) {
+		var buf bytes.Buffer
+		tw := NewTableWriter(&buf, "ID", "SUBJECT", "FROM").SetMaxWidth(1, 30).SetMaxWidth(2, 30)
+		tw.Truncate = true
+		tw.Width = 30
+		tw.AddRow("a1", "A subject that is rather long", "someone@example.com")
+		tw.AddRow("b2", "Short", "x@y")
+		require.NoError(t, tw.Render())
+
+		assert.Equal(t, ""+
+			"ID  SUBJECT          FROM\n"+
+			"a1  A subject that…  someone@…\n"+
+			"b2  Short            x@y\n", buf.String())
+	})
+
+	t.Run("short rows are padded", func(t *testing.T) {
+		var buf bytes.Buffer
+		tw := NewTableWriter(&buf, "A", "B", "C")
+		tw.AddRow("x", "", "z")
+		tw.AddRow("only")
+		require.NoError(t, tw.Render())
+
+		assert.Equal(t, "A     B  C\nx        z\nonly\n", buf.String())
+	})
+}
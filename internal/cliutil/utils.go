@@ -1,6 +1,7 @@
 package cliutil
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"regexp"
@@ -36,17 +37,35 @@ func SubjectOrDefault(subject string) string {
 	return subject
 }
 
-// GetOutput returns the output format with priority: flag > env > config > default.
-func GetOutput(cmd *cobra.Command) string {
-	if flag := cmd.Flag("output"); flag != nil && flag.Changed {
-		return flag.Value.String()
+// CommandContext returns the command's context, which the root command
+// cancels on SIGINT. Falls back to context.Background() when the command
+// was not started through Execute (e.g. in tests).
+func CommandContext(cmd *cobra.Command) context.Context {
+	if ctx := cmd.Context(); ctx != nil {
+		return ctx
 	}
-	return config.GetDefaultOutput()
+	return context.Background()
 }
 
-// OutputJSON marshals v to indented JSON and prints it to stdout.
+// jsonCompact selects single-line JSON output (set from --json-compact).
+var jsonCompact bool
+
+// SetJSONCompact toggles between compact and indented JSON output.
+func SetJSONCompact(compact bool) {
+	jsonCompact = compact
+}
+
+// MarshalJSON encodes v as indented JSON, or compact JSON if enabled.
+func MarshalJSON(v interface{}) ([]byte, error) {
+	if jsonCompact {
+		return json.Marshal(v)
+	}
+	return json.MarshalIndent(v, "", "  ")
+}
+
+// OutputJSON marshals v to JSON and prints it to stdout.
 func OutputJSON(v interface{}) error {
-	data, err := json.MarshalIndent(v, "", "  ")
+	data, err := MarshalJSON(v)
 	if err != nil {
 		return err
 	}
@@ -80,6 +99,28 @@ func FormatDuration(d time.Duration) string {
 	return fmt.Sprintf("%dd", int(d.Hours()/24))
 }
 
+// FormatCountdown formats a remaining duration down to the second, e.g.
+// "3h12m5s" or "1d2h0m0s". Negative durations are shown as "0s".
+func FormatCountdown(d time.Duration) string {
+	if d < time.Second {
+		return "0s"
+	}
+	secs := int(d / time.Second)
+	days, secs := secs/86400, secs%86400
+	hours, secs := secs/3600, secs%3600
+	mins, secs := secs/60, secs%60
+
+	switch {
+	case days > 0:
+		return fmt.Sprintf("%dd%dh%dm%ds", days, hours, mins, secs)
+	case hours > 0:
+		return fmt.Sprintf("%dh%dm%ds", hours, mins, secs)
+	case mins > 0:
+		return fmt.Sprintf("%dm%ds", mins, secs)
+	}
+	return fmt.Sprintf("%ds", secs)
+}
+
 // FormatRelativeTime formats a time as a human-readable relative string (e.g., "just now", "5m ago").
 func FormatRelativeTime(t time.Time) string {
 	diff := time.Since(t)
@@ -116,9 +157,10 @@ func ExtractTLSCipher(received string) string {
 	return ""
 }
 
-// IsExpired checks if a time is in the past.
+// IsExpired checks if a time is in the past by the server's clock
+// (config.ServerNow).
 func IsExpired(expiresAt time.Time) bool {
-	return expiresAt.Before(time.Now())
+	return expiresAt.Before(config.ServerNow())
 }
 
 // FormatExpiry returns remaining time as a formatted string, or "expired" if past.
@@ -126,6 +168,6 @@ func FormatExpiry(expiresAt time.Time) string {
 	if IsExpired(expiresAt) {
 		return "expired"
 	}
-	remaining := time.Until(expiresAt).Round(time.Minute)
+	remaining := expiresAt.Sub(config.ServerNow()).Round(time.Minute)
 	return FormatDuration(remaining)
 }
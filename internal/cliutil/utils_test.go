@@ -32,6 +32,25 @@ func TestFormatDuration(t *testing.T) {
 	}
 }
 
+func TestFormatCountdown(t *testing.T) {
+	tests := []struct {
+		input time.Duration
+		want  string
+	}{
+		{3*time.Hour + 12*time.Minute + 5*time.Second, "3h12m5s"},
+		{26 * time.Hour, "1d2h0m0s"},
+		{90 * time.Second, "1m30s"},
+		{59*time.Second + 900*time.Millisecond, "59s"},
+		{500 * time.Millisecond, "0s"},
+		{-time.Minute, "0s"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			assert.Equal(t, tt.want, FormatCountdown(tt.input))
+		})
+	}
+}
+
 func TestFormatRelativeTime(t *testing.T) {
 	now := time.Now()
 	tests := []struct {
@@ -138,6 +157,25 @@ func TestOutputJSON(t *testing.T) {
 	})
 }
 
+func TestMarshalJSON(t *testing.T) {
+	data := map[string]interface{}{"key": "value", "list": []int{1, 2}}
+
+	t.Run("indented by default", func(t *testing.T) {
+		out, err := MarshalJSON(data)
+		assert.NoError(t, err)
+		assert.Equal(t, "{\n  \"key\": \"value\",\n  \"list\": [\n    1,\n    2\n  ]\n}", string(out))
+	})
+
+	t.Run("compact when enabled", func(t *testing.T) {
+		SetJSONCompact(true)
+		defer SetJSONCompact(false)
+
+		out, err := MarshalJSON(data)
+		assert.NoError(t, err)
+		assert.Equal(t, `{"key":"value","list":[1,2]}`, string(out))
+	})
+}
+
 func TestSubjectOrDefault(t *testing.T) {
 	t.Run("empty string returns NoSubject", func(t *testing.T) {
 		got := SubjectOrDefault("")
@@ -0,0 +1,24 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// GetAliases returns the user-defined command aliases from the config file.
+func GetAliases() map[string]string {
+	return current.Aliases
+}
+
+// ValidateAlias checks that name can be typed as a command and that
+// expansion names something to run. It does not check for clashes with
+// built-in commands, which the CLI knows about.
+func ValidateAlias(name, expansion string) error {
+	if name == "" || strings.HasPrefix(name, "-") || strings.ContainsAny(name, " \t\n=.") {
+		return fmt.Errorf("invalid alias name %q: use a single word without dots that does not start with -", name)
+	}
+	if len(strings.Fields(expansion)) == 0 {
+		return fmt.Errorf("alias %s expands to nothing", name)
+	}
+	return nil
+}
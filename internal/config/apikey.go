@@ -0,0 +1,97 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// apiKeyFileOverride is the --api-key-file flag value, which takes priority
+// over every other API key source
+var apiKeyFileOverride string
+
+// apiKeyCommandTimeout bounds api-key-command, which may wait on a secret
+// manager (overridden in tests)
+var apiKeyCommandTimeout = 30 * time.Second
+
+// commandKey caches the output of api-key-command, so the command runs at
+// most once per process
+var commandKey struct {
+	command string
+	key     string
+}
+
+// SetAPIKeyFileOverride sets the file given with --api-key-file. An empty
+// path clears the override.
+func SetAPIKeyFileOverride(path string) {
+	apiKeyFileOverride = path
+}
+
+// GetAPIKeyCommand returns the command whose output is the API key, with
+// priority: env > config file. An empty string means none.
+func GetAPIKeyCommand() string {
+	return getConfigValue("API_KEY_COMMAND", current.APIKeyCommand, "")
+}
+
+// readAPIKeyFile reads the API key from path, trimming trailing whitespace.
+func readAPIKeyFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read API key file: %w", err)
+	}
+	key := trimAPIKey(string(data))
+	if key == "" {
+		return "", fmt.Errorf("API key file %s is empty", path)
+	}
+	return key, nil
+}
+
+// runAPIKeyCommand runs command through the shell and returns its stdout,
+// trimming trailing whitespace. stderr is passed through so secret manager
+// prompts and errors stay visible.
+func runAPIKeyCommand(command string) (string, error) {
+	if commandKey.command == command && commandKey.key != "" {
+		return commandKey.key, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), apiKeyCommandTimeout)
+	defer cancel()
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", command)
+	}
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = os.Stderr
+	// Don't wait for children of the shell that keep stdout open after a
+	// timeout kills it
+	cmd.WaitDelay = time.Second
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return "", fmt.Errorf("api-key-command timed out after %s", apiKeyCommandTimeout)
+		}
+		return "", fmt.Errorf("api-key-command failed: %w", err)
+	}
+
+	key := trimAPIKey(stdout.String())
+	if key == "" {
+		return "", fmt.Errorf("api-key-command printed no API key")
+	}
+	commandKey.command, commandKey.key = command, key
+	return key, nil
+}
+
+// trimAPIKey drops the trailing newline and whitespace secret managers and
+// editors leave after a key
+func trimAPIKey(s string) string {
+	return strings.TrimRightFunc(s, unicode.IsSpace)
+}
@@ -0,0 +1,168 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// resetAPIKeySources clears the file override, the command cache and the
+// loaded config for one test.
+func resetAPIKeySources(t *testing.T) {
+	originalCurrent := current
+	t.Cleanup(func() {
+		current = originalCurrent
+		apiKeyFileOverride = ""
+		commandKey.command, commandKey.key = "", ""
+	})
+	t.Setenv("VSB_API_KEY", "")
+	t.Setenv("VSB_API_KEY_COMMAND", "")
+	current = Config{}
+}
+
+func TestAPIKeyFile(t *testing.T) {
+	dir := t.TempDir()
+
+	t.Run("trims trailing whitespace", func(t *testing.T) {
+		resetAPIKeySources(t)
+		path := filepath.Join(dir, "key")
+		require.NoError(t, os.WriteFile(path, []byte("file-key \r\n\n"), 0600))
+		SetAPIKeyFileOverride(path)
+
+		key, err := ResolveAPIKey()
+		require.NoError(t, err)
+		assert.Equal(t, "file-key", key)
+	})
+
+	t.Run("takes priority over env and config", func(t *testing.T) {
+		resetAPIKeySources(t)
+		path := filepath.Join(dir, "key")
+		require.NoError(t, os.WriteFile(path, []byte("file-key\n"), 0600))
+		t.Setenv("VSB_API_KEY", "env-key")
+		current = Config{APIKey: "config-key", APIKeyCommand: "echo command-key"}
+		SetAPIKeyFileOverride(path)
+
+		key, err := ResolveAPIKey()
+		require.NoError(t, err)
+		assert.Equal(t, "file-key", key)
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		resetAPIKeySources(t)
+		current = Config{APIKey: "config-key"}
+		SetAPIKeyFileOverride(filepath.Join(dir, "missing"))
+
+		_, err := ResolveAPIKey()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to read API key file")
+		assert.ErrorIs(t, err, os.ErrNotExist)
+	})
+
+	t.Run("empty file", func(t *testing.T) {
+		resetAPIKeySources(t)
+		path := filepath.Join(dir, "empty")
+		require.NoError(t, os.WriteFile(path, []byte("\n"), 0600))
+		SetAPIKeyFileOverride(path)
+
+		_, err := ResolveAPIKey()
+		assert.EqualError(t, err, "API key file "+path+" is empty")
+	})
+}
+
+func TestAPIKeyCommand(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh syntax")
+	}
+
+	t.Run("output is the key", func(t *testing.T) {
+		resetAPIKeySources(t)
+		current = Config{APIKey: "config-key", APIKeyCommand: "printf 'command-key\\n\\n'"}
+
+		key, err := ResolveAPIKey()
+		require.NoError(t, err)
+		assert.Equal(t, "command-key", key)
+		assert.Equal(t, "printf 'command-key\\n\\n'", GetAPIKeyCommand())
+	})
+
+	t.Run("env key takes priority", func(t *testing.T) {
+		resetAPIKeySources(t)
+		t.Setenv("VSB_API_KEY", "env-key")
+		current = Config{APIKeyCommand: "exit 1"}
+
+		key, err := ResolveAPIKey()
+		require.NoError(t, err)
+		assert.Equal(t, "env-key", key)
+	})
+
+	t.Run("env command overrides config", func(t *testing.T) {
+		resetAPIKeySources(t)
+		t.Setenv("VSB_API_KEY_COMMAND", "echo env-command-key")
+		current = Config{APIKeyCommand: "echo config-command-key"}
+
+		key, err := ResolveAPIKey()
+		require.NoError(t, err)
+		assert.Equal(t, "env-command-key", key)
+	})
+
+	t.Run("runs once per process", func(t *testing.T) {
+		resetAPIKeySources(t)
+		counter := filepath.Join(t.TempDir(), "runs")
+		current = Config{APIKeyCommand: "echo x >> " + counter + "; echo cached-key"}
+
+		for range 3 {
+			key, err := ResolveAPIKey()
+			require.NoError(t, err)
+			assert.Equal(t, "cached-key", key)
+		}
+		data, err := os.ReadFile(counter)
+		require.NoError(t, err)
+		assert.Equal(t, "x\n", string(data))
+	})
+
+	t.Run("failing command", func(t *testing.T) {
+		resetAPIKeySources(t)
+		current = Config{APIKey: "config-key", APIKeyCommand: "exit 3"}
+
+		_, err := ResolveAPIKey()
+		assert.EqualError(t, err, "api-key-command failed: exit status 3")
+	})
+
+	t.Run("no output", func(t *testing.T) {
+		resetAPIKeySources(t)
+		current = Config{APIKeyCommand: "true"}
+
+		_, err := ResolveAPIKey()
+		assert.EqualError(t, err, "api-key-command printed no API key")
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		resetAPIKeySources(t)
+		original := apiKeyCommandTimeout
+		apiKeyCommandTimeout = 50 * time.Millisecond
+		t.Cleanup(func() { apiKeyCommandTimeout = original })
+		current = Config{APIKeyCommand: "exec sleep 5"}
+
+		_, err := ResolveAPIKey()
+		assert.EqualError(t, err, "api-key-command timed out after 50ms")
+	})
+
+	t.Run("timeout with a child that outlives the shell", func(t *testing.T) {
+		resetAPIKeySources(t)
+		original := apiKeyCommandTimeout
+		apiKeyCommandTimeout = 50 * time.Millisecond
+		t.Cleanup(func() { apiKeyCommandTimeout = original })
+		// The shell forks sleep, which keeps stdout open after the shell
+		// is killed
+		current = Config{APIKeyCommand: "sleep 5; echo key"}
+
+		started := time.Now()
+		_, err := ResolveAPIKey()
+		assert.EqualError(t, err, "api-key-command timed out after 50ms")
+		assert.Less(t, time.Since(started), 3*time.Second)
+	})
+}
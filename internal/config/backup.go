@@ -0,0 +1,309 @@
+package config
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// KeystoreBackupFormat is the version of the keystore backup file format
+const KeystoreBackupFormat = 1
+
+// Encryption parameters for password-protected backups
+const (
+	backupCipher     = "aes-256-gcm"
+	backupKDF        = "pbkdf2-sha256"
+	backupIterations = 600000
+	backupSaltSize   = 16
+)
+
+var (
+	// ErrBackupCorrupted is returned when a backup's checksum is missing or
+	// does not match its contents.
+	ErrBackupCorrupted = errors.New("keystore backup corrupted or modified")
+	// ErrBackupPassword is returned when an encrypted backup cannot be
+	// decrypted with the given password.
+	ErrBackupPassword = errors.New("wrong password for encrypted keystore backup")
+	// ErrKeystoreExists is returned when a restore would overwrite the
+	// keystore without force.
+	ErrKeystoreExists = errors.New("keystore already exists")
+)
+
+// KeystoreBackup is a copy of the whole keystore file. The keystore is kept
+// as plain JSON, or encrypted with a key derived from a password, in which
+// case Ciphertext replaces Keystore.
+type KeystoreBackup struct {
+	Format     int               `json:"format"`
+	CreatedAt  time.Time         `json:"createdAt"`
+	VSBVersion string            `json:"vsbVersion"`
+	Inboxes    int               `json:"inboxes"`
+	Keystore   json.RawMessage   `json:"keystore,omitempty"`
+	Encryption *BackupEncryption `json:"encryption,omitempty"`
+	Ciphertext string            `json:"ciphertext,omitempty"`
+	Checksum   string            `json:"checksum"`
+}
+
+// BackupEncryption describes how the keystore of an encrypted backup was
+// encrypted. Salt and Nonce are base64.
+type BackupEncryption struct {
+	Cipher     string `json:"cipher"`
+	KDF        string `json:"kdf"`
+	Iterations int    `json:"iterations"`
+	Salt       string `json:"salt"`
+	Nonce      string `json:"nonce"`
+}
+
+// Encrypted reports whether the keystore in the backup is encrypted.
+func (b *KeystoreBackup) Encrypted() bool {
+	return b.Encryption != nil
+}
+
+// NewKeystoreBackup backs up the keystore file, upgraded to the current
+// schema. A non-empty password encrypts the keystore.
+func NewKeystoreBackup(password string) (*KeystoreBackup, error) {
+	path, err := keystorePath()
+	if err != nil {
+		return nil, err
+	}
+	ks := &Keystore{path: path}
+	unlock, err := ks.lock()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	unlock()
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("no keystore to back up at %s", path)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	data, _, _, err = upgradeKeystore(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	inboxes, err := countKeystoreInboxes(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, data); err != nil {
+		return nil, err
+	}
+
+	b := &KeystoreBackup{
+		Format:     KeystoreBackupFormat,
+		CreatedAt:  time.Now().UTC().Truncate(time.Second),
+		VSBVersion: version,
+		Inboxes:    inboxes,
+		Keystore:   compact.Bytes(),
+	}
+	if password != "" {
+		if err := b.encrypt(password); err != nil {
+			return nil, err
+		}
+	}
+	if err := b.Seal(); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// ParseKeystoreBackup decodes a backup file and verifies its checksum.
+func ParseKeystoreBackup(data []byte) (*KeystoreBackup, error) {
+	var b KeystoreBackup
+	if err := json.Unmarshal(data, &b); err != nil {
+		return nil, fmt.Errorf("invalid keystore backup: %w", err)
+	}
+	if b.Format == 0 {
+		return nil, fmt.Errorf("invalid keystore backup: no format version")
+	}
+	if b.Format > KeystoreBackupFormat {
+		return nil, fmt.Errorf("keystore backup format %d is newer than this vsb supports (%d); upgrade vsb", b.Format, KeystoreBackupFormat)
+	}
+	if err := b.VerifyChecksum(); err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+// canonicalPayload is the serialization covered by the checksum: the
+// backup with the checksum cleared.
+func (b *KeystoreBackup) canonicalPayload() ([]byte, error) {
+	payload := *b
+	payload.Checksum = ""
+	return json.Marshal(payload)
+}
+
+// Seal sets the checksum over the canonical payload.
+func (b *KeystoreBackup) Seal() error {
+	payload, err := b.canonicalPayload()
+	if err != nil {
+		return err
+	}
+	sum := sha256.Sum256(payload)
+	b.Checksum = checksumPrefix + hex.EncodeToString(sum[:])
+	return nil
+}
+
+// VerifyChecksum checks the checksum, which unlike inbox exports every
+// backup must have.
+func (b *KeystoreBackup) VerifyChecksum() error {
+	if !strings.HasPrefix(b.Checksum, checksumPrefix) {
+		return fmt.Errorf("%w: missing or unsupported checksum %q", ErrBackupCorrupted, b.Checksum)
+	}
+	want := *b
+	if err := want.Seal(); err != nil {
+		return err
+	}
+	if want.Checksum != b.Checksum {
+		return ErrBackupCorrupted
+	}
+	return nil
+}
+
+// Open returns the keystore JSON held by the backup, decrypting it with
+// password when the backup is encrypted.
+func (b *KeystoreBackup) Open(password string) ([]byte, error) {
+	if !b.Encrypted() {
+		if len(b.Keystore) == 0 {
+			return nil, fmt.Errorf("%w: no keystore in backup", ErrBackupCorrupted)
+		}
+		return b.Keystore, nil
+	}
+
+	e := b.Encryption
+	if e.Cipher != backupCipher || e.KDF != backupKDF {
+		return nil, fmt.Errorf("unsupported backup encryption %s with %s", e.Cipher, e.KDF)
+	}
+	salt, err := base64.StdEncoding.DecodeString(e.Salt)
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed salt", ErrBackupCorrupted)
+	}
+	nonce, err := base64.StdEncoding.DecodeString(e.Nonce)
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed nonce", ErrBackupCorrupted)
+	}
+	ciphertext, err := base64.StdEncoding.DecodeString(b.Ciphertext)
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed ciphertext", ErrBackupCorrupted)
+	}
+
+	gcm, err := backupGCM(password, salt, e.Iterations)
+	if err != nil {
+		return nil, err
+	}
+	if len(nonce) != gcm.NonceSize() {
+		return nil, fmt.Errorf("%w: malformed nonce", ErrBackupCorrupted)
+	}
+	data, err := gcm.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, ErrBackupPassword
+	}
+	return data, nil
+}
+
+// encrypt replaces the plain keystore with its encryption under password
+func (b *KeystoreBackup) encrypt(password string) error {
+	salt := make([]byte, backupSaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return err
+	}
+	gcm, err := backupGCM(password, salt, backupIterations)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+
+	b.Encryption = &BackupEncryption{
+		Cipher:     backupCipher,
+		KDF:        backupKDF,
+		Iterations: backupIterations,
+		Salt:       base64.StdEncoding.EncodeToString(salt),
+		Nonce:      base64.StdEncoding.EncodeToString(nonce),
+	}
+	b.Ciphertext = base64.StdEncoding.EncodeToString(gcm.Seal(nil, nonce, b.Keystore, nil))
+	b.Keystore = nil
+	return nil
+}
+
+// backupGCM derives the AES-256 key for password and returns its GCM mode
+func backupGCM(password string, salt []byte, iterations int) (cipher.AEAD, error) {
+	if iterations <= 0 {
+		return nil, fmt.Errorf("%w: invalid iteration count %d", ErrBackupCorrupted, iterations)
+	}
+	key, err := pbkdf2.Key(sha256.New, password, salt, iterations, 32)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// RestoreKeystore replaces the keystore file with data, the keystore JSON
+// from a backup, returning the path written and the number of inboxes.
+// An existing keystore is only replaced when force is set.
+func RestoreKeystore(data []byte, force bool) (string, int, error) {
+	path, err := keystorePath()
+	if err != nil {
+		return "", 0, err
+	}
+
+	// Backups may come from an older vsb; a newer one is refused here
+	data, _, _, err = upgradeKeystore(data)
+	if err != nil {
+		return "", 0, fmt.Errorf("invalid keystore in backup: %w", err)
+	}
+	inboxes, err := countKeystoreInboxes(data)
+	if err != nil {
+		return "", 0, fmt.Errorf("invalid keystore in backup: %w", err)
+	}
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, data, "", "  "); err != nil {
+		return "", 0, err
+	}
+
+	ks := &Keystore{path: path}
+	unlock, err := ks.lock()
+	if err != nil {
+		return "", 0, err
+	}
+	defer unlock()
+
+	if _, err := os.Stat(path); err == nil && !force {
+		return "", 0, fmt.Errorf("%w: %s (use --force to overwrite)", ErrKeystoreExists, path)
+	}
+	if err := WriteFileAtomic(path, indented.Bytes(), 0600); err != nil {
+		return "", 0, err
+	}
+	return path, inboxes, nil
+}
+
+// countKeystoreInboxes checks that data is a keystore and counts its inboxes
+func countKeystoreInboxes(data []byte) (int, error) {
+	var ks struct {
+		Inboxes []StoredInbox `json:"inboxes"`
+	}
+	if err := json.Unmarshal(data, &ks); err != nil {
+		return 0, err
+	}
+	return len(ks.Inboxes), nil
+}
@@ -0,0 +1,179 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const backupKeystore = `{
+  "version": 2,
+  "inboxes": [
+    {"email": "a@example.com", "id": "h1", "label": "ci", "expiresAt": "2099-01-01T00:00:00Z", "keys": {"kemPrivate": "priv"}},
+    {"email": "b@example.com", "id": "h2", "expiresAt": "2099-01-01T00:00:00Z", "keys": {}}
+  ],
+  "active_inbox": "b@example.com"
+}`
+
+// setupBackupKeystore writes backupKeystore to a temporary config dir and
+// returns the keystore path
+func setupBackupKeystore(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("VSB_CONFIG_DIR", dir)
+	path := filepath.Join(dir, "keystore.json")
+	require.NoError(t, os.WriteFile(path, []byte(backupKeystore), 0600))
+	return path
+}
+
+// roundTripBackup encodes and parses b the way backup files are written
+func roundTripBackup(t *testing.T, b *KeystoreBackup) []byte {
+	t.Helper()
+	data, err := json.MarshalIndent(b, "", "  ")
+	require.NoError(t, err)
+	return data
+}
+
+func TestNewKeystoreBackup(t *testing.T) {
+	setupBackupKeystore(t)
+	SetVersion("1.2.3")
+	t.Cleanup(func() { SetVersion("dev") })
+
+	b, err := NewKeystoreBackup("")
+	require.NoError(t, err)
+	assert.Equal(t, KeystoreBackupFormat, b.Format)
+	assert.Equal(t, "1.2.3", b.VSBVersion)
+	assert.Equal(t, 2, b.Inboxes)
+	assert.False(t, b.CreatedAt.IsZero())
+	assert.False(t, b.Encrypted())
+	assert.Contains(t, b.Checksum, "sha256:")
+
+	parsed, err := ParseKeystoreBackup(roundTripBackup(t, b))
+	require.NoError(t, err)
+	data, err := parsed.Open("")
+	require.NoError(t, err)
+	assert.JSONEq(t, backupKeystore, string(data))
+}
+
+func TestNewKeystoreBackupMissing(t *testing.T) {
+	t.Setenv("VSB_CONFIG_DIR", t.TempDir())
+
+	_, err := NewKeystoreBackup("")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no keystore to back up")
+}
+
+func TestKeystoreBackupChecksum(t *testing.T) {
+	setupBackupKeystore(t)
+	b, err := NewKeystoreBackup("")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		modify func(b *KeystoreBackup)
+	}{
+		{"keystore changed", func(b *KeystoreBackup) {
+			b.Keystore = json.RawMessage(`{"version":2,"inboxes":[],"active_inbox":""}`)
+		}},
+		{"createdAt changed", func(b *KeystoreBackup) { b.CreatedAt = b.CreatedAt.Add(1) }},
+		{"vsbVersion changed", func(b *KeystoreBackup) { b.VSBVersion = "other" }},
+		{"checksum changed", func(b *KeystoreBackup) { b.Checksum = "sha256:00" }},
+		{"checksum missing", func(b *KeystoreBackup) { b.Checksum = "" }},
+		{"unsupported checksum", func(b *KeystoreBackup) { b.Checksum = "md5:abc" }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			modified := *b
+			tt.modify(&modified)
+			_, err := ParseKeystoreBackup(roundTripBackup(t, &modified))
+			assert.ErrorIs(t, err, ErrBackupCorrupted)
+		})
+	}
+
+	t.Run("whitespace does not matter", func(t *testing.T) {
+		data, err := json.Marshal(b)
+		require.NoError(t, err)
+		_, err = ParseKeystoreBackup(data)
+		assert.NoError(t, err)
+	})
+}
+
+func TestParseKeystoreBackupInvalid(t *testing.T) {
+	_, err := ParseKeystoreBackup([]byte("not json"))
+	assert.ErrorContains(t, err, "invalid keystore backup")
+
+	_, err = ParseKeystoreBackup([]byte(`{"active_inbox": ""}`))
+	assert.ErrorContains(t, err, "no format version")
+
+	_, err = ParseKeystoreBackup([]byte(`{"format": 99}`))
+	assert.ErrorContains(t, err, "newer than this vsb supports")
+}
+
+func TestKeystoreBackupEncrypted(t *testing.T) {
+	setupBackupKeystore(t)
+
+	b, err := NewKeystoreBackup("correct horse")
+	require.NoError(t, err)
+	assert.True(t, b.Encrypted())
+	assert.Empty(t, b.Keystore)
+	assert.NotContains(t, string(roundTripBackup(t, b)), "priv")
+
+	parsed, err := ParseKeystoreBackup(roundTripBackup(t, b))
+	require.NoError(t, err)
+
+	data, err := parsed.Open("correct horse")
+	require.NoError(t, err)
+	assert.JSONEq(t, backupKeystore, string(data))
+
+	_, err = parsed.Open("wrong")
+	assert.ErrorIs(t, err, ErrBackupPassword)
+}
+
+func TestRestoreKeystore(t *testing.T) {
+	t.Run("writes a new keystore", func(t *testing.T) {
+		dir := t.TempDir()
+		t.Setenv("VSB_CONFIG_DIR", dir)
+
+		path, inboxes, err := RestoreKeystore([]byte(backupKeystore), false)
+		require.NoError(t, err)
+		assert.Equal(t, filepath.Join(dir, "keystore.json"), path)
+		assert.Equal(t, 2, inboxes)
+
+		info, err := os.Stat(path)
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+		ks, err := LoadKeystore()
+		require.NoError(t, err)
+		assert.Len(t, ks.Inboxes, 2)
+		assert.Equal(t, "b@example.com", ks.ActiveInbox)
+	})
+
+	t.Run("refuses to overwrite without force", func(t *testing.T) {
+		path := setupBackupKeystore(t)
+		replacement := `{"version": 2, "inboxes": [], "active_inbox": ""}`
+
+		_, _, err := RestoreKeystore([]byte(replacement), false)
+		assert.ErrorIs(t, err, ErrKeystoreExists)
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.JSONEq(t, backupKeystore, string(data))
+
+		_, inboxes, err := RestoreKeystore([]byte(replacement), true)
+		require.NoError(t, err)
+		assert.Equal(t, 0, inboxes)
+		data, err = os.ReadFile(path)
+		require.NoError(t, err)
+		assert.JSONEq(t, replacement, string(data))
+	})
+
+	t.Run("rejects a newer keystore", func(t *testing.T) {
+		t.Setenv("VSB_CONFIG_DIR", t.TempDir())
+		_, _, err := RestoreKeystore([]byte(`{"version": 99, "inboxes": []}`), false)
+		assert.ErrorContains(t, err, "newer than this vsb supports")
+	})
+}
@@ -2,32 +2,94 @@ package config
 
 import (
 	"errors"
+	"fmt"
+	"time"
 
 	vaultsandbox "github.com/vaultsandbox/client-go"
 )
 
+// ErrNoAPIKey is returned by NewClient when no source (--api-key-file,
+// VSB_API_KEY, api-key-command or the config file) provides an API key.
 var ErrNoAPIKey = errors.New("API key not configured. Set VSB_API_KEY or run 'vsb config'")
 
 // NewClient creates a VaultSandbox client using current configuration
 func NewClient() (*vaultsandbox.Client, error) {
-	apiKey := GetAPIKey()
+	apiKey, err := ResolveAPIKey()
+	if err != nil {
+		return nil, err
+	}
 	if apiKey == "" {
 		return nil, ErrNoAPIKey
 	}
 
-	opts := []vaultsandbox.Option{}
+	if err := ValidateUserAgent(GetUserAgent()); err != nil {
+		return nil, err
+	}
+
+	// The SDK's SSE connection reuses this client's transport, so the proxy
+	// and User-Agent cover real-time delivery as well
+	httpClient := NewHTTPClient(DefaultHTTPTimeout)
+	httpClient.Transport = &serverClockTransport{base: httpClient.Transport}
+	opts := []vaultsandbox.Option{
+		vaultsandbox.WithHTTPClient(httpClient),
+	}
 
 	if baseURL := GetBaseURL(); baseURL != "" {
 		opts = append(opts, vaultsandbox.WithBaseURL(baseURL))
 	}
 
-	// Apply delivery strategy
-	switch GetStrategy() {
+	// Apply delivery strategy. --poll-interval asks for polling, whatever
+	// the configured strategy.
+	strategy := GetStrategy()
+	if pollIntervalOverride > 0 {
+		strategy = "polling"
+	}
+	switch strategy {
 	case "polling":
 		opts = append(opts, vaultsandbox.WithDeliveryStrategy(vaultsandbox.StrategyPolling))
 	case "sse":
 		opts = append(opts, vaultsandbox.WithDeliveryStrategy(vaultsandbox.StrategySSE))
 	}
 
+	interval, err := pollInterval()
+	if err != nil {
+		return nil, err
+	}
+	if interval > 0 {
+		opts = append(opts, vaultsandbox.WithPollingConfig(pollingConfig(interval)))
+	}
+
 	return vaultsandbox.New(apiKey, opts...)
 }
+
+// pollJitter is the random share added to each polling interval, so
+// parallel CI jobs do not poll in lockstep
+const pollJitter = 0.1
+
+// pollInterval returns the configured polling interval, or zero to keep the
+// SDK's adaptive default
+func pollInterval() (time.Duration, error) {
+	if pollIntervalOverride > 0 {
+		return pollIntervalOverride, nil
+	}
+	value := GetPollInterval()
+	if value == "" {
+		return 0, nil
+	}
+	d, err := ParsePollInterval(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid poll-interval setting: %w", err)
+	}
+	return d, nil
+}
+
+// pollingConfig polls at a steady interval. The SDK otherwise backs off to
+// 30s while an inbox is idle, which is what a fixed interval is meant to
+// avoid.
+func pollingConfig(interval time.Duration) vaultsandbox.PollingConfig {
+	return vaultsandbox.PollingConfig{
+		InitialInterval: interval,
+		MaxBackoff:      interval,
+		JitterFactor:    pollJitter,
+	}
+}
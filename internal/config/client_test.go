@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -52,5 +53,54 @@ func TestNewClient(t *testing.T) {
 		_, err := NewClient()
 		assert.ErrorIs(t, err, ErrNoAPIKey)
 	})
+}
+
+func TestPollInterval(t *testing.T) {
+	originalCurrent := current
+	defer func() {
+		current = originalCurrent
+		SetPollIntervalOverride(0)
+	}()
+
+	t.Run("unset keeps the SDK default", func(t *testing.T) {
+		t.Setenv("VSB_POLL_INTERVAL", "")
+		current = Config{}
+
+		d, err := pollInterval()
+		require.NoError(t, err)
+		assert.Zero(t, d)
+	})
+
+	t.Run("config file value", func(t *testing.T) {
+		t.Setenv("VSB_POLL_INTERVAL", "")
+		current = Config{PollInterval: "3s"}
+
+		d, err := pollInterval()
+		require.NoError(t, err)
+		assert.Equal(t, 3*time.Second, d)
+	})
+
+	t.Run("flag overrides env and config", func(t *testing.T) {
+		t.Setenv("VSB_POLL_INTERVAL", "5s")
+		current = Config{PollInterval: "3s"}
+		SetPollIntervalOverride(time.Second)
+		defer SetPollIntervalOverride(0)
+
+		d, err := pollInterval()
+		require.NoError(t, err)
+		assert.Equal(t, time.Second, d)
+	})
+
+	t.Run("invalid setting", func(t *testing.T) {
+		t.Setenv("VSB_POLL_INTERVAL", "10ms")
 
+		_, err := pollInterval()
+		assert.ErrorContains(t, err, "invalid poll-interval setting")
+	})
+
+	t.Run("fixed interval disables backoff", func(t *testing.T) {
+		cfg := pollingConfig(750 * time.Millisecond)
+		assert.Equal(t, 750*time.Millisecond, cfg.InitialInterval)
+		assert.Equal(t, cfg.InitialInterval, cfg.MaxBackoff)
+	})
 }
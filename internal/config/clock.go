@@ -0,0 +1,167 @@
+package config
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// ClockSkewMaxAge is how long a measured clock skew is reused by commands
+// that do not reach the server
+const ClockSkewMaxAge = time.Hour
+
+// ClockSkewWarnThreshold is the skew above which vsb warns that the local
+// clock is off
+const ClockSkewWarnThreshold = time.Minute
+
+// unknownSkewGrace is how long after expiry an inbox is kept by the implicit
+// pruning in LoadKeystore while the skew is unknown. The keystore is loaded
+// before a command's first request, so without it a fast local clock would
+// delete inboxes the server still serves.
+const unknownSkewGrace = time.Hour
+
+// minClockSkew is the smallest skew that is corrected for. The Date header
+// only has whole seconds, so anything below is noise.
+const minClockSkew = 2 * time.Second
+
+// clockState is the skew between the server's clock and this machine's:
+// measured from the first server response of this process, or else read
+// from clock.json if another command measured it recently
+var clockState struct {
+	mu       sync.Mutex
+	skew     time.Duration
+	known    bool
+	loaded   bool // clock.json has been read
+	observed bool // a server response has been seen
+}
+
+// clockFile is the content of clock.json
+type clockFile struct {
+	SkewMs     int64     `json:"skewMs"` // server time minus local time
+	MeasuredAt time.Time `json:"measuredAt"`
+}
+
+// ServerNow returns the current time by the server's clock, as far as it is
+// known; see ClockSkew. Expiry decisions use it, since the server decides
+// when inboxes expire.
+func ServerNow() time.Time {
+	skew, _ := ClockSkew()
+	return time.Now().Add(skew)
+}
+
+// ClockSkew returns how far the server's clock is ahead of this machine's
+// (negative if behind), and whether it is known: measured by this command,
+// or by another within ClockSkewMaxAge. An unknown skew is zero, so
+// commands that never reach the server use local time.
+func ClockSkew() (time.Duration, bool) {
+	clockState.mu.Lock()
+	defer clockState.mu.Unlock()
+	if !clockState.observed && !clockState.loaded {
+		clockState.loaded = true
+		clockState.skew, clockState.known = loadClockSkew(time.Now())
+	}
+	return clockState.skew, clockState.known
+}
+
+// pruneCutoff returns the time before which LoadKeystore removes expired
+// inboxes: the server's time when the skew is known, and unknownSkewGrace
+// before the local time otherwise
+func pruneCutoff() time.Time {
+	if skew, known := ClockSkew(); known {
+		return time.Now().Add(skew)
+	}
+	return time.Now().Add(-unknownSkewGrace)
+}
+
+// observeServerDate measures the skew from the Date header of a response to
+// a request sent at sent and answered at received. Only the first response
+// of a process is used; the skew is saved for later commands.
+func observeServerDate(date string, sent, received time.Time) {
+	serverTime, err := http.ParseTime(date)
+	if err != nil {
+		return
+	}
+
+	clockState.mu.Lock()
+	if clockState.observed {
+		clockState.mu.Unlock()
+		return
+	}
+	// The header is truncated to the second, and the server wrote it
+	// somewhere between sent and received
+	local := sent.Add(received.Sub(sent) / 2)
+	skew := serverTime.Add(time.Second / 2).Sub(local)
+	if skew > -minClockSkew && skew < minClockSkew {
+		skew = 0
+	}
+	clockState.skew, clockState.known, clockState.observed = skew, true, true
+	clockState.mu.Unlock()
+
+	// Only an optimisation for later commands, so errors are ignored
+	saveClockSkew(skew, received)
+}
+
+// clockPath returns <config dir>/clock.json
+func clockPath() (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "clock.json"), nil
+}
+
+// loadClockSkew returns the skew saved in clock.json, if it was measured
+// within ClockSkewMaxAge before now
+func loadClockSkew(now time.Time) (time.Duration, bool) {
+	path, err := clockPath()
+	if err != nil {
+		return 0, false
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, false
+	}
+	var saved clockFile
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return 0, false
+	}
+	if age := now.Sub(saved.MeasuredAt); age < 0 || age > ClockSkewMaxAge {
+		return 0, false
+	}
+	return time.Duration(saved.SkewMs) * time.Millisecond, true
+}
+
+func saveClockSkew(skew time.Duration, measuredAt time.Time) error {
+	path, err := clockPath()
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(clockFile{SkewMs: skew.Milliseconds(), MeasuredAt: measuredAt}, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	return WriteFileAtomic(path, data, 0600)
+}
+
+// serverClockTransport measures the clock skew from the server's responses.
+// It is only used for the VaultSandbox API, whose clock decides expiry.
+type serverClockTransport struct {
+	base http.RoundTripper
+}
+
+func (t *serverClockTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	sent := time.Now()
+	resp, err := t.base.RoundTrip(req)
+	if err == nil {
+		if date := resp.Header.Get("Date"); date != "" {
+			observeServerDate(date, sent, time.Now())
+		}
+	}
+	return resp, err
+}
@@ -0,0 +1,196 @@
+package config
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// resetServerClock forgets the measured skew and uses a fresh config
+// directory, so the next ClockSkew reads an empty one
+func resetServerClock(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("VSB_CONFIG_DIR", dir)
+	forget := func() {
+		clockState.skew, clockState.known, clockState.loaded, clockState.observed = 0, false, false, false
+	}
+	forget()
+	t.Cleanup(forget)
+	return dir
+}
+
+// fakeServerClock answers every request with a Date header skew ahead of
+// the local clock, and measures it through serverClockTransport
+func fakeServerClock(t *testing.T, skew time.Duration) {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Date", time.Now().Add(skew).UTC().Format(http.TimeFormat))
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: &serverClockTransport{base: http.DefaultTransport}}
+	resp, err := client.Get(server.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+}
+
+func TestClockSkew(t *testing.T) {
+	t.Run("unknown without a measurement", func(t *testing.T) {
+		resetServerClock(t)
+		skew, known := ClockSkew()
+		assert.False(t, known)
+		assert.Zero(t, skew)
+		assert.WithinDuration(t, time.Now(), ServerNow(), time.Second)
+	})
+
+	t.Run("measured from the Date header", func(t *testing.T) {
+		resetServerClock(t)
+		fakeServerClock(t, 10*time.Minute)
+
+		skew, known := ClockSkew()
+		assert.True(t, known)
+		assert.InDelta(t, float64(10*time.Minute), float64(skew), float64(time.Second))
+		assert.WithinDuration(t, time.Now().Add(10*time.Minute), ServerNow(), time.Second)
+	})
+
+	t.Run("only the first response counts", func(t *testing.T) {
+		resetServerClock(t)
+		fakeServerClock(t, -10*time.Minute)
+		fakeServerClock(t, time.Hour)
+
+		skew, _ := ClockSkew()
+		assert.InDelta(t, float64(-10*time.Minute), float64(skew), float64(time.Second))
+	})
+
+	t.Run("skew below two seconds is ignored", func(t *testing.T) {
+		resetServerClock(t)
+		sent := time.Date(2026, 1, 2, 3, 4, 5, 200_000_000, time.UTC)
+		observeServerDate("Fri, 02 Jan 2026 03:04:06 GMT", sent, sent.Add(100*time.Millisecond))
+
+		skew, known := ClockSkew()
+		assert.True(t, known)
+		assert.Zero(t, skew)
+	})
+
+	t.Run("invalid Date header is ignored", func(t *testing.T) {
+		resetServerClock(t)
+		observeServerDate("yesterday", time.Now(), time.Now())
+		_, known := ClockSkew()
+		assert.False(t, known)
+	})
+
+	t.Run("saved for later commands", func(t *testing.T) {
+		dir := resetServerClock(t)
+		fakeServerClock(t, 10*time.Minute)
+
+		// A new process reads clock.json
+		clockState.skew, clockState.known, clockState.observed = 0, false, false
+		skew, known := ClockSkew()
+		assert.True(t, known)
+		assert.InDelta(t, float64(10*time.Minute), float64(skew), float64(time.Second))
+
+		info, err := os.Stat(filepath.Join(dir, "clock.json"))
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	})
+
+	t.Run("old measurements are not reused", func(t *testing.T) {
+		resetServerClock(t)
+		require.NoError(t, saveClockSkew(10*time.Minute, time.Now().Add(-ClockSkewMaxAge-time.Minute)))
+
+		skew, known := ClockSkew()
+		assert.False(t, known)
+		assert.Zero(t, skew)
+	})
+}
+
+func TestServerClockPruning(t *testing.T) {
+	writeKeystore := func(t *testing.T, dir string, expiresAt time.Time) {
+		t.Helper()
+		data := fmt.Sprintf(`{
+  "version": 2,
+  "inboxes": [{"email": "soon@example.com", "id": "h1", "expiresAt": %q, "keys": {}}]
+}`, expiresAt.Format(time.RFC3339))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "keystore.json"), []byte(data), 0600))
+	}
+	loadedEmails := func(t *testing.T) []string {
+		t.Helper()
+		ks, err := LoadKeystore()
+		require.NoError(t, err)
+		var emails []string
+		for _, inbox := range ks.ListInboxes() {
+			emails = append(emails, inbox.Email)
+		}
+		return emails
+	}
+	// 5 minutes from now by the local clock
+	expiresAt := time.Now().Add(5 * time.Minute).Truncate(time.Second)
+
+	for _, tc := range []struct {
+		name   string
+		skew   time.Duration
+		pruned bool
+	}{
+		{"server clock agrees", 0, false},
+		{"server clock just before expiry", 4 * time.Minute, false},
+		{"server clock just after expiry", 6 * time.Minute, true},
+		{"server clock far ahead", 10 * time.Minute, true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := resetServerClock(t)
+			writeKeystore(t, dir, expiresAt)
+			fakeServerClock(t, tc.skew)
+
+			if tc.pruned {
+				assert.Empty(t, loadedEmails(t))
+			} else {
+				assert.Equal(t, []string{"soon@example.com"}, loadedEmails(t))
+			}
+		})
+	}
+
+	t.Run("load before the first request keeps recently expired inboxes", func(t *testing.T) {
+		dir := resetServerClock(t)
+		// The local clock is 10 minutes fast: by the server's clock this
+		// inbox has 5 minutes left
+		data := fmt.Sprintf(`{
+  "version": 2,
+  "inboxes": [
+    {"email": "live@example.com", "id": "h1", "expiresAt": %q, "keys": {}},
+    {"email": "dead@example.com", "id": "h2", "expiresAt": %q, "keys": {}}
+  ]
+}`, time.Now().Add(-5*time.Minute).Format(time.RFC3339), time.Now().Add(-2*time.Hour).Format(time.RFC3339))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "keystore.json"), []byte(data), 0600))
+
+		// As a command does: load the keystore, then talk to the server
+		assert.Equal(t, []string{"live@example.com"}, loadedEmails(t))
+		fakeServerClock(t, -10*time.Minute)
+
+		ks, err := LoadKeystore()
+		require.NoError(t, err)
+		inbox, err := ks.GetInbox("live@example.com")
+		require.NoError(t, err)
+		assert.True(t, inbox.ExpiresAt.After(ServerNow()))
+	})
+
+	t.Run("fast local clock keeps inboxes the server has not expired", func(t *testing.T) {
+		dir := resetServerClock(t)
+		writeKeystore(t, dir, time.Now().Add(-5*time.Minute).Truncate(time.Second))
+		fakeServerClock(t, -10*time.Minute)
+
+		assert.Equal(t, []string{"soon@example.com"}, loadedEmails(t))
+
+		result, err := PruneKeystore(ServerNow(), 0, true)
+		require.NoError(t, err)
+		assert.Empty(t, result.Pruned)
+		assert.Equal(t, 1, result.Remaining)
+	})
+}
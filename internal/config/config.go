@@ -1,17 +1,56 @@
 package config
 
 import (
+	"errors"
+	"fmt"
+	"math"
 	"os"
 	"path/filepath"
+	"strconv"
+	"time"
 
+	"github.com/dustin/go-humanize"
 	"gopkg.in/yaml.v3"
 )
 
 type Config struct {
+	Version       int    `yaml:"version,omitempty"` // see ConfigSchemaVersion
 	APIKey        string `yaml:"api_key"`
+	APIKeyCommand string `yaml:"api_key_command"` // stdout is the API key
 	BaseURL       string `yaml:"base_url"`
 	DefaultOutput string `yaml:"default_output"`
 	Strategy      string `yaml:"strategy"`
+	PollInterval  string `yaml:"poll_interval"` // with the polling strategy
+	Browser       string `yaml:"browser"`
+	CIIntegration bool   `yaml:"ci_integration"`
+	HTMLRenderer  string `yaml:"html_renderer"`
+
+	// Defaults for 'inbox create' when the matching flag is not given
+	DefaultTTL         string `yaml:"default_ttl"`
+	DefaultLabelPrefix string `yaml:"default_label_prefix"`
+
+	// ExportStrict makes 'vsb export' refuse risky output locations
+	ExportStrict bool `yaml:"export_strict"`
+
+	// Proxy routes all HTTP requests through an http(s):// or socks5://
+	// proxy, except for hosts matching NoProxy
+	Proxy   string `yaml:"proxy"`
+	NoProxy string `yaml:"no_proxy"`
+
+	// UserAgent replaces the default vsb-cli/<version> User-Agent header
+	UserAgent string `yaml:"user_agent"`
+
+	// AttachmentMaxSize is the largest attachment 'email attachment' saves
+	// without --force, e.g. 10MB
+	AttachmentMaxSize string `yaml:"attachment_max_size"`
+
+	// Aliases maps user-defined command names to the command line they run,
+	// e.g. links: email url
+	Aliases map[string]string `yaml:"alias,omitempty"`
+
+	// Defaults holds flag defaults per command, e.g. email.list.limit: 20;
+	// see GetFlagDefaults
+	Defaults map[string]interface{} `yaml:"defaults,omitempty"`
 }
 
 // DefaultBaseURL
@@ -20,9 +59,28 @@ const DefaultBaseURL = "https://"
 // DefaultStrategy is the default delivery strategy
 const DefaultStrategy = "sse"
 
+// MinPollInterval is the shortest accepted polling interval, so a typo
+// cannot hammer the server
+const MinPollInterval = 500 * time.Millisecond
+
+// DefaultHTMLRenderer is the default way HTML emails are displayed
+const DefaultHTMLRenderer = "browser"
+
+// DefaultTTL is the lifetime of new inboxes when neither --ttl nor the
+// default-ttl config key is set
+const DefaultTTL = "24h"
+
 // Package-level state
 var current Config
 
+// pollIntervalOverride is the --poll-interval flag value of 'email wait',
+// which takes priority over VSB_POLL_INTERVAL and the config file
+var pollIntervalOverride time.Duration
+
+// browserOverride is the --browser flag value, which takes priority over
+// VSB_BROWSER and the config file
+var browserOverride string
+
 // Dir returns the vsb config directory path.
 // Respects VSB_CONFIG_DIR environment variable if set.
 func Dir() (string, error) {
@@ -77,6 +135,36 @@ func EnsureDir() error {
 	return os.MkdirAll(dir, 0700)
 }
 
+// ErrConfigDirNotWritable is returned by CheckDirWritable
+var ErrConfigDirNotWritable = errors.New("config directory is not writable")
+
+// CheckDirWritable creates the config directory if needed and checks that
+// files can be created in it by writing and removing a probe file, so
+// commands can fail before doing anything they could not record.
+func CheckDirWritable() error {
+	dir, err := Dir()
+	if err != nil {
+		return err
+	}
+	notWritable := func(err error) error {
+		var pathErr *os.PathError
+		if errors.As(err, &pathErr) {
+			err = pathErr.Err
+		}
+		return fmt.Errorf("%w: %s: %v (set VSB_CONFIG_DIR to a writable directory)", ErrConfigDirNotWritable, dir, err)
+	}
+
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return notWritable(err)
+	}
+	probe, err := os.CreateTemp(dir, ".write-check-*")
+	if err != nil {
+		return notWritable(err)
+	}
+	probe.Close()
+	return os.Remove(probe.Name())
+}
+
 // LoadFromFile reads configuration from a YAML file
 func LoadFromFile(path string) error {
 	data, err := os.ReadFile(path)
@@ -100,9 +188,12 @@ func getConfigValue(envKey, fileValue, defaultValue string) string {
 	return defaultValue
 }
 
-// GetAPIKey returns API key with priority: env > config file
+// GetAPIKey returns API key with priority: --api-key-file > env >
+// api-key-command > config file. It returns an empty string if the key
+// cannot be read; use ResolveAPIKey to get the error.
 func GetAPIKey() string {
-	return getConfigValue("API_KEY", current.APIKey, "")
+	key, _ := ResolveAPIKey()
+	return key
 }
 
 // GetBaseURL returns base URL with priority: env > config file > default
@@ -120,6 +211,106 @@ func GetStrategy() string {
 	return getConfigValue("STRATEGY", current.Strategy, DefaultStrategy)
 }
 
+// GetPollInterval returns the polling interval with priority: env > config
+// file. An empty string means the SDK's adaptive default. The value is not
+// validated here; see ParsePollInterval.
+func GetPollInterval() string {
+	return getConfigValue("POLL_INTERVAL", current.PollInterval, "")
+}
+
+// ParsePollInterval parses a polling interval such as 750ms or 2s and
+// checks it against MinPollInterval
+func ParsePollInterval(value string) (time.Duration, error) {
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not a duration (e.g. 750ms or 2s)", value)
+	}
+	if d < MinPollInterval {
+		return 0, fmt.Errorf("%s is below the minimum of %s", d, MinPollInterval)
+	}
+	return d, nil
+}
+
+// SetPollIntervalOverride sets the interval given with --poll-interval. Zero
+// clears the override.
+func SetPollIntervalOverride(d time.Duration) {
+	pollIntervalOverride = d
+}
+
+// GetBrowser returns the browser command with priority: --browser flag >
+// env > config file. An empty string means the platform default should be
+// used.
+func GetBrowser() string {
+	if browserOverride != "" {
+		return browserOverride
+	}
+	return getConfigValue("BROWSER", current.Browser, "")
+}
+
+// SetBrowserOverride sets the browser command given with --browser. An empty
+// command clears the override.
+func SetBrowserOverride(command string) {
+	browserOverride = command
+}
+
+// GetHTMLRenderer returns how HTML emails are displayed ("browser" or
+// "terminal") with priority: env > config file > default
+func GetHTMLRenderer() string {
+	return getConfigValue("HTML_RENDERER", current.HTMLRenderer, DefaultHTMLRenderer)
+}
+
+// GetDefaultTTL returns the lifetime for new inboxes with priority:
+// env > config file > default. The value is not validated here.
+func GetDefaultTTL() string {
+	return getConfigValue("DEFAULT_TTL", current.DefaultTTL, DefaultTTL)
+}
+
+// GetDefaultLabelPrefix returns the label prefix for new inboxes with
+// priority: env > config file. An empty string means no label.
+func GetDefaultLabelPrefix() string {
+	return getConfigValue("DEFAULT_LABEL_PREFIX", current.DefaultLabelPrefix, "")
+}
+
+// GetAttachmentMaxSize returns the attachment size limit with priority:
+// env > config file. An empty string means no limit. The value is not
+// validated here; see ParseByteSize.
+func GetAttachmentMaxSize() string {
+	return getConfigValue("ATTACHMENT_MAX_SIZE", current.AttachmentMaxSize, "")
+}
+
+// ParseByteSize parses a size such as 10MB, 512KiB or 1048576. Zero means
+// no limit.
+func ParseByteSize(value string) (int64, error) {
+	n, err := humanize.ParseBytes(value)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not a size (e.g. 10MB or 512KiB)", value)
+	}
+	if n > math.MaxInt64 {
+		return 0, fmt.Errorf("%q is too large", value)
+	}
+	return int64(n), nil
+}
+
+// GetCIIntegration reports whether CI reporting is enabled automatically,
+// with priority: env > config file
+func GetCIIntegration() bool {
+	if env := os.Getenv("VSB_CI_INTEGRATION"); env != "" {
+		enabled, _ := strconv.ParseBool(env)
+		return enabled
+	}
+	return current.CIIntegration
+}
+
+// GetExportStrict reports whether 'vsb export' should fail instead of warn
+// when the output file may be exposed, with priority: env > config file
+func GetExportStrict() bool {
+	if env := os.Getenv("VSB_EXPORT_STRICT"); env != "" {
+		strict, _ := strconv.ParseBool(env)
+		return strict
+	}
+	return current.ExportStrict
+}
+
 // Save writes the config to disk as YAML
 func Save(cfg *Config) error {
 	if err := EnsureDir(); err != nil {
@@ -129,9 +320,37 @@ func Save(cfg *Config) error {
 	dir, _ := Dir()
 	configPath := filepath.Join(dir, "config.yaml")
 
+	cfg.Version = ConfigSchemaVersion
 	data, err := yaml.Marshal(cfg)
 	if err != nil {
 		return err
 	}
-	return os.WriteFile(configPath, data, 0600)
+	return WriteFileAtomic(configPath, data, 0600)
+}
+
+// WriteFileAtomic writes data to a temp file in the same directory and
+// renames it over path, so an interrupted write never leaves a truncated file.
+func WriteFileAtomic(path string, data []byte, perm os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+	defer os.Remove(tmpPath) // no-op after a successful rename
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmpPath, perm); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, path)
 }
@@ -3,8 +3,10 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"runtime"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -118,6 +120,46 @@ func TestEnsureDir(t *testing.T) {
 	})
 }
 
+func TestCheckDirWritable(t *testing.T) {
+	t.Run("creates the directory and leaves no probe file", func(t *testing.T) {
+		dir := filepath.Join(t.TempDir(), "new-config-dir")
+		t.Setenv("VSB_CONFIG_DIR", dir)
+
+		require.NoError(t, CheckDirWritable())
+		entries, err := os.ReadDir(dir)
+		require.NoError(t, err)
+		assert.Empty(t, entries)
+	})
+
+	// A path below a regular file can never be created, even by root, for
+	// whom permission bits would not make a directory read-only
+	t.Run("directory cannot be created", func(t *testing.T) {
+		file := filepath.Join(t.TempDir(), "file")
+		require.NoError(t, os.WriteFile(file, nil, 0600))
+		dir := filepath.Join(file, "vsb")
+		t.Setenv("VSB_CONFIG_DIR", dir)
+
+		err := CheckDirWritable()
+		assert.ErrorIs(t, err, ErrConfigDirNotWritable)
+		assert.ErrorContains(t, err, dir+": not a directory")
+		assert.ErrorContains(t, err, "VSB_CONFIG_DIR")
+	})
+
+	t.Run("read-only directory", func(t *testing.T) {
+		if os.Geteuid() == 0 {
+			t.Skip("root can write to read-only directories")
+		}
+		dir := t.TempDir()
+		require.NoError(t, os.Chmod(dir, 0500))
+		t.Cleanup(func() { os.Chmod(dir, 0700) })
+		t.Setenv("VSB_CONFIG_DIR", dir)
+
+		err := CheckDirWritable()
+		assert.ErrorIs(t, err, ErrConfigDirNotWritable)
+		assert.ErrorContains(t, err, "permission denied")
+	})
+}
+
 func TestGetAPIKey(t *testing.T) {
 	// Save original state
 	originalCurrent := current
@@ -242,6 +284,196 @@ func TestGetStrategy(t *testing.T) {
 	})
 }
 
+func TestGetBrowser(t *testing.T) {
+	originalCurrent := current
+	defer func() { current = originalCurrent }()
+
+	t.Run("defaults to empty", func(t *testing.T) {
+		t.Setenv("VSB_BROWSER", "")
+		current = Config{}
+
+		assert.Empty(t, GetBrowser())
+	})
+
+	t.Run("env var override", func(t *testing.T) {
+		t.Setenv("VSB_BROWSER", "chromium")
+		current = Config{Browser: "firefox --new-tab"}
+
+		assert.Equal(t, "chromium", GetBrowser())
+	})
+
+	t.Run("config file value", func(t *testing.T) {
+		t.Setenv("VSB_BROWSER", "")
+		current = Config{Browser: "firefox --new-tab"}
+
+		assert.Equal(t, "firefox --new-tab", GetBrowser())
+	})
+
+	t.Run("flag override beats env", func(t *testing.T) {
+		t.Setenv("VSB_BROWSER", "chromium")
+		current = Config{Browser: "firefox --new-tab"}
+		SetBrowserOverride("remote-open %u")
+		defer SetBrowserOverride("")
+
+		assert.Equal(t, "remote-open %u", GetBrowser())
+	})
+}
+
+func TestGetHTMLRenderer(t *testing.T) {
+	originalCurrent := current
+	defer func() { current = originalCurrent }()
+
+	t.Run("defaults to browser", func(t *testing.T) {
+		t.Setenv("VSB_HTML_RENDERER", "")
+		current = Config{}
+
+		assert.Equal(t, DefaultHTMLRenderer, GetHTMLRenderer())
+	})
+
+	t.Run("env var override", func(t *testing.T) {
+		t.Setenv("VSB_HTML_RENDERER", "browser")
+		current = Config{HTMLRenderer: "terminal"}
+
+		assert.Equal(t, "browser", GetHTMLRenderer())
+	})
+
+	t.Run("config file value", func(t *testing.T) {
+		t.Setenv("VSB_HTML_RENDERER", "")
+		current = Config{HTMLRenderer: "terminal"}
+
+		assert.Equal(t, "terminal", GetHTMLRenderer())
+	})
+}
+
+func TestGetDefaultTTL(t *testing.T) {
+	originalCurrent := current
+	defer func() { current = originalCurrent }()
+
+	t.Run("defaults to 24h", func(t *testing.T) {
+		t.Setenv("VSB_DEFAULT_TTL", "")
+		current = Config{}
+
+		assert.Equal(t, DefaultTTL, GetDefaultTTL())
+	})
+
+	t.Run("config file value", func(t *testing.T) {
+		t.Setenv("VSB_DEFAULT_TTL", "")
+		current = Config{DefaultTTL: "7d"}
+
+		assert.Equal(t, "7d", GetDefaultTTL())
+	})
+
+	t.Run("env var overrides config", func(t *testing.T) {
+		t.Setenv("VSB_DEFAULT_TTL", "2h")
+		current = Config{DefaultTTL: "7d"}
+
+		assert.Equal(t, "2h", GetDefaultTTL())
+	})
+}
+
+func TestParsePollInterval(t *testing.T) {
+	d, err := ParsePollInterval("750ms")
+	require.NoError(t, err)
+	assert.Equal(t, 750*time.Millisecond, d)
+
+	_, err = ParsePollInterval("100ms")
+	assert.ErrorContains(t, err, "below the minimum of 500ms")
+
+	_, err = ParsePollInterval("fast")
+	assert.ErrorContains(t, err, "not a duration")
+}
+
+func TestParseByteSize(t *testing.T) {
+	n, err := ParseByteSize("10MB")
+	require.NoError(t, err)
+	assert.Equal(t, int64(10_000_000), n)
+
+	n, err = ParseByteSize("512KiB")
+	require.NoError(t, err)
+	assert.Equal(t, int64(512*1024), n)
+
+	_, err = ParseByteSize("big")
+	assert.ErrorContains(t, err, "is not a size")
+}
+
+func TestGetDefaultLabelPrefix(t *testing.T) {
+	originalCurrent := current
+	defer func() { current = originalCurrent }()
+
+	t.Run("empty by default", func(t *testing.T) {
+		t.Setenv("VSB_DEFAULT_LABEL_PREFIX", "")
+		current = Config{}
+
+		assert.Empty(t, GetDefaultLabelPrefix())
+	})
+
+	t.Run("config file value", func(t *testing.T) {
+		t.Setenv("VSB_DEFAULT_LABEL_PREFIX", "")
+		current = Config{DefaultLabelPrefix: "ci-"}
+
+		assert.Equal(t, "ci-", GetDefaultLabelPrefix())
+	})
+
+	t.Run("env var overrides config", func(t *testing.T) {
+		t.Setenv("VSB_DEFAULT_LABEL_PREFIX", "local-")
+		current = Config{DefaultLabelPrefix: "ci-"}
+
+		assert.Equal(t, "local-", GetDefaultLabelPrefix())
+	})
+}
+
+func TestGetCIIntegration(t *testing.T) {
+	originalCurrent := current
+	defer func() { current = originalCurrent }()
+
+	t.Run("defaults to disabled", func(t *testing.T) {
+		t.Setenv("VSB_CI_INTEGRATION", "")
+		current = Config{}
+
+		assert.False(t, GetCIIntegration())
+	})
+
+	t.Run("config file value", func(t *testing.T) {
+		t.Setenv("VSB_CI_INTEGRATION", "")
+		current = Config{CIIntegration: true}
+
+		assert.True(t, GetCIIntegration())
+	})
+
+	t.Run("env var overrides config", func(t *testing.T) {
+		t.Setenv("VSB_CI_INTEGRATION", "false")
+		current = Config{CIIntegration: true}
+
+		assert.False(t, GetCIIntegration())
+	})
+}
+
+func TestGetExportStrict(t *testing.T) {
+	originalCurrent := current
+	defer func() { current = originalCurrent }()
+
+	t.Run("defaults to warnings only", func(t *testing.T) {
+		t.Setenv("VSB_EXPORT_STRICT", "")
+		current = Config{}
+
+		assert.False(t, GetExportStrict())
+	})
+
+	t.Run("config file value", func(t *testing.T) {
+		t.Setenv("VSB_EXPORT_STRICT", "")
+		current = Config{ExportStrict: true}
+
+		assert.True(t, GetExportStrict())
+	})
+
+	t.Run("env var overrides config", func(t *testing.T) {
+		t.Setenv("VSB_EXPORT_STRICT", "0")
+		current = Config{ExportStrict: true}
+
+		assert.False(t, GetExportStrict())
+	})
+}
+
 func TestSave(t *testing.T) {
 	t.Run("saves config to file", func(t *testing.T) {
 		dir := t.TempDir()
@@ -324,3 +556,26 @@ base_url: https://loaded.example.com`
 		assert.NoError(t, err)
 	})
 }
+
+func TestWriteFileAtomic(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "data.json")
+
+	require.NoError(t, WriteFileAtomic(path, []byte("first"), 0600))
+	require.NoError(t, WriteFileAtomic(path, []byte("second"), 0600))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "second", string(data))
+
+	if runtime.GOOS != "windows" {
+		info, err := os.Stat(path)
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	}
+
+	// No temp files left behind
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
@@ -0,0 +1,73 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// FlagDefault is one entry of the defaults section of the config file: a
+// default for a flag of one command, e.g. defaults.email.list.limit: 20.
+type FlagDefault struct {
+	// Key is the entry as written below defaults, e.g. email.list.limit
+	Key string
+	// Command is the command path without "vsb", e.g. [email list]; empty
+	// for the root command
+	Command []string
+	Flag    string
+	// Values holds the value, or each item of a list for flags that are
+	// repeatable. It is empty when the entry has no value.
+	Values []string
+}
+
+// GetFlagDefaults returns the entries of the defaults section, sorted by
+// key. The section may nest commands or write them as dotted keys:
+//
+//	defaults:
+//	  email.wait.timeout: 60s
+//	  email:
+//	    list:
+//	      limit: 20
+//
+// The last part of each key is the flag name.
+func GetFlagDefaults() []FlagDefault {
+	var defaults []FlagDefault
+	flattenDefaults("", current.Defaults, &defaults)
+	sort.Slice(defaults, func(i, j int) bool { return defaults[i].Key < defaults[j].Key })
+	return defaults
+}
+
+func flattenDefaults(prefix string, section map[string]interface{}, out *[]FlagDefault) {
+	for name, value := range section {
+		key := name
+		if prefix != "" {
+			key = prefix + "." + name
+		}
+
+		switch v := value.(type) {
+		case map[string]interface{}:
+			flattenDefaults(key, v, out)
+			continue
+		case map[interface{}]interface{}:
+			nested := make(map[string]interface{}, len(v))
+			for k, item := range v {
+				nested[fmt.Sprint(k)] = item
+			}
+			flattenDefaults(key, nested, out)
+			continue
+		}
+
+		parts := strings.Split(key, ".")
+		d := FlagDefault{Key: key, Command: parts[:len(parts)-1], Flag: parts[len(parts)-1]}
+		switch v := value.(type) {
+		case nil:
+		case []interface{}:
+			for _, item := range v {
+				d.Values = append(d.Values, fmt.Sprint(item))
+			}
+		default:
+			d.Values = []string{fmt.Sprint(v)}
+		}
+		*out = append(*out, d)
+	}
+}
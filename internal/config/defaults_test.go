@@ -0,0 +1,44 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetFlagDefaults(t *testing.T) {
+	originalCurrent := current
+	defer func() { current = originalCurrent }()
+
+	t.Run("nested and dotted keys", func(t *testing.T) {
+		current = Config{}
+		path := filepath.Join(t.TempDir(), "config.yaml")
+		require.NoError(t, os.WriteFile(path, []byte(`defaults:
+  email.wait.timeout: 60s
+  email:
+    list:
+      wide: true
+    wait:
+      inbox: [a@test.com, b@test.com]
+  output: json
+  inbox.create.ttl:
+`), 0600))
+		require.NoError(t, LoadFromFile(path))
+
+		assert.Equal(t, []FlagDefault{
+			{Key: "email.list.wide", Command: []string{"email", "list"}, Flag: "wide", Values: []string{"true"}},
+			{Key: "email.wait.inbox", Command: []string{"email", "wait"}, Flag: "inbox", Values: []string{"a@test.com", "b@test.com"}},
+			{Key: "email.wait.timeout", Command: []string{"email", "wait"}, Flag: "timeout", Values: []string{"60s"}},
+			{Key: "inbox.create.ttl", Command: []string{"inbox", "create"}, Flag: "ttl"},
+			{Key: "output", Command: []string{}, Flag: "output", Values: []string{"json"}},
+		}, GetFlagDefaults())
+	})
+
+	t.Run("no section", func(t *testing.T) {
+		current = Config{}
+		assert.Empty(t, GetFlagDefaults())
+	})
+}
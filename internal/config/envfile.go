@@ -0,0 +1,148 @@
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// EnvVar is one KEY=VALUE assignment from an env file.
+type EnvVar struct {
+	Key   string
+	Value string
+	// Override is set by an "override" prefix: the value replaces a
+	// variable that is already set in the environment.
+	Override bool
+}
+
+var envKeyPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// ParseEnvFile parses KEY=VALUE lines. Blank lines and lines starting with
+// # are skipped, and a leading "export" is allowed. Values may be wrapped in
+// double quotes, which understand Go escapes such as \n and \", or single
+// quotes, which are taken literally; only a comment may follow the closing
+// quote. Unquoted values run to the end of the line, # included.
+func ParseEnvFile(data []byte) ([]EnvVar, error) {
+	var vars []EnvVar
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if n == 1 {
+			line = strings.TrimPrefix(line, "\ufeff")
+		}
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		var v EnvVar
+		if rest, ok := cutKeyword(line, "override"); ok {
+			v.Override, line = true, rest
+		}
+		if rest, ok := cutKeyword(line, "export"); ok {
+			line = rest
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected KEY=VALUE", n)
+		}
+		v.Key = strings.TrimSpace(key)
+		if !envKeyPattern.MatchString(v.Key) {
+			return nil, fmt.Errorf("line %d: invalid variable name %q", n, v.Key)
+		}
+		var err error
+		if v.Value, err = parseEnvValue(strings.TrimSpace(value)); err != nil {
+			return nil, fmt.Errorf("line %d: %s: %w", n, v.Key, err)
+		}
+		vars = append(vars, v)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return vars, nil
+}
+
+// cutKeyword removes a leading keyword followed by whitespace from line
+func cutKeyword(line, keyword string) (string, bool) {
+	rest, ok := strings.CutPrefix(line, keyword)
+	if !ok || rest == "" || (rest[0] != ' ' && rest[0] != '\t') {
+		return line, false
+	}
+	return strings.TrimSpace(rest), true
+}
+
+func parseEnvValue(value string) (string, error) {
+	if value == "" || (value[0] != '"' && value[0] != '\'') {
+		return value, nil
+	}
+
+	quote := value[0]
+	end := -1
+	for i := 1; i < len(value); i++ {
+		if quote == '"' && value[i] == '\\' {
+			i++
+			continue
+		}
+		if value[i] == quote {
+			end = i
+			break
+		}
+	}
+	if end < 0 {
+		return "", fmt.Errorf("unterminated quoted value")
+	}
+	if rest := strings.TrimSpace(value[end+1:]); rest != "" && !strings.HasPrefix(rest, "#") {
+		return "", fmt.Errorf("unexpected text after quoted value: %s", rest)
+	}
+
+	if quote == '\'' {
+		return value[1:end], nil
+	}
+	unquoted, err := strconv.Unquote(value[:end+1])
+	if err != nil {
+		return "", fmt.Errorf("invalid quoted value: %s", value[:end+1])
+	}
+	return unquoted, nil
+}
+
+// LoadEnvFile sets the variables in the env file at path in the process
+// environment. A variable that is already set to a non-empty value is kept
+// unless its line is marked "override". It returns the names of the
+// variables it set.
+func LoadEnvFile(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read env file: %w", err)
+	}
+	vars, err := ParseEnvFile(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid env file %s: %w", path, err)
+	}
+
+	// Later lines win, as if the file were sourced by a shell
+	existing := make(map[string]bool)
+	for _, v := range vars {
+		if _, seen := existing[v.Key]; !seen {
+			existing[v.Key] = os.Getenv(v.Key) != ""
+		}
+	}
+
+	var set []string
+	for _, v := range vars {
+		if existing[v.Key] && !v.Override {
+			continue
+		}
+		if err := os.Setenv(v.Key, v.Value); err != nil {
+			return set, fmt.Errorf("failed to set %s: %w", v.Key, err)
+		}
+		if !slices.Contains(set, v.Key) {
+			set = append(set, v.Key)
+		}
+	}
+	return set, nil
+}
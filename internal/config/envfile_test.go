@@ -0,0 +1,123 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseEnvFile(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+		want EnvVar
+	}{
+		{"plain", "VSB_API_KEY=abc", EnvVar{Key: "VSB_API_KEY", Value: "abc"}},
+		{"spaces around", "  VSB_API_KEY = abc  ", EnvVar{Key: "VSB_API_KEY", Value: "abc"}},
+		{"empty value", "VSB_API_KEY=", EnvVar{Key: "VSB_API_KEY"}},
+		{"unquoted keeps #", "VSB_API_KEY=a#b # c", EnvVar{Key: "VSB_API_KEY", Value: "a#b # c"}},
+		{"double quoted", `VSB_API_KEY="a b"`, EnvVar{Key: "VSB_API_KEY", Value: "a b"}},
+		{"double quoted escapes", `VSB_API_KEY="a\"b\n"`, EnvVar{Key: "VSB_API_KEY", Value: "a\"b\n"}},
+		{"single quoted is literal", `VSB_API_KEY='a\n"b'`, EnvVar{Key: "VSB_API_KEY", Value: `a\n"b`}},
+		{"comment after quotes", `VSB_API_KEY="abc" # prod`, EnvVar{Key: "VSB_API_KEY", Value: "abc"}},
+		{"export", "export VSB_API_KEY=abc", EnvVar{Key: "VSB_API_KEY", Value: "abc"}},
+		{"override", "override VSB_API_KEY=abc", EnvVar{Key: "VSB_API_KEY", Value: "abc", Override: true}},
+		{"override export", "override export VSB_API_KEY=abc", EnvVar{Key: "VSB_API_KEY", Value: "abc", Override: true}},
+		{"variable named override", "override=1", EnvVar{Key: "override", Value: "1"}},
+		{"value with =", "VSB_PROXY=http://u:p@host/?a=b", EnvVar{Key: "VSB_PROXY", Value: "http://u:p@host/?a=b"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vars, err := ParseEnvFile([]byte(tt.line))
+			require.NoError(t, err)
+			require.Len(t, vars, 1)
+			assert.Equal(t, tt.want, vars[0])
+		})
+	}
+}
+
+func TestParseEnvFileSkipsComments(t *testing.T) {
+	data := "\ufeff# credentials\n\n  # indented comment\r\nVSB_API_KEY=abc\r\n"
+	vars, err := ParseEnvFile([]byte(data))
+	require.NoError(t, err)
+	assert.Equal(t, []EnvVar{{Key: "VSB_API_KEY", Value: "abc"}}, vars)
+}
+
+func TestParseEnvFileErrors(t *testing.T) {
+	tests := []struct {
+		data string
+		want string
+	}{
+		{"# ok\nVSB_API_KEY", "line 2: expected KEY=VALUE"},
+		{"1VSB=abc", `line 1: invalid variable name "1VSB"`},
+		{"VSB API=abc", `line 1: invalid variable name "VSB API"`},
+		{`VSB_API_KEY="abc`, "line 1: VSB_API_KEY: unterminated quoted value"},
+		{`VSB_API_KEY='abc' def`, "line 1: VSB_API_KEY: unexpected text after quoted value: def"},
+		{`VSB_API_KEY="\q"`, `line 1: VSB_API_KEY: invalid quoted value: "\q"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			_, err := ParseEnvFile([]byte(tt.data))
+			assert.EqualError(t, err, tt.want)
+		})
+	}
+}
+
+func TestLoadEnvFile(t *testing.T) {
+	write := func(t *testing.T, data string) string {
+		path := filepath.Join(t.TempDir(), ".vsb.env")
+		require.NoError(t, os.WriteFile(path, []byte(data), 0600))
+		return path
+	}
+
+	t.Run("sets unset and empty variables", func(t *testing.T) {
+		t.Setenv("VSB_TEST_UNSET", "")
+		os.Unsetenv("VSB_TEST_UNSET")
+		t.Setenv("VSB_TEST_EMPTY", "")
+
+		set, err := LoadEnvFile(write(t, "VSB_TEST_UNSET=a\nVSB_TEST_EMPTY=b\n"))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"VSB_TEST_UNSET", "VSB_TEST_EMPTY"}, set)
+		assert.Equal(t, "a", os.Getenv("VSB_TEST_UNSET"))
+		assert.Equal(t, "b", os.Getenv("VSB_TEST_EMPTY"))
+	})
+
+	t.Run("keeps existing variables unless marked override", func(t *testing.T) {
+		t.Setenv("VSB_TEST_KEPT", "shell")
+		t.Setenv("VSB_TEST_OVERRIDDEN", "shell")
+
+		set, err := LoadEnvFile(write(t, "VSB_TEST_KEPT=file\noverride VSB_TEST_OVERRIDDEN=file\n"))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"VSB_TEST_OVERRIDDEN"}, set)
+		assert.Equal(t, "shell", os.Getenv("VSB_TEST_KEPT"))
+		assert.Equal(t, "file", os.Getenv("VSB_TEST_OVERRIDDEN"))
+	})
+
+	t.Run("later lines win", func(t *testing.T) {
+		t.Setenv("VSB_TEST_REPEATED", "")
+
+		set, err := LoadEnvFile(write(t, "VSB_TEST_REPEATED=first\nVSB_TEST_REPEATED=second\n"))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"VSB_TEST_REPEATED"}, set)
+		assert.Equal(t, "second", os.Getenv("VSB_TEST_REPEATED"))
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		_, err := LoadEnvFile(filepath.Join(t.TempDir(), "missing.env"))
+		assert.ErrorContains(t, err, "failed to read env file")
+	})
+
+	t.Run("invalid file sets nothing", func(t *testing.T) {
+		t.Setenv("VSB_TEST_PARTIAL", "")
+		path := write(t, "VSB_TEST_PARTIAL=a\nbroken\n")
+
+		_, err := LoadEnvFile(path)
+		assert.EqualError(t, err, "invalid env file "+path+": line 2: expected KEY=VALUE")
+		assert.Empty(t, os.Getenv("VSB_TEST_PARTIAL"))
+	})
+}
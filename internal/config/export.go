@@ -0,0 +1,94 @@
+package config
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// checksumPrefix identifies the hash algorithm used for export checksums
+const checksumPrefix = "sha256:"
+
+var (
+	// ErrExportCorrupted is returned when an export file's checksum does not
+	// match its contents.
+	ErrExportCorrupted = errors.New("export file corrupted or modified")
+	// ErrExportUnsigned is returned when a signature is required but missing.
+	ErrExportUnsigned = errors.New("export file is not signed")
+	// ErrExportSignature is returned when a signature does not verify.
+	ErrExportSignature = errors.New("export file signature verification failed")
+)
+
+// canonicalPayload is the serialization covered by the checksum and the
+// signature: the export with both of those fields cleared.
+func (e *ExportedInboxFile) canonicalPayload() ([]byte, error) {
+	payload := *e
+	payload.Checksum = ""
+	payload.Signature = ""
+	return json.Marshal(payload)
+}
+
+// Seal sets the checksum over the canonical payload.
+func (e *ExportedInboxFile) Seal() error {
+	payload, err := e.canonicalPayload()
+	if err != nil {
+		return err
+	}
+	sum := sha256.Sum256(payload)
+	e.Checksum = checksumPrefix + hex.EncodeToString(sum[:])
+	return nil
+}
+
+// VerifyChecksum checks the checksum when present. Files exported before
+// checksums were added have none and are accepted.
+func (e *ExportedInboxFile) VerifyChecksum() error {
+	if e.Checksum == "" {
+		return nil
+	}
+	if !strings.HasPrefix(e.Checksum, checksumPrefix) {
+		return fmt.Errorf("%w: unsupported checksum %q", ErrExportCorrupted, e.Checksum)
+	}
+
+	want := *e
+	if err := want.Seal(); err != nil {
+		return err
+	}
+	if want.Checksum != e.Checksum {
+		return ErrExportCorrupted
+	}
+	return nil
+}
+
+// Sign sets an Ed25519 signature over the canonical payload.
+func (e *ExportedInboxFile) Sign(key ed25519.PrivateKey) error {
+	payload, err := e.canonicalPayload()
+	if err != nil {
+		return err
+	}
+	e.Signature = base64.StdEncoding.EncodeToString(ed25519.Sign(key, payload))
+	return nil
+}
+
+// VerifySignature checks the Ed25519 signature against key.
+func (e *ExportedInboxFile) VerifySignature(key ed25519.PublicKey) error {
+	if e.Signature == "" {
+		return ErrExportUnsigned
+	}
+	sig, err := base64.StdEncoding.DecodeString(e.Signature)
+	if err != nil {
+		return fmt.Errorf("%w: malformed signature", ErrExportSignature)
+	}
+	payload, err := e.canonicalPayload()
+	if err != nil {
+		return err
+	}
+	if !ed25519.Verify(key, payload, sig) {
+		return ErrExportSignature
+	}
+	return nil
+}
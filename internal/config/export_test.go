@@ -0,0 +1,94 @@
+package config
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestExport() ExportedInboxFile {
+	return ExportedInboxFile{
+		Version:      1,
+		EmailAddress: "test@example.com",
+		InboxHash:    "hash123",
+		ExpiresAt:    time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC),
+		ExportedAt:   time.Now(),
+		Keys: ExportedKeys{
+			KEMPrivate:  "cHJpdmF0ZS1rZXk",
+			KEMPublic:   "cHVibGljLWtleQ",
+			ServerSigPK: "c2VydmVyLWtleQ",
+		},
+		Encrypted: true,
+	}
+}
+
+// roundTrip marshals and parses an export like a file on disk
+func roundTrip(t *testing.T, e ExportedInboxFile) ExportedInboxFile {
+	t.Helper()
+	data, err := json.MarshalIndent(e, "", "  ")
+	require.NoError(t, err)
+	var out ExportedInboxFile
+	require.NoError(t, json.Unmarshal(data, &out))
+	return out
+}
+
+func TestExportChecksum(t *testing.T) {
+	t.Run("sealed export verifies after round trip", func(t *testing.T) {
+		e := newTestExport()
+		require.NoError(t, e.Seal())
+		assert.Regexp(t, `^sha256:[0-9a-f]{64}$`, e.Checksum)
+
+		parsed := roundTrip(t, e)
+		assert.NoError(t, parsed.VerifyChecksum())
+	})
+
+	t.Run("missing checksum is accepted", func(t *testing.T) {
+		e := newTestExport()
+		assert.NoError(t, e.VerifyChecksum())
+	})
+
+	t.Run("modified field fails", func(t *testing.T) {
+		e := newTestExport()
+		require.NoError(t, e.Seal())
+		e.Keys.KEMPrivate = "dGFtcGVyZWQ"
+		assert.ErrorIs(t, e.VerifyChecksum(), ErrExportCorrupted)
+	})
+
+	t.Run("unknown algorithm fails", func(t *testing.T) {
+		e := newTestExport()
+		e.Checksum = "md5:abc"
+		assert.ErrorIs(t, e.VerifyChecksum(), ErrExportCorrupted)
+	})
+}
+
+func TestExportSignature(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	otherPub, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	e := newTestExport()
+	require.NoError(t, e.Sign(priv))
+	require.NoError(t, e.Seal())
+	parsed := roundTrip(t, e)
+
+	assert.NoError(t, parsed.VerifyChecksum(), "signature is not covered by the checksum")
+	assert.NoError(t, parsed.VerifySignature(pub))
+	assert.ErrorIs(t, parsed.VerifySignature(otherPub), ErrExportSignature)
+
+	tampered := parsed
+	tampered.EmailAddress = "other@example.com"
+	assert.ErrorIs(t, tampered.VerifySignature(pub), ErrExportSignature)
+
+	unsigned := newTestExport()
+	assert.ErrorIs(t, unsigned.VerifySignature(pub), ErrExportUnsigned)
+
+	malformed := parsed
+	malformed.Signature = "!!!"
+	assert.ErrorIs(t, malformed.VerifySignature(pub), ErrExportSignature)
+}
@@ -0,0 +1,87 @@
+package config
+
+import (
+	"errors"
+	"sort"
+)
+
+// ErrPresetNotFound is returned when an inbox has no filter preset with the
+// requested name
+var ErrPresetNotFound = errors.New("filter preset not found")
+
+// FilterPreset is a named set of email filters stored on an inbox. --preset
+// expands it into the flags of the same name on 'email wait' and 'email
+// list'.
+type FilterPreset struct {
+	Subject      string `json:"subject,omitempty"`
+	SubjectRegex string `json:"subjectRegex,omitempty"`
+	From         string `json:"from,omitempty"`
+	FromRegex    string `json:"fromRegex,omitempty"`
+	BodyRegex    string `json:"bodyRegex,omitempty"`
+}
+
+// Flags returns the preset's filters keyed by flag name, leaving out unset
+// ones.
+func (p FilterPreset) Flags() map[string]string {
+	flags := map[string]string{}
+	for name, value := range map[string]string{
+		"subject":       p.Subject,
+		"subject-regex": p.SubjectRegex,
+		"from":          p.From,
+		"from-regex":    p.FromRegex,
+		"body-regex":    p.BodyRegex,
+	} {
+		if value != "" {
+			flags[name] = value
+		}
+	}
+	return flags
+}
+
+// IsEmpty reports whether the preset sets no filter.
+func (p FilterPreset) IsEmpty() bool {
+	return p == FilterPreset{}
+}
+
+// PresetNames returns the names of the inbox's filter presets, sorted.
+func (s *StoredInbox) PresetNames() []string {
+	names := make([]string, 0, len(s.Filters))
+	for name := range s.Filters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SetFilterPreset adds or replaces the named filter preset of an inbox.
+func (ks *Keystore) SetFilterPreset(email, name string, preset FilterPreset) error {
+	return ks.update(func() error {
+		inbox := ks.findInboxLocked(email)
+		if inbox == nil {
+			return ErrInboxNotFound
+		}
+		if inbox.Filters == nil {
+			inbox.Filters = map[string]FilterPreset{}
+		}
+		inbox.Filters[name] = preset
+		return nil
+	})
+}
+
+// DeleteFilterPreset removes the named filter preset of an inbox.
+func (ks *Keystore) DeleteFilterPreset(email, name string) error {
+	return ks.update(func() error {
+		inbox := ks.findInboxLocked(email)
+		if inbox == nil {
+			return ErrInboxNotFound
+		}
+		if _, ok := inbox.Filters[name]; !ok {
+			return ErrPresetNotFound
+		}
+		delete(inbox.Filters, name)
+		if len(inbox.Filters) == 0 {
+			inbox.Filters = nil
+		}
+		return nil
+	})
+}
@@ -0,0 +1,86 @@
+package config
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilterPresetFlags(t *testing.T) {
+	p := FilterPreset{From: "billing@ourapp.com", SubjectRegex: "Invoice"}
+	assert.Equal(t, map[string]string{
+		"from":          "billing@ourapp.com",
+		"subject-regex": "Invoice",
+	}, p.Flags())
+	assert.False(t, p.IsEmpty())
+	assert.True(t, FilterPreset{}.IsEmpty())
+}
+
+func TestFilterPresets(t *testing.T) {
+	ks, _ := setupKeystore(t)
+	inbox := testStoredInbox("presets@example.com", 24*time.Hour)
+	require.NoError(t, ks.AddInbox(inbox))
+
+	t.Run("set and reload", func(t *testing.T) {
+		require.NoError(t, ks.SetFilterPreset(inbox.Email, "billing", FilterPreset{From: "billing@ourapp.com"}))
+		require.NoError(t, ks.SetFilterPreset(inbox.Email, "auth", FilterPreset{SubjectRegex: "code"}))
+
+		reloaded, err := LoadKeystore()
+		require.NoError(t, err)
+		stored, err := reloaded.GetInbox(inbox.Email)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"auth", "billing"}, stored.PresetNames())
+		assert.Equal(t, "billing@ourapp.com", stored.Filters["billing"].From)
+	})
+
+	t.Run("set replaces", func(t *testing.T) {
+		require.NoError(t, ks.SetFilterPreset(inbox.Email, "billing", FilterPreset{Subject: "Receipt"}))
+		stored, err := ks.GetInbox(inbox.Email)
+		require.NoError(t, err)
+		assert.Equal(t, FilterPreset{Subject: "Receipt"}, stored.Filters["billing"])
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		require.NoError(t, ks.DeleteFilterPreset(inbox.Email, "billing"))
+		assert.ErrorIs(t, ks.DeleteFilterPreset(inbox.Email, "billing"), ErrPresetNotFound)
+
+		require.NoError(t, ks.DeleteFilterPreset(inbox.Email, "auth"))
+		stored, err := ks.GetInbox(inbox.Email)
+		require.NoError(t, err)
+		assert.Nil(t, stored.Filters)
+	})
+
+	t.Run("unknown inbox", func(t *testing.T) {
+		err := ks.SetFilterPreset("missing@example.com", "x", FilterPreset{From: "a"})
+		assert.ErrorIs(t, err, ErrInboxNotFound)
+		assert.ErrorIs(t, ks.DeleteFilterPreset("missing@example.com", "x"), ErrInboxNotFound)
+	})
+}
+
+func TestFilterPresetsExport(t *testing.T) {
+	t.Run("roundtrip", func(t *testing.T) {
+		stored := testStoredInbox("export@example.com", 24*time.Hour)
+		stored.Filters = map[string]FilterPreset{"billing": {From: "billing@ourapp.com"}}
+
+		exportFile := stored.ToExportFile()
+		require.NoError(t, exportFile.Seal())
+		data, err := json.Marshal(exportFile)
+		require.NoError(t, err)
+
+		var imported ExportedInboxFile
+		require.NoError(t, json.Unmarshal(data, &imported))
+		require.NoError(t, imported.VerifyChecksum())
+		assert.Equal(t, stored.Filters, imported.ToStoredInbox().Filters)
+	})
+
+	t.Run("omitted without presets", func(t *testing.T) {
+		stored := testStoredInbox("plain@example.com", 24*time.Hour)
+		exportFile := stored.ToExportFile()
+		data, err := json.Marshal(exportFile)
+		require.NoError(t, err)
+		assert.NotContains(t, string(data), "filters")
+	})
+}
@@ -0,0 +1,76 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/vaultsandbox/vsb-cli/internal/keychain"
+)
+
+// APIKeyKeychainRef is written to config.yaml in place of the API key when
+// the key is stored in the system keychain.
+const APIKeyKeychainRef = "keychain:vsb/api-key"
+
+// keychainAccount is the account name the API key is stored under
+const keychainAccount = "api-key"
+
+// IsKeychainRef reports whether a config value refers to the system keychain.
+func IsKeychainRef(value string) bool {
+	return value == APIKeyKeychainRef
+}
+
+// APIKeyInKeychain reports whether the config file stores the API key in the
+// system keychain.
+func APIKeyInKeychain() bool {
+	return IsKeychainRef(current.APIKey)
+}
+
+// ResolveAPIKey returns the API key with priority: --api-key-file > env >
+// api-key-command > config file. A keychain reference in the config file is
+// resolved from the system keychain.
+func ResolveAPIKey() (string, error) {
+	if apiKeyFileOverride != "" {
+		return readAPIKeyFile(apiKeyFileOverride)
+	}
+	if env := os.Getenv("VSB_API_KEY"); env != "" {
+		return env, nil
+	}
+	if command := GetAPIKeyCommand(); command != "" {
+		return runAPIKeyCommand(command)
+	}
+	if !IsKeychainRef(current.APIKey) {
+		return current.APIKey, nil
+	}
+	key, err := ReadKeychainAPIKey()
+	if err != nil {
+		return "", fmt.Errorf("failed to read API key from system keychain (set VSB_API_KEY instead): %w", err)
+	}
+	return key, nil
+}
+
+// ReadKeychainAPIKey reads the API key from the system keychain.
+func ReadKeychainAPIKey() (string, error) {
+	return keychain.Default.Get(keychain.Service, keychainAccount)
+}
+
+// StoreKeychainAPIKey saves the API key in the system keychain.
+func StoreKeychainAPIKey(key string) error {
+	if err := keychain.Default.Set(keychain.Service, keychainAccount, key); err != nil {
+		if errors.Is(err, keychain.ErrUnavailable) {
+			return fmt.Errorf("failed to store API key: %w (set VSB_API_KEY instead)", err)
+		}
+		return fmt.Errorf("failed to store API key in system keychain: %w", err)
+	}
+	return nil
+}
+
+// DeleteKeychainAPIKey removes the API key from the system keychain. A
+// missing entry is not an error.
+func DeleteKeychainAPIKey() error {
+	err := keychain.Default.Delete(keychain.Service, keychainAccount)
+	if err != nil && !errors.Is(err, keychain.ErrNotFound) {
+		return fmt.Errorf("failed to remove API key from system keychain: %w", err)
+	}
+	return nil
+}
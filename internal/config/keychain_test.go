@@ -0,0 +1,78 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vaultsandbox/vsb-cli/internal/keychain"
+)
+
+// useMemoryKeyring swaps the system keychain for an in-memory one.
+func useMemoryKeyring(t *testing.T) *keychain.MemoryKeyring {
+	original := keychain.Default
+	t.Cleanup(func() { keychain.Default = original })
+	kr := keychain.NewMemoryKeyring()
+	keychain.Default = kr
+	return kr
+}
+
+func TestResolveAPIKey(t *testing.T) {
+	originalCurrent := current
+	t.Cleanup(func() { current = originalCurrent })
+
+	t.Run("plain value from config", func(t *testing.T) {
+		t.Setenv("VSB_API_KEY", "")
+		current = Config{APIKey: "file-key"}
+
+		key, err := ResolveAPIKey()
+		require.NoError(t, err)
+		assert.Equal(t, "file-key", key)
+	})
+
+	t.Run("keychain reference", func(t *testing.T) {
+		t.Setenv("VSB_API_KEY", "")
+		kr := useMemoryKeyring(t)
+		require.NoError(t, StoreKeychainAPIKey("keychain-key"))
+		current = Config{APIKey: APIKeyKeychainRef}
+
+		key, err := ResolveAPIKey()
+		require.NoError(t, err)
+		assert.Equal(t, "keychain-key", key)
+		assert.True(t, APIKeyInKeychain())
+
+		kr.Err = keychain.ErrUnavailable
+		_, err = ResolveAPIKey()
+		assert.ErrorIs(t, err, keychain.ErrUnavailable)
+		assert.Contains(t, err.Error(), "VSB_API_KEY")
+		assert.Empty(t, GetAPIKey())
+	})
+
+	t.Run("env overrides keychain", func(t *testing.T) {
+		t.Setenv("VSB_API_KEY", "env-key")
+		kr := useMemoryKeyring(t)
+		kr.Err = keychain.ErrUnavailable
+		current = Config{APIKey: APIKeyKeychainRef}
+
+		key, err := ResolveAPIKey()
+		require.NoError(t, err)
+		assert.Equal(t, "env-key", key)
+	})
+}
+
+func TestKeychainAPIKey(t *testing.T) {
+	kr := useMemoryKeyring(t)
+
+	require.NoError(t, StoreKeychainAPIKey("secret"))
+	key, err := ReadKeychainAPIKey()
+	require.NoError(t, err)
+	assert.Equal(t, "secret", key)
+
+	require.NoError(t, DeleteKeychainAPIKey())
+	require.NoError(t, DeleteKeychainAPIKey(), "missing entry is not an error")
+
+	kr.Err = keychain.ErrUnavailable
+	err = StoreKeychainAPIKey("secret")
+	assert.ErrorIs(t, err, keychain.ErrUnavailable)
+	assert.Contains(t, err.Error(), "VSB_API_KEY")
+}
@@ -3,6 +3,7 @@ package config
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
@@ -27,6 +28,16 @@ type StoredInbox struct {
 	Keys      InboxKeys `json:"keys"`
 	Encrypted bool      `json:"encrypted"`  // whether inbox uses encryption
 	EmailAuth bool      `json:"emailAuth"`  // whether email auth is enabled
+
+	// Filters are the inbox's named filter presets; see FilterPreset
+	Filters map[string]FilterPreset `json:"filters,omitempty"`
+
+	// Tags group inboxes by purpose; kept sorted and without duplicates
+	Tags []string `json:"tags,omitempty"`
+
+	// ExportedAt is when the export the inbox was imported from was
+	// written; zero for inboxes created locally
+	ExportedAt time.Time `json:"exportedAt,omitempty"`
 }
 
 // InboxKeys contains the cryptographic keys for an inbox
@@ -46,8 +57,28 @@ type ExportedInboxFile struct {
 	Keys         ExportedKeys `json:"keys"`
 	Encrypted    bool         `json:"encrypted"`
 	EmailAuth    bool         `json:"emailAuth"`
+	Checksum     string       `json:"checksum,omitempty"`  // see Seal
+	Signature    string       `json:"signature,omitempty"` // see Sign
+
+	// Filters is omitted when empty, so exports without presets are
+	// unchanged
+	Filters map[string]FilterPreset `json:"filters,omitempty"`
+
+	// Tags need export format version 2; see ToExportFile
+	Tags []string `json:"tags,omitempty"`
 }
 
+// Export file format versions. Version 2 adds tags; exports of untagged
+// inboxes are still written as version 1 so older vsb releases can import
+// them.
+const (
+	ExportVersion     = 1
+	ExportVersionTags = 2
+
+	// ExportVersionLatest is the newest format this vsb reads
+	ExportVersionLatest = ExportVersionTags
+)
+
 // ExportedKeys contains the cryptographic keys in an export file
 type ExportedKeys struct {
 	KEMPrivate  string `json:"kemPrivate"`
@@ -57,11 +88,18 @@ type ExportedKeys struct {
 
 // Keystore manages inbox persistence
 type Keystore struct {
+	Version     int           `json:"version"` // see KeystoreSchemaVersion
 	Inboxes     []StoredInbox `json:"inboxes"`
 	ActiveInbox string        `json:"active_inbox"` // email address
 
-	mu   sync.RWMutex
-	path string
+	mu     sync.RWMutex
+	path   string
+	pruned []StoredInbox // inboxes removed by pruneExpired during load
+
+	// The file as read, if it had an older schema version: it is backed up
+	// before the upgraded keystore first overwrites it
+	legacy        []byte
+	legacyVersion int
 }
 
 // keystorePath returns the path to keystore.json
@@ -85,16 +123,33 @@ func LoadKeystore() (*Keystore, error) {
 		path:    path,
 	}
 
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		// New keystore
+		return ks, nil
+	}
+
+	// Hold the file lock so we never read a half-written file
+	unlock, err := ks.lock()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
 	data, err := os.ReadFile(path)
 	if os.IsNotExist(err) {
-		// New keystore
 		return ks, nil
 	}
 	if err != nil {
 		return nil, err
 	}
 
-	if err := json.Unmarshal(data, ks); err != nil {
+	// Older keystores are upgraded in memory; 'vsb config migrate' or the
+	// next save writes the upgrade back, after backing up the old file
+	upgraded, err := ks.upgradeLocked(data)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(upgraded, ks); err != nil {
 		return nil, err
 	}
 	ks.path = path
@@ -109,21 +164,40 @@ func LoadKeystore() (*Keystore, error) {
 func (ks *Keystore) Save() error {
 	ks.mu.Lock()
 	defer ks.mu.Unlock()
+
+	unlock, err := ks.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
 	return ks.saveLocked()
 }
 
 // AddInbox adds a new inbox to the keystore
 func (ks *Keystore) AddInbox(inbox StoredInbox) error {
-	ks.mu.Lock()
-	defer ks.mu.Unlock()
-
-	// Remove existing inbox with same email (update)
-	ks.removeInboxLocked(inbox.Email)
+	return ks.update(func() error {
+		// Remove existing inbox with same email (update)
+		ks.removeInboxLocked(inbox.Email)
+
+		ks.Inboxes = append(ks.Inboxes, inbox)
+		ks.ActiveInbox = inbox.Email
+		return nil
+	})
+}
 
-	ks.Inboxes = append(ks.Inboxes, inbox)
-	ks.ActiveInbox = inbox.Email
+// PutInbox adds or replaces an inbox like AddInbox, but only makes it active
+// if activate is set or no inbox is active yet
+func (ks *Keystore) PutInbox(inbox StoredInbox, activate bool) error {
+	return ks.update(func() error {
+		ks.removeInboxLocked(inbox.Email)
 
-	return ks.saveLocked()
+		ks.Inboxes = append(ks.Inboxes, inbox)
+		if activate || ks.ActiveInbox == "" || !ks.inboxExistsLocked(ks.ActiveInbox) {
+			ks.ActiveInbox = inbox.Email
+		}
+		return nil
+	})
 }
 
 // SaveInbox saves an exported inbox to the keystore
@@ -148,35 +222,66 @@ func (ks *Keystore) GetInbox(email string) (*StoredInbox, error) {
 // ErrMultipleMatches is returned when a partial match finds multiple inboxes
 var ErrMultipleMatches = errors.New("multiple inboxes match")
 
-// FindInbox retrieves an inbox by partial email match.
-// Returns the inbox if exactly one matches, error if none or multiple match.
+// FindInbox retrieves an inbox by identifier (see MatchInboxes for the
+// resolution order). Returns the inbox if exactly one matches, error if none
+// or multiple match; on ambiguity the matching addresses are returned.
 func (ks *Keystore) FindInbox(partial string) (*StoredInbox, []string, error) {
 	ks.mu.RLock()
 	defer ks.mu.RUnlock()
 
-	// Single-pass: check exact and collect partial matches together
-	var matches []*StoredInbox
-	var matchEmails []string
-	for i := range ks.Inboxes {
-		if ks.Inboxes[i].Email == partial {
-			// Exact match - return immediately
-			return &ks.Inboxes[i], nil, nil
-		}
-		if strings.Contains(ks.Inboxes[i].Email, partial) {
-			matches = append(matches, &ks.Inboxes[i])
-			matchEmails = append(matchEmails, ks.Inboxes[i].Email)
-		}
-	}
-
-	if len(matches) == 0 {
+	matches := MatchInboxes(ks.Inboxes, partial)
+	switch len(matches) {
+	case 0:
 		return nil, nil, ErrInboxNotFound
+	case 1:
+		return &ks.Inboxes[matches[0]], nil, nil
 	}
-	if len(matches) == 1 {
-		return matches[0], nil, nil
+
+	matchEmails := make([]string, len(matches))
+	for i, idx := range matches {
+		matchEmails[i] = ks.Inboxes[idx].Email
 	}
 	return nil, matchEmails, ErrMultipleMatches
 }
 
+// MatchInboxes resolves an inbox identifier, trying each tier in order and
+// stopping at the first tier with any match:
+//
+//  1. exact email address
+//  2. exact label (case-insensitive)
+//  3. prefix of the address local part
+//  4. substring of the address (fallback)
+//
+// Returns the indexes of the matching inboxes.
+func MatchInboxes(inboxes []StoredInbox, query string) []int {
+	if query == "" {
+		return nil
+	}
+
+	tiers := []func(StoredInbox) bool{
+		func(in StoredInbox) bool { return in.Email == query },
+		func(in StoredInbox) bool { return in.Label != "" && strings.EqualFold(in.Label, query) },
+		func(in StoredInbox) bool {
+			local, _, _ := strings.Cut(in.Email, "@")
+			return strings.HasPrefix(local, query)
+		},
+		func(in StoredInbox) bool { return strings.Contains(in.Email, query) },
+	}
+
+	for _, match := range tiers {
+		var matches []int
+		for i := range inboxes {
+			if match(inboxes[i]) {
+				matches = append(matches, i)
+			}
+		}
+		if len(matches) > 0 {
+			return matches
+		}
+	}
+	return nil
+}
+
 // GetActiveInbox returns the currently active inbox
 func (ks *Keystore) GetActiveInbox() (*StoredInbox, error) {
 	ks.mu.RLock()
@@ -196,36 +301,40 @@ func (ks *Keystore) GetActiveInbox() (*StoredInbox, error) {
 
 // SetActiveInbox changes the active inbox
 func (ks *Keystore) SetActiveInbox(email string) error {
-	ks.mu.Lock()
-	defer ks.mu.Unlock()
-
-	if !ks.inboxExistsLocked(email) {
-		return ErrInboxNotFound
-	}
+	return ks.update(func() error {
+		if !ks.inboxExistsLocked(email) {
+			return ErrInboxNotFound
+		}
 
-	ks.ActiveInbox = email
-	return ks.saveLocked()
+		ks.ActiveInbox = email
+		return nil
+	})
 }
 
-// RemoveInbox removes an inbox by email address
+// RemoveInbox removes an inbox by email address, along with its local read
+// state
 func (ks *Keystore) RemoveInbox(email string) error {
-	ks.mu.Lock()
-	defer ks.mu.Unlock()
-
-	if !ks.removeInboxLocked(email) {
-		return ErrInboxNotFound
-	}
+	err := ks.update(func() error {
+		if !ks.removeInboxLocked(email) {
+			return ErrInboxNotFound
+		}
 
-	// Clear active if it was this inbox
-	if ks.ActiveInbox == email {
-		if len(ks.Inboxes) > 0 {
-			ks.ActiveInbox = ks.Inboxes[0].Email
-		} else {
-			ks.ActiveInbox = ""
+		// Clear active if it was this inbox
+		if ks.ActiveInbox == email {
+			if len(ks.Inboxes) > 0 {
+				ks.ActiveInbox = ks.Inboxes[0].Email
+			} else {
+				ks.ActiveInbox = ""
+			}
 		}
+		return nil
+	})
+	if err != nil {
+		return err
 	}
-
-	return ks.saveLocked()
+	// Stale read state only costs disk space, so don't fail the removal
+	RemoveReadState(email)
+	return nil
 }
 
 // ListInboxes returns all stored inboxes
@@ -239,18 +348,44 @@ func (ks *Keystore) ListInboxes() []StoredInbox {
 	return result
 }
 
-// pruneExpired removes expired inboxes (internal, no locking - used during load)
+// PrunedInboxes returns the expired inboxes that were removed when the keystore was loaded
+func (ks *Keystore) PrunedInboxes() []StoredInbox {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+
+	result := make([]StoredInbox, len(ks.pruned))
+	copy(result, ks.pruned)
+	return result
+}
+
+// pruneExpired removes expired inboxes (internal, no locking - used during load).
+// See pruneCutoff for when an inbox counts as expired here.
 func (ks *Keystore) pruneExpired() {
-	now := time.Now()
-	active := []StoredInbox{}
+	ks.pruned = ks.removeExpiredLocked(pruneCutoff())
+	if len(ks.pruned) > 0 {
+		// Save changes silently
+		ks.saveLocked()
+		for _, inbox := range ks.pruned {
+			RemoveReadState(inbox.Email)
+		}
+	}
+}
 
+// removeExpiredLocked removes the inboxes that expired at or before cutoff
+// and returns them. If the active inbox is among them, the first remaining
+// inbox becomes active.
+func (ks *Keystore) removeExpiredLocked(cutoff time.Time) []StoredInbox {
+	var removed []StoredInbox
+	active := []StoredInbox{}
 	for _, inbox := range ks.Inboxes {
-		if inbox.ExpiresAt.After(now) {
+		if inbox.ExpiresAt.After(cutoff) {
 			active = append(active, inbox)
+		} else {
+			removed = append(removed, inbox)
 		}
 	}
 
-	if len(active) < len(ks.Inboxes) {
+	if len(removed) > 0 {
 		ks.Inboxes = active
 
 		// Fix active inbox if it was pruned
@@ -261,14 +396,88 @@ func (ks *Keystore) pruneExpired() {
 				ks.ActiveInbox = ""
 			}
 		}
-
-		// Save changes silently
-		ks.saveLocked()
 	}
+	return removed
 }
 
 // Internal helpers
 
+// lock takes the cross-process keystore file lock. Separate vsb processes
+// (e.g. parallel CI jobs) share no memory, so the in-process mutex alone
+// cannot stop them from overwriting each other's changes.
+func (ks *Keystore) lock() (func(), error) {
+	if err := EnsureDir(); err != nil {
+		return nil, err
+	}
+	return lockFile(ks.path + ".lock")
+}
+
+// update applies fn to the latest on-disk state and saves the result, holding
+// both the mutex and the file lock for the whole read-modify-write.
+func (ks *Keystore) update(fn func() error) error {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+
+	unlock, err := ks.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if err := ks.reloadLocked(); err != nil {
+		return err
+	}
+	if err := fn(); err != nil {
+		return err
+	}
+	return ks.saveLocked()
+}
+
+// reloadLocked replaces the in-memory inboxes with the file contents so
+// writes by other processes since load are not lost. A missing file keeps
+// the in-memory state.
+func (ks *Keystore) reloadLocked() error {
+	data, err := os.ReadFile(ks.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	data, err = ks.upgradeLocked(data)
+	if err != nil {
+		return err
+	}
+	var disk struct {
+		Inboxes     []StoredInbox `json:"inboxes"`
+		ActiveInbox string        `json:"active_inbox"`
+	}
+	if err := json.Unmarshal(data, &disk); err != nil {
+		return err
+	}
+	if disk.Inboxes == nil {
+		disk.Inboxes = []StoredInbox{}
+	}
+	ks.Inboxes = disk.Inboxes
+	ks.ActiveInbox = disk.ActiveInbox
+	return nil
+}
+
+// upgradeLocked upgrades the keystore file contents data to the current
+// schema version, remembering the original for saveLocked to back up
+func (ks *Keystore) upgradeLocked(data []byte) ([]byte, error) {
+	upgraded, from, changes, err := upgradeKeystore(data)
+	if err != nil {
+		return nil, err
+	}
+	ks.legacy, ks.legacyVersion = nil, 0
+	if len(changes) > 0 {
+		ks.legacy, ks.legacyVersion = data, from
+	}
+	return upgraded, nil
+}
+
 func (ks *Keystore) inboxExistsLocked(email string) bool {
 	for i := range ks.Inboxes {
 		if ks.Inboxes[i].Email == email {
@@ -278,6 +487,15 @@ func (ks *Keystore) inboxExistsLocked(email string) bool {
 	return false
 }
 
+func (ks *Keystore) findInboxLocked(email string) *StoredInbox {
+	for i := range ks.Inboxes {
+		if ks.Inboxes[i].Email == email {
+			return &ks.Inboxes[i]
+		}
+	}
+	return nil
+}
+
 func (ks *Keystore) removeInboxLocked(email string) bool {
 	for i, inbox := range ks.Inboxes {
 		if inbox.Email == email {
@@ -292,11 +510,19 @@ func (ks *Keystore) saveLocked() error {
 	if err := EnsureDir(); err != nil {
 		return err
 	}
+	if ks.legacy != nil {
+		backup := migrationBackupPath(ks.path, ks.legacyVersion)
+		if err := WriteFileAtomic(backup, ks.legacy, 0600); err != nil {
+			return fmt.Errorf("failed to back up %s: %w", ks.path, err)
+		}
+		ks.legacy = nil
+	}
+	ks.Version = KeystoreSchemaVersion
 	data, err := json.MarshalIndent(ks, "", "  ")
 	if err != nil {
 		return err
 	}
-	return os.WriteFile(ks.path, data, 0600)
+	return WriteFileAtomic(ks.path, data, 0600)
 }
 
 // StoredInboxFromExport converts SDK ExportedInbox to StoredInbox
@@ -333,8 +559,12 @@ func (s *StoredInbox) ToExportedInbox() *vaultsandbox.ExportedInbox {
 
 // ToExportFile converts StoredInbox to ExportedInboxFile for file export
 func (s *StoredInbox) ToExportFile() ExportedInboxFile {
+	version := ExportVersion
+	if len(s.Tags) > 0 {
+		version = ExportVersionTags
+	}
 	return ExportedInboxFile{
-		Version:      1,
+		Version:      version,
 		EmailAddress: s.Email,
 		InboxHash:    s.ID,
 		ExpiresAt:    s.ExpiresAt,
@@ -346,6 +576,8 @@ func (s *StoredInbox) ToExportFile() ExportedInboxFile {
 		},
 		Encrypted: s.Encrypted,
 		EmailAuth: s.EmailAuth,
+		Filters:   s.Filters,
+		Tags:      s.Tags,
 	}
 }
 
@@ -361,7 +593,49 @@ func (e *ExportedInboxFile) ToStoredInbox() StoredInbox {
 			KEMPublic:   e.Keys.KEMPublic,
 			ServerSigPK: e.Keys.ServerSigPK,
 		},
-		Encrypted: e.Encrypted,
-		EmailAuth: e.EmailAuth,
+		Encrypted:  e.Encrypted,
+		EmailAuth:  e.EmailAuth,
+		Filters:    e.Filters,
+		Tags:       normalizeTags(e.Tags),
+		ExportedAt: e.ExportedAt,
+	}
+}
+
+// UpdatedAt returns when the stored inbox data was last written: the time
+// of the export it came from, or its creation time for local inboxes.
+func (s *StoredInbox) UpdatedAt() time.Time {
+	if !s.ExportedAt.IsZero() {
+		return s.ExportedAt
 	}
+	return s.CreatedAt
+}
+
+// UpdateInboxFromExport refreshes the keys and expiry of a stored inbox
+// from a newer export, keeping its label, tags, filter presets, read state
+// and active status. It returns false without changing anything when the
+// stored inbox is as new as the export or newer.
+func (ks *Keystore) UpdateInboxFromExport(e *ExportedInboxFile) (bool, error) {
+	updated := false
+	err := ks.update(func() error {
+		inbox := ks.findInboxLocked(e.EmailAddress)
+		if inbox == nil {
+			return ErrInboxNotFound
+		}
+		if !e.ExportedAt.After(inbox.UpdatedAt()) {
+			return nil
+		}
+		inbox.ID = e.InboxHash
+		inbox.ExpiresAt = e.ExpiresAt
+		inbox.Keys = InboxKeys{
+			KEMPrivate:  e.Keys.KEMPrivate,
+			KEMPublic:   e.Keys.KEMPublic,
+			ServerSigPK: e.Keys.ServerSigPK,
+		}
+		inbox.Encrypted = e.Encrypted
+		inbox.EmailAuth = e.EmailAuth
+		inbox.ExportedAt = e.ExportedAt
+		updated = true
+		return nil
+	})
+	return updated, err
 }
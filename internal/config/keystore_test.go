@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"sync"
 	"testing"
@@ -76,6 +77,10 @@ func TestLoadKeystore(t *testing.T) {
 		ks, err := LoadKeystore()
 		require.NoError(t, err)
 		assert.Empty(t, ks.ListInboxes()) // Expired inbox pruned
+
+		pruned := ks.PrunedInboxes()
+		require.Len(t, pruned, 1)
+		assert.Equal(t, "expired@example.com", pruned[0].Email)
 	})
 
 	t.Run("prunes expired active inbox and switches to remaining", func(t *testing.T) {
@@ -191,6 +196,114 @@ func TestAddInbox(t *testing.T) {
 	})
 }
 
+func TestPutInbox(t *testing.T) {
+	t.Run("first inbox becomes active", func(t *testing.T) {
+		ks, _ := setupKeystore(t)
+		require.NoError(t, ks.PutInbox(testStoredInbox("first@example.com", 24*time.Hour), false))
+
+		active, err := ks.GetActiveInbox()
+		require.NoError(t, err)
+		assert.Equal(t, "first@example.com", active.Email)
+	})
+
+	t.Run("keeps the active inbox", func(t *testing.T) {
+		ks, _ := setupKeystore(t)
+		require.NoError(t, ks.AddInbox(testStoredInbox("current@example.com", 24*time.Hour)))
+		require.NoError(t, ks.PutInbox(testStoredInbox("other@example.com", 24*time.Hour), false))
+
+		assert.Len(t, ks.ListInboxes(), 2)
+		active, err := ks.GetActiveInbox()
+		require.NoError(t, err)
+		assert.Equal(t, "current@example.com", active.Email)
+	})
+
+	t.Run("activate switches and replaces", func(t *testing.T) {
+		ks, _ := setupKeystore(t)
+		require.NoError(t, ks.AddInbox(testStoredInbox("current@example.com", 24*time.Hour)))
+		require.NoError(t, ks.PutInbox(testStoredInbox("other@example.com", 24*time.Hour), false))
+
+		updated := testStoredInbox("other@example.com", 48*time.Hour)
+		updated.ID = "new-hash"
+		require.NoError(t, ks.PutInbox(updated, true))
+
+		assert.Len(t, ks.ListInboxes(), 2)
+		active, err := ks.GetActiveInbox()
+		require.NoError(t, err)
+		assert.Equal(t, "other@example.com", active.Email)
+		assert.Equal(t, "new-hash", active.ID)
+	})
+}
+
+func TestUpdateInboxFromExport(t *testing.T) {
+	setup := func(t *testing.T) (*Keystore, StoredInbox) {
+		ks, _ := setupKeystore(t)
+		stored := testStoredInbox("ci@example.com", time.Hour)
+		stored.Label = "ci"
+		stored.Filters = map[string]FilterPreset{"billing": {From: "billing@example.com"}}
+		stored.ExportedAt = time.Now().Add(-time.Hour)
+		require.NoError(t, ks.AddInbox(stored))
+		require.NoError(t, ks.AddInbox(testStoredInbox("other@example.com", time.Hour)))
+		return ks, stored
+	}
+	export := func(stored StoredInbox, exportedAt time.Time) *ExportedInboxFile {
+		e := stored.ToExportFile()
+		e.ExportedAt = exportedAt
+		e.ExpiresAt = stored.ExpiresAt.Add(24 * time.Hour)
+		e.Keys.KEMPrivate = "new-priv"
+		e.Filters = nil
+		return &e
+	}
+
+	t.Run("refreshes keys and expiry from a newer export", func(t *testing.T) {
+		ks, stored := setup(t)
+		e := export(stored, time.Now())
+
+		updated, err := ks.UpdateInboxFromExport(e)
+		require.NoError(t, err)
+		assert.True(t, updated)
+
+		reloaded, err := LoadKeystore()
+		require.NoError(t, err)
+		inbox, err := reloaded.GetInbox("ci@example.com")
+		require.NoError(t, err)
+		assert.Equal(t, "new-priv", inbox.Keys.KEMPrivate)
+		assert.True(t, inbox.ExpiresAt.Equal(e.ExpiresAt))
+		assert.True(t, inbox.ExportedAt.Equal(e.ExportedAt))
+		assert.Equal(t, "ci", inbox.Label)
+		assert.Contains(t, inbox.Filters, "billing")
+
+		active, err := reloaded.GetActiveInbox()
+		require.NoError(t, err)
+		assert.Equal(t, "other@example.com", active.Email, "active inbox unchanged")
+	})
+
+	t.Run("skips an export that is not newer", func(t *testing.T) {
+		ks, stored := setup(t)
+
+		for _, exportedAt := range []time.Time{stored.ExportedAt, stored.ExportedAt.Add(-time.Minute)} {
+			updated, err := ks.UpdateInboxFromExport(export(stored, exportedAt))
+			require.NoError(t, err)
+			assert.False(t, updated)
+		}
+		inbox, err := ks.GetInbox("ci@example.com")
+		require.NoError(t, err)
+		assert.Equal(t, "priv-key", inbox.Keys.KEMPrivate)
+	})
+
+	t.Run("falls back to the creation time", func(t *testing.T) {
+		stored := testStoredInbox("local@example.com", time.Hour)
+		assert.Equal(t, stored.CreatedAt, stored.UpdatedAt())
+		stored.ExportedAt = stored.CreatedAt.Add(time.Minute)
+		assert.Equal(t, stored.ExportedAt, stored.UpdatedAt())
+	})
+
+	t.Run("missing inbox", func(t *testing.T) {
+		ks, _ := setupKeystore(t)
+		_, err := ks.UpdateInboxFromExport(&ExportedInboxFile{EmailAddress: "nope@example.com", ExportedAt: time.Now()})
+		assert.ErrorIs(t, err, ErrInboxNotFound)
+	})
+}
+
 func TestGetInbox(t *testing.T) {
 	t.Run("returns inbox by exact email", func(t *testing.T) {
 		ks, _ := setupKeystore(t)
@@ -250,6 +363,39 @@ func TestFindInbox(t *testing.T) {
 	})
 }
 
+func TestMatchInboxes(t *testing.T) {
+	inboxes := []StoredInbox{
+		{Email: "12abc@vsx.email", Label: "signup"},
+		{Email: "x9y12z@vsx.email"},
+		{Email: "signup-flow@vsx.email"},
+		{Email: "ci-run-1@vsx.email", Label: "CI"},
+		{Email: "ci-run-2@vsx.email"},
+		{Email: "other@vsx.email"},
+	}
+
+	tests := []struct {
+		name  string
+		query string
+		want  []int
+	}{
+		{"exact address", "other@vsx.email", []int{5}},
+		{"label beats address prefix", "signup", []int{0}},
+		{"label is case-insensitive", "ci", []int{3}},
+		{"local-part prefix beats substring", "12", []int{0}},
+		{"ambiguous local-part prefix", "ci-run", []int{3, 4}},
+		{"substring fallback", "9y1", []int{1}},
+		{"substring matches domain", "vsx", []int{0, 1, 2, 3, 4, 5}},
+		{"no match", "nothing", nil},
+		{"empty query", "", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, MatchInboxes(inboxes, tt.query))
+		})
+	}
+}
+
 func TestGetActiveInbox(t *testing.T) {
 	t.Run("returns active inbox", func(t *testing.T) {
 		ks, _ := setupKeystore(t)
@@ -505,6 +651,46 @@ func TestKeystoreConcurrency(t *testing.T) {
 	}
 }
 
+// TestKeystoreLockHelperProcess is run as a subprocess by
+// TestKeystoreMultiProcess; it adds inboxes using its own Keystore instance.
+func TestKeystoreLockHelperProcess(t *testing.T) {
+	worker := os.Getenv("VSB_KEYSTORE_LOCK_WORKER")
+	if worker == "" {
+		t.Skip("helper process")
+	}
+
+	for i := 0; i < 10; i++ {
+		ks, err := LoadKeystore()
+		require.NoError(t, err)
+		email := fmt.Sprintf("worker%s-%d@example.com", worker, i)
+		require.NoError(t, ks.AddInbox(testStoredInbox(email, 24*time.Hour)))
+	}
+}
+
+func TestKeystoreMultiProcess(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("VSB_CONFIG_DIR", dir)
+
+	const workers = 4
+	cmds := make([]*exec.Cmd, workers)
+	for i := range cmds {
+		cmd := exec.Command(os.Args[0], "-test.run=^TestKeystoreLockHelperProcess$")
+		cmd.Env = append(os.Environ(),
+			"VSB_CONFIG_DIR="+dir,
+			fmt.Sprintf("VSB_KEYSTORE_LOCK_WORKER=%d", i),
+		)
+		require.NoError(t, cmd.Start())
+		cmds[i] = cmd
+	}
+	for _, cmd := range cmds {
+		require.NoError(t, cmd.Wait())
+	}
+
+	ks, err := LoadKeystore()
+	require.NoError(t, err)
+	assert.Len(t, ks.ListInboxes(), workers*10, "no writes should be lost across processes")
+}
+
 func TestSaveInbox(t *testing.T) {
 	t.Run("saves exported inbox to keystore", func(t *testing.T) {
 		ks, _ := setupKeystore(t)
@@ -0,0 +1,266 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Schema versions written by this version of vsb. Files without a version
+// field predate versioning and are treated as version 0.
+const (
+	ConfigSchemaVersion   = 1
+	KeystoreSchemaVersion = 2
+)
+
+// Migration describes the upgrade of one file by MigrateConfig or
+// MigrateKeystore. Changes is empty when the file was already current.
+type Migration struct {
+	Path    string
+	Exists  bool
+	From    int
+	To      int
+	Changes []string
+	Written bool
+	Backup  string // copy of the file before it was rewritten, if any
+}
+
+// MigrateConfig upgrades config.yaml to ConfigSchemaVersion, rewriting it
+// atomically unless dryRun is set. A missing file is left alone.
+func MigrateConfig(dryRun bool) (*Migration, error) {
+	path, err := Path()
+	if err != nil {
+		return nil, err
+	}
+	return migrateFile(path, ConfigSchemaVersion, dryRun, false, upgradeConfig)
+}
+
+// MigrateKeystore upgrades keystore.json to KeystoreSchemaVersion, holding
+// the keystore lock while it reads and rewrites the file. A missing file is
+// left alone.
+func MigrateKeystore(dryRun bool) (*Migration, error) {
+	return MigrateKeystoreVersions(-1, KeystoreSchemaVersion, dryRun)
+}
+
+// MigrateKeystoreVersions upgrades keystore.json like MigrateKeystore along
+// an explicit path: from overrides the schema version recorded in the file
+// (use -1 to keep it) and to is the version to stop at. The old file is
+// copied next to it before it is rewritten.
+func MigrateKeystoreVersions(from, to int, dryRun bool) (*Migration, error) {
+	if to < 1 || to > KeystoreSchemaVersion {
+		return nil, fmt.Errorf("unsupported keystore schema version %d (supported: 1 to %d)", to, KeystoreSchemaVersion)
+	}
+	if from > to {
+		return nil, fmt.Errorf("cannot migrate the keystore from schema version %d down to %d", from, to)
+	}
+
+	path, err := keystorePath()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(path); err == nil {
+		unlock, err := (&Keystore{path: path}).lock()
+		if err != nil {
+			return nil, err
+		}
+		defer unlock()
+	}
+	return migrateFile(path, to, dryRun, true, func(data []byte) ([]byte, int, []string, error) {
+		return upgradeKeystoreTo(data, from, to)
+	})
+}
+
+// upgradeFunc returns the upgraded file contents, the version the file had
+// and a description of each change. No changes means data is current.
+type upgradeFunc func(data []byte) (out []byte, from int, changes []string, err error)
+
+// migrateFile applies upgrade to the file at path, first copying it to a
+// backup file when backup is set.
+func migrateFile(path string, to int, dryRun, backup bool, upgrade upgradeFunc) (*Migration, error) {
+	m := &Migration{Path: path, From: to, To: to}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return m, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	m.Exists = true
+
+	out, from, changes, err := upgrade(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	m.From = from
+	m.Changes = changes
+	if len(changes) == 0 || dryRun {
+		return m, nil
+	}
+	if backup {
+		m.Backup = migrationBackupPath(path, from)
+		if err := WriteFileAtomic(m.Backup, data, 0600); err != nil {
+			return nil, fmt.Errorf("failed to back up %s: %w", path, err)
+		}
+	}
+	if err := WriteFileAtomic(path, out, 0600); err != nil {
+		return nil, err
+	}
+	m.Written = true
+	return m, nil
+}
+
+// migrationBackupPath returns where the file at path, with schema version
+// from, is copied before an upgrade rewrites it
+func migrationBackupPath(path string, from int) string {
+	return fmt.Sprintf("%s.v%d-%s.bak", path, from, time.Now().UTC().Format("20060102T150405Z"))
+}
+
+// newerVersionError is returned for files written by a newer vsb, which
+// this version must not rewrite
+func newerVersionError(from, supported int) error {
+	return fmt.Errorf("schema version %d is newer than this vsb supports (%d); upgrade vsb", from, supported)
+}
+
+// upgradeConfig stamps the schema version into config.yaml. It edits the
+// YAML document rather than the Config struct so unknown keys and comments
+// survive.
+func upgradeConfig(data []byte) ([]byte, int, []string, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, 0, nil, err
+	}
+	if len(doc.Content) == 0 {
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}}
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, 0, nil, fmt.Errorf("expected a mapping of keys to values")
+	}
+
+	from := 0
+	var versionNode *yaml.Node
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if root.Content[i].Value == "version" {
+			versionNode = root.Content[i+1]
+			v, err := strconv.Atoi(versionNode.Value)
+			if err != nil {
+				return nil, 0, nil, fmt.Errorf("invalid version %q", versionNode.Value)
+			}
+			from = v
+		}
+	}
+	if from > ConfigSchemaVersion {
+		return nil, from, nil, newerVersionError(from, ConfigSchemaVersion)
+	}
+	if from == ConfigSchemaVersion {
+		return data, from, nil, nil
+	}
+
+	// 0 -> 1: no keys changed, the file only gains its version
+	value := strconv.Itoa(ConfigSchemaVersion)
+	if versionNode != nil {
+		versionNode.Value = value
+	} else {
+		root.Content = append([]*yaml.Node{
+			{Kind: yaml.ScalarNode, Tag: "!!str", Value: "version"},
+			{Kind: yaml.ScalarNode, Tag: "!!int", Value: value},
+		}, root.Content...)
+	}
+	out, err := yaml.Marshal(&doc)
+	if err != nil {
+		return nil, 0, nil, err
+	}
+	return out, from, []string{fmt.Sprintf("set schema version to %d", ConfigSchemaVersion)}, nil
+}
+
+// upgradeKeystore upgrades keystore data to KeystoreSchemaVersion.
+func upgradeKeystore(data []byte) ([]byte, int, []string, error) {
+	return upgradeKeystoreTo(data, -1, KeystoreSchemaVersion)
+}
+
+// upgradeKeystoreTo upgrades keystore data from schema version from (or,
+// when negative, the version in the data) to version to, filling in inbox
+// fields that version 0 keystores lack and then running keystoreMigrations.
+// The result is encoded the way Keystore.Save writes it.
+func upgradeKeystoreTo(data []byte, from, to int) ([]byte, int, []string, error) {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, 0, nil, err
+	}
+
+	if from < 0 {
+		from = 0
+		if v, ok := raw["version"]; ok {
+			if err := json.Unmarshal(v, &from); err != nil {
+				return nil, 0, nil, fmt.Errorf("invalid version %s", v)
+			}
+		}
+		if from > KeystoreSchemaVersion {
+			return nil, from, nil, newerVersionError(from, KeystoreSchemaVersion)
+		}
+	}
+	if from >= to {
+		return data, from, nil, nil
+	}
+
+	var changes []string
+	if from == 0 {
+		// 0 -> 1: the encrypted and emailAuth flags were added after the
+		// first releases, when every inbox was created with both. An inbox
+		// without a private key cannot have been encrypted.
+		var inboxes []map[string]json.RawMessage
+		if v, ok := raw["inboxes"]; ok {
+			if err := json.Unmarshal(v, &inboxes); err != nil {
+				return nil, 0, nil, fmt.Errorf("invalid inboxes: %w", err)
+			}
+		}
+		for _, inbox := range inboxes {
+			var email string
+			_ = json.Unmarshal(inbox["email"], &email)
+			if _, ok := inbox["encrypted"]; !ok {
+				var keys InboxKeys
+				_ = json.Unmarshal(inbox["keys"], &keys)
+				encrypted := keys.KEMPrivate != ""
+				inbox["encrypted"], _ = json.Marshal(encrypted)
+				changes = append(changes, fmt.Sprintf("%s: set encrypted to %t", email, encrypted))
+			}
+			if _, ok := inbox["emailAuth"]; !ok {
+				inbox["emailAuth"] = json.RawMessage("true")
+				changes = append(changes, fmt.Sprintf("%s: set emailAuth to true", email))
+			}
+		}
+		if inboxes != nil {
+			encoded, err := json.Marshal(inboxes)
+			if err != nil {
+				return nil, 0, nil, err
+			}
+			raw["inboxes"] = encoded
+		}
+	}
+
+	upgraded, err := json.Marshal(raw)
+	if err != nil {
+		return nil, 0, nil, err
+	}
+	ks := Keystore{Inboxes: []StoredInbox{}}
+	if err := json.Unmarshal(upgraded, &ks); err != nil {
+		return nil, 0, nil, err
+	}
+	migrated, err := runKeystoreMigrations(&ks, max(from, 1), to)
+	if err != nil {
+		return nil, 0, nil, err
+	}
+	changes = append(changes, migrated...)
+	changes = append(changes, fmt.Sprintf("set schema version to %d", to))
+
+	ks.Version = to
+	out, err := json.MarshalIndent(&ks, "", "  ")
+	if err != nil {
+		return nil, 0, nil, err
+	}
+	return out, from, changes, nil
+}
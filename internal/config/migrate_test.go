@@ -0,0 +1,298 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+// legacyKeystore is a keystore written before schema versioning, with one
+// inbox from before the encrypted/emailAuth flags and one with them
+const legacyKeystore = `{
+  "inboxes": [
+    {"email": "old@example.com", "id": "h1", "expiresAt": "2099-01-01T00:00:00Z", "keys": {"kem_private": "priv"}},
+    {"email": "plain@example.com", "id": "h2", "expiresAt": "2099-01-01T00:00:00Z", "keys": {}, "encrypted": false, "emailAuth": false}
+  ],
+  "active_inbox": "old@example.com"
+}`
+
+func TestMigrateKeystore(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("VSB_CONFIG_DIR", dir)
+	path := filepath.Join(dir, "keystore.json")
+	require.NoError(t, os.WriteFile(path, []byte(legacyKeystore), 0600))
+
+	t.Run("dry run leaves the file alone", func(t *testing.T) {
+		m, err := MigrateKeystore(true)
+		require.NoError(t, err)
+		assert.Equal(t, 0, m.From)
+		assert.Equal(t, KeystoreSchemaVersion, m.To)
+		assert.Equal(t, []string{
+			"old@example.com: set encrypted to true",
+			"old@example.com: set emailAuth to true",
+			"set schema version to 2",
+		}, m.Changes)
+		assert.False(t, m.Written)
+
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, legacyKeystore, string(data))
+	})
+
+	t.Run("rewrites the file", func(t *testing.T) {
+		m, err := MigrateKeystore(false)
+		require.NoError(t, err)
+		assert.True(t, m.Written)
+
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		var ks Keystore
+		require.NoError(t, json.Unmarshal(data, &ks))
+		assert.Equal(t, KeystoreSchemaVersion, ks.Version)
+		assert.Equal(t, "old@example.com", ks.ActiveInbox)
+		require.Len(t, ks.Inboxes, 2)
+		assert.True(t, ks.Inboxes[0].Encrypted)
+		assert.True(t, ks.Inboxes[0].EmailAuth)
+		assert.Equal(t, "priv", ks.Inboxes[0].Keys.KEMPrivate)
+		assert.False(t, ks.Inboxes[1].Encrypted)
+		assert.False(t, ks.Inboxes[1].EmailAuth)
+	})
+
+	t.Run("is idempotent", func(t *testing.T) {
+		before, err := os.ReadFile(path)
+		require.NoError(t, err)
+
+		m, err := MigrateKeystore(false)
+		require.NoError(t, err)
+		assert.Empty(t, m.Changes)
+		assert.False(t, m.Written)
+
+		after, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, before, after)
+	})
+
+	t.Run("refuses newer versions", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(path, []byte(`{"version": 99, "inboxes": []}`), 0600))
+
+		_, err := MigrateKeystore(false)
+		assert.ErrorContains(t, err, "schema version 99 is newer")
+		_, err = LoadKeystore()
+		assert.ErrorContains(t, err, "schema version 99 is newer")
+	})
+
+	t.Run("missing file is skipped", func(t *testing.T) {
+		t.Setenv("VSB_CONFIG_DIR", t.TempDir())
+
+		m, err := MigrateKeystore(false)
+		require.NoError(t, err)
+		assert.False(t, m.Exists)
+		assert.Empty(t, m.Changes)
+	})
+}
+
+// v1Keystore is a version 1 keystore with a repeated label and an active
+// inbox that is not stored
+const v1Keystore = `{
+  "version": 1,
+  "inboxes": [
+    {"email": "a@example.com", "id": "h1", "label": "ci", "expiresAt": "2099-01-01T00:00:00Z", "keys": {}, "encrypted": true, "emailAuth": true},
+    {"email": "b@example.com", "id": "h2", "label": "ci", "expiresAt": "2099-01-01T00:00:00Z", "keys": {}, "encrypted": true, "emailAuth": true}
+  ],
+  "active_inbox": "gone@example.com"
+}`
+
+func TestMigrateKeystoreVersions(t *testing.T) {
+	setup := func(t *testing.T, data string) string {
+		dir := t.TempDir()
+		t.Setenv("VSB_CONFIG_DIR", dir)
+		path := filepath.Join(dir, "keystore.json")
+		require.NoError(t, os.WriteFile(path, []byte(data), 0600))
+		return path
+	}
+
+	t.Run("backs up the old file", func(t *testing.T) {
+		path := setup(t, v1Keystore)
+
+		m, err := MigrateKeystoreVersions(-1, 2, false)
+		require.NoError(t, err)
+		assert.Equal(t, 1, m.From)
+		assert.Equal(t, 2, m.To)
+		assert.Equal(t, []string{
+			`b@example.com: renamed label "ci" to "ci-2"`,
+			`set active inbox to "a@example.com"`,
+			"set schema version to 2",
+		}, m.Changes)
+		require.NotEmpty(t, m.Backup)
+		assert.True(t, strings.HasPrefix(m.Backup, path+".v1-"), m.Backup)
+
+		backup, err := os.ReadFile(m.Backup)
+		require.NoError(t, err)
+		assert.Equal(t, v1Keystore, string(backup))
+		info, err := os.Stat(m.Backup)
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+		ks, err := LoadKeystore()
+		require.NoError(t, err)
+		assert.Equal(t, 2, ks.Version)
+		active, err := ks.GetActiveInbox()
+		require.NoError(t, err)
+		assert.Equal(t, "a@example.com", active.Email)
+	})
+
+	t.Run("dry run writes no backup", func(t *testing.T) {
+		path := setup(t, v1Keystore)
+
+		m, err := MigrateKeystoreVersions(-1, 2, true)
+		require.NoError(t, err)
+		assert.Empty(t, m.Backup)
+		matches, err := filepath.Glob(path + ".*.bak")
+		require.NoError(t, err)
+		assert.Empty(t, matches)
+	})
+
+	t.Run("stops at the target version", func(t *testing.T) {
+		path := setup(t, legacyKeystore)
+
+		m, err := MigrateKeystoreVersions(-1, 1, false)
+		require.NoError(t, err)
+		assert.Equal(t, 0, m.From)
+		assert.Equal(t, "set schema version to 1", m.Changes[len(m.Changes)-1])
+
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Contains(t, string(data), `"version": 1`)
+	})
+
+	t.Run("from overrides the recorded version", func(t *testing.T) {
+		setup(t, `{"version": 2, "inboxes": [{"email": "a@example.com", "label": "x"}, {"email": "b@example.com", "label": "X"}]}`)
+
+		m, err := MigrateKeystoreVersions(1, 2, true)
+		require.NoError(t, err)
+		assert.Equal(t, 1, m.From)
+		assert.Contains(t, m.Changes, `b@example.com: renamed label "X" to "X-2"`)
+	})
+
+	t.Run("rejects unsupported paths", func(t *testing.T) {
+		setup(t, v1Keystore)
+
+		_, err := MigrateKeystoreVersions(-1, 3, false)
+		assert.ErrorContains(t, err, "unsupported keystore schema version 3")
+		_, err = MigrateKeystoreVersions(2, 1, false)
+		assert.ErrorContains(t, err, "from schema version 2 down to 1")
+	})
+}
+
+func TestLoadKeystoreUpgradesLegacyFile(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("VSB_CONFIG_DIR", dir)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "keystore.json"), []byte(legacyKeystore), 0600))
+
+	ks, err := LoadKeystore()
+	require.NoError(t, err)
+	inbox, err := ks.GetInbox("old@example.com")
+	require.NoError(t, err)
+	assert.True(t, inbox.Encrypted)
+	assert.True(t, inbox.EmailAuth)
+
+	// Loading only upgrades in memory
+	matches, err := filepath.Glob(filepath.Join(dir, "keystore.json.*.bak"))
+	require.NoError(t, err)
+	assert.Empty(t, matches)
+
+	require.NoError(t, ks.SetActiveInbox("plain@example.com"))
+	data, err := os.ReadFile(filepath.Join(dir, "keystore.json"))
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"version": 2`)
+
+	// The first save backs up the old file, later saves do not
+	matches, err = filepath.Glob(filepath.Join(dir, "keystore.json.v0-*.bak"))
+	require.NoError(t, err)
+	require.Len(t, matches, 1)
+	backup, err := os.ReadFile(matches[0])
+	require.NoError(t, err)
+	assert.Equal(t, legacyKeystore, string(backup))
+
+	require.NoError(t, ks.SetActiveInbox("old@example.com"))
+	matches, err = filepath.Glob(filepath.Join(dir, "keystore.json.*.bak"))
+	require.NoError(t, err)
+	assert.Len(t, matches, 1)
+}
+
+func TestImplicitUpgradeBacksUpRenamedLabels(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("VSB_CONFIG_DIR", dir)
+	path := filepath.Join(dir, "keystore.json")
+	require.NoError(t, os.WriteFile(path, []byte(v1Keystore), 0600))
+
+	ks, err := LoadKeystore()
+	require.NoError(t, err)
+	require.NoError(t, ks.PutInbox(StoredInbox{Email: "new@example.com", ID: "h9"}, false))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"label": "ci-2"`)
+
+	matches, err := filepath.Glob(path + ".v1-*.bak")
+	require.NoError(t, err)
+	require.Len(t, matches, 1)
+	backup, err := os.ReadFile(matches[0])
+	require.NoError(t, err)
+	assert.Equal(t, v1Keystore, string(backup))
+}
+
+func TestMigrateConfig(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("VSB_CONFIG_DIR", dir)
+	path := filepath.Join(dir, "config.yaml")
+	legacy := "# local dev server\nbase_url: http://localhost:9999\nstrategy: polling\ncustom_key: kept\n"
+	require.NoError(t, os.WriteFile(path, []byte(legacy), 0600))
+
+	m, err := MigrateConfig(true)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"set schema version to 1"}, m.Changes)
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, legacy, string(data))
+
+	m, err = MigrateConfig(false)
+	require.NoError(t, err)
+	assert.True(t, m.Written)
+	data, err = os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "# local dev server")
+	assert.Contains(t, string(data), "custom_key: kept")
+
+	var cfg Config
+	require.NoError(t, yaml.Unmarshal(data, &cfg))
+	assert.Equal(t, ConfigSchemaVersion, cfg.Version)
+	assert.Equal(t, "http://localhost:9999", cfg.BaseURL)
+	assert.Equal(t, "polling", cfg.Strategy)
+
+	m, err = MigrateConfig(false)
+	require.NoError(t, err)
+	assert.Empty(t, m.Changes, "already current")
+	assert.False(t, m.Written)
+
+	require.NoError(t, os.WriteFile(path, []byte("version: two\n"), 0600))
+	_, err = MigrateConfig(false)
+	assert.ErrorContains(t, err, `invalid version "two"`)
+}
+
+func TestSaveStampsConfigVersion(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("VSB_CONFIG_DIR", dir)
+
+	require.NoError(t, Save(&Config{Strategy: "sse"}))
+	m, err := MigrateConfig(true)
+	require.NoError(t, err)
+	assert.Equal(t, ConfigSchemaVersion, m.From)
+	assert.Empty(t, m.Changes)
+}
@@ -0,0 +1,120 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// keystoreMigration upgrades a decoded keystore from one schema version to
+// the next.
+type keystoreMigration struct {
+	from    int
+	migrate func(ks *Keystore) error
+}
+
+// keystoreMigrations lists the upgrades from schema version 1 on, in order.
+// The 0 -> 1 upgrade runs on the raw JSON in upgradeKeystore instead, as it
+// depends on which fields the file has. To change the keystore format, bump
+// KeystoreSchemaVersion and append a migration from the previous version.
+var keystoreMigrations = []keystoreMigration{
+	{from: 1, migrate: migrateV1ToV2},
+}
+
+// migrateV1ToV2 makes inbox addresses and labels unique and points the
+// active inbox at a stored inbox. Version 1 left these to the commands
+// writing the keystore, so merged or hand-edited files could make inbox
+// lookups ambiguous.
+func migrateV1ToV2(ks *Keystore) error {
+	// Keep the last entry for an address, as AddInbox replaces earlier ones
+	last := make(map[string]int, len(ks.Inboxes))
+	for i, inbox := range ks.Inboxes {
+		last[inbox.Email] = i
+	}
+	inboxes := make([]StoredInbox, 0, len(last))
+	for i, inbox := range ks.Inboxes {
+		if last[inbox.Email] == i {
+			inboxes = append(inboxes, inbox)
+		}
+	}
+
+	// Labels match case-insensitively, so repeats are numbered: ci, ci-2
+	taken := make(map[string]bool, len(inboxes))
+	for _, inbox := range inboxes {
+		taken[strings.ToLower(inbox.Label)] = true
+	}
+	seen := make(map[string]bool, len(inboxes))
+	for i := range inboxes {
+		label := strings.ToLower(inboxes[i].Label)
+		if label == "" {
+			continue
+		}
+		if seen[label] {
+			n := 2
+			for taken[fmt.Sprintf("%s-%d", label, n)] {
+				n++
+			}
+			inboxes[i].Label = fmt.Sprintf("%s-%d", inboxes[i].Label, n)
+			label = strings.ToLower(inboxes[i].Label)
+			taken[label] = true
+		}
+		seen[label] = true
+	}
+
+	ks.Inboxes = inboxes
+	if ks.ActiveInbox != "" && !ks.inboxExistsLocked(ks.ActiveInbox) {
+		ks.ActiveInbox = ""
+		if len(ks.Inboxes) > 0 {
+			ks.ActiveInbox = ks.Inboxes[0].Email
+		}
+	}
+	return nil
+}
+
+// runKeystoreMigrations applies the migrations from schema version from up
+// to version to, returning a description of each change they made.
+func runKeystoreMigrations(ks *Keystore, from, to int) ([]string, error) {
+	var changes []string
+	for _, m := range keystoreMigrations {
+		if m.from < from || m.from >= to {
+			continue
+		}
+		before := append([]StoredInbox(nil), ks.Inboxes...)
+		active := ks.ActiveInbox
+		if err := m.migrate(ks); err != nil {
+			return nil, fmt.Errorf("migration from schema version %d: %w", m.from, err)
+		}
+		changes = append(changes, diffKeystore(before, active, ks)...)
+	}
+	return changes, nil
+}
+
+// diffKeystore describes how a migration changed the inboxes and the active
+// inbox of ks
+func diffKeystore(before []StoredInbox, active string, ks *Keystore) []string {
+	last := make(map[string]int, len(before))
+	for i, inbox := range before {
+		last[inbox.Email] = i
+	}
+
+	var changes []string
+	for i, old := range before {
+		if last[old.Email] != i {
+			changes = append(changes, fmt.Sprintf("%s: removed duplicate entry", old.Email))
+			continue
+		}
+		current := ks.findInboxLocked(old.Email)
+		switch {
+		case current == nil:
+			changes = append(changes, fmt.Sprintf("%s: removed", old.Email))
+		case current.Label != old.Label:
+			changes = append(changes, fmt.Sprintf("%s: renamed label %q to %q", old.Email, old.Label, current.Label))
+		case !reflect.DeepEqual(*current, old):
+			changes = append(changes, fmt.Sprintf("%s: updated", old.Email))
+		}
+	}
+	if ks.ActiveInbox != active {
+		changes = append(changes, fmt.Sprintf("set active inbox to %q", ks.ActiveInbox))
+	}
+	return changes
+}
@@ -0,0 +1,84 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMigrateV1ToV2(t *testing.T) {
+	t.Run("keeps the last entry for an address", func(t *testing.T) {
+		ks := &Keystore{Inboxes: []StoredInbox{
+			{Email: "a@example.com", ID: "old"},
+			{Email: "b@example.com", ID: "b"},
+			{Email: "a@example.com", ID: "new"},
+		}, ActiveInbox: "a@example.com"}
+
+		require.NoError(t, migrateV1ToV2(ks))
+		require.Len(t, ks.Inboxes, 2)
+		assert.Equal(t, "b@example.com", ks.Inboxes[0].Email)
+		assert.Equal(t, "new", ks.Inboxes[1].ID)
+		assert.Equal(t, "a@example.com", ks.ActiveInbox)
+	})
+
+	t.Run("numbers repeated labels ignoring case", func(t *testing.T) {
+		ks := &Keystore{Inboxes: []StoredInbox{
+			{Email: "a@example.com", Label: "ci"},
+			{Email: "b@example.com", Label: "CI"},
+			{Email: "c@example.com", Label: "ci-2"},
+			{Email: "d@example.com", Label: "ci"},
+			{Email: "e@example.com"},
+			{Email: "f@example.com"},
+		}}
+
+		require.NoError(t, migrateV1ToV2(ks))
+		labels := make([]string, len(ks.Inboxes))
+		for i, inbox := range ks.Inboxes {
+			labels[i] = inbox.Label
+		}
+		assert.Equal(t, []string{"ci", "CI-3", "ci-2", "ci-4", "", ""}, labels)
+	})
+
+	t.Run("points a dangling active inbox at the first inbox", func(t *testing.T) {
+		ks := &Keystore{Inboxes: []StoredInbox{{Email: "a@example.com"}}, ActiveInbox: "gone@example.com"}
+		require.NoError(t, migrateV1ToV2(ks))
+		assert.Equal(t, "a@example.com", ks.ActiveInbox)
+
+		ks = &Keystore{Inboxes: []StoredInbox{}, ActiveInbox: "gone@example.com"}
+		require.NoError(t, migrateV1ToV2(ks))
+		assert.Empty(t, ks.ActiveInbox)
+	})
+
+	t.Run("leaves a valid keystore alone", func(t *testing.T) {
+		ks := &Keystore{Inboxes: []StoredInbox{
+			{Email: "a@example.com", Label: "ci"},
+			{Email: "b@example.com"},
+		}, ActiveInbox: "b@example.com"}
+		before := append([]StoredInbox(nil), ks.Inboxes...)
+
+		require.NoError(t, migrateV1ToV2(ks))
+		assert.Equal(t, before, ks.Inboxes)
+		assert.Empty(t, diffKeystore(before, "b@example.com", ks))
+	})
+}
+
+func TestRunKeystoreMigrations(t *testing.T) {
+	ks := &Keystore{Inboxes: []StoredInbox{
+		{Email: "a@example.com", Label: "ci"},
+		{Email: "b@example.com", Label: "ci"},
+		{Email: "a@example.com", Label: "ci"},
+	}, ActiveInbox: "gone@example.com"}
+
+	changes, err := runKeystoreMigrations(ks, 1, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"a@example.com: removed duplicate entry",
+		`a@example.com: renamed label "ci" to "ci-2"`,
+		`set active inbox to "b@example.com"`,
+	}, changes)
+
+	changes, err = runKeystoreMigrations(ks, 2, 2)
+	require.NoError(t, err)
+	assert.Empty(t, changes, "no migrations past the target")
+}
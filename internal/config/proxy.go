@@ -0,0 +1,154 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// DefaultHTTPTimeout matches the timeout the SDK uses for API requests
+const DefaultHTTPTimeout = 30 * time.Second
+
+// GetProxy returns the proxy URL with priority: env (VSB_PROXY) > config
+// file. An empty result means HTTPS_PROXY and HTTP_PROXY are used instead.
+func GetProxy() string {
+	return getConfigValue("PROXY", current.Proxy, "")
+}
+
+// GetNoProxy returns the comma-separated hosts that bypass the proxy, with
+// priority: env (VSB_NO_PROXY) > config file > NO_PROXY.
+func GetNoProxy() string {
+	return getConfigValue("NO_PROXY", current.NoProxy, firstEnv("NO_PROXY", "no_proxy"))
+}
+
+// ParseProxyURL parses and validates a proxy URL. A URL without a scheme is
+// treated as http://, as curl does.
+func ParseProxyURL(raw string) (*url.URL, error) {
+	raw = strings.TrimSpace(raw)
+	if !strings.Contains(raw, "://") {
+		raw = "http://" + raw
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy URL: %w", err)
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		return nil, fmt.Errorf("invalid proxy URL: unsupported scheme %q (valid: http, https, socks5, socks5h)", u.Scheme)
+	}
+	if u.Hostname() == "" {
+		return nil, fmt.Errorf("invalid proxy URL: missing host")
+	}
+	return u, nil
+}
+
+// ValidateNoProxy checks a no-proxy list. Only entries that look like CIDR
+// ranges can be malformed; anything else is matched as a host name.
+func ValidateNoProxy(list string) error {
+	for _, entry := range strings.Split(list, ",") {
+		entry = strings.TrimSpace(entry)
+		if strings.Contains(entry, "/") {
+			if _, _, err := net.ParseCIDR(entry); err != nil {
+				return fmt.Errorf("invalid no-proxy entry %q: not a valid CIDR range", entry)
+			}
+		}
+	}
+	return nil
+}
+
+// ProxyForRequest is an http.Transport Proxy function that applies the
+// proxy and no-proxy settings. They are read on each request so a transport
+// created before the config is loaded still uses them.
+func ProxyForRequest(req *http.Request) (*url.URL, error) {
+	raw := GetProxy()
+	if raw == "" {
+		if req.URL.Scheme == "https" {
+			raw = firstEnv("HTTPS_PROXY", "https_proxy")
+		} else {
+			raw = firstEnv("HTTP_PROXY", "http_proxy")
+		}
+	}
+	if raw == "" || bypassProxy(req.URL, GetNoProxy()) {
+		return nil, nil
+	}
+	return ParseProxyURL(raw)
+}
+
+// NewTransport returns a copy of the default transport that routes requests
+// through the configured proxy.
+func NewTransport() *http.Transport {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.Proxy = ProxyForRequest
+	return t
+}
+
+// NewHTTPClient returns an HTTP client that uses the configured proxy and
+// User-Agent. A zero timeout means no timeout.
+func NewHTTPClient(timeout time.Duration) *http.Client {
+	return &http.Client{Timeout: timeout, Transport: &userAgentTransport{base: NewTransport()}}
+}
+
+// bypassProxy reports whether u matches an entry of the no-proxy list. An
+// entry is "*" (every host), a CIDR range or IP address, or a domain that
+// also matches its subdomains ("example.com" and ".example.com" both match
+// api.example.com). Any entry may carry a port to only match that port.
+func bypassProxy(u *url.URL, noProxy string) bool {
+	host := strings.ToLower(u.Hostname())
+	ip := net.ParseIP(host)
+	port := u.Port()
+	if port == "" {
+		port = map[string]string{"http": "80", "https": "443"}[u.Scheme]
+	}
+
+	for _, entry := range strings.Split(noProxy, ",") {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		if entry == "" {
+			continue
+		}
+		if entry == "*" {
+			return true
+		}
+		if _, cidr, err := net.ParseCIDR(entry); err == nil {
+			if ip != nil && cidr.Contains(ip) {
+				return true
+			}
+			continue
+		}
+
+		entryHost := entry
+		if h, p, err := net.SplitHostPort(entry); err == nil {
+			if p != port {
+				continue
+			}
+			entryHost = h
+		}
+		entryHost = strings.Trim(entryHost, "[]")
+		if entryIP := net.ParseIP(entryHost); entryIP != nil {
+			if ip != nil && entryIP.Equal(ip) {
+				return true
+			}
+			continue
+		}
+
+		entryHost = strings.TrimPrefix(strings.TrimPrefix(entryHost, "*"), ".")
+		if host == entryHost || strings.HasSuffix(host, "."+entryHost) {
+			return true
+		}
+	}
+	return false
+}
+
+// firstEnv returns the first non-empty environment variable of keys
+func firstEnv(keys ...string) string {
+	for _, key := range keys {
+		if v := os.Getenv(key); v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,67 @@
+package config
+
+import (
+	"os"
+	"time"
+)
+
+// PruneResult is the outcome of PruneKeystore.
+type PruneResult struct {
+	Pruned      []StoredInbox
+	Remaining   int    // inboxes left that have not expired
+	KeptExpired int    // inboxes left that expired less than olderThan ago
+	ActiveInbox string // the active inbox after pruning
+	// ActiveChanged is set when the active inbox was pruned
+	ActiveChanged bool
+}
+
+// PruneKeystore removes the inboxes that expired more than olderThan before
+// now from keystore.json, as LoadKeystore does for every expired inbox, and
+// picks a new active inbox if the active one is removed. With dryRun the
+// file is read but not written. A missing keystore has nothing to prune.
+func PruneKeystore(now time.Time, olderThan time.Duration, dryRun bool) (*PruneResult, error) {
+	path, err := keystorePath()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return &PruneResult{}, nil
+	}
+
+	// Not LoadKeystore, which would remove every expired inbox first
+	ks := &Keystore{Inboxes: []StoredInbox{}, path: path}
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+	unlock, err := ks.lock()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	if err := ks.reloadLocked(); err != nil {
+		return nil, err
+	}
+
+	previousActive := ks.ActiveInbox
+	result := &PruneResult{Pruned: ks.removeExpiredLocked(now.Add(-olderThan))}
+	result.ActiveInbox = ks.ActiveInbox
+	result.ActiveChanged = ks.ActiveInbox != previousActive
+	for _, inbox := range ks.Inboxes {
+		if inbox.ExpiresAt.After(now) {
+			result.Remaining++
+		} else {
+			result.KeptExpired++
+		}
+	}
+	if dryRun || len(result.Pruned) == 0 {
+		return result, nil
+	}
+
+	if err := ks.saveLocked(); err != nil {
+		return nil, err
+	}
+	// Stale read state only costs disk space, so don't fail the prune
+	for _, inbox := range result.Pruned {
+		RemoveReadState(inbox.Email)
+	}
+	return result, nil
+}
@@ -0,0 +1,114 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupPruneKeystore writes a keystore with one live inbox and inboxes that
+// expired an hour and three days before now, the oldest of them active
+func setupPruneKeystore(t *testing.T, now time.Time) string {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("VSB_CONFIG_DIR", dir)
+	path := filepath.Join(dir, "keystore.json")
+	data := fmt.Sprintf(`{
+  "version": 2,
+  "inboxes": [
+    {"email": "live@example.com", "id": "h1", "expiresAt": %q, "keys": {}},
+    {"email": "recent@example.com", "id": "h2", "expiresAt": %q, "keys": {}},
+    {"email": "old@example.com", "id": "h3", "expiresAt": %q, "keys": {}}
+  ],
+  "active_inbox": "old@example.com"
+}`, now.Add(time.Hour).Format(time.RFC3339), now.Add(-time.Hour).Format(time.RFC3339), now.Add(-72*time.Hour).Format(time.RFC3339))
+	require.NoError(t, os.WriteFile(path, []byte(data), 0600))
+	return path
+}
+
+func prunedEmails(result *PruneResult) []string {
+	var emails []string
+	for _, inbox := range result.Pruned {
+		emails = append(emails, inbox.Email)
+	}
+	return emails
+}
+
+func TestPruneKeystore(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+
+	t.Run("removes every expired inbox", func(t *testing.T) {
+		setupPruneKeystore(t, now)
+
+		result, err := PruneKeystore(now, 0, false)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"recent@example.com", "old@example.com"}, prunedEmails(result))
+		assert.Equal(t, 1, result.Remaining)
+		assert.Equal(t, 0, result.KeptExpired)
+		assert.Equal(t, "live@example.com", result.ActiveInbox)
+		assert.True(t, result.ActiveChanged)
+
+		ks, err := LoadKeystore()
+		require.NoError(t, err)
+		assert.Len(t, ks.Inboxes, 1)
+		assert.Equal(t, "live@example.com", ks.ActiveInbox)
+	})
+
+	t.Run("older than keeps recently expired inboxes", func(t *testing.T) {
+		path := setupPruneKeystore(t, now)
+
+		result, err := PruneKeystore(now, 24*time.Hour, false)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"old@example.com"}, prunedEmails(result))
+		assert.Equal(t, 1, result.Remaining)
+		assert.Equal(t, 1, result.KeptExpired)
+
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Contains(t, string(data), "recent@example.com")
+		assert.NotContains(t, string(data), "old@example.com")
+	})
+
+	t.Run("dry run leaves the file alone", func(t *testing.T) {
+		path := setupPruneKeystore(t, now)
+		before, err := os.ReadFile(path)
+		require.NoError(t, err)
+
+		result, err := PruneKeystore(now, 0, true)
+		require.NoError(t, err)
+		assert.Len(t, result.Pruned, 2)
+
+		after, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, string(before), string(after))
+	})
+
+	t.Run("active inbox is kept when it has not expired", func(t *testing.T) {
+		path := setupPruneKeystore(t, now)
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		data = []byte(strings.Replace(string(data), `"active_inbox": "old@example.com"`, `"active_inbox": "live@example.com"`, 1))
+		require.NoError(t, os.WriteFile(path, data, 0600))
+
+		result, err := PruneKeystore(now, 0, false)
+		require.NoError(t, err)
+		assert.Len(t, result.Pruned, 2)
+		assert.Equal(t, "live@example.com", result.ActiveInbox)
+		assert.False(t, result.ActiveChanged)
+	})
+
+	t.Run("missing keystore", func(t *testing.T) {
+		t.Setenv("VSB_CONFIG_DIR", t.TempDir())
+
+		result, err := PruneKeystore(now, 0, false)
+		require.NoError(t, err)
+		assert.Empty(t, result.Pruned)
+		assert.Equal(t, 0, result.Remaining)
+	})
+}
@@ -0,0 +1,130 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ReadState records which emails of one inbox have been displayed on this
+// machine. It is purely local bookkeeping; nothing is sent to the server.
+type ReadState struct {
+	Read map[string]time.Time `json:"read"` // email ID -> when it was marked read
+}
+
+// IsRead reports whether the email has been marked read.
+func (rs *ReadState) IsRead(emailID string) bool {
+	_, ok := rs.Read[emailID]
+	return ok
+}
+
+// MarkRead marks emails as read, keeping the original time for emails that
+// already were.
+func (rs *ReadState) MarkRead(now time.Time, emailIDs ...string) {
+	if rs.Read == nil {
+		rs.Read = make(map[string]time.Time)
+	}
+	for _, id := range emailIDs {
+		if _, ok := rs.Read[id]; !ok {
+			rs.Read[id] = now
+		}
+	}
+}
+
+// MarkUnread removes emails from the read set.
+func (rs *ReadState) MarkUnread(emailIDs ...string) {
+	for _, id := range emailIDs {
+		delete(rs.Read, id)
+	}
+}
+
+// readStatePath returns the read state file for an inbox:
+// <config dir>/readstate/<inbox>.json
+func readStatePath(inboxEmail string) (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	name := strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') ||
+			r == '@' || r == '.' || r == '-' || r == '_' || r == '+' {
+			return r
+		}
+		return '_'
+	}, inboxEmail)
+	return filepath.Join(dir, "readstate", name+".json"), nil
+}
+
+// LoadReadState reads the read state of an inbox. An inbox without a state
+// file has no read emails.
+func LoadReadState(inboxEmail string) (*ReadState, error) {
+	path, err := readStatePath(inboxEmail)
+	if err != nil {
+		return nil, err
+	}
+	return readReadState(path)
+}
+
+func readReadState(path string) (*ReadState, error) {
+	rs := &ReadState{Read: map[string]time.Time{}}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return rs, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, rs); err != nil {
+		return nil, err
+	}
+	if rs.Read == nil {
+		rs.Read = map[string]time.Time{}
+	}
+	return rs, nil
+}
+
+// UpdateReadState applies fn to the latest read state of an inbox and saves
+// it, holding a file lock so concurrent vsb processes don't lose updates.
+func UpdateReadState(inboxEmail string, fn func(rs *ReadState)) error {
+	path, err := readStatePath(inboxEmail)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	unlock, err := lockFile(path + ".lock")
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	rs, err := readReadState(path)
+	if err != nil {
+		return err
+	}
+	fn(rs)
+
+	data, err := json.MarshalIndent(rs, "", "  ")
+	if err != nil {
+		return err
+	}
+	return WriteFileAtomic(path, data, 0600)
+}
+
+// RemoveReadState deletes the read state of an inbox, e.g. once the inbox
+// has expired or been deleted. A missing state file is not an error.
+func RemoveReadState(inboxEmail string) error {
+	path, err := readStatePath(inboxEmail)
+	if err != nil {
+		return err
+	}
+	for _, p := range []string{path, path + ".lock"} {
+		if err := os.Remove(p); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadState(t *testing.T) {
+	t.Setenv("VSB_CONFIG_DIR", t.TempDir())
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("missing state has nothing read", func(t *testing.T) {
+		rs, err := LoadReadState("fresh@example.com")
+		require.NoError(t, err)
+		assert.False(t, rs.IsRead("abc"))
+	})
+
+	t.Run("mark read and unread persist", func(t *testing.T) {
+		require.NoError(t, UpdateReadState("inbox@example.com", func(rs *ReadState) {
+			rs.MarkRead(now, "one", "two")
+		}))
+		require.NoError(t, UpdateReadState("inbox@example.com", func(rs *ReadState) {
+			rs.MarkRead(now.Add(time.Hour), "two", "three")
+			rs.MarkUnread("one")
+		}))
+
+		rs, err := LoadReadState("inbox@example.com")
+		require.NoError(t, err)
+		assert.False(t, rs.IsRead("one"))
+		assert.True(t, rs.IsRead("two"))
+		assert.True(t, rs.IsRead("three"))
+		assert.Equal(t, now, rs.Read["two"], "re-marking keeps the first time")
+	})
+
+	t.Run("state is per inbox", func(t *testing.T) {
+		rs, err := LoadReadState("other@example.com")
+		require.NoError(t, err)
+		assert.False(t, rs.IsRead("two"))
+	})
+
+	t.Run("state file is private", func(t *testing.T) {
+		path, err := readStatePath("inbox@example.com")
+		require.NoError(t, err)
+		info, err := os.Stat(path)
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	})
+
+	t.Run("remove", func(t *testing.T) {
+		require.NoError(t, RemoveReadState("inbox@example.com"))
+		require.NoError(t, RemoveReadState("inbox@example.com"), "missing state is not an error")
+
+		rs, err := LoadReadState("inbox@example.com")
+		require.NoError(t, err)
+		assert.Empty(t, rs.Read)
+	})
+
+	t.Run("corrupt state is an error", func(t *testing.T) {
+		path, err := readStatePath("corrupt@example.com")
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(path, []byte("{"), 0600))
+		_, err = LoadReadState("corrupt@example.com")
+		assert.Error(t, err)
+	})
+}
+
+func TestReadStatePath(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("VSB_CONFIG_DIR", dir)
+
+	path, err := readStatePath("../../evil/x@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "readstate"), filepath.Dir(path))
+}
+
+func TestReadStateRemovedWithInbox(t *testing.T) {
+	t.Run("inbox removal", func(t *testing.T) {
+		ks, _ := setupKeystore(t)
+		require.NoError(t, ks.AddInbox(testStoredInbox("gone@example.com", time.Hour)))
+		require.NoError(t, UpdateReadState("gone@example.com", func(rs *ReadState) { rs.MarkRead(time.Now(), "a") }))
+
+		require.NoError(t, ks.RemoveInbox("gone@example.com"))
+
+		path, err := readStatePath("gone@example.com")
+		require.NoError(t, err)
+		assert.NoFileExists(t, path)
+	})
+
+	t.Run("expiry pruning", func(t *testing.T) {
+		dir := t.TempDir()
+		t.Setenv("VSB_CONFIG_DIR", dir)
+		expired := time.Now().Add(-time.Hour).Format(time.RFC3339)
+		data := fmt.Sprintf(`{"inboxes":[{"email":"old@example.com","expiresAt":"%s"}]}`, expired)
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "keystore.json"), []byte(data), 0600))
+		require.NoError(t, UpdateReadState("old@example.com", func(rs *ReadState) { rs.MarkRead(time.Now(), "a") }))
+		require.NoError(t, UpdateReadState("kept@example.com", func(rs *ReadState) { rs.MarkRead(time.Now(), "a") }))
+
+		_, err := LoadKeystore()
+		require.NoError(t, err)
+
+		oldPath, _ := readStatePath("old@example.com")
+		keptPath, _ := readStatePath("kept@example.com")
+		assert.NoFileExists(t, oldPath)
+		assert.FileExists(t, keptPath)
+	})
+}
@@ -0,0 +1,74 @@
+package config
+
+import "sort"
+
+// normalizeTags returns tags sorted and without duplicates, or nil when
+// there are none.
+func normalizeTags(tags []string) []string {
+	if len(tags) == 0 {
+		return nil
+	}
+	seen := make(map[string]bool, len(tags))
+	var out []string
+	for _, tag := range tags {
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		out = append(out, tag)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// HasTag reports whether the inbox is tagged with tag.
+func (s *StoredInbox) HasTag(tag string) bool {
+	for _, t := range s.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// TagInbox adds tags to an inbox and returns its tags afterwards. Tags the
+// inbox already has are left alone.
+func (ks *Keystore) TagInbox(email string, tags []string) ([]string, error) {
+	var result []string
+	err := ks.update(func() error {
+		inbox := ks.findInboxLocked(email)
+		if inbox == nil {
+			return ErrInboxNotFound
+		}
+		inbox.Tags = normalizeTags(append(append([]string(nil), inbox.Tags...), tags...))
+		result = inbox.Tags
+		return nil
+	})
+	return result, err
+}
+
+// UntagInbox removes tags from an inbox and returns its tags afterwards.
+// Tags the inbox does not have are ignored.
+func (ks *Keystore) UntagInbox(email string, tags []string) ([]string, error) {
+	var result []string
+	err := ks.update(func() error {
+		inbox := ks.findInboxLocked(email)
+		if inbox == nil {
+			return ErrInboxNotFound
+		}
+		remove := make(map[string]bool, len(tags))
+		for _, tag := range tags {
+			remove[tag] = true
+		}
+		var kept []string
+		for _, tag := range inbox.Tags {
+			if !remove[tag] {
+				kept = append(kept, tag)
+			}
+		}
+		inbox.Tags = normalizeTags(kept)
+		result = inbox.Tags
+		return nil
+	})
+	return result, err
+}
@@ -0,0 +1,83 @@
+package config
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInboxTags(t *testing.T) {
+	ks, _ := setupKeystore(t)
+	inbox := testStoredInbox("tags@example.com", 24*time.Hour)
+	require.NoError(t, ks.AddInbox(inbox))
+
+	t.Run("tag and reload", func(t *testing.T) {
+		tags, err := ks.TagInbox(inbox.Email, []string{"billing", "auth", "billing"})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"auth", "billing"}, tags)
+
+		reloaded, err := LoadKeystore()
+		require.NoError(t, err)
+		stored, err := reloaded.GetInbox(inbox.Email)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"auth", "billing"}, stored.Tags)
+		assert.True(t, stored.HasTag("auth"))
+		assert.False(t, stored.HasTag("other"))
+	})
+
+	t.Run("tag again keeps existing", func(t *testing.T) {
+		tags, err := ks.TagInbox(inbox.Email, []string{"auth", "ci"})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"auth", "billing", "ci"}, tags)
+	})
+
+	t.Run("untag", func(t *testing.T) {
+		tags, err := ks.UntagInbox(inbox.Email, []string{"billing", "missing"})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"auth", "ci"}, tags)
+
+		tags, err = ks.UntagInbox(inbox.Email, []string{"auth", "ci"})
+		require.NoError(t, err)
+		assert.Nil(t, tags)
+		stored, err := ks.GetInbox(inbox.Email)
+		require.NoError(t, err)
+		assert.Nil(t, stored.Tags)
+	})
+
+	t.Run("unknown inbox", func(t *testing.T) {
+		_, err := ks.TagInbox("missing@example.com", []string{"a"})
+		assert.ErrorIs(t, err, ErrInboxNotFound)
+		_, err = ks.UntagInbox("missing@example.com", []string{"a"})
+		assert.ErrorIs(t, err, ErrInboxNotFound)
+	})
+}
+
+func TestInboxTagsExport(t *testing.T) {
+	t.Run("roundtrip as version 2", func(t *testing.T) {
+		stored := testStoredInbox("export@example.com", 24*time.Hour)
+		stored.Tags = []string{"auth", "billing"}
+
+		exportFile := stored.ToExportFile()
+		assert.Equal(t, ExportVersionTags, exportFile.Version)
+		require.NoError(t, exportFile.Seal())
+		data, err := json.Marshal(exportFile)
+		require.NoError(t, err)
+
+		var imported ExportedInboxFile
+		require.NoError(t, json.Unmarshal(data, &imported))
+		require.NoError(t, imported.VerifyChecksum())
+		assert.Equal(t, stored.Tags, imported.ToStoredInbox().Tags)
+	})
+
+	t.Run("untagged stays version 1", func(t *testing.T) {
+		stored := testStoredInbox("plain@example.com", 24*time.Hour)
+		exportFile := stored.ToExportFile()
+		assert.Equal(t, ExportVersion, exportFile.Version)
+		data, err := json.Marshal(exportFile)
+		require.NoError(t, err)
+		assert.NotContains(t, string(data), "tags")
+	})
+}
@@ -0,0 +1,55 @@
+package config
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// version is the vsb version reported in the default User-Agent
+var version = "dev"
+
+// SetVersion sets the vsb version used in the default User-Agent.
+func SetVersion(v string) {
+	version = v
+}
+
+// DefaultUserAgent returns the User-Agent sent when none is configured,
+// e.g. "vsb-cli/1.2.3".
+func DefaultUserAgent() string {
+	return "vsb-cli/" + version
+}
+
+// GetUserAgent returns the User-Agent header sent with every HTTP request,
+// with priority: env (VSB_USER_AGENT) > config file > DefaultUserAgent.
+func GetUserAgent() string {
+	return getConfigValue("USER_AGENT", current.UserAgent, DefaultUserAgent())
+}
+
+// ValidateUserAgent checks that ua is a non-empty string of printable
+// ASCII characters, which is all a header value may safely contain.
+func ValidateUserAgent(ua string) error {
+	if ua == "" {
+		return fmt.Errorf("invalid user-agent: must not be empty")
+	}
+	for i := 0; i < len(ua); i++ {
+		if ua[i] < 0x20 || ua[i] > 0x7e {
+			return fmt.Errorf("invalid user-agent %q: only printable ASCII characters are allowed", ua)
+		}
+	}
+	return nil
+}
+
+// userAgentTransport sets the configured User-Agent on requests that do
+// not carry their own. Like the proxy, it is read on each request.
+type userAgentTransport struct {
+	base http.RoundTripper
+}
+
+func (t *userAgentTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Header.Get("User-Agent") == "" {
+		// A RoundTripper must not modify the caller's request
+		req = req.Clone(req.Context())
+		req.Header.Set("User-Agent", GetUserAgent())
+	}
+	return t.base.RoundTrip(req)
+}
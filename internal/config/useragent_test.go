@@ -0,0 +1,114 @@
+package config
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetUserAgent(t *testing.T) {
+	originalCurrent, originalVersion := current, version
+	defer func() { current, version = originalCurrent, originalVersion }()
+	t.Setenv("VSB_USER_AGENT", "")
+
+	SetVersion("1.2.3")
+	current = Config{}
+	assert.Equal(t, "vsb-cli/1.2.3", GetUserAgent())
+
+	current = Config{UserAgent: "MyCI/2.0 vsb-cli"}
+	assert.Equal(t, "MyCI/2.0 vsb-cli", GetUserAgent())
+
+	t.Setenv("VSB_USER_AGENT", "EnvCI/1.0")
+	assert.Equal(t, "EnvCI/1.0", GetUserAgent())
+}
+
+func TestValidateUserAgent(t *testing.T) {
+	assert.NoError(t, ValidateUserAgent("MyCI/2.0 vsb-cli (+https://ci.example.com)"))
+	assert.EqualError(t, ValidateUserAgent(""), "invalid user-agent: must not be empty")
+	assert.ErrorContains(t, ValidateUserAgent("MyCI\r\nX-Injected: 1"), "only printable ASCII")
+	assert.ErrorContains(t, ValidateUserAgent("MyCI\t2.0"), "only printable ASCII")
+	assert.ErrorContains(t, ValidateUserAgent("Café/1.0"), "only printable ASCII")
+}
+
+// userAgentServer records the User-Agent of each request it receives
+func userAgentServer(t *testing.T) (*httptest.Server, func() []string) {
+	var mu sync.Mutex
+	var agents []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		agents = append(agents, r.Header.Get("User-Agent"))
+		mu.Unlock()
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	t.Cleanup(server.Close)
+	return server, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), agents...)
+	}
+}
+
+func TestNewHTTPClientSetsUserAgent(t *testing.T) {
+	originalCurrent, originalVersion := current, version
+	defer func() { current, version = originalCurrent, originalVersion }()
+	current = Config{}
+	clearProxyEnv(t)
+	t.Setenv("VSB_USER_AGENT", "")
+	SetVersion("1.2.3")
+
+	server, agents := userAgentServer(t)
+
+	resp, err := NewHTTPClient(DefaultHTTPTimeout).Get(server.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	current = Config{UserAgent: "MyCI/2.0 vsb-cli"}
+	resp, err = NewHTTPClient(DefaultHTTPTimeout).Get(server.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	// A header set by the caller, e.g. with --post-header, wins
+	req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+	require.NoError(t, err)
+	req.Header.Set("User-Agent", "custom/1.0")
+	resp, err = NewHTTPClient(DefaultHTTPTimeout).Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, "custom/1.0", req.Header.Get("User-Agent"))
+
+	assert.Equal(t, []string{"vsb-cli/1.2.3", "MyCI/2.0 vsb-cli", "custom/1.0"}, agents())
+}
+
+func TestNewClientUserAgent(t *testing.T) {
+	resetAPIKeySources(t)
+	clearProxyEnv(t)
+	t.Setenv("VSB_USER_AGENT", "")
+	t.Setenv("VSB_BASE_URL", "")
+	t.Setenv("VSB_STRATEGY", "")
+	t.Setenv("VSB_POLL_INTERVAL", "")
+
+	server, agents := userAgentServer(t)
+
+	t.Run("sent with API requests", func(t *testing.T) {
+		current = Config{APIKey: "test-key", BaseURL: server.URL, UserAgent: "MyCI/2.0 vsb-cli"}
+
+		_, err := NewClient()
+		require.Error(t, err, "the test server rejects the key")
+		require.NotEmpty(t, agents())
+		for _, ua := range agents() {
+			assert.Equal(t, "MyCI/2.0 vsb-cli", ua)
+		}
+	})
+
+	t.Run("invalid value is rejected", func(t *testing.T) {
+		current = Config{APIKey: "test-key", BaseURL: server.URL}
+		t.Setenv("VSB_USER_AGENT", "bad\x7f")
+
+		_, err := NewClient()
+		assert.ErrorContains(t, err, "invalid user-agent")
+	})
+}
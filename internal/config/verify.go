@@ -0,0 +1,150 @@
+package config
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/sha3"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Decoded sizes of the keys an encrypted inbox stores: an ML-KEM-768
+// key pair and the server's ML-DSA-65 public key
+const (
+	KEMPrivateKeySize  = 2400
+	KEMPublicKeySize   = 1184
+	ServerSigPKKeySize = 1952
+)
+
+// Problems reported by VerifyInbox; each is wrapped with the details
+var (
+	ErrKEMPrivateKey = errors.New("invalid KEM private key")
+	ErrKEMPublicKey  = errors.New("invalid KEM public key")
+	ErrServerSigPK   = errors.New("invalid server signature key")
+	ErrInboxExpired  = errors.New("inbox expired")
+
+	// ErrKeystoreInvalid is returned by 'keystore verify' when inboxes
+	// with invalid keys are left in the keystore
+	ErrKeystoreInvalid = errors.New("keystore has inboxes with invalid keys")
+)
+
+// KeyInfo describes one decoded key for 'keystore verify --verbose'.
+// Fingerprint is empty when the key is not a public key or did not decode.
+type KeyInfo struct {
+	Size        int    `json:"size"`
+	Fingerprint string `json:"fingerprint,omitempty"`
+}
+
+// InboxVerification is the result of checking one stored inbox
+type InboxVerification struct {
+	Email    string
+	Problems []error
+
+	KEMPrivate  KeyInfo
+	KEMPublic   KeyInfo
+	ServerSigPK KeyInfo
+}
+
+// OK reports whether the inbox passed every check.
+func (v *InboxVerification) OK() bool {
+	return len(v.Problems) == 0
+}
+
+// KeysInvalid reports whether a key check failed, as opposed to only the
+// inbox having expired.
+func (v *InboxVerification) KeysInvalid() bool {
+	for _, p := range v.Problems {
+		if !errors.Is(p, ErrInboxExpired) {
+			return true
+		}
+	}
+	return false
+}
+
+// VerifyInbox checks that an encrypted inbox's KEM private key is a
+// well-formed ML-KEM-768 decapsulation key that matches its stored public
+// key, that the pinned server key has the ML-DSA-65 size, and that the
+// inbox has not expired at now. Plain inboxes store no keys, so only their
+// expiry is checked.
+func VerifyInbox(inbox *StoredInbox, now time.Time) InboxVerification {
+	v := InboxVerification{Email: inbox.Email}
+	if !inbox.ExpiresAt.After(now) {
+		v.Problems = append(v.Problems, fmt.Errorf("%w at %s", ErrInboxExpired, inbox.ExpiresAt.Format(time.RFC3339)))
+	}
+	if !inbox.Encrypted {
+		return v
+	}
+
+	var embedded []byte // encapsulation key inside the private key
+	priv, err := decodeKey(inbox.Keys.KEMPrivate, KEMPrivateKeySize)
+	v.KEMPrivate.Size = len(priv)
+	if err != nil {
+		v.Problems = append(v.Problems, fmt.Errorf("%w: %v", ErrKEMPrivateKey, err))
+	} else if embedded, err = kemEncapsulationKey(priv); err != nil {
+		v.Problems = append(v.Problems, fmt.Errorf("%w: %v", ErrKEMPrivateKey, err))
+	}
+
+	// The public key is usually not stored, as it is derived from the
+	// private key; check it only when present
+	if inbox.Keys.KEMPublic != "" {
+		pub, err := decodeKey(inbox.Keys.KEMPublic, KEMPublicKeySize)
+		v.KEMPublic = KeyInfo{Size: len(pub)}
+		switch {
+		case err != nil:
+			v.Problems = append(v.Problems, fmt.Errorf("%w: %v", ErrKEMPublicKey, err))
+		case embedded != nil && !bytes.Equal(pub, embedded):
+			v.Problems = append(v.Problems, fmt.Errorf("%w: does not match the private key", ErrKEMPublicKey))
+		default:
+			v.KEMPublic.Fingerprint = keyFingerprint(pub)
+		}
+	} else if embedded != nil {
+		v.KEMPublic = KeyInfo{Size: len(embedded), Fingerprint: keyFingerprint(embedded)}
+	}
+
+	sig, err := decodeKey(inbox.Keys.ServerSigPK, ServerSigPKKeySize)
+	v.ServerSigPK.Size = len(sig)
+	if err != nil {
+		v.Problems = append(v.Problems, fmt.Errorf("%w: %v", ErrServerSigPK, err))
+	} else {
+		v.ServerSigPK.Fingerprint = keyFingerprint(sig)
+	}
+	return v
+}
+
+// decodeKey decodes a base64url key and checks its size.
+func decodeKey(encoded string, size int) ([]byte, error) {
+	if encoded == "" {
+		return nil, errors.New("missing")
+	}
+	key, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, errors.New("not valid base64url")
+	}
+	if len(key) != size {
+		return key, fmt.Errorf("%d bytes, expected %d", len(key), size)
+	}
+	return key, nil
+}
+
+// kemEncapsulationKey returns the encapsulation key embedded in an
+// ML-KEM-768 decapsulation key after checking it against the SHA3-256 hash
+// stored next to it (the FIPS 203 decapsulation key check).
+func kemEncapsulationKey(priv []byte) ([]byte, error) {
+	const ekStart = KEMPrivateKeySize - KEMPublicKeySize - 64
+	ek := priv[ekStart : ekStart+KEMPublicKeySize]
+	h := priv[ekStart+KEMPublicKeySize : ekStart+KEMPublicKeySize+32]
+	sum := sha3.Sum256(ek)
+	if !bytes.Equal(sum[:], h) {
+		return nil, errors.New("embedded public key hash mismatch (corrupted key)")
+	}
+	return ek, nil
+}
+
+// keyFingerprint is a short SHA-256 fingerprint of a public key.
+func keyFingerprint(key []byte) string {
+	sum := sha256.Sum256(key)
+	return "SHA256:" + hex.EncodeToString(sum[:8])
+}
@@ -0,0 +1,105 @@
+package config
+
+import (
+	"crypto/rand"
+	"crypto/sha3"
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testKEMPrivateKey returns a base64url ML-KEM-768 decapsulation key with a
+// consistent embedded public key hash, and that public key.
+func testKEMPrivateKey(t *testing.T) (priv string, pub []byte) {
+	t.Helper()
+	key := make([]byte, KEMPrivateKeySize)
+	_, err := rand.Read(key)
+	require.NoError(t, err)
+	ekStart := KEMPrivateKeySize - KEMPublicKeySize - 64
+	ek := key[ekStart : ekStart+KEMPublicKeySize]
+	sum := sha3.Sum256(ek)
+	copy(key[ekStart+KEMPublicKeySize:], sum[:])
+	return base64.RawURLEncoding.EncodeToString(key), append([]byte(nil), ek...)
+}
+
+func TestVerifyInbox(t *testing.T) {
+	now := time.Now()
+	priv, pub := testKEMPrivateKey(t)
+	sig := base64.RawURLEncoding.EncodeToString(make([]byte, ServerSigPKKeySize))
+
+	valid := func() StoredInbox {
+		return StoredInbox{
+			Email:     "valid@example.com",
+			ExpiresAt: now.Add(time.Hour),
+			Encrypted: true,
+			Keys:      InboxKeys{KEMPrivate: priv, ServerSigPK: sig},
+		}
+	}
+
+	t.Run("valid", func(t *testing.T) {
+		inbox := valid()
+		v := VerifyInbox(&inbox, now)
+		assert.True(t, v.OK(), "problems: %v", v.Problems)
+		assert.Equal(t, KEMPrivateKeySize, v.KEMPrivate.Size)
+		assert.Equal(t, KEMPublicKeySize, v.KEMPublic.Size)
+		assert.Equal(t, keyFingerprint(pub), v.KEMPublic.Fingerprint)
+		assert.Equal(t, ServerSigPKKeySize, v.ServerSigPK.Size)
+		assert.Contains(t, v.ServerSigPK.Fingerprint, "SHA256:")
+	})
+
+	t.Run("matching stored public key", func(t *testing.T) {
+		inbox := valid()
+		inbox.Keys.KEMPublic = base64.RawURLEncoding.EncodeToString(pub)
+		v := VerifyInbox(&inbox, now)
+		assert.True(t, v.OK(), "problems: %v", v.Problems)
+	})
+
+	t.Run("plain inbox needs no keys", func(t *testing.T) {
+		inbox := StoredInbox{Email: "plain@example.com", ExpiresAt: now.Add(time.Hour)}
+		v := VerifyInbox(&inbox, now)
+		assert.True(t, v.OK())
+	})
+
+	// Flip a bit in the middle of the embedded public key
+	corrupted := func() string {
+		key, err := base64.RawURLEncoding.DecodeString(priv)
+		require.NoError(t, err)
+		key[KEMPrivateKeySize-KEMPublicKeySize] ^= 1
+		return base64.RawURLEncoding.EncodeToString(key)
+	}()
+
+	tests := []struct {
+		name    string
+		modify  func(inbox *StoredInbox)
+		want    error
+		message string
+	}{
+		{"missing private key", func(i *StoredInbox) { i.Keys.KEMPrivate = "" }, ErrKEMPrivateKey, "missing"},
+		{"private key not base64url", func(i *StoredInbox) { i.Keys.KEMPrivate = "not/base64+" }, ErrKEMPrivateKey, "not valid base64url"},
+		{"truncated private key", func(i *StoredInbox) { i.Keys.KEMPrivate = priv[:100] }, ErrKEMPrivateKey, "75 bytes, expected 2400"},
+		{"corrupted private key", func(i *StoredInbox) { i.Keys.KEMPrivate = corrupted }, ErrKEMPrivateKey, "hash mismatch"},
+		{"mismatched public key", func(i *StoredInbox) {
+			other := make([]byte, KEMPublicKeySize)
+			i.Keys.KEMPublic = base64.RawURLEncoding.EncodeToString(other)
+		}, ErrKEMPublicKey, "does not match the private key"},
+		{"missing server key", func(i *StoredInbox) { i.Keys.ServerSigPK = "" }, ErrServerSigPK, "missing"},
+		{"short server key", func(i *StoredInbox) {
+			i.Keys.ServerSigPK = base64.RawURLEncoding.EncodeToString(make([]byte, 32))
+		}, ErrServerSigPK, "32 bytes, expected 1952"},
+		{"expired", func(i *StoredInbox) { i.ExpiresAt = now.Add(-time.Hour) }, ErrInboxExpired, "inbox expired"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inbox := valid()
+			tt.modify(&inbox)
+			v := VerifyInbox(&inbox, now)
+			require.Len(t, v.Problems, 1)
+			assert.ErrorIs(t, v.Problems[0], tt.want)
+			assert.Contains(t, v.Problems[0].Error(), tt.message)
+			assert.Equal(t, tt.want != ErrInboxExpired, v.KeysInvalid())
+		})
+	}
+}
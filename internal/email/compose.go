@@ -0,0 +1,204 @@
+package email
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/textproto"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Message is an email to be composed for sending over SMTP.
+type Message struct {
+	From      string
+	To        string
+	Subject   string
+	Date      time.Time
+	MessageID string
+
+	// InReplyTo and References thread the message under earlier ones
+	InReplyTo  string
+	References string
+
+	// Text and HTML are the body alternatives; either may be empty
+	Text string
+	HTML string
+
+	Attachments []Attachment
+}
+
+// Attachment is a file attached to a composed message. An empty ContentType
+// is guessed from the file name.
+type Attachment struct {
+	Filename    string
+	ContentType string
+	Data        []byte
+}
+
+// base64LineLength is the maximum encoded line length allowed by RFC 2045
+const base64LineLength = 76
+
+// Compose renders m as a MIME message with CRLF line endings. A message with
+// both bodies is multipart/alternative, and attachments wrap the body in
+// multipart/mixed. Text parts are quoted-printable and attachments base64.
+func Compose(m Message) ([]byte, error) {
+	var buf bytes.Buffer
+	writeHeader(&buf, "From", m.From)
+	writeHeader(&buf, "To", m.To)
+	writeHeader(&buf, "Subject", mime.QEncoding.Encode("utf-8", m.Subject))
+	if !m.Date.IsZero() {
+		writeHeader(&buf, "Date", m.Date.Format(time.RFC1123Z))
+	}
+	if m.MessageID != "" {
+		writeHeader(&buf, "Message-ID", m.MessageID)
+	}
+	if m.InReplyTo != "" {
+		writeHeader(&buf, "In-Reply-To", m.InReplyTo)
+	}
+	if m.References != "" {
+		writeHeader(&buf, "References", m.References)
+	}
+	writeHeader(&buf, "MIME-Version", "1.0")
+
+	bodyHeader, body, err := bodyPart(m)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(m.Attachments) == 0 {
+		writeHeader(&buf, "Content-Type", bodyHeader.Get("Content-Type"))
+		if cte := bodyHeader.Get("Content-Transfer-Encoding"); cte != "" {
+			writeHeader(&buf, "Content-Transfer-Encoding", cte)
+		}
+		buf.WriteString("\r\n")
+		buf.Write(body)
+		return buf.Bytes(), nil
+	}
+
+	mixed := multipart.NewWriter(&buf)
+	writeHeader(&buf, "Content-Type", mime.FormatMediaType("multipart/mixed",
+		map[string]string{"boundary": mixed.Boundary()}))
+	buf.WriteString("\r\n")
+
+	part, err := mixed.CreatePart(bodyHeader)
+	if err != nil {
+		return nil, err
+	}
+	part.Write(body)
+
+	for _, a := range m.Attachments {
+		if err := writeAttachment(mixed, a); err != nil {
+			return nil, err
+		}
+	}
+	if err := mixed.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// bodyPart returns the MIME header and content of the message body: a single
+// text part, or multipart/alternative when m has both bodies.
+func bodyPart(m Message) (textproto.MIMEHeader, []byte, error) {
+	if m.Text == "" || m.HTML == "" {
+		mediaType, content := "text/plain", m.Text
+		if m.HTML != "" {
+			mediaType, content = "text/html", m.HTML
+		}
+		var buf bytes.Buffer
+		if err := writeQuotedPrintable(&buf, content); err != nil {
+			return nil, nil, err
+		}
+		return textHeader(mediaType), buf.Bytes(), nil
+	}
+
+	var buf bytes.Buffer
+	alt := multipart.NewWriter(&buf)
+	for _, p := range []struct{ mediaType, content string }{
+		{"text/plain", m.Text},
+		{"text/html", m.HTML},
+	} {
+		part, err := alt.CreatePart(textHeader(p.mediaType))
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := writeQuotedPrintable(part, p.content); err != nil {
+			return nil, nil, err
+		}
+	}
+	if err := alt.Close(); err != nil {
+		return nil, nil, err
+	}
+
+	h := textproto.MIMEHeader{}
+	h.Set("Content-Type", mime.FormatMediaType("multipart/alternative",
+		map[string]string{"boundary": alt.Boundary()}))
+	return h, buf.Bytes(), nil
+}
+
+func textHeader(mediaType string) textproto.MIMEHeader {
+	h := textproto.MIMEHeader{}
+	h.Set("Content-Type", mediaType+"; charset=utf-8")
+	h.Set("Content-Transfer-Encoding", "quoted-printable")
+	return h
+}
+
+func writeQuotedPrintable(w io.Writer, content string) error {
+	qp := quotedprintable.NewWriter(w)
+	if _, err := qp.Write([]byte(strings.ReplaceAll(normalizeNewlines(content), "\n", "\r\n"))); err != nil {
+		return err
+	}
+	return qp.Close()
+}
+
+func writeAttachment(w *multipart.Writer, a Attachment) error {
+	name := filepath.Base(a.Filename)
+	contentType := a.ContentType
+	if contentType == "" {
+		contentType = mime.TypeByExtension(filepath.Ext(name))
+	}
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	h := textproto.MIMEHeader{}
+	h.Set("Content-Type", withParam(contentType, "name", name))
+	h.Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": name}))
+	h.Set("Content-Transfer-Encoding", "base64")
+	part, err := w.CreatePart(h)
+	if err != nil {
+		return err
+	}
+
+	encoded := base64.StdEncoding.EncodeToString(a.Data)
+	for len(encoded) > base64LineLength {
+		fmt.Fprintf(part, "%s\r\n", encoded[:base64LineLength])
+		encoded = encoded[base64LineLength:]
+	}
+	_, err = fmt.Fprintf(part, "%s\r\n", encoded)
+	return err
+}
+
+// withParam adds a parameter to a Content-Type value, keeping any it
+// already has.
+func withParam(contentType, key, value string) string {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return contentType
+	}
+	if params == nil {
+		params = map[string]string{}
+	}
+	params[key] = value
+	return mime.FormatMediaType(mediaType, params)
+}
+
+func writeHeader(buf *bytes.Buffer, name, value string) {
+	buf.WriteString(name + ": " + value + "\r\n")
+}
@@ -0,0 +1,150 @@
+package email
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompose(t *testing.T) {
+	base := Message{
+		From:      "dev@example.com",
+		To:        "abc@inbox.vsx.email",
+		Subject:   "Héllo",
+		Date:      time.Date(2026, 3, 1, 9, 5, 7, 0, time.UTC),
+		MessageID: "<1@vsb-dev>",
+	}
+
+	t.Run("plain text", func(t *testing.T) {
+		m := base
+		m.Text = "line one\nline two"
+		msg := parseComposed(t, m)
+
+		assert.Equal(t, "dev@example.com", msg.Header.Get("From"))
+		assert.Equal(t, "<1@vsb-dev>", msg.Header.Get("Message-ID"))
+		subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+		require.NoError(t, err)
+		assert.Equal(t, "Héllo", subject)
+		date, err := msg.Header.Date()
+		require.NoError(t, err)
+		assert.True(t, date.Equal(base.Date))
+
+		assert.Equal(t, "text/plain; charset=utf-8", msg.Header.Get("Content-Type"))
+		assert.Equal(t, "line one\r\nline two", decodeQP(t, msg.Body))
+		assert.Empty(t, msg.Header.Get("In-Reply-To"))
+	})
+
+	t.Run("threading headers", func(t *testing.T) {
+		m := base
+		m.Text = "reply"
+		m.InReplyTo = "<0@vsb-dev>"
+		m.References = "<root@vsb-dev> <0@vsb-dev>"
+		msg := parseComposed(t, m)
+
+		assert.Equal(t, "<0@vsb-dev>", msg.Header.Get("In-Reply-To"))
+		assert.Equal(t, "<root@vsb-dev> <0@vsb-dev>", msg.Header.Get("References"))
+	})
+
+	t.Run("text and HTML", func(t *testing.T) {
+		m := base
+		m.Text = "plain"
+		m.HTML = "<p>html</p>"
+		msg := parseComposed(t, m)
+
+		parts := readParts(t, msg.Header.Get("Content-Type"), msg.Body)
+		require.Len(t, parts, 2)
+		assert.Equal(t, "text/plain; charset=utf-8", parts[0].Header.Get("Content-Type"))
+		assert.Equal(t, "plain", decodeQP(t, strings.NewReader(parts[0].body)))
+		assert.Equal(t, "text/html; charset=utf-8", parts[1].Header.Get("Content-Type"))
+		assert.Equal(t, "<p>html</p>", decodeQP(t, strings.NewReader(parts[1].body)))
+	})
+
+	t.Run("attachments", func(t *testing.T) {
+		pdf := bytes.Repeat([]byte{0x25, 0x50, 0x44, 0x46, 0x00, 0xff}, 40)
+		m := base
+		m.HTML = "<p>see attached</p>"
+		m.Attachments = []Attachment{
+			{Filename: "/tmp/report.pdf", Data: pdf},
+			{Filename: "data.bin", Data: []byte("raw")},
+		}
+		msg := parseComposed(t, m)
+
+		mediaType, _, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+		require.NoError(t, err)
+		assert.Equal(t, "multipart/mixed", mediaType)
+
+		parts := readParts(t, msg.Header.Get("Content-Type"), msg.Body)
+		require.Len(t, parts, 3)
+		assert.Equal(t, "text/html; charset=utf-8", parts[0].Header.Get("Content-Type"))
+
+		assert.Equal(t, "application/pdf; name=report.pdf", parts[1].Header.Get("Content-Type"))
+		assert.Equal(t, "attachment; filename=report.pdf", parts[1].Header.Get("Content-Disposition"))
+		for _, line := range strings.Split(strings.TrimSpace(parts[1].body), "\r\n") {
+			assert.LessOrEqual(t, len(line), 76)
+		}
+		decoded, err := base64.StdEncoding.DecodeString(strings.ReplaceAll(parts[1].body, "\r\n", ""))
+		require.NoError(t, err)
+		assert.Equal(t, pdf, decoded)
+
+		assert.Equal(t, "application/octet-stream; name=data.bin", parts[2].Header.Get("Content-Type"))
+	})
+}
+
+func parseComposed(t *testing.T, m Message) *mail.Message {
+	t.Helper()
+	raw, err := Compose(m)
+	require.NoError(t, err)
+	assert.NotContains(t, strings.ReplaceAll(string(raw), "\r\n", ""), "\n", "all line endings are CRLF")
+	msg, err := mail.ReadMessage(bytes.NewReader(raw))
+	require.NoError(t, err)
+	return msg
+}
+
+// leafPart is a non-multipart part of a composed message
+type leafPart struct {
+	Header mail.Header
+	body   string
+}
+
+// readParts reads the leaf parts of a multipart body, descending into nested
+// multiparts.
+func readParts(t *testing.T, contentType string, body io.Reader) []leafPart {
+	t.Helper()
+	_, params, err := mime.ParseMediaType(contentType)
+	require.NoError(t, err)
+
+	var parts []leafPart
+	r := multipart.NewReader(body, params["boundary"])
+	for {
+		p, err := r.NextRawPart()
+		if err == io.EOF {
+			return parts
+		}
+		require.NoError(t, err)
+		ct := p.Header.Get("Content-Type")
+		if strings.HasPrefix(ct, "multipart/") {
+			parts = append(parts, readParts(t, ct, p)...)
+			continue
+		}
+		data, err := io.ReadAll(p)
+		require.NoError(t, err)
+		parts = append(parts, leafPart{mail.Header(p.Header), string(data)})
+	}
+}
+
+func decodeQP(t *testing.T, r io.Reader) string {
+	t.Helper()
+	data, err := io.ReadAll(quotedprintable.NewReader(r))
+	require.NoError(t, err)
+	return string(data)
+}
@@ -0,0 +1,61 @@
+package email
+
+import (
+	"bufio"
+	"io"
+	"net/mail"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// mboxDateLayout is the asctime format used in mbox "From " lines
+const mboxDateLayout = "Mon Jan _2 15:04:05 2006"
+
+// fromLinePattern matches body lines that need a ">" prefix in mboxrd
+var fromLinePattern = regexp.MustCompile(`^>*From `)
+
+// EML returns a raw RFC 5322 message as the contents of an .eml file: CRLF
+// line endings and a trailing line break.
+func EML(raw string) []byte {
+	text := normalizeNewlines(raw)
+	if !strings.HasSuffix(text, "\n") {
+		text += "\n"
+	}
+	return []byte(strings.ReplaceAll(text, "\n", "\r\n"))
+}
+
+// WriteMbox appends a raw message to w in mboxrd format: a "From " separator
+// line with the sender and receive time, the message with LF line endings and
+// "From " lines quoted, then a blank line.
+func WriteMbox(w io.Writer, from string, receivedAt time.Time, raw string) error {
+	bw := bufio.NewWriter(w)
+	bw.WriteString("From " + envelopeSender(from) + " " + receivedAt.UTC().Format(mboxDateLayout) + "\n")
+
+	text := strings.TrimSuffix(normalizeNewlines(raw), "\n")
+	for _, line := range strings.Split(text, "\n") {
+		if fromLinePattern.MatchString(line) {
+			bw.WriteByte('>')
+		}
+		bw.WriteString(line)
+		bw.WriteByte('\n')
+	}
+	bw.WriteByte('\n')
+	return bw.Flush()
+}
+
+// envelopeSender returns the bare address from a From header for the mbox
+// separator line, which can't contain spaces.
+func envelopeSender(from string) string {
+	if addr, err := mail.ParseAddress(from); err == nil && addr.Address != "" {
+		return addr.Address
+	}
+	if from = strings.Join(strings.Fields(from), ""); from != "" {
+		return from
+	}
+	return "MAILER-DAEMON"
+}
+
+func normalizeNewlines(s string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(s, "\r\n", "\n"), "\r", "\n")
+}
@@ -0,0 +1,51 @@
+package email
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const rawMessage = "From: Alice <alice@example.com>\r\nSubject: Hello\r\n\r\nFrom now on\r\n>From quoted\r\nbye"
+
+func TestEML(t *testing.T) {
+	assert.Equal(t,
+		"From: Alice <alice@example.com>\r\nSubject: Hello\r\n\r\nFrom now on\r\n>From quoted\r\nbye\r\n",
+		string(EML(rawMessage)))
+
+	t.Run("LF input is converted", func(t *testing.T) {
+		assert.Equal(t, "Subject: x\r\n\r\nbody\r\n", string(EML("Subject: x\n\nbody\n")))
+	})
+}
+
+func TestWriteMbox(t *testing.T) {
+	received := time.Date(2026, 3, 1, 9, 5, 7, 0, time.FixedZone("CET", 3600))
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteMbox(&buf, "Alice <alice@example.com>", received, rawMessage))
+	require.NoError(t, WriteMbox(&buf, "", received, "Subject: second\n\nbody\n"))
+
+	assert.Equal(t, "From alice@example.com Sun Mar  1 08:05:07 2026\n"+
+		"From: Alice <alice@example.com>\n"+
+		"Subject: Hello\n"+
+		"\n"+
+		">From now on\n"+
+		">>From quoted\n"+
+		"bye\n"+
+		"\n"+
+		"From MAILER-DAEMON Sun Mar  1 08:05:07 2026\n"+
+		"Subject: second\n"+
+		"\n"+
+		"body\n"+
+		"\n", buf.String())
+}
+
+func TestEnvelopeSender(t *testing.T) {
+	assert.Equal(t, "bob@example.com", envelopeSender("Bob Smith <bob@example.com>"))
+	assert.Equal(t, "bob@example.com", envelopeSender("bob@example.com"))
+	assert.Equal(t, "notanaddress", envelopeSender("not an address"))
+	assert.Equal(t, "MAILER-DAEMON", envelopeSender(" "))
+}
@@ -0,0 +1,141 @@
+// Package email processes emails independently of how they are displayed:
+// grouping received emails into threads, serializing raw messages and
+// composing messages to send.
+package email
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	vaultsandbox "github.com/vaultsandbox/client-go"
+)
+
+// Thread is an email and the replies to it.
+type Thread struct {
+	Email    *vaultsandbox.Email
+	Children []*Thread
+}
+
+// FlatEntry is an email in a depth-first walk of threads. Roots have depth 0.
+type FlatEntry struct {
+	Email *vaultsandbox.Email
+	Depth int
+}
+
+var msgIDPattern = regexp.MustCompile(`<[^<>\s]+>`)
+
+// BuildThreads groups emails into conversation threads using the
+// Message-ID, In-Reply-To and References headers. A reply is attached to the
+// message named in In-Reply-To, or else to the nearest ancestor listed in
+// References that is present. Emails whose parent isn't among emails start
+// their own thread. Roots and replies are ordered by receive time.
+func BuildThreads(emails []*vaultsandbox.Email) []*Thread {
+	nodes := make([]*Thread, len(emails))
+	byMsgID := make(map[string]*Thread, len(emails))
+	for i, e := range emails {
+		nodes[i] = &Thread{Email: e}
+		if id := MessageID(e); id != "" {
+			if _, dup := byMsgID[id]; !dup {
+				byMsgID[id] = nodes[i]
+			}
+		}
+	}
+
+	parents := make(map[*Thread]*Thread, len(nodes))
+	for _, node := range nodes {
+		parent := findParent(node.Email, byMsgID)
+		if parent == nil || parent == node || isAncestor(node, parent, parents) {
+			continue
+		}
+		parents[node] = parent
+	}
+
+	var roots []*Thread
+	for _, node := range nodes {
+		if parent, ok := parents[node]; ok {
+			parent.Children = append(parent.Children, node)
+		} else {
+			roots = append(roots, node)
+		}
+	}
+
+	sortThreads(roots)
+	return roots
+}
+
+// findParent returns the thread node this email replies to, if present.
+func findParent(e *vaultsandbox.Email, byMsgID map[string]*Thread) *Thread {
+	for _, id := range msgIDs(header(e, "In-Reply-To")) {
+		if parent, ok := byMsgID[id]; ok {
+			return parent
+		}
+	}
+	// References lists ancestors oldest first; the nearest one wins
+	refs := msgIDs(header(e, "References"))
+	for i := len(refs) - 1; i >= 0; i-- {
+		if parent, ok := byMsgID[refs[i]]; ok {
+			return parent
+		}
+	}
+	return nil
+}
+
+// isAncestor reports whether node is already an ancestor of candidate, in
+// which case linking them would create a cycle.
+func isAncestor(node, candidate *Thread, parents map[*Thread]*Thread) bool {
+	for p := candidate; p != nil; p = parents[p] {
+		if p == node {
+			return true
+		}
+	}
+	return false
+}
+
+func sortThreads(threads []*Thread) {
+	sort.SliceStable(threads, func(i, j int) bool {
+		return threads[i].Email.ReceivedAt.Before(threads[j].Email.ReceivedAt)
+	})
+	for _, t := range threads {
+		sortThreads(t.Children)
+	}
+}
+
+// Flatten walks threads depth-first, parents before their replies.
+func Flatten(threads []*Thread) []FlatEntry {
+	var out []FlatEntry
+	var walk func(ts []*Thread, depth int)
+	walk = func(ts []*Thread, depth int) {
+		for _, t := range ts {
+			out = append(out, FlatEntry{Email: t.Email, Depth: depth})
+			walk(t.Children, depth+1)
+		}
+	}
+	walk(threads, 0)
+	return out
+}
+
+// MessageID returns the email's Message-ID including angle brackets, or ""
+// if it has none.
+func MessageID(e *vaultsandbox.Email) string {
+	ids := msgIDs(header(e, "Message-ID"))
+	if len(ids) == 0 {
+		return ""
+	}
+	return ids[0]
+}
+
+// msgIDs extracts the <id> tokens from a header value.
+func msgIDs(value string) []string {
+	return msgIDPattern.FindAllString(value, -1)
+}
+
+// header looks up a header case-insensitively.
+func header(e *vaultsandbox.Email, name string) string {
+	for k, v := range e.Headers {
+		if strings.EqualFold(k, name) {
+			return v
+		}
+	}
+	return ""
+}
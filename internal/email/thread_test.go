@@ -0,0 +1,102 @@
+package email
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	vaultsandbox "github.com/vaultsandbox/client-go"
+)
+
+var threadBase = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+func threadEmail(id string, minute int, headers map[string]string) *vaultsandbox.Email {
+	return &vaultsandbox.Email{
+		ID:         id,
+		Subject:    id,
+		ReceivedAt: threadBase.Add(time.Duration(minute) * time.Minute),
+		Headers:    headers,
+	}
+}
+
+// shape renders threads as "id(child,child)" for compact assertions.
+func shape(threads []*Thread) string {
+	var s string
+	for i, t := range threads {
+		if i > 0 {
+			s += ","
+		}
+		s += t.Email.ID
+		if len(t.Children) > 0 {
+			s += "(" + shape(t.Children) + ")"
+		}
+	}
+	return s
+}
+
+func TestBuildThreads(t *testing.T) {
+	signup := threadEmail("signup", 0, map[string]string{"message-id": "<1@app>"})
+	confirm := threadEmail("confirm", 2, map[string]string{
+		"message-id":  "<2@app>",
+		"in-reply-to": "<1@app>",
+		"references":  "<1@app>",
+	})
+	// Only References, nearest known ancestor wins
+	done := threadEmail("done", 5, map[string]string{
+		"Message-ID": "<3@app>",
+		"References": "<1@app> <missing@app> <2@app>",
+	})
+	sibling := threadEmail("sibling", 3, map[string]string{
+		"Message-ID":  "<4@app>",
+		"In-Reply-To": "<1@app>",
+	})
+	other := threadEmail("other", 1, map[string]string{"Message-ID": "<9@app>"})
+	orphan := threadEmail("orphan", 4, map[string]string{"In-Reply-To": "<gone@app>"})
+
+	threads := BuildThreads([]*vaultsandbox.Email{done, other, sibling, orphan, confirm, signup})
+	assert.Equal(t, "signup(confirm(done),sibling),other,orphan", shape(threads))
+}
+
+func TestBuildThreadsCycle(t *testing.T) {
+	a := threadEmail("a", 0, map[string]string{"Message-ID": "<a@x>", "In-Reply-To": "<b@x>"})
+	b := threadEmail("b", 1, map[string]string{"Message-ID": "<b@x>", "In-Reply-To": "<a@x>"})
+	self := threadEmail("self", 2, map[string]string{"Message-ID": "<s@x>", "In-Reply-To": "<s@x>"})
+
+	threads := BuildThreads([]*vaultsandbox.Email{a, b, self})
+	// Every email appears exactly once
+	assert.Len(t, Flatten(threads), 3)
+	assert.Equal(t, "b(a),self", shape(threads))
+}
+
+func TestBuildThreadsNoHeaders(t *testing.T) {
+	threads := BuildThreads([]*vaultsandbox.Email{threadEmail("x", 1, nil), threadEmail("y", 0, nil)})
+	assert.Equal(t, "y,x", shape(threads))
+	assert.Empty(t, BuildThreads(nil))
+}
+
+func TestFlatten(t *testing.T) {
+	root := &Thread{Email: threadEmail("root", 0, nil)}
+	child := &Thread{Email: threadEmail("child", 1, nil)}
+	grandchild := &Thread{Email: threadEmail("grandchild", 2, nil)}
+	child.Children = []*Thread{grandchild}
+	root.Children = []*Thread{child, {Email: threadEmail("second", 3, nil)}}
+
+	flat := Flatten([]*Thread{root, {Email: threadEmail("next", 4, nil)}})
+	require.Len(t, flat, 5)
+
+	var got []string
+	var depths []int
+	for _, e := range flat {
+		got = append(got, e.Email.ID)
+		depths = append(depths, e.Depth)
+	}
+	assert.Equal(t, []string{"root", "child", "grandchild", "second", "next"}, got)
+	assert.Equal(t, []int{0, 1, 2, 1, 0}, depths)
+}
+
+func TestMessageID(t *testing.T) {
+	assert.Equal(t, "<abc@example.com>", MessageID(threadEmail("e", 0, map[string]string{"Message-Id": " <abc@example.com> "})))
+	assert.Empty(t, MessageID(threadEmail("e", 0, map[string]string{"Message-ID": "no brackets"})))
+	assert.Empty(t, MessageID(threadEmail("e", 0, nil)))
+}
@@ -2,6 +2,7 @@ package files
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 )
@@ -31,15 +32,50 @@ func GetUniqueFilename(dir, name string) string {
 // SaveFile saves data to a file in the given directory, using a unique filename
 // if the file already exists. Returns the final path used.
 func SaveFile(dir, name string, data []byte) (string, error) {
+	return saveFile(dir, name, data, 0644)
+}
+
+// SavePrivateFile is like SaveFile but creates the file readable only by
+// the current user.
+func SavePrivateFile(dir, name string, data []byte) (string, error) {
+	return saveFile(dir, name, data, 0600)
+}
+
+func saveFile(dir, name string, data []byte, perm os.FileMode) (string, error) {
 	if err := os.MkdirAll(dir, 0755); err != nil {
 		return "", fmt.Errorf("failed to create directory: %w", err)
 	}
 
 	path := GetUniqueFilename(dir, name)
 
-	if err := os.WriteFile(path, data, 0644); err != nil {
+	if err := os.WriteFile(path, data, perm); err != nil {
 		return "", fmt.Errorf("failed to write file: %w", err)
 	}
 
 	return path, nil
 }
+
+// SaveReader is like SaveFile but streams the content from r, so a large
+// file is never held in memory by this function. Returns the final path and
+// the number of bytes written; a partially written file is removed.
+func SaveReader(dir, name string, r io.Reader) (string, int64, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", 0, fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	path := GetUniqueFilename(dir, name)
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to write file: %w", err)
+	}
+
+	n, err := io.Copy(f, r)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(path)
+		return "", n, fmt.Errorf("failed to write file: %w", err)
+	}
+	return path, n, nil
+}
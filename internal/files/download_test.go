@@ -1,8 +1,11 @@
 package files
 
 import (
+	"errors"
+	"io"
 	"os"
 	"path/filepath"
+	"runtime"
 	"strings"
 	"testing"
 
@@ -179,3 +182,95 @@ func TestSaveFile(t *testing.T) {
 		assert.Contains(t, err.Error(), "failed to write file")
 	})
 }
+
+func TestSavePrivateFile(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "msg.eml"), []byte("x"), 0644))
+
+	path, err := SavePrivateFile(dir, "msg.eml", []byte("data"))
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "msg_1.eml"), path)
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+}
+
+// patternReader produces n bytes without holding them in memory, standing
+// in for a large attachment
+type patternReader struct {
+	n int64
+}
+
+func (r *patternReader) Read(p []byte) (int, error) {
+	if r.n <= 0 {
+		return 0, io.EOF
+	}
+	if int64(len(p)) > r.n {
+		p = p[:r.n]
+	}
+	for i := range p {
+		p[i] = byte(i)
+	}
+	r.n -= int64(len(p))
+	return len(p), nil
+}
+
+// failingReader returns some data, then an error
+type failingReader struct {
+	sent bool
+}
+
+func (r *failingReader) Read(p []byte) (int, error) {
+	if r.sent {
+		return 0, errors.New("connection reset")
+	}
+	r.sent = true
+	return copy(p, "partial"), nil
+}
+
+func TestSaveReader(t *testing.T) {
+	t.Run("streams to a unique file", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "report.pdf"), []byte("x"), 0644))
+
+		path, n, err := SaveReader(dir, "../report.pdf", strings.NewReader("content"))
+		require.NoError(t, err)
+		assert.Equal(t, filepath.Join(dir, "report_1.pdf"), path)
+		assert.Equal(t, int64(7), n)
+
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, "content", string(data))
+	})
+
+	t.Run("removes the file on a read error", func(t *testing.T) {
+		dir := t.TempDir()
+
+		_, _, err := SaveReader(dir, "broken.bin", &failingReader{})
+		require.ErrorContains(t, err, "connection reset")
+		assert.NoFileExists(t, filepath.Join(dir, "broken.bin"))
+	})
+
+	t.Run("memory stays bounded for large files", func(t *testing.T) {
+		if testing.Short() {
+			t.Skip("writes 64 MiB")
+		}
+		const size = 64 << 20
+		dir := t.TempDir()
+
+		var before, after runtime.MemStats
+		runtime.GC()
+		runtime.ReadMemStats(&before)
+		path, n, err := SaveReader(dir, "large.bin", &patternReader{n: size})
+		runtime.ReadMemStats(&after)
+		require.NoError(t, err)
+
+		assert.Equal(t, int64(size), n)
+		info, err := os.Stat(path)
+		require.NoError(t, err)
+		assert.Equal(t, int64(size), info.Size())
+		allocated := after.TotalAlloc - before.TotalAlloc
+		assert.Less(t, allocated, uint64(1<<20), "saving %d bytes allocated %d", size, allocated)
+	})
+}
@@ -0,0 +1,88 @@
+package format
+
+import (
+	"encoding/base64"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// minBase64Length avoids treating short words ("test", "abcd") as base64.
+const minBase64Length = 16
+
+// IsBase64Encoding reports whether a Content-Transfer-Encoding header value is base64.
+func IsBase64Encoding(headerValue string) bool {
+	return strings.EqualFold(strings.TrimSpace(headerValue), "base64")
+}
+
+// DecodeBase64 decodes s if it is base64 text. Line breaks and surrounding
+// whitespace are ignored. If force is false, s must also look like base64
+// (long enough, correctly padded) and decode to printable UTF-8 text.
+// Returns the original string and false if s is not decodable.
+func DecodeBase64(s string, force bool) (string, bool) {
+	data, ok := DecodeBase64Bytes([]byte(s), force)
+	if !ok {
+		return s, false
+	}
+	if !force && !isPrintableText(data) {
+		return s, false
+	}
+	return string(data), true
+}
+
+// DecodeBase64Bytes decodes base64 content such as attachment data.
+// If force is false, the content must look like base64 to be decoded.
+func DecodeBase64Bytes(content []byte, force bool) ([]byte, bool) {
+	compact := strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return -1
+		}
+		return r
+	}, string(content))
+
+	if compact == "" {
+		return content, false
+	}
+	if !force && !looksLikeBase64(compact) {
+		return content, false
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(compact)
+	if err != nil {
+		decoded, err = base64.RawStdEncoding.DecodeString(strings.TrimRight(compact, "="))
+		if err != nil {
+			return content, false
+		}
+	}
+	return decoded, true
+}
+
+// looksLikeBase64 checks length, padding and alphabet of whitespace-free input.
+func looksLikeBase64(s string) bool {
+	if len(s) < minBase64Length || len(s)%4 != 0 {
+		return false
+	}
+	for i, r := range s {
+		switch {
+		case r >= 'A' && r <= 'Z', r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '+', r == '/':
+		case r == '=' && i >= len(s)-2:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// isPrintableText reports whether data is valid UTF-8 without control characters
+// other than common whitespace.
+func isPrintableText(data []byte) bool {
+	if !utf8.Valid(data) {
+		return false
+	}
+	for _, r := range string(data) {
+		if unicode.IsControl(r) && r != '\n' && r != '\r' && r != '\t' {
+			return false
+		}
+	}
+	return true
+}
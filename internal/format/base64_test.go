@@ -0,0 +1,104 @@
+package format
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecodeBase64(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		force  bool
+		want   string
+		wantOK bool
+	}{
+		{
+			name:   "decodes base64 text",
+			input:  "SGVsbG8sIHRoaXMgaXMgYSB0ZXN0IGVtYWlsIGJvZHku",
+			want:   "Hello, this is a test email body.",
+			wantOK: true,
+		},
+		{
+			name:   "ignores line breaks",
+			input:  "SGVsbG8sIHRoaXMgaXMg\r\nYSB0ZXN0IGVtYWlsIGJv\r\nZHku\r\n",
+			want:   "Hello, this is a test email body.",
+			wantOK: true,
+		},
+		{
+			name:   "decodes padded input",
+			input:  "VmVyaWZ5IHlvdXIgYWNjb3VudA==",
+			want:   "Verify your account",
+			wantOK: true,
+		},
+		{
+			name:   "plain text left alone",
+			input:  "Hello, this is a plain text email.",
+			want:   "Hello, this is a plain text email.",
+			wantOK: false,
+		},
+		{
+			name:   "short words left alone",
+			input:  "test",
+			want:   "test",
+			wantOK: false,
+		},
+		{
+			name:   "binary result left alone",
+			input:  "AAECAwQFBgcICQoLDA0ODw==",
+			want:   "AAECAwQFBgcICQoLDA0ODw==",
+			wantOK: false,
+		},
+		{
+			name:   "force decodes short input",
+			input:  "dGVzdA==",
+			force:  true,
+			want:   "test",
+			wantOK: true,
+		},
+		{
+			name:   "force still rejects invalid input",
+			input:  "not base64!",
+			force:  true,
+			want:   "not base64!",
+			wantOK: false,
+		},
+		{
+			name:   "empty input",
+			input:  "",
+			want:   "",
+			wantOK: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := DecodeBase64(tt.input, tt.force)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantOK, ok)
+		})
+	}
+}
+
+func TestDecodeBase64Bytes(t *testing.T) {
+	t.Run("decodes binary content", func(t *testing.T) {
+		got, ok := DecodeBase64Bytes([]byte("AAECAwQFBgcICQoLDA0ODw=="), false)
+		assert.True(t, ok)
+		assert.Equal(t, []byte{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15}, got)
+	})
+
+	t.Run("returns original content when not base64", func(t *testing.T) {
+		input := []byte("%PDF-1.4 binary data")
+		got, ok := DecodeBase64Bytes(input, false)
+		assert.False(t, ok)
+		assert.Equal(t, input, got)
+	})
+}
+
+func TestIsBase64Encoding(t *testing.T) {
+	assert.True(t, IsBase64Encoding("base64"))
+	assert.True(t, IsBase64Encoding(" BASE64 "))
+	assert.False(t, IsBase64Encoding("quoted-printable"))
+	assert.False(t, IsBase64Encoding(""))
+}
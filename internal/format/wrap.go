@@ -0,0 +1,57 @@
+// Package format provides text formatting helpers for terminal output.
+package format
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// DefaultWrapWidth is used when the terminal width cannot be determined.
+const DefaultWrapWidth = 80
+
+// Wrap hard-wraps text at the given column width. Lines are broken at the
+// last space before the limit; words longer than the width (long URLs,
+// base64 blobs) are split mid-word. Existing line breaks are preserved.
+// A width of zero or less returns the text unchanged.
+func Wrap(text string, width int) string {
+	if width <= 0 {
+		return text
+	}
+
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = wrapLine(strings.TrimRight(line, "\r"), width)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// wrapLine wraps a single line without embedded newlines.
+func wrapLine(line string, width int) string {
+	if utf8.RuneCountInString(line) <= width {
+		return line
+	}
+
+	var b strings.Builder
+	runes := []rune(line)
+	for len(runes) > width {
+		// Find last space within the limit
+		cut := -1
+		for j := width; j > 0; j-- {
+			if runes[j] == ' ' {
+				cut = j
+				break
+			}
+		}
+
+		if cut > 0 {
+			b.WriteString(strings.TrimRight(string(runes[:cut]), " "))
+			runes = runes[cut+1:]
+		} else {
+			b.WriteString(string(runes[:width]))
+			runes = runes[width:]
+		}
+		b.WriteByte('\n')
+	}
+	b.WriteString(string(runes))
+	return b.String()
+}
@@ -0,0 +1,78 @@
+package format
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWrap(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		width int
+		want  string
+	}{
+		{
+			name:  "short line unchanged",
+			text:  "hello world",
+			width: 20,
+			want:  "hello world",
+		},
+		{
+			name:  "breaks at last space",
+			text:  "the quick brown fox jumps",
+			width: 10,
+			want:  "the quick\nbrown fox\njumps",
+		},
+		{
+			name:  "exact width not wrapped",
+			text:  "abcde",
+			width: 5,
+			want:  "abcde",
+		},
+		{
+			name:  "space at limit",
+			text:  "abcde fghij",
+			width: 5,
+			want:  "abcde\nfghij",
+		},
+		{
+			name:  "long word split hard",
+			text:  "aGVsbG8gd29ybGQ=aGVsbG8=",
+			width: 10,
+			want:  "aGVsbG8gd2\n9ybGQ=aGVs\nbG8=",
+		},
+		{
+			name:  "preserves existing newlines",
+			text:  "one two three\n\nfour five",
+			width: 8,
+			want:  "one two\nthree\n\nfour\nfive",
+		},
+		{
+			name:  "strips carriage returns",
+			text:  "line one\r\nline two",
+			width: 20,
+			want:  "line one\nline two",
+		},
+		{
+			name:  "counts runes not bytes",
+			text:  "héllo wörld",
+			width: 6,
+			want:  "héllo\nwörld",
+		},
+		{
+			name:  "zero width disables wrapping",
+			text:  strings.Repeat("x", 200),
+			width: 0,
+			want:  strings.Repeat("x", 200),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Wrap(tt.text, tt.width))
+		})
+	}
+}
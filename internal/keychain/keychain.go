@@ -0,0 +1,87 @@
+// Package keychain stores secrets in the operating system's credential
+// store: macOS Keychain, Windows Credential Manager, or the Secret Service
+// (GNOME Keyring, KWallet) on Linux.
+package keychain
+
+import (
+	"errors"
+	"os/exec"
+	"sync"
+)
+
+// Service is the service name secrets are stored under.
+const Service = "vsb"
+
+var (
+	// ErrNotFound is returned when no secret is stored for the account.
+	ErrNotFound = errors.New("secret not found in keychain")
+	// ErrUnavailable is returned when no keychain can be reached, e.g. on a
+	// headless CI machine without a Secret Service.
+	ErrUnavailable = errors.New("system keychain unavailable")
+)
+
+// Keyring is a store of secrets keyed by service and account.
+type Keyring interface {
+	Set(service, account, secret string) error
+	Get(service, account string) (string, error)
+	Delete(service, account string) error
+}
+
+// Default is the platform keyring. Tests replace it with a MemoryKeyring.
+var Default Keyring = platformKeyring{}
+
+// execCommand is a variable for exec.Command that can be overridden in tests
+var execCommand = exec.Command
+
+// lookPath is a variable for exec.LookPath that can be overridden in tests
+var lookPath = exec.LookPath
+
+// MemoryKeyring is an in-memory Keyring for tests.
+type MemoryKeyring struct {
+	mu      sync.Mutex
+	secrets map[string]string
+	// Err, if set, is returned by every operation.
+	Err error
+}
+
+// NewMemoryKeyring returns an empty MemoryKeyring.
+func NewMemoryKeyring() *MemoryKeyring {
+	return &MemoryKeyring{secrets: make(map[string]string)}
+}
+
+func (m *MemoryKeyring) Set(service, account, secret string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.Err != nil {
+		return m.Err
+	}
+	m.secrets[service+"/"+account] = secret
+	return nil
+}
+
+func (m *MemoryKeyring) Get(service, account string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.Err != nil {
+		return "", m.Err
+	}
+	secret, ok := m.secrets[service+"/"+account]
+	if !ok {
+		return "", ErrNotFound
+	}
+	return secret, nil
+}
+
+func (m *MemoryKeyring) Delete(service, account string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.Err != nil {
+		return m.Err
+	}
+	key := service + "/" + account
+	if _, ok := m.secrets[key]; !ok {
+		return ErrNotFound
+	}
+	delete(m.secrets, key)
+	return nil
+}
@@ -0,0 +1,64 @@
+//go:build darwin
+
+package keychain
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// platformKeyring uses the macOS Keychain via the security command.
+type platformKeyring struct{}
+
+// errItemNotFound is the exit status of security when no item matches
+const errItemNotFound = 44
+
+func (platformKeyring) Set(service, account, secret string) error {
+	if _, err := lookPath("security"); err != nil {
+		return ErrUnavailable
+	}
+	// Pass the command on stdin (-i) so the secret never appears in argv
+	cmd := execCommand("security", "-i")
+	cmd.Stdin = strings.NewReader(fmt.Sprintf("add-generic-password -U -s %s -a %s -w %s\n",
+		quote(service), quote(account), quote(secret)))
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%w: %s", ErrUnavailable, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+func (platformKeyring) Get(service, account string) (string, error) {
+	if _, err := lookPath("security"); err != nil {
+		return "", ErrUnavailable
+	}
+	out, err := execCommand("security", "find-generic-password", "-s", service, "-a", account, "-w").Output()
+	if err != nil {
+		return "", classify(err)
+	}
+	return strings.TrimSuffix(string(out), "\n"), nil
+}
+
+func (platformKeyring) Delete(service, account string) error {
+	if _, err := lookPath("security"); err != nil {
+		return ErrUnavailable
+	}
+	if err := execCommand("security", "delete-generic-password", "-s", service, "-a", account).Run(); err != nil {
+		return classify(err)
+	}
+	return nil
+}
+
+func classify(err error) error {
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == errItemNotFound {
+		return ErrNotFound
+	}
+	return fmt.Errorf("%w: %v", ErrUnavailable, err)
+}
+
+// quote single-quotes s for the security -i command parser.
+func quote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'"'"'`) + "'"
+}
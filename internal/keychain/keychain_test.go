@@ -0,0 +1,26 @@
+package keychain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryKeyring(t *testing.T) {
+	kr := NewMemoryKeyring()
+
+	_, err := kr.Get(Service, "api-key")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	require.NoError(t, kr.Set(Service, "api-key", "secret"))
+	got, err := kr.Get(Service, "api-key")
+	require.NoError(t, err)
+	assert.Equal(t, "secret", got)
+
+	require.NoError(t, kr.Delete(Service, "api-key"))
+	assert.ErrorIs(t, kr.Delete(Service, "api-key"), ErrNotFound)
+
+	kr.Err = ErrUnavailable
+	assert.ErrorIs(t, kr.Set(Service, "api-key", "secret"), ErrUnavailable)
+}
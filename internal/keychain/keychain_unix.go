@@ -0,0 +1,68 @@
+//go:build !darwin && !windows
+
+package keychain
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// platformKeyring uses the freedesktop Secret Service via secret-tool.
+type platformKeyring struct{}
+
+func (platformKeyring) Set(service, account, secret string) error {
+	if _, err := lookPath("secret-tool"); err != nil {
+		return ErrUnavailable
+	}
+	// secret-tool reads the secret from stdin
+	cmd := execCommand("secret-tool", "store", "--label", service+" "+account,
+		"service", service, "account", account)
+	cmd.Stdin = strings.NewReader(secret)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%w: %s", ErrUnavailable, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+func (platformKeyring) Get(service, account string) (string, error) {
+	if _, err := lookPath("secret-tool"); err != nil {
+		return "", ErrUnavailable
+	}
+	var stderr bytes.Buffer
+	cmd := execCommand("secret-tool", "lookup", "service", service, "account", account)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", classify(err, stderr.String())
+	}
+	return string(out), nil
+}
+
+func (platformKeyring) Delete(service, account string) error {
+	if _, err := lookPath("secret-tool"); err != nil {
+		return ErrUnavailable
+	}
+	var stderr bytes.Buffer
+	cmd := execCommand("secret-tool", "clear", "service", service, "account", account)
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return classify(err, stderr.String())
+	}
+	return nil
+}
+
+// classify maps secret-tool failures: a silent exit status 1 means no
+// matching secret, anything else means the service could not be reached.
+func classify(err error, stderr string) error {
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 && strings.TrimSpace(stderr) == "" {
+		return ErrNotFound
+	}
+	if msg := strings.TrimSpace(stderr); msg != "" {
+		return fmt.Errorf("%w: %s", ErrUnavailable, msg)
+	}
+	return fmt.Errorf("%w: %v", ErrUnavailable, err)
+}
@@ -0,0 +1,73 @@
+//go:build !darwin && !windows
+
+package keychain
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockSecretTool replaces secret-tool with a shell script.
+func mockSecretTool(t *testing.T, script string) {
+	originalExec, originalLookPath := execCommand, lookPath
+	t.Cleanup(func() { execCommand, lookPath = originalExec, originalLookPath })
+
+	path := filepath.Join(t.TempDir(), "secret-tool")
+	require.NoError(t, os.WriteFile(path, []byte("#!/bin/sh\n"+script), 0755))
+
+	lookPath = func(string) (string, error) { return path, nil }
+	execCommand = func(name string, args ...string) *exec.Cmd {
+		return exec.Command(path, args...)
+	}
+}
+
+func TestPlatformKeyring(t *testing.T) {
+	t.Run("stores secret from stdin", func(t *testing.T) {
+		out := filepath.Join(t.TempDir(), "out")
+		mockSecretTool(t, `echo "$@" > `+out+`; cat >> `+out)
+
+		require.NoError(t, platformKeyring{}.Set(Service, "api-key", "s3cret"))
+		data, err := os.ReadFile(out)
+		require.NoError(t, err)
+		assert.Equal(t, "store --label vsb api-key service vsb account api-key\ns3cret", string(data))
+	})
+
+	t.Run("looks up secret", func(t *testing.T) {
+		mockSecretTool(t, `printf s3cret`)
+
+		got, err := platformKeyring{}.Get(Service, "api-key")
+		require.NoError(t, err)
+		assert.Equal(t, "s3cret", got)
+	})
+
+	t.Run("silent exit 1 is not found", func(t *testing.T) {
+		mockSecretTool(t, `exit 1`)
+
+		_, err := platformKeyring{}.Get(Service, "api-key")
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+
+	t.Run("dbus failure is unavailable", func(t *testing.T) {
+		mockSecretTool(t, `echo "Cannot autolaunch D-Bus" >&2; exit 1`)
+
+		_, err := platformKeyring{}.Get(Service, "api-key")
+		assert.ErrorIs(t, err, ErrUnavailable)
+		assert.Contains(t, err.Error(), "D-Bus")
+	})
+
+	t.Run("missing secret-tool is unavailable", func(t *testing.T) {
+		originalLookPath := lookPath
+		t.Cleanup(func() { lookPath = originalLookPath })
+		lookPath = func(string) (string, error) { return "", exec.ErrNotFound }
+
+		assert.ErrorIs(t, platformKeyring{}.Set(Service, "api-key", "x"), ErrUnavailable)
+		_, err := platformKeyring{}.Get(Service, "api-key")
+		assert.ErrorIs(t, err, ErrUnavailable)
+		assert.ErrorIs(t, platformKeyring{}.Delete(Service, "api-key"), ErrUnavailable)
+	})
+}
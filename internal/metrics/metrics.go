@@ -0,0 +1,218 @@
+// Package metrics tracks delivery statistics for the long-running email
+// dashboard and exposes them in Prometheus text format or as a JSON file.
+// Only counts and timestamps are recorded, never email contents.
+package metrics
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vaultsandbox/vsb-cli/internal/config"
+)
+
+// Recorder collects watch statistics. It is safe for concurrent use, and
+// a nil Recorder is valid: it records nothing and reports zero values, so
+// callers don't need to check whether metrics are enabled.
+type Recorder struct {
+	mu         sync.Mutex
+	received   map[string]uint64
+	reconnects uint64
+	lastEmail  time.Time
+	up         bool
+}
+
+// NewRecorder returns a Recorder with a zero counter for each inbox, so
+// inboxes that never receive mail still show up in the output.
+func NewRecorder(inboxes ...string) *Recorder {
+	r := &Recorder{received: make(map[string]uint64)}
+	for _, inbox := range inboxes {
+		r.received[inbox] = 0
+	}
+	return r
+}
+
+// EmailReceived counts an email for the inbox and records its receive time.
+func (r *Recorder) EmailReceived(inbox string, receivedAt time.Time) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.received[inbox]++
+	if receivedAt.After(r.lastEmail) {
+		r.lastEmail = receivedAt
+	}
+}
+
+// Reconnected counts a re-established inbox subscription.
+func (r *Recorder) Reconnected() {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.reconnects++
+}
+
+// SetUp records whether the watcher is connected.
+func (r *Recorder) SetUp(up bool) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.up = up
+}
+
+// Stats is a point-in-time copy of the recorded values. It is also the
+// format of the --metrics-file JSON.
+type Stats struct {
+	EmailsReceived      map[string]uint64 `json:"emailsReceived"`
+	EmailsReceivedTotal uint64            `json:"emailsReceivedTotal"`
+	SSEReconnects       uint64            `json:"sseReconnects"`
+	LastEmailAt         *time.Time        `json:"lastEmailAt,omitempty"`
+	WatchUp             bool              `json:"watchUp"`
+	UpdatedAt           time.Time         `json:"updatedAt"`
+}
+
+// Snapshot returns a copy of the current values.
+func (r *Recorder) Snapshot() Stats {
+	if r == nil {
+		return Stats{EmailsReceived: map[string]uint64{}, UpdatedAt: time.Now().UTC()}
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stats := Stats{
+		EmailsReceived: make(map[string]uint64, len(r.received)),
+		SSEReconnects:  r.reconnects,
+		WatchUp:        r.up,
+		UpdatedAt:      time.Now().UTC(),
+	}
+	for inbox, n := range r.received {
+		stats.EmailsReceived[inbox] = n
+		stats.EmailsReceivedTotal += n
+	}
+	if !r.lastEmail.IsZero() {
+		last := r.lastEmail.UTC()
+		stats.LastEmailAt = &last
+	}
+	return stats
+}
+
+// WritePrometheus writes the metrics in Prometheus text exposition format.
+func (r *Recorder) WritePrometheus(w io.Writer) error {
+	stats := r.Snapshot()
+
+	var b strings.Builder
+	b.WriteString("# HELP emails_received_total Emails received by the watcher.\n")
+	b.WriteString("# TYPE emails_received_total counter\n")
+	inboxes := make([]string, 0, len(stats.EmailsReceived))
+	for inbox := range stats.EmailsReceived {
+		inboxes = append(inboxes, inbox)
+	}
+	sort.Strings(inboxes)
+	for _, inbox := range inboxes {
+		fmt.Fprintf(&b, "emails_received_total{inbox=\"%s\"} %d\n", escapeLabel(inbox), stats.EmailsReceived[inbox])
+	}
+
+	b.WriteString("# HELP sse_reconnects_total Times the inbox subscription was re-established.\n")
+	b.WriteString("# TYPE sse_reconnects_total counter\n")
+	fmt.Fprintf(&b, "sse_reconnects_total %d\n", stats.SSEReconnects)
+
+	b.WriteString("# HELP last_email_timestamp_seconds Receive time of the newest email, as a Unix timestamp (0 if none).\n")
+	b.WriteString("# TYPE last_email_timestamp_seconds gauge\n")
+	var last int64
+	if stats.LastEmailAt != nil {
+		last = stats.LastEmailAt.Unix()
+	}
+	fmt.Fprintf(&b, "last_email_timestamp_seconds %d\n", last)
+
+	b.WriteString("# HELP watch_up Whether the watcher is connected (1) or not (0).\n")
+	b.WriteString("# TYPE watch_up gauge\n")
+	up := 0
+	if stats.WatchUp {
+		up = 1
+	}
+	fmt.Fprintf(&b, "watch_up %d\n", up)
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// escapeLabel escapes a Prometheus label value.
+func escapeLabel(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s)
+}
+
+// Handler serves the metrics at /metrics.
+func (r *Recorder) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		_ = r.WritePrometheus(w)
+	})
+	return mux
+}
+
+// shutdownTimeout bounds how long Listen's stop function waits for
+// in-flight scrapes.
+const shutdownTimeout = 2 * time.Second
+
+// Listen binds addr and serves h in the background. Binding happens before
+// Listen returns, so a busy port is reported immediately. It returns the
+// bound address and a function that shuts the server down.
+func Listen(addr string, h http.Handler) (bound string, stop func(), err error) {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return "", nil, err
+	}
+
+	srv := &http.Server{Handler: h, ReadHeaderTimeout: 5 * time.Second}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = srv.Serve(ln)
+	}()
+
+	return ln.Addr().String(), func() {
+		ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		_ = srv.Shutdown(ctx)
+		<-done
+	}, nil
+}
+
+// WriteFile atomically replaces path with the current stats as JSON.
+func (r *Recorder) WriteFile(path string) error {
+	data, err := json.MarshalIndent(r.Snapshot(), "", "  ")
+	if err != nil {
+		return err
+	}
+	return config.WriteFileAtomic(path, append(data, '\n'), 0644)
+}
+
+// WriteFileEvery rewrites path every interval until ctx is done, then writes
+// it one last time. Write errors are ignored so a full disk doesn't stop the
+// watcher; WriteFile can be called first to check the path is writable.
+func (r *Recorder) WriteFileEvery(ctx context.Context, path string, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			_ = r.WriteFile(path)
+			return
+		case <-ticker.C:
+			_ = r.WriteFile(path)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package metrics
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func scrape(t *testing.T, r *Recorder) string {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	r.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Header().Get("Content-Type"), "text/plain")
+	return rec.Body.String()
+}
+
+func TestRecorderPrometheus(t *testing.T) {
+	r := NewRecorder("a@vsx.email", "b@vsx.email")
+
+	body := scrape(t, r)
+	assert.Contains(t, body, `emails_received_total{inbox="a@vsx.email"} 0`)
+	assert.Contains(t, body, `emails_received_total{inbox="b@vsx.email"} 0`)
+	assert.Contains(t, body, "last_email_timestamp_seconds 0\n")
+	assert.Contains(t, body, "watch_up 0\n")
+
+	received := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	r.SetUp(true)
+	r.EmailReceived("a@vsx.email", received)
+	r.EmailReceived("a@vsx.email", received.Add(-time.Hour))
+	r.Reconnected()
+
+	body = scrape(t, r)
+	assert.Contains(t, body, `emails_received_total{inbox="a@vsx.email"} 2`)
+	assert.Contains(t, body, `emails_received_total{inbox="b@vsx.email"} 0`)
+	assert.Contains(t, body, "sse_reconnects_total 1\n")
+	assert.Contains(t, body, "last_email_timestamp_seconds 1772366400\n")
+	assert.Contains(t, body, "watch_up 1\n")
+	assert.Contains(t, body, "# TYPE emails_received_total counter")
+}
+
+func TestEscapeLabel(t *testing.T) {
+	assert.Equal(t, `a\"b\\c\nd`, escapeLabel("a\"b\\c\nd"))
+}
+
+func TestNilRecorder(t *testing.T) {
+	var r *Recorder
+	assert.NotPanics(t, func() {
+		r.EmailReceived("a@vsx.email", time.Now())
+		r.Reconnected()
+		r.SetUp(true)
+	})
+
+	stats := r.Snapshot()
+	assert.Empty(t, stats.EmailsReceived)
+	assert.Zero(t, stats.EmailsReceivedTotal)
+	assert.False(t, stats.WatchUp)
+
+	body := scrape(t, r)
+	assert.Contains(t, body, "sse_reconnects_total 0\n")
+	assert.Contains(t, body, "watch_up 0\n")
+
+	path := filepath.Join(t.TempDir(), "stats.json")
+	require.NoError(t, r.WriteFile(path))
+	assert.FileExists(t, path)
+}
+
+func TestWriteFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "stats.json")
+	r := NewRecorder("a@vsx.email")
+	r.EmailReceived("a@vsx.email", time.Now())
+	r.SetUp(true)
+
+	require.NoError(t, r.WriteFile(path))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	var stats Stats
+	require.NoError(t, json.Unmarshal(data, &stats))
+	assert.Equal(t, uint64(1), stats.EmailsReceived["a@vsx.email"])
+	assert.Equal(t, uint64(1), stats.EmailsReceivedTotal)
+	assert.True(t, stats.WatchUp)
+	assert.NotNil(t, stats.LastEmailAt)
+}
+
+func TestWriteFileEvery(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "stats.json")
+	r := NewRecorder("a@vsx.email")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		r.WriteFileEvery(ctx, path, time.Hour)
+		close(done)
+	}()
+
+	r.EmailReceived("a@vsx.email", time.Now())
+	cancel()
+	<-done
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"emailsReceivedTotal": 1`)
+}
+
+func TestListen(t *testing.T) {
+	r := NewRecorder("a@vsx.email")
+
+	t.Run("serves metrics until stopped", func(t *testing.T) {
+		addr, stop, err := Listen("127.0.0.1:0", r.Handler())
+		require.NoError(t, err)
+
+		resp, err := http.Get("http://" + addr + "/metrics")
+		require.NoError(t, err)
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		assert.Contains(t, string(body), "watch_up")
+
+		stop()
+		_, err = http.Get("http://" + addr + "/metrics")
+		assert.Error(t, err)
+	})
+
+	t.Run("busy port is reported", func(t *testing.T) {
+		srv := httptest.NewServer(http.NotFoundHandler())
+		defer srv.Close()
+
+		_, _, err := Listen(strings.TrimPrefix(srv.URL, "http://"), r.Handler())
+		assert.Error(t, err)
+	})
+}
+
+func TestHandlerOnlyServesMetrics(t *testing.T) {
+	srv := httptest.NewServer(NewRecorder().Handler())
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/")
+	require.NoError(t, err)
+	_, _ = io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
@@ -0,0 +1,224 @@
+// Package mimewalk parses a raw RFC 5322 message into its MIME part tree and
+// decodes individual parts. Malformed input is reported on the affected part
+// rather than failing the whole parse, since the point is usually to debug a
+// broken message.
+package mimewalk
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"strconv"
+	"strings"
+)
+
+// Part is a node in a message's MIME tree.
+type Part struct {
+	// Path is the IMAP-style part number, e.g. "1.2". A multipart message's
+	// root has an empty path; a single-part message's root is "1".
+	Path        string  `json:"path"`
+	ContentType string  `json:"contentType"`
+	Charset     string  `json:"charset,omitempty"`
+	Encoding    string  `json:"encoding,omitempty"`
+	Disposition string  `json:"disposition,omitempty"`
+	Filename    string  `json:"filename,omitempty"`
+	Size        int     `json:"size"`
+	Error       string  `json:"error,omitempty"`
+	Children    []*Part `json:"children,omitempty"`
+
+	Header textproto.MIMEHeader `json:"-"`
+	body   []byte
+}
+
+// wordDecoder decodes RFC 2047 encoded filenames
+var wordDecoder = mime.WordDecoder{}
+
+// Parse reads a raw message and returns its part tree. It only fails if the
+// message headers can't be read at all.
+func Parse(raw []byte) (*Part, error) {
+	msg, err := mail.ReadMessage(bufio.NewReader(bytes.NewReader(raw)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse message: %w", err)
+	}
+	body, err := io.ReadAll(msg.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read message body: %w", err)
+	}
+
+	root := newPart(textproto.MIMEHeader(msg.Header), body, "")
+	if !strings.HasPrefix(root.ContentType, "multipart/") {
+		root.Path = "1"
+	}
+	return root, nil
+}
+
+// newPart builds the part for an entity and, for multiparts, its children.
+func newPart(header textproto.MIMEHeader, body []byte, path string) *Part {
+	p := &Part{
+		Path:        path,
+		ContentType: "text/plain",
+		Encoding:    strings.ToLower(strings.TrimSpace(header.Get("Content-Transfer-Encoding"))),
+		Header:      header,
+		body:        body,
+	}
+
+	var params map[string]string
+	if ct := header.Get("Content-Type"); ct != "" {
+		mediaType, ctParams, err := mime.ParseMediaType(ct)
+		if err != nil && mediaType == "" {
+			p.Error = fmt.Sprintf("invalid Content-Type %q", ct)
+		} else {
+			p.ContentType = mediaType
+			params = ctParams
+		}
+	}
+	p.Charset = params["charset"]
+
+	if cd := header.Get("Content-Disposition"); cd != "" {
+		disposition, cdParams, _ := mime.ParseMediaType(cd)
+		p.Disposition = disposition
+		p.Filename = cdParams["filename"]
+	}
+	if p.Filename == "" {
+		p.Filename = params["name"]
+	}
+	if decoded, err := wordDecoder.DecodeHeader(p.Filename); err == nil {
+		p.Filename = decoded
+	}
+
+	if strings.HasPrefix(p.ContentType, "multipart/") {
+		p.parseChildren(params["boundary"])
+		p.Size = len(body)
+		return p
+	}
+
+	if decoded, err := p.Decode(); err == nil {
+		p.Size = len(decoded)
+	} else {
+		p.Size = len(body)
+		if p.Error == "" {
+			p.Error = err.Error()
+		}
+	}
+	return p
+}
+
+// parseChildren splits a multipart body. Parts read before an error are
+// kept and the error is recorded on p.
+func (p *Part) parseChildren(boundary string) {
+	if boundary == "" {
+		p.Error = "multipart without boundary"
+		return
+	}
+
+	reader := multipart.NewReader(bytes.NewReader(p.body), boundary)
+	for i := 1; ; i++ {
+		// NextRawPart leaves quoted-printable bodies encoded so Decode sees
+		// them the same way as every other part
+		mp, err := reader.NextRawPart()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			p.Error = fmt.Sprintf("malformed multipart: %v", err)
+			break
+		}
+		body, err := io.ReadAll(mp)
+		if err != nil {
+			p.Error = fmt.Sprintf("malformed multipart: %v", err)
+		}
+		p.Children = append(p.Children, newPart(mp.Header, body, childPath(p.Path, i)))
+		if err != nil {
+			break
+		}
+	}
+	if len(p.Children) == 0 && p.Error == "" {
+		p.Error = "multipart has no parts (boundary not found)"
+	}
+}
+
+func childPath(parent string, i int) string {
+	if parent == "" {
+		return strconv.Itoa(i)
+	}
+	return parent + "." + strconv.Itoa(i)
+}
+
+// Decode returns the part body with its Content-Transfer-Encoding removed.
+// On a decoding error it returns the bytes decoded so far with the error.
+func (p *Part) Decode() ([]byte, error) {
+	switch p.Encoding {
+	case "base64":
+		return decodeBase64(p.body)
+	case "quoted-printable":
+		decoded, err := io.ReadAll(quotedprintable.NewReader(bytes.NewReader(p.body)))
+		if err != nil {
+			return decoded, fmt.Errorf("invalid quoted-printable: %w", err)
+		}
+		return decoded, nil
+	default:
+		return p.body, nil
+	}
+}
+
+// decodeBase64 decodes base64 ignoring line breaks and other whitespace,
+// tolerating missing padding.
+func decodeBase64(body []byte) ([]byte, error) {
+	clean := strings.Map(func(r rune) rune {
+		if r == ' ' || r == '\t' || r == '\r' || r == '\n' {
+			return -1
+		}
+		return r
+	}, string(body))
+
+	decoded, err := base64.StdEncoding.DecodeString(clean)
+	if err == nil {
+		return decoded, nil
+	}
+	if raw, rawErr := base64.RawStdEncoding.DecodeString(strings.TrimRight(clean, "=")); rawErr == nil {
+		return raw, nil
+	}
+	return decoded, fmt.Errorf("invalid base64: %w", err)
+}
+
+// IsText reports whether the part is human-readable text.
+func (p *Part) IsText() bool {
+	return strings.HasPrefix(p.ContentType, "text/") ||
+		p.ContentType == "message/rfc822" ||
+		strings.HasSuffix(p.ContentType, "+xml") ||
+		strings.HasSuffix(p.ContentType, "/json")
+}
+
+// Find returns the part with the given path, or nil.
+func (p *Part) Find(path string) *Part {
+	if p.Path == path {
+		return p
+	}
+	for _, c := range p.Children {
+		if found := c.Find(path); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// Walk calls fn for p and every descendant, depth-first, with the depth
+// below p.
+func (p *Part) Walk(fn func(part *Part, depth int)) {
+	var walk func(part *Part, depth int)
+	walk = func(part *Part, depth int) {
+		fn(part, depth)
+		for _, c := range part.Children {
+			walk(c, depth+1)
+		}
+	}
+	walk(p, 0)
+}
@@ -0,0 +1,210 @@
+package mimewalk
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// crlf converts a readable fixture to the CRLF line endings used on the wire.
+// A literal \x01 in the fixture becomes a control byte.
+func crlf(s string) []byte {
+	s = strings.ReplaceAll(s, `\x01`, "\x01")
+	return []byte(strings.ReplaceAll(s, "\n", "\r\n"))
+}
+
+var nestedMessage = crlf(`From: shop@example.com
+To: user@example.com
+Subject: Order
+MIME-Version: 1.0
+Content-Type: multipart/mixed; boundary="outer"
+
+preamble
+--outer
+Content-Type: multipart/alternative; boundary="inner"
+
+--inner
+Content-Type: text/plain; charset=utf-8
+Content-Transfer-Encoding: quoted-printable
+
+Caf=C3=A9 order =
+confirmed
+--inner
+Content-Type: text/html; charset="iso-8859-1"
+Content-Transfer-Encoding: base64
+
+PHA+SGk8L3A+
+--inner--
+--outer
+Content-Type: image/png; name="logo.png"
+Content-Disposition: inline; filename="logo.png"
+Content-Transfer-Encoding: base64
+Content-ID: <logo>
+
+iVBORw0K
+GgoAAAAN
+--outer
+Content-Type: application/pdf
+Content-Disposition: attachment; filename="=?utf-8?q?Rechnung_M=C3=A4rz.pdf?="
+
+%PDF-1.4
+--outer--
+`)
+
+func TestParseNested(t *testing.T) {
+	root, err := Parse(nestedMessage)
+	require.NoError(t, err)
+
+	assert.Equal(t, "", root.Path)
+	assert.Equal(t, "multipart/mixed", root.ContentType)
+	assert.Empty(t, root.Error)
+	require.Len(t, root.Children, 3)
+
+	alt := root.Children[0]
+	assert.Equal(t, "1", alt.Path)
+	assert.Equal(t, "multipart/alternative", alt.ContentType)
+	require.Len(t, alt.Children, 2)
+
+	plain := alt.Children[0]
+	assert.Equal(t, "1.1", plain.Path)
+	assert.Equal(t, "text/plain", plain.ContentType)
+	assert.Equal(t, "utf-8", plain.Charset)
+	assert.Equal(t, "quoted-printable", plain.Encoding)
+	body, err := plain.Decode()
+	require.NoError(t, err)
+	assert.Equal(t, "Café order confirmed", string(body))
+	assert.Equal(t, len(body), plain.Size)
+
+	html := root.Find("1.2")
+	require.NotNil(t, html)
+	assert.Equal(t, "iso-8859-1", html.Charset)
+	body, err = html.Decode()
+	require.NoError(t, err)
+	assert.Equal(t, "<p>Hi</p>", string(body))
+
+	logo := root.Find("2")
+	require.NotNil(t, logo)
+	assert.Equal(t, "inline", logo.Disposition)
+	assert.Equal(t, "logo.png", logo.Filename)
+	assert.Equal(t, 12, logo.Size)
+	assert.Equal(t, "<logo>", logo.Header.Get("Content-ID"))
+	assert.False(t, logo.IsText())
+
+	pdf := root.Find("3")
+	require.NotNil(t, pdf)
+	assert.Equal(t, "attachment", pdf.Disposition)
+	assert.Equal(t, "Rechnung März.pdf", pdf.Filename)
+	assert.Equal(t, "", pdf.Encoding)
+
+	assert.Nil(t, root.Find("4"))
+	assert.Nil(t, root.Find("1.3"))
+}
+
+func TestParseSinglePart(t *testing.T) {
+	root, err := Parse(crlf("Subject: plain\n\nhello\n"))
+	require.NoError(t, err)
+
+	assert.Equal(t, "1", root.Path)
+	assert.Equal(t, "text/plain", root.ContentType)
+	assert.Empty(t, root.Children)
+	assert.Same(t, root, root.Find("1"))
+	assert.True(t, root.IsText())
+}
+
+func TestParseMissingBoundary(t *testing.T) {
+	root, err := Parse(crlf("Content-Type: multipart/mixed\n\n--x\n\nbody\n--x--\n"))
+	require.NoError(t, err)
+	assert.Equal(t, "multipart without boundary", root.Error)
+	assert.Empty(t, root.Children)
+}
+
+func TestParseBoundaryNotFound(t *testing.T) {
+	root, err := Parse(crlf("Content-Type: multipart/mixed; boundary=abc\n\njust text\n"))
+	require.NoError(t, err)
+	assert.Contains(t, root.Error, "boundary not found")
+	assert.Empty(t, root.Children)
+}
+
+func TestParseTruncatedMultipart(t *testing.T) {
+	root, err := Parse(crlf(`Content-Type: multipart/mixed; boundary="b"
+
+--b
+Content-Type: text/plain
+
+first
+--b
+Content-Type: text/plain
+
+second, never closed`))
+	require.NoError(t, err)
+
+	assert.Contains(t, root.Error, "malformed multipart")
+	require.NotEmpty(t, root.Children)
+	body, err := root.Children[0].Decode()
+	require.NoError(t, err)
+	assert.Equal(t, "first", string(body))
+}
+
+func TestBrokenEncodings(t *testing.T) {
+	root, err := Parse(crlf(`Content-Type: multipart/mixed; boundary="b"
+
+--b
+Content-Type: text/plain
+Content-Transfer-Encoding: base64
+
+not*valid*base64
+--b
+Content-Type: text/plain
+Content-Transfer-Encoding: quoted-printable
+
+ok\x01 bad
+--b
+Content-Type: text/plain
+Content-Transfer-Encoding: base64
+
+aGVsbG8
+--b
+Content-Type: ;;;
+
+x
+--b--
+`))
+	require.NoError(t, err)
+	require.Len(t, root.Children, 4)
+
+	badB64 := root.Children[0]
+	assert.Contains(t, badB64.Error, "invalid base64")
+	assert.Equal(t, len("not*valid*base64"), badB64.Size)
+
+	badQP := root.Children[1]
+	assert.Contains(t, badQP.Error, "invalid quoted-printable")
+	partial, err := badQP.Decode()
+	assert.Error(t, err)
+	assert.Equal(t, "ok", string(partial))
+
+	unpadded, err := root.Children[2].Decode()
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(unpadded))
+
+	badType := root.Children[3]
+	assert.Equal(t, "text/plain", badType.ContentType)
+	assert.Contains(t, badType.Error, "invalid Content-Type")
+}
+
+func TestParseInvalidMessage(t *testing.T) {
+	_, err := Parse([]byte("no headers here"))
+	assert.Error(t, err)
+}
+
+func TestWalk(t *testing.T) {
+	root, err := Parse(nestedMessage)
+	require.NoError(t, err)
+
+	var visited []string
+	root.Walk(func(p *Part, depth int) {
+		visited = append(visited, strings.Repeat(">", depth)+p.Path)
+	})
+	assert.Equal(t, []string{"", ">1", ">>1.1", ">>1.2", ">2", ">3"}, visited)
+}
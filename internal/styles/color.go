@@ -0,0 +1,88 @@
+package styles
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+// Values of the --color flag
+const (
+	ColorAuto   = "auto"   // color on a terminal, unless NO_COLOR is set
+	ColorAlways = "always" // color even when piped, and despite NO_COLOR
+	ColorNever  = "never"  // no color, as with NO_COLOR
+)
+
+// ColorMode is the pflag.Value of --color. Apply it with SetColorMode.
+type ColorMode string
+
+func (m *ColorMode) String() string {
+	if *m == "" {
+		return ColorAuto
+	}
+	return string(*m)
+}
+
+func (m *ColorMode) Set(value string) error {
+	switch mode := strings.ToLower(value); mode {
+	case ColorAuto, ColorAlways, ColorNever:
+		*m = ColorMode(mode)
+		return nil
+	}
+	return fmt.Errorf("must be auto, always or never")
+}
+
+func (m *ColorMode) Type() string { return "when" }
+
+// colorMode is the mode set by SetColorMode
+var colorMode ColorMode = ColorAuto
+
+// SetColorMode decides whether the styles render colors. It is the one place
+// that does: everything styled with this package, tables and the TUI
+// follow it.
+func SetColorMode(mode ColorMode) {
+	if mode == "" {
+		mode = ColorAuto
+	}
+	if mode == colorMode {
+		return
+	}
+	colorMode = mode
+
+	switch mode {
+	case ColorAlways:
+		lipgloss.SetColorProfile(forcedColorProfile())
+	case ColorNever:
+		lipgloss.SetColorProfile(termenv.Ascii)
+	default:
+		// What lipgloss detects on its own: none when stdout is not a
+		// terminal or NO_COLOR is set
+		lipgloss.SetColorProfile(termenv.NewOutput(os.Stdout).EnvColorProfile())
+	}
+}
+
+// forcedColorProfile returns the colors the terminal supports as if stdout
+// were one, and at least 256 colors when the environment does not say
+func forcedColorProfile() termenv.Profile {
+	profile := termenv.NewOutput(os.Stdout, termenv.WithTTY(true)).ColorProfile()
+	if profile == termenv.Ascii {
+		return termenv.ANSI256
+	}
+	return profile
+}
+
+// UseColor reports whether output styled outside lipgloss, such as table
+// headers and emoji markers, should be: with --color auto only on a
+// terminal (tty) and without NO_COLOR.
+func UseColor(tty bool) bool {
+	switch colorMode {
+	case ColorAlways:
+		return true
+	case ColorNever:
+		return false
+	}
+	return tty && os.Getenv("NO_COLOR") == ""
+}
@@ -0,0 +1,68 @@
+package styles
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestColorModeSet(t *testing.T) {
+	var m ColorMode
+	assert.Equal(t, ColorAuto, m.String())
+
+	for _, value := range []string{"auto", "always", "never", "NEVER"} {
+		assert.NoError(t, m.Set(value), value)
+	}
+	assert.Equal(t, ColorNever, m.String())
+
+	assert.EqualError(t, m.Set("yes"), "must be auto, always or never")
+	assert.Equal(t, ColorNever, m.String(), "a bad value leaves the mode unchanged")
+}
+
+func TestSetColorMode(t *testing.T) {
+	t.Cleanup(func() { SetColorMode(ColorAuto) })
+
+	t.Run("never matches NO_COLOR byte for byte", func(t *testing.T) {
+		t.Setenv("NO_COLOR", "1")
+		SetColorMode(ColorAlways)
+		SetColorMode(ColorAuto)
+		noColor := renderSample()
+
+		t.Setenv("NO_COLOR", "")
+		SetColorMode(ColorNever)
+		assert.Equal(t, noColor, renderSample())
+		assert.NotContains(t, noColor, "\x1b[")
+	})
+
+	t.Run("always colors piped output despite NO_COLOR", func(t *testing.T) {
+		t.Setenv("NO_COLOR", "1")
+		SetColorMode(ColorAlways)
+		assert.Contains(t, renderSample(), "\x1b[")
+	})
+}
+
+func renderSample() string {
+	return PassStyle.Render("PASS") + SectionStyle.Render("SECTION") + BoxStyle.Render("box")
+}
+
+func TestUseColor(t *testing.T) {
+	t.Cleanup(func() { SetColorMode(ColorAuto) })
+
+	tests := []struct {
+		mode    ColorMode
+		noColor string
+		tty     bool
+		want    bool
+	}{
+		{ColorAuto, "", true, true},
+		{ColorAuto, "", false, false},
+		{ColorAuto, "1", true, false},
+		{ColorAlways, "1", false, true},
+		{ColorNever, "", true, false},
+	}
+	for _, tt := range tests {
+		t.Setenv("NO_COLOR", tt.noColor)
+		SetColorMode(tt.mode)
+		assert.Equal(t, tt.want, UseColor(tt.tty), "mode=%s NO_COLOR=%q tty=%v", tt.mode, tt.noColor, tt.tty)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/charmbracelet/lipgloss"
 	vaultsandbox "github.com/vaultsandbox/client-go"
 	"github.com/vaultsandbox/client-go/authresults"
+	"github.com/vaultsandbox/vsb-cli/internal/analysis"
 )
 
 var (
@@ -285,6 +286,26 @@ func RenderAuthResults(auth *authresults.AuthResults, labelStyle lipgloss.Style,
 	return strings.Join(lines, "\n")
 }
 
+// RenderAuthExplanations renders explanations of failed authentication
+// checks as an indented list, one block per mechanism.
+func RenderAuthExplanations(explanations []analysis.AuthExplanation) string {
+	var lines []string
+	for _, e := range explanations {
+		heading := fmt.Sprintf("%s %s", strings.ToUpper(e.Mechanism), FormatAuthResult(e.Result))
+		if e.Domain != "" {
+			heading += " " + MutedStyle.Render("("+e.Domain+")")
+		}
+		lines = append(lines, "  "+heading)
+		lines = append(lines, "    "+e.Problem)
+		lines = append(lines, "    "+MutedStyle.Render("Fix:")+" "+e.Fix)
+		if e.Record != "" {
+			lines = append(lines, "    "+MutedStyle.Render("Record:")+" "+e.Record)
+		}
+		lines = append(lines, "    "+MutedStyle.Render("Docs:")+" "+e.DocURL)
+	}
+	return strings.Join(lines, "\n")
+}
+
 // CalculateScore computes a security score (0-100) based on auth results.
 // Base score of 50 assumes E2E encryption.
 func CalculateScore(email *vaultsandbox.Email) int {
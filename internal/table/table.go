@@ -0,0 +1,236 @@
+// Package table renders column-aligned text tables that fit the terminal.
+//
+// Fixed columns (ids, timestamps) always get their width. Flexible columns
+// (subjects, senders) get their preferred width when there is room and
+// shrink in proportion when there is not, with cells cut short by an
+// ellipsis. Wide mode turns truncation off for output that must keep every
+// character.
+package table
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/vaultsandbox/vsb-cli/internal/styles"
+	"golang.org/x/term"
+)
+
+// DefaultWidth is the width used when stdout is not a terminal and COLUMNS
+// is not set, e.g. in CI logs.
+const DefaultWidth = 120
+
+// columnGap separates adjacent columns
+const columnGap = "  "
+
+// minFlexWidth is the narrowest a flexible column shrinks to, unless its
+// header is wider
+const minFlexWidth = 8
+
+// terminalWidth returns the width of the terminal on stdout, if it is one;
+// overridden in tests
+var terminalWidth = func() (int, bool) {
+	fd := int(os.Stdout.Fd())
+	if !term.IsTerminal(fd) {
+		return 0, false
+	}
+	width, _, err := term.GetSize(fd)
+	return width, err == nil && width > 0
+}
+
+// Width returns the width to render tables at: COLUMNS when set to a
+// positive number, else the terminal width, else DefaultWidth.
+func Width() int {
+	if n, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && n > 0 {
+		return n
+	}
+	if width, ok := terminalWidth(); ok {
+		return width
+	}
+	return DefaultWidth
+}
+
+// Column defines a table column.
+type Column struct {
+	Header string
+	Width  int            // fixed width, or preferred width when Flex; 0 means unpadded
+	Flex   bool           // shrink to fit the available width
+	Style  lipgloss.Style // optional style for cell values
+	styled bool           // internal: true if Style was explicitly set
+}
+
+// WithStyle returns a copy of the column with the given style applied.
+func (c Column) WithStyle(s lipgloss.Style) Column {
+	c.Style = s
+	c.styled = true
+	return c
+}
+
+// Table writes rows aligned to column widths laid out for a total width.
+type Table struct {
+	w       io.Writer
+	columns []Column
+	widths  []int
+	total   int
+	indent  string
+	wide    bool
+}
+
+// New creates a table laid out to fit width columns of text.
+func New(w io.Writer, width int, columns ...Column) *Table {
+	t := &Table{w: w, columns: columns, total: width, indent: "  "}
+	t.widths = Layout(columns, width-len(t.indent))
+	return t
+}
+
+// WithIndent sets a custom indent string and returns the table for chaining.
+func (t *Table) WithIndent(indent string) *Table {
+	t.indent = indent
+	t.widths = Layout(t.columns, t.total-len(indent))
+	return t
+}
+
+// WithWide disables truncation when wide is true: cells are padded to their
+// column but never cut.
+func (t *Table) WithWide(wide bool) *Table {
+	t.wide = wide
+	return t
+}
+
+// Widths returns the laid out column widths.
+func (t *Table) Widths() []int {
+	return t.widths
+}
+
+// WriteHeader writes a blank line, the styled header row and a separator
+// line.
+func (t *Table) WriteHeader() {
+	headerStyle := styles.HeaderStyle.MarginBottom(0)
+	headers := make([]string, len(t.columns))
+	totalWidth := len(t.indent) + len(columnGap)*max(len(t.columns)-1, 0)
+	for i, col := range t.columns {
+		if t.widths[i] > 0 {
+			header := col.Header
+			if i < len(t.columns)-1 {
+				header = pad(header, t.widths[i])
+			}
+			headers[i] = headerStyle.Render(header)
+			totalWidth += t.widths[i]
+		} else {
+			headers[i] = headerStyle.Render(col.Header)
+			totalWidth += lipgloss.Width(col.Header)
+		}
+	}
+
+	fmt.Fprintln(t.w)
+	fmt.Fprintf(t.w, "%s%s\n", t.indent, strings.Join(headers, columnGap))
+	fmt.Fprintln(t.w, strings.Repeat("-", totalWidth))
+}
+
+// WriteRow writes a data row. If a column has a Style set, it is applied to
+// the cell value after padding.
+func (t *Table) WriteRow(values ...string) {
+	cells := make([]string, len(values))
+	for i, val := range values {
+		cell := val
+		if i < len(t.columns) {
+			if i < len(values)-1 {
+				cell = t.Cell(i, val)
+			} else {
+				cell = t.fit(i, val)
+			}
+			if t.columns[i].styled {
+				cell = t.columns[i].Style.Render(cell)
+			}
+		}
+		cells[i] = cell
+	}
+	fmt.Fprintf(t.w, "%s%s\n", t.indent, strings.Join(cells, columnGap))
+}
+
+// Cell returns value fitted to column i: truncated unless the table is wide,
+// then padded to the column width.
+func (t *Table) Cell(i int, value string) string {
+	return pad(t.fit(i, value), t.widths[i])
+}
+
+func (t *Table) fit(i int, value string) string {
+	if t.wide || t.widths[i] == 0 {
+		return value
+	}
+	return Truncate(value, t.widths[i])
+}
+
+// Layout returns the width of each column for a row of at most width
+// characters. Fixed columns keep their width; flexible ones get their
+// preferred width if it fits and otherwise share the space left in
+// proportion to it, down to a minimum. A row can still overflow when even
+// the minimums do not fit.
+func Layout(columns []Column, width int) []int {
+	widths := make([]int, len(columns))
+	available := width - len(columnGap)*max(len(columns)-1, 0)
+	preferred := 0
+	for i, col := range columns {
+		widths[i] = col.Width
+		if col.Flex {
+			preferred += col.Width
+		} else {
+			available -= col.Width
+		}
+	}
+	if preferred <= available {
+		return widths
+	}
+
+	// Shrink in proportion to the preferred widths, handing the rounding
+	// remainder to the leftmost flexible columns
+	remaining := max(available, 0)
+	for i, col := range columns {
+		if col.Flex {
+			widths[i] = available * col.Width / preferred
+			remaining -= widths[i]
+		}
+	}
+	for i, col := range columns {
+		if col.Flex && remaining > 0 {
+			widths[i]++
+			remaining--
+		}
+	}
+	for i, col := range columns {
+		if col.Flex {
+			widths[i] = max(widths[i], minFlexWidth, lipgloss.Width(col.Header))
+		}
+	}
+	return widths
+}
+
+// Truncate shortens s to at most width columns, ending in an ellipsis when
+// cut. It never splits a multi-byte character.
+func Truncate(s string, width int) string {
+	if lipgloss.Width(s) <= width {
+		return s
+	}
+	if width <= 0 {
+		return ""
+	}
+	var b strings.Builder
+	used := 0
+	for _, r := range s {
+		w := lipgloss.Width(string(r))
+		if used+w > width-1 {
+			break
+		}
+		b.WriteRune(r)
+		used += w
+	}
+	return b.String() + "…"
+}
+
+// pad right-pads s with spaces to width columns
+func pad(s string, width int) string {
+	return s + strings.Repeat(" ", max(width-lipgloss.Width(s), 0))
+}
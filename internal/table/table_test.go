@@ -0,0 +1,105 @@
+package table
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testColumns = []Column{
+	{Header: "ID", Width: 4},
+	{Header: "SUBJECT", Width: 30, Flex: true},
+	{Header: "FROM", Width: 20, Flex: true},
+	{Header: "RECEIVED", Width: 10},
+}
+
+var testRows = [][]string{
+	{"a1b2", "Please confirm your email address for Acme", "no-reply@accounts.acme.test", "2m ago"},
+	{"c3d4", "Hi", "bob@x.test", "1h ago"},
+}
+
+func render(width int, wide bool) string {
+	var buf bytes.Buffer
+	t := New(&buf, width, testColumns...).WithWide(wide)
+	t.WriteHeader()
+	for _, row := range testRows {
+		t.WriteRow(row...)
+	}
+	return buf.String()
+}
+
+func TestTableRender(t *testing.T) {
+	t.Run("120 columns fits preferred widths", func(t *testing.T) {
+		assert.Equal(t, "\n"+
+			"  ID    SUBJECT                         FROM                  RECEIVED\n"+
+			strings.Repeat("-", 72)+"\n"+
+			"  a1b2  Please confirm your email add…  no-reply@accounts.a…  2m ago\n"+
+			"  c3d4  Hi                              bob@x.test            1h ago\n",
+			render(120, false))
+	})
+
+	t.Run("60 columns shrinks flexible columns", func(t *testing.T) {
+		assert.Equal(t, "\n"+
+			"  ID    SUBJECT                  FROM             RECEIVED\n"+
+			strings.Repeat("-", 60)+"\n"+
+			"  a1b2  Please confirm your em…  no-reply@accou…  2m ago\n"+
+			"  c3d4  Hi                       bob@x.test       1h ago\n",
+			render(60, false))
+	})
+
+	t.Run("40 columns stops at the minimum width", func(t *testing.T) {
+		assert.Equal(t, "\n"+
+			"  ID    SUBJECT      FROM      RECEIVED\n"+
+			strings.Repeat("-", 41)+"\n"+
+			"  a1b2  Please con…  no-repl…  2m ago\n"+
+			"  c3d4  Hi           bob@x.t…  1h ago\n",
+			render(40, false))
+	})
+
+	t.Run("wide keeps whole cells", func(t *testing.T) {
+		out := render(60, true)
+		assert.Contains(t, out, "  a1b2  Please confirm your email address for Acme  no-reply@accounts.acme.test  2m ago\n")
+		assert.Contains(t, out, "  c3d4  Hi                       bob@x.test       1h ago\n")
+	})
+}
+
+func TestLayout(t *testing.T) {
+	assert.Equal(t, []int{4, 30, 20, 10}, Layout(testColumns, 200))
+	assert.Equal(t, []int{4, 23, 15, 10}, Layout(testColumns, 58))
+	assert.Equal(t, []int{4, 8, 8, 10}, Layout(testColumns, 10))
+	assert.Equal(t, []int{8}, Layout([]Column{{Header: "EMAIL", Width: 30, Flex: true}}, 3))
+	assert.Equal(t, []int{10}, Layout([]Column{{Header: "LAST EMAIL", Width: 30, Flex: true}}, 3))
+}
+
+func TestWidth(t *testing.T) {
+	orig := terminalWidth
+	t.Cleanup(func() { terminalWidth = orig })
+
+	t.Run("COLUMNS wins", func(t *testing.T) {
+		t.Setenv("COLUMNS", "90")
+		terminalWidth = func() (int, bool) { return 200, true }
+		assert.Equal(t, 90, Width())
+	})
+
+	t.Run("terminal width", func(t *testing.T) {
+		t.Setenv("COLUMNS", "")
+		terminalWidth = func() (int, bool) { return 100, true }
+		assert.Equal(t, 100, Width())
+	})
+
+	t.Run("default when not a terminal", func(t *testing.T) {
+		t.Setenv("COLUMNS", "junk")
+		terminalWidth = func() (int, bool) { return 0, false }
+		assert.Equal(t, DefaultWidth, Width())
+	})
+}
+
+func TestTruncate(t *testing.T) {
+	assert.Equal(t, "short", Truncate("short", 5))
+	assert.Equal(t, "shor…", Truncate("shorter", 5))
+	assert.Equal(t, "café…", Truncate("café crème", 5))
+	assert.Equal(t, "日本…", Truncate("日本語テキスト", 5))
+	assert.Equal(t, "", Truncate("abc", 0))
+}
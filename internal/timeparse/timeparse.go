@@ -0,0 +1,36 @@
+// Package timeparse parses durations and points in time given as flag values.
+package timeparse
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Duration parses a Go duration ("90m", "1h30m") or a whole number of
+// days ("7d"), which time.ParseDuration does not support.
+func Duration(s string) (time.Duration, error) {
+	if strings.HasSuffix(s, "d") {
+		days := strings.TrimSuffix(s, "d")
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, fmt.Errorf("invalid day value: %s", days)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	return time.ParseDuration(s)
+}
+
+// Time parses an RFC3339 timestamp or a duration relative to now: "2h"
+// means two hours before now.
+func Time(s string, now time.Time) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	d, err := Duration(s)
+	if err != nil || d < 0 {
+		return time.Time{}, fmt.Errorf("invalid time %q (use RFC3339 like 2026-01-02T15:04:05Z or a duration like 2h, 30m, 7d)", s)
+	}
+	return now.Add(-d), nil
+}
@@ -0,0 +1,71 @@
+package timeparse
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDuration(t *testing.T) {
+	tests := []struct {
+		input string
+		want  time.Duration
+	}{
+		{"30m", 30 * time.Minute},
+		{"1h30m", time.Hour + 30*time.Minute},
+		{"1d", 24 * time.Hour},
+		{"7d", 7 * 24 * time.Hour},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := Duration(tt.input)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	for _, input := range []string{"", "abc", "xd", "1.5d"} {
+		t.Run("invalid "+input, func(t *testing.T) {
+			_, err := Duration(input)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestTime(t *testing.T) {
+	now := time.Date(2026, 3, 1, 15, 0, 0, 0, time.UTC)
+
+	t.Run("RFC3339", func(t *testing.T) {
+		got, err := Time("2026-03-01T14:00:00Z", now)
+		require.NoError(t, err)
+		assert.Equal(t, time.Date(2026, 3, 1, 14, 0, 0, 0, time.UTC), got)
+	})
+
+	t.Run("RFC3339 with offset", func(t *testing.T) {
+		got, err := Time("2026-03-01T16:00:00+02:00", now)
+		require.NoError(t, err)
+		assert.True(t, got.Equal(time.Date(2026, 3, 1, 14, 0, 0, 0, time.UTC)))
+	})
+
+	t.Run("relative duration", func(t *testing.T) {
+		got, err := Time("2h", now)
+		require.NoError(t, err)
+		assert.Equal(t, now.Add(-2*time.Hour), got)
+	})
+
+	t.Run("relative days", func(t *testing.T) {
+		got, err := Time("1d", now)
+		require.NoError(t, err)
+		assert.Equal(t, now.Add(-24*time.Hour), got)
+	})
+
+	for _, input := range []string{"", "yesterday", "2026-03-01", "-2h"} {
+		t.Run("invalid "+input, func(t *testing.T) {
+			_, err := Time(input, now)
+			assert.Error(t, err)
+		})
+	}
+}
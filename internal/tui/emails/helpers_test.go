@@ -3,6 +3,7 @@ package emails
 import (
 	"os"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	vaultsandbox "github.com/vaultsandbox/client-go"
@@ -79,6 +80,16 @@ func TestEmailItemDescription(t *testing.T) {
 		// Should contain time in format "HH:MM:SS"
 		assert.Contains(t, desc, ":")
 	})
+
+	t.Run("truncates label and sender to keep the timestamp", func(t *testing.T) {
+		item := testEmailItem("1", "Subject", "sender@example.com", "a-very-long-inbox-label@vsx.email")
+		item.Email.ReceivedAt = time.Date(2026, 1, 2, 15, 4, 5, 0, time.Local)
+		item.width = 40
+		assert.Equal(t, "[a-very-long-inbox-label@vsx… • 15:04:05", item.Description())
+
+		item.width = 200
+		assert.Equal(t, "[a-very-long-inbox-label@vsx.email] From: sender@example.com • 15:04:05", item.Description())
+	})
 }
 
 func TestEmailItemFilterValue(t *testing.T) {
@@ -4,18 +4,22 @@ import "github.com/charmbracelet/bubbles/key"
 
 // KeyMap defines the keybindings
 type KeyMap struct {
-	Up        key.Binding
-	Down      key.Binding
-	Enter     key.Binding
-	Back      key.Binding
-	OpenURL   key.Binding
-	ViewHTML  key.Binding
-	Delete    key.Binding
-	Quit      key.Binding
-	Help      key.Binding
-	PrevInbox key.Binding
-	NextInbox key.Binding
-	NewInbox  key.Binding
+	Up           key.Binding
+	Down         key.Binding
+	Enter        key.Binding
+	Back         key.Binding
+	OpenURL      key.Binding
+	ViewHTML     key.Binding
+	Delete       key.Binding
+	Quit         key.Binding
+	Help         key.Binding
+	PrevInbox    key.Binding
+	NextInbox    key.Binding
+	NewInbox     key.Binding
+	ToggleStats  key.Binding
+	GlobalSearch key.Binding
+	TogglePin    key.Binding
+	ClearPins    key.Binding
 }
 
 var DefaultKeyMap = KeyMap{
@@ -67,4 +71,20 @@ var DefaultKeyMap = KeyMap{
 		key.WithKeys("n"),
 		key.WithHelp("n", "new inbox"),
 	),
+	ToggleStats: key.NewBinding(
+		key.WithKeys("s"),
+		key.WithHelp("s", "stats"),
+	),
+	GlobalSearch: key.NewBinding(
+		key.WithKeys("ctrl+f"),
+		key.WithHelp("ctrl+f", "search all inboxes"),
+	),
+	TogglePin: key.NewBinding(
+		key.WithKeys("p"),
+		key.WithHelp("p", "pin/unpin"),
+	),
+	ClearPins: key.NewBinding(
+		key.WithKeys("P"),
+		key.WithHelp("P", "clear pins"),
+	),
 }
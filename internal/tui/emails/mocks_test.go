@@ -24,6 +24,22 @@ func (m *MockKeystore) SaveInbox(exp *vaultsandbox.ExportedInbox) error {
 	return nil
 }
 
+// MockReadTracker implements ReadTracker in memory
+type MockReadTracker struct {
+	read map[string]bool // "inbox/id"
+}
+
+func (r *MockReadTracker) IsRead(inboxEmail, emailID string) bool {
+	return r.read[inboxEmail+"/"+emailID]
+}
+
+func (r *MockReadTracker) MarkRead(inboxEmail, emailID string) {
+	if r.read == nil {
+		r.read = make(map[string]bool)
+	}
+	r.read[inboxEmail+"/"+emailID] = true
+}
+
 // testEmail creates a test email with the given parameters
 func testEmail(id, subject, from string) *vaultsandbox.Email {
 	return &vaultsandbox.Email{
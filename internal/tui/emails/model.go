@@ -2,33 +2,57 @@ package emails
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/charmbracelet/bubbles/list"
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
 	vaultsandbox "github.com/vaultsandbox/client-go"
 	"github.com/vaultsandbox/vsb-cli/internal/cliutil"
+	"github.com/vaultsandbox/vsb-cli/internal/metrics"
 	"github.com/vaultsandbox/vsb-cli/internal/styles"
+	"github.com/vaultsandbox/vsb-cli/internal/table"
 )
 
 // EmailItem represents an email in the list
 type EmailItem struct {
 	Email      *vaultsandbox.Email
 	InboxLabel string
+	Unread     bool // set from the ReadTracker when the list is built
+	Global     bool // global search result: the inbox label prefixes the title
+	Pinned     bool // shown in the pinned section at the top of the list
+	width      int  // description width set when the list is built, 0 for no limit
 }
 
 func (e EmailItem) Title() string {
-	return cliutil.SubjectOrDefault(e.Email.Subject)
+	title := cliutil.SubjectOrDefault(e.Email.Subject)
+	if e.Global && e.InboxLabel != "" {
+		title = fmt.Sprintf("[%s] %s", e.InboxLabel, title)
+	}
+	if e.Unread {
+		title = "● " + title
+	}
+	if e.Pinned {
+		title = pinIndicator() + " " + title
+	}
+	return title
 }
 
+// Description shows the sender and receive time. Long labels and senders
+// are truncated so the time stays visible.
 func (e EmailItem) Description() string {
 	desc := fmt.Sprintf("From: %s", e.Email.From)
-	if e.InboxLabel != "" {
+	if e.InboxLabel != "" && !e.Global {
 		desc = fmt.Sprintf("[%s] %s", e.InboxLabel, desc)
 	}
-	desc += fmt.Sprintf(" • %s", e.Email.ReceivedAt.Format(cliutil.TimeFormatTimeOnly))
-	return desc
+	received := fmt.Sprintf(" • %s", e.Email.ReceivedAt.Format(cliutil.TimeFormatTimeOnly))
+	if e.width > 0 {
+		desc = table.Truncate(desc, e.width-lipgloss.Width(received))
+	}
+	return desc + received
 }
 
 func (e EmailItem) FilterValue() string {
@@ -40,18 +64,51 @@ type Keystore interface {
 	SaveInbox(exported *vaultsandbox.ExportedInbox) error
 }
 
+// ReadTracker records which emails have been opened, keyed by inbox address
+type ReadTracker interface {
+	IsRead(inboxEmail, emailID string) bool
+	MarkRead(inboxEmail, emailID string)
+}
+
 // Messages
 type emailReceivedMsg struct {
 	email      *vaultsandbox.Email
 	inboxLabel string
+	live       bool // delivered by the event stream, not loaded at startup
 }
 
+// errMsg reports that the event stream failed, and reconnects it
 type errMsg struct {
 	err error
 }
 
+// loadFailedMsg is sent when the existing emails of an inbox cannot be
+// loaded. The event stream is unaffected, so it does not reconnect.
+type loadFailedMsg struct {
+	err error
+}
+
 type connectedMsg struct{}
 
+// watchClosedMsg is sent when the event stream ends without the watch being
+// cancelled
+type watchClosedMsg struct{}
+
+// watchReconnectMsg triggers reconnection attempt number attempt once its
+// backoff has elapsed
+type watchReconnectMsg struct {
+	attempt int
+}
+
+// watchStableMsg is sent ReconnectStableAfter after reconnection attempt
+// number attempt, to reset the backoff if the stream is still up
+type watchStableMsg struct {
+	attempt int
+}
+
+// statusTickMsg refreshes the status line so the last event age stays current
+type statusTickMsg struct{}
+
 type inboxCreatedMsg struct {
 	inbox *vaultsandbox.Inbox
 	err   error
@@ -82,6 +139,15 @@ type Model struct {
 	emails          []EmailItem
 	currentInboxIdx int // index into inboxes slice
 
+	// Global search (ctrl+f) lists matches from every inbox, bypassing
+	// currentInboxIdx
+	globalSearch bool
+	globalQuery  string
+
+	// Pinned emails (p) are listed first whatever the inbox filter; the
+	// pins last for the session only
+	pinnedEmailIDs map[string]bool
+
 	// Detail view state
 	viewing            bool
 	viewedEmail        *EmailItem
@@ -91,8 +157,21 @@ type Model struct {
 	lastSavedFile      string
 
 	// Connection state
-	connected bool
-	lastError error
+	connected        bool
+	lastError        error
+	transport        string    // delivery strategy shown in the status line
+	lastEventAt      time.Time // zero until the first email arrives
+	reconnectAttempt int       // 0 unless the stream was lost
+	reconnectAt      time.Time // when the pending attempt fires; zero if none
+
+	// Statistics sidebar
+	showStats    bool
+	sessionStats sessionStats
+
+	// --save-dir state
+	saveDir    string
+	persisted  map[string]bool // email IDs written or being written
+	savedCount int
 
 	// Layout
 	width  int
@@ -106,7 +185,9 @@ type Model struct {
 	client   *vaultsandbox.Client
 	inboxes  []*vaultsandbox.Inbox
 	keystore Keystore
+	reads    ReadTracker // nil disables read tracking
 	program  *tea.Program
+	metrics  *metrics.Recorder // nil unless --metrics-listen/--metrics-file
 }
 
 // NewModel creates a new watch TUI model
@@ -149,15 +230,24 @@ func NewModel(client *vaultsandbox.Client, inboxes []*vaultsandbox.Inbox, active
 		client:          client,
 		inboxes:         inboxes,
 		keystore:        keystore,
+		sessionStats:    sessionStats{startedAt: time.Now()},
 	}
 }
 
 func (m Model) Init() tea.Cmd {
 	return tea.Batch(
 		m.startWatching(),
+		statusTick(),
 	)
 }
 
+// statusTick schedules the next status line refresh
+func statusTick() tea.Cmd {
+	return tea.Tick(time.Second, func(time.Time) tea.Msg {
+		return statusTickMsg{}
+	})
+}
+
 func (m *Model) startWatching() tea.Cmd {
 	return func() tea.Msg {
 		return connectedMsg{}
@@ -169,20 +259,25 @@ func (m *Model) WatchEmails(p *tea.Program) {
 	if len(m.inboxes) == 0 {
 		return
 	}
-	eventCh := m.client.WatchInboxes(m.ctx, m.inboxes...)
+	ctx := m.ctx
+	eventCh := m.client.WatchInboxes(ctx, m.inboxes...)
 	go func() {
 		for {
 			select {
-			case <-m.ctx.Done():
+			case <-ctx.Done():
 				return
 			case event, ok := <-eventCh:
 				if !ok {
+					if ctx.Err() == nil {
+						p.Send(watchClosedMsg{})
+					}
 					return
 				}
 				if event != nil {
 					p.Send(emailReceivedMsg{
 						email:      event.Email,
 						inboxLabel: event.Inbox.EmailAddress(),
+						live:       true,
 					})
 				}
 			}
@@ -196,7 +291,7 @@ func (m *Model) LoadExistingEmails(p *tea.Program) {
 		for _, inbox := range m.inboxes {
 			emails, err := inbox.GetEmails(m.ctx)
 			if err != nil {
-				p.Send(errMsg{err: err})
+				p.Send(loadFailedMsg{err: err})
 				continue
 			}
 			for _, email := range emails {
@@ -219,6 +314,35 @@ func (m *Model) SetProgram(p *tea.Program) {
 	m.program = p
 }
 
+// SetReadTracker marks emails read when they are opened and shows unread
+// counts in the title
+func (m *Model) SetReadTracker(reads ReadTracker) {
+	m.reads = reads
+}
+
+// SetTransport sets the delivery strategy (sse, polling or auto) shown in
+// the status line
+func (m *Model) SetTransport(transport string) {
+	m.transport = transport
+}
+
+// SetMetrics records delivery statistics in rec while the dashboard runs
+func (m *Model) SetMetrics(rec *metrics.Recorder) {
+	m.metrics = rec
+}
+
+// resize fits the list and viewport to the window, leaving room for the
+// statistics sidebar when it is shown
+func (m *Model) resize() {
+	listWidth := m.width - 4
+	if m.showStats {
+		listWidth -= statsWidth
+	}
+	m.list.SetSize(max(listWidth, 0), m.height-7)
+	m.viewport.Width = m.width - 4
+	m.viewport.Height = m.height - 8
+}
+
 // restartWatch cancels the current watch and starts a new one with all inboxes
 func (m *Model) restartWatch() {
 	if m.program == nil || len(m.inboxes) == 0 {
@@ -229,6 +353,51 @@ func (m *Model) restartWatch() {
 	m.ctx, m.cancel = context.WithCancel(context.Background())
 	// Start watching with updated inbox list
 	m.WatchEmails(m.program)
+	m.metrics.Reconnected()
+}
+
+// Reconnection backoff: the first retry waits reconnectBaseDelay, doubling
+// with each failed attempt up to maxReconnectDelay. The WatchInboxes stream
+// does not report when it is established, so the backoff is only reset by
+// an email from the stream, or once the stream has stayed up for
+// ReconnectStableAfter (overridden in tests). The headless watch of
+// vsb --no-tui uses the same backoff.
+var (
+	reconnectBaseDelay   = time.Second
+	maxReconnectDelay    = 30 * time.Second
+	ReconnectStableAfter = time.Minute
+)
+
+// errStreamClosed is reported when the event stream ends on its own
+var errStreamClosed = errors.New("event stream closed")
+
+// ReconnectDelay returns how long to wait before the given attempt
+func ReconnectDelay(attempt int) time.Duration {
+	delay := reconnectBaseDelay
+	for i := 1; i < attempt && delay < maxReconnectDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, maxReconnectDelay)
+}
+
+// connectionLost marks the watch as down and schedules the next reconnection
+// attempt, unless one is already pending.
+func (m *Model) connectionLost(err error) tea.Cmd {
+	m.lastError = err
+	m.connected = false
+	m.metrics.SetUp(false)
+	if !m.reconnectAt.IsZero() {
+		m.updateTitle()
+		return nil
+	}
+	m.reconnectAttempt++
+	attempt := m.reconnectAttempt
+	delay := ReconnectDelay(attempt)
+	m.reconnectAt = time.Now().Add(delay)
+	m.updateTitle()
+	return tea.Tick(delay, func(time.Time) tea.Msg {
+		return watchReconnectMsg{attempt: attempt}
+	})
 }
 
 // selectedEmail returns the currently selected or viewed email
@@ -0,0 +1,80 @@
+package emails
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	tea "github.com/charmbracelet/bubbletea"
+	vaultsandbox "github.com/vaultsandbox/client-go"
+	"github.com/vaultsandbox/vsb-cli/internal/cliutil"
+	mailfile "github.com/vaultsandbox/vsb-cli/internal/email"
+)
+
+// fetchRawEmail fetches an email's RFC 5322 source; overridden in tests
+var fetchRawEmail = func(ctx context.Context, inbox *vaultsandbox.Inbox, emailID string) (string, error) {
+	return inbox.GetRawEmail(ctx, emailID)
+}
+
+// emailPersistedMsg is sent after writing a received email to --save-dir
+type emailPersistedMsg struct {
+	emailID string
+	// existed is set when the file was already on disk, e.g. from an
+	// earlier session, and was left alone
+	existed bool
+	err     error
+}
+
+// SetSaveDir makes the dashboard write every email it receives to
+// dir/<id>.eml. Each email is written at most once.
+func (m *Model) SetSaveDir(dir string) {
+	m.saveDir = dir
+	m.persisted = make(map[string]bool)
+}
+
+// persistEmail writes item to the save directory unless it has already
+// been written this session.
+func (m *Model) persistEmail(item EmailItem) tea.Cmd {
+	if m.saveDir == "" || m.persisted[item.Email.ID] {
+		return nil
+	}
+	// Mark before writing so a redelivery while the write is in flight
+	// doesn't start a second one
+	m.persisted[item.Email.ID] = true
+
+	ctx, dir := m.ctx, m.saveDir
+	inbox := m.findInboxForEmail(item)
+	return func() tea.Msg {
+		id := item.Email.ID
+		if inbox == nil {
+			return emailPersistedMsg{emailID: id, err: fmt.Errorf("no inbox for email %s", id)}
+		}
+		raw, err := fetchRawEmail(ctx, inbox, id)
+		if err != nil {
+			return emailPersistedMsg{emailID: id, err: fmt.Errorf("failed to save email %s: %w", id, err)}
+		}
+		existed, err := WriteEMLOnce(dir, id, mailfile.EML(raw))
+		return emailPersistedMsg{emailID: id, existed: existed, err: err}
+	}
+}
+
+// WriteEMLOnce creates dir/<id>.eml with 0600 permissions. An existing file
+// is not overwritten; existed reports whether there was one.
+func WriteEMLOnce(dir, id string, data []byte) (existed bool, err error) {
+	path := filepath.Join(dir, cliutil.SanitizeFilename(id)+".eml")
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if errors.Is(err, os.ErrExist) {
+		return true, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to save email: %w", err)
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		os.Remove(path)
+		return false, fmt.Errorf("failed to save email: %w", err)
+	}
+	return false, f.Close()
+}
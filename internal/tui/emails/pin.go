@@ -0,0 +1,72 @@
+package emails
+
+import "github.com/vaultsandbox/vsb-cli/internal/styles"
+
+// pinIndicator marks pinned emails in the list; NO_COLOR and --color never
+// fall back to plain ASCII
+func pinIndicator() string {
+	if !styles.UseColor(true) {
+		return "*"
+	}
+	return "📌"
+}
+
+// withPinned returns the pinned emails followed by the others in filtered.
+// Outside global search, pinned emails of every inbox are shown, so a pin
+// stays in view when switching inboxes. Both parts keep the newest-first
+// order of m.emails.
+func (m Model) withPinned(filtered []EmailItem) []EmailItem {
+	if len(m.pinnedEmailIDs) == 0 {
+		return filtered
+	}
+	source := m.emails
+	if m.globalSearch {
+		source = filtered
+	}
+	var result []EmailItem
+	for _, e := range source {
+		if m.pinnedEmailIDs[e.Email.ID] {
+			e.Pinned = true
+			result = append(result, e)
+		}
+	}
+	for _, e := range filtered {
+		if !m.pinnedEmailIDs[e.Email.ID] {
+			result = append(result, e)
+		}
+	}
+	return result
+}
+
+// togglePin pins the selected email, or unpins it if it is pinned. The
+// selection follows the email to its new position.
+func (m *Model) togglePin() {
+	filtered := m.filteredEmails()
+	i := m.list.Index()
+	if i < 0 || i >= len(filtered) {
+		return
+	}
+	id := filtered[i].Email.ID
+	if m.pinnedEmailIDs[id] {
+		delete(m.pinnedEmailIDs, id)
+	} else {
+		if m.pinnedEmailIDs == nil {
+			m.pinnedEmailIDs = make(map[string]bool)
+		}
+		m.pinnedEmailIDs[id] = true
+	}
+	m.updateFilteredList()
+	for j, e := range m.filteredEmails() {
+		if e.Email.ID == id {
+			m.list.Select(j)
+			break
+		}
+	}
+}
+
+// clearPins unpins every email
+func (m *Model) clearPins() {
+	m.pinnedEmailIDs = nil
+	m.list.ResetSelected()
+	m.updateFilteredList()
+}
@@ -0,0 +1,112 @@
+package emails
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	vaultsandbox "github.com/vaultsandbox/client-go"
+	"github.com/vaultsandbox/vsb-cli/internal/styles"
+)
+
+func pinTestModel() Model {
+	m := testModel([]EmailItem{
+		testEmailItem("1", "Newest", "a@test.com", "alpha@vsx.email"),
+		testEmailItem("2", "Middle", "b@test.com", "beta@vsx.email"),
+		testEmailItem("3", "Oldest", "c@test.com", "alpha@vsx.email"),
+	})
+	m.updateFilteredList()
+	return m
+}
+
+func pressKey(m Model, r rune) Model {
+	newModel, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	return newModel.(Model)
+}
+
+func TestTogglePin(t *testing.T) {
+	m := pinTestModel()
+	m.list.Select(2)
+
+	m = pressKey(m, 'p')
+	assert.True(t, m.pinnedEmailIDs["3"])
+	assert.Equal(t, []string{"3", "1", "2"}, resultIDs(m.filteredEmails()))
+	assert.Equal(t, 0, m.list.Index(), "selection follows the pinned email")
+	assert.True(t, m.filteredEmails()[0].Pinned)
+
+	m.list.Select(2)
+	m = pressKey(m, 'p')
+	assert.Equal(t, []string{"2", "3", "1"}, resultIDs(m.filteredEmails()), "pins keep newest-first order")
+	assert.Equal(t, 0, m.list.Index())
+
+	m = pressKey(m, 'p')
+	assert.False(t, m.pinnedEmailIDs["2"])
+	assert.Equal(t, []string{"3", "1", "2"}, resultIDs(m.filteredEmails()))
+	assert.Equal(t, 2, m.list.Index())
+}
+
+func TestClearPins(t *testing.T) {
+	m := pinTestModel()
+	m.pinnedEmailIDs = map[string]bool{"2": true, "3": true}
+	m.updateFilteredList()
+
+	m = pressKey(m, 'P')
+	assert.Empty(t, m.pinnedEmailIDs)
+	assert.Equal(t, []string{"1", "2", "3"}, resultIDs(m.filteredEmails()))
+}
+
+func TestPinsSurviveInboxSwitch(t *testing.T) {
+	m := pinTestModel()
+	m.inboxes = []*vaultsandbox.Inbox{{}, {}}
+	m.pinnedEmailIDs = map[string]bool{"2": true}
+
+	// Neither test inbox matches the labels, so only the pin is listed
+	m.currentInboxIdx = 0
+	assert.Equal(t, []string{"2"}, resultIDs(m.filteredEmails()))
+	newModel, _ := m.Update(tea.KeyMsg{Type: tea.KeyRight})
+	m = newModel.(Model)
+	assert.Equal(t, 1, m.currentInboxIdx)
+	assert.Equal(t, []string{"2"}, resultIDs(m.filteredEmails()))
+}
+
+func TestPinsInGlobalSearch(t *testing.T) {
+	m := pinTestModel()
+	m.pinnedEmailIDs = map[string]bool{"1": true, "3": true}
+	m.globalSearch = true
+	m.globalQuery = "oldest"
+
+	results := m.filteredEmails()
+	require.Len(t, results, 1, "only pins matching the query are listed")
+	assert.Equal(t, "3", results[0].Email.ID)
+	assert.True(t, results[0].Pinned)
+}
+
+func TestDeletedEmailIsUnpinned(t *testing.T) {
+	m := pinTestModel()
+	m.pinnedEmailIDs = map[string]bool{"2": true}
+
+	newModel, _ := m.Update(emailDeletedMsg{emailID: "2"})
+	m = newModel.(Model)
+	assert.Empty(t, m.pinnedEmailIDs)
+}
+
+func TestPinnedTitle(t *testing.T) {
+	item := testEmailItem("1", "Hello", "a@test.com", "alpha@vsx.email")
+	item.Pinned = true
+	item.Unread = true
+
+	t.Setenv("NO_COLOR", "")
+	assert.Equal(t, "📌 ● Hello", item.Title())
+
+	t.Setenv("NO_COLOR", "1")
+	assert.Equal(t, "* ● Hello", item.Title())
+
+	t.Cleanup(func() { styles.SetColorMode(styles.ColorAuto) })
+	styles.SetColorMode(styles.ColorAlways)
+	assert.Equal(t, "📌 ● Hello", item.Title())
+
+	t.Setenv("NO_COLOR", "")
+	styles.SetColorMode(styles.ColorNever)
+	assert.Equal(t, "* ● Hello", item.Title())
+}
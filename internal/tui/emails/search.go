@@ -0,0 +1,108 @@
+package emails
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// snippetLength is how much of the body global search looks at
+const snippetLength = 200
+
+// searchEmails returns the emails matching query across all inboxes, newest
+// first. Every whitespace-separated term must match: fuzzily (its letters
+// in order) against the subject or sender, or as a substring of the start
+// of the body. Matching ignores case; an empty query matches everything.
+func searchEmails(emails []EmailItem, query string) []EmailItem {
+	terms := strings.Fields(strings.ToLower(query))
+	var results []EmailItem
+	for _, e := range emails {
+		if matchesTerms(e, terms) {
+			e.Global = true
+			results = append(results, e)
+		}
+	}
+	return results
+}
+
+func matchesTerms(e EmailItem, terms []string) bool {
+	subject := strings.ToLower(e.Email.Subject)
+	from := strings.ToLower(e.Email.From)
+	body := strings.ToLower(bodySnippet(e))
+	for _, term := range terms {
+		if !fuzzyMatch(subject, term) && !fuzzyMatch(from, term) && !strings.Contains(body, term) {
+			return false
+		}
+	}
+	return true
+}
+
+// fuzzyMatch reports whether the runes of term appear in s in order
+func fuzzyMatch(s, term string) bool {
+	for _, r := range term {
+		i := strings.IndexRune(s, r)
+		if i < 0 {
+			return false
+		}
+		s = s[i+utf8.RuneLen(r):]
+	}
+	return true
+}
+
+// bodySnippet returns the start of the plain text body, or of the HTML body
+// when there is no text part
+func bodySnippet(e EmailItem) string {
+	body := e.Email.Text
+	if body == "" {
+		body = e.Email.HTML
+	}
+	if len(body) > snippetLength {
+		body = strings.ToValidUTF8(body[:snippetLength], "")
+	}
+	return body
+}
+
+// toggleGlobalSearch enters or leaves global search mode. Leaving restores
+// the list of the current inbox.
+func (m *Model) toggleGlobalSearch() {
+	m.globalSearch = !m.globalSearch
+	m.globalQuery = ""
+	m.list.ResetFilter()
+	m.list.ResetSelected()
+	m.updateFilteredList()
+}
+
+// handleGlobalSearchUpdate handles key events in global search mode: typing
+// edits the query and the results follow it.
+func (m Model) handleGlobalSearchUpdate(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case msg.Type == tea.KeyCtrlC:
+		m.cancel()
+		return m, tea.Quit
+	case msg.Type == tea.KeyEsc, key.Matches(msg, DefaultKeyMap.GlobalSearch):
+		m.toggleGlobalSearch()
+		return m, nil
+	case msg.Type == tea.KeyEnter:
+		return m.handleListViewUpdate(msg)
+	case msg.Type == tea.KeyUp, msg.Type == tea.KeyDown:
+		var cmd tea.Cmd
+		m.list, cmd = m.list.Update(msg)
+		return m, cmd
+	case msg.Type == tea.KeyBackspace:
+		if m.globalQuery != "" {
+			_, size := utf8.DecodeLastRuneInString(m.globalQuery)
+			m.globalQuery = m.globalQuery[:len(m.globalQuery)-size]
+		}
+	case msg.Type == tea.KeySpace:
+		m.globalQuery += " "
+	case msg.Type == tea.KeyRunes:
+		m.globalQuery += string(msg.Runes)
+	default:
+		return m, nil
+	}
+	m.list.ResetSelected()
+	m.updateFilteredList()
+	return m, nil
+}
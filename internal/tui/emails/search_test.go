@@ -0,0 +1,149 @@
+package emails
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	vaultsandbox "github.com/vaultsandbox/client-go"
+)
+
+func searchTestEmails() []EmailItem {
+	invoice := testEmailItem("1", "Your invoice #42", "billing@shop.test", "alpha@vsx.email")
+	invoice.Email.Text = "Total due: 19.99 EUR"
+	welcome := testEmailItem("2", "Welcome aboard", "hello@app.test", "beta@vsx.email")
+	welcome.Email.HTML = "<p>Confirm your account</p>"
+	reset := testEmailItem("3", "Password reset", "security@app.test", "alpha@vsx.email")
+	reset.Email.Text = strings.Repeat("x", snippetLength) + " hidden token"
+	return []EmailItem{invoice, welcome, reset}
+}
+
+func resultIDs(items []EmailItem) []string {
+	var ids []string
+	for _, e := range items {
+		ids = append(ids, e.Email.ID)
+	}
+	return ids
+}
+
+func TestSearchEmails(t *testing.T) {
+	emails := searchTestEmails()
+
+	tests := []struct {
+		name  string
+		query string
+		want  []string
+	}{
+		{"empty query matches all", "", []string{"1", "2", "3"}},
+		{"subject substring", "invoice", []string{"1"}},
+		{"case insensitive", "WELCOME", []string{"2"}},
+		{"fuzzy subject", "pwdrst", []string{"3"}},
+		{"sender", "app.test", []string{"2", "3"}},
+		{"body snippet", "19.99", []string{"1"}},
+		{"html body when no text", "confirm", []string{"2"}},
+		{"body beyond snippet ignored", "hidden", nil},
+		{"all terms must match", "app.test reset", []string{"3"}},
+		{"no match", "zzz", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, resultIDs(searchEmails(emails, tt.query)))
+		})
+	}
+
+	t.Run("results are marked global", func(t *testing.T) {
+		results := searchEmails(emails, "invoice")
+		require.Len(t, results, 1)
+		assert.Equal(t, "[alpha@vsx.email] Your invoice #42", results[0].Title())
+		assert.NotContains(t, results[0].Description(), "alpha@vsx.email")
+		assert.False(t, emails[0].Global)
+	})
+}
+
+func TestFuzzyMatch(t *testing.T) {
+	assert.True(t, fuzzyMatch("password reset", "pwreset"))
+	assert.True(t, fuzzyMatch("café receipt", "caf"))
+	assert.False(t, fuzzyMatch("password reset", "reset password"))
+	assert.True(t, fuzzyMatch("anything", ""))
+}
+
+func TestGlobalSearchBypassesInbox(t *testing.T) {
+	m := testModel(searchTestEmails())
+	m.inboxes = []*vaultsandbox.Inbox{{}}
+	m.currentInboxIdx = 0
+	assert.Empty(t, m.filteredEmails())
+
+	m.globalSearch = true
+	assert.Equal(t, []string{"1", "2", "3"}, resultIDs(m.filteredEmails()))
+
+	m.globalQuery = "alpha"
+	assert.Empty(t, m.filteredEmails(), "inbox labels are not searched")
+}
+
+func TestUpdateGlobalSearch(t *testing.T) {
+	typeText := func(m Model, text string) Model {
+		for _, r := range text {
+			msg := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}}
+			if r == ' ' {
+				msg = tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{r}}
+			}
+			newModel, _ := m.Update(msg)
+			m = newModel.(Model)
+		}
+		return m
+	}
+
+	m := testModel(searchTestEmails())
+	newModel, _ := m.Update(tea.KeyMsg{Type: tea.KeyCtrlF})
+	m = newModel.(Model)
+	require.True(t, m.globalSearch)
+	assert.Len(t, m.list.Items(), 3)
+
+	t.Run("results follow typing", func(t *testing.T) {
+		m := typeText(m, "app reset")
+		assert.Equal(t, "app reset", m.globalQuery)
+		assert.Len(t, m.list.Items(), 1)
+		assert.Contains(t, m.list.Title, "Search all inboxes: app reset")
+
+		newModel, _ := m.Update(tea.KeyMsg{Type: tea.KeyBackspace})
+		m = newModel.(Model)
+		assert.Equal(t, "app rese", m.globalQuery)
+	})
+
+	t.Run("list keys are typed into the query", func(t *testing.T) {
+		m := typeText(m, "dq")
+		assert.Equal(t, "dq", m.globalQuery)
+		assert.Len(t, m.emails, 3)
+	})
+
+	t.Run("enter views the selected result", func(t *testing.T) {
+		m := typeText(m, "welcome")
+		newModel, _ := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		m = newModel.(Model)
+		require.True(t, m.viewing)
+		assert.Equal(t, "2", m.viewedEmail.Email.ID)
+
+		newModel, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+		m = newModel.(Model)
+		assert.False(t, m.viewing)
+		assert.True(t, m.globalSearch, "back returns to the results")
+	})
+
+	t.Run("esc leaves search mode", func(t *testing.T) {
+		m := typeText(m, "invoice")
+		newModel, _ := m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+		m = newModel.(Model)
+		assert.False(t, m.globalSearch)
+		assert.Empty(t, m.globalQuery)
+		assert.Len(t, m.list.Items(), 3)
+	})
+
+	t.Run("new emails appear live", func(t *testing.T) {
+		m := typeText(m, "invoice")
+		newModel, _ := m.Update(emailReceivedMsg{email: testEmail("4", "Second invoice", "billing@shop.test"), inboxLabel: "beta@vsx.email"})
+		m = newModel.(Model)
+		assert.Len(t, m.list.Items(), 2)
+	})
+}
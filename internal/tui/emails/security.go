@@ -5,6 +5,7 @@ import (
 	"strings"
 
 	vaultsandbox "github.com/vaultsandbox/client-go"
+	"github.com/vaultsandbox/vsb-cli/internal/analysis"
 	"github.com/vaultsandbox/vsb-cli/internal/cliutil"
 	"github.com/vaultsandbox/vsb-cli/internal/styles"
 )
@@ -19,6 +20,11 @@ func (m Model) renderSecurityView() string {
 		b.WriteString("\n")
 		b.WriteString(styles.RenderAuthResults(email.AuthResults, labelStyle, false))
 		b.WriteString("\n")
+		if explanations := analysis.ExplainAuth(email); len(explanations) > 0 {
+			b.WriteString("\n")
+			b.WriteString(styles.RenderAuthExplanations(explanations))
+			b.WriteString("\n")
+		}
 
 		// Transport Security
 		b.WriteString("\n")
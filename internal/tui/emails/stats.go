@@ -0,0 +1,143 @@
+package emails
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	vaultsandbox "github.com/vaultsandbox/client-go"
+	"github.com/vaultsandbox/vsb-cli/internal/styles"
+)
+
+// statsWidth is the width of the statistics sidebar, taken from the list
+const statsWidth = 30
+
+// statsHours is how many hours the sidebar sparkline covers
+const statsHours = 24
+
+// sparkBars are the sparkline levels, lowest first
+var sparkBars = []rune("▁▂▃▄▅▆▇█")
+
+// sessionStats aggregates the emails the dashboard has received. Emails
+// loaded at startup count towards today and the hourly chart, but only
+// emails received after startedAt count towards the session.
+type sessionStats struct {
+	startedAt time.Time
+	session   int
+	byDay     map[string]int    // local date (2006-01-02) -> count
+	byHour    map[time.Time]int // receive time truncated to the hour -> count
+	senders   map[string]int
+}
+
+// add records one email. Callers must skip duplicates.
+func (s *sessionStats) add(email *vaultsandbox.Email) {
+	if s.byDay == nil {
+		s.byDay = map[string]int{}
+		s.byHour = map[time.Time]int{}
+		s.senders = map[string]int{}
+	}
+	received := email.ReceivedAt
+	if !received.Before(s.startedAt) {
+		s.session++
+	}
+	s.byDay[received.Local().Format(time.DateOnly)]++
+	s.byHour[received.Truncate(time.Hour)]++
+	s.senders[email.From]++
+}
+
+// today returns the number of emails received on now's local date
+func (s sessionStats) today(now time.Time) int {
+	return s.byDay[now.Local().Format(time.DateOnly)]
+}
+
+// hourly returns the email count of each of the last hours hours, oldest
+// first; the last bucket is the current hour.
+func (s sessionStats) hourly(now time.Time, hours int) []int {
+	counts := make([]int, hours)
+	current := now.Truncate(time.Hour)
+	for i := range counts {
+		counts[i] = s.byHour[current.Add(-time.Duration(hours-1-i)*time.Hour)]
+	}
+	return counts
+}
+
+// topSender returns the most frequent sender and its count, breaking ties
+// alphabetically. It returns "" if no email has been received.
+func (s sessionStats) topSender() (string, int) {
+	senders := make([]string, 0, len(s.senders))
+	for sender := range s.senders {
+		senders = append(senders, sender)
+	}
+	sort.Slice(senders, func(i, j int) bool {
+		ci, cj := s.senders[senders[i]], s.senders[senders[j]]
+		if ci != cj {
+			return ci > cj
+		}
+		return senders[i] < senders[j]
+	})
+	if len(senders) == 0 {
+		return "", 0
+	}
+	return senders[0], s.senders[senders[0]]
+}
+
+// sparkline renders counts as block characters scaled to the largest count
+func sparkline(counts []int) string {
+	peak := 0
+	for _, c := range counts {
+		peak = max(peak, c)
+	}
+	var b strings.Builder
+	for _, c := range counts {
+		level := 0
+		if peak > 0 && c > 0 {
+			level = max(c*(len(sparkBars)-1)/peak, 1)
+		}
+		b.WriteRune(sparkBars[level])
+	}
+	return b.String()
+}
+
+// renderStats renders the statistics sidebar, height lines tall
+func (m Model) renderStats(now time.Time, height int) string {
+	inner := statsWidth - 4 // border and padding
+
+	var b strings.Builder
+	b.WriteString(styles.PrimaryBoldStyle.Render("Statistics"))
+	b.WriteString("\n\n")
+	fmt.Fprintf(&b, "%s %d\n", styles.MutedStyle.Render("Today:  "), m.sessionStats.today(now))
+	fmt.Fprintf(&b, "%s %d\n\n", styles.MutedStyle.Render("Session:"), m.sessionStats.session)
+
+	b.WriteString(styles.MutedStyle.Render(fmt.Sprintf("Last %dh", statsHours)))
+	b.WriteString("\n")
+	b.WriteString(styles.FromStyle.Render(sparkline(m.sessionStats.hourly(now, statsHours))))
+	b.WriteString("\n\n")
+
+	b.WriteString(styles.MutedStyle.Render("Top sender"))
+	b.WriteString("\n")
+	if sender, count := m.sessionStats.topSender(); sender != "" {
+		b.WriteString(truncate(sender, inner))
+		fmt.Fprintf(&b, "\n%s", styles.MutedStyle.Render(fmt.Sprintf("%d email(s)", count)))
+	} else {
+		b.WriteString(styles.MutedStyle.Render("none yet"))
+	}
+
+	return lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(styles.DarkGray).
+		Padding(0, 1).
+		Width(statsWidth - 2).
+		Height(max(height-2, 0)).
+		Render(b.String())
+}
+
+// truncate shortens s to width runes, marking the cut with an ellipsis
+func truncate(s string, width int) string {
+	r := []rune(s)
+	if len(r) <= width {
+		return s
+	}
+	return string(r[:width-1]) + "…"
+}
@@ -0,0 +1,59 @@
+package emails
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	vaultsandbox "github.com/vaultsandbox/client-go"
+)
+
+func TestSessionStats(t *testing.T) {
+	now := time.Date(2026, 3, 1, 15, 30, 0, 0, time.Local)
+	email := func(from string, receivedAt time.Time) *vaultsandbox.Email {
+		return &vaultsandbox.Email{From: from, ReceivedAt: receivedAt}
+	}
+
+	s := sessionStats{startedAt: now.Add(-time.Hour)}
+	s.add(email("old@example.com", now.Add(-26*time.Hour))) // yesterday, before the session
+	s.add(email("a@example.com", now.Add(-2*time.Hour)))    // today, before the session
+	s.add(email("b@example.com", now.Add(-30*time.Minute))) // this session
+	s.add(email("a@example.com", now.Add(-10*time.Minute))) // this session
+	s.add(email("b@example.com", now))                      // this session
+
+	assert.Equal(t, 3, s.session)
+	assert.Equal(t, 4, s.today(now))
+	assert.Equal(t, 1, s.today(now.Add(-24*time.Hour)))
+
+	hourly := s.hourly(now, 24)
+	assert.Len(t, hourly, 24)
+	assert.Equal(t, 3, hourly[23], "current hour")
+	assert.Equal(t, 0, hourly[22])
+	assert.Equal(t, 1, hourly[21])
+	assert.Equal(t, 0, hourly[0], "older than the window")
+
+	sender, count := s.topSender()
+	assert.Equal(t, "a@example.com", sender, "ties break alphabetically")
+	assert.Equal(t, 2, count)
+}
+
+func TestSessionStatsEmpty(t *testing.T) {
+	var s sessionStats
+	assert.Equal(t, 0, s.today(time.Now()))
+	assert.Equal(t, []int{0, 0, 0}, s.hourly(time.Now(), 3))
+	sender, count := s.topSender()
+	assert.Empty(t, sender)
+	assert.Zero(t, count)
+}
+
+func TestSparkline(t *testing.T) {
+	assert.Equal(t, "▁▁▁", sparkline([]int{0, 0, 0}))
+	assert.Equal(t, "▁▂█", sparkline([]int{0, 1, 8}))
+	assert.Equal(t, "▂▄█", sparkline([]int{1, 4, 8}))
+	assert.Equal(t, "██", sparkline([]int{3, 3}))
+}
+
+func TestTruncate(t *testing.T) {
+	assert.Equal(t, "short", truncate("short", 10))
+	assert.Equal(t, "averylongaddr…", truncate("averylongaddress@example.com", 14))
+}
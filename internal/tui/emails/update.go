@@ -2,6 +2,7 @@ package emails
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
@@ -14,6 +15,9 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if m.viewing {
 			return m.handleDetailViewUpdate(msg)
 		}
+		if m.globalSearch {
+			return m.handleGlobalSearchUpdate(msg)
+		}
 		if m.list.FilterState() == list.Filtering {
 			break
 		}
@@ -22,9 +26,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
 		m.height = msg.Height
-		m.list.SetSize(msg.Width-4, msg.Height-6)
-		m.viewport.Width = msg.Width - 4
-		m.viewport.Height = msg.Height - 8
+		m.resize()
 		// Refresh list after sizing
 		if m.connected {
 			m.updateFilteredList()
@@ -32,9 +34,43 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case connectedMsg:
 		m.connected = true
+		m.lastError = nil
+		m.reconnectAt = time.Time{}
+		m.metrics.SetUp(true)
 		m.updateFilteredList()
+		// The watch has only been started, so keep counting attempts until
+		// it proves to be up
+		if attempt := m.reconnectAttempt; attempt > 0 {
+			return m, tea.Tick(ReconnectStableAfter, func(time.Time) tea.Msg {
+				return watchStableMsg{attempt: attempt}
+			})
+		}
+
+	case watchClosedMsg:
+		return m, m.connectionLost(errStreamClosed)
+
+	case watchReconnectMsg:
+		// Ignore retries made stale by a successful reconnect
+		if m.connected || msg.attempt != m.reconnectAttempt {
+			return m, nil
+		}
+		m.reconnectAt = time.Time{}
+		m.restartWatch()
+		return m, m.startWatching()
+
+	case watchStableMsg:
+		if m.connected && msg.attempt == m.reconnectAttempt {
+			m.reconnectAttempt = 0
+		}
+
+	case statusTickMsg:
+		return m, statusTick()
 
 	case emailReceivedMsg:
+		m.lastEventAt = time.Now()
+		if msg.live && m.connected {
+			m.reconnectAttempt = 0
+		}
 		// Check if email already exists (avoid duplicates)
 		for _, existing := range m.emails {
 			if existing.Email.ID == msg.email.ID {
@@ -48,13 +84,29 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		// Add to front (newest first)
 		m.emails = append([]EmailItem{item}, m.emails...)
+		m.sessionStats.add(msg.email)
+		m.metrics.EmailReceived(msg.inboxLabel, msg.email.ReceivedAt)
 
 		// Update list
 		m.updateFilteredList()
+		return m, m.persistEmail(item)
+
+	case emailPersistedMsg:
+		if msg.err != nil {
+			// Allow a retry if the email is delivered again
+			delete(m.persisted, msg.emailID)
+			m.lastError = msg.err
+			m.updateTitle()
+		} else if !msg.existed {
+			m.savedCount++
+		}
+		return m, nil
 
 	case errMsg:
+		return m, m.connectionLost(msg.err)
+
+	case loadFailedMsg:
 		m.lastError = msg.err
-		m.connected = false
 		m.updateTitle()
 
 	case emailDeletedMsg:
@@ -69,6 +121,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				break
 			}
 		}
+		delete(m.pinnedEmailIDs, msg.emailID)
 		// Update list items
 		m.updateFilteredList()
 
@@ -108,19 +161,43 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, cmd
 }
 
-// filteredEmails returns emails for the current inbox filter
+// filteredEmails returns emails for the current inbox filter, or the global
+// search results, with pinned emails first
 func (m Model) filteredEmails() []EmailItem {
-	if m.currentInboxIdx < 0 || m.currentInboxIdx >= len(m.inboxes) {
-		return m.emails // show all
-	}
-	currentInbox := m.inboxes[m.currentInboxIdx].EmailAddress()
 	var filtered []EmailItem
-	for _, e := range m.emails {
-		if e.InboxLabel == currentInbox {
-			filtered = append(filtered, e)
+	if m.globalSearch {
+		filtered = searchEmails(m.emails, m.globalQuery)
+	} else if m.currentInboxIdx < 0 || m.currentInboxIdx >= len(m.inboxes) {
+		filtered = m.emails // show all
+	} else {
+		currentInbox := m.inboxes[m.currentInboxIdx].EmailAddress()
+		for _, e := range m.emails {
+			if e.InboxLabel == currentInbox {
+				filtered = append(filtered, e)
+			}
+		}
+	}
+	filtered = m.withPinned(filtered)
+	if m.reads == nil {
+		return filtered
+	}
+	withState := make([]EmailItem, len(filtered))
+	for i, e := range filtered {
+		e.Unread = !m.reads.IsRead(e.InboxLabel, e.Email.ID)
+		withState[i] = e
+	}
+	return withState
+}
+
+// unreadCount returns the number of unread emails for the current inbox filter
+func (m Model) unreadCount() int {
+	count := 0
+	for _, e := range m.filteredEmails() {
+		if e.Unread {
+			count++
 		}
 	}
-	return filtered
+	return count
 }
 
 // updateFilteredList updates the list with filtered emails
@@ -128,23 +205,37 @@ func (m *Model) updateFilteredList() {
 	filtered := m.filteredEmails()
 	items := make([]list.Item, len(filtered))
 	for i, e := range filtered {
+		e.width = m.list.Width() - descPadding
 		items[i] = e
 	}
 	m.list.SetItems(items)
 	m.updateTitle()
 }
 
+// descPadding is the left border and padding the list delegate draws
+// before a description
+const descPadding = 2
+
 // updateTitle updates the list title with current inbox info
 func (m *Model) updateTitle() {
+	counts := fmt.Sprintf("%d emails", len(m.filteredEmails()))
+	if m.reads != nil {
+		counts += fmt.Sprintf(" (%d unread)", m.unreadCount())
+	}
+
 	var title string
-	if !m.connected {
+	if !m.connected && m.reconnectAttempt > 0 && m.lastError != nil {
+		title = "Reconnecting: " + m.lastError.Error()
+	} else if !m.connected {
 		title = "Disconnected"
 	} else if m.lastError != nil {
 		title = "Error: " + m.lastError.Error()
+	} else if m.globalSearch {
+		title = fmt.Sprintf("Search all inboxes: %s▏ • %d results", m.globalQuery, len(m.filteredEmails()))
 	} else if len(m.inboxes) > 1 {
-		title = fmt.Sprintf("[%d/%d] %s • %d emails", m.currentInboxIdx+1, len(m.inboxes), m.currentInboxLabel(), len(m.filteredEmails()))
+		title = fmt.Sprintf("[%d/%d] %s • %s", m.currentInboxIdx+1, len(m.inboxes), m.currentInboxLabel(), counts)
 	} else if len(m.inboxes) == 1 {
-		title = fmt.Sprintf("%s • %d emails", m.currentInboxLabel(), len(m.filteredEmails()))
+		title = fmt.Sprintf("%s • %s", m.currentInboxLabel(), counts)
 	} else {
 		title = "No inboxes"
 	}
@@ -243,6 +334,10 @@ func (m Model) handleListViewUpdate(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			m.viewedEmail = &filtered[i]
 			m.viewport.SetContent(m.renderEmailDetail())
 			m.viewport.GotoTop()
+			if m.reads != nil && filtered[i].Unread {
+				m.reads.MarkRead(filtered[i].InboxLabel, filtered[i].Email.ID)
+				m.updateFilteredList()
+			}
 		}
 		return m, nil
 	case key.Matches(msg, DefaultKeyMap.OpenURL):
@@ -271,6 +366,20 @@ func (m Model) handleListViewUpdate(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return m, nil
 	case key.Matches(msg, DefaultKeyMap.NewInbox):
 		return m, m.createNewInbox()
+	case key.Matches(msg, DefaultKeyMap.ToggleStats):
+		m.showStats = !m.showStats
+		m.resize()
+		m.updateFilteredList()
+		return m, nil
+	case key.Matches(msg, DefaultKeyMap.GlobalSearch):
+		m.toggleGlobalSearch()
+		return m, nil
+	case key.Matches(msg, DefaultKeyMap.TogglePin):
+		m.togglePin()
+		return m, nil
+	case key.Matches(msg, DefaultKeyMap.ClearPins):
+		m.clearPins()
+		return m, nil
 	}
 
 	var cmd tea.Cmd
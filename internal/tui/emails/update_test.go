@@ -1,13 +1,20 @@
 package emails
 
 import (
+	"context"
 	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	vaultsandbox "github.com/vaultsandbox/client-go"
+	"github.com/vaultsandbox/vsb-cli/internal/metrics"
 )
 
 func TestUpdateEmailReceived(t *testing.T) {
@@ -53,6 +60,61 @@ func TestUpdateEmailReceived(t *testing.T) {
 	})
 }
 
+func TestUpdateSessionStats(t *testing.T) {
+	t.Run("counts received emails once", func(t *testing.T) {
+		m := testModel([]EmailItem{})
+		email := testEmail("1", "Hello", "a@x.com")
+
+		newModel, _ := m.Update(emailReceivedMsg{email: email, inboxLabel: "inbox"})
+		newModel, _ = newModel.(Model).Update(emailReceivedMsg{email: email, inboxLabel: "inbox"})
+
+		updated := newModel.(Model)
+		assert.Equal(t, 1, updated.sessionStats.session)
+		assert.Equal(t, 1, updated.sessionStats.today(time.Now()))
+	})
+
+	t.Run("s toggles the sidebar and narrows the list", func(t *testing.T) {
+		m := testModel([]EmailItem{})
+		newModel, _ := m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+		assert.Equal(t, 116, newModel.(Model).list.Width())
+
+		newModel, _ = newModel.(Model).Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'s'}})
+		updated := newModel.(Model)
+		assert.True(t, updated.showStats)
+		assert.Equal(t, 116-statsWidth, updated.list.Width())
+
+		newModel, _ = updated.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'s'}})
+		assert.False(t, newModel.(Model).showStats)
+		assert.Equal(t, 116, newModel.(Model).list.Width())
+	})
+}
+
+func TestUpdateMetrics(t *testing.T) {
+	scrape := func(rec *metrics.Recorder) string {
+		w := httptest.NewRecorder()
+		rec.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+		return w.Body.String()
+	}
+
+	rec := metrics.NewRecorder("inbox@test.com")
+	m := testModel([]EmailItem{})
+	m.SetMetrics(rec)
+
+	newModel, _ := m.Update(connectedMsg{})
+	newModel, _ = newModel.Update(emailReceivedMsg{email: testEmail("1", "First", "a@example.com"), inboxLabel: "inbox@test.com"})
+	newModel, _ = newModel.Update(emailReceivedMsg{email: testEmail("2", "Second", "a@example.com"), inboxLabel: "inbox@test.com"})
+	// Duplicates are not counted
+	newModel, _ = newModel.Update(emailReceivedMsg{email: testEmail("2", "Second", "a@example.com"), inboxLabel: "inbox@test.com"})
+
+	body := scrape(rec)
+	assert.Contains(t, body, `emails_received_total{inbox="inbox@test.com"} 2`)
+	assert.Contains(t, body, "watch_up 1\n")
+	assert.NotContains(t, body, "Second", "email contents must not be exposed")
+
+	newModel.Update(errMsg{err: errors.New("connection lost")})
+	assert.Contains(t, scrape(rec), "watch_up 0\n")
+}
+
 func TestUpdateErrorMsg(t *testing.T) {
 	t.Run("sets lastError", func(t *testing.T) {
 		m := testModel([]EmailItem{})
@@ -87,6 +149,155 @@ func TestUpdateConnectedMsg(t *testing.T) {
 	})
 }
 
+func TestUpdateReconnect(t *testing.T) {
+	origBase, origMax, origStable := reconnectBaseDelay, maxReconnectDelay, ReconnectStableAfter
+	defer func() {
+		reconnectBaseDelay, maxReconnectDelay, ReconnectStableAfter = origBase, origMax, origStable
+	}()
+	reconnectBaseDelay = time.Millisecond
+	maxReconnectDelay = 4 * time.Millisecond
+	ReconnectStableAfter = time.Millisecond
+
+	t.Run("channel closure schedules a reconnect", func(t *testing.T) {
+		m := testModel([]EmailItem{})
+
+		newModel, cmd := m.Update(watchClosedMsg{})
+		updated := newModel.(Model)
+		assert.False(t, updated.connected)
+		assert.Equal(t, 1, updated.reconnectAttempt)
+		assert.ErrorIs(t, updated.lastError, errStreamClosed)
+		assert.Equal(t, "Reconnecting: event stream closed", updated.list.Title)
+		require.NotNil(t, cmd)
+		assert.Equal(t, watchReconnectMsg{attempt: 1}, cmd())
+
+		newModel, cmd = updated.Update(watchReconnectMsg{attempt: 1})
+		updated = newModel.(Model)
+		assert.True(t, updated.reconnectAt.IsZero())
+		require.NotNil(t, cmd)
+		msg := cmd()
+		assert.Equal(t, connectedMsg{}, msg)
+
+		newModel, cmd = updated.Update(msg)
+		updated = newModel.(Model)
+		assert.True(t, updated.connected)
+		assert.NoError(t, updated.lastError)
+		// Starting the watch does not prove it works
+		assert.Equal(t, 1, updated.reconnectAttempt)
+		require.NotNil(t, cmd)
+		assert.Equal(t, watchStableMsg{attempt: 1}, cmd())
+
+		newModel, _ = updated.Update(watchStableMsg{attempt: 1})
+		assert.Zero(t, newModel.(Model).reconnectAttempt)
+	})
+
+	t.Run("backoff grows while reconnected streams keep failing", func(t *testing.T) {
+		m := testModel([]EmailItem{})
+
+		var newModel tea.Model = m
+		var cmd tea.Cmd
+		for attempt := 1; attempt <= 3; attempt++ {
+			newModel, cmd = newModel.Update(watchClosedMsg{})
+			updated := newModel.(Model)
+			assert.Equal(t, attempt, updated.reconnectAttempt)
+			require.NotNil(t, cmd)
+			assert.Equal(t, watchReconnectMsg{attempt: attempt}, cmd())
+
+			newModel, cmd = newModel.Update(watchReconnectMsg{attempt: attempt})
+			newModel, _ = newModel.Update(cmd())
+		}
+		assert.Equal(t, 3, newModel.(Model).reconnectAttempt)
+
+		// A stability check from an earlier attempt does not reset it
+		newModel, _ = newModel.Update(watchStableMsg{attempt: 1})
+		assert.Equal(t, 3, newModel.(Model).reconnectAttempt)
+	})
+
+	t.Run("an email from the stream resets the backoff", func(t *testing.T) {
+		m := testModel([]EmailItem{})
+
+		newModel, _ := m.Update(watchClosedMsg{})
+		newModel, cmd := newModel.Update(watchReconnectMsg{attempt: 1})
+		newModel, _ = newModel.Update(cmd())
+		newModel, _ = newModel.Update(emailReceivedMsg{email: testEmail("1", "Loaded", "a@example.com"), inboxLabel: "inbox@test.com"})
+		assert.Equal(t, 1, newModel.(Model).reconnectAttempt, "loaded emails do not show the stream works")
+
+		newModel, _ = newModel.Update(emailReceivedMsg{email: testEmail("2", "Live", "a@example.com"), inboxLabel: "inbox@test.com", live: true})
+		assert.Zero(t, newModel.(Model).reconnectAttempt)
+	})
+
+	t.Run("failing to load existing emails does not reconnect", func(t *testing.T) {
+		m := testModel([]EmailItem{})
+
+		newModel, cmd := m.Update(loadFailedMsg{err: errors.New("inbox not found")})
+		updated := newModel.(Model)
+		assert.Nil(t, cmd)
+		assert.True(t, updated.connected)
+		assert.Zero(t, updated.reconnectAttempt)
+		assert.EqualError(t, updated.lastError, "inbox not found")
+		assert.Equal(t, "Error: inbox not found", updated.list.Title)
+	})
+
+	t.Run("errMsg schedules a reconnect", func(t *testing.T) {
+		m := testModel([]EmailItem{})
+
+		newModel, cmd := m.Update(errMsg{err: errors.New("stream reset")})
+		updated := newModel.(Model)
+		assert.Equal(t, 1, updated.reconnectAttempt)
+		assert.False(t, updated.reconnectAt.IsZero())
+		require.NotNil(t, cmd)
+		assert.Equal(t, watchReconnectMsg{attempt: 1}, cmd())
+	})
+
+	t.Run("errors while a retry is pending do not schedule another", func(t *testing.T) {
+		m := testModel([]EmailItem{})
+
+		newModel, _ := m.Update(errMsg{err: errors.New("first")})
+		newModel, cmd := newModel.Update(watchClosedMsg{})
+		updated := newModel.(Model)
+		assert.Nil(t, cmd)
+		assert.Equal(t, 1, updated.reconnectAttempt)
+		assert.ErrorIs(t, updated.lastError, errStreamClosed)
+	})
+
+	t.Run("failed attempts count up", func(t *testing.T) {
+		m := testModel([]EmailItem{})
+
+		newModel, _ := m.Update(watchClosedMsg{})
+		newModel, _ = newModel.Update(watchReconnectMsg{attempt: 1})
+		newModel, cmd := newModel.Update(watchClosedMsg{})
+		updated := newModel.(Model)
+		assert.Equal(t, 2, updated.reconnectAttempt)
+		require.NotNil(t, cmd)
+		assert.Equal(t, watchReconnectMsg{attempt: 2}, cmd())
+	})
+
+	t.Run("ignores stale attempts", func(t *testing.T) {
+		m := testModel([]EmailItem{})
+
+		newModel, cmd := m.Update(watchReconnectMsg{attempt: 1})
+		assert.Nil(t, cmd)
+		assert.True(t, newModel.(Model).connected)
+	})
+}
+
+func TestReconnectDelay(t *testing.T) {
+	assert.Equal(t, time.Second, ReconnectDelay(1))
+	assert.Equal(t, 2*time.Second, ReconnectDelay(2))
+	assert.Equal(t, 16*time.Second, ReconnectDelay(5))
+	assert.Equal(t, 30*time.Second, ReconnectDelay(6))
+	assert.Equal(t, 30*time.Second, ReconnectDelay(100))
+}
+
+func TestUpdateStatusTick(t *testing.T) {
+	m := testModel([]EmailItem{})
+
+	_, cmd := m.Update(statusTickMsg{})
+	assert.NotNil(t, cmd, "the ticker keeps running")
+
+	newModel, _ := m.Update(emailReceivedMsg{email: testEmail("1", "Hi", "a@example.com"), inboxLabel: "inbox"})
+	assert.WithinDuration(t, time.Now(), newModel.(Model).lastEventAt, time.Second)
+}
+
 func TestUpdateWindowSize(t *testing.T) {
 	t.Run("updates width and height", func(t *testing.T) {
 		m := testModel([]EmailItem{})
@@ -593,3 +804,109 @@ func TestHandleTabSwitch(t *testing.T) {
 		assert.Nil(t, cmd)
 	})
 }
+
+func TestUpdateSaveDir(t *testing.T) {
+	oldFetch := fetchRawEmail
+	defer func() { fetchRawEmail = oldFetch }()
+	fetches := 0
+	fetchRawEmail = func(ctx context.Context, inbox *vaultsandbox.Inbox, id string) (string, error) {
+		fetches++
+		if id == "broken" {
+			return "", errors.New("not found")
+		}
+		return "Subject: " + id + "\n\nbody\n", nil
+	}
+
+	dir := t.TempDir()
+	m := testModel([]EmailItem{})
+	m.inboxes = []*vaultsandbox.Inbox{{}}
+	m.SetSaveDir(dir)
+
+	// receive feeds an email through Update and runs the resulting save
+	receive := func(m Model, email *vaultsandbox.Email) Model {
+		newModel, cmd := m.Update(emailReceivedMsg{email: email, inboxLabel: "inbox@test.com"})
+		if cmd != nil {
+			newModel, _ = newModel.Update(cmd())
+		}
+		return newModel.(Model)
+	}
+
+	m = receive(m, testEmail("one", "First", "a@example.com"))
+	data, err := os.ReadFile(filepath.Join(dir, "one.eml"))
+	require.NoError(t, err)
+	assert.Equal(t, "Subject: one\r\n\r\nbody\r\n", string(data))
+	assert.Equal(t, 1, m.savedCount)
+
+	t.Run("duplicate delivery is not saved again", func(t *testing.T) {
+		before := fetches
+		m = receive(m, testEmail("one", "First", "a@example.com"))
+		assert.Equal(t, before, fetches)
+		assert.Equal(t, 1, m.savedCount)
+	})
+
+	t.Run("existing file is kept and not counted", func(t *testing.T) {
+		path := filepath.Join(dir, "two.eml")
+		require.NoError(t, os.WriteFile(path, []byte("earlier"), 0600))
+		m = receive(m, testEmail("two", "Second", "a@example.com"))
+
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, "earlier", string(data))
+		assert.Equal(t, 1, m.savedCount)
+	})
+
+	t.Run("failed save is reported and can be retried", func(t *testing.T) {
+		m = receive(m, testEmail("broken", "Broken", "a@example.com"))
+		require.Error(t, m.lastError)
+		assert.Contains(t, m.lastError.Error(), "failed to save email broken")
+		assert.False(t, m.persisted["broken"])
+		assert.NoFileExists(t, filepath.Join(dir, "broken.eml"))
+	})
+
+	t.Run("disabled without save dir", func(t *testing.T) {
+		plain := testModel([]EmailItem{})
+		_, cmd := plain.Update(emailReceivedMsg{email: testEmail("three", "Third", "a@example.com"), inboxLabel: "inbox"})
+		assert.Nil(t, cmd)
+	})
+}
+
+func TestUpdateReadTracking(t *testing.T) {
+	reads := &MockReadTracker{}
+	reads.MarkRead("inbox", "1")
+
+	m := testModel([]EmailItem{
+		testEmailItem("2", "Second", "b@example.com", "inbox"),
+		testEmailItem("1", "First", "a@example.com", "inbox"),
+	})
+	m.SetReadTracker(reads)
+	m.updateFilteredList()
+
+	assert.Equal(t, 1, m.unreadCount())
+	filtered := m.filteredEmails()
+	assert.True(t, filtered[0].Unread)
+	assert.False(t, filtered[1].Unread)
+	assert.Equal(t, "● Second", filtered[0].Title())
+
+	// Opening the unread email marks it read
+	newModel, _ := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	updated := newModel.(Model)
+	assert.True(t, updated.viewing)
+	assert.True(t, reads.IsRead("inbox", "2"))
+	assert.Equal(t, 0, updated.unreadCount())
+
+	t.Run("title shows unread count", func(t *testing.T) {
+		m := testModel([]EmailItem{testEmailItem("3", "Third", "c@example.com", "inbox")})
+		m.inboxes = []*vaultsandbox.Inbox{{}}
+		m.currentInboxIdx = -1 // show all
+		m.SetReadTracker(&MockReadTracker{})
+		m.updateTitle()
+		assert.Contains(t, m.list.Title, "1 emails (1 unread)")
+	})
+
+	t.Run("no tracker means no unread markers", func(t *testing.T) {
+		m := testModel([]EmailItem{testEmailItem("3", "Third", "c@example.com", "inbox")})
+		assert.False(t, m.filteredEmails()[0].Unread)
+		m.updateTitle()
+		assert.NotContains(t, m.list.Title, "unread")
+	})
+}
@@ -3,6 +3,7 @@ package emails
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/lipgloss"
 	vaultsandbox "github.com/vaultsandbox/client-go"
@@ -18,16 +19,60 @@ func (m Model) View() string {
 }
 
 func (m Model) viewList() string {
-	help := styles.HelpStyle.Render("q: quit • enter: view • o: open • v: html • d: delete • ←/→: inbox • n: new")
+	helpText := "q: quit • enter: view • o: open • v: html • d: delete • ←/→: inbox • n: new • s: stats • p/P: pin/clear • ctrl+f: search all"
+	if m.globalSearch {
+		helpText = "type to search subject, sender and body • ↑/↓: move • enter: view • esc: exit search"
+	}
+	if m.saveDir != "" {
+		helpText += fmt.Sprintf(" • saved %d", m.savedCount)
+	}
+	help := styles.HelpStyle.Render(helpText)
+
+	now := time.Now()
+	main := m.list.View()
+	if m.showStats {
+		main = lipgloss.JoinHorizontal(lipgloss.Top, main, m.renderStats(now, lipgloss.Height(main)))
+	}
 
 	content := lipgloss.JoinVertical(lipgloss.Left,
-		m.list.View(),
+		main,
+		m.statusLine(now),
 		help,
 	)
 
 	return styles.AppStyle.Render(content)
 }
 
+// statusLine shows the connection state, the delivery strategy and how long
+// ago the last email arrived, e.g. "● connected • sse • last event 12s ago".
+func (m Model) statusLine(now time.Time) string {
+	var state string
+	switch {
+	case m.connected:
+		state = styles.PassStyle.Render("● connected")
+	case m.reconnectAttempt > 0:
+		text := fmt.Sprintf("◌ reconnecting (attempt %d", m.reconnectAttempt)
+		if wait := m.reconnectAt.Sub(now); !m.reconnectAt.IsZero() && wait > 0 {
+			text += fmt.Sprintf(", retry in %s", max(wait.Round(time.Second), time.Second))
+		}
+		state = styles.WarnStyle.Render(text + ")")
+	default:
+		state = styles.FailStyle.Render("○ disconnected")
+	}
+
+	details := []string{}
+	if m.transport != "" {
+		details = append(details, m.transport)
+	}
+	if m.lastEventAt.IsZero() {
+		details = append(details, "no events yet")
+	} else {
+		age := max(now.Sub(m.lastEventAt), 0).Truncate(time.Second)
+		details = append(details, fmt.Sprintf("last event %s ago", age))
+	}
+	return state + styles.MutedStyle.Render(" • "+strings.Join(details, " • "))
+}
+
 func (m Model) viewDetail() string {
 	if m.viewedEmail == nil {
 		return ""
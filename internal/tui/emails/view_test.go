@@ -3,6 +3,7 @@ package emails
 import (
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	vaultsandbox "github.com/vaultsandbox/client-go"
@@ -53,6 +54,15 @@ func TestViewList(t *testing.T) {
 		assert.Contains(t, output, "v: html")
 		assert.Contains(t, output, "d: delete")
 		assert.Contains(t, output, "n: new")
+		assert.NotContains(t, output, "saved")
+	})
+
+	t.Run("shows saved counter with save dir", func(t *testing.T) {
+		m := testModel([]EmailItem{})
+		m.SetSaveDir(t.TempDir())
+		m.savedCount = 2
+
+		assert.Contains(t, m.viewList(), "saved 2")
 	})
 }
 
@@ -507,3 +517,64 @@ func TestUpdateTitle(t *testing.T) {
 		assert.Equal(t, "No inboxes", m.list.Title)
 	})
 }
+
+func TestStatusLine(t *testing.T) {
+	now := time.Now()
+
+	t.Run("connected", func(t *testing.T) {
+		m := testModel([]EmailItem{})
+		m.SetTransport("sse")
+		m.lastEventAt = now.Add(-12 * time.Second)
+
+		line := m.statusLine(now)
+		assert.Contains(t, line, "● connected")
+		assert.Contains(t, line, "sse")
+		assert.Contains(t, line, "last event 12s ago")
+	})
+
+	t.Run("no events yet", func(t *testing.T) {
+		m := testModel([]EmailItem{})
+		assert.Contains(t, m.statusLine(now), "no events yet")
+	})
+
+	t.Run("reconnecting", func(t *testing.T) {
+		m := testModel([]EmailItem{})
+		m.SetTransport("polling")
+		m.connected = false
+		m.reconnectAttempt = 3
+		m.reconnectAt = now.Add(4 * time.Second)
+
+		line := m.statusLine(now)
+		assert.Contains(t, line, "reconnecting (attempt 3, retry in 4s)")
+		assert.Contains(t, line, "polling")
+	})
+
+	t.Run("disconnected", func(t *testing.T) {
+		m := testModel([]EmailItem{})
+		m.connected = false
+
+		assert.Contains(t, m.statusLine(now), "○ disconnected")
+	})
+
+	t.Run("shown in the list view", func(t *testing.T) {
+		m := testModel([]EmailItem{})
+		assert.Contains(t, m.View(), "● connected")
+	})
+}
+
+func TestViewListStats(t *testing.T) {
+	m := testModel([]EmailItem{})
+	m.sessionStats.add(testEmail("1", "Hello", "alice@example.com"))
+	m.sessionStats.add(testEmail("2", "Again", "alice@example.com"))
+
+	output := m.viewList()
+	assert.Contains(t, output, "s: stats")
+	assert.NotContains(t, output, "Statistics")
+
+	m.showStats = true
+	output = m.viewList()
+	assert.Contains(t, output, "Statistics")
+	assert.Contains(t, output, "Top sender")
+	assert.Contains(t, output, "alice@example.com")
+	assert.Contains(t, output, "█")
+}